@@ -35,7 +35,9 @@ var Cmd = &cobra.Command{
 
 // Values of the command line arguments:
 var args struct {
-	paths []string
+	paths    []string
+	features []string
+	public   bool
 }
 
 func init() {
@@ -49,6 +51,21 @@ func init() {
 			"multiple times then all the specified files and directories will be "+
 			"loaded, in the same order that they appear in the command line.",
 	)
+	flags.StringSliceVar(
+		&args.features,
+		"enable-feature",
+		[]string{},
+		"Name of an experimental feature to enable. Attributes and methods marked with "+
+			"the '@experimental' annotation are only included when the corresponding "+
+			"feature is enabled. Can be used multiple times to enable multiple features.",
+	)
+	flags.BoolVar(
+		&args.public,
+		"public",
+		false,
+		"Remove the types and resources marked with the '@internal' annotation before "+
+			"checking the model, to verify that the public part is consistent on its own.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) {
@@ -69,6 +86,8 @@ func run(cmd *cobra.Command, argv []string) {
 	_, err := language.NewReader().
 		Reporter(reporter).
 		Inputs(args.paths).
+		Features(args.features).
+		Public(args.public).
 		Read()
 	if err != nil {
 		reporter.Errorf("Check failed: %v", err)
@@ -0,0 +1,148 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package export
+
+import (
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/exporter"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/loader"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// Cmd is the definition of the command:
+var Cmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports a model",
+	Long: "Exports the resolved model to a JSON or YAML document that can be used by tools " +
+		"that don't understand the model language.",
+	Run: run,
+}
+
+// Values of the command line arguments:
+var args struct {
+	paths    []string
+	features []string
+	public   bool
+	format   string
+	output   string
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringSliceVar(
+		&args.paths,
+		"model",
+		[]string{},
+		"File or directory containing the model. If it is a directory then all .model"+
+			"files inside it and its sub directories will be loaded. If used "+
+			"multiple times then all the specified files and directories will be "+
+			"loaded, in the same order that they appear in the command line.",
+	)
+	flags.StringSliceVar(
+		&args.features,
+		"enable-feature",
+		[]string{},
+		"Name of an experimental feature to enable. Attributes and methods marked with "+
+			"the '@experimental' annotation are only included when the corresponding "+
+			"feature is enabled. Can be used multiple times to enable multiple features.",
+	)
+	flags.BoolVar(
+		&args.public,
+		"public",
+		false,
+		"Remove the types and resources marked with the '@internal' annotation, so that "+
+			"the exported document only contains the public part of the API.",
+	)
+	flags.StringVar(
+		&args.format,
+		"format",
+		exporter.JSON,
+		"Format of the exported document. Can be 'json' or 'yaml'.",
+	)
+	flags.StringVar(
+		&args.output,
+		"output",
+		"",
+		"File where the exported document will be written. If not specified it will be "+
+			"written to the standard output.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) {
+	// Create the reporter:
+	reporter := reporter.NewReporter()
+
+	// Check command line options:
+	ok := true
+	if len(args.paths) == 0 {
+		reporter.Errorf("Option '--model' is mandatory")
+		ok = false
+	}
+	if !ok {
+		os.Exit(1)
+	}
+
+	// Read the model:
+	modelLoader, err := loader.NewLoader().
+		Reporter(reporter).
+		Paths(args.paths...).
+		Features(args.features...).
+		Public(args.public).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create model loader: %v", err)
+		os.Exit(1)
+	}
+	model, err := modelLoader.Load()
+	if err != nil {
+		reporter.Errorf("Can't read model: %v", err)
+		os.Exit(1)
+	}
+
+	// Open the output file:
+	var output io.Writer = os.Stdout
+	if args.output != "" {
+		file, err := os.Create(args.output)
+		if err != nil {
+			reporter.Errorf("Can't create output file '%s': %v", args.output, err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	// Export the model:
+	modelExporter, err := exporter.NewExporter().
+		Reporter(reporter).
+		Model(model).
+		Format(args.format).
+		Output(output).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create model exporter: %v", err)
+		os.Exit(1)
+	}
+	err = modelExporter.Export()
+	if err != nil {
+		reporter.Errorf("Can't export model: %v", err)
+		os.Exit(1)
+	}
+}
@@ -23,7 +23,8 @@ import (
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/generators"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/generators/docs"
-	"github.com/openshift-online/ocm-api-metamodel/pkg/language"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/hooks"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/loader"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
 )
 
@@ -37,8 +38,13 @@ var Cmd = &cobra.Command{
 
 // Values of the command line arguments:
 var args struct {
-	paths  []string
-	output string
+	paths      []string
+	descriptor string
+	features   []string
+	public     bool
+	output     string
+	runHooks   []string
+	fileHooks  []string
 }
 
 func init() {
@@ -52,12 +58,51 @@ func init() {
 			"multiple times then all the specified files and directories will be "+
 			"loaded, in the same order that they appear in the command line.",
 	)
+	flags.StringVar(
+		&args.descriptor,
+		"descriptor",
+		"",
+		"JSON or YAML document created with the 'export' command that will be used "+
+			"instead of the model files. Can't be used together with '--model'.",
+	)
+	flags.StringSliceVar(
+		&args.features,
+		"enable-feature",
+		[]string{},
+		"Name of an experimental feature to enable. Attributes and methods marked with "+
+			"the '@experimental' annotation are only included when the corresponding "+
+			"feature is enabled. Can be used multiple times to enable multiple features.",
+	)
+	flags.BoolVar(
+		&args.public,
+		"public",
+		false,
+		"Remove the types and resources marked with the '@internal' annotation, so that "+
+			"the generated documentation only contains the public part of the API.",
+	)
 	flags.StringVar(
 		&args.output,
 		"output",
 		"",
 		"Directory where the documentation will be generated.",
 	)
+	flags.StringArrayVar(
+		&args.runHooks,
+		"hook",
+		[]string{},
+		"Shell command that will be executed after generating all the files. The names of "+
+			"the generated files are passed in the standard input, one per line. Can be "+
+			"used multiple times to run multiple commands.",
+	)
+	flags.StringArrayVar(
+		&args.fileHooks,
+		"file-hook",
+		[]string{},
+		"Shell command that will be executed for each generated file, before the commands "+
+			"specified with '--hook'. The name of the file is passed in the standard input "+
+			"and in the 'METAMODEL_FILE' environment variable. Can be used multiple times "+
+			"to run multiple commands.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) {
@@ -66,8 +111,12 @@ func run(cmd *cobra.Command, argv []string) {
 
 	// Check command line options:
 	ok := true
-	if len(args.paths) == 0 {
-		reporter.Errorf("Option '--model' is mandatory")
+	if len(args.paths) == 0 && args.descriptor == "" {
+		reporter.Errorf("Option '--model' or '--descriptor' is mandatory")
+		ok = false
+	}
+	if len(args.paths) > 0 && args.descriptor != "" {
+		reporter.Errorf("Options '--model' and '--descriptor' can't be used together")
 		ok = false
 	}
 	if args.output == "" {
@@ -78,11 +127,32 @@ func run(cmd *cobra.Command, argv []string) {
 		os.Exit(1)
 	}
 
+	// Create the runner for the post generation hooks:
+	hooksRunner, err := hooks.NewRunner().
+		Reporter(reporter).
+		RunHooks(args.runHooks...).
+		FileHooks(args.fileHooks...).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create hooks runner: %v", err)
+		os.Exit(1)
+	}
+
 	// Read the model:
-	model, err := language.NewReader().
+	modelLoader, err := loader.NewLoader().
 		Reporter(reporter).
-		Inputs(args.paths).
-		Read()
+		Paths(args.paths...).
+		Descriptor(args.descriptor).
+		Features(args.features...).
+		Public(args.public).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create model loader: %v", err)
+		os.Exit(1)
+	}
+	end := reporter.Begin("Loading model")
+	model, err := modelLoader.Load()
+	end()
 	if err != nil {
 		reporter.Errorf("Can't read model: %v", err)
 		os.Exit(1)
@@ -106,13 +176,26 @@ func run(cmd *cobra.Command, argv []string) {
 
 	// Run the generators:
 	for _, gen := range gens {
+		end = reporter.Begin("Running generator '%s'", generators.Name(gen))
 		err = gen.Run()
+		end()
 		if err != nil {
 			reporter.Errorf("Generation failed: %v", err)
 			os.Exit(1)
 		}
 	}
 
-	// Bye:
-	os.Exit(0)
+	// Run the post generation hooks:
+	if len(args.runHooks) > 0 || len(args.fileHooks) > 0 {
+		end = reporter.Begin("Running hooks")
+		err = hooksRunner.Run(reporter.Files())
+		end()
+		if err != nil {
+			reporter.Errorf("Hooks failed: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// Print the summary of the time taken and the files generated by each step:
+	reporter.Summary()
 }
@@ -17,15 +17,20 @@ limitations under the License.
 package golang
 
 import (
+	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 
 	"github.com/spf13/cobra"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/generators"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/generators/golang"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/generators/openapi"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/hooks"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
-	"github.com/openshift-online/ocm-api-metamodel/pkg/language"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/loader"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
 )
 
@@ -37,11 +42,47 @@ var Cmd = &cobra.Command{
 	Run:   run,
 }
 
+// genericsGoVersion is the oldest version of Go that supports the generic functions used when the
+// '--generics' option is enabled. When the option isn't explicitly given the generic functions
+// are used if the module where the code is generated requires this version or a newer one.
+const genericsGoVersion = "1.18"
+
 // Values of the command line arguments:
 var args struct {
-	paths  []string
-	base   string
-	output string
+	paths              []string
+	descriptor         string
+	features           []string
+	public             bool
+	base               string
+	module             string
+	updateModule       bool
+	output             string
+	deprecateNoContext bool
+	trailingSlash      string
+	rawAttributes      bool
+	listChecks         bool
+	generics           bool
+	tolerantNumbers    bool
+	longsAsStrings     bool
+	canonicalJSON      bool
+	cbor               bool
+	clients            bool
+	servers            bool
+	aliases            bool
+	aliasPrefixes      []string
+	stream             bool
+	maxLines           int
+	escape             string
+	plurals            []string
+	copyright          string
+	licenseFile        string
+	generator          string
+	marker             string
+	buildTags          []string
+	templates          string
+	modelRevision      string
+	runHooks           []string
+	fileHooks          []string
 }
 
 func init() {
@@ -55,11 +96,49 @@ func init() {
 			"multiple times then all the specified files and directories will be "+
 			"loaded, in the same order that they appear in the command line.",
 	)
+	flags.StringVar(
+		&args.descriptor,
+		"descriptor",
+		"",
+		"JSON or YAML document created with the 'export' command that will be used "+
+			"instead of the model files. Can't be used together with '--model'.",
+	)
+	flags.StringSliceVar(
+		&args.features,
+		"enable-feature",
+		[]string{},
+		"Name of an experimental feature to enable. Attributes and methods marked with "+
+			"the '@experimental' annotation are only included when the corresponding "+
+			"feature is enabled. Can be used multiple times to enable multiple features.",
+	)
+	flags.BoolVar(
+		&args.public,
+		"public",
+		false,
+		"Remove the types and resources marked with the '@internal' annotation, so that "+
+			"the generated code only contains the public part of the API.",
+	)
 	flags.StringVar(
 		&args.base,
 		"base",
 		"",
-		"Import path of the base package for the generated code.",
+		"Import path of the base package for the generated code. If not specified it "+
+			"is calculated from the Go module that contains the output directory.",
+	)
+	flags.StringVar(
+		&args.module,
+		"module",
+		"",
+		"Path of the Go module where the code will be generated. Only needed when the "+
+			"output directory isn't already inside a module, and then the output "+
+			"directory will be the root of the module.",
+	)
+	flags.BoolVar(
+		&args.updateModule,
+		"update-module",
+		false,
+		"Create the 'go.mod' file of the module if it doesn't exist, and add the modules "+
+			"required by the generated code that are missing.",
 	)
 	flags.StringVar(
 		&args.output,
@@ -67,6 +146,215 @@ func init() {
 		"",
 		"Directory where the source code will be generated.",
 	)
+	flags.BoolVar(
+		&args.deprecateNoContext,
+		"deprecate-no-context",
+		false,
+		"Mark the generated methods that send requests without a context as deprecated, "+
+			"so that users are directed to the methods that require a context.",
+	)
+	flags.StringVar(
+		&args.trailingSlash,
+		"trailing-slash",
+		"ignore",
+		"Default way that the generated servers handle request paths that end with a "+
+			"slash. Can be 'ignore', to treat them as equivalent to the paths without the "+
+			"slash, or 'redirect', to redirect the client to the path without the slash.",
+	)
+	flags.BoolVar(
+		&args.rawAttributes,
+		"raw-attributes",
+		false,
+		"Keep the JSON text of the attributes read when unmarshalling objects, and "+
+			"generate the 'RawAttribute' method to access it.",
+	)
+	flags.BoolVar(
+		&args.listChecks,
+		"list-checks",
+		true,
+		"Generate tests that check that all the list types implement the generic list "+
+			"interface of the runtime package. Those tests are only built with Go 1.18 or "+
+			"newer, and they import the runtime package, so use '--list-checks=false' when "+
+			"that package isn't available to the tests of the module.",
+	)
+	flags.BoolVar(
+		&args.generics,
+		"generics",
+		false,
+		"Use generic helper functions in the accessors and list types instead of repeating "+
+			"the same logic for each type, to reduce the size of the generated code. The "+
+			"generated code will need Go 1.18 or newer. By default they are used when the "+
+			"'go.mod' file of the module where the code is generated requires that version "+
+			"or a newer one, and the same logic is repeated for each type otherwise. "+
+			"Explicitly enabling it is rejected if the module requires an older version.",
+	)
+	flags.BoolVar(
+		&args.tolerantNumbers,
+		"tolerant-numbers",
+		false,
+		"Accept integer and long values encoded as JSON strings when unmarshalling objects, "+
+			"in addition to JSON numbers.",
+	)
+	flags.BoolVar(
+		&args.longsAsStrings,
+		"longs-as-strings",
+		false,
+		"Encode long values as JSON strings when marshalling objects, to avoid loss of "+
+			"precision in clients that use floating point numbers.",
+	)
+	flags.BoolVar(
+		&args.canonicalJSON,
+		"canonical-json",
+		false,
+		"Write JSON without indentation, with the attributes of objects sorted by name and "+
+			"with a stable format for floating point numbers, so that the same object always "+
+			"produces exactly the same bytes.",
+	)
+	flags.BoolVar(
+		&args.cbor,
+		"cbor",
+		false,
+		"Generate code to encode and decode objects using CBOR, and make the generated "+
+			"clients and servers use it when the request contains the 'Accept: "+
+			"application/cbor' header.",
+	)
+	flags.BoolVar(
+		&args.clients,
+		"clients",
+		true,
+		"Generate the clients. Use '--clients=false' in projects that only serve the API.",
+	)
+	flags.BoolVar(
+		&args.servers,
+		"servers",
+		true,
+		"Generate the server interfaces and adapters. Use '--servers=false' in projects that "+
+			"only consume the API. If both the clients and the servers are disabled only "+
+			"the types, builders and encoding and decoding code are generated.",
+	)
+	flags.BoolVar(
+		&args.aliases,
+		"aliases",
+		false,
+		"Generate for each version an 'aliases' package containing aliases of the types and "+
+			"functions with names prefixed by the service and version, for example "+
+			"'ClustersMgmtV1Cluster'. Useful to dot import multiple services or versions "+
+			"that define types with the same names.",
+	)
+	flags.StringSliceVar(
+		&args.aliasPrefixes,
+		"alias-prefix",
+		[]string{},
+		"Prefix used for the aliases of the types of a service, in the 'service=Prefix' format, "+
+			"for example 'clusters_mgmt=CM'. Can be used multiple times to set the prefixes of "+
+			"multiple services. Services without an explicit prefix use the names of the "+
+			"service and the version.",
+	)
+	flags.BoolVar(
+		&args.stream,
+		"stream",
+		false,
+		"Write the generated code to temporary files as it is generated, instead of keeping "+
+			"it in memory. Reduces the memory used when generating large models.",
+	)
+	flags.IntVar(
+		&args.maxLines,
+		"max-lines",
+		0,
+		"Approximate maximum number of lines of the generated files. Longer files will be "+
+			"split into multiple files of the same package. Zero means that files are "+
+			"never split.",
+	)
+	flags.StringVar(
+		&args.escape,
+		"escape",
+		"_",
+		"Suffix added to names from the model that would otherwise generate Go keywords, "+
+			"predeclared identifiers or identifiers used by the generated code. For "+
+			"example, with the default an attribute named 'type' is stored in a field "+
+			"named 'type_'.",
+	)
+	flags.StringSliceVar(
+		&args.plurals,
+		"plural",
+		[]string{},
+		"Plural form of a word, in the 'singular=plural' format, for example "+
+			"'mesh=meshes'. Overrides the plural calculated by the default rules, "+
+			"which is used in the documentation of the generated code. Can be used "+
+			"multiple times to set the plurals of multiple words.",
+	)
+	flags.StringVar(
+		&args.copyright,
+		"copyright",
+		"",
+		"Copyright notice added to the header of the generated files. If not specified the "+
+			"Red Hat copyright will be used. If empty the copyright notice will be omitted.",
+	)
+	flags.StringVar(
+		&args.licenseFile,
+		"license-file",
+		"",
+		"File containing the license text added to the header of the generated files. If not "+
+			"specified the Apache license will be used. If the file is empty the license "+
+			"will be omitted.",
+	)
+	flags.StringVar(
+		&args.generator,
+		"generator",
+		"",
+		"Name of the generator used in the 'Code generated by ... DO NOT EDIT.' marker "+
+			"added to the header of the generated files. If not specified a generic "+
+			"warning will be added instead of the marker.",
+	)
+	flags.StringVar(
+		&args.marker,
+		"marker",
+		"bottom",
+		"Placement of the marker that indicates that the files have been generated. Can "+
+			"be 'top', to put it in the first line of the file, or 'bottom', to put it "+
+			"after the copyright and license.",
+	)
+	flags.StringSliceVar(
+		&args.buildTags,
+		"build-tags",
+		[]string{},
+		"Build tags that will be required by all the generated files.",
+	)
+	flags.StringVar(
+		&args.templates,
+		"templates",
+		"",
+		"Directory containing templates that will be used instead of the built-in "+
+			"templates of the generators. It should contain a sub-directory for each "+
+			"generator, for example 'types', and inside it a file for each replaced "+
+			"template, for example 'types/struct_type.tmpl'.",
+	)
+	flags.StringVar(
+		&args.modelRevision,
+		"model-revision",
+		"",
+		"Revision of the model, for example a git tag or commit hash, that will be added "+
+			"to the 'GeneratedFrom' constant of the generated version packages. If not "+
+			"specified it will be calculated with 'git describe' in the directory of "+
+			"the model.",
+	)
+	flags.StringArrayVar(
+		&args.runHooks,
+		"hook",
+		[]string{},
+		"Shell command that will be executed after generating all the files. The names of "+
+			"the generated files are passed in the standard input, one per line. Can be "+
+			"used multiple times to run multiple commands.",
+	)
+	flags.StringArrayVar(
+		&args.fileHooks,
+		"file-hook",
+		[]string{},
+		"Shell command that will be executed for each generated file, before the commands "+
+			"specified with '--hook'. The name of the file is passed in the standard input "+
+			"and in the 'METAMODEL_FILE' environment variable. Can be used multiple times "+
+			"to run multiple commands.",
+	)
 }
 
 func run(cmd *cobra.Command, argv []string) {
@@ -75,32 +363,140 @@ func run(cmd *cobra.Command, argv []string) {
 
 	// Check command line options:
 	ok := true
-	if len(args.paths) == 0 {
-		reporter.Errorf("Option '--model' is mandatory")
+	if len(args.paths) == 0 && args.descriptor == "" {
+		reporter.Errorf("Option '--model' or '--descriptor' is mandatory")
 		ok = false
 	}
-	if args.base == "" {
-		reporter.Errorf("Option '--base' is mandatory")
+	if len(args.paths) > 0 && args.descriptor != "" {
+		reporter.Errorf("Options '--model' and '--descriptor' can't be used together")
 		ok = false
 	}
 	if args.output == "" {
 		reporter.Errorf("Option '--output' is mandatory")
 		ok = false
 	}
+	aliasPrefixes := map[string]string{}
+	for _, aliasPrefix := range args.aliasPrefixes {
+		parts := strings.SplitN(aliasPrefix, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			reporter.Errorf(
+				"Value '%s' of option '--alias-prefix' should be in the 'service=Prefix' "+
+					"format",
+				aliasPrefix,
+			)
+			ok = false
+			continue
+		}
+		aliasPrefixes[parts[0]] = parts[1]
+	}
+	if len(aliasPrefixes) > 0 && !args.aliases {
+		reporter.Errorf("Option '--alias-prefix' can only be used together with '--aliases'")
+		ok = false
+	}
+	plurals := map[string]string{}
+	for _, plural := range args.plurals {
+		parts := strings.SplitN(plural, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			reporter.Errorf(
+				"Value '%s' of option '--plural' should be in the 'singular=plural' "+
+					"format",
+				plural,
+			)
+			ok = false
+			continue
+		}
+		plurals[parts[0]] = parts[1]
+	}
 	if !ok {
 		os.Exit(1)
 	}
 
+	// Create the runner for the post generation hooks:
+	hooksRunner, err := hooks.NewRunner().
+		Reporter(reporter).
+		RunHooks(args.runHooks...).
+		FileHooks(args.fileHooks...).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create hooks runner: %v", err)
+		os.Exit(1)
+	}
+
+	// Find the module where the code will be generated, and use it to calculate the base
+	// package if it hasn't been explicitly specified. The module is also needed to check that
+	// its version of Go supports the generic functions. When the '--generics' option isn't
+	// given the module is optional, and without it the generic functions aren't used.
+	var module *golang.Module
+	genericsAuto := !cmd.Flags().Changed("generics")
+	moduleRequired := args.base == "" || args.updateModule || args.generics
+	if moduleRequired || genericsAuto {
+		// This is the version used when the module file doesn't exist. It is only created
+		// when the '--update-module' option is given, otherwise the code is generated for
+		// the oldest version unless the generic functions have been explicitly requested.
+		goVersion := "1.12"
+		if args.generics || (genericsAuto && args.updateModule) {
+			goVersion = genericsGoVersion
+		}
+		module, err = golang.NewModule().
+			Reporter(reporter).
+			Output(args.output).
+			Path(args.module).
+			Go(goVersion).
+			Build()
+		if err != nil && moduleRequired {
+			reporter.Errorf("Can't find Go module: %v", err)
+			os.Exit(1)
+		}
+		if err != nil {
+			module = nil
+		}
+		if args.base == "" {
+			args.base = module.Base()
+		}
+	}
+	if genericsAuto {
+		args.generics = module != nil && module.GoAtLeast(genericsGoVersion)
+	}
+	if args.generics && !module.GoAtLeast(genericsGoVersion) {
+		reporter.Errorf(
+			"Option '--generics' needs Go %s or newer, but the 'go.mod' file of module "+
+				"'%s' requires Go '%s'",
+			genericsGoVersion, module.Path(), module.Go(),
+		)
+		os.Exit(1)
+	}
+
 	// Read the model:
-	model, err := language.NewReader().
+	modelLoader, err := loader.NewLoader().
 		Reporter(reporter).
-		Inputs(args.paths).
-		Read()
+		Paths(args.paths...).
+		Descriptor(args.descriptor).
+		Features(args.features...).
+		Public(args.public).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create model loader: %v", err)
+		os.Exit(1)
+	}
+	end := reporter.Begin("Loading model")
+	model, err := modelLoader.Load()
+	end()
 	if err != nil {
 		reporter.Errorf("Can't read model: %v", err)
 		os.Exit(1)
 	}
 
+	// Calculate the revision of the model:
+	if args.modelRevision == "" && len(args.paths) > 0 {
+		args.modelRevision = modelRevision(args.paths[0])
+		if args.modelRevision == "" {
+			reporter.Warnf(
+				"Can't calculate the git revision of the model, use the " +
+					"'--model-revision' option to set it",
+			)
+		}
+	}
+
 	// Create the calculators:
 	goPackagesCalculator, err := golang.NewPackagesCalculator().
 		Reporter(reporter).
@@ -112,6 +508,8 @@ func run(cmd *cobra.Command, argv []string) {
 	}
 	goNamesCalculator, err := golang.NewNamesCalculator().
 		Reporter(reporter).
+		Escape(args.escape).
+		Plurals(plurals).
 		Build()
 	if err != nil {
 		reporter.Errorf("Can't create Go names calculator: %v", err)
@@ -141,6 +539,57 @@ func run(cmd *cobra.Command, argv []string) {
 		os.Exit(1)
 	}
 
+	// Check that the model doesn't contain concepts that would generate the same Go identifiers,
+	// as that would result in code that doesn't compile:
+	identifiersChecker, err := golang.NewIdentifiersChecker().
+		Reporter(reporter).
+		Names(goNamesCalculator).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create Go identifiers checker: %v", err)
+		os.Exit(1)
+	}
+	err = identifiersChecker.Check(model)
+	if err != nil {
+		reporter.Errorf("Model contains Go identifier collisions: %v", err)
+		os.Exit(1)
+	}
+
+	// Create the header of the generated files:
+	headerBuilder := golang.NewHeader().
+		Generator(args.generator).
+		Marker(args.marker).
+		Tags(args.buildTags...)
+	if cmd.Flags().Changed("copyright") {
+		headerBuilder.Copyright(args.copyright)
+	}
+	if args.licenseFile != "" {
+		license, err := ioutil.ReadFile(args.licenseFile)
+		if err != nil {
+			reporter.Errorf("Can't read license file '%s': %v", args.licenseFile, err)
+			os.Exit(1)
+		}
+		headerBuilder.License(string(license))
+	}
+	header, err := headerBuilder.Build()
+	if err != nil {
+		reporter.Errorf("Can't create header: %v", err)
+		os.Exit(1)
+	}
+
+	// Load the templates that replace the built-in templates:
+	var templates *golang.Templates
+	if args.templates != "" {
+		templates, err = golang.NewTemplates().
+			Reporter(reporter).
+			Dir(args.templates).
+			Build()
+		if err != nil {
+			reporter.Errorf("Can't load templates: %v", err)
+			os.Exit(1)
+		}
+	}
+
 	// We will store here all the code generators that we will later run:
 	var gens []generators.Generator
 	var gen generators.Generator
@@ -152,6 +601,10 @@ func run(cmd *cobra.Command, argv []string) {
 		Output(args.output).
 		Packages(goPackagesCalculator).
 		Names(goNamesCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
 		Build()
 	if err != nil {
 		reporter.Errorf("Can't create errors generator: %v", err)
@@ -166,6 +619,12 @@ func run(cmd *cobra.Command, argv []string) {
 		Output(args.output).
 		Packages(goPackagesCalculator).
 		Names(goNamesCalculator).
+		Binding(bindingCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
+		Generics(args.generics).
 		Build()
 	if err != nil {
 		reporter.Errorf("Can't create helpers generator: %v", err)
@@ -181,6 +640,14 @@ func run(cmd *cobra.Command, argv []string) {
 		Packages(goPackagesCalculator).
 		Names(goNamesCalculator).
 		Types(goTypesCalculator).
+		RawAttributes(args.rawAttributes).
+		ModelRevision(args.modelRevision).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		ListChecks(args.listChecks).
+		Generics(args.generics).
+		Templates(templates).
 		Build()
 	if err != nil {
 		reporter.Errorf("Can't create types generator: %v", err)
@@ -196,6 +663,10 @@ func run(cmd *cobra.Command, argv []string) {
 		Packages(goPackagesCalculator).
 		Names(goNamesCalculator).
 		Types(goTypesCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
 		Build()
 	if err != nil {
 		reporter.Errorf("Can't create builders generator: %v", err)
@@ -203,24 +674,75 @@ func run(cmd *cobra.Command, argv []string) {
 	}
 	gens = append(gens, gen)
 
-	// Create the clients generator:
-	gen, err = golang.NewClientsGenerator().
+	// Create the conversions generator:
+	gen, err = golang.NewConversionsGenerator().
 		Reporter(reporter).
 		Model(model).
 		Output(args.output).
 		Packages(goPackagesCalculator).
 		Names(goNamesCalculator).
 		Types(goTypesCalculator).
-		Binding(bindingCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
 		Build()
 	if err != nil {
-		reporter.Errorf("Can't create clients generator: %v", err)
+		reporter.Errorf("Can't create conversions generator: %v", err)
 		os.Exit(1)
 	}
 	gens = append(gens, gen)
 
+	// Create the clients generator:
+	if args.clients {
+		gen, err = golang.NewClientsGenerator().
+			Reporter(reporter).
+			Model(model).
+			Output(args.output).
+			Packages(goPackagesCalculator).
+			Names(goNamesCalculator).
+			Types(goTypesCalculator).
+			Binding(bindingCalculator).
+			DeprecateNoContext(args.deprecateNoContext).
+			CBOR(args.cbor).
+			Header(header).
+			Stream(args.stream).
+			MaxLines(args.maxLines).
+			Templates(templates).
+			Build()
+		if err != nil {
+			reporter.Errorf("Can't create clients generator: %v", err)
+			os.Exit(1)
+		}
+		gens = append(gens, gen)
+	}
+
 	// Create the resource generator:
-	gen, err = golang.NewServersGenerator().
+	if args.servers {
+		gen, err = golang.NewServersGenerator().
+			Reporter(reporter).
+			Model(model).
+			Output(args.output).
+			Packages(goPackagesCalculator).
+			Names(goNamesCalculator).
+			Types(goTypesCalculator).
+			Binding(bindingCalculator).
+			TrailingSlash(args.trailingSlash).
+			CBOR(args.cbor).
+			Header(header).
+			Stream(args.stream).
+			MaxLines(args.maxLines).
+			Templates(templates).
+			Build()
+		if err != nil {
+			reporter.Errorf("Can't create servers generator: %v", err)
+			os.Exit(1)
+		}
+		gens = append(gens, gen)
+	}
+
+	// Create the JSON readers generator:
+	gen, err = golang.NewJSONSupportGenerator().
 		Reporter(reporter).
 		Model(model).
 		Output(args.output).
@@ -228,29 +750,128 @@ func run(cmd *cobra.Command, argv []string) {
 		Names(goNamesCalculator).
 		Types(goTypesCalculator).
 		Binding(bindingCalculator).
+		RawAttributes(args.rawAttributes).
+		TolerantNumbers(args.tolerantNumbers).
+		LongsAsStrings(args.longsAsStrings).
+		CanonicalJSON(args.canonicalJSON).
+		Clients(args.clients).
+		Servers(args.servers).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
 		Build()
 	if err != nil {
-		reporter.Errorf("Can't create servers generator: %v", err)
+		reporter.Errorf("Can't create JSON readers generator: %v", err)
 		os.Exit(1)
 	}
 	gens = append(gens, gen)
 
-	// Create the JSON readers generator:
-	gen, err = golang.NewJSONSupportGenerator().
+	// Create the CBOR support generator:
+	if args.cbor {
+		gen, err = golang.NewCBORSupportGenerator().
+			Reporter(reporter).
+			Model(model).
+			Output(args.output).
+			Packages(goPackagesCalculator).
+			Names(goNamesCalculator).
+			Types(goTypesCalculator).
+			Binding(bindingCalculator).
+			Clients(args.clients).
+			Servers(args.servers).
+			Header(header).
+			Stream(args.stream).
+			MaxLines(args.maxLines).
+			Templates(templates).
+			Build()
+		if err != nil {
+			reporter.Errorf("Can't create CBOR support generator: %v", err)
+			os.Exit(1)
+		}
+		gens = append(gens, gen)
+	}
+
+	// Create the events generator:
+	gen, err = golang.NewEventsGenerator().
 		Reporter(reporter).
 		Model(model).
 		Output(args.output).
 		Packages(goPackagesCalculator).
 		Names(goNamesCalculator).
 		Types(goTypesCalculator).
+		CBOR(args.cbor).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create events generator: %v", err)
+		os.Exit(1)
+	}
+	gens = append(gens, gen)
+
+	// Create the JSON tests generator:
+	gen, err = golang.NewJSONTestsGenerator().
+		Reporter(reporter).
+		Model(model).
+		Output(args.output).
+		Packages(goPackagesCalculator).
+		Names(goNamesCalculator).
 		Binding(bindingCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
+		LongsAsStrings(args.longsAsStrings).
+		RawAttributes(args.rawAttributes).
 		Build()
 	if err != nil {
-		reporter.Errorf("Can't create JSON readers generator: %v", err)
+		reporter.Errorf("Can't create JSON tests generator: %v", err)
+		os.Exit(1)
+	}
+	gens = append(gens, gen)
+
+	// Create the descriptors generator:
+	gen, err = golang.NewDescriptorsGenerator().
+		Reporter(reporter).
+		Model(model).
+		Output(args.output).
+		Packages(goPackagesCalculator).
+		Binding(bindingCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
+		Build()
+	if err != nil {
+		reporter.Errorf("Can't create descriptors generator: %v", err)
 		os.Exit(1)
 	}
 	gens = append(gens, gen)
 
+	// Create the aliases generator:
+	if args.aliases {
+		gen, err = golang.NewAliasesGenerator().
+			Reporter(reporter).
+			Model(model).
+			Output(args.output).
+			Packages(goPackagesCalculator).
+			Names(goNamesCalculator).
+			Types(goTypesCalculator).
+			Prefixes(aliasPrefixes).
+			Header(header).
+			Stream(args.stream).
+			MaxLines(args.maxLines).
+			Templates(templates).
+			Build()
+		if err != nil {
+			reporter.Errorf("Can't create aliases generator: %v", err)
+			os.Exit(1)
+		}
+		gens = append(gens, gen)
+	}
+
 	// Create the OpenAPI specifications generator:
 	gen, err = golang.NewOpenAPIGenerator().
 		Reporter(reporter).
@@ -259,6 +880,10 @@ func run(cmd *cobra.Command, argv []string) {
 		Packages(goPackagesCalculator).
 		Names(openapiNamesCalculator).
 		Binding(bindingCalculator).
+		Header(header).
+		Stream(args.stream).
+		MaxLines(args.maxLines).
+		Templates(templates).
 		Build()
 	if err != nil {
 		reporter.Errorf("Can't create OpenAPI specifications generator: %v", err)
@@ -268,13 +893,58 @@ func run(cmd *cobra.Command, argv []string) {
 
 	// Run the generators:
 	for _, gen := range gens {
+		end = reporter.Begin("Running generator '%s'", generators.Name(gen))
 		err = gen.Run()
+		end()
 		if err != nil {
 			reporter.Errorf("Generation failed: %v", err)
 			os.Exit(1)
 		}
 	}
 
-	// Bye:
-	os.Exit(0)
+	// Warn about templates that weren't used, as they are probably misspelled:
+	for _, key := range templates.Unused() {
+		reporter.Warnf("Template '%s' wasn't used by any generator", key)
+	}
+
+	// Update the module file:
+	if args.updateModule {
+		err = module.Update()
+		if err != nil {
+			reporter.Errorf("Can't update Go module: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// Run the post generation hooks:
+	if len(args.runHooks) > 0 || len(args.fileHooks) > 0 {
+		end = reporter.Begin("Running hooks")
+		err = hooksRunner.Run(reporter.Files())
+		end()
+		if err != nil {
+			reporter.Errorf("Hooks failed: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	// Print the summary of the time taken and the files generated by each step:
+	reporter.Summary()
+}
+
+// modelRevision uses 'git describe' to calculate the revision of the repository that contains the
+// given model file or directory. It returns an empty string if the revision can't be calculated,
+// for example if the model isn't inside a git repository.
+func modelRevision(path string) string {
+	dir := path
+	info, err := os.Stat(path)
+	if err == nil && !info.IsDir() {
+		dir = filepath.Dir(path)
+	}
+	gitCmd := exec.Command("git", "describe", "--tags", "--always", "--dirty")
+	gitCmd.Dir = dir
+	output, err := gitCmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
 }
@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the flags and functions used to profile the generators.
+
+package generate
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+
+	"github.com/spf13/cobra"
+)
+
+// Values of the command line arguments that control profiling:
+var profileArgs struct {
+	cpuProfile string
+	memProfile string
+	trace      string
+}
+
+// Files where the CPU profile and the execution trace are being written:
+var (
+	cpuProfileFile *os.File
+	traceFile      *os.File
+)
+
+func init() {
+	flags := Cmd.PersistentFlags()
+	flags.StringVar(
+		&profileArgs.cpuProfile,
+		"cpuprofile",
+		"",
+		"Write a CPU profile to this file. It can be analyzed with 'go tool pprof'. The "+
+			"profile is only written if the generation finishes successfully.",
+	)
+	flags.StringVar(
+		&profileArgs.memProfile,
+		"memprofile",
+		"",
+		"Write a memory profile to this file. It can be analyzed with 'go tool pprof'. "+
+			"The profile is only written if the generation finishes successfully.",
+	)
+	flags.StringVar(
+		&profileArgs.trace,
+		"trace",
+		"",
+		"Write an execution trace to this file. It can be analyzed with 'go tool trace'. "+
+			"The trace is only written if the generation finishes successfully.",
+	)
+	Cmd.PersistentPreRunE = startProfiling
+	Cmd.PersistentPostRunE = stopProfiling
+}
+
+// startProfiling starts the CPU profile and the execution trace, if they have been requested.
+func startProfiling(cmd *cobra.Command, argv []string) error {
+	var err error
+	if profileArgs.cpuProfile != "" {
+		cpuProfileFile, err = os.Create(profileArgs.cpuProfile)
+		if err != nil {
+			return fmt.Errorf("can't create CPU profile file: %v", err)
+		}
+		err = pprof.StartCPUProfile(cpuProfileFile)
+		if err != nil {
+			return fmt.Errorf("can't start CPU profile: %v", err)
+		}
+	}
+	if profileArgs.trace != "" {
+		traceFile, err = os.Create(profileArgs.trace)
+		if err != nil {
+			return fmt.Errorf("can't create trace file: %v", err)
+		}
+		err = trace.Start(traceFile)
+		if err != nil {
+			return fmt.Errorf("can't start trace: %v", err)
+		}
+	}
+	return nil
+}
+
+// stopProfiling stops the CPU profile and the execution trace, and writes the memory profile, if
+// they have been requested.
+func stopProfiling(cmd *cobra.Command, argv []string) error {
+	if cpuProfileFile != nil {
+		pprof.StopCPUProfile()
+		err := cpuProfileFile.Close()
+		if err != nil {
+			return fmt.Errorf("can't close CPU profile file: %v", err)
+		}
+	}
+	if traceFile != nil {
+		trace.Stop()
+		err := traceFile.Close()
+		if err != nil {
+			return fmt.Errorf("can't close trace file: %v", err)
+		}
+	}
+	if profileArgs.memProfile != "" {
+		memProfileFile, err := os.Create(profileArgs.memProfile)
+		if err != nil {
+			return fmt.Errorf("can't create memory profile file: %v", err)
+		}
+		defer memProfileFile.Close()
+
+		// Run the garbage collector so that the profile contains up to date statistics:
+		runtime.GC()
+		err = pprof.WriteHeapProfile(memProfileFile)
+		if err != nil {
+			return fmt.Errorf("can't write memory profile: %v", err)
+		}
+	}
+	return nil
+}
@@ -25,7 +25,9 @@ import (
 	"github.com/spf13/pflag"
 
 	"github.com/openshift-online/ocm-api-metamodel/cmd/check"
+	"github.com/openshift-online/ocm-api-metamodel/cmd/export"
 	"github.com/openshift-online/ocm-api-metamodel/cmd/generate"
+	"github.com/openshift-online/ocm-api-metamodel/cmd/releases"
 	"github.com/openshift-online/ocm-api-metamodel/cmd/version"
 )
 
@@ -42,7 +44,9 @@ func init() {
 
 	// Register the sub-commands:
 	root.AddCommand(check.Cmd)
+	root.AddCommand(export.Cmd)
 	root.AddCommand(generate.Cmd)
+	root.AddCommand(releases.Cmd)
 	root.AddCommand(version.Cmd)
 }
 
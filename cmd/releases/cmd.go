@@ -0,0 +1,176 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package releases
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/language"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// Cmd is the definition of the command:
+var Cmd = &cobra.Command{
+	Use:   "releases",
+	Short: "Lists the features introduced in each release",
+	Long: "Lists the attributes and methods of a model grouped by the version of the API " +
+		"where they were introduced, as indicated by the '@since' annotations.",
+	Run: run,
+}
+
+// Values of the command line arguments:
+var args struct {
+	paths    []string
+	features []string
+	public   bool
+}
+
+func init() {
+	flags := Cmd.Flags()
+	flags.StringSliceVar(
+		&args.paths,
+		"model",
+		[]string{},
+		"File or directory containing the model. If it is a directory then all .model"+
+			"files inside it and its sub directories will be loaded. If used "+
+			"multiple times then all the specified files and directories will be "+
+			"loaded, in the same order that they appear in the command line.",
+	)
+	flags.StringSliceVar(
+		&args.features,
+		"enable-feature",
+		[]string{},
+		"Name of an experimental feature to enable. Attributes and methods marked with "+
+			"the '@experimental' annotation are only included when the corresponding "+
+			"feature is enabled. Can be used multiple times to enable multiple features.",
+	)
+	flags.BoolVar(
+		&args.public,
+		"public",
+		false,
+		"Remove the types and resources marked with the '@internal' annotation, so that "+
+			"only the features of the public part of the model are listed.",
+	)
+}
+
+func run(cmd *cobra.Command, argv []string) {
+	// Create the reporter:
+	reporter := reporter.NewReporter()
+
+	// Check command line options:
+	ok := true
+	if len(args.paths) == 0 {
+		reporter.Errorf("Option '--model' is mandatory")
+		ok = false
+	}
+	if !ok {
+		os.Exit(1)
+	}
+
+	// Read the model:
+	model, err := language.NewReader().
+		Reporter(reporter).
+		Inputs(args.paths).
+		Features(args.features).
+		Public(args.public).
+		Read()
+	if err != nil {
+		reporter.Errorf("Can't read model: %v", err)
+		os.Exit(1)
+	}
+
+	// Collect the features grouped by release:
+	features := map[string][]string{}
+	for _, service := range model.Services() {
+		for _, version := range service.Versions() {
+			prefix := fmt.Sprintf("%s/%s", service.Name(), version.Name())
+			for _, typ := range version.Types() {
+				for _, attribute := range typ.Attributes() {
+					if attribute.Since() == "" {
+						continue
+					}
+					features[attribute.Since()] = append(
+						features[attribute.Since()],
+						fmt.Sprintf("attribute %s.%s.%s", prefix, typ.Name(), attribute.Name()),
+					)
+				}
+			}
+			for _, resource := range version.Resources() {
+				for _, method := range resource.Methods() {
+					if method.Since() == "" {
+						continue
+					}
+					features[method.Since()] = append(
+						features[method.Since()],
+						fmt.Sprintf("method %s.%s.%s", prefix, resource.Name(), method.Name()),
+					)
+				}
+			}
+		}
+	}
+
+	// Print the features, starting with the oldest release:
+	releases := make([]string, 0, len(features))
+	for release := range features {
+		releases = append(releases, release)
+	}
+	sortReleases(releases)
+	for _, release := range releases {
+		fmt.Fprintf(os.Stdout, "%s:\n", release)
+		items := features[release]
+		sort.Strings(items)
+		for _, item := range items {
+			fmt.Fprintf(os.Stdout, "  %s\n", item)
+		}
+	}
+
+	// Bye:
+	os.Exit(0)
+}
+
+// sortReleases sorts the given release strings, starting with the oldest release.
+func sortReleases(releases []string) {
+	sort.Slice(releases, func(i, j int) bool {
+		return compareReleases(releases[i], releases[j]) < 0
+	})
+}
+
+// compareReleases compares two release strings like '1.2' or '1.10.3', comparing numerically the
+// parts that are numbers.
+func compareReleases(a, b string) int {
+	aParts := strings.Split(a, ".")
+	bParts := strings.Split(b, ".")
+	for i := 0; i < len(aParts) && i < len(bParts); i++ {
+		aNumber, aErr := strconv.Atoi(aParts[i])
+		bNumber, bErr := strconv.Atoi(bParts[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				return aNumber - bNumber
+			}
+		case aParts[i] != bParts[i]:
+			return strings.Compare(aParts[i], bParts[i])
+		}
+	}
+	return len(aParts) - len(bParts)
+}
@@ -0,0 +1,62 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the comparison and sorting of releases.
+
+package releases
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Releases", func() {
+	DescribeTable(
+		"Compares releases",
+		func(a, b string, expected int) {
+			actual := compareReleases(a, b)
+			switch {
+			case expected < 0:
+				Expect(actual).To(BeNumerically("<", 0))
+			case expected > 0:
+				Expect(actual).To(BeNumerically(">", 0))
+			default:
+				Expect(actual).To(BeZero())
+			}
+		},
+		Entry("Equal", "1.2", "1.2", 0),
+		Entry("Smaller major", "1.2", "2.0", -1),
+		Entry("Greater minor", "1.3", "1.2", 1),
+		Entry("Numeric instead of lexical", "1.2", "1.10", -1),
+		Entry("Shorter is older", "1.2", "1.2.1", -1),
+		Entry("Longer is newer", "1.2.1", "1.2", 1),
+		Entry("Non numeric parts", "1.beta", "1.alpha", 1),
+		Entry("Numeric part and non numeric part", "1.2", "1.x", -1),
+	)
+
+	It("Sorts releases starting with the oldest", func() {
+		releases := []string{"1.10", "2.0", "1.2.1", "1.2", "1.9"}
+		sortReleases(releases)
+		Expect(releases).To(Equal([]string{"1.2", "1.2.1", "1.9", "1.10", "2.0"}))
+	})
+
+	It("Sorts an empty list", func() {
+		releases := []string{}
+		sortReleases(releases)
+		Expect(releases).To(BeEmpty())
+	})
+})
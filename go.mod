@@ -12,4 +12,5 @@ require (
 	github.com/openshift-online/ocm-sdk-go v0.1.81
 	github.com/spf13/cobra v0.0.5
 	github.com/spf13/pflag v1.0.3
+	golang.org/x/mod v0.3.0
 )
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 	"unicode"
 
@@ -45,6 +47,7 @@ type Buffer struct {
 	pkg       string
 	file      string
 	functions map[string]interface{}
+	key       string
 	imports   map[string]interface{}
 	code      *bytes.Buffer
 }
@@ -106,6 +109,7 @@ func (b *BufferBuilder) Build() (buffer *Buffer, err error) {
 	for name, function := range b.functions {
 		buffer.functions[name] = function
 	}
+	buffer.key = buffer.functionsKey()
 	buffer.code = new(bytes.Buffer)
 
 	return
@@ -168,10 +172,8 @@ func (b *Buffer) Emit(tmpl string, args ...interface{}) {
 		data[name] = value
 	}
 
-	// Parse the template:
-	obj, err := template.New("").
-		Funcs(b.functions).
-		Parse(tmpl)
+	// Get the parsed template:
+	obj, err := b.parse(tmpl)
 	if err != nil {
 		b.reporter.Errorf("Can't parse template '%s': %v", tmpl, err)
 		return
@@ -197,6 +199,51 @@ func (b *Buffer) Emit(tmpl string, args ...interface{}) {
 	b.code.WriteString("\n")
 }
 
+// parse returns the parsed template for the given text, bound to the functions of this buffer.
+// The parsed templates are saved in a cache shared by all the buffers, as parsing them is
+// expensive and the same templates are used many times.
+func (b *Buffer) parse(text string) (result *template.Template, err error) {
+	key := b.key + "\x00" + text
+	templateCacheLock.Lock()
+	parsed, ok := templateCache[key]
+	templateCacheLock.Unlock()
+	if !ok {
+		parsed, err = template.New("").
+			Funcs(b.functions).
+			Parse(text)
+		if err != nil {
+			return
+		}
+		templateCacheLock.Lock()
+		templateCache[key] = parsed
+		templateCacheLock.Unlock()
+	}
+	result, err = parsed.Clone()
+	if err != nil {
+		return
+	}
+	result.Funcs(b.functions)
+	return
+}
+
+// functionsKey calculates the part of the key of the templates cache that corresponds to the
+// names of the functions of this buffer.
+func (b *Buffer) functionsKey() string {
+	names := make([]string, 0, len(b.functions))
+	for name := range b.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// templateCache contains the templates that have already been parsed, indexed by the names of
+// the functions and the text of the template.
+var (
+	templateCache     = map[string]*template.Template{}
+	templateCacheLock = &sync.Mutex{}
+)
+
 // Write creates the output file and writes the generated content.
 func (b *Buffer) Write() error {
 	var err error
@@ -245,6 +292,7 @@ func (b *Buffer) Write() error {
 	if err != nil {
 		return fmt.Errorf("can't close output file '%s': %v", b.file, err)
 	}
+	b.reporter.Written(b.file)
 
 	return nil
 }
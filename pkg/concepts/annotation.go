@@ -0,0 +1,126 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package concepts
+
+import (
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+)
+
+// Annotation represents an annotation like '@since("1.1")' or '@internal' added to a concept of
+// the model. Annotations that the metamodel doesn't understand are kept as well, so that custom
+// generators can use them as directives.
+type Annotation struct {
+	name  *names.Name
+	value interface{}
+}
+
+// NewAnnotation creates a new annotation.
+func NewAnnotation() *Annotation {
+	return new(Annotation)
+}
+
+// Name returns the name of the annotation.
+func (a *Annotation) Name() *names.Name {
+	return a.name
+}
+
+// SetName sets the name of the annotation.
+func (a *Annotation) SetName(value *names.Name) {
+	a.name = value
+}
+
+// Value returns the value of the annotation. It will be a boolean, an integer, a string or nil if
+// the annotation doesn't have a value.
+func (a *Annotation) Value() interface{} {
+	return a.value
+}
+
+// SetValue sets the value of the annotation.
+func (a *Annotation) SetValue(value interface{}) {
+	a.value = value
+}
+
+// HasValue returns true if the annotation has a value.
+func (a *Annotation) HasValue() bool {
+	return a.value != nil
+}
+
+// StringValue returns the value of the annotation and a flag indicating if the annotation has a
+// value and it is a string.
+func (a *Annotation) StringValue() (value string, ok bool) {
+	value, ok = a.value.(string)
+	return
+}
+
+// IntValue returns the value of the annotation and a flag indicating if the annotation has a
+// value and it is an integer.
+func (a *Annotation) IntValue() (value int, ok bool) {
+	value, ok = a.value.(int)
+	return
+}
+
+// BoolValue returns the value of the annotation and a flag indicating if the annotation has a
+// value and it is a boolean.
+func (a *Annotation) BoolValue() (value bool, ok bool) {
+	value, ok = a.value.(bool)
+	return
+}
+
+// AnnotationSlice is used to represent a slice of annotations.
+type AnnotationSlice []*Annotation
+
+// Annotated is implemented by concepts that can have annotations.
+type Annotated interface {
+	Annotations() AnnotationSlice
+	FindAnnotation(name *names.Name) *Annotation
+	HasAnnotation(name *names.Name) bool
+}
+
+// annotations contains the implementation of the methods of the Annotated interface. It is
+// intended to be embedded in the concepts that support annotations.
+type annotations struct {
+	list AnnotationSlice
+}
+
+// Annotations returns the annotations of the concept, in the same order that they appear in
+// the model.
+func (a *annotations) Annotations() AnnotationSlice {
+	return a.list
+}
+
+// AddAnnotation adds an annotation to the concept.
+func (a *annotations) AddAnnotation(annotation *Annotation) {
+	if annotation != nil {
+		a.list = append(a.list, annotation)
+	}
+}
+
+// FindAnnotation returns the annotation with the given name, or nil if there is no such
+// annotation. If there are multiple annotations with the same name it returns the last one.
+func (a *annotations) FindAnnotation(name *names.Name) *Annotation {
+	for i := len(a.list) - 1; i >= 0; i-- {
+		if a.list[i].Name().Equals(name) {
+			return a.list[i]
+		}
+	}
+	return nil
+}
+
+// HasAnnotation returns true if the concept has an annotation with the given name.
+func (a *annotations) HasAnnotation(name *names.Name) bool {
+	return a.FindAnnotation(name) != nil
+}
@@ -0,0 +1,82 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the annotations.
+
+package concepts
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+)
+
+var _ = Describe("Annotation", func() {
+	It("Returns typed values", func() {
+		annotation := NewAnnotation()
+		annotation.SetValue("my_value")
+		Expect(annotation.HasValue()).To(BeTrue())
+		text, ok := annotation.StringValue()
+		Expect(ok).To(BeTrue())
+		Expect(text).To(Equal("my_value"))
+		_, ok = annotation.IntValue()
+		Expect(ok).To(BeFalse())
+		_, ok = annotation.BoolValue()
+		Expect(ok).To(BeFalse())
+
+		annotation.SetValue(42)
+		number, ok := annotation.IntValue()
+		Expect(ok).To(BeTrue())
+		Expect(number).To(Equal(42))
+
+		annotation.SetValue(true)
+		flag, ok := annotation.BoolValue()
+		Expect(ok).To(BeTrue())
+		Expect(flag).To(BeTrue())
+	})
+
+	It("Doesn't have a value by default", func() {
+		annotation := NewAnnotation()
+		Expect(annotation.HasValue()).To(BeFalse())
+		_, ok := annotation.StringValue()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Can be added to and found in a type", func() {
+		first := NewAnnotation()
+		first.SetName(names.ParseUsingCase("Label"))
+		first.SetValue("first")
+		second := NewAnnotation()
+		second.SetName(names.ParseUsingCase("Label"))
+		second.SetValue("second")
+		typ := NewType()
+		typ.AddAnnotation(first)
+		typ.AddAnnotation(second)
+		Expect(typ.Annotations()).To(HaveLen(2))
+		Expect(typ.HasAnnotation(names.ParseUsingCase("Label"))).To(BeTrue())
+		Expect(typ.HasAnnotation(names.ParseUsingCase("Other"))).To(BeFalse())
+		Expect(typ.FindAnnotation(names.ParseUsingCase("Label"))).To(BeIdenticalTo(second))
+	})
+
+	It("Is supported by attributes, resources and methods", func() {
+		var annotated []Annotated
+		annotated = append(annotated, NewAttribute(), NewResource(), NewMethod())
+		for _, item := range annotated {
+			Expect(item.Annotations()).To(BeEmpty())
+		}
+	})
+})
@@ -22,11 +22,24 @@ import (
 
 // Attribute is the representation of an attribute of an structured type.
 type Attribute struct {
-	owner *Type
-	doc   string
-	name  *names.Name
-	link  bool
-	typ   *Type
+	annotations
+
+	owner     *Type
+	doc       string
+	name      *names.Name
+	link      bool
+	typ       *Type
+	min       *int
+	max       *int
+	pattern   string
+	immutable bool
+	readOnly  bool
+	lazy      bool
+	format    string
+	always    bool
+	sensitive bool
+	since     string
+	feature   string
 }
 
 // NewAttribute creates a new attribute.
@@ -84,6 +97,155 @@ func (a *Attribute) SetType(value *Type) {
 	a.typ = value
 }
 
+// Min returns the minimum value of the attribute, or nil if there is no minimum. For strings and
+// lists this is the minimum length.
+func (a *Attribute) Min() *int {
+	return a.min
+}
+
+// SetMin sets the minimum value of the attribute.
+func (a *Attribute) SetMin(value *int) {
+	a.min = value
+}
+
+// Max returns the maximum value of the attribute, or nil if there is no maximum. For strings and
+// lists this is the maximum length.
+func (a *Attribute) Max() *int {
+	return a.max
+}
+
+// SetMax sets the maximum value of the attribute.
+func (a *Attribute) SetMax(value *int) {
+	a.max = value
+}
+
+// Pattern returns the regular expression that the values of the attribute must match, or an
+// empty string if there is no such pattern.
+func (a *Attribute) Pattern() string {
+	return a.pattern
+}
+
+// SetPattern sets the regular expression that the values of the attribute must match.
+func (a *Attribute) SetPattern(value string) {
+	a.pattern = value
+}
+
+// Immutable returns true if the value of the attribute can't be changed once the object has been
+// created.
+func (a *Attribute) Immutable() bool {
+	return a.immutable
+}
+
+// SetImmutable sets the flag that indicates if the value of the attribute can't be changed once
+// the object has been created.
+func (a *Attribute) SetImmutable(value bool) {
+	a.immutable = value
+}
+
+// ReadOnly returns true if the value of the attribute is populated by the server and shouldn't be
+// sent by clients.
+func (a *Attribute) ReadOnly() bool {
+	return a.readOnly
+}
+
+// SetReadOnly sets the flag that indicates if the value of the attribute is populated by the server
+// and shouldn't be sent by clients.
+func (a *Attribute) SetReadOnly(value bool) {
+	a.readOnly = value
+}
+
+// Lazy returns true if the value of the attribute should be kept as raw JSON when reading the
+// object, and decoded only when it is accessed for the first time.
+func (a *Attribute) Lazy() bool {
+	return a.lazy
+}
+
+// SetLazy sets the flag that indicates if the value of the attribute should be decoded only when it
+// is accessed for the first time.
+func (a *Attribute) SetLazy(value bool) {
+	a.lazy = value
+}
+
+// Format returns the format used to represent the values of the attribute in JSON documents. This
+// is only used for dates, and should be one of the DateFormat... constants. An empty string means
+// that the default format will be used.
+func (a *Attribute) Format() string {
+	return a.format
+}
+
+// SetFormat sets the format used to represent the values of the attribute in JSON documents.
+func (a *Attribute) SetFormat(value string) {
+	a.format = value
+}
+
+// Always returns true if the attribute should always be written when serializing the object,
+// using the zero value of its type when it hasn't been set.
+func (a *Attribute) Always() bool {
+	return a.always
+}
+
+// SetAlways sets the flag that indicates if the attribute should always be written when
+// serializing the object.
+func (a *Attribute) SetAlways(value bool) {
+	a.always = value
+}
+
+// Sensitive returns true if the value of the attribute contains secrets, like passwords or tokens,
+// that shouldn't appear in logs or audit records.
+func (a *Attribute) Sensitive() bool {
+	return a.sensitive
+}
+
+// SetSensitive sets the flag that indicates if the value of the attribute contains secrets.
+func (a *Attribute) SetSensitive(value bool) {
+	a.sensitive = value
+}
+
+// Since returns the version of the API where the attribute was introduced, or an empty string if
+// that isn't known.
+func (a *Attribute) Since() string {
+	return a.since
+}
+
+// SetSince sets the version of the API where the attribute was introduced.
+func (a *Attribute) SetSince(value string) {
+	a.since = value
+}
+
+// Feature returns the name of the experimental feature that the attribute belongs to, or an empty
+// string if the attribute isn't experimental.
+func (a *Attribute) Feature() string {
+	return a.feature
+}
+
+// SetFeature sets the name of the experimental feature that the attribute belongs to.
+func (a *Attribute) SetFeature(value string) {
+	a.feature = value
+}
+
+// Constrained returns true if the attribute has any constraint for its values.
+func (a *Attribute) Constrained() bool {
+	return a.min != nil || a.max != nil || a.pattern != ""
+}
+
+// Formats that can be used for the values of date attributes:
+const (
+	// DateFormatRFC3339 is the default format, a string like '2019-07-14T15:16:17Z'.
+	DateFormatRFC3339 = "rfc3339"
+
+	// DateFormatRFC3339Nano is like RFC 3339, but always with nanoseconds.
+	DateFormatRFC3339Nano = "rfc3339nano"
+
+	// DateFormatDate is a string containing only the date, like '2019-07-14'.
+	DateFormatDate = "date"
+
+	// DateFormatUnix is a number containing the seconds since the Unix epoch.
+	DateFormatUnix = "unix"
+
+	// DateFormatUnixMillis is a number containing the milliseconds since the Unix epoch.
+	DateFormatUnixMillis = "unix_millis"
+)
+
 // AttributeSlice is used to simplify sorting of slices of attributes by name.
 type AttributeSlice []*Attribute
 
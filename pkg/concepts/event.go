@@ -0,0 +1,102 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package concepts
+
+import (
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+)
+
+// Event is the representation of a notification sent when an object changes. The payload of the
+// event is a class that contains the action, the path of the resource and the states of the
+// object before and after the change.
+type Event struct {
+	owner   *Version
+	doc     string
+	name    *names.Name
+	object  *Type
+	payload *Type
+}
+
+// NewEvent creates a new event.
+func NewEvent() *Event {
+	return new(Event)
+}
+
+// Owner returns the version that owns this event.
+func (e *Event) Owner() *Version {
+	return e.owner
+}
+
+// SetOwner sets the version that owns this event.
+func (e *Event) SetOwner(version *Version) {
+	e.owner = version
+}
+
+// Doc returns the documentation of this event.
+func (e *Event) Doc() string {
+	return e.doc
+}
+
+// SetDoc sets the documentation of this event.
+func (e *Event) SetDoc(value string) {
+	e.doc = value
+}
+
+// Name returns the name of this event.
+func (e *Event) Name() *names.Name {
+	return e.name
+}
+
+// SetName sets the name of this event.
+func (e *Event) SetName(value *names.Name) {
+	e.name = value
+}
+
+// Object returns the type of the objects whose changes are notified by this event.
+func (e *Event) Object() *Type {
+	return e.object
+}
+
+// SetObject sets the type of the objects whose changes are notified by this event.
+func (e *Event) SetObject(value *Type) {
+	e.object = value
+}
+
+// Payload returns the class that is sent when this event happens.
+func (e *Event) Payload() *Type {
+	return e.payload
+}
+
+// SetPayload sets the class that is sent when this event happens.
+func (e *Event) SetPayload(value *Type) {
+	e.payload = value
+}
+
+// EventSlice is used to simplify sorting of slices of events by name.
+type EventSlice []*Event
+
+func (s EventSlice) Len() int {
+	return len(s)
+}
+
+func (s EventSlice) Less(i, j int) bool {
+	return names.Compare(s[i].name, s[j].name) == -1
+}
+
+func (s EventSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package concepts
+
+// ExampleKind represents the kind of an example.
+type ExampleKind string
+
+const (
+	// GenericExample is an example that isn't specific to requests or responses.
+	GenericExample ExampleKind = ""
+
+	// RequestExample is an example of the body of a request.
+	RequestExample ExampleKind = "request"
+
+	// ResponseExample is an example of the body of a response.
+	ResponseExample ExampleKind = "response"
+)
+
+// Example represents an example of how to use a method. Examples are extracted from the
+// documentation of the method.
+type Example struct {
+	kind    ExampleKind
+	summary string
+	text    string
+}
+
+// NewExample creates a new example.
+func NewExample() *Example {
+	return new(Example)
+}
+
+// Kind returns the kind of the example.
+func (e *Example) Kind() ExampleKind {
+	return e.kind
+}
+
+// SetKind sets the kind of the example.
+func (e *Example) SetKind(value ExampleKind) {
+	e.kind = value
+}
+
+// IsRequest returns true if this is an example of the body of a request.
+func (e *Example) IsRequest() bool {
+	return e.kind == RequestExample
+}
+
+// IsResponse returns true if this is an example of the body of a response.
+func (e *Example) IsResponse() bool {
+	return e.kind == ResponseExample
+}
+
+// Summary returns the short description of the example. It may be empty.
+func (e *Example) Summary() string {
+	return e.summary
+}
+
+// SetSummary sets the short description of the example.
+func (e *Example) SetSummary(value string) {
+	e.summary = value
+}
+
+// Text returns the text of the example, for example the JSON document sent or received.
+func (e *Example) Text() string {
+	return e.text
+}
+
+// SetText sets the text of the example.
+func (e *Example) SetText(value string) {
+	e.text = value
+}
@@ -74,7 +74,7 @@ func (l *Locator) Variable() bool {
 // Variable sets the flag that indicates if the name of the referenced resource is a variable
 // instead of a fixed URL segment.
 func (l *Locator) SetVariable(value bool) {
-	l.variable = true
+	l.variable = value
 }
 
 // Target returns the resource that is referenced by the locator.
@@ -25,10 +25,15 @@ import (
 
 // Method represents a method of a resource.
 type Method struct {
+	annotations
+
 	owner      *Resource
 	doc        string
 	name       *names.Name
 	parameters ParameterSlice
+	examples   []*Example
+	since      string
+	feature    string
 }
 
 // NewMethod creates a new method.
@@ -56,6 +61,40 @@ func (m *Method) SetDoc(value string) {
 	m.doc = value
 }
 
+// Examples returns the examples of the method, in the order they appear in the documentation.
+func (m *Method) Examples() []*Example {
+	return m.examples
+}
+
+// AddExample adds an example to the method.
+func (m *Method) AddExample(example *Example) {
+	if example != nil {
+		m.examples = append(m.examples, example)
+	}
+}
+
+// Since returns the version of the API where the method was introduced, or an empty string if
+// that isn't known.
+func (m *Method) Since() string {
+	return m.since
+}
+
+// SetSince sets the version of the API where the method was introduced.
+func (m *Method) SetSince(value string) {
+	m.since = value
+}
+
+// Feature returns the name of the experimental feature that the method belongs to, or an empty
+// string if the method isn't experimental.
+func (m *Method) Feature() string {
+	return m.feature
+}
+
+// SetFeature sets the name of the experimental feature that the method belongs to.
+func (m *Method) SetFeature(value string) {
+	m.feature = value
+}
+
 // Name returns the name of the method.
 func (m *Method) Name() *names.Name {
 	return m.name
@@ -124,6 +163,30 @@ func (m *Method) IsUpdate() bool {
 	return m.name.Equals(nomenclator.Update)
 }
 
+// IsBulkAdd returns true if this is a method that adds multiple objects with one request.
+func (m *Method) IsBulkAdd() bool {
+	return m.name.Equals(nomenclator.BulkAdd)
+}
+
+// IsBulkDelete returns true if this is a method that deletes multiple objects with one request.
+func (m *Method) IsBulkDelete() bool {
+	return m.name.Equals(nomenclator.BulkDelete)
+}
+
+// IsPing returns true if this is a method that clients use to check the connection with the
+// server. Ping methods are actions without input parameters, and they always have output
+// parameters named `time` and `version` containing the time and version of the server.
+func (m *Method) IsPing() bool {
+	return m.name.Equals(nomenclator.Ping)
+}
+
+// IsBulk returns true if this is a method that processes multiple objects with one request. Bulk
+// methods are actions, so they are sent and received like any other action, but they always have
+// an output parameter named `results` containing the result of processing each object.
+func (m *Method) IsBulk() bool {
+	return m.IsBulkAdd() || m.IsBulkDelete()
+}
+
 // IsAction determined if this method is an action instead of a regular REST method.
 func (m *Method) IsAction() bool {
 	switch {
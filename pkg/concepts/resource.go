@@ -24,11 +24,14 @@ import (
 
 // Resource represents an API resource.
 type Resource struct {
+	annotations
+
 	owner    *Version
 	doc      string
 	name     *names.Name
 	methods  MethodSlice
 	locators LocatorSlice
+	internal bool
 }
 
 // NewResource creates a new resource.
@@ -66,6 +69,17 @@ func (r *Resource) SetName(value *names.Name) {
 	r.name = value
 }
 
+// Internal returns true if this resource has been marked as internal, so that it isn't part of
+// the public API.
+func (r *Resource) Internal() bool {
+	return r.internal
+}
+
+// SetInternal sets the flag that indicates if this resource is internal.
+func (r *Resource) SetInternal(value bool) {
+	r.internal = value
+}
+
 // Methods returns the methods of the resource.
 func (r *Resource) Methods() MethodSlice {
 	return r.methods
@@ -126,6 +140,16 @@ func (r *Resource) AddLocator(locator *Locator) {
 	}
 }
 
+// RemoveLocator removes the given locator from the resource.
+func (r *Resource) RemoveLocator(locator *Locator) {
+	for i, current := range r.locators {
+		if current == locator {
+			r.locators = append(r.locators[:i], r.locators[i+1:]...)
+			return
+		}
+	}
+}
+
 // IsRoot returns `true` if this is the root of the tree of resources of the version.
 func (r *Resource) IsRoot() bool {
 	return r.owner != nil && r == r.owner.Root()
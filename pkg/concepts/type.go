@@ -65,6 +65,8 @@ func NewType() *Type {
 
 // Type specifies the data type of attributes of structs and method parameters.
 type Type struct {
+	annotations
+
 	owner      *Version
 	doc        string
 	kind       TypeKind
@@ -73,6 +75,7 @@ type Type struct {
 	values     EnumValueSlice
 	element    *Type
 	index      *Type
+	internal   bool
 }
 
 // Owner returns the version that owns this type.
@@ -95,6 +98,17 @@ func (t *Type) SetDoc(value string) {
 	t.doc = value
 }
 
+// Internal returns true if this type has been marked as internal, so that it isn't part of the
+// public API.
+func (t *Type) Internal() bool {
+	return t.internal
+}
+
+// SetInternal sets the flag that indicates if this type is internal.
+func (t *Type) SetInternal(value bool) {
+	t.internal = value
+}
+
 // Kind returns the kind of this type.
 func (t *Type) Kind() TypeKind {
 	return t.kind
@@ -135,6 +149,11 @@ func (t *Type) IsDate() bool {
 	return t.owner != nil && t == t.owner.DateType()
 }
 
+// IsInterface returns true iff this type is the built-in interface type.
+func (t *Type) IsInterface() bool {
+	return t.owner != nil && t == t.owner.InterfaceType()
+}
+
 // IsClass returns true iff this type is a class type.
 func (t *Type) IsClass() bool {
 	return t.kind == ClassType
@@ -182,6 +201,17 @@ func (t *Type) Attributes() AttributeSlice {
 	return t.attributes
 }
 
+// HasLazyAttributes returns true if the type has at least one attribute whose value is decoded
+// only when it is accessed for the first time.
+func (t *Type) HasLazyAttributes() bool {
+	for _, attribute := range t.attributes {
+		if attribute.Lazy() {
+			return true
+		}
+	}
+	return false
+}
+
 // AddAttribute adds an attribute to the type, assuming hat it is an structured type.
 func (t *Type) AddAttribute(attribute *Attribute) {
 	if attribute != nil {
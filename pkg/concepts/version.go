@@ -39,6 +39,9 @@ type Version struct {
 
 	// All the error catagories of the version, indexed by name:
 	errors map[string]*Error
+
+	// All the events of the version, indexed by name:
+	events map[string]*Event
 }
 
 // NewVersion creates a new version containing only the built-in types.
@@ -48,6 +51,7 @@ func NewVersion() *Version {
 	version.types = make(map[string]*Type)
 	version.resources = make(map[string]*Resource)
 	version.errors = make(map[string]*Error)
+	version.events = make(map[string]*Event)
 
 	// Add the built-in scalar types:
 	version.addScalarType(nomenclator.Boolean)
@@ -56,6 +60,7 @@ func NewVersion() *Version {
 	version.addScalarType(nomenclator.Float)
 	version.addScalarType(nomenclator.String)
 	version.addScalarType(nomenclator.Date)
+	version.addScalarType(nomenclator.Interface)
 
 	return version
 }
@@ -109,6 +114,13 @@ func (v *Version) AddType(typ *Type) {
 	}
 }
 
+// RemoveType removes the given type from the version.
+func (v *Version) RemoveType(typ *Type) {
+	if typ != nil {
+		delete(v.types, typ.Name().String())
+	}
+}
+
 // AddTypes adds the given types to the version.
 func (v *Version) AddTypes(types []*Type) {
 	for _, typ := range types {
@@ -146,6 +158,11 @@ func (v *Version) DateType() *Type {
 	return v.FindType(nomenclator.Date)
 }
 
+// InterfaceType returns the interface type, used for attributes that can contain any JSON value.
+func (v *Version) InterfaceType() *Type {
+	return v.FindType(nomenclator.Interface)
+}
+
 // Resources returns the list of resources that are part of this version.
 func (v *Version) Resources() ResourceSlice {
 	count := len(v.resources)
@@ -175,6 +192,13 @@ func (v *Version) AddResource(resource *Resource) {
 	}
 }
 
+// RemoveResource removes the given resource from the version.
+func (v *Version) RemoveResource(resource *Resource) {
+	if resource != nil {
+		delete(v.resources, resource.Name().String())
+	}
+}
+
 // AddResources adds the given resources to the version.
 func (v *Version) AddResources(resources []*Resource) {
 	for _, resource := range resources {
@@ -223,6 +247,32 @@ func (v *Version) AddErrors(errors []*Error) {
 	}
 }
 
+// Events returns the list of events that are part of this version.
+func (v *Version) Events() EventSlice {
+	events := make(EventSlice, 0, len(v.events))
+	for _, event := range v.events {
+		events = append(events, event)
+	}
+	sort.Sort(events)
+	return events
+}
+
+// FindEvent returns the event with the given name, or nil if there is no such event.
+func (v *Version) FindEvent(name *names.Name) *Event {
+	if name == nil {
+		return nil
+	}
+	return v.events[name.String()]
+}
+
+// AddEvent adds the given event to the version.
+func (v *Version) AddEvent(event *Event) {
+	if event != nil {
+		v.events[event.Name().String()] = event
+		event.SetOwner(v)
+	}
+}
+
 // Paths returns the list of paths of this version. A path is a sequence of locators that go from
 // the root of the version to all reachable resources.
 func (v *Version) Paths() [][]*Locator {
@@ -0,0 +1,366 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the visitor interface and the function that walks the model calling it.
+
+package concepts
+
+import (
+	"errors"
+)
+
+// Visitor is the interface implemented by objects that want to be called for each of the concepts
+// of a model. See the Walk function for details. Implementations will usually embed BaseVisitor,
+// so that they only need to implement the methods for the concepts that they are interested in.
+type Visitor interface {
+	VisitService(service *Service) error
+	VisitVersion(version *Version) error
+	VisitType(typ *Type) error
+	VisitAttribute(attribute *Attribute) error
+	VisitEnumValue(value *EnumValue) error
+	VisitResource(resource *Resource) error
+	VisitMethod(method *Method) error
+	VisitParameter(parameter *Parameter) error
+	VisitLocator(locator *Locator) error
+	VisitError(err *Error) error
+	VisitEvent(event *Event) error
+}
+
+// SkipChildren can be returned by the methods of a visitor to indicate that the children of the
+// concept shouldn't be visited. For example, if VisitType returns it then the attributes and
+// values of that type won't be visited. It isn't considered an error, so the walk continues with
+// the next concept.
+var SkipChildren = errors.New("skip children")
+
+// Walk visits all the concepts of the given model, in the following order: for each service its
+// versions, for each version its types, resources, errors and events, for each type its
+// attributes and enumerated values, and for each resource its methods and locators, and for each
+// method its parameters. Concepts of the same kind are visited in the same order that the
+// corresponding methods of the model return them, for example the Types method of the version.
+// The parent is always visited before its children.
+//
+// If a method of the visitor returns an error, other than SkipChildren, then the walk stops and
+// the error is returned.
+func Walk(model *Model, visitor Visitor) error {
+	for _, service := range model.Services() {
+		err := walkService(service, visitor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkService(service *Service, visitor Visitor) error {
+	err := visitor.VisitService(service)
+	if err == SkipChildren {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, version := range service.Versions() {
+		err = walkVersion(version, visitor)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkVersion(version *Version, visitor Visitor) error {
+	err := visitor.VisitVersion(version)
+	if err == SkipChildren {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, typ := range version.Types() {
+		err = walkType(typ, visitor)
+		if err != nil {
+			return err
+		}
+	}
+	for _, resource := range version.Resources() {
+		err = walkResource(resource, visitor)
+		if err != nil {
+			return err
+		}
+	}
+	for _, item := range version.Errors() {
+		err = skip(visitor.VisitError(item))
+		if err != nil {
+			return err
+		}
+	}
+	for _, event := range version.Events() {
+		err = skip(visitor.VisitEvent(event))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkType(typ *Type, visitor Visitor) error {
+	err := visitor.VisitType(typ)
+	if err == SkipChildren {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, attribute := range typ.Attributes() {
+		err = skip(visitor.VisitAttribute(attribute))
+		if err != nil {
+			return err
+		}
+	}
+	for _, value := range typ.Values() {
+		err = skip(visitor.VisitEnumValue(value))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkResource(resource *Resource, visitor Visitor) error {
+	err := visitor.VisitResource(resource)
+	if err == SkipChildren {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, method := range resource.Methods() {
+		err = walkMethod(method, visitor)
+		if err != nil {
+			return err
+		}
+	}
+	for _, locator := range resource.Locators() {
+		err = skip(visitor.VisitLocator(locator))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkMethod(method *Method, visitor Visitor) error {
+	err := visitor.VisitMethod(method)
+	if err == SkipChildren {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, parameter := range method.Parameters() {
+		err = skip(visitor.VisitParameter(parameter))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip converts the SkipChildren error returned for concepts that don't have children to nil.
+func skip(err error) error {
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
+
+// BaseVisitor is an implementation of the Visitor interface where all the methods do nothing. It
+// is intended to be embedded in other visitors, so that they only need to implement the methods
+// that they are interested in.
+type BaseVisitor struct{}
+
+// Make sure that we implement the interface:
+var _ Visitor = BaseVisitor{}
+
+// VisitService does nothing.
+func (v BaseVisitor) VisitService(service *Service) error {
+	return nil
+}
+
+// VisitVersion does nothing.
+func (v BaseVisitor) VisitVersion(version *Version) error {
+	return nil
+}
+
+// VisitType does nothing.
+func (v BaseVisitor) VisitType(typ *Type) error {
+	return nil
+}
+
+// VisitAttribute does nothing.
+func (v BaseVisitor) VisitAttribute(attribute *Attribute) error {
+	return nil
+}
+
+// VisitEnumValue does nothing.
+func (v BaseVisitor) VisitEnumValue(value *EnumValue) error {
+	return nil
+}
+
+// VisitResource does nothing.
+func (v BaseVisitor) VisitResource(resource *Resource) error {
+	return nil
+}
+
+// VisitMethod does nothing.
+func (v BaseVisitor) VisitMethod(method *Method) error {
+	return nil
+}
+
+// VisitParameter does nothing.
+func (v BaseVisitor) VisitParameter(parameter *Parameter) error {
+	return nil
+}
+
+// VisitLocator does nothing.
+func (v BaseVisitor) VisitLocator(locator *Locator) error {
+	return nil
+}
+
+// VisitError does nothing.
+func (v BaseVisitor) VisitError(err *Error) error {
+	return nil
+}
+
+// VisitEvent does nothing.
+func (v BaseVisitor) VisitEvent(event *Event) error {
+	return nil
+}
+
+// VisitorFuncs is an implementation of the Visitor interface that calls the functions stored in
+// its fields. Functions that are nil aren't called. It is intended for simple walks that don't
+// need a type of their own. For example, to print the names of all the methods of a model:
+//
+//	concepts.Walk(model, &concepts.VisitorFuncs{
+//		Method: func(method *concepts.Method) error {
+//			fmt.Println(method.Name())
+//			return nil
+//		},
+//	})
+type VisitorFuncs struct {
+	Service   func(service *Service) error
+	Version   func(version *Version) error
+	Type      func(typ *Type) error
+	Attribute func(attribute *Attribute) error
+	EnumValue func(value *EnumValue) error
+	Resource  func(resource *Resource) error
+	Method    func(method *Method) error
+	Parameter func(parameter *Parameter) error
+	Locator   func(locator *Locator) error
+	Error     func(err *Error) error
+	Event     func(event *Event) error
+}
+
+// Make sure that we implement the interface:
+var _ Visitor = &VisitorFuncs{}
+
+// VisitService calls the Service function if it isn't nil.
+func (v *VisitorFuncs) VisitService(service *Service) error {
+	if v.Service == nil {
+		return nil
+	}
+	return v.Service(service)
+}
+
+// VisitVersion calls the Version function if it isn't nil.
+func (v *VisitorFuncs) VisitVersion(version *Version) error {
+	if v.Version == nil {
+		return nil
+	}
+	return v.Version(version)
+}
+
+// VisitType calls the Type function if it isn't nil.
+func (v *VisitorFuncs) VisitType(typ *Type) error {
+	if v.Type == nil {
+		return nil
+	}
+	return v.Type(typ)
+}
+
+// VisitAttribute calls the Attribute function if it isn't nil.
+func (v *VisitorFuncs) VisitAttribute(attribute *Attribute) error {
+	if v.Attribute == nil {
+		return nil
+	}
+	return v.Attribute(attribute)
+}
+
+// VisitEnumValue calls the EnumValue function if it isn't nil.
+func (v *VisitorFuncs) VisitEnumValue(value *EnumValue) error {
+	if v.EnumValue == nil {
+		return nil
+	}
+	return v.EnumValue(value)
+}
+
+// VisitResource calls the Resource function if it isn't nil.
+func (v *VisitorFuncs) VisitResource(resource *Resource) error {
+	if v.Resource == nil {
+		return nil
+	}
+	return v.Resource(resource)
+}
+
+// VisitMethod calls the Method function if it isn't nil.
+func (v *VisitorFuncs) VisitMethod(method *Method) error {
+	if v.Method == nil {
+		return nil
+	}
+	return v.Method(method)
+}
+
+// VisitParameter calls the Parameter function if it isn't nil.
+func (v *VisitorFuncs) VisitParameter(parameter *Parameter) error {
+	if v.Parameter == nil {
+		return nil
+	}
+	return v.Parameter(parameter)
+}
+
+// VisitLocator calls the Locator function if it isn't nil.
+func (v *VisitorFuncs) VisitLocator(locator *Locator) error {
+	if v.Locator == nil {
+		return nil
+	}
+	return v.Locator(locator)
+}
+
+// VisitError calls the Error function if it isn't nil.
+func (v *VisitorFuncs) VisitError(err *Error) error {
+	if v.Error == nil {
+		return nil
+	}
+	return v.Error(err)
+}
+
+// VisitEvent calls the Event function if it isn't nil.
+func (v *VisitorFuncs) VisitEvent(event *Event) error {
+	if v.Event == nil {
+		return nil
+	}
+	return v.Event(event)
+}
@@ -0,0 +1,136 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the model walker.
+
+package concepts
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+)
+
+// namesVisitor is a visitor that records the names of the concepts visited.
+type namesVisitor struct {
+	BaseVisitor
+	visited []string
+}
+
+func (v *namesVisitor) VisitType(typ *Type) error {
+	v.visited = append(v.visited, "type:"+typ.Name().Camel())
+	return nil
+}
+
+func (v *namesVisitor) VisitAttribute(attribute *Attribute) error {
+	v.visited = append(v.visited, "attribute:"+attribute.Name().Camel())
+	return nil
+}
+
+func (v *namesVisitor) VisitResource(resource *Resource) error {
+	v.visited = append(v.visited, "resource:"+resource.Name().Camel())
+	return nil
+}
+
+func (v *namesVisitor) VisitMethod(method *Method) error {
+	v.visited = append(v.visited, "method:"+method.Name().Camel())
+	return nil
+}
+
+var _ = Describe("Walk", func() {
+	var model *Model
+
+	BeforeEach(func() {
+		version := NewVersion()
+		version.SetName(names.ParseUsingCase("V1"))
+
+		// Remove the built-in types, so that the results are easier to check:
+		for _, typ := range version.Types() {
+			version.RemoveType(typ)
+		}
+
+		// Add a type with one attribute:
+		cluster := NewType()
+		cluster.SetKind(ClassType)
+		cluster.SetName(names.ParseUsingCase("Cluster"))
+		name := NewAttribute()
+		name.SetName(names.ParseUsingCase("Name"))
+		cluster.AddAttribute(name)
+		version.AddType(cluster)
+
+		// Add a resource with one method:
+		root := NewResource()
+		root.SetName(names.ParseUsingCase("Root"))
+		get := NewMethod()
+		get.SetName(names.ParseUsingCase("Get"))
+		root.AddMethod(get)
+		version.AddResource(root)
+
+		service := NewService()
+		service.SetName(names.ParseUsingCase("Clusters"))
+		service.AddVersion(version)
+		model = NewModel()
+		model.AddService(service)
+	})
+
+	It("Visits parents before children", func() {
+		visitor := &namesVisitor{}
+		err := Walk(model, visitor)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(visitor.visited).To(Equal([]string{
+			"type:Cluster",
+			"attribute:Name",
+			"resource:Root",
+			"method:Get",
+		}))
+	})
+
+	It("Doesn't visit children when requested", func() {
+		var visited []string
+		err := Walk(model, &VisitorFuncs{
+			Type: func(typ *Type) error {
+				visited = append(visited, typ.Name().Camel())
+				return SkipChildren
+			},
+			Attribute: func(attribute *Attribute) error {
+				visited = append(visited, attribute.Name().Camel())
+				return nil
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(visited).To(Equal([]string{"Cluster"}))
+	})
+
+	It("Stops when the visitor returns an error", func() {
+		failure := errors.New("failure")
+		count := 0
+		err := Walk(model, &VisitorFuncs{
+			Type: func(typ *Type) error {
+				count++
+				return failure
+			},
+			Resource: func(resource *Resource) error {
+				count++
+				return nil
+			},
+		})
+		Expect(err).To(Equal(failure))
+		Expect(count).To(Equal(1))
+	})
+})
@@ -0,0 +1,434 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package exporter contains the object that writes a resolved model to a machine readable JSON or
+// YAML document, so that tools written in other languages can use it without having to parse the
+// model language, and the object that reads the model back from that document.
+package exporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// Formats supported by the exporter:
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// ExporterBuilder is an object used to configure and build exporters. Don't create instances
+// directly, use the NewExporter function instead.
+type ExporterBuilder struct {
+	reporter *reporter.Reporter
+	model    *concepts.Model
+	format   string
+	output   io.Writer
+}
+
+// Exporter writes a model to a JSON or YAML document. Don't create instances directly, use the
+// builder instead.
+type Exporter struct {
+	reporter *reporter.Reporter
+	model    *concepts.Model
+	format   string
+	output   io.Writer
+}
+
+// NewExporter creates a new builder for exporters.
+func NewExporter() *ExporterBuilder {
+	return &ExporterBuilder{
+		format: JSON,
+	}
+}
+
+// Reporter sets the object that will be used to report information about the export process,
+// including errors.
+func (b *ExporterBuilder) Reporter(value *reporter.Reporter) *ExporterBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be exported.
+func (b *ExporterBuilder) Model(value *concepts.Model) *ExporterBuilder {
+	b.model = value
+	return b
+}
+
+// Format sets the format of the generated document. The supported formats are 'json' and
+// 'yaml'. The default is 'json'.
+func (b *ExporterBuilder) Format(value string) *ExporterBuilder {
+	b.format = value
+	return b
+}
+
+// Output sets the writer where the document will be written.
+func (b *ExporterBuilder) Output(value io.Writer) *ExporterBuilder {
+	b.output = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// exporter using it.
+func (b *ExporterBuilder) Build() (exporter *Exporter, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == nil {
+		err = fmt.Errorf("output is mandatory")
+		return
+	}
+
+	// Check that the format is supported:
+	switch b.format {
+	case JSON, YAML:
+	default:
+		err = fmt.Errorf(
+			"format '%s' isn't supported, valid values are '%s' and '%s'",
+			b.format, JSON, YAML,
+		)
+		return
+	}
+
+	// Create the exporter:
+	exporter = &Exporter{
+		reporter: b.reporter,
+		model:    b.model,
+		format:   b.format,
+		output:   b.output,
+	}
+
+	return
+}
+
+// Export writes the model to the output.
+func (e *Exporter) Export() error {
+	data, err := json.MarshalIndent(e.exportModel(e.model), "", "  ")
+	if err != nil {
+		return err
+	}
+	if e.format == YAML {
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return err
+		}
+	} else {
+		data = append(data, '\n')
+	}
+	_, err = e.output.Write(data)
+	return err
+}
+
+// The following types describe the structure of the exported document. Names are written in
+// the same camel case used in the model files, and references to other types or resources
+// contain only their names, so the document is a tree without cycles.
+
+type modelDoc struct {
+	Services []*serviceDoc `json:"services"`
+}
+
+type serviceDoc struct {
+	Name     string        `json:"name"`
+	Versions []*versionDoc `json:"versions"`
+}
+
+type versionDoc struct {
+	Name      string         `json:"name"`
+	Types     []*typeDoc     `json:"types"`
+	Resources []*resourceDoc `json:"resources"`
+	Errors    []*errorDoc    `json:"errors,omitempty"`
+	Events    []*eventDoc    `json:"events,omitempty"`
+}
+
+type typeDoc struct {
+	Name        string           `json:"name"`
+	Kind        string           `json:"kind"`
+	Doc         string           `json:"doc,omitempty"`
+	Internal    bool             `json:"internal,omitempty"`
+	Element     string           `json:"element,omitempty"`
+	Index       string           `json:"index,omitempty"`
+	Attributes  []*attributeDoc  `json:"attributes,omitempty"`
+	Values      []*valueDoc      `json:"values,omitempty"`
+	Annotations []*annotationDoc `json:"annotations,omitempty"`
+}
+
+type attributeDoc struct {
+	Name        string           `json:"name"`
+	Type        string           `json:"type"`
+	Doc         string           `json:"doc,omitempty"`
+	Link        bool             `json:"link,omitempty"`
+	Min         *int             `json:"min,omitempty"`
+	Max         *int             `json:"max,omitempty"`
+	Pattern     string           `json:"pattern,omitempty"`
+	Format      string           `json:"format,omitempty"`
+	Immutable   bool             `json:"immutable,omitempty"`
+	ReadOnly    bool             `json:"read_only,omitempty"`
+	Lazy        bool             `json:"lazy,omitempty"`
+	Always      bool             `json:"always,omitempty"`
+	Sensitive   bool             `json:"sensitive,omitempty"`
+	Since       string           `json:"since,omitempty"`
+	Feature     string           `json:"feature,omitempty"`
+	Annotations []*annotationDoc `json:"annotations,omitempty"`
+}
+
+type valueDoc struct {
+	Name string `json:"name"`
+	Doc  string `json:"doc,omitempty"`
+}
+
+type resourceDoc struct {
+	Name        string           `json:"name"`
+	Doc         string           `json:"doc,omitempty"`
+	Internal    bool             `json:"internal,omitempty"`
+	Methods     []*methodDoc     `json:"methods,omitempty"`
+	Locators    []*locatorDoc    `json:"locators,omitempty"`
+	Annotations []*annotationDoc `json:"annotations,omitempty"`
+}
+
+type methodDoc struct {
+	Name        string           `json:"name"`
+	Doc         string           `json:"doc,omitempty"`
+	Since       string           `json:"since,omitempty"`
+	Feature     string           `json:"feature,omitempty"`
+	Parameters  []*parameterDoc  `json:"parameters,omitempty"`
+	Examples    []*exampleDoc    `json:"examples,omitempty"`
+	Annotations []*annotationDoc `json:"annotations,omitempty"`
+}
+
+type annotationDoc struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+type exampleDoc struct {
+	Kind    string `json:"kind,omitempty"`
+	Summary string `json:"summary,omitempty"`
+	Text    string `json:"text"`
+}
+
+type parameterDoc struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Doc     string      `json:"doc,omitempty"`
+	In      bool        `json:"in,omitempty"`
+	Out     bool        `json:"out,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+}
+
+type locatorDoc struct {
+	Name     string `json:"name"`
+	Doc      string `json:"doc,omitempty"`
+	Variable bool   `json:"variable,omitempty"`
+	Target   string `json:"target"`
+}
+
+type errorDoc struct {
+	Name string `json:"name"`
+	Code int    `json:"code"`
+	Doc  string `json:"doc,omitempty"`
+}
+
+type eventDoc struct {
+	Name    string `json:"name"`
+	Doc     string `json:"doc,omitempty"`
+	Object  string `json:"object,omitempty"`
+	Payload string `json:"payload,omitempty"`
+}
+
+func (e *Exporter) exportModel(model *concepts.Model) *modelDoc {
+	doc := &modelDoc{
+		Services: []*serviceDoc{},
+	}
+	for _, service := range model.Services() {
+		doc.Services = append(doc.Services, e.exportService(service))
+	}
+	return doc
+}
+
+func (e *Exporter) exportService(service *concepts.Service) *serviceDoc {
+	doc := &serviceDoc{
+		Name:     service.Name().Snake(),
+		Versions: []*versionDoc{},
+	}
+	for _, version := range service.Versions() {
+		doc.Versions = append(doc.Versions, e.exportVersion(version))
+	}
+	return doc
+}
+
+func (e *Exporter) exportVersion(version *concepts.Version) *versionDoc {
+	doc := &versionDoc{
+		Name:      version.Name().Snake(),
+		Types:     []*typeDoc{},
+		Resources: []*resourceDoc{},
+	}
+	for _, typ := range version.Types() {
+		// The built-in scalar types and their lists are added to every version when it is
+		// created, so they don't need to be exported:
+		if typ.IsScalar() || typ.IsList() && typ.Element().IsScalar() {
+			continue
+		}
+		doc.Types = append(doc.Types, e.exportType(typ))
+	}
+	for _, resource := range version.Resources() {
+		doc.Resources = append(doc.Resources, e.exportResource(resource))
+	}
+	for _, err := range version.Errors() {
+		doc.Errors = append(doc.Errors, &errorDoc{
+			Name: e.name(err.Name()),
+			Code: err.Code(),
+			Doc:  err.Doc(),
+		})
+	}
+	for _, event := range version.Events() {
+		doc.Events = append(doc.Events, &eventDoc{
+			Name:    e.name(event.Name()),
+			Doc:     event.Doc(),
+			Object:  e.typeName(event.Object()),
+			Payload: e.typeName(event.Payload()),
+		})
+	}
+	return doc
+}
+
+func (e *Exporter) exportType(typ *concepts.Type) *typeDoc {
+	doc := &typeDoc{
+		Name:        e.name(typ.Name()),
+		Kind:        typ.Kind().String(),
+		Doc:         typ.Doc(),
+		Internal:    typ.Internal(),
+		Element:     e.typeName(typ.Element()),
+		Index:       e.typeName(typ.Index()),
+		Annotations: e.exportAnnotations(typ),
+	}
+	for _, attribute := range typ.Attributes() {
+		doc.Attributes = append(doc.Attributes, &attributeDoc{
+			Name:        e.name(attribute.Name()),
+			Type:        e.typeName(attribute.Type()),
+			Doc:         attribute.Doc(),
+			Link:        attribute.Link(),
+			Min:         attribute.Min(),
+			Max:         attribute.Max(),
+			Pattern:     attribute.Pattern(),
+			Format:      attribute.Format(),
+			Immutable:   attribute.Immutable(),
+			ReadOnly:    attribute.ReadOnly(),
+			Lazy:        attribute.Lazy(),
+			Always:      attribute.Always(),
+			Sensitive:   attribute.Sensitive(),
+			Since:       attribute.Since(),
+			Feature:     attribute.Feature(),
+			Annotations: e.exportAnnotations(attribute),
+		})
+	}
+	for _, value := range typ.Values() {
+		doc.Values = append(doc.Values, &valueDoc{
+			Name: e.name(value.Name()),
+			Doc:  value.Doc(),
+		})
+	}
+	return doc
+}
+
+func (e *Exporter) exportResource(resource *concepts.Resource) *resourceDoc {
+	doc := &resourceDoc{
+		Name:        e.name(resource.Name()),
+		Doc:         resource.Doc(),
+		Internal:    resource.Internal(),
+		Annotations: e.exportAnnotations(resource),
+	}
+	for _, method := range resource.Methods() {
+		methodDoc := &methodDoc{
+			Name:        e.name(method.Name()),
+			Doc:         method.Doc(),
+			Since:       method.Since(),
+			Feature:     method.Feature(),
+			Annotations: e.exportAnnotations(method),
+		}
+		for _, parameter := range method.Parameters() {
+			methodDoc.Parameters = append(methodDoc.Parameters, &parameterDoc{
+				Name:    e.name(parameter.Name()),
+				Type:    e.typeName(parameter.Type()),
+				Doc:     parameter.Doc(),
+				In:      parameter.In(),
+				Out:     parameter.Out(),
+				Default: parameter.Default(),
+			})
+		}
+		for _, example := range method.Examples() {
+			methodDoc.Examples = append(methodDoc.Examples, &exampleDoc{
+				Kind:    string(example.Kind()),
+				Summary: example.Summary(),
+				Text:    example.Text(),
+			})
+		}
+		doc.Methods = append(doc.Methods, methodDoc)
+	}
+	for _, locator := range resource.Locators() {
+		doc.Locators = append(doc.Locators, &locatorDoc{
+			Name:     e.name(locator.Name()),
+			Doc:      locator.Doc(),
+			Variable: locator.Variable(),
+			Target:   e.resourceName(locator.Target()),
+		})
+	}
+	return doc
+}
+
+func (e *Exporter) exportAnnotations(annotated concepts.Annotated) []*annotationDoc {
+	var docs []*annotationDoc
+	for _, annotation := range annotated.Annotations() {
+		docs = append(docs, &annotationDoc{
+			Name:  e.name(annotation.Name()),
+			Value: annotation.Value(),
+		})
+	}
+	return docs
+}
+
+func (e *Exporter) name(name *names.Name) string {
+	if name == nil {
+		return ""
+	}
+	return name.Camel()
+}
+
+func (e *Exporter) typeName(typ *concepts.Type) string {
+	if typ == nil {
+		return ""
+	}
+	return e.name(typ.Name())
+}
+
+func (e *Exporter) resourceName(resource *concepts.Resource) string {
+	if resource == nil {
+		return ""
+	}
+	return e.name(resource.Name())
+}
@@ -0,0 +1,137 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the model exporter.
+
+package exporter
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Exporter", func() {
+	var model *concepts.Model
+
+	BeforeEach(func() {
+		version := concepts.NewVersion()
+		version.SetName(names.ParseUsingCase("V1"))
+		typ := concepts.NewType()
+		typ.SetKind(concepts.ClassType)
+		typ.SetName(names.ParseUsingCase("ClusterID"))
+		typ.SetDoc("Identifier of a cluster.")
+		version.AddType(typ)
+		service := concepts.NewService()
+		service.SetName(names.ParseUsingSeparator("clusters_mgmt", "_"))
+		service.AddVersion(version)
+		model = concepts.NewModel()
+		model.AddService(service)
+	})
+
+	export := func(format string) string {
+		buffer := &bytes.Buffer{}
+		exporter, err := NewExporter().
+			Reporter(reporter.NewReporter()).
+			Model(model).
+			Format(format).
+			Output(buffer).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = exporter.Export()
+		Expect(err).ToNot(HaveOccurred())
+		return buffer.String()
+	}
+
+	It("Exports to JSON", func() {
+		Expect(export(JSON)).To(MatchJSON(`{
+			"services": [{
+				"name": "clusters_mgmt",
+				"versions": [{
+					"name": "v1",
+					"types": [{
+						"name": "ClusterID",
+						"kind": "class",
+						"doc": "Identifier of a cluster."
+					}],
+					"resources": []
+				}]
+			}]
+		}`))
+	})
+
+	It("Exports to YAML", func() {
+		Expect(export(YAML)).To(MatchYAML(`
+services:
+- name: clusters_mgmt
+  versions:
+  - name: v1
+    types:
+    - name: ClusterID
+      kind: class
+      doc: Identifier of a cluster.
+    resources: []
+`))
+	})
+
+	It("Imports what it exports", func() {
+		// Add a resource with a method and a locator, so that the references between concepts
+		// are also checked:
+		version := model.Services()[0].Versions()[0]
+		root := concepts.NewResource()
+		root.SetName(names.ParseUsingCase("Root"))
+		version.AddResource(root)
+		method := concepts.NewMethod()
+		method.SetName(names.ParseUsingCase("Get"))
+		parameter := concepts.NewParameter()
+		parameter.SetName(names.ParseUsingCase("DryRun"))
+		parameter.SetType(version.Boolean())
+		parameter.SetIn(true)
+		parameter.SetDefault(false)
+		method.AddParameter(parameter)
+		root.AddMethod(method)
+		locator := concepts.NewLocator()
+		locator.SetName(names.ParseUsingCase("Self"))
+		locator.SetTarget(root)
+		root.AddLocator(locator)
+
+		// Export, import and export again:
+		exported := export(YAML)
+		importer, err := NewImporter().
+			Reporter(reporter.NewReporter()).
+			Input(bytes.NewBufferString(exported)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		model, err = importer.Import()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(export(YAML)).To(MatchYAML(exported))
+	})
+
+	It("Rejects unsupported format", func() {
+		_, err := NewExporter().
+			Reporter(reporter.NewReporter()).
+			Model(model).
+			Format("xml").
+			Output(&bytes.Buffer{}).
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+})
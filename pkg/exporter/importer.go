@@ -0,0 +1,335 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the object that reads a model from a document
+// previously written by the exporter.
+
+package exporter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/ghodss/yaml"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// ImporterBuilder is an object used to configure and build importers. Don't create instances
+// directly, use the NewImporter function instead.
+type ImporterBuilder struct {
+	reporter *reporter.Reporter
+	input    io.Reader
+}
+
+// Importer reads a model from a JSON or YAML document written by the exporter. Don't create
+// instances directly, use the builder instead.
+type Importer struct {
+	reporter *reporter.Reporter
+	input    io.Reader
+	errors   int
+}
+
+// NewImporter creates a new builder for importers.
+func NewImporter() *ImporterBuilder {
+	return new(ImporterBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the import process,
+// including errors.
+func (b *ImporterBuilder) Reporter(value *reporter.Reporter) *ImporterBuilder {
+	b.reporter = value
+	return b
+}
+
+// Input sets the reader where the document will be read from. The format of the document, JSON
+// or YAML, is detected automatically.
+func (b *ImporterBuilder) Input(value io.Reader) *ImporterBuilder {
+	b.input = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// importer using it.
+func (b *ImporterBuilder) Build() (importer *Importer, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.input == nil {
+		err = fmt.Errorf("input is mandatory")
+		return
+	}
+
+	// Create the importer:
+	importer = &Importer{
+		reporter: b.reporter,
+		input:    b.input,
+	}
+
+	return
+}
+
+// Import reads the document and creates the model.
+func (i *Importer) Import() (model *concepts.Model, err error) {
+	// Read the document, converting it to JSON if needed, as YAML is a superset of JSON:
+	data, err := ioutil.ReadAll(i.input)
+	if err != nil {
+		return
+	}
+	data, err = yaml.YAMLToJSON(data)
+	if err != nil {
+		return
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	doc := &modelDoc{}
+	err = decoder.Decode(doc)
+	if err != nil {
+		return
+	}
+
+	// Create the model:
+	i.errors = 0
+	model = concepts.NewModel()
+	for _, serviceDoc := range doc.Services {
+		model.AddService(i.importService(serviceDoc))
+	}
+
+	// Check if there were errors:
+	if i.errors > 0 {
+		if i.errors > 1 {
+			err = fmt.Errorf("there were %d errors", i.errors)
+		} else {
+			err = fmt.Errorf("there was 1 error")
+		}
+		model = nil
+	}
+
+	return
+}
+
+func (i *Importer) importService(doc *serviceDoc) *concepts.Service {
+	service := concepts.NewService()
+	service.SetName(names.ParseUsingSeparator(doc.Name, "_"))
+	for _, versionDoc := range doc.Versions {
+		service.AddVersion(i.importVersion(versionDoc))
+	}
+	return service
+}
+
+func (i *Importer) importVersion(doc *versionDoc) *concepts.Version {
+	version := concepts.NewVersion()
+	version.SetName(names.ParseUsingSeparator(doc.Name, "_"))
+
+	// Types and resources can reference each other in any order, so first we create all of them
+	// and then we populate them:
+	for _, typeDoc := range doc.Types {
+		name := names.ParseUsingCase(typeDoc.Name)
+		if version.FindType(name) == nil {
+			typ := concepts.NewType()
+			typ.SetName(name)
+			version.AddType(typ)
+		}
+	}
+	for _, resourceDoc := range doc.Resources {
+		resource := concepts.NewResource()
+		resource.SetName(names.ParseUsingCase(resourceDoc.Name))
+		version.AddResource(resource)
+	}
+	for _, typeDoc := range doc.Types {
+		i.importType(version, typeDoc)
+	}
+	for _, resourceDoc := range doc.Resources {
+		i.importResource(version, resourceDoc)
+	}
+
+	// Errors and events:
+	for _, errorDoc := range doc.Errors {
+		err := concepts.NewError()
+		err.SetName(names.ParseUsingCase(errorDoc.Name))
+		err.SetCode(errorDoc.Code)
+		err.SetDoc(errorDoc.Doc)
+		version.AddError(err)
+	}
+	for _, eventDoc := range doc.Events {
+		event := concepts.NewEvent()
+		event.SetName(names.ParseUsingCase(eventDoc.Name))
+		event.SetDoc(eventDoc.Doc)
+		event.SetObject(i.findType(version, eventDoc.Object))
+		event.SetPayload(i.findType(version, eventDoc.Payload))
+		version.AddEvent(event)
+	}
+
+	return version
+}
+
+func (i *Importer) importType(version *concepts.Version, doc *typeDoc) {
+	typ := version.FindType(names.ParseUsingCase(doc.Name))
+	kind, ok := typeKinds[doc.Kind]
+	if !ok {
+		i.reporter.Errorf("Type '%s' has unknown kind '%s'", doc.Name, doc.Kind)
+		i.errors++
+		return
+	}
+	typ.SetKind(kind)
+	typ.SetDoc(doc.Doc)
+	typ.SetInternal(doc.Internal)
+	typ.SetElement(i.findType(version, doc.Element))
+	typ.SetIndex(i.findType(version, doc.Index))
+	for _, annotation := range i.importAnnotations(doc.Annotations) {
+		typ.AddAnnotation(annotation)
+	}
+	for _, attributeDoc := range doc.Attributes {
+		attribute := concepts.NewAttribute()
+		attribute.SetName(names.ParseUsingCase(attributeDoc.Name))
+		attribute.SetType(i.findType(version, attributeDoc.Type))
+		attribute.SetDoc(attributeDoc.Doc)
+		attribute.SetLink(attributeDoc.Link)
+		attribute.SetMin(attributeDoc.Min)
+		attribute.SetMax(attributeDoc.Max)
+		attribute.SetPattern(attributeDoc.Pattern)
+		attribute.SetFormat(attributeDoc.Format)
+		attribute.SetImmutable(attributeDoc.Immutable)
+		attribute.SetReadOnly(attributeDoc.ReadOnly)
+		attribute.SetLazy(attributeDoc.Lazy)
+		attribute.SetAlways(attributeDoc.Always)
+		attribute.SetSensitive(attributeDoc.Sensitive)
+		attribute.SetSince(attributeDoc.Since)
+		attribute.SetFeature(attributeDoc.Feature)
+		for _, annotation := range i.importAnnotations(attributeDoc.Annotations) {
+			attribute.AddAnnotation(annotation)
+		}
+		typ.AddAttribute(attribute)
+	}
+	for _, valueDoc := range doc.Values {
+		value := concepts.NewEnumValue()
+		value.SetName(names.ParseUsingCase(valueDoc.Name))
+		value.SetDoc(valueDoc.Doc)
+		typ.AddValue(value)
+	}
+}
+
+func (i *Importer) importResource(version *concepts.Version, doc *resourceDoc) {
+	resource := version.FindResource(names.ParseUsingCase(doc.Name))
+	resource.SetDoc(doc.Doc)
+	resource.SetInternal(doc.Internal)
+	for _, annotation := range i.importAnnotations(doc.Annotations) {
+		resource.AddAnnotation(annotation)
+	}
+	for _, methodDoc := range doc.Methods {
+		method := concepts.NewMethod()
+		method.SetName(names.ParseUsingCase(methodDoc.Name))
+		method.SetDoc(methodDoc.Doc)
+		method.SetSince(methodDoc.Since)
+		method.SetFeature(methodDoc.Feature)
+		for _, annotation := range i.importAnnotations(methodDoc.Annotations) {
+			method.AddAnnotation(annotation)
+		}
+		for _, parameterDoc := range methodDoc.Parameters {
+			parameter := concepts.NewParameter()
+			parameter.SetName(names.ParseUsingCase(parameterDoc.Name))
+			parameter.SetType(i.findType(version, parameterDoc.Type))
+			parameter.SetDoc(parameterDoc.Doc)
+			parameter.SetIn(parameterDoc.In)
+			parameter.SetOut(parameterDoc.Out)
+			parameter.SetDefault(i.importValue(parameterDoc.Default))
+			method.AddParameter(parameter)
+		}
+		for _, exampleDoc := range methodDoc.Examples {
+			example := concepts.NewExample()
+			example.SetKind(concepts.ExampleKind(exampleDoc.Kind))
+			example.SetSummary(exampleDoc.Summary)
+			example.SetText(exampleDoc.Text)
+			method.AddExample(example)
+		}
+		resource.AddMethod(method)
+	}
+	for _, locatorDoc := range doc.Locators {
+		locator := concepts.NewLocator()
+		locator.SetName(names.ParseUsingCase(locatorDoc.Name))
+		locator.SetDoc(locatorDoc.Doc)
+		locator.SetVariable(locatorDoc.Variable)
+		target := version.FindResource(names.ParseUsingCase(locatorDoc.Target))
+		if target == nil {
+			i.reporter.Errorf(
+				"Locator '%s' of resource '%s' has unknown target '%s'",
+				locatorDoc.Name, doc.Name, locatorDoc.Target,
+			)
+			i.errors++
+		}
+		locator.SetTarget(target)
+		resource.AddLocator(locator)
+	}
+}
+
+func (i *Importer) importAnnotations(docs []*annotationDoc) concepts.AnnotationSlice {
+	var annotations concepts.AnnotationSlice
+	for _, doc := range docs {
+		annotation := concepts.NewAnnotation()
+		annotation.SetName(names.ParseUsingCase(doc.Name))
+		annotation.SetValue(i.importValue(doc.Value))
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
+
+// importValue converts the default value of a parameter or the value of an annotation to the
+// same Go types that the reader of the model language uses: booleans, integers and strings.
+func (i *Importer) importValue(value interface{}) interface{} {
+	number, ok := value.(json.Number)
+	if !ok {
+		return value
+	}
+	result, err := number.Int64()
+	if err != nil {
+		i.reporter.Errorf("Value '%s' isn't an integer", number)
+		i.errors++
+		return nil
+	}
+	return int(result)
+}
+
+// findType finds the type with the given name, and reports an error if it doesn't exist. An
+// empty name means that there is no type, and it returns nil without reporting an error.
+func (i *Importer) findType(version *concepts.Version, name string) *concepts.Type {
+	if name == "" {
+		return nil
+	}
+	typ := version.FindType(names.ParseUsingCase(name))
+	if typ == nil {
+		i.reporter.Errorf("Type '%s' doesn't exist", name)
+		i.errors++
+	}
+	return typ
+}
+
+// typeKinds is used to convert the names of the kinds of types used in the document to the
+// corresponding values.
+var typeKinds = map[string]concepts.TypeKind{
+	concepts.ClassType.String():  concepts.ClassType,
+	concepts.EnumType.String():   concepts.EnumType,
+	concepts.ListType.String():   concepts.ListType,
+	concepts.MapType.String():    concepts.MapType,
+	concepts.ScalarType.String(): concepts.ScalarType,
+	concepts.StructType.String(): concepts.StructType,
+}
@@ -116,6 +116,7 @@ func (g *DocsGenerator) Run() error {
 
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating documentation for version '%s'", version)
 			// Generate documentation for each resource:
 			for _, resource := range version.Resources() {
 				err = g.generateResource(resource)
@@ -16,7 +16,23 @@ limitations under the License.
 
 package generators
 
+import (
+	"reflect"
+	"strings"
+)
+
 // Generator is the interface that should be implemented by code generators.
 type Generator interface {
 	Run() error
 }
+
+// Name returns a name for the given generator, suitable for progress and summary messages. It is
+// the name of the type of the generator without the 'Generator' suffix, for example 'Types' for
+// the Go types generator.
+func Name(generator Generator) string {
+	typ := reflect.TypeOf(generator)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return strings.TrimSuffix(typ.Name(), "Generator")
+}
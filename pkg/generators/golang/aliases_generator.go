@@ -0,0 +1,377 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the generator that creates, for each version, a package containing aliases
+// of the public types and functions with names prefixed by the service and version. That way
+// code that needs to dot import the packages of multiple services or versions doesn't get
+// collisions when two of them define types with the same name.
+
+package golang
+
+import (
+	"fmt"
+	"path"
+	"unicode"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// AliasesGeneratorBuilder is an object used to configure and build the aliases generator. Don't
+// create instances directly, use the NewAliasesGenerator function instead.
+type AliasesGeneratorBuilder struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	prefixes  map[string]string
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+}
+
+// AliasesGenerator generates the packages containing the prefixed aliases of the types. Don't
+// create instances directly, use the builder instead.
+type AliasesGenerator struct {
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	prefixes  map[string]string
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
+}
+
+// NewAliasesGenerator creates a new builder for aliases generators.
+func NewAliasesGenerator() *AliasesGeneratorBuilder {
+	return new(AliasesGeneratorBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the generation process,
+// including errors.
+func (b *AliasesGeneratorBuilder) Reporter(value *reporter.Reporter) *AliasesGeneratorBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be used by the aliases generator.
+func (b *AliasesGeneratorBuilder) Model(value *concepts.Model) *AliasesGeneratorBuilder {
+	b.model = value
+	return b
+}
+
+// Output sets the output directory.
+func (b *AliasesGeneratorBuilder) Output(value string) *AliasesGeneratorBuilder {
+	b.output = value
+	return b
+}
+
+// Packages sets the object that will be used to calculate package names.
+func (b *AliasesGeneratorBuilder) Packages(value *PackagesCalculator) *AliasesGeneratorBuilder {
+	b.packages = value
+	return b
+}
+
+// Names sets the object that will be used to calculate names.
+func (b *AliasesGeneratorBuilder) Names(value *NamesCalculator) *AliasesGeneratorBuilder {
+	b.names = value
+	return b
+}
+
+// Types sets the object that will be used to calculate types.
+func (b *AliasesGeneratorBuilder) Types(value *TypesCalculator) *AliasesGeneratorBuilder {
+	b.types = value
+	return b
+}
+
+// Prefix sets the prefix that will be added to the names of the aliases of the types of the given
+// service. The name of the service is the one used in the model, for example 'clusters_mgmt'. The
+// default prefix is the name of the service followed by the name of the version, for example
+// 'ClustersMgmtV1'.
+func (b *AliasesGeneratorBuilder) Prefix(service, prefix string) *AliasesGeneratorBuilder {
+	if b.prefixes == nil {
+		b.prefixes = map[string]string{}
+	}
+	b.prefixes[service] = prefix
+	return b
+}
+
+// Prefixes sets the prefixes for multiple services. This is equivalent to calling the Prefix
+// method multiple times.
+func (b *AliasesGeneratorBuilder) Prefixes(values map[string]string) *AliasesGeneratorBuilder {
+	for service, prefix := range values {
+		b.Prefix(service, prefix)
+	}
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *AliasesGeneratorBuilder) Header(value *Header) *AliasesGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *AliasesGeneratorBuilder) Stream(value bool) *AliasesGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *AliasesGeneratorBuilder) MaxLines(value int) *AliasesGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *AliasesGeneratorBuilder) Templates(value *Templates) *AliasesGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// aliases generator using it.
+func (b *AliasesGeneratorBuilder) Build() (generator *AliasesGenerator, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output path is mandatory")
+		return
+	}
+	if b.packages == nil {
+		err = fmt.Errorf("packages calculator is mandatory")
+		return
+	}
+	if b.names == nil {
+		err = fmt.Errorf("names calculator is mandatory")
+		return
+	}
+	if b.types == nil {
+		err = fmt.Errorf("types calculator is mandatory")
+		return
+	}
+
+	// Check that the prefixes are valid exported Go identifiers:
+	for service, prefix := range b.prefixes {
+		if !isExportedIdentifier(prefix) {
+			err = fmt.Errorf(
+				"prefix '%s' for service '%s' isn't a valid exported Go identifier",
+				prefix, service,
+			)
+			return
+		}
+	}
+
+	// Copy the prefixes so that changes in the builder don't affect the generator:
+	prefixes := make(map[string]string, len(b.prefixes))
+	for service, prefix := range b.prefixes {
+		prefixes[service] = prefix
+	}
+
+	// Create the generator:
+	generator = &AliasesGenerator{
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		types:     b.types,
+		prefixes:  prefixes,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+	}
+
+	return
+}
+
+// Run executes the code generator.
+func (g *AliasesGenerator) Run() error {
+	var err error
+
+	// Generate the aliases for each version:
+	for _, service := range g.model.Services() {
+		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating aliases for version '%s'", version)
+			err = g.generateVersionAliases(version)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Check if there were errors:
+	if g.errors > 0 {
+		if g.errors > 1 {
+			err = fmt.Errorf("there were %d errors", g.errors)
+		} else {
+			err = fmt.Errorf("there was 1 error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *AliasesGenerator) generateVersionAliases(version *concepts.Version) error {
+	var err error
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("aliases").
+		Package(g.packages.AliasesPackage(version)).
+		File(path.Base(g.packages.AliasesPackage(version))).
+		Function("enumName", g.types.EnumName).
+		Function("listType", g.listType).
+		Function("structName", g.types.StructName).
+		Function("valueName", g.valueName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateVersionAliasesSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *AliasesGenerator) generateVersionAliasesSource(version *concepts.Version) {
+	g.buffer.Import(g.packages.VersionImport(version), "")
+	g.buffer.EmitTemplate("version_aliases", `
+		{{ $selector := .Selector }}
+		{{ $prefix := .Prefix }}
+
+		{{ range .Version.Types }}
+			{{ if .IsEnum }}
+				{{ $enumName := enumName . }}
+
+				// {{ $prefix }}{{ $enumName }} is an alias of the {{ $selector }}.{{ $enumName }} type.
+				type {{ $prefix }}{{ $enumName }} = {{ $selector }}.{{ $enumName }}
+
+				// Values of the {{ $prefix }}{{ $enumName }} type:
+				const (
+					{{ range .Values }}
+						{{ $valueName := valueName . }}
+						{{ $prefix }}{{ $valueName }} = {{ $selector }}.{{ $valueName }}
+					{{ end }}
+				)
+			{{ else if .IsStruct }}
+				{{ $structName := structName . }}
+				{{ $listName := structName (listType .) }}
+
+				// {{ $prefix }}{{ $structName }} is an alias of the {{ $selector }}.{{ $structName }} type.
+				type {{ $prefix }}{{ $structName }} = {{ $selector }}.{{ $structName }}
+
+				// {{ $prefix }}{{ $structName }}Builder is an alias of the {{ $selector }}.{{ $structName }}Builder type.
+				type {{ $prefix }}{{ $structName }}Builder = {{ $selector }}.{{ $structName }}Builder
+
+				// {{ $prefix }}{{ $listName }} is an alias of the {{ $selector }}.{{ $listName }} type.
+				type {{ $prefix }}{{ $listName }} = {{ $selector }}.{{ $listName }}
+
+				// {{ $prefix }}{{ $listName }}Builder is an alias of the {{ $selector }}.{{ $listName }}Builder type.
+				type {{ $prefix }}{{ $listName }}Builder = {{ $selector }}.{{ $listName }}Builder
+
+				// Aliases of the functions that create, marshal and unmarshal objects of the
+				// {{ $selector }}.{{ $structName }} type:
+				var (
+					New{{ $prefix }}{{ $structName }} = {{ $selector }}.New{{ $structName }}
+					New{{ $prefix }}{{ $listName }} = {{ $selector }}.New{{ $listName }}
+					Marshal{{ $prefix }}{{ $structName }} = {{ $selector }}.Marshal{{ $structName }}
+					Unmarshal{{ $prefix }}{{ $structName }} = {{ $selector }}.Unmarshal{{ $structName }}
+					Marshal{{ $prefix }}{{ $listName }} = {{ $selector }}.Marshal{{ $listName }}
+					Unmarshal{{ $prefix }}{{ $listName }} = {{ $selector }}.Unmarshal{{ $listName }}
+				)
+			{{ end }}
+		{{ end }}
+		`,
+		"Version", version,
+		"Selector", g.packages.VersionSelector(version),
+		"Prefix", g.prefix(version),
+	)
+}
+
+// prefix calculates the prefix for the aliases of the given version, either the one explicitly
+// configured for the service or the default one calculated from the names of the service and
+// the version.
+func (g *AliasesGenerator) prefix(version *concepts.Version) string {
+	service := version.Owner()
+	prefix, ok := g.prefixes[service.Name().Snake()]
+	if ok {
+		return prefix
+	}
+	return g.names.Public(names.Cat(service.Name(), version.Name()))
+}
+
+func (g *AliasesGenerator) listType(typ *concepts.Type) *concepts.Type {
+	list := typ.Owner().FindType(names.Cat(typ.Name(), nomenclator.List))
+	if list == nil {
+		g.reporter.Errorf("Can't find list type for type '%s'", typ.Name())
+		g.errors++
+		return typ
+	}
+	return list
+}
+
+func (g *AliasesGenerator) valueName(value *concepts.EnumValue) string {
+	return g.names.Public(names.Cat(value.Type().Name(), value.Name()))
+}
+
+// isExportedIdentifier checks if the given text is a valid exported Go identifier.
+func isExportedIdentifier(text string) bool {
+	for i, r := range text {
+		switch {
+		case i == 0 && !unicode.IsUpper(r):
+			return false
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_':
+			return false
+		}
+	}
+	return text != ""
+}
@@ -20,12 +20,19 @@ import (
 	"bufio"
 	"bytes"
 	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io"
+	"io/ioutil"
 	"os"
-	"os/exec"
 	"path"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
@@ -34,25 +41,59 @@ import (
 // BufferBuilder is used to create a new Go buffer. Don't create it directly, use the
 // NewBuffer function instead.
 type BufferBuilder struct {
-	reporter  *reporter.Reporter
-	output    string
-	pkg       string
-	file      string
-	packages  *PackagesCalculator
-	functions map[string]interface{}
+	reporter   *reporter.Reporter
+	output     string
+	pkg        string
+	file       string
+	constraint string
+	header     *Header
+	stream     bool
+	maxLines   int
+	packages   *PackagesCalculator
+	functions  map[string]interface{}
+	templates  *Templates
+	generator  string
 }
 
 // Buffer is a type that simplifies the generation of Go code.
 type Buffer struct {
-	reporter  *reporter.Reporter
-	pkg       string
-	file      string
-	packages  *PackagesCalculator
-	functions map[string]interface{}
-	imports   map[string]string
-	code      *bytes.Buffer
+	reporter   *reporter.Reporter
+	pkg        string
+	file       string
+	constraint string
+	header     *Header
+	maxLines   int
+	packages   *PackagesCalculator
+	functions  map[string]interface{}
+	templates  *Templates
+	generator  string
+	wrappers   template.FuncMap
+	key        string
+	imports    map[string]string
+	code       *bytes.Buffer
+	stream     bool
+	pending    int
+	retry      int
+	shards     []*streamShard
 }
 
+// streamShard contains the information about one of the output files generated in streaming mode.
+// The formatted code of the file is kept in a temporary file, and the imports that it needs are
+// collected while it is generated, so that the preamble can be written when the file is complete.
+type streamShard struct {
+	spool   *os.File
+	writer  *bufio.Writer
+	imports map[string]string
+	lines   int
+	written bool
+	fixErr  error
+	fmtErr  error
+}
+
+// streamChunkLines is the approximate number of lines of code that are kept in memory in streaming
+// mode before they are formatted and written to the temporary file.
+const streamChunkLines = 1000
+
 // NewBuffer creates a builder for Golang buffers.
 func NewBuffer() *BufferBuilder {
 	return new(BufferBuilder)
@@ -90,6 +131,54 @@ func (b *BufferBuilder) File(value string) *BufferBuilder {
 	return b
 }
 
+// Constraint sets the build constraint that will be added to the generated file, for example
+// 'go1.18'. The default is to not add any build constraint.
+func (b *BufferBuilder) Constraint(value string) *BufferBuilder {
+	b.constraint = value
+	return b
+}
+
+// Header sets the header that will be added to the beginning of the generated file. The default
+// is to add the Red Hat copyright and the Apache license.
+func (b *BufferBuilder) Header(value *Header) *BufferBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead of
+// being kept in memory. When enabled the code is formatted and written to temporary files in
+// chunks of complete declarations as it is generated, so that the memory used doesn't grow with
+// the size of the generated file. The default is to keep the code in memory.
+func (b *BufferBuilder) Stream(value bool) *BufferBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated file. If the generated
+// code is longer it will be split into multiple files of the same package, named like the
+// original file with a number suffix, for example 'clusters_client_2.go'. Declarations are never
+// split, so files may be slightly longer than this limit. The default is zero, which means that
+// files are never split.
+func (b *BufferBuilder) MaxLines(value int) *BufferBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the
+// built-in templates passed to the EmitTemplate method. The default is to always use the
+// built-in templates.
+func (b *BufferBuilder) Templates(value *Templates) *BufferBuilder {
+	b.templates = value
+	return b
+}
+
+// Generator sets the name of the generator that uses the buffer, for example 'types'. It is
+// used to find the templates that replace the built-in templates.
+func (b *BufferBuilder) Generator(value string) *BufferBuilder {
+	b.generator = value
+	return b
+}
+
 // Function adds a function that can then be used in the templates.
 func (b *BufferBuilder) Function(name string, function interface{}) *BufferBuilder {
 	if b.functions == nil {
@@ -118,20 +207,39 @@ func (b *BufferBuilder) Build() (buffer *Buffer, err error) {
 		err = fmt.Errorf("file is mandatory")
 		return
 	}
+	if b.maxLines < 0 {
+		err = fmt.Errorf("maximum number of lines %d isn't valid, can't be negative", b.maxLines)
+		return
+	}
+	if b.templates != nil && b.generator == "" {
+		err = fmt.Errorf("generator is mandatory when templates are used")
+		return
+	}
 
 	// Allocate and populate the buffer:
 	buffer = new(Buffer)
 	buffer.reporter = b.reporter
 	buffer.pkg = path.Join(b.packages.BasePackage(), b.pkg)
 	buffer.file = filepath.Join(b.output, b.pkg, b.file+".go")
+	buffer.constraint = b.constraint
+	buffer.header = b.header
+	buffer.maxLines = b.maxLines
+	buffer.templates = b.templates
+	buffer.generator = b.generator
+	if buffer.header == nil {
+		buffer.header = defaultHeader
+	}
 	buffer.functions = make(map[string]interface{})
 	buffer.functions["lineComment"] = buffer.lineComment
 	buffer.functions["byteArray"] = buffer.byteArray
 	for name, function := range b.functions {
 		buffer.functions[name] = function
 	}
+	buffer.wrappers = buffer.wrapFunctions()
+	buffer.key = buffer.functionsKey()
 	buffer.imports = make(map[string]string)
 	buffer.code = new(bytes.Buffer)
+	buffer.stream = b.stream
 
 	return
 }
@@ -206,41 +314,8 @@ func (b *Buffer) Eval(tmpl string, args ...interface{}) string {
 		data[name] = b.replaceValue(value)
 	}
 
-	// Wrap each function with another function that replaces qualified names and type
-	// references in the returned values:
-	functions := make(map[string]interface{})
-	for name, function := range b.functions {
-		callable := reflect.ValueOf(function)
-		wrapper := func(args ...interface{}) (result interface{}, err error) {
-			values := make([]reflect.Value, len(args))
-			for i, arg := range args {
-				values[i] = reflect.ValueOf(arg)
-			}
-			results := callable.Call(values)
-			if len(results) > 2 {
-				err = fmt.Errorf(
-					"expected at most 2 return values from '%s' but got %d",
-					name, len(results),
-				)
-				return
-			}
-			if len(results) > 1 {
-				switch typed := results[1].Interface().(type) {
-				case error:
-					err = typed
-					return
-				}
-			}
-			result = b.replaceValue(results[0].Interface())
-			return
-		}
-		functions[name] = wrapper
-	}
-
-	// Parse the template:
-	obj, err := template.New("").
-		Funcs(functions).
-		Parse(tmpl)
+	// Get the parsed template:
+	obj, err := b.parse(tmpl)
 	if err != nil {
 		b.reporter.Errorf("Can't parse template '%s': %v", tmpl, err)
 		return ""
@@ -258,6 +333,89 @@ func (b *Buffer) Eval(tmpl string, args ...interface{}) string {
 	return buffer.String()
 }
 
+// parse returns the parsed template for the given text, bound to the functions of this buffer.
+// Parsing templates is expensive and the same templates are used many times, so the parsed
+// templates are saved in a cache shared by all the buffers. The key of the cache includes the
+// names of the functions, because they are needed to parse the template.
+func (b *Buffer) parse(text string) (result *template.Template, err error) {
+	key := b.key + "\x00" + text
+	templateCacheLock.Lock()
+	parsed, ok := templateCache[key]
+	templateCacheLock.Unlock()
+	if !ok {
+		parsed, err = template.New("").
+			Funcs(b.wrappers).
+			Parse(text)
+		if err != nil {
+			return
+		}
+		templateCacheLock.Lock()
+		templateCache[key] = parsed
+		templateCacheLock.Unlock()
+	}
+	result, err = parsed.Clone()
+	if err != nil {
+		return
+	}
+	result.Funcs(b.wrappers)
+	return
+}
+
+// wrapFunctions wraps each function with another function that replaces qualified names and
+// type references in the returned values.
+func (b *Buffer) wrapFunctions() template.FuncMap {
+	wrappers := template.FuncMap{}
+	for name, function := range b.functions {
+		wrappers[name] = b.wrapFunction(name, function)
+	}
+	return wrappers
+}
+
+func (b *Buffer) wrapFunction(name string, function interface{}) interface{} {
+	callable := reflect.ValueOf(function)
+	return func(args ...interface{}) (result interface{}, err error) {
+		values := make([]reflect.Value, len(args))
+		for i, arg := range args {
+			values[i] = reflect.ValueOf(arg)
+		}
+		results := callable.Call(values)
+		if len(results) > 2 {
+			err = fmt.Errorf(
+				"expected at most 2 return values from '%s' but got %d",
+				name, len(results),
+			)
+			return
+		}
+		if len(results) > 1 {
+			switch typed := results[1].Interface().(type) {
+			case error:
+				err = typed
+				return
+			}
+		}
+		result = b.replaceValue(results[0].Interface())
+		return
+	}
+}
+
+// functionsKey calculates the part of the key of the templates cache that corresponds to the
+// names of the functions of this buffer.
+func (b *Buffer) functionsKey() string {
+	names := make([]string, 0, len(b.functions))
+	for name := range b.functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// templateCache contains the templates that have already been parsed, indexed by the names of
+// the functions and the text of the template.
+var (
+	templateCache     = map[string]*template.Template{}
+	templateCacheLock = &sync.Mutex{}
+)
+
 // Emit writes to the code buffer, using the given template and arguments. The syntax of the
 // template is the one used by the text/template package. The arguments should be a set nave/value
 // pairs. Names should be strings, and values can be anything. These names and values will be put in
@@ -284,21 +442,40 @@ func (b *Buffer) Emit(tmpl string, args ...interface{}) {
 	// Evaluate the template:
 	text := b.Eval(tmpl, args...)
 
-	// Add the generated text to the code:
-	_, err := b.code.WriteString(text)
-	if err != nil {
-		b.reporter.Errorf("Can't add generated text '%s' to the buffer: %v", text, err)
-		return
+	// Add the generated text to the code, followed by a blank line:
+	b.code.WriteString(text + "\n")
+
+	// In streaming mode send the code to the temporary files when there is enough of it:
+	if b.stream {
+		b.pending += strings.Count(text, "\n") + 1
+		if b.pending >= b.chunkLines() && b.pending >= b.retry {
+			err := b.flushCode(false)
+			if err != nil {
+				b.reporter.Errorf("Can't write generated code to temporary file: %v", err)
+			}
+		}
 	}
+}
 
-	// Add a blank line:
-	b.code.WriteString("\n")
+// EmitTemplate is like Emit, but the template has a name, and if there is a template with that
+// name for the generator of this buffer in the templates set with the Templates method of the
+// builder then that template is used instead of the given one. Replacement templates receive
+// the same arguments and can use the same functions than the built-in templates.
+func (b *Buffer) EmitTemplate(name, tmpl string, args ...interface{}) {
+	text, ok := b.templates.Lookup(b.generator, name)
+	if ok {
+		tmpl = text
+	}
+	b.Emit(tmpl, args...)
 }
 
 // Write creates the output file and writes the generated content.
 func (b *Buffer) Write() error {
 	var err error
 
+	// Make sure that the temporary files used in streaming mode are removed:
+	defer b.Close()
+
 	// Inform that we are writing the file:
 	b.reporter.Infof("Writing file '%s'", b.file)
 
@@ -320,71 +497,424 @@ func (b *Buffer) Write() error {
 		return fmt.Errorf("can't create output directory '%s': %v", outputDir, err)
 	}
 
-	// Open the file:
-	outputFd, err := os.OpenFile(b.file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	// Write the content:
+	if b.stream {
+		return b.writeStream()
+	}
+	return b.writeMemory()
+}
+
+// Close removes the temporary files used in streaming mode. It is called automatically by the
+// Write method, but generators should also call it when they fail before writing the file. It is
+// safe to call it multiple times.
+func (b *Buffer) Close() error {
+	for _, shard := range b.shards {
+		shard.spool.Close()
+		os.Remove(shard.spool.Name())
+	}
+	b.shards = nil
+	return nil
+}
+
+// writeMemory writes the code kept in memory to the output file, or to multiple files if it is
+// longer than the maximum number of lines.
+func (b *Buffer) writeMemory() error {
+	code := new(bytes.Buffer)
+	err := b.removeExtraBlankLines(b.code, code)
 	if err != nil {
-		return fmt.Errorf("can't open output file '%s': %v", b.file, err)
+		return fmt.Errorf("can't remove extra blank lines: %v", err)
 	}
+	shards, err := b.splitCode(code.Bytes())
+	if err != nil {
+		// If the code can't be parsed then it can't be split either, so write it to one
+		// file and let the formatter report the problem:
+		shards = [][]byte{code.Bytes()}
+	}
+	for i, shard := range shards {
+		file := b.shardFile(i)
+		if i > 0 {
+			b.reporter.Infof("Writing file '%s'", file)
+		}
+		err = b.writeShard(file, shard)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// Write the header:
-	fmt.Fprintf(outputFd, "%s\n", fileHeader)
-	fmt.Fprintf(outputFd, "\n")
+// writeShard calculates the imports needed by the given code, formats it and writes it to the
+// given file. If the code can't be analyzed or formatted it is written anyhow, as that is useful
+// to find the problem.
+func (b *Buffer) writeShard(file string, code []byte) error {
+	imports, fixErr := b.fixImports(code)
+	if fixErr != nil {
+		imports = b.imports
+	}
+	source := new(bytes.Buffer)
+	b.writePreamble(source, imports)
+	source.Write(code)
+	formatted, fmtErr := format.Source(source.Bytes())
+	if fixErr != nil || fmtErr != nil {
+		formatted = source.Bytes()
+	}
+	err := ioutil.WriteFile(file, formatted, 0666)
+	if err != nil {
+		return fmt.Errorf("can't write generated code to file '%s': %v", file, err)
+	}
+	b.reporter.Written(file)
+	if fixErr != nil {
+		return fmt.Errorf("can't calculate imports of output file '%s': %v", file, fixErr)
+	}
+	if fmtErr != nil {
+		return fmt.Errorf("can't format output file '%s': %v", file, fmtErr)
+	}
+	return nil
+}
 
-	// Write the package statement:
-	pkgName := b.cleanPkg(path.Base(b.pkg))
-	fmt.Fprintf(outputFd, "package %s // %s\n", pkgName, b.pkg)
-	fmt.Fprintf(outputFd, "\n")
-
-	// Write the import statement:
-	if len(b.imports) > 0 {
-		fmt.Fprintf(outputFd, "import (\n")
-		for imprt, selector := range b.imports {
-			if selector != "" {
-				fmt.Fprintf(outputFd, "%s \"%s\"\n", selector, imprt)
-			} else {
-				fmt.Fprintf(outputFd, "\"%s\"\n", imprt)
+// splitCode splits the given code into pieces that have approximately the maximum number of lines.
+// The code is only split between top level declarations, and the comments of each declaration are
+// kept together with it.
+func (b *Buffer) splitCode(code []byte) (shards [][]byte, err error) {
+	if b.maxLines == 0 || bytes.Count(code, []byte("\n")) <= b.maxLines {
+		shards = [][]byte{code}
+		return
+	}
+	decls, err := b.splitDecls(code)
+	if err != nil {
+		return
+	}
+
+	// Cut the code before the first declaration that would make the current piece longer than
+	// the limit:
+	start, end := 0, 0
+	for _, decl := range decls {
+		if end > start && bytes.Count(code[start:end], []byte("\n")) >= b.maxLines {
+			shards = append(shards, code[start:end])
+			start = end
+		}
+		end += len(decl)
+	}
+	shards = append(shards, code[start:end])
+	return
+}
+
+// splitDecls splits the given code into consecutive pieces that contain one top level declaration
+// each, together with its comments. It returns an error if the code can't be parsed.
+func (b *Buffer) splitDecls(code []byte) (decls [][]byte, err error) {
+	// Parse the code to find the top level declarations. We need to add the package statement
+	// because the code of the buffer doesn't contain it.
+	prefix := "package " + b.cleanPkg(path.Base(b.pkg)) + "\n"
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, b.file, prefix+string(code), parser.ParseComments)
+	if err != nil {
+		return
+	}
+
+	// Cut the code before each declaration, including its documentation comments:
+	start := 0
+	for _, decl := range file.Decls {
+		pos := decl.Pos()
+		switch typed := decl.(type) {
+		case *ast.FuncDecl:
+			if typed.Doc != nil {
+				pos = typed.Doc.Pos()
+			}
+		case *ast.GenDecl:
+			if typed.Doc != nil {
+				pos = typed.Doc.Pos()
 			}
 		}
-		fmt.Fprintf(outputFd, ")\n")
-		fmt.Fprintf(outputFd, "\n")
+		offset := fset.Position(pos).Offset - len(prefix)
+		if offset > start {
+			decls = append(decls, code[start:offset])
+			start = offset
+		}
 	}
+	decls = append(decls, code[start:])
+	return
+}
+
+// shardFile calculates the name of the file for the given piece of the code. The first piece goes
+// to the original file and the rest to files with a number suffix. The suffix is added before the
+// '_test' suffix, if present, so that test files remain test files.
+func (b *Buffer) shardFile(index int) string {
+	if index == 0 {
+		return b.file
+	}
+	base := strings.TrimSuffix(b.file, ".go")
+	if strings.HasSuffix(base, "_test") {
+		base = strings.TrimSuffix(base, "_test")
+		return fmt.Sprintf("%s_%d_test.go", base, index+1)
+	}
+	return fmt.Sprintf("%s_%d.go", base, index+1)
+}
 
-	// Remove extra blank lines from the code:
-	err = b.removeExtraBlankLines()
+// chunkLines returns the number of lines of code that are accumulated in streaming mode before
+// trying to send them to the temporary files.
+func (b *Buffer) chunkLines() int {
+	if b.maxLines > 0 && b.maxLines < streamChunkLines {
+		return b.maxLines
+	}
+	return streamChunkLines
+}
+
+// flushCode formats the code accumulated in memory and appends it to the temporary files used in
+// streaming mode, starting a new file when the current one reaches the maximum number of lines.
+// If the code can't be parsed it is assumed that the last declaration isn't complete yet, and it is
+// kept in memory till more code is generated, unless this is the final flush. In that case the next
+// attempt is delayed till the amount of code in memory doubles, so that a declaration generated by
+// many calls to Emit doesn't result in parsing the same code again and again.
+func (b *Buffer) flushCode(final bool) error {
+	code := new(bytes.Buffer)
+	err := b.removeExtraBlankLines(bytes.NewReader(b.code.Bytes()), code)
 	if err != nil {
 		return fmt.Errorf("can't remove extra blank lines: %v", err)
 	}
+	data := code.Bytes()
+	decls, err := b.splitDecls(data)
+	if err != nil {
+		if !final {
+			b.retry = 2 * b.pending
+			return nil
+		}
+		decls = [][]byte{data}
+	}
+	b.code.Reset()
+	b.pending = 0
+	b.retry = 0
 
-	// Write the code:
-	_, err = b.code.WriteTo(outputFd)
+	// Send the declarations to the current file, or to a new one if the current one is
+	// already long enough:
+	shard, err := b.currentShard()
 	if err != nil {
-		return fmt.Errorf("can't write generated code to file '%s': %v", b.file, err)
+		return err
 	}
+	start, end := 0, 0
+	for _, decl := range decls {
+		if b.maxLines > 0 && shard.lines >= b.maxLines {
+			err = b.writeChunk(shard, data[start:end])
+			if err != nil {
+				return err
+			}
+			start = end
+			shard, err = b.addShard()
+			if err != nil {
+				return err
+			}
+		}
+		shard.lines += bytes.Count(decl, []byte("\n"))
+		end += len(decl)
+	}
+	return b.writeChunk(shard, data[start:end])
+}
 
-	// Close the file:
-	err = outputFd.Close()
+// currentShard returns the file where code is currently being written in streaming mode, creating
+// it if needed.
+func (b *Buffer) currentShard() (shard *streamShard, err error) {
+	if len(b.shards) == 0 {
+		return b.addShard()
+	}
+	shard = b.shards[len(b.shards)-1]
+	return
+}
+
+// addShard creates the temporary file for a new output file in streaming mode.
+func (b *Buffer) addShard() (shard *streamShard, err error) {
+	spool, err := ioutil.TempFile("", "metamodel-*.go")
 	if err != nil {
-		return fmt.Errorf("can't close output file '%s': %v", b.file, err)
+		err = fmt.Errorf("can't create temporary file for '%s': %v", b.file, err)
+		return
+	}
+	shard = &streamShard{
+		spool:   spool,
+		writer:  bufio.NewWriter(spool),
+		imports: map[string]string{},
+	}
+	b.shards = append(b.shards, shard)
+	return
+}
+
+// writeChunk calculates the imports needed by the given chunk of code, formats it and appends it to
+// the temporary file of the given output file. If the code can't be analyzed or formatted it is
+// written anyhow, and the error is reported when the output file is written.
+func (b *Buffer) writeChunk(shard *streamShard, code []byte) error {
+	if len(bytes.TrimSpace(code)) == 0 {
+		return nil
+	}
+	imports, fixErr := b.fixImports(code)
+	if fixErr != nil {
+		imports = b.imports
+	}
+	for imprt, selector := range imports {
+		shard.imports[imprt] = selector
+	}
+	formatted, fmtErr := b.formatChunk(code)
+	if fixErr != nil || fmtErr != nil {
+		formatted = code
+	}
+	if shard.fixErr == nil {
+		shard.fixErr = fixErr
 	}
+	if shard.fmtErr == nil {
+		shard.fmtErr = fmtErr
+	}
+	if shard.written {
+		shard.writer.WriteString("\n")
+	}
+	shard.writer.Write(bytes.TrimSpace(formatted))
+	_, err := shard.writer.WriteString("\n")
+	shard.written = true
+	return err
+}
 
-	// Run goimport on the file:
-	fmtCmd := exec.Command("goimports", "-w", b.file)
-	err = fmtCmd.Run()
+// formatChunk formats a chunk of code that contains complete declarations. The formatter would keep
+// the indentation of code that isn't a complete file, so the package statement is added before
+// formatting and removed afterwards.
+func (b *Buffer) formatChunk(code []byte) (result []byte, err error) {
+	source := new(bytes.Buffer)
+	fmt.Fprintf(source, "package %s\n\n", b.cleanPkg(path.Base(b.pkg)))
+	source.Write(code)
+	formatted, err := format.Source(source.Bytes())
 	if err != nil {
-		return fmt.Errorf("can't format output file '%s': %v", b.file, err)
+		return
 	}
+	index := bytes.IndexByte(formatted, '\n')
+	result = formatted[index+1:]
+	return
+}
 
+// writeStream formats the code that is still in memory and then writes the output files, copying
+// the code from the temporary files after the preamble.
+func (b *Buffer) writeStream() error {
+	if b.code.Len() > 0 || len(b.shards) == 0 {
+		err := b.flushCode(true)
+		if err != nil {
+			return fmt.Errorf("can't write generated code to temporary file: %v", err)
+		}
+	}
+	for i, shard := range b.shards {
+		file := b.shardFile(i)
+		if i > 0 {
+			b.reporter.Infof("Writing file '%s'", file)
+		}
+		err := b.writeStreamShard(file, shard)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-// removeExtraBlankLines removes the extra blank lines from the code, so that later `goimports` will
-// generate nicer code.
-func (b *Buffer) removeExtraBlankLines() error {
-	clean := new(bytes.Buffer)
+// writeStreamShard writes the preamble of the given output file, followed by the code that was
+// saved in its temporary file.
+func (b *Buffer) writeStreamShard(file string, shard *streamShard) error {
+	err := shard.writer.Flush()
+	if err != nil {
+		return fmt.Errorf("can't write generated code to temporary file: %v", err)
+	}
+	_, err = shard.spool.Seek(0, io.SeekStart)
+	if err != nil {
+		return fmt.Errorf("can't rewind temporary file: %v", err)
+	}
+	output, err := os.OpenFile(file, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666)
+	if err != nil {
+		return fmt.Errorf("can't open output file '%s': %v", file, err)
+	}
+	defer output.Close()
+	// The preamble is a valid Go file by itself, so it can be formatted separately:
+	preamble := new(bytes.Buffer)
+	b.writePreamble(preamble, shard.imports)
+	formatted, err := format.Source(preamble.Bytes())
+	if err != nil {
+		formatted = preamble.Bytes()
+	}
+	writer := bufio.NewWriter(output)
+	writer.Write(formatted)
+	writer.WriteString("\n")
+	_, err = io.Copy(writer, shard.spool)
+	if err == nil {
+		err = writer.Flush()
+	}
+	if err == nil {
+		err = output.Close()
+	}
+	if err != nil {
+		return fmt.Errorf("can't write generated code to file '%s': %v", file, err)
+	}
+	b.reporter.Written(file)
+	if shard.fixErr != nil {
+		return fmt.Errorf("can't calculate imports of output file '%s': %v", file, shard.fixErr)
+	}
+	if shard.fmtErr != nil {
+		return fmt.Errorf("can't format output file '%s': %v", file, shard.fmtErr)
+	}
+	return nil
+}
+
+// writePreamble writes the header, build constraint, package and import statements.
+func (b *Buffer) writePreamble(writer io.Writer, imports map[string]string) {
+	// Write the header:
+	fmt.Fprintf(writer, "%s\n", b.header.Text())
+	fmt.Fprintf(writer, "\n")
+
+	// Write the build constraint, combining the tags required by the header with the
+	// constraint of this file:
+	var tags []string
+	tags = append(tags, b.header.Tags()...)
+	if b.constraint != "" {
+		tags = append(tags, b.constraint)
+	}
+	if len(tags) > 0 {
+		fmt.Fprintf(writer, "//go:build %s\n", strings.Join(tags, " && "))
+		fmt.Fprintf(writer, "// +build %s\n", strings.Join(tags, ","))
+		fmt.Fprintf(writer, "\n")
+	}
+
+	// Write the package statement:
+	pkgName := b.cleanPkg(path.Base(b.pkg))
+	fmt.Fprintf(writer, "package %s // %s\n", pkgName, b.pkg)
+	fmt.Fprintf(writer, "\n")
+
+	// Write the import statement, sorted so that the result doesn't depend on the iteration
+	// order of the map, and with the packages of the standard library in a separate group:
+	if len(imports) > 0 {
+		var standard, others []string
+		for imprt := range imports {
+			if isStandardImport(imprt) {
+				standard = append(standard, imprt)
+			} else {
+				others = append(others, imprt)
+			}
+		}
+		sort.Strings(standard)
+		sort.Strings(others)
+		fmt.Fprintf(writer, "import (\n")
+		for i, group := range [][]string{standard, others} {
+			if i > 0 && len(standard) > 0 && len(group) > 0 {
+				fmt.Fprintf(writer, "\n")
+			}
+			for _, imprt := range group {
+				selector := imports[imprt]
+				if selector != "" {
+					fmt.Fprintf(writer, "%s \"%s\"\n", selector, imprt)
+				} else {
+					fmt.Fprintf(writer, "\"%s\"\n", imprt)
+				}
+			}
+		}
+		fmt.Fprintf(writer, ")\n")
+		fmt.Fprintf(writer, "\n")
+	}
+}
+
+// removeExtraBlankLines copies the code from the reader to the writer removing the extra blank
+// lines, so that later the formatter will generate nicer code.
+func (b *Buffer) removeExtraBlankLines(reader io.Reader, writer io.Writer) error {
 	var depth int
 	var curr string
 	var next string
-	scanner := bufio.NewScanner(b.code)
+	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
 		curr = next
 		next = scanner.Text()
@@ -395,21 +925,16 @@ func (b *Buffer) removeExtraBlankLines() error {
 		}
 		if currTrim == "" {
 			if depth > 0 && strings.HasPrefix(nextTrim, "//") {
-				fmt.Fprintln(clean, curr)
+				fmt.Fprintln(writer, curr)
 			}
 		} else {
-			fmt.Fprintln(clean, curr)
+			fmt.Fprintln(writer, curr)
 		}
 		if currTrim == "}" {
 			depth--
 		}
 	}
-	err := scanner.Err()
-	if err != nil {
-		return err
-	}
-	b.code = clean
-	return nil
+	return scanner.Err()
 }
 
 // replaceValue replaces type references with their corresponding text, and adds the required
@@ -471,24 +996,3 @@ func (b *Buffer) cleanPkg(name string) string {
 	}
 	return name
 }
-
-// Header that will be included in all generated files:
-const fileHeader = `/*
-Copyright (c) 2019 Red Hat, Inc.
-
-Licensed under the Apache License, Version 2.0 (the "License");
-you may not use this file except in compliance with the License.
-You may obtain a copy of the License at
-
-  http://www.apache.org/licenses/LICENSE-2.0
-
-Unless required by applicable law or agreed to in writing, software
-distributed under the License is distributed on an "AS IS" BASIS,
-WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
-See the License for the specific language governing permissions and
-limitations under the License.
-*/
-
-// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
-// your changes will be lost when the file is generated again.
-`
@@ -28,25 +28,33 @@ import (
 // BuildersGeneratorBuilder is an object used to configure and build the builders generator. Don't
 // create instances directly, use the NewBuildersGenerator function instead.
 type BuildersGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
 }
 
 // BuildersGenerator generates code for the builders of the model types. Don't create instances
 // directly, use the builder instead.
 type BuildersGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	buffer   *Buffer
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
 }
 
 // NewBuildersGenerator creates a new builder for builders generators.
@@ -92,6 +100,35 @@ func (b *BuildersGeneratorBuilder) Types(value *TypesCalculator) *BuildersGenera
 	return b
 }
 
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *BuildersGeneratorBuilder) Header(value *Header) *BuildersGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *BuildersGeneratorBuilder) Stream(value bool) *BuildersGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *BuildersGeneratorBuilder) MaxLines(value int) *BuildersGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *BuildersGeneratorBuilder) Templates(value *Templates) *BuildersGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new
 // builders generator using it.
 func (b *BuildersGeneratorBuilder) Build() (generator *BuildersGenerator, err error) {
@@ -123,12 +160,16 @@ func (b *BuildersGeneratorBuilder) Build() (generator *BuildersGenerator, err er
 
 	// Create the generator:
 	generator = &BuildersGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
-		types:    b.types,
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		types:     b.types,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
 	}
 
 	return
@@ -141,6 +182,11 @@ func (g *BuildersGenerator) Run() error {
 	// Generate the code for each type:
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating builders for version '%s'", version)
+			err = g.generateVersionMetadataBuilderFile(version)
+			if err != nil {
+				return err
+			}
 			for _, typ := range version.Types() {
 				switch {
 				case typ.IsStruct():
@@ -168,6 +214,127 @@ func (g *BuildersGenerator) Run() error {
 	return nil
 }
 
+func (g *BuildersGenerator) generateVersionMetadataBuilderFile(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.metadataFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("builders").
+		Package(pkgName).
+		File(fileName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the source:
+	g.generateVersionMetadataBuilderSource()
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *BuildersGenerator) generateVersionMetadataBuilderSource() {
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("version_metadata_builder", `
+		// MetadataBuilder contains the data and logic needed to build the version metadata.
+		type MetadataBuilder struct {
+			serverVersion  *string
+			buildTimestamp *time.Time
+			gitCommit      *string
+			generatedFrom  *string
+			features       []string
+		}
+
+		// NewMetadata creates a new builder of version metadata. The generated from attribute
+		// is initialized with the value of the GeneratedFrom constant.
+		func NewMetadata() *MetadataBuilder {
+			generatedFrom := GeneratedFrom
+			return &MetadataBuilder{
+				generatedFrom: &generatedFrom,
+			}
+		}
+
+		// ServerVersion sets the version of the server.
+		func (b *MetadataBuilder) ServerVersion(value string) *MetadataBuilder {
+			b.serverVersion = &value
+			return b
+		}
+
+		// BuildTimestamp sets the time when the server was built.
+		func (b *MetadataBuilder) BuildTimestamp(value time.Time) *MetadataBuilder {
+			b.buildTimestamp = &value
+			return b
+		}
+
+		// GitCommit sets the identifier of the git commit used to build the server.
+		func (b *MetadataBuilder) GitCommit(value string) *MetadataBuilder {
+			b.gitCommit = &value
+			return b
+		}
+
+		// GeneratedFrom sets the revision of the model and the version of the metamodel that
+		// were used to generate the code of the server.
+		func (b *MetadataBuilder) GeneratedFrom(value string) *MetadataBuilder {
+			b.generatedFrom = &value
+			return b
+		}
+
+		// Features sets the names of the features supported by the server.
+		func (b *MetadataBuilder) Features(values ...string) *MetadataBuilder {
+			b.features = make([]string, len(values))
+			copy(b.features, values)
+			return b
+		}
+
+		// Copy copies the attributes of the given metadata into this builder, discarding any
+		// previous values.
+		func (b *MetadataBuilder) Copy(object *Metadata) *MetadataBuilder {
+			if object == nil {
+				return b
+			}
+			b.serverVersion = object.serverVersion
+			b.buildTimestamp = object.buildTimestamp
+			b.gitCommit = object.gitCommit
+			b.generatedFrom = object.generatedFrom
+			if object.features != nil {
+				b.features = make([]string, len(object.features))
+				copy(b.features, object.features)
+			} else {
+				b.features = nil
+			}
+			return b
+		}
+
+		// Build creates the version metadata using the configuration stored in the builder.
+		func (b *MetadataBuilder) Build() (object *Metadata, err error) {
+			object = new(Metadata)
+			object.serverVersion = b.serverVersion
+			object.buildTimestamp = b.buildTimestamp
+			object.gitCommit = b.gitCommit
+			object.generatedFrom = b.generatedFrom
+			if b.features != nil {
+				object.features = make([]string, len(b.features))
+				copy(object.features, b.features)
+			}
+			return
+		}
+		`,
+	)
+}
+
 func (g *BuildersGenerator) generateStructBuilderFile(typ *concepts.Type) error {
 	var err error
 
@@ -180,6 +347,11 @@ func (g *BuildersGenerator) generateStructBuilderFile(typ *concepts.Type) error
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("builders").
 		Package(pkgName).
 		File(fileName).
 		Function("builderCtor", g.builderCtor).
@@ -190,10 +362,12 @@ func (g *BuildersGenerator) generateStructBuilderFile(typ *concepts.Type) error
 		Function("setterName", g.setterName).
 		Function("setterType", g.setterType).
 		Function("valueType", g.valueType).
+		Function("buildMethod", g.buildMethod).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateStructBuilderSource(typ)
@@ -204,7 +378,8 @@ func (g *BuildersGenerator) generateStructBuilderFile(typ *concepts.Type) error
 }
 
 func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
-	g.buffer.Emit(`
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("struct_builder", `
 		{{ $builderName := builderName .Type }}
 		{{ $builderCtor := builderCtor .Type }}
 		{{ $objectName := objectName .Type }}
@@ -299,6 +474,9 @@ func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
 			if object == nil {
 				return b
 			}
+			{{ if .Type.HasLazyAttributes }}
+				object.decode()
+			{{ end }}
 			{{ if .Type.IsClass }}
 				b.id = object.id
 				b.href = object.href
@@ -334,7 +512,7 @@ func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
 						b.{{ $fieldName }} = nil
 					}
 				{{ else if .Type.IsMap }}
-					if len(object.{{ $fieldName }}) > 0 {
+					if object.{{ $fieldName }} != nil {
 						{{ if .Type.Element.IsScalar }}
 							b.{{ $fieldName }} = make({{ $fieldType }})
 							for k, v := range object.{{ $fieldName }} {
@@ -354,8 +532,28 @@ func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
 			return b
 		}
 
-		// Build creates a '{{ .Type.Name }}' object using the configuration stored in the builder.
+		// Build creates a '{{ .Type.Name }}' object using the configuration stored in the builder,
+		// and checks that the values satisfy the constraints of the model. If they don't the
+		// returned error will be of type helpers.ValidationErrors.
 		func (b *{{ $builderName }}) Build() (object *{{ $objectName }}, err error) {
+			object, err = b.build()
+			if err != nil {
+				return
+			}
+			var errs helpers.ValidationErrors
+			object.validate("", &errs)
+			if len(errs) > 0 {
+				object = nil
+				err = errs
+			}
+			return
+		}
+
+		// build creates a '{{ .Type.Name }}' object using the configuration stored in the builder,
+		// without checking the constraints. Nested objects of the same version are also created
+		// without checking them, as they are checked together with the object that contains them,
+		// so that the problems are reported with the complete path.
+		func (b *{{ $builderName }}) build() (object *{{ $objectName }}, err error) {
 			object = new({{ $objectName }})
 			{{ if .Type.IsClass }}
 				object.id = b.id
@@ -369,7 +567,7 @@ func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
 					object.{{ $fieldName }} = b.{{ $fieldName }}
 				{{ else if .Type.IsStruct }}
 					if b.{{ $fieldName }} != nil {
-						object.{{ $fieldName }}, err = b.{{ $fieldName }}.Build()
+						object.{{ $fieldName }}, err = b.{{ $fieldName }}.{{ buildMethod .Type $.Type }}()
 						if err != nil {
 							return
 						}
@@ -388,7 +586,7 @@ func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
 							{{ else if .Type.Element.IsStruct }}
 								object.{{ $fieldName }} = make([]*{{ objectName .Type.Element }}, len(b.{{ $fieldName }}))
 								for i, v := range b.{{ $fieldName }} {
-									object.{{ $fieldName }}[i], err = v.Build()
+									object.{{ $fieldName }}[i], err = v.{{ buildMethod .Type.Element $.Type }}()
 									if err != nil {
 										return
 									}
@@ -406,7 +604,7 @@ func (g *BuildersGenerator) generateStructBuilderSource(typ *concepts.Type) {
 						{{ else if .Type.Element.IsStruct }}
 							object.{{ $fieldName }}  = make(map[string]*{{ objectName .Type.Element }})
 							for k, v := range b.{{ $fieldName }} {
-								object.{{ $fieldName }}[k], err = v.Build()
+								object.{{ $fieldName }}[k], err = v.{{ buildMethod .Type.Element $.Type }}()
 								if err != nil {
 									return
 								}
@@ -434,6 +632,11 @@ func (g *BuildersGenerator) generateListBuilderFile(typ *concepts.Type) error {
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("builders").
 		Package(pkgName).
 		File(fileName).
 		Function("builderCtor", g.builderCtor).
@@ -443,6 +646,7 @@ func (g *BuildersGenerator) generateListBuilderFile(typ *concepts.Type) error {
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateListBuilderSource(typ)
@@ -458,7 +662,7 @@ func (g *BuildersGenerator) generateListBuilderSource(typ *concepts.Type) {
 }
 
 func (g *BuildersGenerator) generateStructListBuilderSource(typ *concepts.Type) {
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("struct_list_builder", `
 		{{ $objectName := objectName .Type }}
 		{{ $builderName := builderName .Type }}
 		{{ $builderCtor := builderCtor .Type }}
@@ -516,6 +720,20 @@ func (g *BuildersGenerator) generateStructListBuilderSource(typ *concepts.Type)
 	)
 }
 
+// buildMethod returns the name of the method that should be used to build the nested objects of
+// the given type inside an object of the given container type. Objects of the same version use the
+// method that doesn't check the constraints, because they are checked together with the container.
+func (g *BuildersGenerator) buildMethod(typ, container *concepts.Type) string {
+	if typ.Owner() == container.Owner() {
+		return "build"
+	}
+	return "Build"
+}
+
+func (g *BuildersGenerator) metadataFile() string {
+	return g.names.File(names.Cat(nomenclator.Metadata, nomenclator.Builder))
+}
+
 func (g *BuildersGenerator) fileName(typ *concepts.Type) string {
 	return g.names.File(names.Cat(typ.Name(), nomenclator.Builder))
 }
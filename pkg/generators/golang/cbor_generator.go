@@ -0,0 +1,1567 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// CBORSupportGeneratorBuilder is an object used to configure and build the CBOR support
+// generator. Don't create instances directly, use the NewCBORSupportGenerator function instead.
+type CBORSupportGeneratorBuilder struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	binding   *http.BindingCalculator
+	clients   bool
+	servers   bool
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+}
+
+// CBORSupportGenerator generates code to encode and decode the model types using CBOR, as an
+// alternative to JSON that uses less bandwidth and less CPU. Don't create instances directly, use
+// the builder instead.
+type CBORSupportGenerator struct {
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	binding   *http.BindingCalculator
+	clients   bool
+	servers   bool
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
+}
+
+// NewCBORSupportGenerator creates a new builder for CBOR support code generators.
+func NewCBORSupportGenerator() *CBORSupportGeneratorBuilder {
+	return &CBORSupportGeneratorBuilder{
+		clients: true,
+		servers: true,
+	}
+}
+
+// Reporter sets the object that will be used to report information about the generation process,
+// including errors.
+func (b *CBORSupportGeneratorBuilder) Reporter(
+	value *reporter.Reporter) *CBORSupportGeneratorBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be used by the generator.
+func (b *CBORSupportGeneratorBuilder) Model(value *concepts.Model) *CBORSupportGeneratorBuilder {
+	b.model = value
+	return b
+}
+
+// Output sets import path of the output package.
+func (b *CBORSupportGeneratorBuilder) Output(value string) *CBORSupportGeneratorBuilder {
+	b.output = value
+	return b
+}
+
+// Packages sets the object that will be used to calculate package names.
+func (b *CBORSupportGeneratorBuilder) Packages(
+	value *PackagesCalculator) *CBORSupportGeneratorBuilder {
+	b.packages = value
+	return b
+}
+
+// Names sets the object that will be used to calculate names.
+func (b *CBORSupportGeneratorBuilder) Names(value *NamesCalculator) *CBORSupportGeneratorBuilder {
+	b.names = value
+	return b
+}
+
+// Types sets the object that will be used to calculate types.
+func (b *CBORSupportGeneratorBuilder) Types(value *TypesCalculator) *CBORSupportGeneratorBuilder {
+	b.types = value
+	return b
+}
+
+// Binding sets the object that will by used to do HTTP binding calculations.
+func (b *CBORSupportGeneratorBuilder) Binding(
+	value *http.BindingCalculator) *CBORSupportGeneratorBuilder {
+	b.binding = value
+	return b
+}
+
+// Clients sets the flag that indicates if the code used by the clients to read responses should
+// be generated. The default is to generate it.
+func (b *CBORSupportGeneratorBuilder) Clients(value bool) *CBORSupportGeneratorBuilder {
+	b.clients = value
+	return b
+}
+
+// Servers sets the flag that indicates if the code used by the servers to write responses should
+// be generated. The default is to generate it.
+func (b *CBORSupportGeneratorBuilder) Servers(value bool) *CBORSupportGeneratorBuilder {
+	b.servers = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *CBORSupportGeneratorBuilder) Header(value *Header) *CBORSupportGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be written to disk as it is
+// generated instead of being kept in memory. The default is to keep it in memory.
+func (b *CBORSupportGeneratorBuilder) Stream(value bool) *CBORSupportGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *CBORSupportGeneratorBuilder) MaxLines(value int) *CBORSupportGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *CBORSupportGeneratorBuilder) Templates(value *Templates) *CBORSupportGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new CBOR
+// support generator using it.
+func (b *CBORSupportGeneratorBuilder) Build() (generator *CBORSupportGenerator, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output is mandatory")
+		return
+	}
+	if b.packages == nil {
+		err = fmt.Errorf("packages calculator is mandatory")
+		return
+	}
+	if b.names == nil {
+		err = fmt.Errorf("names calculator is mandatory")
+		return
+	}
+	if b.types == nil {
+		err = fmt.Errorf("types calculator is mandatory")
+		return
+	}
+	if b.binding == nil {
+		err = fmt.Errorf("binding calculator is mandatory")
+		return
+	}
+
+	// Create the generator:
+	generator = &CBORSupportGenerator{
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		types:     b.types,
+		binding:   b.binding,
+		clients:   b.clients,
+		servers:   b.servers,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+	}
+
+	return
+}
+
+// Run executes the code generator.
+func (g *CBORSupportGenerator) Run() error {
+	var err error
+
+	// Generate the helpers:
+	err = g.generateHelpers()
+	if err != nil {
+		return err
+	}
+
+	// Generate the code for each type:
+	for _, service := range g.model.Services() {
+		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating CBOR support for version '%s'", version)
+			// Generate the code for the model types:
+			for _, typ := range version.Types() {
+				switch {
+				case typ.IsStruct():
+					err = g.generateStructTypeSupport(typ)
+				case typ.IsList():
+					element := typ.Element()
+					if element.IsScalar() || element.IsStruct() {
+						err = g.generateListTypeSupport(typ)
+					}
+				}
+				if err != nil {
+					return err
+				}
+			}
+
+			// Generate the code for the model methods, unless neither clients nor
+			// servers are generated:
+			if g.clients || g.servers {
+				for _, resource := range version.Resources() {
+					err = g.generateResourceSupport(resource)
+					if err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	// Check if there were errors:
+	if g.errors > 0 {
+		if g.errors > 1 {
+			err = fmt.Errorf("there were %d errors", g.errors)
+		} else {
+			err = fmt.Errorf("there was 1 error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *CBORSupportGenerator) generateHelpers() error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.HelpersPackage()
+	fileName := g.helpersFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("cbor").
+		Package(pkgName).
+		File(fileName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.buffer.Import("bufio", "")
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("encoding/binary", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("math", "")
+	g.buffer.Import("mime", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("sort", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("helpers", `
+		// CBORContentType is the media type used for request and response bodies encoded with CBOR.
+		const CBORContentType = "application/cbor"
+
+		// IsCBOR checks if the Content-Type header of the given header indicates that the body is
+		// encoded with CBOR.
+		func IsCBOR(header http.Header) bool {
+			mediaType, _, err := mime.ParseMediaType(header.Get("Content-Type"))
+			return err == nil && mediaType == CBORContentType
+		}
+
+		// Major types of CBOR data items:
+		const (
+			cborUnsigned byte = 0
+			cborNegative byte = 1
+			cborBytes    byte = 2
+			cborText     byte = 3
+			cborArray    byte = 4
+			cborMap      byte = 5
+			cborTag      byte = 6
+			cborSimple   byte = 7
+		)
+
+		// Additional information values and simple values of CBOR data items:
+		const (
+			cborIndefinite byte = 31
+			cborFalse      byte = 20
+			cborTrue       byte = 21
+			cborNull       byte = 22
+			cborFloat16    byte = 25
+			cborFloat32    byte = 26
+			cborFloat64    byte = 27
+			cborBreak      byte = 0xff
+		)
+
+		// Tags of CBOR data items:
+		const (
+			cborDateTimeTag = 0
+			cborEpochTag    = 1
+		)
+
+		// CBOREncoder writes values encoded with CBOR to a writer. Maps and arrays are written with
+		// indefinite length, so that it isn't necessary to know the number of items in advance. Errors
+		// are remembered and returned by the Flush method.
+		type CBOREncoder struct {
+			writer *bufio.Writer
+			buffer [9]byte
+			err    error
+		}
+
+		// NewCBOREncoder creates a new encoder that writes to the given writer. Remember to call the
+		// Flush method when finished.
+		func NewCBOREncoder(writer io.Writer) *CBOREncoder {
+			return &CBOREncoder{
+				writer: bufio.NewWriter(writer),
+			}
+		}
+
+		// WriteMapStart writes the start of a map of indefinite length. It must be followed by the keys
+		// and values and then by a call to WriteEnd.
+		func (e *CBOREncoder) WriteMapStart() {
+			e.writer.WriteByte(cborMap<<5 | cborIndefinite)
+		}
+
+		// WriteArrayStart writes the start of an array of indefinite length. It must be followed by the
+		// items and then by a call to WriteEnd.
+		func (e *CBOREncoder) WriteArrayStart() {
+			e.writer.WriteByte(cborArray<<5 | cborIndefinite)
+		}
+
+		// WriteEnd writes the end of a map or array started with WriteMapStart or WriteArrayStart.
+		func (e *CBOREncoder) WriteEnd() {
+			e.writer.WriteByte(cborBreak)
+		}
+
+		// WriteBool writes a boolean value.
+		func (e *CBOREncoder) WriteBool(value bool) {
+			if value {
+				e.writer.WriteByte(cborSimple<<5 | cborTrue)
+			} else {
+				e.writer.WriteByte(cborSimple<<5 | cborFalse)
+			}
+		}
+
+		// WriteInt writes an integer value.
+		func (e *CBOREncoder) WriteInt(value int) {
+			e.WriteInt64(int64(value))
+		}
+
+		// WriteInt64 writes a 64 bits integer value.
+		func (e *CBOREncoder) WriteInt64(value int64) {
+			if value >= 0 {
+				e.writeHead(cborUnsigned, uint64(value))
+			} else {
+				e.writeHead(cborNegative, uint64(-1-value))
+			}
+		}
+
+		// WriteFloat64 writes a floating point value.
+		func (e *CBOREncoder) WriteFloat64(value float64) {
+			e.buffer[0] = cborSimple<<5 | cborFloat64
+			binary.BigEndian.PutUint64(e.buffer[1:], math.Float64bits(value))
+			e.writer.Write(e.buffer[:9])
+		}
+
+		// WriteString writes a text string.
+		func (e *CBOREncoder) WriteString(value string) {
+			e.writeHead(cborText, uint64(len(value)))
+			e.writer.WriteString(value)
+		}
+
+		// WriteDate writes a date as a text string in RFC3339 format, tagged as a standard date.
+		func (e *CBOREncoder) WriteDate(value time.Time) {
+			e.writeHead(cborTag, cborDateTimeTag)
+			e.WriteString(value.Format(time.RFC3339))
+		}
+
+		// WriteValue writes a value of any of the types used when decoding JSON into an empty
+		// interface: nil, booleans, numbers, strings, slices of values and maps of values.
+		func (e *CBOREncoder) WriteValue(value interface{}) {
+			switch typed := value.(type) {
+			case nil:
+				e.writer.WriteByte(cborSimple<<5 | cborNull)
+			case bool:
+				e.WriteBool(typed)
+			case int:
+				e.WriteInt(typed)
+			case int64:
+				e.WriteInt64(typed)
+			case float64:
+				e.WriteFloat64(typed)
+			case json.Number:
+				integer, err := typed.Int64()
+				if err == nil {
+					e.WriteInt64(integer)
+					return
+				}
+				float, err := typed.Float64()
+				if err != nil {
+					e.fail(err)
+					return
+				}
+				e.WriteFloat64(float)
+			case string:
+				e.WriteString(typed)
+			case []interface{}:
+				e.WriteArrayStart()
+				for _, item := range typed {
+					e.WriteValue(item)
+				}
+				e.WriteEnd()
+			case map[string]interface{}:
+				keys := make([]string, 0, len(typed))
+				for key := range typed {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				e.WriteMapStart()
+				for _, key := range keys {
+					e.WriteString(key)
+					e.WriteValue(typed[key])
+				}
+				e.WriteEnd()
+			default:
+				e.fail(fmt.Errorf("don't know how to encode value of type '%T'", value))
+			}
+		}
+
+		// Flush writes to the underlying writer any buffered data, and returns the first error that
+		// happened while writing.
+		func (e *CBOREncoder) Flush() error {
+			err := e.writer.Flush()
+			if e.err != nil {
+				return e.err
+			}
+			return err
+		}
+
+		func (e *CBOREncoder) fail(err error) {
+			if e.err == nil {
+				e.err = err
+			}
+		}
+
+		func (e *CBOREncoder) writeHead(major byte, value uint64) {
+			switch {
+			case value < 24:
+				e.buffer[0] = major<<5 | byte(value)
+				e.writer.Write(e.buffer[:1])
+			case value <= math.MaxUint8:
+				e.buffer[0] = major<<5 | 24
+				e.buffer[1] = byte(value)
+				e.writer.Write(e.buffer[:2])
+			case value <= math.MaxUint16:
+				e.buffer[0] = major<<5 | 25
+				binary.BigEndian.PutUint16(e.buffer[1:], uint16(value))
+				e.writer.Write(e.buffer[:3])
+			case value <= math.MaxUint32:
+				e.buffer[0] = major<<5 | 26
+				binary.BigEndian.PutUint32(e.buffer[1:], uint32(value))
+				e.writer.Write(e.buffer[:5])
+			default:
+				e.buffer[0] = major<<5 | 27
+				binary.BigEndian.PutUint64(e.buffer[1:], value)
+				e.writer.Write(e.buffer[:9])
+			}
+		}
+
+		// CBORMaxDepth is the maximum number of nested maps, arrays and indefinite length strings
+		// that the decoder accepts. Deeper data items are rejected, so that a small body can't
+		// exhaust the stack of the goroutine that decodes it.
+		const CBORMaxDepth = 1000
+
+		// CBORDecoder reads values encoded with CBOR from a reader. The first error is saved in the
+		// Error field and after that all the read methods return zero values.
+		type CBORDecoder struct {
+			reader *bufio.Reader
+			buffer [8]byte
+			depth  int
+			Error  error
+		}
+
+		// NewCBORDecoder creates a new decoder that will read from the given source, which can be a
+		// slice of bytes, a string or a reader.
+		func NewCBORDecoder(source interface{}) (decoder *CBORDecoder, err error) {
+			var reader io.Reader
+			switch typed := source.(type) {
+			case []byte:
+				reader = bytes.NewReader(typed)
+			case string:
+				reader = strings.NewReader(typed)
+			case io.Reader:
+				reader = typed
+			default:
+				err = fmt.Errorf(
+					"expected bytes, string or reader but got '%T'",
+					source,
+				)
+				return
+			}
+			decoder = &CBORDecoder{
+				reader: bufio.NewReader(reader),
+			}
+			return
+		}
+
+		// ReadMap reads the start of a map and returns the number of entries, or -1 if the map has
+		// indefinite length. Use the More method to check if there are more entries.
+		func (d *CBORDecoder) ReadMap() int {
+			return d.readContainer(cborMap)
+		}
+
+		// ReadArray reads the start of an array and returns the number of items, or -1 if the array has
+		// indefinite length. Use the More method to check if there are more items.
+		func (d *CBORDecoder) ReadArray() int {
+			return d.readContainer(cborArray)
+		}
+
+		// More checks if there are more entries in a map or items in an array, given the length returned
+		// by ReadMap or ReadArray and the number of entries or items already read. When the container has
+		// indefinite length and there are no more entries or items it consumes the end marker. Callers
+		// must call it till it returns false, as that is how the decoder knows that the container has
+		// ended.
+		func (d *CBORDecoder) More(length, index int) bool {
+			if d.Error != nil {
+				return false
+			}
+			if length >= 0 {
+				if index < length {
+					return true
+				}
+				d.depth--
+				return false
+			}
+			next, err := d.reader.ReadByte()
+			if err != nil {
+				d.fail(err)
+				return false
+			}
+			if next == cborBreak {
+				d.depth--
+				return false
+			}
+			d.reader.UnreadByte()
+			return true
+		}
+
+		// ReadBool reads a boolean value.
+		func (d *CBORDecoder) ReadBool() bool {
+			major, info, _ := d.readHead()
+			if d.Error != nil {
+				return false
+			}
+			if major == cborSimple && info == cborTrue {
+				return true
+			}
+			if major == cborSimple && info == cborFalse {
+				return false
+			}
+			d.unexpected("boolean", major)
+			return false
+		}
+
+		// ReadInt reads an integer value.
+		func (d *CBORDecoder) ReadInt() int {
+			return int(d.ReadInt64())
+		}
+
+		// ReadInt64 reads a 64 bits integer value.
+		func (d *CBORDecoder) ReadInt64() int64 {
+			major, _, value := d.readHead()
+			if d.Error != nil {
+				return 0
+			}
+			switch {
+			case major == cborUnsigned && value <= math.MaxInt64:
+				return int64(value)
+			case major == cborNegative && value <= math.MaxInt64:
+				return -1 - int64(value)
+			case major == cborUnsigned || major == cborNegative:
+				d.fail(fmt.Errorf("integer value doesn't fit in 64 bits"))
+				return 0
+			}
+			d.unexpected("integer", major)
+			return 0
+		}
+
+		// ReadFloat64 reads a floating point value. Integer values are also accepted.
+		func (d *CBORDecoder) ReadFloat64() float64 {
+			major, info, value := d.readHead()
+			if d.Error != nil {
+				return 0
+			}
+			switch {
+			case major == cborUnsigned:
+				return float64(value)
+			case major == cborNegative:
+				return -1 - float64(value)
+			case major == cborSimple && info == cborFloat16:
+				return float16(uint16(value))
+			case major == cborSimple && info == cborFloat32:
+				return float64(math.Float32frombits(uint32(value)))
+			case major == cborSimple && info == cborFloat64:
+				return math.Float64frombits(value)
+			}
+			d.unexpected("floating point number", major)
+			return 0
+		}
+
+		// ReadString reads a text string.
+		func (d *CBORDecoder) ReadString() string {
+			major, info, value := d.readHead()
+			if d.Error != nil {
+				return ""
+			}
+			if major != cborText {
+				d.unexpected("text string", major)
+				return ""
+			}
+			if info != cborIndefinite {
+				return string(d.readBytes(value))
+			}
+			if !d.enter() {
+				return ""
+			}
+			var builder strings.Builder
+			for d.More(-1, 0) {
+				builder.WriteString(d.ReadString())
+			}
+			return builder.String()
+		}
+
+		// ReadDate reads a date. It accepts text strings in RFC3339 format and numbers of seconds since
+		// the epoch.
+		func (d *CBORDecoder) ReadDate() time.Time {
+			major, _, _ := d.peekHead()
+			if d.Error != nil {
+				return time.Time{}
+			}
+			if major != cborText {
+				seconds := d.ReadFloat64()
+				whole, fraction := math.Modf(seconds)
+				return time.Unix(int64(whole), int64(fraction*1e9)).UTC()
+			}
+			text := d.ReadString()
+			value, err := time.Parse(time.RFC3339, text)
+			if err != nil {
+				d.fail(err)
+			}
+			return value
+		}
+
+		// ReadValue reads a value of any type and returns it using the types used when decoding JSON
+		// into an empty interface, except that integers are returned as int64 values.
+		func (d *CBORDecoder) ReadValue() interface{} {
+			major, info, _ := d.peekHead()
+			if d.Error != nil {
+				return nil
+			}
+			switch major {
+			case cborUnsigned, cborNegative:
+				return d.ReadInt64()
+			case cborText:
+				return d.ReadString()
+			case cborArray:
+				result := []interface{}{}
+				length := d.ReadArray()
+				for i := 0; d.More(length, i); i++ {
+					result = append(result, d.ReadValue())
+				}
+				return result
+			case cborMap:
+				result := map[string]interface{}{}
+				length := d.ReadMap()
+				for i := 0; d.More(length, i); i++ {
+					key := d.ReadString()
+					result[key] = d.ReadValue()
+				}
+				return result
+			case cborSimple:
+				switch info {
+				case cborTrue, cborFalse:
+					return d.ReadBool()
+				case cborNull:
+					d.readHead()
+					return nil
+				default:
+					return d.ReadFloat64()
+				}
+			}
+			d.unexpected("value", major)
+			return nil
+		}
+
+		// Skip reads and discards the next value, including all the nested values if it is a map or an
+		// array.
+		func (d *CBORDecoder) Skip() {
+			major, info, value := d.readHead()
+			if d.Error != nil {
+				return
+			}
+			switch major {
+			case cborBytes, cborText:
+				if info != cborIndefinite {
+					d.readBytes(value)
+					return
+				}
+				if !d.enter() {
+					return
+				}
+				for d.More(-1, 0) {
+					d.Skip()
+				}
+			case cborArray, cborMap:
+				count := int(value)
+				if info == cborIndefinite {
+					count = -1
+				} else if value > math.MaxInt32 {
+					d.fail(fmt.Errorf("container has too many items"))
+					return
+				}
+				if major == cborMap && count > 0 {
+					count *= 2
+				}
+				if !d.enter() {
+					return
+				}
+				for i := 0; d.More(count, i); i++ {
+					d.Skip()
+					if major == cborMap && count < 0 {
+						d.Skip()
+					}
+				}
+			}
+		}
+
+		func (d *CBORDecoder) readContainer(major byte) int {
+			actual, info, value := d.readHead()
+			if d.Error != nil {
+				return 0
+			}
+			if actual != major {
+				if major == cborMap {
+					d.unexpected("map", actual)
+				} else {
+					d.unexpected("array", actual)
+				}
+				return 0
+			}
+			if info != cborIndefinite && value > math.MaxInt32 {
+				d.fail(fmt.Errorf("container has too many items"))
+				return 0
+			}
+			if !d.enter() {
+				return 0
+			}
+			if info == cborIndefinite {
+				return -1
+			}
+			return int(value)
+		}
+
+		// enter increments the nesting depth when a map, an array or an indefinite length string
+		// starts, and fails if it exceeds the maximum. The More method decrements it when the
+		// container ends.
+		func (d *CBORDecoder) enter() bool {
+			d.depth++
+			if d.depth > CBORMaxDepth {
+				d.fail(fmt.Errorf("data items are nested more than %d levels", CBORMaxDepth))
+				return false
+			}
+			return true
+		}
+
+		// peekHead returns the major type of the next data item, skipping tags, without consuming it.
+		func (d *CBORDecoder) peekHead() (major, info byte, value uint64) {
+			for {
+				next, err := d.reader.Peek(1)
+				if err != nil {
+					d.fail(err)
+					return
+				}
+				if next[0]>>5 != cborTag {
+					major = next[0] >> 5
+					info = next[0] & 0x1f
+					return
+				}
+				d.readRawHead()
+				if d.Error != nil {
+					return
+				}
+			}
+		}
+
+		// readHead reads the head of the next data item, skipping tags.
+		func (d *CBORDecoder) readHead() (major, info byte, value uint64) {
+			for {
+				major, info, value = d.readRawHead()
+				if d.Error != nil || major != cborTag {
+					return
+				}
+			}
+		}
+
+		// readRawHead reads the head of the next data item, including tags.
+		func (d *CBORDecoder) readRawHead() (major, info byte, value uint64) {
+			if d.Error != nil {
+				return
+			}
+			first, err := d.reader.ReadByte()
+			if err != nil {
+				d.fail(err)
+				return
+			}
+			major = first >> 5
+			info = first & 0x1f
+			switch {
+			case info < 24:
+				value = uint64(info)
+			case info == 24:
+				value = uint64(d.readBytes(1)[0])
+			case info == 25:
+				value = uint64(binary.BigEndian.Uint16(d.readBytes(2)))
+			case info == 26:
+				value = uint64(binary.BigEndian.Uint32(d.readBytes(4)))
+			case info == 27:
+				value = binary.BigEndian.Uint64(d.readBytes(8))
+			case info == cborIndefinite && major != cborUnsigned && major != cborNegative &&
+				major != cborTag:
+				// Indefinite length, or break marker.
+			default:
+				d.fail(fmt.Errorf("invalid additional information %d", info))
+			}
+			return
+		}
+
+		// readBytes reads the given number of bytes. Small amounts are read into the buffer of the
+		// decoder, so the result is only valid till the next read. Large amounts are read incrementally,
+		// so that a corrupted length doesn't result in a large allocation.
+		func (d *CBORDecoder) readBytes(count uint64) []byte {
+			if count <= uint64(len(d.buffer)) {
+				result := d.buffer[:count]
+				_, err := io.ReadFull(d.reader, result)
+				if err != nil {
+					d.fail(err)
+					for i := range result {
+						result[i] = 0
+					}
+				}
+				return result
+			}
+			if count > math.MaxInt64 {
+				d.fail(fmt.Errorf("data item is too large"))
+				return nil
+			}
+			buffer := &bytes.Buffer{}
+			_, err := io.CopyN(buffer, d.reader, int64(count))
+			if err != nil {
+				d.fail(err)
+				return nil
+			}
+			return buffer.Bytes()
+		}
+
+		func (d *CBORDecoder) unexpected(expected string, major byte) {
+			d.fail(fmt.Errorf("expected %s but found data item of major type %d", expected, major))
+		}
+
+		func (d *CBORDecoder) fail(err error) {
+			if d.Error == nil {
+				if err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				d.Error = err
+			}
+		}
+
+		// float16 converts the bits of an IEEE 754 half precision number to a float64.
+		func float16(bits uint16) float64 {
+			exponent := int(bits>>10) & 0x1f
+			mantissa := float64(bits & 0x3ff)
+			var value float64
+			switch exponent {
+			case 0:
+				value = math.Ldexp(mantissa, -24)
+			case 0x1f:
+				if mantissa == 0 {
+					value = math.Inf(1)
+				} else {
+					value = math.NaN()
+				}
+			default:
+				value = math.Ldexp(mantissa+1024, exponent-25)
+			}
+			if bits&0x8000 != 0 {
+				value = -value
+			}
+			return value
+		}
+	`)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *CBORSupportGenerator) generateStructTypeSupport(typ *concepts.Type) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(typ.Owner())
+	fileName := g.typeFile(typ)
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("cbor").
+		Package(pkgName).
+		File(fileName).
+		Function("attributeFieldName", g.attributeFieldName).
+		Function("attributeFieldTag", g.binding.AttributeName).
+		Function("enumName", g.types.EnumName).
+		Function("generateReadAttribute", g.generateReadAttribute).
+		Function("generateReadValue", g.generateReadValue).
+		Function("generateWriteAlwaysAttribute", g.generateWriteAlwaysAttribute).
+		Function("generateWriteAttribute", g.generateWriteAttribute).
+		Function("generateWriteValue", g.generateWriteValue).
+		Function("marshalTypeFunc", g.marshalTypeFunc).
+		Function("readTypeFunc", g.readTypeFunc).
+		Function("structName", g.types.StructName).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Function("valueReference", g.types.ValueReference).
+		Function("writeTypeFunc", g.writeTypeFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateStructTypeSource(typ)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *CBORSupportGenerator) generateStructTypeSource(typ *concepts.Type) {
+	g.buffer.Import("io", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("struct_type", `
+		{{ $structName := structName .Type }}
+		{{ $marshalTypeFunc := marshalTypeFunc .Type }}
+		{{ $writeTypeFunc := writeTypeFunc .Type }}
+		{{ $unmarshalTypeFunc := unmarshalTypeFunc .Type }}
+		{{ $readTypeFunc := readTypeFunc .Type }}
+
+		// {{ $marshalTypeFunc }} writes a value of the '{{ .Type.Name }}' type to the given
+		// writer, encoded with CBOR.
+		func {{ $marshalTypeFunc }}(object *{{ $structName }}, writer io.Writer) error {
+			encoder := helpers.NewCBOREncoder(writer)
+			{{ $writeTypeFunc }}(object, encoder)
+			return encoder.Flush()
+		}
+
+		// {{ $writeTypeFunc }} writes a value of the '{{ .Type.Name }}' type to the given
+		// encoder.
+		func {{ $writeTypeFunc }}(object *{{ $structName }}, encoder *helpers.CBOREncoder) {
+			{{ if .Type.HasLazyAttributes }}
+				object.decode()
+			{{ end }}
+			encoder.WriteMapStart()
+			{{ if .Type.IsClass }}
+				encoder.WriteString("kind")
+				if object.link {
+					encoder.WriteString({{ $structName }}LinkKind)
+				} else {
+					encoder.WriteString({{ $structName }}Kind)
+				}
+				{{ generateWriteAttribute "id" "id" .Type.Owner.StringType false }}
+				{{ generateWriteAttribute "href" "href" .Type.Owner.StringType false }}
+			{{ end }}
+			{{ range .Type.Attributes }}
+				{{ if .Always }}
+					{{ generateWriteAlwaysAttribute (attributeFieldName .) (attributeFieldTag .) .Type }}
+				{{ else }}
+					{{ generateWriteAttribute (attributeFieldName .) (attributeFieldTag .) .Type .Link }}
+				{{ end }}
+			{{ end }}
+			encoder.WriteEnd()
+		}
+
+		// {{ $unmarshalTypeFunc }} reads a value of the '{{ .Type.Name }}' type encoded with
+		// CBOR from the given source, which can be a slice of bytes, a string or a reader.
+		func {{ $unmarshalTypeFunc }}(source interface{}) (object *{{ $structName }}, err error) {
+			decoder, err := helpers.NewCBORDecoder(source)
+			if err != nil {
+				return
+			}
+			object = {{ $readTypeFunc }}(decoder)
+			err = decoder.Error
+			return
+		}
+
+		// {{ $readTypeFunc }} reads a value of the '{{ .Type.Name }}' type from the given
+		// decoder.
+		func {{ $readTypeFunc }}(decoder *helpers.CBORDecoder) *{{ $structName }} {
+			object := &{{ $structName }}{}
+			length := decoder.ReadMap()
+			for i := 0; decoder.More(length, i); i++ {
+				field := decoder.ReadString()
+				switch field {
+				{{ if .Type.IsClass }}
+					case "kind":
+						value := decoder.ReadString()
+						object.link = value == {{ $structName }}LinkKind
+					case "id":
+						value := decoder.ReadString()
+						object.id = &value
+					case "href":
+						value := decoder.ReadString()
+						object.href = &value
+				{{ end }}
+				{{ range .Type.Attributes }}
+					{{ generateReadAttribute (attributeFieldName .) (attributeFieldTag .) .Type .Link }}
+				{{ end }}
+				default:
+					decoder.Skip()
+				}
+			}
+			return object
+		}
+		`,
+		"Type", typ,
+	)
+}
+
+func (g *CBORSupportGenerator) generateListTypeSupport(typ *concepts.Type) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(typ.Owner())
+	fileName := g.typeFile(typ)
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("cbor").
+		Package(pkgName).
+		File(fileName).
+		Function("enumName", g.types.EnumName).
+		Function("generateReadValue", g.generateReadValue).
+		Function("generateWriteValue", g.generateWriteValue).
+		Function("marshalTypeFunc", g.marshalTypeFunc).
+		Function("readTypeFunc", g.readTypeFunc).
+		Function("structName", g.types.StructName).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Function("valueReference", g.types.ValueReference).
+		Function("writeTypeFunc", g.writeTypeFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateListTypeSource(typ)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *CBORSupportGenerator) generateListTypeSource(typ *concepts.Type) {
+	g.buffer.Import("io", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("list_type", `
+		{{ $sliceType := valueReference .Type }}
+		{{ $marshalTypeFunc := marshalTypeFunc .Type }}
+		{{ $writeTypeFunc := writeTypeFunc .Type }}
+		{{ $unmarshalTypeFunc := unmarshalTypeFunc .Type }}
+		{{ $readTypeFunc := readTypeFunc .Type }}
+
+		// {{ $marshalTypeFunc }} writes a list of values of the '{{ .Type.Element.Name }}' type to
+		// the given writer, encoded with CBOR.
+		func {{ $marshalTypeFunc }}(list {{ $sliceType }}, writer io.Writer) error {
+			encoder := helpers.NewCBOREncoder(writer)
+			{{ $writeTypeFunc }}(list, encoder)
+			return encoder.Flush()
+		}
+
+		// {{ $writeTypeFunc }} writes a list of values of the '{{ .Type.Element.Name }}' type to
+		// the given encoder.
+		func {{ $writeTypeFunc }}(list {{ $sliceType }}, encoder *helpers.CBOREncoder) {
+			encoder.WriteArrayStart()
+			for _, value := range list {
+				{{ generateWriteValue "value" .Type.Element false }}
+			}
+			encoder.WriteEnd()
+		}
+
+		// {{ $unmarshalTypeFunc }} reads a list of values of the '{{ .Type.Element.Name }}' type
+		// encoded with CBOR from the given source, which can be a slice of bytes, a string or a
+		// reader.
+		func {{ $unmarshalTypeFunc }}(source interface{}) (items {{ $sliceType }}, err error) {
+			decoder, err := helpers.NewCBORDecoder(source)
+			if err != nil {
+				return
+			}
+			items = {{ $readTypeFunc }}(decoder)
+			err = decoder.Error
+			return
+		}
+
+		// {{ $readTypeFunc }} reads a list of values of the '{{ .Type.Element.Name }}' type from
+		// the given decoder.
+		func {{ $readTypeFunc }}(decoder *helpers.CBORDecoder) {{ $sliceType }} {
+			list := {{ $sliceType }}{}
+			length := decoder.ReadArray()
+			for i := 0; decoder.More(length, i); i++ {
+				{{ generateReadValue "item" .Type.Element false }}
+				list = append(list, item)
+			}
+			return list
+		}
+		`,
+		"Type", typ,
+	)
+}
+
+func (g *CBORSupportGenerator) generateResourceSupport(resource *concepts.Resource) error {
+	var err error
+
+	// Only the methods that return a single object in the response body support CBOR, so
+	// there is nothing to generate if the resource doesn't have any of them. Errors and lists
+	// are always sent using JSON.
+	var methods []*concepts.Method
+	for _, method := range resource.Methods() {
+		if method.IsGet() || method.IsAdd() || method.IsUpdate() {
+			methods = append(methods, method)
+		}
+	}
+	if len(methods) == 0 {
+		return nil
+	}
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(resource.Owner())
+	fileName := g.resourceFile(resource)
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("cbor").
+		Package(pkgName).
+		File(fileName).
+		Function("clientResponseName", g.clientResponseName).
+		Function("clients", g.generateClients).
+		Function("marshalTypeFunc", g.marshalTypeFunc).
+		Function("readResponseFunc", g.readResponseFunc).
+		Function("serverResponseName", g.serverResponseName).
+		Function("servers", g.generateServers).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Function("writeResponseFunc", g.writeResponseFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	for _, method := range methods {
+		g.generateMethodSource(method)
+	}
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *CBORSupportGenerator) generateMethodSource(method *concepts.Method) {
+	body := method.GetParameter(nomenclator.Body)
+	g.buffer.Import("io", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.EmitTemplate("method", `
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				var err error
+				response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
+				return err
+			}
+		{{ end }}
+
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				return {{ marshalTypeFunc .Body.Type }}(response.body, w)
+			}
+		{{ end }}
+		`,
+		"Method", method,
+		"Body", body,
+	)
+}
+
+func (g *CBORSupportGenerator) generateReadAttribute(field, tag string, typ *concepts.Type,
+	link bool) string {
+	return g.buffer.Eval(`
+		case "{{ .Tag }}":
+			{{ generateReadValue "value" .Type .Link }}
+			{{ if .Type.IsScalar }}
+				object.{{ .Field }} = &value
+			{{ else }}
+				object.{{ .Field }} = value
+			{{ end }}
+		`,
+		"Field", field,
+		"Tag", tag,
+		"Type", typ,
+		"Link", link,
+	)
+}
+
+func (g *CBORSupportGenerator) generateReadValue(variable string, typ *concepts.Type,
+	link bool) string {
+	return g.buffer.Eval(`
+		{{ if .Type.IsBoolean }}
+			{{ .Variable }} := decoder.ReadBool()
+		{{ else if .Type.IsInteger }}
+			{{ .Variable }} := decoder.ReadInt()
+		{{ else if .Type.IsLong }}
+			{{ .Variable }} := decoder.ReadInt64()
+		{{ else if .Type.IsFloat }}
+			{{ .Variable }} := decoder.ReadFloat64()
+		{{ else if .Type.IsString }}
+			{{ .Variable }} := decoder.ReadString()
+		{{ else if .Type.IsDate }}
+			{{ .Variable }} := decoder.ReadDate()
+		{{ else if .Type.IsInterface }}
+			{{ .Variable }} := decoder.ReadValue()
+		{{ else if .Type.IsEnum }}
+			text := decoder.ReadString()
+			{{ .Variable }} := {{ enumName .Type }}(text)
+		{{ else if .Type.IsStruct }}
+			{{ .Variable }} := {{ readTypeFunc .Type }}(decoder)
+		{{ else if .Type.IsList }}
+			{{ if .Link }}
+				{{ $structName := structName .Type }}
+				{{ .Variable }} := &{{ $structName }}{}
+				length := decoder.ReadMap()
+				for i := 0; decoder.More(length, i); i++ {
+					field := decoder.ReadString()
+					switch field {
+					case "kind":
+						text := decoder.ReadString()
+						{{ .Variable }}.link = text == {{ $structName }}LinkKind
+					case "href":
+						text := decoder.ReadString()
+						{{ .Variable }}.href = &text
+					case "items":
+						{{ .Variable }}.items = {{ readTypeFunc .Type }}(decoder)
+					default:
+						decoder.Skip()
+					}
+				}
+			{{ else }}
+				{{ .Variable }} := {{ readTypeFunc .Type }}(decoder)
+			{{ end }}
+		{{ else if .Type.IsMap }}
+			{{ .Variable }} := {{ valueReference .Type }}{}
+			length := decoder.ReadMap()
+			for i := 0; decoder.More(length, i); i++ {
+				key := decoder.ReadString()
+				{{ generateReadValue "item" .Type.Element false }}
+				{{ .Variable }}[key] = item
+			}
+		{{ else }}
+			decoder.Skip()
+		{{ end }}
+		`,
+		"Variable", variable,
+		"Type", typ,
+		"Link", link,
+	)
+}
+
+func (g *CBORSupportGenerator) generateWriteAttribute(field, tag string, typ *concepts.Type,
+	link bool) string {
+	var value string
+	if typ.IsScalar() {
+		value = g.buffer.Eval(
+			`*object.{{ .Field }}`,
+			"Field", field,
+		)
+	} else {
+		value = g.buffer.Eval(
+			`object.{{ .Field }}`,
+			"Field", field,
+		)
+	}
+	return g.buffer.Eval(`
+		if object.{{ .Field }} != nil {
+			encoder.WriteString("{{ .Tag }}")
+			{{ generateWriteValue .Value .Type .Link }}
+		}
+		`,
+		"Field", field,
+		"Tag", tag,
+		"Value", value,
+		"Type", typ,
+		"Link", link,
+	)
+}
+
+// generateWriteAlwaysAttribute generates the code that writes a scalar attribute that is written
+// even if it hasn't been set, using the zero value of the type in that case.
+func (g *CBORSupportGenerator) generateWriteAlwaysAttribute(field, tag string,
+	typ *concepts.Type) string {
+	return g.buffer.Eval(`
+		encoder.WriteString("{{ .Tag }}")
+		if object.{{ .Field }} != nil {
+			{{ generateWriteValue .Value .Type false }}
+		} else {
+			{{ generateWriteValue .Zero .Type false }}
+		}
+		`,
+		"Field", field,
+		"Tag", tag,
+		"Value", "*object."+field,
+		"Zero", g.types.ZeroValue(typ),
+		"Type", typ,
+	)
+}
+
+func (g *CBORSupportGenerator) generateWriteValue(value string, typ *concepts.Type,
+	link bool) string {
+	g.buffer.Import("sort", "")
+	return g.buffer.Eval(`
+		{{ if .Type.IsBoolean }}
+			encoder.WriteBool({{ .Value }})
+		{{ else if .Type.IsInteger }}
+			encoder.WriteInt({{ .Value }})
+		{{ else if .Type.IsLong }}
+			encoder.WriteInt64({{ .Value }})
+		{{ else if .Type.IsFloat }}
+			encoder.WriteFloat64({{ .Value }})
+		{{ else if .Type.IsString }}
+			encoder.WriteString({{ .Value }})
+		{{ else if .Type.IsDate }}
+			encoder.WriteDate({{ .Value }})
+		{{ else if .Type.IsInterface }}
+			encoder.WriteValue({{ .Value }})
+		{{ else if .Type.IsEnum }}
+			encoder.WriteString(string({{ .Value }}))
+		{{ else if .Type.IsStruct }}
+			{{ writeTypeFunc .Type }}({{ .Value }}, encoder)
+		{{ else if .Type.IsList }}
+			{{ if .Link }}
+				encoder.WriteMapStart()
+				encoder.WriteString("items")
+				{{ writeTypeFunc .Type }}({{ .Value }}.items, encoder)
+				encoder.WriteEnd()
+			{{ else }}
+				{{ writeTypeFunc .Type }}({{ .Value }}, encoder)
+			{{ end }}
+		{{ else if .Type.IsMap }}
+			encoder.WriteMapStart()
+			keys := make([]string, 0, len({{ .Value }}))
+			for key := range {{ .Value }} {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				item := {{ .Value }}[key]
+				encoder.WriteString(key)
+				{{ generateWriteValue "item" .Type.Element false }}
+			}
+			encoder.WriteEnd()
+		{{ end }}
+		`,
+		"Value", value,
+		"Type", typ,
+		"Link", link,
+	)
+}
+
+func (g *CBORSupportGenerator) helpersFile() string {
+	return g.names.File(names.Cat(nomenclator.CBOR, nomenclator.Helpers))
+}
+
+func (g *CBORSupportGenerator) typeFile(typ *concepts.Type) string {
+	return g.names.File(names.Cat(typ.Name(), nomenclator.Type, nomenclator.CBOR))
+}
+
+func (g *CBORSupportGenerator) resourceFile(resource *concepts.Resource) string {
+	return g.names.File(names.Cat(resource.Name(), nomenclator.Resource, nomenclator.CBOR))
+}
+
+func (g *CBORSupportGenerator) marshalTypeFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Marshal, typ.Name(), nomenclator.CBOR)
+	return g.names.Public(name)
+}
+
+func (g *CBORSupportGenerator) writeTypeFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Write, typ.Name(), nomenclator.CBOR)
+	return g.names.Private(name)
+}
+
+func (g *CBORSupportGenerator) unmarshalTypeFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Unmarshal, typ.Name(), nomenclator.CBOR)
+	return g.names.Public(name)
+}
+
+func (g *CBORSupportGenerator) readTypeFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Read, typ.Name(), nomenclator.CBOR)
+	return g.names.Private(name)
+}
+
+func (g *CBORSupportGenerator) attributeFieldName(attribute *concepts.Attribute) string {
+	return g.names.Private(attribute.Name())
+}
+
+func (g *CBORSupportGenerator) generateClients() bool {
+	return g.clients
+}
+
+func (g *CBORSupportGenerator) generateServers() bool {
+	return g.servers
+}
+
+func (g *CBORSupportGenerator) clientResponseName(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(method.Name(), nomenclator.Response)
+	} else {
+		name = names.Cat(resource.Name(), method.Name(), nomenclator.Response)
+	}
+	return g.names.Public(name)
+}
+
+func (g *CBORSupportGenerator) serverResponseName(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(
+			method.Name(),
+			nomenclator.Server,
+			nomenclator.Response,
+		)
+	} else {
+		name = names.Cat(
+			resource.Name(),
+			method.Name(),
+			nomenclator.Server,
+			nomenclator.Response,
+		)
+	}
+	return g.names.Public(name)
+}
+
+func (g *CBORSupportGenerator) readResponseFunc(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(
+			nomenclator.Read,
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	} else {
+		name = names.Cat(
+			nomenclator.Read,
+			resource.Name(),
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	}
+	return g.names.Private(name)
+}
+
+func (g *CBORSupportGenerator) writeResponseFunc(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(
+			nomenclator.Write,
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	} else {
+		name = names.Cat(
+			nomenclator.Write,
+			resource.Name(),
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	}
+	return g.names.Private(name)
+}
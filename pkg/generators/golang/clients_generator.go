@@ -18,6 +18,7 @@ package golang
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
@@ -29,26 +30,38 @@ import (
 // ClientsGeneratorBuilder is an object used to configure and build a client generator. Don't create
 // instances directly, use the NewClientsGenerator function instead.
 type ClientsGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	binding  *http.BindingCalculator
+	reporter           *reporter.Reporter
+	model              *concepts.Model
+	output             string
+	packages           *PackagesCalculator
+	names              *NamesCalculator
+	types              *TypesCalculator
+	binding            *http.BindingCalculator
+	deprecateNoContext bool
+	cbor               bool
+	header             *Header
+	stream             bool
+	maxLines           int
+	templates          *Templates
 }
 
 // ClientsGenerator generates client code. Don't create instances directly, use the builder instead.
 type ClientsGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	binding  *http.BindingCalculator
-	buffer   *Buffer
+	reporter           *reporter.Reporter
+	errors             int
+	model              *concepts.Model
+	output             string
+	packages           *PackagesCalculator
+	names              *NamesCalculator
+	types              *TypesCalculator
+	binding            *http.BindingCalculator
+	deprecateNoContext bool
+	cbor               bool
+	header             *Header
+	stream             bool
+	maxLines           int
+	templates          *Templates
+	buffer             *Buffer
 }
 
 // NewClientsGenerator creates a new builder for client generators.
@@ -100,6 +113,52 @@ func (b *ClientsGeneratorBuilder) Binding(value *http.BindingCalculator) *Client
 	return b
 }
 
+// DeprecateNoContext sets the flag that indicates if the methods that send requests without a
+// context, like the Send method, should be marked as deprecated in favour of the methods that
+// require a context, like the SendContext method. The default is false.
+func (b *ClientsGeneratorBuilder) DeprecateNoContext(value bool) *ClientsGeneratorBuilder {
+	b.deprecateNoContext = value
+	return b
+}
+
+// CBOR sets the flag that indicates if the generated clients should decode response bodies
+// encoded with CBOR, when the server sends them because the request contains the 'Accept:
+// application/cbor' header. This requires the code generated by the CBOR support generator. The
+// default is false.
+func (b *ClientsGeneratorBuilder) CBOR(value bool) *ClientsGeneratorBuilder {
+	b.cbor = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *ClientsGeneratorBuilder) Header(value *Header) *ClientsGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *ClientsGeneratorBuilder) Stream(value bool) *ClientsGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *ClientsGeneratorBuilder) MaxLines(value int) *ClientsGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *ClientsGeneratorBuilder) Templates(value *Templates) *ClientsGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new client
 // generator using it.
 func (b *ClientsGeneratorBuilder) Build() (generator *ClientsGenerator, err error) {
@@ -135,13 +194,19 @@ func (b *ClientsGeneratorBuilder) Build() (generator *ClientsGenerator, err erro
 
 	// Create the generator:
 	generator = &ClientsGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
-		types:    b.types,
-		binding:  b.binding,
+		reporter:           b.reporter,
+		model:              b.model,
+		output:             b.output,
+		packages:           b.packages,
+		names:              b.names,
+		types:              b.types,
+		binding:            b.binding,
+		deprecateNoContext: b.deprecateNoContext,
+		cbor:               b.cbor,
+		header:             b.header,
+		stream:             b.stream,
+		maxLines:           b.maxLines,
+		templates:          b.templates,
 	}
 
 	return
@@ -185,6 +250,11 @@ func (g *ClientsGenerator) generateServiceClient(service *concepts.Service) erro
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("clients").
 		Package(pkgName).
 		File(fileName).
 		Function("clientName", g.clientName).
@@ -195,6 +265,7 @@ func (g *ClientsGenerator) generateServiceClient(service *concepts.Service) erro
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the source for the service:
 	err = g.generateServiceClientSource(service)
@@ -218,13 +289,21 @@ func (g *ClientsGenerator) generateServiceClient(service *concepts.Service) erro
 }
 
 func (g *ClientsGenerator) generateServiceClientSource(service *concepts.Service) error {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("context", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("io/ioutil", "")
 	g.buffer.Import("net/http", "")
+	g.buffer.Import("net/url", "")
 	g.buffer.Import("path", "")
+	g.buffer.Import(g.packages.ErrorsImport(), "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
 	for _, version := range service.Versions() {
 		g.buffer.Import(g.packages.VersionImport(version), "")
 	}
-	g.buffer.Emit(`
-		// Client is the client for service '{{ .Service.Name }}'.
+	g.buffer.EmitTemplate("service_client", `
+		// Client is the client for service '{{ .Service.Name }}'. It doesn't have mutable
+		// state, so it is safe for concurrent use by multiple goroutines.
 		type Client struct {
 			transport http.RoundTripper
 			path string
@@ -256,8 +335,332 @@ func (g *ClientsGenerator) generateServiceClientSource(service *concepts.Service
 				)
 			}
 		{{ end }}
+
+		// KnownVersions returns the identifiers of the versions of the service that this
+		// client knows, from the oldest to the newest.
+		func KnownVersions() []string {
+			return []string{
+				{{ range .Service.Versions }}
+					"{{ versionSegment . }}",
+				{{ end }}
+			}
+		}
+
+		// Versions creates a request to retrieve the list of versions of the service that
+		// are supported by the server.
+		func (c *Client) Versions() *VersionsRequest {
+			return &VersionsRequest{
+				transport: c.transport,
+				path:      c.path,
+				metric:    c.metric,
+			}
+		}
+
+		// VersionsRequest is the request to retrieve the list of versions of the service
+		// supported by the server.
+		type VersionsRequest struct {
+			transport   http.RoundTripper
+			path        string
+			metric      string
+			query       url.Values
+			header      http.Header
+			keepRawBody bool
+		}
+
+		// Parameter adds a query parameter.
+		func (r *VersionsRequest) Parameter(name string, value interface{}) *VersionsRequest {
+			helpers.AddValue(&r.query, name, value)
+			return r
+		}
+
+		// Header adds a request header.
+		func (r *VersionsRequest) Header(name string, value interface{}) *VersionsRequest {
+			helpers.AddHeader(&r.header, name, value)
+			return r
+		}
+
+		// Impersonate asks the server to process the request on behalf of the given user and
+		// groups.
+		func (r *VersionsRequest) Impersonate(user string, groups ...string) *VersionsRequest {
+			helpers.Impersonate(&r.header, user, groups)
+			return r
+		}
+
+		// KeepRawBody asks the client to keep in memory the bytes of the body of successful
+		// responses, so that they are available with the RawBody and RawResponse methods of
+		// the response. The default is to keep only the bodies of error responses, as the
+		// bodies of successful responses, for example large lists, are decoded while they
+		// are received.
+		func (r *VersionsRequest) KeepRawBody(value bool) *VersionsRequest {
+			r.keepRawBody = value
+			return r
+		}
+
+		// Clone creates a copy of this request that can be modified and sent independently
+		// of the original.
+		func (r *VersionsRequest) Clone() *VersionsRequest {
+			clone := *r
+			clone.query = helpers.CopyQuery(r.query)
+			clone.header = helpers.CopyHeader(r.header)
+			return &clone
+		}
+
+		// Send sends the versions request, waits for the response, and returns it.
+		//
+		// This is a potentially lengthy operation, as it requires network communication.
+		// Consider using a context and the SendContext method.
+		{{ if .DeprecateNoContext }}
+		//
+		// Deprecated: Use the SendContext method instead.
+		{{ end }}
+		func (r *VersionsRequest) Send() (result *VersionsResponse, err error) {
+			return r.SendContext(context.Background())
+		}
+
+		// SendContext sends the versions request, waits for the response, and returns it.
+		func (r *VersionsRequest) SendContext(ctx context.Context) (result *VersionsResponse, err error) {
+			request := r.httpRequest(ctx)
+			response, err := r.transport.RoundTrip(request)
+			if err != nil {
+				return
+			}
+			defer response.Body.Close()
+			raw := *response
+			raw.Body = http.NoBody
+			result = &VersionsResponse{
+				status: response.StatusCode,
+				header: response.Header,
+				raw:    &raw,
+			}
+			if result.status >= 400 {
+				result.rawBody, err = helpers.ReadErrorBody(response.Body)
+				if err != nil {
+					return
+				}
+				raw.Body = ioutil.NopCloser(bytes.NewReader(result.rawBody))
+				result.err, err = errors.UnmarshalError(bytes.NewReader(result.rawBody))
+				if err != nil {
+					return
+				}
+				err = result.err
+				return
+			}
+			var body io.Reader = response.Body
+			if r.keepRawBody {
+				result.rawBody, err = ioutil.ReadAll(response.Body)
+				if err != nil {
+					return
+				}
+				raw.Body = ioutil.NopCloser(bytes.NewReader(result.rawBody))
+				body = bytes.NewReader(result.rawBody)
+			}
+			result.items, result.links, err = readVersions(body)
+			return
+		}
+
+		// AsCurl returns a curl command that sends a request equivalent to this one. The
+		// address of the server and the access token aren't known by the request, so the
+		// command uses the 'URL' and 'TOKEN' environment variables instead.
+		func (r *VersionsRequest) AsCurl() (command string, err error) {
+			command = helpers.Curl(r.httpRequest(context.Background()), nil)
+			return
+		}
+
+		// httpRequest creates the HTTP request that is sent by the SendContext method.
+		func (r *VersionsRequest) httpRequest(ctx context.Context) *http.Request {
+			query := helpers.CopyQuery(r.query)
+			header := helpers.SetHeader(r.header, r.metric)
+			operationID := helpers.OperationIDFromContext(ctx)
+			if operationID != "" && header.Get(helpers.OperationIDHeader) == "" {
+				header.Set(helpers.OperationIDHeader, operationID)
+			}
+			uri := &url.URL{
+				Path:     r.path,
+				RawQuery: query.Encode(),
+			}
+			request := &http.Request{
+				Method: http.MethodGet,
+				URL:    uri,
+				Header: header,
+			}
+			if ctx != nil {
+				request = request.WithContext(ctx)
+			}
+			return request
+		}
+
+		// VersionsResponse is the response for the versions request.
+		type VersionsResponse struct {
+			status  int
+			header  http.Header
+			raw     *http.Response
+			rawBody []byte
+			err     *errors.Error
+			items   []string
+			links   map[string]string
+		}
+
+		// readVersions extracts the identifiers of the versions and their links from the
+		// body of the response, which can be a reader, a slice of bytes or a string.
+		func readVersions(source interface{}) (versions []string, links map[string]string, err error) {
+			iterator, err := helpers.NewIterator(source)
+			if err != nil {
+				return
+			}
+			for {
+				field := iterator.ReadObject()
+				if field == "" {
+					break
+				}
+				if field != "items" {
+					iterator.Skip()
+					continue
+				}
+				versions = []string{}
+				links = map[string]string{}
+				for iterator.ReadArray() {
+					var id, href string
+					for {
+						field := iterator.ReadObject()
+						if field == "" {
+							break
+						}
+						switch field {
+						case "id":
+							id = iterator.ReadString()
+						case "href":
+							href = iterator.ReadString()
+						default:
+							iterator.Skip()
+						}
+					}
+					versions = append(versions, id)
+					if href != "" {
+						links[id] = href
+					}
+				}
+			}
+			err = iterator.Error
+			return
+		}
+
+		// Status returns the response status code.
+		func (r *VersionsResponse) Status() int {
+			if r == nil {
+				return 0
+			}
+			return r.status
+		}
+
+		// Header returns header of the response.
+		func (r *VersionsResponse) Header() http.Header {
+			if r == nil {
+				return nil
+			}
+			return r.header
+		}
+
+		// RawResponse returns the HTTP response received from the server, for the cases where
+		// the typed methods aren't enough. The bodies of successful responses are decoded
+		// while they are received, so they are only available here if the request was sent
+		// with the KeepRawBody option. The bodies of error responses are always available.
+		// In both cases the body contains the bytes received from the server and can be read
+		// only once.
+		func (r *VersionsResponse) RawResponse() *http.Response {
+			if r == nil {
+				return nil
+			}
+			return r.raw
+		}
+
+		// RawBody returns the bytes of the body of the response, as received from the server.
+		// It is always available for error responses, but for successful responses it is
+		// only available if the request was sent with the KeepRawBody option, otherwise it
+		// returns nil.
+		func (r *VersionsResponse) RawBody() []byte {
+			if r == nil {
+				return nil
+			}
+			return r.rawBody
+		}
+
+		// RateLimit returns the rate limit information sent by the server, or nil if the server
+		// didn't send it.
+		func (r *VersionsResponse) RateLimit() *helpers.RateLimit {
+			if r == nil {
+				return nil
+			}
+			return helpers.ParseRateLimit(r.header)
+		}
+
+		// Error returns the response error.
+		func (r *VersionsResponse) Error() *errors.Error {
+			if r == nil {
+				return nil
+			}
+			return r.err
+		}
+
+		// OperationID returns the identifier of the operation assigned by the server, so
+		// that it can be used to find the details of the request in the logs of the server.
+		func (r *VersionsResponse) OperationID() string {
+			if r == nil {
+				return ""
+			}
+			return r.header.Get(helpers.OperationIDHeader)
+		}
+
+		// Items returns the identifiers of the versions supported by the server.
+		func (r *VersionsResponse) Items() []string {
+			if r == nil {
+				return nil
+			}
+			return r.items
+		}
+
+		// Link returns the path of the given version, as returned by the server. It returns
+		// an empty string if the server doesn't support the version or doesn't return the
+		// link.
+		func (r *VersionsResponse) Link(version string) string {
+			if r == nil {
+				return ""
+			}
+			return r.links[version]
+		}
+
+		// Supports returns true if the server supports the given version.
+		func (r *VersionsResponse) Supports(version string) bool {
+			for _, item := range r.Items() {
+				if item == version {
+					return true
+				}
+			}
+			return false
+		}
+
+		// Select returns the first of the given versions that is supported by the server.
+		// If no version is given it returns the newest of the versions known by this client
+		// that is supported by the server. The flag will be false if there is no such
+		// version.
+		func (r *VersionsResponse) Select(preferred ...string) (version string, ok bool) {
+			if len(preferred) == 0 {
+				known := KnownVersions()
+				for i := len(known) - 1; i >= 0; i-- {
+					preferred = append(preferred, known[i])
+				}
+			}
+			for _, candidate := range preferred {
+				if r.Supports(candidate) {
+					version = candidate
+					ok = true
+					return
+				}
+			}
+			return
+		}
 		`,
 		"Service", service,
+		"DeprecateNoContext", g.deprecateNoContext,
 	)
 
 	return nil
@@ -278,9 +681,289 @@ func (g *ClientsGenerator) generateVersionClient(version *concepts.Version) erro
 		}
 	}
 
+	// Generate the link expansion helpers:
+	err = g.generateVersionExpandFile(version)
+	if err != nil {
+		return err
+	}
+	err = g.generateVersionFetchFile(version)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+func (g *ClientsGenerator) generateVersionExpandFile(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.expandFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("clients").
+		Package(pkgName).
+		File(fileName).
+		Function("listName", g.listName).
+		Function("objectName", g.objectName).
+		Function("readListFunc", g.readListFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateVersionExpandSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *ClientsGenerator) generateVersionFetchFile(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.fetchFile()
+
+	// Create the buffer for the generated code. Note that this uses generics, so it needs
+	// to be excluded when building with versions of Go older than 1.18.
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("clients").
+		Package(pkgName).
+		File(fileName).
+		Constraint("go1.18").
+		Function("objectName", g.objectName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateVersionFetchSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *ClientsGenerator) generateVersionFetchSource(version *concepts.Version) {
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.EmitTemplate("version_fetch", `
+		// Fetchable is the set of types of objects that can be retrieved using the Fetch
+		// function.
+		type Fetchable interface {
+			{{ range $index, $type := .Types }}{{ if gt $index 0 }} | {{ end }}*{{ objectName $type }}{{ end }}
+		}
+
+		// Fetch retrieves the object that the given link points to, using the given
+		// transport to send the request, and unmarshals it using the reader that
+		// corresponds to the requested type. For example, to retrieve a cluster:
+		//
+		//	cluster, err := Fetch[*Cluster](ctx, transport, href)
+		func Fetch[T Fetchable](ctx context.Context, transport http.RoundTripper,
+			href string) (result T, err error) {
+			if href == "" {
+				err = fmt.Errorf("link is mandatory")
+				return
+			}
+			{{ if .Types }}
+				data, err := fetchLink(ctx, transport, href, 0)
+				if err != nil {
+					return
+				}
+				switch target := any(&result).(type) {
+				{{ range .Types }}
+					{{ $objectName := objectName . }}
+					case **{{ $objectName }}:
+						*target, err = Unmarshal{{ $objectName }}(data)
+				{{ end }}
+				default:
+					err = fmt.Errorf("type %T can't be fetched", result)
+				}
+			{{ else }}
+				err = fmt.Errorf("type %T can't be fetched", result)
+			{{ end }}
+			return
+		}
+		`,
+		"Types", g.classTypes(version),
+	)
+}
+
+func (g *ClientsGenerator) generateVersionExpandSource(version *concepts.Version) {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("net/url", "")
+	g.buffer.Import("strconv", "")
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
+	g.buffer.Import(g.packages.ErrorsImport(), "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("version_expand", `
+		// fetchLink sends a request to retrieve the object or the page of the list that the
+		// given link points to, and returns the body of the response. If the page number is
+		// zero the page parameter isn't added to the request.
+		func fetchLink(ctx context.Context, transport http.RoundTripper, href string,
+			page int) (data []byte, err error) {
+			query := url.Values{}
+			if page > 0 {
+				query.Set("page", strconv.Itoa(page))
+			}
+			header := helpers.SetHeader(nil, "")
+			operationID := helpers.OperationIDFromContext(ctx)
+			if operationID != "" {
+				header.Set(helpers.OperationIDHeader, operationID)
+			}
+			uri := &url.URL{
+				Path:     href,
+				RawQuery: query.Encode(),
+			}
+			request := &http.Request{
+				Method: http.MethodGet,
+				URL:    uri,
+				Header: header,
+			}
+			if ctx != nil {
+				request = request.WithContext(ctx)
+			}
+			response, err := transport.RoundTrip(request)
+			if err != nil {
+				return
+			}
+			defer response.Body.Close()
+			data, err = ioutil.ReadAll(response.Body)
+			if err != nil {
+				return
+			}
+			if response.StatusCode >= 400 {
+				var failure *errors.Error
+				failure, err = errors.UnmarshalError(bytes.NewReader(data))
+				if err != nil {
+					return
+				}
+				err = failure
+			}
+			return
+		}
+
+		{{ range .Version.Types }}
+			{{ if .IsClass }}
+				{{ $objectName := objectName . }}
+				{{ $listName := listName . }}
+
+				// Expand retrieves the complete representation of the object if it is
+				// a link, using the given transport to send the request. If the object
+				// isn't a link it is returned unchanged.
+				func (o *{{ $objectName }}) Expand(ctx context.Context,
+					transport http.RoundTripper) (result *{{ $objectName }}, err error) {
+					if !o.Link() {
+						result = o
+						return
+					}
+					href, ok := o.GetHREF()
+					if !ok {
+						err = fmt.Errorf(
+							"link to object of type '{{ .Name }}' doesn't contain " +
+							"the 'href' attribute",
+						)
+						return
+					}
+					data, err := fetchLink(ctx, transport, href, 0)
+					if err != nil {
+						return
+					}
+					result, err = Unmarshal{{ $objectName }}(data)
+					return
+				}
+
+				// Expand retrieves all the items of the list if it is a link, using the
+				// given transport to send the requests, one per page. If the list isn't
+				// a link it is returned unchanged.
+				func (l *{{ $listName }}) Expand(ctx context.Context,
+					transport http.RoundTripper) (result *{{ $listName }}, err error) {
+					if !l.Link() {
+						result = l
+						return
+					}
+					href, ok := l.GetHREF()
+					if !ok {
+						err = fmt.Errorf(
+							"link to list of type '{{ .Name }}' doesn't contain " +
+							"the 'href' attribute",
+						)
+						return
+					}
+					items := []*{{ $objectName }}{}
+					for page := 1; ; page++ {
+						var data []byte
+						data, err = fetchLink(ctx, transport, href, page)
+						if err != nil {
+							return
+						}
+						var iterator *jsoniter.Iterator
+						iterator, err = helpers.NewIterator(data)
+						if err != nil {
+							return
+						}
+						total := -1
+						var chunk []*{{ $objectName }}
+						for {
+							field := iterator.ReadObject()
+							if field == "" {
+								break
+							}
+							switch field {
+							case "total":
+								total = iterator.ReadInt()
+							case "items":
+								chunk = {{ readListFunc . }}(iterator)
+							default:
+								iterator.ReadAny()
+							}
+						}
+						err = iterator.Error
+						if err != nil {
+							return
+						}
+						items = append(items, chunk...)
+						if len(chunk) == 0 || total < 0 || len(items) >= total {
+							break
+						}
+					}
+					result = &{{ $listName }}{
+						items: items,
+					}
+					return
+				}
+			{{ end }}
+		{{ end }}
+		`,
+		"Version", version,
+	)
+}
+
 func (g *ClientsGenerator) generateVersionMetadataClient(version *concepts.Version) error {
 	var err error
 
@@ -293,12 +976,18 @@ func (g *ClientsGenerator) generateVersionMetadataClient(version *concepts.Versi
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("clients").
 		Package(pkgName).
 		File(fileName).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateVersionMetadataClientSource(version)
@@ -308,27 +997,33 @@ func (g *ClientsGenerator) generateVersionMetadataClient(version *concepts.Versi
 }
 
 func (g *ClientsGenerator) generateVersionMetadataClientSource(version *concepts.Version) {
+	g.buffer.Import("bytes", "")
 	g.buffer.Import("context", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("io/ioutil", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import("net/url", "")
 	g.buffer.Import(g.packages.ErrorsImport(), "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("version_metadata_client", `
 		// MetadataRequest is the request to retrieve the metadata.
 		type MetadataRequest struct {
-			transport http.RoundTripper
-			path      string
-			metric    string
-			query     url.Values
-			header    http.Header
+			transport   http.RoundTripper
+			path        string
+			metric      string
+			query       url.Values
+			header      http.Header
+			keepRawBody bool
 		}
 
 		// MetadataResponse is the response for the metadata request.
 		type MetadataResponse struct {
-			status int
-			header http.Header
-			err    *errors.Error
-			body   *Metadata
+			status  int
+			header  http.Header
+			raw     *http.Response
+			rawBody []byte
+			err     *errors.Error
+			body    *Metadata
 		}
 
 		// Parameter adds a query parameter.
@@ -343,68 +1038,166 @@ func (g *ClientsGenerator) generateVersionMetadataClientSource(version *concepts
 			return r
 		}
 
+		// Impersonate asks the server to process the request on behalf of the given user and
+		// groups.
+		func (r *MetadataRequest) Impersonate(user string, groups ...string) *MetadataRequest {
+			helpers.Impersonate(&r.header, user, groups)
+			return r
+		}
+
+		// KeepRawBody asks the client to keep in memory the bytes of the body of successful
+		// responses, so that they are available with the RawBody and RawResponse methods of
+		// the response. The default is to keep only the bodies of error responses, as the
+		// bodies of successful responses, for example large lists, are decoded while they
+		// are received.
+		func (r *MetadataRequest) KeepRawBody(value bool) *MetadataRequest {
+			r.keepRawBody = value
+			return r
+		}
+
+		// Clone creates a copy of this request that can be modified and sent independently
+		// of the original.
+		func (r *MetadataRequest) Clone() *MetadataRequest {
+			clone := *r
+			clone.query = helpers.CopyQuery(r.query)
+			clone.header = helpers.CopyHeader(r.header)
+			return &clone
+		}
+
 		// Send sends the metadata request, waits for the response, and returns it.
 		//
 		// This is a potentially lengthy operation, as it requires network communication.
 		// Consider using a context and the SendContext method.
+		{{ if .DeprecateNoContext }}
+		//
+		// Deprecated: Use the SendContext method instead.
+		{{ end }}
 		func (r *MetadataRequest) Send() (result *MetadataResponse, err error) {
 			return r.SendContext(context.Background())
 		}
 
 		// SendContext sends the metadata request, waits for the response, and returns it.
 		func (r *MetadataRequest) SendContext(ctx context.Context) (result *MetadataResponse, err error) {
-			query := helpers.CopyQuery(r.query)
-			header := helpers.SetHeader(r.header, r.metric)
-			uri := &url.URL{
-				Path: r.path,
-				RawQuery: query.Encode(),
-			}
-			request := &http.Request{
-				Method: http.MethodGet,
-				URL:    uri,
-				Header: header,
-			}
-			if ctx != nil {
-				request = request.WithContext(ctx)
-			}
+			request := r.httpRequest(ctx)
 			response, err := r.transport.RoundTrip(request)
 			if err != nil {
 				return
 			}
 			defer response.Body.Close()
+			raw := *response
+			raw.Body = http.NoBody
 			result = &MetadataResponse{
 				status: response.StatusCode,
 				header: response.Header,
+				raw:    &raw,
 			}
 			if result.status >= 400 {
-				result.err, err = errors.UnmarshalError(response.Body)
+				result.rawBody, err = helpers.ReadErrorBody(response.Body)
+				if err != nil {
+					return
+				}
+				raw.Body = ioutil.NopCloser(bytes.NewReader(result.rawBody))
+				result.err, err = errors.UnmarshalError(bytes.NewReader(result.rawBody))
 				if err != nil {
 					return
 				}
 				err = result.err
 				return
 			}
-			result.body, err = UnmarshalMetadata(response.Body)
+			var body io.Reader = response.Body
+			if r.keepRawBody {
+				result.rawBody, err = ioutil.ReadAll(response.Body)
+				if err != nil {
+					return
+				}
+				raw.Body = ioutil.NopCloser(bytes.NewReader(result.rawBody))
+				body = bytes.NewReader(result.rawBody)
+			}
+			result.body, err = UnmarshalMetadata(body)
 			if err != nil {
 				return
 			}
-			return
+			return
+		}
+
+		// AsCurl returns a curl command that sends a request equivalent to this one. The
+		// address of the server and the access token aren't known by the request, so the
+		// command uses the 'URL' and 'TOKEN' environment variables instead.
+		func (r *MetadataRequest) AsCurl() (command string, err error) {
+			command = helpers.Curl(r.httpRequest(context.Background()), nil)
+			return
+		}
+
+		// httpRequest creates the HTTP request that is sent by the SendContext method.
+		func (r *MetadataRequest) httpRequest(ctx context.Context) *http.Request {
+			query := helpers.CopyQuery(r.query)
+			header := helpers.SetHeader(r.header, r.metric)
+			operationID := helpers.OperationIDFromContext(ctx)
+			if operationID != "" && header.Get(helpers.OperationIDHeader) == "" {
+				header.Set(helpers.OperationIDHeader, operationID)
+			}
+			uri := &url.URL{
+				Path:     r.path,
+				RawQuery: query.Encode(),
+			}
+			request := &http.Request{
+				Method: http.MethodGet,
+				URL:    uri,
+				Header: header,
+			}
+			if ctx != nil {
+				request = request.WithContext(ctx)
+			}
+			return request
+		}
+
+		// Status returns the response status code.
+		func (r *MetadataResponse) Status() int {
+			if r == nil {
+				return 0
+			}
+			return r.status
+		}
+
+		// Header returns header of the response.
+		func (r *MetadataResponse) Header() http.Header {
+			if r == nil {
+				return nil
+			}
+			return r.header
+		}
+
+		// RawResponse returns the HTTP response received from the server, for the cases where
+		// the typed methods aren't enough. The bodies of successful responses are decoded
+		// while they are received, so they are only available here if the request was sent
+		// with the KeepRawBody option. The bodies of error responses are always available.
+		// In both cases the body contains the bytes received from the server and can be read
+		// only once.
+		func (r *MetadataResponse) RawResponse() *http.Response {
+			if r == nil {
+				return nil
+			}
+			return r.raw
 		}
 
-		// Status returns the response status code.
-		func (r *MetadataResponse) Status() int {
+		// RawBody returns the bytes of the body of the response, as received from the server.
+		// It is always available for error responses, but for successful responses it is
+		// only available if the request was sent with the KeepRawBody option, otherwise it
+		// returns nil.
+		func (r *MetadataResponse) RawBody() []byte {
 			if r == nil {
-				return 0
+				return nil
 			}
-			return r.status
+			return r.rawBody
 		}
 
-		// Header returns header of the response.
-		func (r *MetadataResponse) Header() http.Header {
+		// RateLimit returns the rate limit information sent by the server, or nil if the server
+		// didn't send it.
+		func (r *MetadataResponse) RateLimit() *helpers.RateLimit {
 			if r == nil {
 				return nil
 			}
-			return r.header
+			return helpers.ParseRateLimit(r.header)
 		}
 
 		// Error returns the response error.
@@ -415,11 +1208,21 @@ func (g *ClientsGenerator) generateVersionMetadataClientSource(version *concepts
 			return r.err
 		}
 
+		// OperationID returns the identifier of the operation assigned by the server, so
+		// that it can be used to find the details of the request in the logs of the server.
+		func (r *MetadataResponse) OperationID() string {
+			if r == nil {
+				return ""
+			}
+			return r.header.Get(helpers.OperationIDHeader)
+		}
+
 		// Body returns the response body.
 		func (r *MetadataResponse) Body() *Metadata {
 			return r.body
 		}
 		`,
+		"DeprecateNoContext", g.deprecateNoContext,
 	)
 }
 
@@ -435,10 +1238,16 @@ func (g *ClientsGenerator) generateResourceClient(resource *concepts.Resource) e
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("clients").
 		Package(pkgName).
 		File(fileName).
 		Function("clientName", g.clientName).
 		Function("enumName", g.enumName).
+		Function("exampleComment", g.exampleComment).
 		Function("fieldName", g.fieldName).
 		Function("fieldType", g.fieldType).
 		Function("getterName", g.getterName).
@@ -449,10 +1258,13 @@ func (g *ClientsGenerator) generateResourceClient(resource *concepts.Resource) e
 		Function("methodName", g.methodName).
 		Function("methodSegment", g.binding.MethodSegment).
 		Function("parameterName", g.binding.ParameterName).
+		Function("plural", g.names.Plural).
 		Function("pollRequestName", g.pollRequestName).
 		Function("pollResponseName", g.pollResponseName).
+		Function("readCBORResponseFunc", g.readCBORResponseFunc).
 		Function("readResponseFunc", g.readResponseFunc).
 		Function("requestBodyParameters", g.binding.RequestBodyParameters).
+		Function("requestBodySensitivePaths", g.binding.RequestBodySensitivePaths).
 		Function("requestName", g.requestName).
 		Function("requestParameters", g.binding.RequestParameters).
 		Function("requestQueryParameters", g.binding.RequestQueryParameters).
@@ -468,6 +1280,7 @@ func (g *ClientsGenerator) generateResourceClient(resource *concepts.Resource) e
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateResourceClientSource(resource)
@@ -480,15 +1293,42 @@ func (g *ClientsGenerator) generateResourceClientSource(resource *concepts.Resou
 	// We need to know if this is the root resource in order to add the metadata methods:
 	root := resource == resource.Owner().Root()
 
+	// Collections that have a variable locator pointing to a resource with a `Get` method
+	// also get a method to retrieve multiple objects at once:
+	var manyLocator *concepts.Locator
+	var manyBody *concepts.Parameter
+	for _, locator := range resource.Locators() {
+		if !locator.Variable() {
+			continue
+		}
+		get := locator.Target().FindMethod(nomenclator.Get)
+		if get == nil {
+			continue
+		}
+		bodies := g.binding.ResponseBodyParameters(get)
+		if len(bodies) == 0 {
+			continue
+		}
+		manyLocator = locator
+		manyBody = bodies[0]
+		break
+	}
+
 	// Generate the source:
+	g.buffer.Import("context", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import("path", "")
-	g.buffer.Emit(`
+	g.buffer.Import("sync", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("resource_client", `
 		{{ $clientName := clientName .Resource }}
 
 		// {{ $clientName }} is the client of the '{{ .Resource.Name }}' resource.
 		//
 		{{ lineComment .Resource.Doc }}
+		//
+		// Clients don't have mutable state, so they are safe for concurrent use by multiple
+		// goroutines.
 		type {{ $clientName }} struct {
 			transport http.RoundTripper
 			path string
@@ -514,6 +1354,10 @@ func (g *ClientsGenerator) generateResourceClientSource(resource *concepts.Resou
 			// {{ $methodName }} creates a request for the '{{ .Name }}' method.
 			//
 			{{ lineComment .Doc }}
+			{{ with .Since }}
+				//
+				// Available since version {{ . }} of the API.
+			{{ end }}
 			func (c *{{ $clientName }}) {{ $methodName }}() *{{ $requestName }} {
 				return &{{ $requestName }}{
 					transport: c.transport,
@@ -568,9 +1412,49 @@ func (g *ClientsGenerator) generateResourceClientSource(resource *concepts.Resou
 				}
 			{{ end }}
 		{{ end }}
+
+		{{ if .ManyLocator }}
+			{{ $locatorName := locatorName .ManyLocator }}
+			{{ $bodyType := getterType .ManyBody }}
+
+			// GetMany retrieves the {{ plural .ManyLocator.Name }} with the given
+			// identifiers. The requests are sent concurrently, but never more than
+			// helpers.MaxConcurrentRequests at the same time. The objects retrieved are
+			// returned in the items map, and the errors in the errs map, both indexed by
+			// identifier. Each identifier will be in one of the maps, but never in both.
+			func (c *{{ $clientName }}) GetMany(ctx context.Context, ids ...string) (items map[string]{{ $bodyType }}, errs map[string]error) {
+				return c.GetManyConcurrently(ctx, helpers.MaxConcurrentRequests, ids...)
+			}
+
+			// GetManyConcurrently is like GetMany, but sends at most the given number of
+			// requests at the same time. If the context is cancelled or its deadline expires
+			// no more requests are sent, and the identifiers that weren't requested are
+			// returned in the errs map with the error of the context.
+			func (c *{{ $clientName }}) GetManyConcurrently(ctx context.Context, concurrency int, ids ...string) (items map[string]{{ $bodyType }}, errs map[string]error) {
+				items = map[string]{{ $bodyType }}{}
+				errs = map[string]error{}
+				lock := &sync.Mutex{}
+				skipped := helpers.FanOut(ctx, ids, concurrency, func(ctx context.Context, id string) {
+					response, err := c.{{ $locatorName }}(id).Get().SendContext(ctx)
+					lock.Lock()
+					defer lock.Unlock()
+					if err != nil {
+						errs[id] = err
+						return
+					}
+					items[id] = response.{{ getterName .ManyBody }}()
+				})
+				for _, id := range skipped {
+					errs[id] = ctx.Err()
+				}
+				return
+			}
+		{{ end }}
 		`,
 		"Resource", resource,
 		"Root", root,
+		"ManyLocator", manyLocator,
+		"ManyBody", manyBody,
 	)
 
 	// If the resource has a `Get` method then generate the `Poll` method:
@@ -591,11 +1475,13 @@ func (g *ClientsGenerator) generatePollMethodSource(resource *concepts.Resource,
 	body := g.binding.ResponseBodyParameters(method)[0]
 
 	// Generate the code:
+	g.buffer.Import("context", "")
 	g.buffer.Import("fmt", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import("time", "")
 	g.buffer.Import(g.packages.ErrorsImport(), "")
-	g.buffer.Emit(`
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("poll_method", `
 		{{ $clientName := clientName .Resource }}
 		{{ $requestName := pollRequestName .Resource }}
 		{{ $responseName := pollResponseName .Resource }}
@@ -624,6 +1510,21 @@ func (g *ClientsGenerator) generatePollMethodSource(resource *concepts.Resource,
 			return r
 		}
 
+		// Impersonate asks the server to process all the requests that will be used to retrieve
+		// the object on behalf of the given user and groups.
+		func (r *{{ $requestName }}) Impersonate(user string, groups ...string) *{{ $requestName }} {
+			r.request.Impersonate(user, groups...)
+			return r
+		}
+
+		// KeepRawBody asks the client to keep in memory the bytes of the bodies of the
+		// successful responses, so that the body of the last one is available with the RawBody
+		// method of the response.
+		func (r *{{ $requestName }}) KeepRawBody(value bool) *{{ $requestName }} {
+			r.request.KeepRawBody(value)
+			return r
+		}
+
 		{{ range $methodRequestParameters }}
 			{{ $setterName := setterName . }}
 			{{ $setterType := setterType . }}
@@ -633,7 +1534,7 @@ func (g *ClientsGenerator) generatePollMethodSource(resource *concepts.Resource,
 			//
 			{{ lineComment .Doc }}
 			func (r *{{ $requestName }}) {{ $setterName }}(value {{ $setterType }}) *{{ $requestName }} {
-				get.{{ $setterName }}(value)
+				r.request.{{ $setterName }}(value)
 				return r
 			}
 		{{ end }}
@@ -708,6 +1609,43 @@ func (g *ClientsGenerator) generatePollMethodSource(resource *concepts.Resource,
 			return r.response.Header()
 		}
 
+		// RawResponse returns the HTTP response received from the server for the last
+		// request.
+		func (r *{{ $responseName }}) RawResponse() *http.Response {
+			if r == nil {
+				return nil
+			}
+			return r.response.RawResponse()
+		}
+
+		// RawBody returns the bytes of the body of the last response received from the server.
+		// For successful responses it is only available if the request was sent with the
+		// KeepRawBody option, otherwise it returns nil.
+		func (r *{{ $responseName }}) RawBody() []byte {
+			if r == nil {
+				return nil
+			}
+			return r.response.RawBody()
+		}
+
+		// RateLimit returns the rate limit information sent by the server in the last
+		// response, or nil if the server didn't send it.
+		func (r *{{ $responseName }}) RateLimit() *helpers.RateLimit {
+			if r == nil {
+				return nil
+			}
+			return r.response.RateLimit()
+		}
+
+		// OperationID returns the identifier of the operation assigned by the server to the
+		// last request.
+		func (r *{{ $responseName }}) OperationID() string {
+			if r == nil {
+				return ""
+			}
+			return r.response.OperationID()
+		}
+
 		// Error returns the response error.
 		func (r *{{ $responseName }}) Error() *errors.Error {
 			if r == nil {
@@ -765,17 +1703,33 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 		}
 	}
 
+	// List methods that have the paging parameters also get a method to retrieve all the
+	// pages. That method needs to create the list, so the list type must be in the same
+	// package than the client.
+	var page, size, total, items *concepts.Parameter
+	paginated := false
+	if method.IsList() {
+		page = method.GetParameter(nomenclator.Page)
+		size = method.GetParameter(nomenclator.Size)
+		total = method.GetParameter(nomenclator.Total)
+		items = method.GetParameter(nomenclator.Items)
+		paginated = page != nil && size != nil && items != nil &&
+			items.Type().IsList() && items.Type().Owner() == method.Owner().Owner()
+	}
+
 	g.buffer.Import("bytes", "")
 	g.buffer.Import("context", "")
 	g.buffer.Import("encoding/json", "")
 	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
 	g.buffer.Import("io/ioutil", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import("net/url", "")
-	g.buffer.Import("gitub.com/json-iterator/go", "")
+	g.buffer.Import("time", "")
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
 	g.buffer.Import(g.packages.ErrorsImport(), "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("request", `
 		{{ $requestName := requestName .Method }}
 		{{ $requestParameters := requestParameters .Method }}
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
@@ -786,12 +1740,34 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 		{{ $isAction := .Method.IsAction }}
 
 		// {{ $requestName }} is the request for the '{{ .Method.Name }}' method.
+		{{ with .Method.Doc }}
+			//
+			{{ lineComment . }}
+		{{ end }}
+		{{ with .Method.Since }}
+			//
+			// Available since version {{ . }} of the API.
+		{{ end }}
+		{{ exampleComment .Method }}
+		//
+		// Sending a request doesn't modify it, so the same request can be sent concurrently
+		// from multiple goroutines, and each send uses its own copy of the query parameters
+		// and headers. The methods that set parameters and headers do modify the request,
+		// so they must not be called while the request is being sent. To send variations
+		// of a request concurrently use the Clone method to create independent copies.
 		type {{ $requestName }} struct {
 			transport http.RoundTripper
 			path      string
 			metric    string
 			query     url.Values
 			header    http.Header
+			keepRawBody bool
+			{{ if .Method.IsAdd }}
+				idempotencyKey *string
+			{{ end }}
+			{{ if .Paginated }}
+				maxItems *int
+			{{ end }}
 			{{ range $requestParameters }}
 				{{ fieldName . }} {{ fieldType . }}
 			{{ end }}
@@ -809,6 +1785,58 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 			return r
 		}
 
+		// Impersonate asks the server to process the request on behalf of the given user and
+		// groups.
+		func (r *{{ $requestName }}) Impersonate(user string, groups ...string) *{{ $requestName }} {
+			helpers.Impersonate(&r.header, user, groups)
+			return r
+		}
+
+		// KeepRawBody asks the client to keep in memory the bytes of the body of successful
+		// responses, so that they are available with the RawBody and RawResponse methods of
+		// the response. The default is to keep only the bodies of error responses, as the
+		// bodies of successful responses, for example large lists, are decoded while they
+		// are received.
+		func (r *{{ $requestName }}) KeepRawBody(value bool) *{{ $requestName }} {
+			r.keepRawBody = value
+			return r
+		}
+
+		// Clone creates a copy of this request that can be modified and sent independently
+		// of the original. This is intended for preparing a base request with the common
+		// parameters and headers, and then sending copies of it concurrently, for example
+		// from multiple goroutines. Note that the original request must not be modified
+		// while it is being cloned.
+		func (r *{{ $requestName }}) Clone() *{{ $requestName }} {
+			clone := *r
+			clone.query = helpers.CopyQuery(r.query)
+			clone.header = helpers.CopyHeader(r.header)
+			return &clone
+		}
+
+		{{ if or .Method.IsGet .Method.IsList }}
+			// IfModifiedSince sets the 'If-Modified-Since' header, so that the server will
+			// return a 304 status code and no body if the data hasn't been modified since the
+			// given time.
+			func (r *{{ $requestName }}) IfModifiedSince(value time.Time) *{{ $requestName }} {
+				if r.header == nil {
+					r.header = make(http.Header)
+				}
+				r.header.Set("If-Modified-Since", value.UTC().Format(http.TimeFormat))
+				return r
+			}
+		{{ end }}
+
+		{{ if .Method.IsAdd }}
+			// IdempotencyKey sets the key that the server will use to detect retries of this
+			// request. Requests sent with the same key will create the object only once, so
+			// it is safe to retry them. The key should be unique, for example a random UUID.
+			func (r *{{ $requestName }}) IdempotencyKey(value string) *{{ $requestName }} {
+				r.idempotencyKey = &value
+				return r
+			}
+		{{ end }}
+
 		{{ range $requestParameters }}
 			{{ $fieldName := fieldName . }}
 			{{ $setterName := setterName . }}
@@ -831,12 +1859,106 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 		//
 		// This is a potentially lengthy operation, as it requires network communication.
 		// Consider using a context and the SendContext method.
+		{{ if .DeprecateNoContext }}
+		//
+		// Deprecated: Use the SendContext method instead.
+		{{ end }}
 		func (r *{{ $requestName }}) Send() (result *{{ $responseName }}, err error) {
 			return r.SendContext(context.Background())
 		}
 
 		// SendContext sends this request, waits for the response, and returns it.
 		func (r *{{ $requestName }}) SendContext(ctx context.Context) (result *{{ $responseName }}, err error) {
+			request, err := r.httpRequest(ctx)
+			if err != nil {
+				return
+			}
+			response, err := r.transport.RoundTrip(request)
+			if err != nil {
+				return
+			}
+			defer response.Body.Close()
+			raw := *response
+			raw.Body = http.NoBody
+			result = &{{ $responseName }}{}
+			result.status = response.StatusCode
+			result.header = response.Header
+			result.raw = &raw
+			{{ if or .Method.IsGet .Method.IsList }}
+				if result.status == http.StatusNotModified {
+					return
+				}
+			{{ end }}
+			if result.status >= 400 {
+				result.rawBody, err = helpers.ReadErrorBody(response.Body)
+				if err != nil {
+					return
+				}
+				raw.Body = ioutil.NopCloser(bytes.NewReader(result.rawBody))
+				result.err, err = errors.UnmarshalError(bytes.NewReader(result.rawBody))
+				if err != nil {
+					return
+				}
+				err = result.err
+				return
+			}
+			if r.keepRawBody {
+				result.rawBody, err = ioutil.ReadAll(response.Body)
+				if err != nil {
+					return
+				}
+				raw.Body = ioutil.NopCloser(bytes.NewReader(result.rawBody))
+			}
+			{{ if $responseParameters }}
+				var body io.Reader = response.Body
+				if r.keepRawBody {
+					body = bytes.NewReader(result.rawBody)
+				}
+				{{ if and .CBOR (or .Method.IsGet .Method.IsAdd .Method.IsUpdate) }}
+					if helpers.IsCBOR(response.Header) {
+						err = {{ readCBORResponseFunc .Method }}(result, body)
+					} else {
+						err = {{ readResponseFunc .Method }}(result, body)
+					}
+				{{ else }}
+					err = {{ readResponseFunc .Method }}(result, body)
+				{{ end }}
+				if err != nil {
+					return
+				}
+			{{ end }}
+			return
+		}
+
+		// AsCurl returns a curl command that sends a request equivalent to this one. The
+		// address of the server and the access token aren't known by the request, so the
+		// command uses the 'URL' and 'TOKEN' environment variables instead. This is intended
+		// for bug reports and for reproducing issues without the Go client. The values of the
+		// attributes marked as sensitive in the model are replaced by 'REDACTED' in the body.
+		func (r *{{ $requestName }}) AsCurl() (command string, err error) {
+			request, err := r.httpRequest(context.Background())
+			if err != nil {
+				return
+			}
+			var body []byte
+			if request.Body != nil {
+				body, err = ioutil.ReadAll(request.Body)
+				if err != nil {
+					return
+				}
+			}
+			command = helpers.Curl(
+				request,
+				body,
+				{{ range requestBodySensitivePaths .Method }}
+					{{ printf "%q" . }},
+				{{ end }}
+			)
+			return
+		}
+
+		// httpRequest creates the HTTP request that is sent by the SendContext method.
+		func (r *{{ $requestName }}) httpRequest(ctx context.Context) (request *http.Request, err error) {
 			query := helpers.CopyQuery(r.query)
 			{{ range $requestQueryParameters }}
 				{{ $fieldName := fieldName . }}
@@ -846,6 +1968,15 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 				}
 			{{ end }}
 			header := helpers.SetHeader(r.header, r.metric)
+			operationID := helpers.OperationIDFromContext(ctx)
+			if operationID != "" && header.Get(helpers.OperationIDHeader) == "" {
+				header.Set(helpers.OperationIDHeader, operationID)
+			}
+			{{ if .Method.IsAdd }}
+				if r.idempotencyKey != nil {
+					header.Set(helpers.IdempotencyKeyHeader, *r.idempotencyKey)
+				}
+			{{ end }}
 			{{ if $requestBodyParameters }}
 				buffer := &bytes.Buffer{}
 				err = {{ writeRequestFunc .Method }}(r, buffer)
@@ -857,7 +1988,7 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 				Path: r.path,
 				RawQuery: query.Encode(),
 			}
-			request := &http.Request{
+			request = &http.Request{
 				Method: "{{ httpMethod .Method }}",
 				URL:    uri,
 				Header: header,
@@ -868,30 +1999,91 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 			if ctx != nil {
 				request = request.WithContext(ctx)
 			}
-			response, err := r.transport.RoundTrip(request)
-			if err != nil {
-				return
+			return
+		}
+
+		{{ if .Paginated }}
+			{{ $listName := structName .Items.Type }}
+			{{ $elementName := structName .Items.Type.Element }}
+			{{ $pageField := fieldName .Page }}
+			{{ $sizeField := fieldName .Size }}
+
+			// MaxItems sets the maximum number of items that the ListAll method will
+			// retrieve. If the server has more items than this the ListAll method will
+			// return an error instead of a partial list. The default is 10000.
+			func (r *{{ $requestName }}) MaxItems(value int) *{{ $requestName }} {
+				r.maxItems = &value
+				return r
 			}
-			defer response.Body.Close()
-			result = &{{ $responseName }}{}
-			result.status = response.StatusCode
-			result.header = response.Header
-			if result.status >= 400 {
-				result.err, err = errors.UnmarshalError(response.Body)
-				if err != nil {
-					return
+
+			// ListAll sends this request repeatedly, once for each page, starting with the
+			// page set in the request or with the first page, and returns a list containing
+			// the items of all the pages. This is intended for the cases where all the items
+			// are really needed, otherwise it is better to use the paging parameters and
+			// process one page at a time.
+			func (r *{{ $requestName }}) ListAll(ctx context.Context) (result *{{ $listName }}, err error) {
+				maxItems := helpers.DefaultMaxItems
+				if r.maxItems != nil {
+					maxItems = *r.maxItems
+				}
+				page := 1
+				if r.{{ $pageField }} != nil {
+					page = *r.{{ $pageField }}
+				}
+				pageSize := 0
+				if r.{{ $sizeField }} != nil {
+					pageSize = *r.{{ $sizeField }}
+				}
+				request := *r
+				var all []*{{ $elementName }}
+				for {
+					if ctx != nil {
+						err = ctx.Err()
+						if err != nil {
+							return
+						}
+					}
+					current := page
+					request.{{ $pageField }} = &current
+					var response *{{ $responseName }}
+					response, err = request.SendContext(ctx)
+					if err != nil {
+						return
+					}
+					list := response.{{ getterName .Items }}()
+					count := list.Len()
+					if len(all)+count > maxItems {
+						err = fmt.Errorf(
+							"list has more than the maximum of %d items",
+							maxItems,
+						)
+						return
+					}
+					all = append(all, list.Slice()...)
+					// The size returned by the server is the number of items of the page, so
+					// the last page is detected comparing the number of items with the size
+					// requested. If no size was requested the server uses its default, and
+					// that is the size of the first page.
+					if count == 0 || count < pageSize {
+						break
+					}
+					if pageSize == 0 {
+						pageSize = count
+					}
+					{{ if .Total }}
+						total, ok := response.Get{{ getterName .Total }}()
+						if ok && len(all) >= total {
+							break
+						}
+					{{ end }}
+					page++
+				}
+				result = &{{ $listName }}{
+					items: all,
 				}
-				err = result.err
 				return
 			}
-			{{ if $responseParameters }}
-				err = {{ readResponseFunc .Method }}(result, response.Body)
-				if err != nil {
-					return
-				}
-			{{ end }}
-			return
-		}
+		{{ end }}
 
 		{{ if $requestBodyParameters }}
 			// marshall is the method used internally to marshal requests for the
@@ -909,6 +2101,13 @@ func (g *ClientsGenerator) generateRequestSource(method *concepts.Method) {
 		"Method", method,
 		"Main", main,
 		"Others", others,
+		"DeprecateNoContext", g.deprecateNoContext,
+		"CBOR", g.cbor,
+		"Paginated", paginated,
+		"Page", page,
+		"Size", size,
+		"Total", total,
+		"Items", items,
 	)
 }
 
@@ -928,8 +2127,10 @@ func (g *ClientsGenerator) generateResponseSource(method *concepts.Method) {
 	// Generate the code:
 	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
+	g.buffer.Import("time", "")
 	g.buffer.Import(g.packages.ErrorsImport(), "")
-	g.buffer.Emit(`
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("response", `
 		{{ $responseName := responseName .Method }}
 		{{ $responseParameters := responseParameters .Method }}
 		{{ $responseBodyLen := len $responseParameters }}
@@ -937,9 +2138,11 @@ func (g *ClientsGenerator) generateResponseSource(method *concepts.Method) {
 
 		// {{ $responseName }} is the response for the '{{ .Method.Name }}' method.
 		type  {{ $responseName }} struct {
-			status int
-			header http.Header
-			err    *errors.Error
+			status  int
+			header  http.Header
+			raw     *http.Response
+			rawBody []byte
+			err     *errors.Error
 			{{ range $responseParameters }}
 				{{ fieldName . }} {{ fieldType . }}
 			{{ end }}
@@ -961,6 +2164,39 @@ func (g *ClientsGenerator) generateResponseSource(method *concepts.Method) {
 			return r.header
 		}
 
+		// RawResponse returns the HTTP response received from the server, for the cases where
+		// the typed methods aren't enough. The bodies of successful responses are decoded
+		// while they are received, so they are only available here if the request was sent
+		// with the KeepRawBody option. The bodies of error responses are always available.
+		// In both cases the body contains the bytes received from the server and can be read
+		// only once.
+		func (r *{{ $responseName }}) RawResponse() *http.Response {
+			if r == nil {
+				return nil
+			}
+			return r.raw
+		}
+
+		// RawBody returns the bytes of the body of the response, as received from the server.
+		// It is always available for error responses, but for successful responses it is
+		// only available if the request was sent with the KeepRawBody option, otherwise it
+		// returns nil.
+		func (r *{{ $responseName }}) RawBody() []byte {
+			if r == nil {
+				return nil
+			}
+			return r.rawBody
+		}
+
+		// RateLimit returns the rate limit information sent by the server, or nil if the server
+		// didn't send it.
+		func (r *{{ $responseName }}) RateLimit() *helpers.RateLimit {
+			if r == nil {
+				return nil
+			}
+			return helpers.ParseRateLimit(r.header)
+		}
+
 		// Error returns the response error.
 		func (r *{{ $responseName }}) Error() *errors.Error {
 			if r == nil {
@@ -969,6 +2205,47 @@ func (g *ClientsGenerator) generateResponseSource(method *concepts.Method) {
 			return r.err
 		}
 
+		{{ if or .Method.IsGet .Method.IsList }}
+			// LastModified returns the time when the data was last modified, as reported by
+			// the server in the 'Last-Modified' header, or the zero time if the server didn't
+			// send it.
+			func (r *{{ $responseName }}) LastModified() time.Time {
+				value, _ := r.GetLastModified()
+				return value
+			}
+
+			// GetLastModified returns the time when the data was last modified and a flag
+			// indicating if the server sent it.
+			func (r *{{ $responseName }}) GetLastModified() (value time.Time, ok bool) {
+				if r == nil {
+					return
+				}
+				header := r.header.Get("Last-Modified")
+				if header == "" {
+					return
+				}
+				value, err := http.ParseTime(header)
+				ok = err == nil
+				return
+			}
+
+			// NotModified returns true if the server responded that the data hasn't been
+			// modified since the time given with the 'IfModifiedSince' method. In that case
+			// the response doesn't contain the data.
+			func (r *{{ $responseName }}) NotModified() bool {
+				return r != nil && r.status == http.StatusNotModified
+			}
+		{{ end }}
+
+		// OperationID returns the identifier of the operation assigned by the server, so
+		// that it can be used to find the details of the request in the logs of the server.
+		func (r *{{ $responseName }}) OperationID() string {
+			if r == nil {
+				return ""
+			}
+			return r.header.Get(helpers.OperationIDHeader)
+		}
+
 		{{ range $responseParameters }}
 			{{ $fieldName := fieldName . }}
 			{{ $getterName := getterName . }}
@@ -1030,14 +2307,71 @@ func (g *ClientsGenerator) resourceFile(resource *concepts.Resource) string {
 	return g.names.File(names.Cat(resource.Name(), nomenclator.Client))
 }
 
+func (g *ClientsGenerator) expandFile() string {
+	return g.names.File(nomenclator.Expand)
+}
+
+func (g *ClientsGenerator) fetchFile() string {
+	return g.names.File(nomenclator.Fetch)
+}
+
+func (g *ClientsGenerator) classTypes(version *concepts.Version) concepts.TypeSlice {
+	var result concepts.TypeSlice
+	for _, typ := range version.Types() {
+		if typ.IsClass() {
+			result = append(result, typ)
+		}
+	}
+	return result
+}
+
+// exampleComment generates the Go comment that contains the examples of the given method. The
+// text of each example is indented so that it is displayed as preformatted text by 'go doc'.
+func (g *ClientsGenerator) exampleComment(method *concepts.Method) string {
+	buffer := &strings.Builder{}
+	for _, example := range method.Examples() {
+		buffer.WriteString("//\n")
+		switch {
+		case example.IsRequest():
+			buffer.WriteString("// Example request")
+		case example.IsResponse():
+			buffer.WriteString("// Example response")
+		default:
+			buffer.WriteString("// Example")
+		}
+		if example.Summary() != "" {
+			fmt.Fprintf(buffer, ": %s", example.Summary())
+		}
+		buffer.WriteString("\n//\n")
+		for _, line := range strings.Split(example.Text(), "\n") {
+			if line == "" {
+				buffer.WriteString("//\n")
+			} else {
+				fmt.Fprintf(buffer, "//\t%s\n", line)
+			}
+		}
+	}
+	return buffer.String()
+}
+
+func (g *ClientsGenerator) objectName(typ *concepts.Type) string {
+	return g.names.Public(typ.Name())
+}
+
+func (g *ClientsGenerator) listName(typ *concepts.Type) string {
+	return g.names.Public(names.Cat(typ.Name(), nomenclator.List))
+}
+
+func (g *ClientsGenerator) readListFunc(typ *concepts.Type) string {
+	return g.names.Private(names.Cat(nomenclator.Read, typ.Name(), nomenclator.List))
+}
+
 func (g *ClientsGenerator) enumName(typ *concepts.Type) string {
 	return g.names.Public(typ.Name())
 }
 
 func (g *ClientsGenerator) fieldName(parameter *concepts.Parameter) string {
-	name := g.names.Private(parameter.Name())
-	name = g.avoidBuiltin(name, builtinFields)
-	return name
+	return g.names.Field(parameter.Name())
 }
 
 func (g *ClientsGenerator) fieldType(parameter *concepts.Parameter) *TypeReference {
@@ -1059,9 +2393,7 @@ func (g *ClientsGenerator) fieldType(parameter *concepts.Parameter) *TypeReferen
 }
 
 func (g *ClientsGenerator) getterName(parameter *concepts.Parameter) string {
-	name := g.names.Public(parameter.Name())
-	name = g.avoidBuiltin(name, builtinGetters)
-	return name
+	return g.names.Accessor(parameter.Name())
 }
 
 func (g *ClientsGenerator) getterType(parameter *concepts.Parameter) *TypeReference {
@@ -1069,9 +2401,7 @@ func (g *ClientsGenerator) getterType(parameter *concepts.Parameter) *TypeRefere
 }
 
 func (g *ClientsGenerator) setterName(parameter *concepts.Parameter) string {
-	name := g.names.Public(parameter.Name())
-	name = g.avoidBuiltin(name, builtinSetters)
-	return name
+	return g.names.Accessor(parameter.Name())
 }
 
 func (g *ClientsGenerator) setterType(parameter *concepts.Parameter) *TypeReference {
@@ -1188,25 +2518,24 @@ func (g *ClientsGenerator) readResponseFunc(method *concepts.Method) string {
 	return g.names.Private(name)
 }
 
-func (g *ClientsGenerator) avoidBuiltin(name string, builtins map[string]interface{}) string {
-	_, ok := builtins[name]
-	if ok {
-		name = name + "_"
+func (g *ClientsGenerator) readCBORResponseFunc(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(
+			nomenclator.Read,
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	} else {
+		name = names.Cat(
+			nomenclator.Read,
+			resource.Name(),
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
 	}
-	return name
-}
-
-var builtinFields = map[string]interface{}{
-	"err":    nil,
-	"status": nil,
-}
-
-var builtinGetters = map[string]interface{}{
-	"Error":  nil,
-	"Status": nil,
-}
-
-var builtinSetters = map[string]interface{}{
-	"Error":  nil,
-	"Status": nil,
+	return g.names.Private(name)
 }
@@ -0,0 +1,543 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// ConversionsGeneratorBuilder is an object used to configure and build the conversions generator.
+// Don't create instances directly, use the NewConversionsGenerator function instead.
+type ConversionsGeneratorBuilder struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+}
+
+// ConversionsGenerator generates functions that convert objects between adjacent versions of the
+// same service. Don't create instances directly, use the builder instead.
+type ConversionsGenerator struct {
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
+}
+
+// NewConversionsGenerator creates a new builder for conversions generators.
+func NewConversionsGenerator() *ConversionsGeneratorBuilder {
+	return &ConversionsGeneratorBuilder{}
+}
+
+// Reporter sets the object that will be used to report information about the generation process,
+// including errors.
+func (b *ConversionsGeneratorBuilder) Reporter(value *reporter.Reporter) *ConversionsGeneratorBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be used by the conversions generator.
+func (b *ConversionsGeneratorBuilder) Model(value *concepts.Model) *ConversionsGeneratorBuilder {
+	b.model = value
+	return b
+}
+
+// Output sets the directory where the source will be generated.
+func (b *ConversionsGeneratorBuilder) Output(value string) *ConversionsGeneratorBuilder {
+	b.output = value
+	return b
+}
+
+// Packages sets the object that will be used to calculate package names.
+func (b *ConversionsGeneratorBuilder) Packages(
+	value *PackagesCalculator) *ConversionsGeneratorBuilder {
+	b.packages = value
+	return b
+}
+
+// Names sets the object that will be used to calculate names.
+func (b *ConversionsGeneratorBuilder) Names(value *NamesCalculator) *ConversionsGeneratorBuilder {
+	b.names = value
+	return b
+}
+
+// Types sets the object that will be used to calculate types.
+func (b *ConversionsGeneratorBuilder) Types(value *TypesCalculator) *ConversionsGeneratorBuilder {
+	b.types = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *ConversionsGeneratorBuilder) Header(value *Header) *ConversionsGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *ConversionsGeneratorBuilder) Stream(value bool) *ConversionsGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *ConversionsGeneratorBuilder) MaxLines(value int) *ConversionsGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *ConversionsGeneratorBuilder) Templates(value *Templates) *ConversionsGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// conversions generator using it.
+func (b *ConversionsGeneratorBuilder) Build() (generator *ConversionsGenerator, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output is mandatory")
+		return
+	}
+	if b.packages == nil {
+		err = fmt.Errorf("packages calculator is mandatory")
+		return
+	}
+	if b.names == nil {
+		err = fmt.Errorf("names calculator is mandatory")
+		return
+	}
+	if b.types == nil {
+		err = fmt.Errorf("types is mandatory")
+		return
+	}
+
+	// Create the generator:
+	generator = &ConversionsGenerator{
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		types:     b.types,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+	}
+
+	return
+}
+
+// typeConversion describes the conversion of objects of a struct type of one version to the type
+// with the same name of another version.
+type typeConversion struct {
+	Source *concepts.Type
+	Target *concepts.Type
+}
+
+// attributeConversion describes how to convert the value of an attribute. The kind is one of the
+// conversion* constants.
+type attributeConversion struct {
+	Source *concepts.Attribute
+	Target *concepts.Attribute
+	Kind   string
+	Values []*enumValueConversion
+}
+
+// enumValueConversion describes the conversion of a value of an enumerated type.
+type enumValueConversion struct {
+	Source *concepts.EnumValue
+	Target *concepts.EnumValue
+}
+
+// Kinds of attribute conversions:
+const (
+	conversionNone    = "none"
+	conversionValue   = "value"
+	conversionEnum    = "enum"
+	conversionStruct  = "struct"
+	conversionValues  = "values"
+	conversionStructs = "structs"
+)
+
+// Run executes the code generator.
+func (g *ConversionsGenerator) Run() error {
+	var err error
+
+	// Generate the code for each service that has more than one version:
+	for _, service := range g.model.Services() {
+		versions := service.Versions()
+		if len(versions) < 2 {
+			continue
+		}
+		g.reporter.Infof("Generating conversions for service '%s'", service.Name())
+		err = g.generateServiceConversions(service)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Check if there were errors:
+	if g.errors > 0 {
+		if g.errors > 1 {
+			err = fmt.Errorf("there were %d errors", g.errors)
+		} else {
+			err = fmt.Errorf("there was 1 error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *ConversionsGenerator) generateServiceConversions(service *concepts.Service) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.ServicePackage(service)
+	fileName := g.names.File(nomenclator.Conversions)
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("conversions").
+		Package(pkgName).
+		File(fileName).
+		Function("attributeConversions", g.attributeConversions).
+		Function("builderCtor", g.builderCtor).
+		Function("builderType", g.builderType).
+		Function("converterName", g.converterName).
+		Function("enumValueName", g.enumValueName).
+		Function("getterName", g.getterName).
+		Function("objectType", g.objectType).
+		Function("publicConverterName", g.publicConverterName).
+		Function("setterName", g.setterName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Calculate the conversions between each pair of adjacent versions, in both directions:
+	var conversions []*typeConversion
+	versions := service.Versions()
+	for i := 0; i < len(versions)-1; i++ {
+		older := versions[i]
+		newer := versions[i+1]
+		conversions = append(conversions, g.typeConversions(older, newer)...)
+		conversions = append(conversions, g.typeConversions(newer, older)...)
+	}
+
+	// Generate the code:
+	for _, conversion := range conversions {
+		g.generateConversionSource(conversion)
+	}
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *ConversionsGenerator) generateConversionSource(conversion *typeConversion) {
+	g.buffer.Import(g.packages.VersionImport(conversion.Source.Owner()), "")
+	g.buffer.Import(g.packages.VersionImport(conversion.Target.Owner()), "")
+	g.buffer.Import("fmt", "")
+	g.buffer.EmitTemplate("conversion", `
+		{{ $sourceType := objectType .Source }}
+		{{ $targetType := objectType .Target }}
+		{{ $converterName := converterName .Source .Target }}
+		{{ $publicConverterName := publicConverterName .Source .Target }}
+
+		// {{ $publicConverterName }} converts an object of type '{{ .Source.Name }}' from
+		// version '{{ .Source.Owner.Name }}' to version '{{ .Target.Owner.Name }}'. The
+		// attributes that have the same name and a compatible type in both versions are
+		// copied. The paths of the attributes that have a value that can't be converted are
+		// returned in the unconverted slice, using the JSON names of the attributes and
+		// dots to separate the names of nested attributes.
+		func {{ $publicConverterName }}(object *{{ $sourceType }}) (result *{{ $targetType }}, unconverted []string, err error) {
+			if object == nil {
+				return
+			}
+			result, err = {{ $converterName }}(object, "", &unconverted).Build()
+			return
+		}
+
+		// {{ $converterName }} creates a builder for the '{{ .Target.Owner.Name }}' version of
+		// the given object, and adds to the unconverted slice the paths of the attributes
+		// that can't be converted.
+		func {{ $converterName }}(object *{{ $sourceType }}, prefix string, unconverted *[]string) {{ builderType .Target }} {
+			if object == nil {
+				return nil
+			}
+			builder := {{ builderCtor .Target }}()
+			{{ if and .Source.IsClass .Target.IsClass }}
+				if value, ok := object.GetID(); ok {
+					builder.ID(value)
+				}
+				builder.Link(object.Link())
+			{{ end }}
+			{{ range attributeConversions .Source .Target }}
+				{{ $getterName := getterName .Source }}
+				{{ $field := .Source.Name.Snake }}
+				{{ if eq .Kind "value" }}
+					if value, ok := object.Get{{ $getterName }}(); ok {
+						builder.{{ setterName .Target }}(value)
+					}
+				{{ else if eq .Kind "values" }}
+					if value, ok := object.Get{{ $getterName }}(); ok {
+						builder.{{ setterName .Target }}(value...)
+					}
+				{{ else if eq .Kind "enum" }}
+					if value, ok := object.Get{{ $getterName }}(); ok {
+						switch value {
+						{{ $setterName := setterName .Target }}
+						{{ range .Values }}
+							case {{ enumValueName .Source }}:
+								builder.{{ $setterName }}({{ enumValueName .Target }})
+						{{ end }}
+						default:
+							*unconverted = append(*unconverted, prefix+"{{ $field }}")
+						}
+					}
+				{{ else if eq .Kind "struct" }}
+					if value, ok := object.Get{{ $getterName }}(); ok {
+						builder.{{ setterName .Target }}(
+							{{ converterName .Source.Type .Target.Type }}(value, prefix+"{{ $field }}.", unconverted),
+						)
+					}
+				{{ else if eq .Kind "structs" }}
+					if value, ok := object.Get{{ $getterName }}(); ok {
+						items := make([]{{ builderType .Target.Type.Element }}, len(value))
+						for i, item := range value {
+							items[i] = {{ converterName .Source.Type.Element .Target.Type.Element }}(
+								item,
+								fmt.Sprintf("%s{{ $field }}[%d].", prefix, i),
+								unconverted,
+							)
+						}
+						builder.{{ setterName .Target }}(items...)
+					}
+				{{ else }}
+					if _, ok := object.Get{{ $getterName }}(); ok {
+						*unconverted = append(*unconverted, prefix+"{{ $field }}")
+					}
+				{{ end }}
+			{{ end }}
+			return builder
+		}
+		`,
+		"Source", conversion.Source,
+		"Target", conversion.Target,
+	)
+}
+
+// typeConversions calculates the conversions for the struct types of the source version that have a
+// struct type with the same name in the target version.
+func (g *ConversionsGenerator) typeConversions(source,
+	target *concepts.Version) []*typeConversion {
+	var conversions []*typeConversion
+	for _, sourceType := range source.Types() {
+		if !sourceType.IsStruct() {
+			continue
+		}
+		targetType := target.FindType(sourceType.Name())
+		if targetType == nil || !targetType.IsStruct() {
+			continue
+		}
+		conversions = append(conversions, &typeConversion{
+			Source: sourceType,
+			Target: targetType,
+		})
+	}
+	return conversions
+}
+
+// attributeConversions calculates how to convert each of the attributes of the source type.
+func (g *ConversionsGenerator) attributeConversions(source,
+	target *concepts.Type) []*attributeConversion {
+	var conversions []*attributeConversion
+	for _, sourceAttribute := range source.Attributes() {
+		conversion := &attributeConversion{
+			Source: sourceAttribute,
+			Kind:   conversionNone,
+		}
+		for _, targetAttribute := range target.Attributes() {
+			if targetAttribute.Name().Equals(sourceAttribute.Name()) {
+				conversion.Target = targetAttribute
+				break
+			}
+		}
+		if conversion.Target != nil && !sourceAttribute.Link() && !conversion.Target.Link() {
+			g.completeAttributeConversion(conversion)
+		}
+		conversions = append(conversions, conversion)
+	}
+	return conversions
+}
+
+func (g *ConversionsGenerator) completeAttributeConversion(conversion *attributeConversion) {
+	sourceType := conversion.Source.Type()
+	targetType := conversion.Target.Type()
+	switch {
+	case sourceType.IsEnum() && targetType.IsEnum():
+		if sourceType.Name().Equals(targetType.Name()) {
+			conversion.Kind = conversionEnum
+			conversion.Values = g.enumValueConversions(sourceType, targetType)
+		}
+	case sourceType.IsScalar() && targetType.IsScalar():
+		if sourceType.Name().Equals(targetType.Name()) {
+			conversion.Kind = conversionValue
+		}
+	case sourceType.IsStruct() && targetType.IsStruct():
+		if sourceType.Name().Equals(targetType.Name()) {
+			conversion.Kind = conversionStruct
+		}
+	case sourceType.IsList() && targetType.IsList():
+		sourceElement := sourceType.Element()
+		targetElement := targetType.Element()
+		switch {
+		case sourceElement.IsEnum() || targetElement.IsEnum():
+			// Lists of enumerated values aren't converted.
+		case sourceElement.IsScalar() && targetElement.IsScalar():
+			if sourceElement.Name().Equals(targetElement.Name()) {
+				conversion.Kind = conversionValues
+			}
+		case sourceElement.IsStruct() && targetElement.IsStruct():
+			if sourceElement.Name().Equals(targetElement.Name()) {
+				conversion.Kind = conversionStructs
+			}
+		}
+	case sourceType.IsMap() && targetType.IsMap():
+		sourceElement := sourceType.Element()
+		targetElement := targetType.Element()
+		if !sourceElement.IsEnum() && sourceElement.IsScalar() &&
+			sourceElement.Name().Equals(targetElement.Name()) {
+			conversion.Kind = conversionValue
+		}
+	}
+}
+
+// enumValueConversions calculates the conversions for the values that exist in both enumerated
+// types.
+func (g *ConversionsGenerator) enumValueConversions(source,
+	target *concepts.Type) []*enumValueConversion {
+	var conversions []*enumValueConversion
+	for _, sourceValue := range source.Values() {
+		for _, targetValue := range target.Values() {
+			if targetValue.Name().Equals(sourceValue.Name()) {
+				conversions = append(conversions, &enumValueConversion{
+					Source: sourceValue,
+					Target: targetValue,
+				})
+				break
+			}
+		}
+	}
+	return conversions
+}
+
+func (g *ConversionsGenerator) objectType(typ *concepts.Type) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		g.packages.VersionSelector(typ.Owner()),
+		g.names.Public(typ.Name()),
+	)
+}
+
+func (g *ConversionsGenerator) builderType(typ *concepts.Type) string {
+	return g.types.BuilderReference(typ).Text()
+}
+
+func (g *ConversionsGenerator) builderCtor(typ *concepts.Type) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		g.packages.VersionSelector(typ.Owner()),
+		g.names.Public(names.Cat(nomenclator.New, typ.Name())),
+	)
+}
+
+func (g *ConversionsGenerator) enumValueName(value *concepts.EnumValue) string {
+	return fmt.Sprintf(
+		"%s.%s",
+		g.packages.VersionSelector(value.Type().Owner()),
+		g.names.Public(names.Cat(value.Type().Name(), value.Name())),
+	)
+}
+
+func (g *ConversionsGenerator) converterName(source, target *concepts.Type) string {
+	return g.names.Private(g.conversionName(source, target))
+}
+
+func (g *ConversionsGenerator) publicConverterName(source, target *concepts.Type) string {
+	return g.names.Public(g.conversionName(source, target))
+}
+
+func (g *ConversionsGenerator) conversionName(source, target *concepts.Type) *names.Name {
+	return names.Cat(
+		nomenclator.Convert,
+		source.Name(),
+		source.Owner().Name(),
+		nomenclator.To,
+		target.Owner().Name(),
+	)
+}
+
+func (g *ConversionsGenerator) getterName(attribute *concepts.Attribute) string {
+	return g.names.Public(attribute.Name())
+}
+
+func (g *ConversionsGenerator) setterName(attribute *concepts.Attribute) string {
+	return g.names.Public(attribute.Name())
+}
@@ -0,0 +1,681 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the generator that creates the package containing the descriptors of the
+// model, so that tools can inspect the services, types and resources at run time.
+
+package golang
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// DescriptorsGeneratorBuilder is an object used to configure and build the descriptors generator.
+// Don't create instances directly, use the NewDescriptorsGenerator function instead.
+type DescriptorsGeneratorBuilder struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	binding   *http.BindingCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+}
+
+// DescriptorsGenerator generates the descriptors of the model. Don't create instances directly,
+// use the builder instead.
+type DescriptorsGenerator struct {
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	binding   *http.BindingCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
+}
+
+// NewDescriptorsGenerator creates a new builder for descriptors generators.
+func NewDescriptorsGenerator() *DescriptorsGeneratorBuilder {
+	return new(DescriptorsGeneratorBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the generation process,
+// including errors.
+func (b *DescriptorsGeneratorBuilder) Reporter(
+	value *reporter.Reporter) *DescriptorsGeneratorBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be used by the descriptors generator.
+func (b *DescriptorsGeneratorBuilder) Model(value *concepts.Model) *DescriptorsGeneratorBuilder {
+	b.model = value
+	return b
+}
+
+// Output sets the output directory.
+func (b *DescriptorsGeneratorBuilder) Output(value string) *DescriptorsGeneratorBuilder {
+	b.output = value
+	return b
+}
+
+// Packages sets the object that will be used to calculate package names.
+func (b *DescriptorsGeneratorBuilder) Packages(
+	value *PackagesCalculator) *DescriptorsGeneratorBuilder {
+	b.packages = value
+	return b
+}
+
+// Binding sets the object that will by used to do HTTP binding calculations.
+func (b *DescriptorsGeneratorBuilder) Binding(
+	value *http.BindingCalculator) *DescriptorsGeneratorBuilder {
+	b.binding = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *DescriptorsGeneratorBuilder) Header(value *Header) *DescriptorsGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *DescriptorsGeneratorBuilder) Stream(value bool) *DescriptorsGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *DescriptorsGeneratorBuilder) MaxLines(value int) *DescriptorsGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *DescriptorsGeneratorBuilder) Templates(value *Templates) *DescriptorsGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// descriptors generator using it.
+func (b *DescriptorsGeneratorBuilder) Build() (generator *DescriptorsGenerator, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output path is mandatory")
+		return
+	}
+	if b.packages == nil {
+		err = fmt.Errorf("packages calculator is mandatory")
+		return
+	}
+	if b.binding == nil {
+		err = fmt.Errorf("HTTP binding calculator is mandatory")
+		return
+	}
+
+	// Create the generator:
+	generator = &DescriptorsGenerator{
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		binding:   b.binding,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+	}
+
+	return
+}
+
+// Run executes the code generator.
+func (g *DescriptorsGenerator) Run() error {
+	var err error
+
+	// Generate the types used by the descriptors and then the descriptors themselves:
+	err = g.generateTypes()
+	if err != nil {
+		return err
+	}
+	err = g.generateModel()
+	if err != nil {
+		return err
+	}
+
+	// Check if there were errors:
+	if g.errors > 0 {
+		if g.errors > 1 {
+			err = fmt.Errorf("there were %d errors", g.errors)
+		} else {
+			err = fmt.Errorf("there was 1 error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *DescriptorsGenerator) createBuffer(fileName string) error {
+	var err error
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("descriptors").
+		Package(g.packages.DescriptorsPackage()).
+		File(fileName).
+		Function("typeKind", g.typeKind).
+		Function("typeName", g.typeName).
+		Function("attributeField", g.binding.AttributeName).
+		Function("parameterField", g.binding.ParameterName).
+		Function("httpMethod", g.binding.Method).
+		Function("versionPath", g.versionPath).
+		Function("locatorPath", g.locatorPath).
+		Function("pathTarget", g.pathTarget).
+		Function("enumValueName", g.binding.EnumValueName).
+		Build()
+	return err
+}
+
+func (g *DescriptorsGenerator) generateTypes() error {
+	err := g.createBuffer("descriptors")
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+	g.buffer.EmitTemplate("types", `
+		// TypeKind is the kind of a type of the model.
+		type TypeKind string
+
+		// Values of the TypeKind type:
+		const (
+			BooleanKind   TypeKind = "boolean"
+			IntegerKind   TypeKind = "integer"
+			LongKind      TypeKind = "long"
+			FloatKind     TypeKind = "float"
+			StringKind    TypeKind = "string"
+			DateKind      TypeKind = "date"
+			InterfaceKind TypeKind = "interface"
+			EnumKind      TypeKind = "enum"
+			ListKind      TypeKind = "list"
+			MapKind       TypeKind = "map"
+			StructKind    TypeKind = "struct"
+			ClassKind     TypeKind = "class"
+		)
+
+		// ModelDescriptor describes the complete model.
+		type ModelDescriptor struct {
+			// Services of the model.
+			Services []*ServiceDescriptor
+		}
+
+		// ServiceDescriptor describes a service of the model.
+		type ServiceDescriptor struct {
+			// Name of the service, as used in the URL paths.
+			Name string
+
+			// Versions of the service.
+			Versions []*VersionDescriptor
+		}
+
+		// VersionDescriptor describes a version of a service.
+		type VersionDescriptor struct {
+			// Name of the version, as used in the URL paths.
+			Name string
+
+			// Path is the absolute URL path of the root resource of the version.
+			Path string
+
+			// Types defined in the version.
+			Types []*TypeDescriptor
+
+			// Resources defined in the version.
+			Resources []*ResourceDescriptor
+
+			// Paths contains the URL paths of all the resources reachable from the
+			// root resource of the version.
+			Paths []*PathDescriptor
+		}
+
+		// TypeDescriptor describes a type.
+		type TypeDescriptor struct {
+			// Name of the type, as it appears in the model.
+			Name string
+
+			// Kind of the type.
+			Kind TypeKind
+
+			// Doc is the documentation of the type.
+			Doc string
+
+			// Element is the name of the type of the elements of list and map
+			// types.
+			Element string
+
+			// Index is the name of the type of the keys of map types.
+			Index string
+
+			// Values contains the values of enum types, as they are sent in
+			// JSON documents.
+			Values []string
+
+			// Attributes of struct and class types.
+			Attributes []*AttributeDescriptor
+		}
+
+		// AttributeDescriptor describes an attribute of an struct or class type.
+		type AttributeDescriptor struct {
+			// Name of the attribute, as it appears in the model.
+			Name string
+
+			// Field is the name of the attribute in JSON documents.
+			Field string
+
+			// Type is the name of the type of the attribute.
+			Type string
+
+			// Doc is the documentation of the attribute.
+			Doc string
+
+			// Link is true if the attribute is a link to another object.
+			Link bool
+
+			// ReadOnly is true if the attribute can't be set by clients.
+			ReadOnly bool
+
+			// Immutable is true if the attribute can't be changed once the object
+			// has been created.
+			Immutable bool
+
+			// Since is the version of the API where the attribute was introduced,
+			// if known.
+			Since string
+		}
+
+		// ResourceDescriptor describes a resource.
+		type ResourceDescriptor struct {
+			// Name of the resource, as it appears in the model.
+			Name string
+
+			// Doc is the documentation of the resource.
+			Doc string
+
+			// Methods of the resource.
+			Methods []*MethodDescriptor
+
+			// Locators of the resource.
+			Locators []*LocatorDescriptor
+		}
+
+		// MethodDescriptor describes a method of a resource.
+		type MethodDescriptor struct {
+			// Name of the method, as it appears in the model.
+			Name string
+
+			// Doc is the documentation of the method.
+			Doc string
+
+			// HTTPMethod is the HTTP method used to send requests for this method.
+			HTTPMethod string
+
+			// Parameters of the method.
+			Parameters []*ParameterDescriptor
+
+			// Since is the version of the API where the method was introduced, if
+			// known.
+			Since string
+		}
+
+		// ParameterDescriptor describes a parameter of a method.
+		type ParameterDescriptor struct {
+			// Name of the parameter, as it appears in the model.
+			Name string
+
+			// Field is the name of the parameter in query strings and JSON
+			// documents.
+			Field string
+
+			// Type is the name of the type of the parameter.
+			Type string
+
+			// In is true if the parameter is sent in requests.
+			In bool
+
+			// Out is true if the parameter is received in responses.
+			Out bool
+		}
+
+		// LocatorDescriptor describes a locator of a resource.
+		type LocatorDescriptor struct {
+			// Name of the locator, as it appears in the model.
+			Name string
+
+			// Segment is the URL path segment of the locator. For variable
+			// locators it is the name of the variable.
+			Segment string
+
+			// Variable is true if the locator takes an identifier.
+			Variable bool
+
+			// Target is the name of the resource that the locator points to.
+			Target string
+		}
+
+		// PathDescriptor describes an URL path of a version.
+		type PathDescriptor struct {
+			// Path is the absolute URL path, using variables like '{cluster_id}'
+			// for the identifiers.
+			Path string
+
+			// Resource is the name of the resource that is reached using the path.
+			Resource string
+		}
+
+		// FindService returns the descriptor of the service with the given name, or nil if
+		// there is no such service.
+		func (d *ModelDescriptor) FindService(name string) *ServiceDescriptor {
+			for _, service := range d.Services {
+				if service.Name == name {
+					return service
+				}
+			}
+			return nil
+		}
+
+		// FindVersion returns the descriptor of the version with the given name, or nil if
+		// there is no such version.
+		func (d *ServiceDescriptor) FindVersion(name string) *VersionDescriptor {
+			for _, version := range d.Versions {
+				if version.Name == name {
+					return version
+				}
+			}
+			return nil
+		}
+
+		// FindType returns the descriptor of the type with the given name, or nil if there is
+		// no such type.
+		func (d *VersionDescriptor) FindType(name string) *TypeDescriptor {
+			for _, typ := range d.Types {
+				if typ.Name == name {
+					return typ
+				}
+			}
+			return nil
+		}
+
+		// FindResource returns the descriptor of the resource with the given name, or nil if
+		// there is no such resource.
+		func (d *VersionDescriptor) FindResource(name string) *ResourceDescriptor {
+			for _, resource := range d.Resources {
+				if resource.Name == name {
+					return resource
+				}
+			}
+			return nil
+		}
+
+		// FindAttribute returns the descriptor of the attribute with the given name or JSON
+		// field name, or nil if there is no such attribute.
+		func (d *TypeDescriptor) FindAttribute(name string) *AttributeDescriptor {
+			for _, attribute := range d.Attributes {
+				if attribute.Name == name || attribute.Field == name {
+					return attribute
+				}
+			}
+			return nil
+		}
+
+		// FindMethod returns the descriptor of the method with the given name, or nil if there
+		// is no such method.
+		func (d *ResourceDescriptor) FindMethod(name string) *MethodDescriptor {
+			for _, method := range d.Methods {
+				if method.Name == name {
+					return method
+				}
+			}
+			return nil
+		}
+	`)
+	return g.buffer.Write()
+}
+
+func (g *DescriptorsGenerator) generateModel() error {
+	err := g.createBuffer("model")
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+	g.buffer.EmitTemplate("model", `
+		// Model contains the descriptors of all the services of the model.
+		var Model = &ModelDescriptor{
+			Services: []*ServiceDescriptor{
+				{{ range .Model.Services }}
+					{
+						Name: {{ printf "%q" .Name.Snake }},
+						Versions: []*VersionDescriptor{
+							{{ range .Versions }}
+								{{ $version := . }}
+								{
+									Name: {{ printf "%q" .Name.Snake }},
+									Path: {{ printf "%q" (versionPath .) }},
+									Types: []*TypeDescriptor{
+										{{ range .Types }}
+											{
+												Name: {{ printf "%q" (typeName .) }},
+												Kind: {{ typeKind . }},
+												{{ with .Doc }}
+													Doc: {{ printf "%q" . }},
+												{{ end }}
+												{{ with .Element }}
+													Element: {{ printf "%q" (typeName .) }},
+												{{ end }}
+												{{ with .Index }}
+													Index: {{ printf "%q" (typeName .) }},
+												{{ end }}
+												{{ with .Values }}
+													Values: []string{
+														{{ range . }}
+															{{ printf "%q" (enumValueName .) }},
+														{{ end }}
+													},
+												{{ end }}
+												{{ with .Attributes }}
+													Attributes: []*AttributeDescriptor{
+														{{ range . }}
+															{
+																Name: {{ printf "%q" .Name.Camel }},
+																Field: {{ printf "%q" (attributeField .) }},
+																Type: {{ printf "%q" (typeName .Type) }},
+																{{ with .Doc }}
+																	Doc: {{ printf "%q" . }},
+																{{ end }}
+																Link: {{ .Link }},
+																ReadOnly: {{ .ReadOnly }},
+																Immutable: {{ .Immutable }},
+																{{ with .Since }}
+																	Since: {{ printf "%q" . }},
+																{{ end }}
+															},
+														{{ end }}
+													},
+												{{ end }}
+											},
+										{{ end }}
+									},
+									Resources: []*ResourceDescriptor{
+										{{ range .Resources }}
+											{
+												Name: {{ printf "%q" .Name.Camel }},
+												{{ with .Doc }}
+													Doc: {{ printf "%q" . }},
+												{{ end }}
+												{{ with .Methods }}
+													Methods: []*MethodDescriptor{
+														{{ range . }}
+															{
+																Name: {{ printf "%q" .Name.Camel }},
+																{{ with .Doc }}
+																	Doc: {{ printf "%q" . }},
+																{{ end }}
+																HTTPMethod: {{ printf "%q" (httpMethod .) }},
+																{{ with .Parameters }}
+																	Parameters: []*ParameterDescriptor{
+																		{{ range . }}
+																			{
+																				Name: {{ printf "%q" .Name.Camel }},
+																				Field: {{ printf "%q" (parameterField .) }},
+																				Type: {{ printf "%q" (typeName .Type) }},
+																				In: {{ .In }},
+																				Out: {{ .Out }},
+																			},
+																		{{ end }}
+																	},
+																{{ end }}
+																{{ with .Since }}
+																	Since: {{ printf "%q" . }},
+																{{ end }}
+															},
+														{{ end }}
+													},
+												{{ end }}
+												{{ with .Locators }}
+													Locators: []*LocatorDescriptor{
+														{{ range . }}
+															{
+																Name: {{ printf "%q" .Name.Camel }},
+																Segment: {{ printf "%q" .Name.Snake }},
+																Variable: {{ .Variable }},
+																Target: {{ printf "%q" .Target.Name.Camel }},
+															},
+														{{ end }}
+													},
+												{{ end }}
+											},
+										{{ end }}
+									},
+									Paths: []*PathDescriptor{
+										{{ range .Paths }}
+											{
+												Path: {{ printf "%q" (locatorPath $version .) }},
+												Resource: {{ printf "%q" (pathTarget .).Name.Camel }},
+											},
+										{{ end }}
+									},
+								},
+							{{ end }}
+						},
+					},
+				{{ end }}
+			},
+		}
+		`,
+		"Model", g.model,
+	)
+	return g.buffer.Write()
+}
+
+func (g *DescriptorsGenerator) typeKind(typ *concepts.Type) string {
+	switch {
+	case typ.IsBoolean():
+		return "BooleanKind"
+	case typ.IsInteger():
+		return "IntegerKind"
+	case typ.IsLong():
+		return "LongKind"
+	case typ.IsFloat():
+		return "FloatKind"
+	case typ.IsString():
+		return "StringKind"
+	case typ.IsDate():
+		return "DateKind"
+	case typ.IsInterface():
+		return "InterfaceKind"
+	case typ.IsEnum():
+		return "EnumKind"
+	case typ.IsList():
+		return "ListKind"
+	case typ.IsMap():
+		return "MapKind"
+	case typ.IsClass():
+		return "ClassKind"
+	case typ.IsStruct():
+		return "StructKind"
+	default:
+		g.reporter.Errorf("Don't know how to calculate kind for type '%s'", typ.Name())
+		g.errors++
+		return ""
+	}
+}
+
+func (g *DescriptorsGenerator) typeName(typ *concepts.Type) string {
+	return typ.Name().Camel()
+}
+
+func (g *DescriptorsGenerator) versionPath(version *concepts.Version) string {
+	return fmt.Sprintf(
+		"/api/%s/%s",
+		g.binding.ServiceSegment(version.Owner()),
+		g.binding.VersionSegment(version),
+	)
+}
+
+func (g *DescriptorsGenerator) locatorPath(version *concepts.Version,
+	path []*concepts.Locator) string {
+	segments := make([]string, len(path))
+	for i, locator := range path {
+		if locator.Variable() {
+			segments[i] = fmt.Sprintf("{%s_id}", g.binding.LocatorSegment(locator))
+		} else {
+			segments[i] = g.binding.LocatorSegment(locator)
+		}
+	}
+	return g.versionPath(version) + "/" + strings.Join(segments, "/")
+}
+
+func (g *DescriptorsGenerator) pathTarget(path []*concepts.Locator) *concepts.Resource {
+	return path[len(path)-1].Target()
+}
@@ -28,22 +28,30 @@ import (
 // ErrorsGeneratorBuilder is an object used to configure and build an errors generator. Don't create
 // instances directly, use the NewErrorsGenerator function instead.
 type ErrorsGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
 }
 
 // ErrorsGenerator generates errors code. Don't create instances directly, use the builder instead.
 type ErrorsGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	buffer   *Buffer
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
 }
 
 // NewErrorsGenerator creates a new builder for errors generators.
@@ -82,6 +90,35 @@ func (b *ErrorsGeneratorBuilder) Names(value *NamesCalculator) *ErrorsGeneratorB
 	return b
 }
 
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *ErrorsGeneratorBuilder) Header(value *Header) *ErrorsGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *ErrorsGeneratorBuilder) Stream(value bool) *ErrorsGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *ErrorsGeneratorBuilder) MaxLines(value int) *ErrorsGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *ErrorsGeneratorBuilder) Templates(value *Templates) *ErrorsGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new errors
 // generator using it.
 func (b *ErrorsGeneratorBuilder) Build() (generator *ErrorsGenerator, err error) {
@@ -109,11 +146,15 @@ func (b *ErrorsGeneratorBuilder) Build() (generator *ErrorsGenerator, err error)
 
 	// Create the generator:
 	generator = &ErrorsGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
 	}
 
 	return
@@ -132,6 +173,7 @@ func (g *ErrorsGenerator) Run() error {
 	// Generate the clients for the versions:
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating errors for version '%s'", version)
 			err = g.generateVersionErrors(version)
 			if err != nil {
 				return err
@@ -164,19 +206,29 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("errors").
 		Package(pkgName).
 		File(fileName).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
+	g.buffer.Import("fmt", "")
 	g.buffer.Import("io", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("strconv", "")
 	g.buffer.Import("github.com/golang/glog", "")
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
 	g.buffer.Import("github.com/openshift-online/ocm-api-metamodel/pkg/runtime", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("common_errors", `
 		// Error kind is the name of the type used to represent errors.
 		const ErrorKind = "Error"
 
@@ -185,18 +237,20 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 
 		// ErrorBuilder is a builder for the error type.
 		type ErrorBuilder struct{
-			id     *string
-			href   *string
-			code   *string
-			reason *string
+			id          *string
+			href        *string
+			code        *string
+			reason      *string
+			operationID *string
 		}
 
 		// Error represents errors.
 		type Error struct {
-			id     *string
-			href   *string
-			code   *string
-			reason *string
+			id          *string
+			href        *string
+			code        *string
+			reason      *string
+			operationID *string
 		}
 
 		// NewError returns a new ErrorBuilder
@@ -228,6 +282,12 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 			return e
 		}
 
+		// OperationID sets the identifier of the operation that caused the error.
+		func (e *ErrorBuilder) OperationID(operationID string) *ErrorBuilder {
+			e.operationID = &operationID
+			return e
+		}
+
 		// Build builds a new error type or returns an error.
 		func (e *ErrorBuilder) Build() (*Error, error) {
 			err := new(Error)
@@ -235,6 +295,7 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 			err.code = e.code
 			err.id = e.id
 			err.href = e.href
+			err.operationID = e.operationID
 			return err, nil
 		}
 
@@ -318,6 +379,26 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 			return
 		}
 
+		// OperationID returns the identifier of the operation that caused the error. This
+		// is the value that should be used to find the details of the error in the logs of
+		// the server.
+		func (e *Error) OperationID() string {
+			if e != nil && e.operationID != nil {
+				return *e.operationID
+			}
+			return ""
+		}
+
+		// GetOperationID returns the identifier of the operation that caused the error and
+		// a flag indicating if the identifier has a value.
+		func (e *Error) GetOperationID() (value string, ok bool) {
+			ok = e != nil && e.operationID != nil
+			if ok {
+				value = *e.operationID
+			}
+			return
+		}
+
 		// Error is the implementation of the error interface.
 		func (e *Error) Error() string {
 			if e.reason != nil {
@@ -364,6 +445,9 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 				case "reason":
 					value := iterator.ReadString()
 					object.reason = &value
+				case "operation_id":
+					value := iterator.ReadString()
+					object.operationID = &value
 				default:
 					iterator.ReadAny()
 				}
@@ -373,10 +457,9 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 
 		// MarshalError writes an error to the given writer.
 		func MarshalError(e *Error, writer io.Writer) error {
-			stream := helpers.NewStream(writer)
+			stream := helpers.BorrowStream(writer)
 			writeError(e, stream)
-			stream.Flush()
-			return stream.Error
+			return helpers.ReturnStream(stream)
 		}
 
 		func writeError(e *Error, stream *jsoniter.Stream) {
@@ -403,6 +486,11 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 				stream.WriteObjectField("reason")
 				stream.WriteString(*e.reason)
 			}
+			if e.operationID != nil {
+				stream.WriteMore()
+				stream.WriteObjectField("operation_id")
+				stream.WriteString(*e.operationID)
+			}
 			stream.WriteObjectEnd()
 		}
 
@@ -422,6 +510,14 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 				SendPanic(w, r)
 				return
 			}
+			if object.operationID == nil {
+				operationID := helpers.OperationIDFromContext(r.Context())
+				if operationID != "" {
+					copy := *object
+					copy.operationID = &operationID
+					object = &copy
+				}
+			}
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(status)
 			err = MarshalError(object, w)
@@ -462,7 +558,41 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 			SendError(w, r, body)
 		}
 
-		// SendMethodNotAllowed sends a generic 405 error.
+		// SendBadRequest sends a 400 error with the given reason.
+		func SendBadRequest(w http.ResponseWriter, r *http.Request, reason string) {
+			body, err := NewError().
+				ID("400").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendForbidden sends a 403 error with the given reason. If the reason is empty a
+		// generic one will be used.
+		func SendForbidden(w http.ResponseWriter, r *http.Request, reason string) {
+			if reason == "" {
+				reason = fmt.Sprintf(
+					"Method '%s' isn't allowed for path '%s'",
+					r.Method, r.URL.Path,
+				)
+			}
+			body, err := NewError().
+				ID("403").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendMethodNotAllowed sends a generic 405 error. The caller is responsible for
+		// setting the 'Allow' header with the list of methods supported by the path.
 		func SendMethodNotAllowed(w http.ResponseWriter, r *http.Request) {
 			reason := fmt.Sprintf(
 				"Method '%s' isn't supported for path '%s''",
@@ -479,6 +609,107 @@ func (g *ErrorsGenerator) generateCommonErrors() error {
 			SendError(w, r, body)
 		}
 
+		// SendNotAcceptable sends a generic 406 error. It is used when the client doesn't
+		// accept any of the media types that the server can use for the response body.
+		func SendNotAcceptable(w http.ResponseWriter, r *http.Request) {
+			reason := fmt.Sprintf(
+				"Can't produce any of the media types accepted for path '%s'",
+				r.URL.Path,
+			)
+			body, err := NewError().
+				ID("406").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendConflict sends a 409 error with the given reason.
+		func SendConflict(w http.ResponseWriter, r *http.Request, reason string) {
+			body, err := NewError().
+				ID("409").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendRequestEntityTooLarge sends a generic 413 error.
+		func SendRequestEntityTooLarge(w http.ResponseWriter, r *http.Request) {
+			reason := fmt.Sprintf(
+				"Body of '%s' request for path '%s' is too large",
+				r.Method, r.URL.Path,
+			)
+			body, err := NewError().
+				ID("413").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendClientClosedRequest sends a generic 499 error. The client will usually not
+		// receive it, but it is sent so that it appears in the logs and metrics.
+		func SendClientClosedRequest(w http.ResponseWriter, r *http.Request) {
+			reason := fmt.Sprintf(
+				"Client closed '%s' request for path '%s' before the response was sent",
+				r.Method, r.URL.Path,
+			)
+			body, err := NewError().
+				ID("499").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendGatewayTimeout sends a generic 504 error.
+		func SendGatewayTimeout(w http.ResponseWriter, r *http.Request) {
+			reason := fmt.Sprintf(
+				"Can't process '%s' request for path '%s' because the deadline was "+
+					"exceeded",
+				r.Method, r.URL.Path,
+			)
+			body, err := NewError().
+				ID("504").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
+		// SendNotImplemented sends a generic 501 error.
+		func SendNotImplemented(w http.ResponseWriter, r *http.Request) {
+			reason := fmt.Sprintf(
+				"Method '%s' for path '%s' isn't implemented",
+				r.Method, r.URL.Path,
+			)
+			body, err := NewError().
+				ID("501").
+				Reason(reason).
+				Build()
+			if err != nil {
+				SendPanic(w, r)
+				return
+			}
+			SendError(w, r, body)
+		}
+
 		// SendInternalServerError sends a generic 500 error.
 		func SendInternalServerError(w http.ResponseWriter, r *http.Request) {
 			reason := fmt.Sprintf(
@@ -514,6 +745,11 @@ func (g *ErrorsGenerator) generateVersionErrors(version *concepts.Version) error
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("errors").
 		Package(pkgName).
 		File(fileName).
 		Function("errorName", g.errorName).
@@ -521,6 +757,7 @@ func (g *ErrorsGenerator) generateVersionErrors(version *concepts.Version) error
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	err = g.generateVersionErrorsSource(version)
@@ -533,7 +770,7 @@ func (g *ErrorsGenerator) generateVersionErrors(version *concepts.Version) error
 }
 
 func (g *ErrorsGenerator) generateVersionErrorsSource(version *concepts.Version) error {
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("version_errors", `
 		{{ if .Version.Errors }}
 			const (
 				{{ range .Version.Errors }}
@@ -0,0 +1,578 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the generator that creates the publishers and subscribers of the events of
+// the model.
+
+package golang
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// EventsGeneratorBuilder is an object used to configure and build the events generator. Don't
+// create instances directly, use the NewEventsGenerator function instead.
+type EventsGeneratorBuilder struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	cbor      bool
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+}
+
+// EventsGenerator generates the publishers and subscribers of events. Don't create instances
+// directly, use the builder instead.
+type EventsGenerator struct {
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	cbor      bool
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
+}
+
+// NewEventsGenerator creates a new builder for events generators.
+func NewEventsGenerator() *EventsGeneratorBuilder {
+	return new(EventsGeneratorBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the generation process,
+// including errors.
+func (b *EventsGeneratorBuilder) Reporter(value *reporter.Reporter) *EventsGeneratorBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be used by the events generator.
+func (b *EventsGeneratorBuilder) Model(value *concepts.Model) *EventsGeneratorBuilder {
+	b.model = value
+	return b
+}
+
+// Output sets the output directory.
+func (b *EventsGeneratorBuilder) Output(value string) *EventsGeneratorBuilder {
+	b.output = value
+	return b
+}
+
+// Packages sets the object that will be used to calculate package names.
+func (b *EventsGeneratorBuilder) Packages(value *PackagesCalculator) *EventsGeneratorBuilder {
+	b.packages = value
+	return b
+}
+
+// Names sets the object that will be used to calculate names.
+func (b *EventsGeneratorBuilder) Names(value *NamesCalculator) *EventsGeneratorBuilder {
+	b.names = value
+	return b
+}
+
+// Types sets the object that will be used to calculate types.
+func (b *EventsGeneratorBuilder) Types(value *TypesCalculator) *EventsGeneratorBuilder {
+	b.types = value
+	return b
+}
+
+// CBOR sets the flag that indicates if the publishers and subscribers should support the CBOR
+// encoding in addition to JSON. This requires the code generated by the CBOR support generator.
+func (b *EventsGeneratorBuilder) CBOR(value bool) *EventsGeneratorBuilder {
+	b.cbor = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *EventsGeneratorBuilder) Header(value *Header) *EventsGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *EventsGeneratorBuilder) Stream(value bool) *EventsGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *EventsGeneratorBuilder) MaxLines(value int) *EventsGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *EventsGeneratorBuilder) Templates(value *Templates) *EventsGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// events generator using it.
+func (b *EventsGeneratorBuilder) Build() (generator *EventsGenerator, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output path is mandatory")
+		return
+	}
+	if b.packages == nil {
+		err = fmt.Errorf("packages calculator is mandatory")
+		return
+	}
+	if b.names == nil {
+		err = fmt.Errorf("names calculator is mandatory")
+		return
+	}
+	if b.types == nil {
+		err = fmt.Errorf("types calculator is mandatory")
+		return
+	}
+
+	// Create the generator:
+	generator = &EventsGenerator{
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		types:     b.types,
+		cbor:      b.cbor,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+	}
+
+	return
+}
+
+// Run executes the code generator.
+func (g *EventsGenerator) Run() error {
+	var err error
+
+	// Generate the publishers and subscribers for the versions that have events:
+	for _, service := range g.model.Services() {
+		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating events for version '%s'", version)
+			if len(version.Events()) == 0 {
+				continue
+			}
+			err = g.generateVersionEvents(version)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	// Check if there were errors:
+	if g.errors > 0 {
+		if g.errors > 1 {
+			err = fmt.Errorf("there were %d errors", g.errors)
+		} else {
+			err = fmt.Errorf("there was 1 error")
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (g *EventsGenerator) generateVersionEvents(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.eventsFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("events").
+		Package(pkgName).
+		File(fileName).
+		Function("structName", g.types.StructName).
+		Function("publishMethod", g.publishMethod).
+		Function("publishActionMethod", g.publishActionMethod).
+		Function("marshalFunc", g.marshalFunc).
+		Function("marshalCBORFunc", g.marshalCBORFunc).
+		Function("unmarshalCBORFunc", g.unmarshalCBORFunc).
+		Function("createdMethod", g.createdMethod).
+		Function("updatedMethod", g.updatedMethod).
+		Function("deletedMethod", g.deletedMethod).
+		Function("handleEventMethod", g.handleEventMethod).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generatePublisherSource(version)
+	g.generateSubscriberSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *EventsGenerator) generatePublisherSource(version *concepts.Version) {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("time", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("publisher", `
+		// EventPublisherBuilder contains the configuration used to create an event publisher.
+		// Don't create instances directly, use the NewEventPublisher function instead.
+		type EventPublisherBuilder struct {
+			broker      helpers.Broker
+			topic       string
+			contentType string
+		}
+
+		// EventPublisher sends the events of this version to a message broker. Don't create
+		// instances directly, use the builder instead.
+		type EventPublisher struct {
+			broker      helpers.Broker
+			topic       string
+			contentType string
+		}
+
+		// NewEventPublisher creates a builder that can then be used to configure and create an
+		// event publisher.
+		func NewEventPublisher() *EventPublisherBuilder {
+			return &EventPublisherBuilder{
+				contentType: "application/json",
+			}
+		}
+
+		// Broker sets the broker that will be used to send the events. This is mandatory.
+		func (b *EventPublisherBuilder) Broker(value helpers.Broker) *EventPublisherBuilder {
+			b.broker = value
+			return b
+		}
+
+		// Topic sets the topic where the events will be sent. This is mandatory.
+		func (b *EventPublisherBuilder) Topic(value string) *EventPublisherBuilder {
+			b.topic = value
+			return b
+		}
+
+		// ContentType sets the media type used to serialize the events. The default is
+		// 'application/json'.
+		{{ if .CBOR }}
+			// The 'application/cbor' media type is also supported.
+		{{ end }}
+		func (b *EventPublisherBuilder) ContentType(value string) *EventPublisherBuilder {
+			b.contentType = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new event publisher.
+		func (b *EventPublisherBuilder) Build() (publisher *EventPublisher, err error) {
+			if b.broker == nil {
+				err = fmt.Errorf("broker is mandatory")
+				return
+			}
+			if b.topic == "" {
+				err = fmt.Errorf("topic is mandatory")
+				return
+			}
+			switch b.contentType {
+			{{ if .CBOR }}
+				case "application/json", "application/cbor":
+			{{ else }}
+				case "application/json":
+			{{ end }}
+			default:
+				err = fmt.Errorf("content type '%s' isn't supported", b.contentType)
+				return
+			}
+			publisher = &EventPublisher{
+				broker:      b.broker,
+				topic:       b.topic,
+				contentType: b.contentType,
+			}
+			return
+		}
+
+		{{ range .Version.Events }}
+			{{ $payloadName := structName .Payload }}
+			{{ $objectName := structName .Object }}
+			{{ $publishMethod := publishMethod . }}
+			{{ $publishActionMethod := publishActionMethod . }}
+
+			// {{ $publishMethod }} sends the given event to the broker.
+			func (p *EventPublisher) {{ $publishMethod }}(ctx context.Context,
+				event *{{ $payloadName }}) error {
+				buffer := &bytes.Buffer{}
+				var err error
+				switch p.contentType {
+				{{ if $.CBOR }}
+					case "application/cbor":
+						err = {{ marshalCBORFunc .Payload }}(event, buffer)
+				{{ end }}
+				default:
+					err = {{ marshalFunc .Payload }}(event, buffer)
+				}
+				if err != nil {
+					return err
+				}
+				var key string
+				{{ if .Object.IsClass }}
+					if after, ok := event.GetAfter(); ok {
+						key = after.ID()
+					} else if before, ok := event.GetBefore(); ok {
+						key = before.ID()
+					}
+				{{ end }}
+				return p.broker.Publish(ctx, &helpers.Message{
+					Topic:       p.topic,
+					Key:         key,
+					ContentType: p.contentType,
+					Kind:        {{ $payloadName }}Kind,
+					Body:        buffer.Bytes(),
+				})
+			}
+
+			// {{ createdMethod . }} sends an event indicating that the given object has been
+			// created.
+			func (p *EventPublisher) {{ createdMethod . }}(ctx context.Context,
+				object *{{ $objectName }}) error {
+				return p.{{ $publishActionMethod }}(ctx, EventActionCreate, nil, object)
+			}
+
+			// {{ updatedMethod . }} sends an event indicating that the given object has been
+			// updated.
+			func (p *EventPublisher) {{ updatedMethod . }}(ctx context.Context,
+				before, after *{{ $objectName }}) error {
+				return p.{{ $publishActionMethod }}(ctx, EventActionUpdate, before, after)
+			}
+
+			// {{ deletedMethod . }} sends an event indicating that the given object has been
+			// deleted.
+			func (p *EventPublisher) {{ deletedMethod . }}(ctx context.Context,
+				object *{{ $objectName }}) error {
+				return p.{{ $publishActionMethod }}(ctx, EventActionDelete, object, nil)
+			}
+
+			func (p *EventPublisher) {{ $publishActionMethod }}(ctx context.Context,
+				action EventAction, before, after *{{ $objectName }}) error {
+				builder := New{{ $payloadName }}().
+					Action(action).
+					Timestamp(time.Now())
+				if before != nil {
+					builder.Before(New{{ $objectName }}().Copy(before))
+				}
+				if after != nil {
+					builder.After(New{{ $objectName }}().Copy(after))
+				}
+				{{ if .Object.IsClass }}
+					if after != nil && after.HREF() != "" {
+						builder.Resource(after.HREF())
+					} else if before != nil && before.HREF() != "" {
+						builder.Resource(before.HREF())
+					}
+				{{ end }}
+				event, err := builder.Build()
+				if err != nil {
+					return err
+				}
+				return p.{{ $publishMethod }}(ctx, event)
+			}
+		{{ end }}
+		`,
+		"Version", version,
+		"CBOR", g.cbor,
+	)
+}
+
+func (g *EventsGenerator) generateSubscriberSource(version *concepts.Version) {
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("subscriber", `
+		// EventSubscriberBuilder contains the configuration used to create an event
+		// subscriber. Don't create instances directly, use the NewEventSubscriber function
+		// instead.
+		type EventSubscriberBuilder struct {
+			broker  helpers.Broker
+			topic   string
+			handler EventHandler
+		}
+
+		// EventSubscriber receives the events of this version from a message broker and
+		// passes them to a handler. Don't create instances directly, use the builder instead.
+		type EventSubscriber struct {
+			broker  helpers.Broker
+			topic   string
+			handler EventHandler
+		}
+
+		// NewEventSubscriber creates a builder that can then be used to configure and create
+		// an event subscriber.
+		func NewEventSubscriber() *EventSubscriberBuilder {
+			return &EventSubscriberBuilder{}
+		}
+
+		// Broker sets the broker that will be used to receive the events. This is mandatory.
+		func (b *EventSubscriberBuilder) Broker(value helpers.Broker) *EventSubscriberBuilder {
+			b.broker = value
+			return b
+		}
+
+		// Topic sets the topic where the events will be received from. This is mandatory.
+		func (b *EventSubscriberBuilder) Topic(value string) *EventSubscriberBuilder {
+			b.topic = value
+			return b
+		}
+
+		// Handler sets the object that will process the events. This is mandatory.
+		func (b *EventSubscriberBuilder) Handler(value EventHandler) *EventSubscriberBuilder {
+			b.handler = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new event
+		// subscriber.
+		func (b *EventSubscriberBuilder) Build() (subscriber *EventSubscriber, err error) {
+			if b.broker == nil {
+				err = fmt.Errorf("broker is mandatory")
+				return
+			}
+			if b.topic == "" {
+				err = fmt.Errorf("topic is mandatory")
+				return
+			}
+			if b.handler == nil {
+				err = fmt.Errorf("handler is mandatory")
+				return
+			}
+			subscriber = &EventSubscriber{
+				broker:  b.broker,
+				topic:   b.topic,
+				handler: b.handler,
+			}
+			return
+		}
+
+		// Start subscribes to the topic. The events received will be passed to the handler
+		// till the context is cancelled.
+		func (s *EventSubscriber) Start(ctx context.Context) error {
+			return s.broker.Subscribe(ctx, s.topic, s.handleMessage)
+		}
+
+		func (s *EventSubscriber) handleMessage(ctx context.Context,
+			message *helpers.Message) error {
+			switch message.ContentType {
+			{{ if .CBOR }}
+				case "application/cbor":
+					switch message.Kind {
+					{{ range .Version.Events }}
+						{{ $payloadName := structName .Payload }}
+						case {{ $payloadName }}Kind:
+							event, err := {{ unmarshalCBORFunc .Payload }}(message.Body)
+							if err != nil {
+								return err
+							}
+							return s.handler.{{ handleEventMethod . }}(ctx, event)
+					{{ end }}
+					}
+					return fmt.Errorf("message of kind '%s' isn't an event", message.Kind)
+			{{ end }}
+			case "", "application/json":
+				return DispatchEvent(ctx, s.handler, message.Body)
+			}
+			return fmt.Errorf("content type '%s' isn't supported", message.ContentType)
+		}
+		`,
+		"Version", version,
+		"CBOR", g.cbor,
+	)
+}
+
+func (g *EventsGenerator) eventsFile() string {
+	return g.names.File(names.Cat(nomenclator.Event, nomenclator.Broker))
+}
+
+func (g *EventsGenerator) publishMethod(event *concepts.Event) string {
+	return g.names.Public(names.Cat(nomenclator.Publish, event.Name()))
+}
+
+func (g *EventsGenerator) publishActionMethod(event *concepts.Event) string {
+	return g.names.Private(names.Cat(nomenclator.Publish, event.Name(), nomenclator.Action))
+}
+
+func (g *EventsGenerator) marshalFunc(typ *concepts.Type) string {
+	return g.names.Public(names.Cat(nomenclator.Marshal, typ.Name()))
+}
+
+func (g *EventsGenerator) marshalCBORFunc(typ *concepts.Type) string {
+	return g.names.Public(names.Cat(nomenclator.Marshal, typ.Name(), nomenclator.CBOR))
+}
+
+func (g *EventsGenerator) unmarshalCBORFunc(typ *concepts.Type) string {
+	return g.names.Public(names.Cat(nomenclator.Unmarshal, typ.Name(), nomenclator.CBOR))
+}
+
+func (g *EventsGenerator) createdMethod(event *concepts.Event) string {
+	return g.names.Public(names.Cat(event.Object().Name(), nomenclator.Created))
+}
+
+func (g *EventsGenerator) updatedMethod(event *concepts.Event) string {
+	return g.names.Public(names.Cat(event.Object().Name(), nomenclator.Updated))
+}
+
+func (g *EventsGenerator) deletedMethod(event *concepts.Event) string {
+	return g.names.Public(names.Cat(event.Object().Name(), nomenclator.Deleted))
+}
+
+func (g *EventsGenerator) handleEventMethod(event *concepts.Event) string {
+	return g.names.Public(names.Cat(nomenclator.Handle, event.Name()))
+}
@@ -0,0 +1,199 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the header that is added to the generated files.
+
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// HeaderBuilder is used to create the header that is added to the generated files. Don't create
+// instances directly, use the NewHeader function instead.
+type HeaderBuilder struct {
+	copyright string
+	license   string
+	generator string
+	marker    string
+	tags      []string
+}
+
+// Header contains the text and the build tags that are added to the beginning of the generated
+// files. Don't create instances directly, use the builder instead.
+type Header struct {
+	copyright string
+	license   string
+	generator string
+	marker    string
+	tags      []string
+}
+
+// NewHeader creates a builder for headers. The builder is initialized with the default copyright
+// and license.
+func NewHeader() *HeaderBuilder {
+	return &HeaderBuilder{
+		copyright: defaultCopyright,
+		license:   defaultLicense,
+		marker:    "bottom",
+	}
+}
+
+// Copyright sets the copyright notice, for example 'Copyright (c) 2019 Red Hat, Inc.'. The default
+// is the Red Hat copyright. If the value is empty the copyright notice will not be added.
+func (b *HeaderBuilder) Copyright(value string) *HeaderBuilder {
+	b.copyright = value
+	return b
+}
+
+// License sets the text of the license. The default is the Apache 2 license. If the value is empty
+// the license will not be added.
+func (b *HeaderBuilder) License(value string) *HeaderBuilder {
+	b.license = value
+	return b
+}
+
+// Generator sets the name of the generator that will be used in the standard
+// 'Code generated by ... DO NOT EDIT.' marker. If the value is empty, which is the default, a
+// generic warning will be added instead of the marker.
+func (b *HeaderBuilder) Generator(value string) *HeaderBuilder {
+	b.generator = value
+	return b
+}
+
+// Marker sets the placement of the marker that indicates that the file has been generated. It can
+// be 'top', to put it in the first line of the file, or 'bottom', to put it after the copyright and
+// license. The default is 'bottom'.
+func (b *HeaderBuilder) Marker(value string) *HeaderBuilder {
+	b.marker = value
+	return b
+}
+
+// Tags sets the build tags that will be required by all the generated files. These are combined
+// with the build constraints of the individual files, if any.
+func (b *HeaderBuilder) Tags(values ...string) *HeaderBuilder {
+	b.tags = append(b.tags, values...)
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new header
+// using it.
+func (b *HeaderBuilder) Build() (header *Header, err error) {
+	// Check the parameters:
+	switch b.marker {
+	case "top", "bottom":
+	default:
+		err = fmt.Errorf(
+			"marker placement '%s' isn't valid, should be 'top' or 'bottom'",
+			b.marker,
+		)
+		return
+	}
+	for _, tag := range b.tags {
+		if !headerTagRE.MatchString(tag) {
+			err = fmt.Errorf(
+				"build tag '%s' isn't valid, should contain only letters, digits, "+
+					"underscores and dots",
+				tag,
+			)
+			return
+		}
+	}
+
+	// Create the header:
+	header = &Header{
+		copyright: b.copyright,
+		license:   b.license,
+		generator: b.generator,
+		marker:    b.marker,
+	}
+	if len(b.tags) > 0 {
+		header.tags = make([]string, len(b.tags))
+		copy(header.tags, b.tags)
+	}
+
+	return
+}
+
+// Text returns the text of the header, without the build constraints.
+func (h *Header) Text() string {
+	buffer := new(bytes.Buffer)
+
+	// Calculate the marker:
+	var marker string
+	if h.generator != "" {
+		marker = fmt.Sprintf("// Code generated by %s. DO NOT EDIT.\n", h.generator)
+	} else {
+		marker = defaultMarker
+	}
+
+	// Write the marker, the copyright and the license:
+	if h.marker == "top" {
+		fmt.Fprintf(buffer, "%s\n", marker)
+	}
+	var sections []string
+	if h.copyright != "" {
+		sections = append(sections, strings.TrimSpace(h.copyright))
+	}
+	if h.license != "" {
+		sections = append(sections, strings.TrimSpace(h.license))
+	}
+	if len(sections) > 0 {
+		fmt.Fprintf(buffer, "/*\n%s\n*/\n\n", strings.Join(sections, "\n\n"))
+	}
+	if h.marker == "bottom" {
+		fmt.Fprintf(buffer, "%s", marker)
+	}
+
+	return buffer.String()
+}
+
+// Tags returns the build tags that are required by all the generated files.
+func (h *Header) Tags() []string {
+	return h.tags
+}
+
+// headerTagRE is the regular expression used to check build tags.
+var headerTagRE = regexp.MustCompile(`^[A-Za-z0-9_.]+$`)
+
+// defaultHeader is the header used when the buffer isn't explicitly configured with a different
+// one.
+var defaultHeader = &Header{
+	copyright: defaultCopyright,
+	license:   defaultLicense,
+	marker:    "bottom",
+}
+
+const defaultCopyright = `Copyright (c) 2019 Red Hat, Inc.`
+
+const defaultLicense = `Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.`
+
+const defaultMarker = `// IMPORTANT: This file has been generated automatically, refrain from modifying it manually as all
+// your changes will be lost when the file is generated again.
+`
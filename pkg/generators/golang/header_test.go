@@ -0,0 +1,129 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the header of the generated files.
+
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Header", func() {
+	It("Uses the Red Hat copyright and Apache license by default", func() {
+		header, err := NewHeader().Build()
+		Expect(err).ToNot(HaveOccurred())
+		text := header.Text()
+		Expect(text).To(HavePrefix("/*\nCopyright (c) 2019 Red Hat, Inc.\n\nLicensed under"))
+		Expect(text).To(HaveSuffix("*/\n\n" + defaultMarker))
+	})
+
+	It("Puts the marker in the first line", func() {
+		header, err := NewHeader().
+			Generator("mygen").
+			Marker("top").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(header.Text()).To(HavePrefix("// Code generated by mygen. DO NOT EDIT.\n\n/*\n"))
+	})
+
+	It("Replaces the copyright and omits the license", func() {
+		header, err := NewHeader().
+			Copyright("Copyright (c) 2020 My Company").
+			License("").
+			Generator("mygen").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(header.Text()).To(Equal(
+			"/*\nCopyright (c) 2020 My Company\n*/\n\n" +
+				"// Code generated by mygen. DO NOT EDIT.\n",
+		))
+	})
+
+	It("Omits the comment when there is no copyright or license", func() {
+		header, err := NewHeader().
+			Copyright("").
+			License("").
+			Generator("mygen").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(header.Text()).To(Equal("// Code generated by mygen. DO NOT EDIT.\n"))
+	})
+
+	It("Rejects invalid marker placement", func() {
+		_, err := NewHeader().
+			Marker("middle").
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("middle"))
+	})
+
+	It("Rejects invalid build tags", func() {
+		_, err := NewHeader().
+			Tags("good", "not good").
+			Build()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("not good"))
+	})
+
+	It("Combines build tags with the constraint of the file", func() {
+		// Create a temporary directory for the generated code:
+		tmp, err := ioutil.TempDir("", "header-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+
+		// Generate the file:
+		header, err := NewHeader().
+			Tags("custom").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		reporter := reporter.NewReporter()
+		packages, err := NewPackagesCalculator().
+			Reporter(reporter).
+			Base("example.com/generated").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer, err := NewBuffer().
+			Reporter(reporter).
+			Output(tmp).
+			Packages(packages).
+			Package("mypkg").
+			File("example").
+			Header(header).
+			Constraint("go1.18").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer.Emit(`
+			var value = 42
+		`)
+		err = buffer.Write()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Check the result:
+		data, err := ioutil.ReadFile(filepath.Join(tmp, "mypkg", "example.go"))
+		Expect(err).ToNot(HaveOccurred())
+		code := string(data)
+		Expect(code).To(ContainSubstring("//go:build custom && go1.18\n"))
+		Expect(code).To(ContainSubstring("// +build custom,go1.18\n"))
+	})
+})
@@ -18,8 +18,10 @@ package golang
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
 )
@@ -27,22 +29,34 @@ import (
 // HelpersGeneratorBuilder is an object used to configure and build a helpers generator. Don't
 // create instances directly, use the NewHelpersGenerator function instead.
 type HelpersGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	binding   *http.BindingCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	generics  bool
 }
 
 // HelpersGenerator generates helper code. Don't create instances directly, use the builder instead.
 type HelpersGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	buffer   *Buffer
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	binding   *http.BindingCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	generics  bool
+	buffer    *Buffer
 }
 
 // NewHelpersGenerator creates a new builder for helpers generators.
@@ -82,6 +96,49 @@ func (b *HelpersGeneratorBuilder) Names(value *NamesCalculator) *HelpersGenerato
 	return b
 }
 
+// Binding sets the object that will by used to do HTTP binding calculations.
+func (b *HelpersGeneratorBuilder) Binding(value *http.BindingCalculator) *HelpersGeneratorBuilder {
+	b.binding = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *HelpersGeneratorBuilder) Header(value *Header) *HelpersGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *HelpersGeneratorBuilder) Stream(value bool) *HelpersGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *HelpersGeneratorBuilder) MaxLines(value int) *HelpersGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *HelpersGeneratorBuilder) Templates(value *Templates) *HelpersGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// Generics sets the flag that indicates if the generic functions used by the rest of the generated
+// code to avoid repeating the same logic for each type should be generated. Those functions need
+// Go 1.18 or newer. The default is to not generate them.
+func (b *HelpersGeneratorBuilder) Generics(value bool) *HelpersGeneratorBuilder {
+	b.generics = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new client
 // generator using it.
 func (b *HelpersGeneratorBuilder) Build() (generator *HelpersGenerator, err error) {
@@ -106,14 +163,24 @@ func (b *HelpersGeneratorBuilder) Build() (generator *HelpersGenerator, err erro
 		err = fmt.Errorf("names calculator is mandatory")
 		return
 	}
+	if b.binding == nil {
+		err = fmt.Errorf("binding calculator is mandatory")
+		return
+	}
 
 	// Create the generator:
 	generator = &HelpersGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		binding:   b.binding,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+		generics:  b.generics,
 	}
 
 	return
@@ -123,6 +190,14 @@ func (b *HelpersGeneratorBuilder) Build() (generator *HelpersGenerator, err erro
 func (g *HelpersGenerator) Run() error {
 	var err error
 
+	// Generate the generic functions:
+	if g.generics {
+		err = g.generateGenericsFile()
+		if err != nil {
+			return err
+		}
+	}
+
 	// Calculate the package and file name:
 	pkgName := g.packages.HelpersPackage()
 	fileName := g.helpersFile()
@@ -132,21 +207,52 @@ func (g *HelpersGenerator) Run() error {
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("helpers").
 		Package(pkgName).
 		File(fileName).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
+	g.generateCommonSource()
+	g.generateNegotiationSource()
+	g.generateBreakerSource()
+	g.generateLimitSource()
+	g.generateGzipSource()
+	g.generateTransportSource()
+	g.generateTokenSource()
+	g.generateRateLimitSource()
+	g.generateValidationSource()
+	g.generateCassetteSource()
+	g.generateDumpSource()
+	g.generateCurlSource()
+	g.generateBrokerSource()
+	g.generateAuditSource()
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *HelpersGenerator) generateCommonSource() {
 	g.buffer.Import("context", "")
+	g.buffer.Import("crypto/rand", "")
+	g.buffer.Import("encoding/hex", "")
+	g.buffer.Import("errors", "")
 	g.buffer.Import("fmt", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import("net/url", "")
+	g.buffer.Import("strconv", "")
 	g.buffer.Import("strings", "")
+	g.buffer.Import("sync", "")
 	g.buffer.Import("time", "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("common", `
 		// AddValue creates the given set of query parameters if needed, an then adds
 		// the given parameter.
 		func AddValue(query *url.Values, name string, value interface{}) {
@@ -188,6 +294,18 @@ func (g *HelpersGenerator) Run() error {
 			return result
 		}
 
+		// CopyHeader creates a copy of the given set of headers.
+		func CopyHeader(header http.Header) http.Header {
+			if header == nil {
+				return nil
+			}
+			result := make(http.Header)
+			for name, values := range header {
+				result[name] = CopyValues(values)
+			}
+			return result
+		}
+
 		// CopyValues copies a slice of strings.
 		func CopyValues(values []string) []string {
 			if values == nil {
@@ -209,6 +327,336 @@ func (g *HelpersGenerator) Run() error {
 			return strings.Split(path, "/")
 		}
 
+		// Dispatcher is a function that sends a request to the server that manages a resource,
+		// or to one of its sub-resources. The segments are the path segments relative to the
+		// resource.
+		type Dispatcher func(w http.ResponseWriter, r *http.Request, segments []string)
+
+		// DiscardBody returns a response writer that sends the status code and the headers
+		// to the given writer, but discards the body. It is used to answer HEAD requests
+		// with the same code that answers GET requests.
+		func DiscardBody(w http.ResponseWriter) http.ResponseWriter {
+			return &discardWriter{
+				ResponseWriter: w,
+			}
+		}
+
+		type discardWriter struct {
+			http.ResponseWriter
+		}
+
+		func (w *discardWriter) Write(data []byte) (int, error) {
+			return len(data), nil
+		}
+
+		// Interceptor is a function that the generated adapters call after reading a request
+		// and before calling the server. The request and response parameters are the server
+		// request and response objects of the method. The interceptor should call the next
+		// function to continue processing the request, or return an error to stop it.
+		type Interceptor func(
+			ctx context.Context,
+			request interface{},
+			response interface{},
+			next func(ctx context.Context) error,
+		) error
+
+		// interceptorsKey is the key used to store the interceptors in the context.
+		type interceptorsKey struct{}
+
+		// WithInterceptors returns a new context that contains the given interceptors, so
+		// that they are called by the generated adapters.
+		func WithInterceptors(ctx context.Context, interceptors []Interceptor) context.Context {
+			return context.WithValue(ctx, interceptorsKey{}, interceptors)
+		}
+
+		// Intercept calls the interceptors stored in the context, in order, and then the
+		// given function.
+		func Intercept(
+			ctx context.Context,
+			request interface{},
+			response interface{},
+			call func(ctx context.Context) error,
+		) error {
+			interceptors, _ := ctx.Value(interceptorsKey{}).([]Interceptor)
+			var next func(ctx context.Context, i int) error
+			next = func(ctx context.Context, i int) error {
+				if i == len(interceptors) {
+					return call(ctx)
+				}
+				return interceptors[i](ctx, request, response, func(ctx context.Context) error {
+					return next(ctx, i+1)
+				})
+			}
+			return next(ctx, 0)
+		}
+
+		// Route describes the service, version, resource and method that a request is
+		// addressed to. The names are in snake case, the same used in the URL paths, for
+		// example 'clusters_mgmt', 'v1', 'cluster' and 'get'. The variables contain the
+		// identifiers extracted from the path, indexed by the name of the locator, for
+		// example the path '/clusters_mgmt/v1/clusters/123' has a variable named 'cluster'
+		// with value '123'.
+		type Route struct {
+			Service   string
+			Version   string
+			Resource  string
+			Method    string
+			Path      string
+			Variables map[string]string
+		}
+
+		// routeKey is the key used to store the route holder in the context.
+		type routeKey struct{}
+
+		// routeHolder contains the route of a request. The adapters put an empty holder in
+		// the context before calling the middleware, and fill it when the request is
+		// matched to a method, so that the middleware can see the route.
+		type routeHolder struct {
+			route *Route
+		}
+
+		// WithRouteHolder returns a new context that contains an empty holder for the route,
+		// that will be filled by the WithRoute function.
+		func WithRouteHolder(ctx context.Context) context.Context {
+			return context.WithValue(ctx, routeKey{}, &routeHolder{})
+		}
+
+		// WithRoute stores the given route in the holder of the context and returns the same
+		// context. If the context doesn't have a holder it returns a new context that
+		// contains one with the route.
+		func WithRoute(ctx context.Context, route *Route) context.Context {
+			holder, ok := ctx.Value(routeKey{}).(*routeHolder)
+			if ok {
+				holder.route = route
+				return ctx
+			}
+			return context.WithValue(ctx, routeKey{}, &routeHolder{
+				route: route,
+			})
+		}
+
+		// RouteFromContext returns the route that the generated adapters store in the
+		// context of the requests, or nil if there is no route. The route is only available
+		// once the request has been matched to a method, so middleware will only see it
+		// after calling the next handler, for example:
+		//
+		//	func(w http.ResponseWriter, r *http.Request) {
+		//		next.ServeHTTP(w, r)
+		//		route := helpers.RouteFromContext(r.Context())
+		//		...
+		//	}
+		func RouteFromContext(ctx context.Context) *Route {
+			holder, _ := ctx.Value(routeKey{}).(*routeHolder)
+			if holder == nil {
+				return nil
+			}
+			return holder.route
+		}
+
+		// variablesKey is the key used to store the path variables in the context.
+		type variablesKey struct{}
+
+		// AddVariable returns a copy of the given request with a context that contains the
+		// path variables of the original request and the given one.
+		func AddVariable(r *http.Request, name, value string) *http.Request {
+			variables := Variables(r.Context())
+			if variables == nil {
+				variables = map[string]string{}
+			}
+			variables[name] = value
+			return r.WithContext(context.WithValue(r.Context(), variablesKey{}, variables))
+		}
+
+		// Variables returns a copy of the path variables stored in the context.
+		func Variables(ctx context.Context) map[string]string {
+			variables, _ := ctx.Value(variablesKey{}).(map[string]string)
+			if variables == nil {
+				return nil
+			}
+			result := make(map[string]string, len(variables))
+			for name, value := range variables {
+				result[name] = value
+			}
+			return result
+		}
+
+		// Authorizer is the interface that should be implemented by objects that decide if
+		// requests are allowed. The generated adapters call it before reading the request and
+		// calling the server. When the request isn't allowed the reason is sent to the client
+		// with a 403 status code. When an error is returned the client gets a 500 status code.
+		type Authorizer interface {
+			Authorize(ctx context.Context, route *Route) (allowed bool, reason string, err error)
+		}
+
+		// authorizerKey is the key used to store the authorizer in the context.
+		type authorizerKey struct{}
+
+		// WithAuthorizer returns a new context that contains the given authorizer, so that
+		// it is called by the generated adapters.
+		func WithAuthorizer(ctx context.Context, authorizer Authorizer) context.Context {
+			return context.WithValue(ctx, authorizerKey{}, authorizer)
+		}
+
+		// Authorize calls the authorizer stored in the context. If there is no authorizer
+		// the request is allowed.
+		func Authorize(ctx context.Context, route *Route) (allowed bool, reason string, err error) {
+			authorizer, ok := ctx.Value(authorizerKey{}).(Authorizer)
+			if !ok {
+				allowed = true
+				return
+			}
+			return authorizer.Authorize(ctx, route)
+		}
+
+		// TimeoutConfig contains the maximum time that the servers can take to process a
+		// request, for each kind of method. A zero value means that the default will be
+		// used, and a zero default means that there is no limit. Note that the generated
+		// adapters only set the deadline of the context passed to the servers, so servers
+		// need to honor it.
+		type TimeoutConfig struct {
+			// Default is the timeout for the methods that don't have a specific one.
+			Default time.Duration
+
+			// Get is the timeout for the 'Get' methods.
+			Get time.Duration
+
+			// List is the timeout for the 'List' methods.
+			List time.Duration
+
+			// Add is the timeout for the 'Add' methods.
+			Add time.Duration
+
+			// Update is the timeout for the 'Update' methods.
+			Update time.Duration
+
+			// Delete is the timeout for the 'Delete' methods.
+			Delete time.Duration
+
+			// Post is the timeout for the 'Post' methods.
+			Post time.Duration
+
+			// Action is the timeout for the methods that are actions, like the bulk methods.
+			Action time.Duration
+		}
+
+		// Timeout returns the timeout for the given kind of method, which should be 'get',
+		// 'list', 'add', 'update', 'delete', 'post' or 'action'.
+		func (c *TimeoutConfig) Timeout(kind string) time.Duration {
+			var result time.Duration
+			switch kind {
+			case "get":
+				result = c.Get
+			case "list":
+				result = c.List
+			case "add":
+				result = c.Add
+			case "update":
+				result = c.Update
+			case "delete":
+				result = c.Delete
+			case "post":
+				result = c.Post
+			case "action":
+				result = c.Action
+			}
+			if result == 0 {
+				result = c.Default
+			}
+			return result
+		}
+
+		// timeoutConfigKey is the key used to store the timeout configuration in the context.
+		type timeoutConfigKey struct{}
+
+		// WithTimeoutConfig returns a new context that contains the given timeout
+		// configuration, so that it is used by the generated adapters.
+		func WithTimeoutConfig(ctx context.Context, config *TimeoutConfig) context.Context {
+			return context.WithValue(ctx, timeoutConfigKey{}, config)
+		}
+
+		// WithMethodTimeout returns a new context with a deadline calculated from the timeout
+		// configuration stored in the given context for the given kind of method. If there
+		// is no configuration, or there is no timeout for that kind of method, it returns
+		// the same context. The returned function must always be called to release the
+		// resources of the context.
+		func WithMethodTimeout(ctx context.Context, kind string) (context.Context, context.CancelFunc) {
+			config, ok := ctx.Value(timeoutConfigKey{}).(*TimeoutConfig)
+			if !ok || config == nil {
+				return ctx, func() {}
+			}
+			timeout := config.Timeout(kind)
+			if timeout <= 0 {
+				return ctx, func() {}
+			}
+			return context.WithTimeout(ctx, timeout)
+		}
+
+		// fallbackKey is the key used to store the fallback handler in the context.
+		type fallbackKey struct{}
+
+		// WithFallback returns a new context that contains the given handler, so that the
+		// generated dispatchers send to it the requests for services, versions and resources
+		// that don't have a server.
+		func WithFallback(ctx context.Context, handler http.Handler) context.Context {
+			return context.WithValue(ctx, fallbackKey{}, handler)
+		}
+
+		// Fallback sends the request to the fallback handler stored in the context. If there
+		// is no fallback handler it calls the given function instead.
+		func Fallback(w http.ResponseWriter, r *http.Request,
+			otherwise func(w http.ResponseWriter, r *http.Request)) {
+			handler, ok := r.Context().Value(fallbackKey{}).(http.Handler)
+			if !ok || handler == nil {
+				otherwise(w, r)
+				return
+			}
+			handler.ServeHTTP(w, r)
+		}
+
+		// NotModified checks if the given request contains the 'If-Modified-Since' header and
+		// the given modification time isn't after the time in that header. The modification
+		// time is truncated to seconds, as that is the resolution of the header.
+		func NotModified(r *http.Request, modified time.Time) bool {
+			header := r.Header.Get("If-Modified-Since")
+			if header == "" {
+				return false
+			}
+			since, err := http.ParseTime(header)
+			if err != nil {
+				return false
+			}
+			return !modified.Truncate(time.Second).After(since)
+		}
+
+		// StatusClientClosedRequest is the non standard status code used when the client
+		// closes the request before the server sends the response.
+		const StatusClientClosedRequest = 499
+
+		// ContextStatus returns the status code that should be sent when the server returns
+		// the given error while processing a request with the given context. If the error is
+		// caused by the client closing the request it returns StatusClientClosedRequest, if
+		// it is caused by a deadline it returns 504, and otherwise it returns zero. Errors
+		// that wrap the context errors, for example the *url.Error returned by the HTTP
+		// client, are also recognized.
+		func ContextStatus(ctx context.Context, err error) int {
+			switch {
+			case errors.Is(err, context.DeadlineExceeded) ||
+				errors.Is(ctx.Err(), context.DeadlineExceeded):
+				return http.StatusGatewayTimeout
+			case errors.Is(err, context.Canceled) || errors.Is(ctx.Err(), context.Canceled):
+				return StatusClientClosedRequest
+			default:
+				return 0
+			}
+		}
+
+		// SendOptions sends the response for an OPTIONS request, advertising the given list
+		// of allowed methods.
+		func SendOptions(w http.ResponseWriter, allowed string) {
+			w.Header().Set("Allow", allowed)
+			w.WriteHeader(http.StatusNoContent)
+		}
+
 		// PollContext repeatedly executes a task till it returns one of the given statuses and till the result
 		// satisfies all the given predicates.
 		func PollContext(
@@ -292,14 +740,2888 @@ func (g *HelpersGenerator) Run() error {
 			return true
 		}
 
+		// OperationIDHeader is the name of the header that contains the identifier of the
+		// operation. Servers generate it when the client doesn't send it, and return it in
+		// the response headers and in the bodies of error responses.
+		const OperationIDHeader = "X-Operation-ID"
+
+		// operationIDKey is the key used to store the operation identifier in the context.
+		type operationIDKey struct{}
+
+		// WithOperationID returns a new context that contains the given operation identifier.
+		// Clients send it in the requests that use that context.
+		func WithOperationID(ctx context.Context, id string) context.Context {
+			return context.WithValue(ctx, operationIDKey{}, id)
+		}
+
+		// OperationIDFromContext returns the operation identifier stored in the context, or
+		// an empty string if there is no such identifier.
+		func OperationIDFromContext(ctx context.Context) string {
+			if ctx == nil {
+				return ""
+			}
+			id, _ := ctx.Value(operationIDKey{}).(string)
+			return id
+		}
+
+		// NewOperationID generates a new random operation identifier.
+		func NewOperationID() string {
+			data := make([]byte, 16)
+			_, err := rand.Read(data)
+			if err != nil {
+				return strconv.FormatInt(time.Now().UnixNano(), 16)
+			}
+			return hex.EncodeToString(data)
+		}
+
 		// Name of the header used to contain the metrics path:
 		const metricHeader = "X-Metric"
+
+		// IdempotencyKeyHeader is the name of the header that clients use to send the key that
+		// servers use to detect retries of requests that create objects.
+		const IdempotencyKeyHeader = "Idempotency-Key"
+
+		// DefaultMaxItems is the default maximum number of items that the ListAll methods of
+		// the clients will retrieve.
+		const DefaultMaxItems = 10000
+
+		// MaxConcurrentRequests is the maximum number of requests that the GetMany methods of
+		// the clients will send at the same time when the caller doesn't specify it.
+		const MaxConcurrentRequests = 10
+
+		// FanOut calls the given task once for each of the given identifiers, ignoring
+		// duplicates, running at most the given number of them at the same time. If the
+		// concurrency is zero or negative MaxConcurrentRequests will be used. It stops
+		// starting new tasks when the context is cancelled or its deadline expires, and
+		// returns the identifiers that were skipped for that reason. It returns when all the
+		// tasks that were started have finished.
+		func FanOut(ctx context.Context, ids []string, concurrency int,
+			task func(context.Context, string)) (skipped []string) {
+			if concurrency <= 0 {
+				concurrency = MaxConcurrentRequests
+			}
+			seen := make(map[string]bool, len(ids))
+			slots := make(chan struct{}, concurrency)
+			group := &sync.WaitGroup{}
+			for _, id := range ids {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				if ctx.Err() != nil {
+					skipped = append(skipped, id)
+					continue
+				}
+				select {
+				case slots <- struct{}{}:
+				case <-ctx.Done():
+					skipped = append(skipped, id)
+					continue
+				}
+				group.Add(1)
+				go func(id string) {
+					defer func() {
+						<-slots
+						group.Done()
+					}()
+					task(ctx, id)
+				}(id)
+			}
+			group.Wait()
+			return
+		}
+
+		// Names of the headers that clients use to request that the server processes the
+		// request on behalf of other user and groups:
+		const (
+			ImpersonateUserHeader  = "Impersonate-User"
+			ImpersonateGroupHeader = "Impersonate-Group"
+		)
+
+		// Impersonate creates the given set of headers if needed, and then sets the headers that
+		// request impersonation of the given user and groups, replacing any previous value.
+		func Impersonate(header *http.Header, user string, groups []string) {
+			if *header == nil {
+				*header = make(http.Header)
+			}
+			header.Set(ImpersonateUserHeader, user)
+			header.Del(ImpersonateGroupHeader)
+			for _, group := range groups {
+				header.Add(ImpersonateGroupHeader, group)
+			}
+		}
+
+		// Impersonation returns the user and groups that the client asked to impersonate in the
+		// given request. The user will be empty if the client didn't request impersonation.
+		// Note that this doesn't check if the client is allowed to impersonate, that is the
+		// responsibility of the server, for example using an authorizer.
+		func Impersonation(r *http.Request) (user string, groups []string) {
+			user = r.Header.Get(ImpersonateUserHeader)
+			if user == "" {
+				return
+			}
+			groups = CopyValues(r.Header[ImpersonateGroupHeader])
+			return
+		}
+
+		// ImpersonationTransport is a round tripper that adds the impersonation headers to all
+		// the requests. Don't create instances of this type directly, use the
+		// NewImpersonationTransport function instead.
+		type ImpersonationTransport struct {
+			wrapped http.RoundTripper
+			user    string
+			groups  []string
+		}
+
+		// NewImpersonationTransport creates a round tripper that sends requests using the given
+		// one, impersonating the given user and groups. Requests that already contain the
+		// impersonation headers, for example because the Impersonate method of the request was
+		// used, are sent unchanged.
+		func NewImpersonationTransport(wrapped http.RoundTripper, user string,
+			groups ...string) *ImpersonationTransport {
+			return &ImpersonationTransport{
+				wrapped: wrapped,
+				user:    user,
+				groups:  CopyValues(groups),
+			}
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (t *ImpersonationTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+			if request.Header.Get(ImpersonateUserHeader) == "" {
+				copy := *request
+				copy.Header = make(http.Header)
+				for name, values := range request.Header {
+					copy.Header[name] = CopyValues(values)
+				}
+				Impersonate(&copy.Header, t.user, t.groups)
+				request = &copy
+			}
+			return t.wrapped.RoundTrip(request)
+		}
         `)
+}
 
-	// Write the generated code:
-	return g.buffer.Write()
+func (g *HelpersGenerator) generateNegotiationSource() {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("mime", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("strconv", "")
+	g.buffer.Import("strings", "")
+	g.buffer.EmitTemplate("negotiation", `
+		// Media types of the request and response bodies supported by the generated code:
+		const (
+			JSONContentType = "application/json"
+			YAMLContentType = "application/yaml"
+		)
+
+		// NegotiateContentType selects, from the given list of media types that the server can
+		// produce, the one that best matches the Accept header of the request. The order of the list is
+		// the order of preference of the server, used when the client accepts several of them with the
+		// same quality. Requests without the Accept header accept any media type. The returned flag
+		// will be false if the client doesn't accept any of the media types.
+		func NegotiateContentType(r *http.Request, offered ...string) (result string, ok bool) {
+			accept := strings.Join(r.Header["Accept"], ",")
+			if strings.TrimSpace(accept) == "" {
+				if len(offered) > 0 {
+					result = offered[0]
+					ok = true
+				}
+				return
+			}
+			best := 0.0
+			for _, candidate := range offered {
+				quality := acceptQuality(accept, candidate)
+				if quality > best {
+					result = candidate
+					best = quality
+					ok = true
+				}
+			}
+			return
+		}
+
+		// acceptQuality returns the quality that the given Accept header assigns to the given media
+		// type. It is taken from the most specific media range that matches it, and it is zero if there
+		// is no such media range.
+		func acceptQuality(accept, mediaType string) float64 {
+			quality := 0.0
+			specificity := -1
+			wildcard := mediaType[0:strings.Index(mediaType, "/")+1] + "*"
+			for _, item := range strings.Split(accept, ",") {
+				item = strings.TrimSpace(item)
+				if item == "" {
+					continue
+				}
+				name, params, err := mime.ParseMediaType(item)
+				if err != nil {
+					continue
+				}
+				var level int
+				switch name {
+				case mediaType:
+					level = 2
+				case wildcard:
+					level = 1
+				case "*/*":
+					level = 0
+				default:
+					continue
+				}
+				if level < specificity {
+					continue
+				}
+				value := 1.0
+				text, ok := params["q"]
+				if ok {
+					value, err = strconv.ParseFloat(text, 64)
+					if err != nil {
+						continue
+					}
+				}
+				specificity = level
+				quality = value
+			}
+			return quality
+		}
+
+		// JSONToYAML converts the given JSON text to YAML. Objects and arrays are written using the
+		// block style and strings using the double quoted style, preserving the order of the fields.
+		func JSONToYAML(data []byte) ([]byte, error) {
+			decoder := json.NewDecoder(bytes.NewReader(data))
+			decoder.UseNumber()
+			buffer := &bytes.Buffer{}
+			err := writeYAMLValue(decoder, buffer, 0)
+			if err != nil {
+				return nil, err
+			}
+			result := buffer.Bytes()
+			if len(result) > 0 {
+				result = result[1:]
+			}
+			return result, nil
+		}
+
+		// writeYAMLValue reads the next value from the given decoder and writes it to the given buffer.
+		// The value is written after the key of an object field or after the dash of an array item, so
+		// it starts with a space for scalars or with a new line for non empty objects and arrays.
+		func writeYAMLValue(decoder *json.Decoder, buffer *bytes.Buffer, indent int) error {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			switch typed := token.(type) {
+			case json.Delim:
+				if !decoder.More() {
+					_, err = decoder.Token()
+					if err != nil {
+						return err
+					}
+					if typed == '{' {
+						buffer.WriteString(" {}\n")
+					} else {
+						buffer.WriteString(" []\n")
+					}
+					return nil
+				}
+				buffer.WriteString("\n")
+				for decoder.More() {
+					buffer.WriteString(strings.Repeat(" ", indent))
+					if typed == '{' {
+						token, err = decoder.Token()
+						if err != nil {
+							return err
+						}
+						key, _ := token.(string)
+						buffer.WriteString(strconv.Quote(key))
+						buffer.WriteString(":")
+					} else {
+						buffer.WriteString("-")
+					}
+					err = writeYAMLValue(decoder, buffer, indent+2)
+					if err != nil {
+						return err
+					}
+				}
+				_, err = decoder.Token()
+				if err != nil {
+					return err
+				}
+			case string:
+				buffer.WriteString(" ")
+				buffer.WriteString(strconv.Quote(typed))
+				buffer.WriteString("\n")
+			case json.Number:
+				buffer.WriteString(" ")
+				buffer.WriteString(typed.String())
+				buffer.WriteString("\n")
+			case bool:
+				buffer.WriteString(" ")
+				buffer.WriteString(strconv.FormatBool(typed))
+				buffer.WriteString("\n")
+			case nil:
+				buffer.WriteString(" null\n")
+			}
+			return nil
+		}
+
+		// YAMLWriter is a response writer that collects the JSON body written to it, so that it can be
+		// sent converted to YAML. Don't create instances of this type directly, use the NewYAMLWriter
+		// function instead.
+		type YAMLWriter struct {
+			http.ResponseWriter
+			buffer bytes.Buffer
+		}
+
+		// NewYAMLWriter creates a response writer that sends the status code and the headers to the
+		// given writer, and that collects the JSON body till the Close method is called.
+		func NewYAMLWriter(w http.ResponseWriter) *YAMLWriter {
+			return &YAMLWriter{
+				ResponseWriter: w,
+			}
+		}
+
+		// Write is the implementation of the io.Writer interface.
+		func (w *YAMLWriter) Write(data []byte) (int, error) {
+			return w.buffer.Write(data)
+		}
+
+		// Close converts the collected JSON body to YAML and sends it to the wrapped writer.
+		func (w *YAMLWriter) Close() error {
+			if w.buffer.Len() == 0 {
+				return nil
+			}
+			data, err := JSONToYAML(w.buffer.Bytes())
+			if err != nil {
+				return err
+			}
+			_, err = w.ResponseWriter.Write(data)
+			return err
+		}
+		`)
 }
 
-func (g *HelpersGenerator) helpersFile() string {
-	return g.names.File(nomenclator.Helpers)
+func (g *HelpersGenerator) generateLimitSource() {
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.EmitTemplate("limit", `
+		// ErrBodyTooLarge is the error returned when reading a request or response body that
+		// is larger than the configured limit.
+		var ErrBodyTooLarge = fmt.Errorf("body is too large")
+
+		// ErrNotImplemented is the error returned by the methods of the unimplemented servers.
+		// The adapter translates it into a 501 response.
+		var ErrNotImplemented = fmt.Errorf("not implemented")
+
+		// LimitBody returns a reader that reads from the given one, but that returns
+		// ErrBodyTooLarge as soon as more than the given number of bytes have been read. The
+		// check happens while the body is read, so large bodies are rejected without reading
+		// them completely.
+		func LimitBody(body io.ReadCloser, limit int64) io.ReadCloser {
+			return &limitedBody{
+				body:      body,
+				remaining: limit,
+			}
+		}
+
+		type limitedBody struct {
+			body      io.ReadCloser
+			remaining int64
+		}
+
+		func (b *limitedBody) Read(data []byte) (n int, err error) {
+			if b.remaining < 0 {
+				err = ErrBodyTooLarge
+				return
+			}
+			if int64(len(data)) > b.remaining+1 {
+				data = data[0 : b.remaining+1]
+			}
+			n, err = b.body.Read(data)
+			b.remaining -= int64(n)
+			if b.remaining < 0 {
+				n += int(b.remaining)
+				err = ErrBodyTooLarge
+			}
+			return
+		}
+
+		func (b *limitedBody) Close() error {
+			return b.body.Close()
+		}
+
+		// MaxErrorBodySize is the maximum size in bytes of the bodies of error responses that
+		// the clients read in memory.
+		const MaxErrorBodySize = 1 << 20
+
+		// ReadErrorBody reads the body of an error response. It returns ErrBodyTooLarge if it
+		// is larger than MaxErrorBodySize.
+		func ReadErrorBody(body io.Reader) (data []byte, err error) {
+			data, err = ioutil.ReadAll(io.LimitReader(body, MaxErrorBodySize+1))
+			if err != nil {
+				return
+			}
+			if len(data) > MaxErrorBodySize {
+				data = nil
+				err = ErrBodyTooLarge
+			}
+			return
+		}
+
+		// ResponseLimiter is a round tripper that rejects response bodies larger than a given
+		// limit. Don't create instances of this type directly, use the NewResponseLimiter
+		// function instead.
+		type ResponseLimiter struct {
+			wrapped http.RoundTripper
+			limit   int64
+		}
+
+		// NewResponseLimiter creates a round tripper that sends requests using the given one,
+		// and that makes the clients fail with ErrBodyTooLarge when the body of a response,
+		// for example a very large list, is larger than the given number of bytes.
+		func NewResponseLimiter(wrapped http.RoundTripper, limit int64) *ResponseLimiter {
+			return &ResponseLimiter{
+				wrapped: wrapped,
+				limit:   limit,
+			}
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (l *ResponseLimiter) RoundTrip(request *http.Request) (*http.Response, error) {
+			response, err := l.wrapped.RoundTrip(request)
+			if err != nil {
+				return nil, err
+			}
+			response.Body = LimitBody(response.Body, l.limit)
+			return response, nil
+		}
+		`)
+}
+
+func (g *HelpersGenerator) generateGzipSource() {
+	g.buffer.Import("compress/gzip", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.EmitTemplate("gzip", `
+		// GzipTransport is a round tripper that asks the server to compress responses using
+		// gzip, and decompresses them transparently. Don't create instances of this type
+		// directly, use the NewGzipTransport function instead.
+		type GzipTransport struct {
+			wrapped http.RoundTripper
+		}
+
+		// NewGzipTransport creates a round tripper that sends requests using the given one,
+		// adding the 'Accept-Encoding' header, and that decompresses the responses that the
+		// server compressed.
+		func NewGzipTransport(wrapped http.RoundTripper) *GzipTransport {
+			return &GzipTransport{
+				wrapped: wrapped,
+			}
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (t *GzipTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+			if request.Header.Get("Accept-Encoding") == "" {
+				copy := *request
+				copy.Header = make(http.Header)
+				for name, values := range request.Header {
+					copy.Header[name] = CopyValues(values)
+				}
+				copy.Header.Set("Accept-Encoding", "gzip")
+				request = &copy
+			}
+			response, err := t.wrapped.RoundTrip(request)
+			if err != nil {
+				return nil, err
+			}
+			if response.Header.Get("Content-Encoding") == "gzip" {
+				reader, err := gzip.NewReader(response.Body)
+				if err != nil {
+					response.Body.Close()
+					return nil, err
+				}
+				response.Body = &gzipBody{
+					reader: reader,
+					body:   response.Body,
+				}
+				response.Header.Del("Content-Encoding")
+				response.Header.Del("Content-Length")
+				response.ContentLength = -1
+				response.Uncompressed = true
+			}
+			return response, nil
+		}
+
+		type gzipBody struct {
+			reader *gzip.Reader
+			body   io.ReadCloser
+		}
+
+		func (b *gzipBody) Read(data []byte) (int, error) {
+			return b.reader.Read(data)
+		}
+
+		func (b *gzipBody) Close() error {
+			b.reader.Close()
+			return b.body.Close()
+		}
+		`)
+}
+
+func (g *HelpersGenerator) generateBreakerSource() {
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("sync", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("breaker", `
+		// ErrCircuitOpen is the error returned by the circuit breaker when a request is rejected
+		// without sending it to the server because the circuit is open.
+		var ErrCircuitOpen = fmt.Errorf("circuit breaker is open")
+
+		// CircuitBreakerBuilder contains the configuration and logic needed to create a circuit
+		// breaker. Don't create instances of this type directly, use the NewCircuitBreaker function
+		// instead.
+		type CircuitBreakerBuilder struct {
+			wrapped   http.RoundTripper
+			threshold int
+			timeout   time.Duration
+			key       func(*http.Request) string
+		}
+
+		// CircuitBreaker is a transport that stops sending requests after a number of consecutive
+		// failures, so that clients fail fast instead of piling up requests against a server that
+		// is already degraded. Once the circuit is open it stays open during the configured
+		// timeout. After that a single probe request is allowed. If that probe succeeds the
+		// circuit is closed again, otherwise it stays open for another timeout. Requests are
+		// grouped in circuits using the configured key function, so that a failing host or
+		// resource doesn't affect the others. Don't create instances of this type directly, use
+		// the NewCircuitBreaker function instead.
+		type CircuitBreaker struct {
+			wrapped   http.RoundTripper
+			threshold int
+			timeout   time.Duration
+			key       func(*http.Request) string
+			lock      *sync.Mutex
+			circuits  map[string]*circuit
+		}
+
+		// circuit contains the state of one of the circuits managed by the breaker.
+		type circuit struct {
+			failures int
+			opened   time.Time
+			probing  bool
+		}
+
+		// NewCircuitBreaker creates a builder that can then be used to configure and create a
+		// circuit breaker that wraps the given transport. By default the circuit will open after
+		// five consecutive failures, it will stay open for ten seconds, and there will be one
+		// circuit per host.
+		func NewCircuitBreaker(wrapped http.RoundTripper) *CircuitBreakerBuilder {
+			return &CircuitBreakerBuilder{
+				wrapped:   wrapped,
+				threshold: defaultBreakerThreshold,
+				timeout:   defaultBreakerTimeout,
+				key:       BreakerHostKey,
+			}
+		}
+
+		// Threshold sets the number of consecutive failures that will open the circuit. A failure
+		// is a request that can't be sent or that receives a response with a 5xx status code.
+		func (b *CircuitBreakerBuilder) Threshold(value int) *CircuitBreakerBuilder {
+			b.threshold = value
+			return b
+		}
+
+		// Timeout sets the time that the circuit will stay open before allowing a probe request.
+		func (b *CircuitBreakerBuilder) Timeout(value time.Duration) *CircuitBreakerBuilder {
+			b.timeout = value
+			return b
+		}
+
+		// Key sets the function that calculates the circuit that a request belongs to. The
+		// BreakerHostKey and BreakerPathKey functions can be used to have one circuit per host or
+		// one circuit per resource path.
+		func (b *CircuitBreakerBuilder) Key(value func(*http.Request) string) *CircuitBreakerBuilder {
+			b.key = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new circuit breaker.
+		func (b *CircuitBreakerBuilder) Build() (breaker *CircuitBreaker, err error) {
+			// Check parameters:
+			if b.wrapped == nil {
+				err = fmt.Errorf("wrapped transport is mandatory")
+				return
+			}
+			if b.threshold <= 0 {
+				err = fmt.Errorf("threshold must be greater than zero")
+				return
+			}
+			if b.timeout <= 0 {
+				err = fmt.Errorf("timeout must be greater than zero")
+				return
+			}
+			if b.key == nil {
+				err = fmt.Errorf("key function is mandatory")
+				return
+			}
+
+			// Create and populate the object:
+			breaker = &CircuitBreaker{
+				wrapped:   b.wrapped,
+				threshold: b.threshold,
+				timeout:   b.timeout,
+				key:       b.key,
+				lock:      &sync.Mutex{},
+				circuits:  map[string]*circuit{},
+			}
+
+			return
+		}
+
+		// BreakerHostKey is a circuit breaker key function that puts all the requests sent to the
+		// same host in the same circuit.
+		func BreakerHostKey(request *http.Request) string {
+			return request.URL.Host
+		}
+
+		// BreakerPathKey is a circuit breaker key function that puts all the requests sent to the
+		// same resource in the same circuit. The resource is calculated from the metrics path, so
+		// requests for different instances of the same collection, for example different clusters,
+		// share the same circuit.
+		func BreakerPathKey(request *http.Request) string {
+			path := request.Header.Get(metricHeader)
+			if path == "" {
+				path = request.URL.Path
+			}
+			return request.URL.Host + path
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (b *CircuitBreaker) RoundTrip(request *http.Request) (response *http.Response, err error) {
+			key := b.key(request)
+			err = b.acquire(key)
+			if err != nil {
+				return
+			}
+			response, err = b.wrapped.RoundTrip(request)
+			switch {
+			case err == nil && response.StatusCode < http.StatusInternalServerError:
+				b.succeeded(key)
+			case request.Context().Err() != nil:
+				// The request was cancelled by the caller, so it doesn't say anything about the
+				// health of the server:
+				b.abandoned(key)
+			default:
+				b.failed(key)
+			}
+			return
+		}
+
+		// acquire checks if a request for the given circuit can be sent.
+		func (b *CircuitBreaker) acquire(key string) error {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			state := b.circuits[key]
+			if state == nil || state.failures < b.threshold {
+				return nil
+			}
+			if state.probing || time.Since(state.opened) < b.timeout {
+				return ErrCircuitOpen
+			}
+			state.probing = true
+			return nil
+		}
+
+		// succeeded closes the given circuit.
+		func (b *CircuitBreaker) succeeded(key string) {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			delete(b.circuits, key)
+		}
+
+		// failed records a failure for the given circuit, and opens it if the threshold has been
+		// reached.
+		func (b *CircuitBreaker) failed(key string) {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			state := b.circuits[key]
+			if state == nil {
+				state = &circuit{}
+				b.circuits[key] = state
+			}
+			state.failures++
+			state.probing = false
+			if state.failures >= b.threshold {
+				state.opened = time.Now()
+			}
+		}
+
+		// abandoned releases the probe of the given circuit, if any, without changing the count
+		// of failures.
+		func (b *CircuitBreaker) abandoned(key string) {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			state := b.circuits[key]
+			if state != nil {
+				state.probing = false
+			}
+		}
+
+		// Default circuit breaker configuration:
+		const (
+			defaultBreakerThreshold = 5
+			defaultBreakerTimeout   = 10 * time.Second
+		)
+        `)
+}
+
+func (g *HelpersGenerator) generateTransportSource() {
+	g.buffer.Import("context", "")
+	g.buffer.Import("crypto/tls", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("net", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("transport", `
+		// TransportBuilder contains the configuration and logic needed to create an HTTP
+		// transport tuned for the clients. Don't create instances of this type directly, use the
+		// NewTransport function instead.
+		type TransportBuilder struct {
+			maxIdleConns        int
+			maxIdleConnsPerHost int
+			maxConnsPerHost     int
+			idleConnTimeout     time.Duration
+			dialTimeout         time.Duration
+			tlsHandshakeTimeout time.Duration
+			tlsConfig           *tls.Config
+			http2               bool
+			dialer              func(context.Context, string, string) (net.Conn, error)
+			socket              string
+		}
+
+		// NewTransport creates a builder that can then be used to configure and create an HTTP
+		// transport that can be passed to the clients. By default HTTP/2 is enabled and the rest
+		// of the settings have the same values than the default transport of the Go library.
+		func NewTransport() *TransportBuilder {
+			return &TransportBuilder{
+				maxIdleConns:        defaultTransportMaxIdleConns,
+				idleConnTimeout:     defaultTransportIdleConnTimeout,
+				dialTimeout:         defaultTransportDialTimeout,
+				tlsHandshakeTimeout: defaultTransportTLSHandshakeTimeout,
+				http2:               true,
+			}
+		}
+
+		// MaxIdleConns sets the maximum number of idle connections kept open across all the
+		// hosts. Zero means no limit.
+		func (b *TransportBuilder) MaxIdleConns(value int) *TransportBuilder {
+			b.maxIdleConns = value
+			return b
+		}
+
+		// MaxIdleConnsPerHost sets the maximum number of idle connections kept open for each
+		// host. Zero means that the default of the Go library will be used.
+		func (b *TransportBuilder) MaxIdleConnsPerHost(value int) *TransportBuilder {
+			b.maxIdleConnsPerHost = value
+			return b
+		}
+
+		// MaxConnsPerHost sets the maximum number of connections, including the ones that are
+		// in use, that will be opened for each host. Zero means no limit.
+		func (b *TransportBuilder) MaxConnsPerHost(value int) *TransportBuilder {
+			b.maxConnsPerHost = value
+			return b
+		}
+
+		// IdleConnTimeout sets the time that an idle connection will be kept open before
+		// closing it. Zero means no limit.
+		func (b *TransportBuilder) IdleConnTimeout(value time.Duration) *TransportBuilder {
+			b.idleConnTimeout = value
+			return b
+		}
+
+		// DialTimeout sets the maximum time to wait for a connection to be established. Zero
+		// means no limit.
+		func (b *TransportBuilder) DialTimeout(value time.Duration) *TransportBuilder {
+			b.dialTimeout = value
+			return b
+		}
+
+		// TLSHandshakeTimeout sets the maximum time to wait for the TLS handshake. Zero means
+		// no limit.
+		func (b *TransportBuilder) TLSHandshakeTimeout(value time.Duration) *TransportBuilder {
+			b.tlsHandshakeTimeout = value
+			return b
+		}
+
+		// TLSConfig sets the TLS configuration, for example to use custom certificate
+		// authorities or client certificates. The builder makes a copy, so later changes to
+		// the given object don't affect the transport.
+		func (b *TransportBuilder) TLSConfig(value *tls.Config) *TransportBuilder {
+			b.tlsConfig = value
+			return b
+		}
+
+		// HTTP2 enables or disables the use of HTTP/2 for TLS connections. The default is to
+		// enable it.
+		func (b *TransportBuilder) HTTP2(value bool) *TransportBuilder {
+			b.http2 = value
+			return b
+		}
+
+		// Dialer sets a custom function that will be used to open connections, for example to
+		// connect through a sidecar or ambassador proxy. The function receives the network and
+		// the address calculated from the request URL. When a custom dialer is used the dial
+		// timeout is ignored, the function should honour the deadline of the context instead.
+		func (b *TransportBuilder) Dialer(
+			value func(ctx context.Context, network, address string) (net.Conn, error)) *TransportBuilder {
+			b.dialer = value
+			return b
+		}
+
+		// UnixSocket sets the path of an Unix domain socket that will be used for all the
+		// connections, regardless of the host and port of the request URL. The request URL
+		// still needs a host, but it is only used to populate the 'Host' header, so any value
+		// will work, for example 'http://localhost'.
+		func (b *TransportBuilder) UnixSocket(value string) *TransportBuilder {
+			b.socket = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new transport.
+		func (b *TransportBuilder) Build() (transport *http.Transport, err error) {
+			// Check parameters:
+			if b.maxIdleConns < 0 {
+				err = fmt.Errorf("maximum number of idle connections can't be negative")
+				return
+			}
+			if b.maxIdleConnsPerHost < 0 {
+				err = fmt.Errorf("maximum number of idle connections per host can't be negative")
+				return
+			}
+			if b.maxConnsPerHost < 0 {
+				err = fmt.Errorf("maximum number of connections per host can't be negative")
+				return
+			}
+			if b.idleConnTimeout < 0 {
+				err = fmt.Errorf("idle connection timeout can't be negative")
+				return
+			}
+			if b.dialTimeout < 0 {
+				err = fmt.Errorf("dial timeout can't be negative")
+				return
+			}
+			if b.tlsHandshakeTimeout < 0 {
+				err = fmt.Errorf("TLS handshake timeout can't be negative")
+				return
+			}
+			if b.dialer != nil && b.socket != "" {
+				err = fmt.Errorf("custom dialer and Unix socket can't be used at the same time")
+				return
+			}
+
+			// Calculate the function that will be used to open connections:
+			dial := b.dialer
+			if dial == nil {
+				dialer := &net.Dialer{
+					Timeout:   b.dialTimeout,
+					KeepAlive: defaultTransportKeepAlive,
+				}
+				dial = dialer.DialContext
+				if b.socket != "" {
+					socket := b.socket
+					dial = func(ctx context.Context, _, _ string) (net.Conn, error) {
+						return dialer.DialContext(ctx, "unix", socket)
+					}
+				}
+			}
+
+			// Create and populate the object:
+			transport = &http.Transport{
+				DialContext:           dial,
+				MaxIdleConns:          b.maxIdleConns,
+				MaxIdleConnsPerHost:   b.maxIdleConnsPerHost,
+				MaxConnsPerHost:       b.maxConnsPerHost,
+				IdleConnTimeout:       b.idleConnTimeout,
+				TLSHandshakeTimeout:   b.tlsHandshakeTimeout,
+				ExpectContinueTimeout: defaultTransportExpectContinueTimeout,
+				ForceAttemptHTTP2:     b.http2,
+			}
+			if b.tlsConfig != nil {
+				transport.TLSClientConfig = b.tlsConfig.Clone()
+			}
+
+			// Connections to a Unix socket are always local, so they shouldn't go through a
+			// proxy, even if one is configured in the environment:
+			if b.socket == "" {
+				transport.Proxy = http.ProxyFromEnvironment
+			}
+
+			// The Go library doesn't enable HTTP/2 automatically when the transport has a
+			// custom dialer or TLS configuration, that is the reason for the explicit flag
+			// above. To make sure that it is disabled we need to replace the protocol upgrade
+			// map with an empty one.
+			if !b.http2 {
+				transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+			}
+
+			return
+		}
+
+		// Default transport configuration:
+		const (
+			defaultTransportMaxIdleConns          = 100
+			defaultTransportIdleConnTimeout       = 90 * time.Second
+			defaultTransportDialTimeout           = 30 * time.Second
+			defaultTransportKeepAlive             = 30 * time.Second
+			defaultTransportTLSHandshakeTimeout   = 10 * time.Second
+			defaultTransportExpectContinueTimeout = 1 * time.Second
+		)
+        `)
+}
+
+func (g *HelpersGenerator) generateTokenSource() {
+	g.buffer.Import("context", "")
+	g.buffer.Import("encoding/base64", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("net/url", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("sync", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("token", `
+		// TokenSource is the interface implemented by the objects that know how to obtain access
+		// tokens. The returned expiry time is used to decide when the token needs to be
+		// refreshed, a zero value means that the token never expires.
+		type TokenSource interface {
+			Token(ctx context.Context) (access string, expiry time.Time, err error)
+		}
+
+		// TokenSourceFunc is an adapter that allows the use of ordinary functions as token
+		// sources.
+		type TokenSourceFunc func(ctx context.Context) (string, time.Time, error)
+
+		// Token is the implementation of the TokenSource interface.
+		func (f TokenSourceFunc) Token(ctx context.Context) (string, time.Time, error) {
+			return f(ctx)
+		}
+
+		// StaticTokenSource returns a token source that always returns the given access token,
+		// and that never expires.
+		func StaticTokenSource(access string) TokenSource {
+			return TokenSourceFunc(func(ctx context.Context) (string, time.Time, error) {
+				return access, time.Time{}, nil
+			})
+		}
+
+		// FileTokenSource returns a token source that reads the access token from the given
+		// file, for example a service account token mounted by Kubernetes. The file is read
+		// again every time that the token needs to be refreshed, so that changes made by the
+		// system that manages the file are picked. If the token is a JWT the expiry time is
+		// taken from its 'exp' claim, otherwise the file is read again after one minute.
+		func FileTokenSource(path string) TokenSource {
+			return TokenSourceFunc(func(ctx context.Context) (string, time.Time, error) {
+				data, err := ioutil.ReadFile(path)
+				if err != nil {
+					return "", time.Time{}, fmt.Errorf("can't read token file '%s': %v", path, err)
+				}
+				access := strings.TrimSpace(string(data))
+				if access == "" {
+					return "", time.Time{}, fmt.Errorf("token file '%s' is empty", path)
+				}
+				expiry := tokenExpiry(access)
+				if expiry.IsZero() {
+					expiry = time.Now().Add(defaultTokenFileReload)
+				}
+				return access, expiry, nil
+			})
+		}
+
+		// tokenExpiry tries to extract the expiry time from the 'exp' claim of the given token.
+		// It returns the zero time if the token isn't a JWT or if it doesn't have that claim.
+		// Note that the signature of the token isn't verified, that is the responsibility of
+		// the server.
+		func tokenExpiry(token string) time.Time {
+			parts := strings.Split(token, ".")
+			if len(parts) != 3 {
+				return time.Time{}
+			}
+			data, err := base64.RawURLEncoding.DecodeString(parts[1])
+			if err != nil {
+				return time.Time{}
+			}
+			var claims map[string]interface{}
+			err = json.Unmarshal(data, &claims)
+			if err != nil {
+				return time.Time{}
+			}
+			exp, ok := claims["exp"].(float64)
+			if !ok || exp <= 0 {
+				return time.Time{}
+			}
+			return time.Unix(int64(exp), 0)
+		}
+
+		// OAuthTokenSourceBuilder contains the configuration and logic needed to create a token
+		// source that requests tokens from an OAuth server. Don't create instances of this type
+		// directly, use the NewOAuthTokenSource function instead.
+		type OAuthTokenSourceBuilder struct {
+			transport    http.RoundTripper
+			url          string
+			clientID     string
+			clientSecret string
+			scopes       []string
+			refreshToken string
+		}
+
+		// OAuthTokenSource is a token source that requests tokens from an OAuth server, using
+		// the client credentials grant or the refresh token grant. When the server returns a
+		// new refresh token it is used for the next request. Don't create instances of this type
+		// directly, use the NewOAuthTokenSource function instead.
+		type OAuthTokenSource struct {
+			client       *http.Client
+			url          string
+			clientID     string
+			clientSecret string
+			scopes       []string
+			lock         *sync.Mutex
+			refreshToken string
+		}
+
+		// NewOAuthTokenSource creates a builder that can then be used to configure and create
+		// a token source that sends requests to the OAuth server using the given transport.
+		func NewOAuthTokenSource(transport http.RoundTripper) *OAuthTokenSourceBuilder {
+			return &OAuthTokenSourceBuilder{
+				transport: transport,
+			}
+		}
+
+		// URL sets the URL of the token endpoint of the OAuth server. This is mandatory.
+		func (b *OAuthTokenSourceBuilder) URL(value string) *OAuthTokenSourceBuilder {
+			b.url = value
+			return b
+		}
+
+		// Client sets the identifier and secret of the OAuth client.
+		func (b *OAuthTokenSourceBuilder) Client(id, secret string) *OAuthTokenSourceBuilder {
+			b.clientID = id
+			b.clientSecret = secret
+			return b
+		}
+
+		// Scopes sets the scopes that will be requested.
+		func (b *OAuthTokenSourceBuilder) Scopes(values ...string) *OAuthTokenSourceBuilder {
+			b.scopes = values
+			return b
+		}
+
+		// RefreshToken sets the offline refresh token. When it is set the token source will use
+		// the refresh token grant, otherwise it will use the client credentials grant.
+		func (b *OAuthTokenSourceBuilder) RefreshToken(value string) *OAuthTokenSourceBuilder {
+			b.refreshToken = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new token source.
+		func (b *OAuthTokenSourceBuilder) Build() (source *OAuthTokenSource, err error) {
+			// Check parameters:
+			if b.transport == nil {
+				err = fmt.Errorf("transport is mandatory")
+				return
+			}
+			if b.url == "" {
+				err = fmt.Errorf("token URL is mandatory")
+				return
+			}
+			if b.clientID == "" {
+				err = fmt.Errorf("client identifier is mandatory")
+				return
+			}
+			if b.refreshToken == "" && b.clientSecret == "" {
+				err = fmt.Errorf(
+					"client secret is mandatory when no refresh token is provided",
+				)
+				return
+			}
+
+			// Create and populate the object:
+			scopes := make([]string, len(b.scopes))
+			copy(scopes, b.scopes)
+			source = &OAuthTokenSource{
+				client: &http.Client{
+					Transport: b.transport,
+				},
+				url:          b.url,
+				clientID:     b.clientID,
+				clientSecret: b.clientSecret,
+				scopes:       scopes,
+				lock:         &sync.Mutex{},
+				refreshToken: b.refreshToken,
+			}
+
+			return
+		}
+
+		// Token is the implementation of the TokenSource interface.
+		func (s *OAuthTokenSource) Token(ctx context.Context) (access string, expiry time.Time,
+			err error) {
+			s.lock.Lock()
+			defer s.lock.Unlock()
+
+			// Prepare the form:
+			form := url.Values{}
+			if s.refreshToken != "" {
+				form.Set("grant_type", "refresh_token")
+				form.Set("refresh_token", s.refreshToken)
+			} else {
+				form.Set("grant_type", "client_credentials")
+			}
+			form.Set("client_id", s.clientID)
+			if s.clientSecret != "" {
+				form.Set("client_secret", s.clientSecret)
+			}
+			if len(s.scopes) > 0 {
+				form.Set("scope", strings.Join(s.scopes, " "))
+			}
+
+			// Send the request:
+			request, err := http.NewRequest(
+				http.MethodPost,
+				s.url,
+				strings.NewReader(form.Encode()),
+			)
+			if err != nil {
+				return
+			}
+			request = request.WithContext(ctx)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			request.Header.Set("Accept", "application/json")
+			now := time.Now()
+			response, err := s.client.Do(request)
+			if err != nil {
+				err = fmt.Errorf("can't send token request: %v", err)
+				return
+			}
+			defer response.Body.Close()
+
+			// Parse the response:
+			var result map[string]interface{}
+			err = json.NewDecoder(response.Body).Decode(&result)
+			if err != nil {
+				err = fmt.Errorf(
+					"can't parse token response with status code %d: %v",
+					response.StatusCode, err,
+				)
+				return
+			}
+			code, _ := result["error"].(string)
+			if code != "" {
+				description, _ := result["error_description"].(string)
+				err = fmt.Errorf(
+					"token request failed with status code %d: %s: %s",
+					response.StatusCode, code, description,
+				)
+				return
+			}
+			if response.StatusCode != http.StatusOK {
+				err = fmt.Errorf("token request failed with status code %d", response.StatusCode)
+				return
+			}
+			access, _ = result["access_token"].(string)
+			if access == "" {
+				err = fmt.Errorf("token response doesn't contain an access token")
+				return
+			}
+
+			// Save the new refresh token, as some servers rotate them:
+			refreshToken, _ := result["refresh_token"].(string)
+			if refreshToken != "" && s.refreshToken != "" {
+				s.refreshToken = refreshToken
+			}
+
+			// Calculate the expiry time, using the claim of the token if the server didn't
+			// return it explicitly:
+			expiresIn, _ := result["expires_in"].(float64)
+			if expiresIn > 0 {
+				expiry = now.Add(time.Duration(expiresIn) * time.Second)
+			} else {
+				expiry = tokenExpiry(access)
+			}
+
+			return
+		}
+
+		// TokenTransportBuilder contains the configuration and logic needed to create a
+		// transport that adds access tokens to requests. Don't create instances of this type
+		// directly, use the NewTokenTransport function instead.
+		type TokenTransportBuilder struct {
+			wrapped http.RoundTripper
+			source  TokenSource
+			margin  time.Duration
+		}
+
+		// TokenTransport is a round tripper that adds the 'Authorization' header to requests,
+		// using the tokens obtained from a token source. Tokens are cached and refreshed
+		// automatically shortly before they expire, or when the server rejects them. Don't
+		// create instances of this type directly, use the NewTokenTransport function instead.
+		type TokenTransport struct {
+			wrapped http.RoundTripper
+			source  TokenSource
+			margin  time.Duration
+			lock    *sync.Mutex
+			access  string
+			expiry  time.Time
+		}
+
+		// NewTokenTransport creates a builder that can then be used to configure and create a
+		// transport that sends requests using the given one. By default tokens are refreshed
+		// one minute before they expire.
+		func NewTokenTransport(wrapped http.RoundTripper) *TokenTransportBuilder {
+			return &TokenTransportBuilder{
+				wrapped: wrapped,
+				margin:  defaultTokenMargin,
+			}
+		}
+
+		// Source sets the object that will be used to obtain the tokens. This is mandatory.
+		func (b *TokenTransportBuilder) Source(value TokenSource) *TokenTransportBuilder {
+			b.source = value
+			return b
+		}
+
+		// Margin sets how long before the expiry time a token will be refreshed.
+		func (b *TokenTransportBuilder) Margin(value time.Duration) *TokenTransportBuilder {
+			b.margin = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new token transport.
+		func (b *TokenTransportBuilder) Build() (transport *TokenTransport, err error) {
+			// Check parameters:
+			if b.wrapped == nil {
+				err = fmt.Errorf("wrapped transport is mandatory")
+				return
+			}
+			if b.source == nil {
+				err = fmt.Errorf("token source is mandatory")
+				return
+			}
+			if b.margin < 0 {
+				err = fmt.Errorf("margin can't be negative")
+				return
+			}
+
+			// Create and populate the object:
+			transport = &TokenTransport{
+				wrapped: b.wrapped,
+				source:  b.source,
+				margin:  b.margin,
+				lock:    &sync.Mutex{},
+			}
+
+			return
+		}
+
+		// Token returns the current access token, requesting a new one from the source if
+		// needed.
+		func (t *TokenTransport) Token(ctx context.Context) (access string, err error) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			if t.access != "" && (t.expiry.IsZero() || time.Now().Add(t.margin).Before(t.expiry)) {
+				access = t.access
+				return
+			}
+			access, expiry, err := t.source.Token(ctx)
+			if err != nil {
+				return
+			}
+			t.access = access
+			t.expiry = expiry
+			return
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (t *TokenTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+			// Requests that already have an explicit authorization header are sent unchanged:
+			if request.Header.Get("Authorization") != "" {
+				return t.wrapped.RoundTrip(request)
+			}
+
+			// Add the header to a copy of the request:
+			access, err := t.Token(request.Context())
+			if err != nil {
+				return nil, fmt.Errorf("can't get access token: %v", err)
+			}
+			copy := *request
+			copy.Header = make(http.Header)
+			for name, values := range request.Header {
+				copy.Header[name] = CopyValues(values)
+			}
+			copy.Header.Set("Authorization", "Bearer "+access)
+			response, err := t.wrapped.RoundTrip(&copy)
+			if err != nil {
+				return nil, err
+			}
+
+			// If the server rejected the token then discard it, so that the next request
+			// will get a new one:
+			if response.StatusCode == http.StatusUnauthorized {
+				t.lock.Lock()
+				if t.access == access {
+					t.access = ""
+				}
+				t.lock.Unlock()
+			}
+
+			return response, nil
+		}
+
+		// Default token management configuration:
+		const (
+			defaultTokenMargin     = 1 * time.Minute
+			defaultTokenFileReload = 1 * time.Minute
+		)
+		`)
+}
+
+func (g *HelpersGenerator) generateRateLimitSource() {
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("strconv", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("rate_limit", `
+		// RateLimit contains the rate limit information sent by the server in the response
+		// headers. Fields for headers that the server didn't send have the zero value.
+		type RateLimit struct {
+			// Limit is the maximum number of requests allowed in the current window, from the
+			// 'X-RateLimit-Limit' header.
+			Limit int
+
+			// Remaining is the number of requests left in the current window, from the
+			// 'X-RateLimit-Remaining' header.
+			Remaining int
+
+			// Reset is the time when the current window ends, from the 'X-RateLimit-Reset'
+			// header. That header can contain an absolute Unix time or a number of seconds,
+			// both are supported.
+			Reset time.Time
+
+			// RetryAfter is the time that the client should wait before sending more requests,
+			// from the 'Retry-After' header. That header can contain a number of seconds or a
+			// date, both are supported.
+			RetryAfter time.Duration
+		}
+
+		// ParseRateLimit extracts the rate limit information from the given response headers.
+		// Relative values are calculated using the 'Date' header, if present, or else the
+		// current time. It returns nil if the headers don't contain any rate limit information.
+		func ParseRateLimit(header http.Header) *RateLimit {
+			if header == nil {
+				return nil
+			}
+			now := time.Now()
+			date, err := http.ParseTime(header.Get("Date"))
+			if err == nil {
+				now = date
+			}
+			result := &RateLimit{}
+			found := false
+			value, ok := rateLimitValue(header, "X-RateLimit-Limit")
+			if ok {
+				result.Limit = int(value)
+				found = true
+			}
+			value, ok = rateLimitValue(header, "X-RateLimit-Remaining")
+			if ok {
+				result.Remaining = int(value)
+				found = true
+			}
+			value, ok = rateLimitValue(header, "X-RateLimit-Reset")
+			if ok {
+				if value >= rateLimitEpochThreshold {
+					result.Reset = time.Unix(value, 0)
+				} else {
+					result.Reset = now.Add(time.Duration(value) * time.Second)
+				}
+				found = true
+			}
+			text := strings.TrimSpace(header.Get("Retry-After"))
+			if text != "" {
+				seconds, err := strconv.ParseInt(text, 10, 64)
+				if err == nil && seconds >= 0 {
+					result.RetryAfter = time.Duration(seconds) * time.Second
+					found = true
+				} else {
+					when, err := http.ParseTime(text)
+					if err == nil {
+						result.RetryAfter = when.Sub(now)
+						if result.RetryAfter < 0 {
+							result.RetryAfter = 0
+						}
+						found = true
+					}
+				}
+			}
+			if !found {
+				return nil
+			}
+			return result
+		}
+
+		// rateLimitValue returns the integer value of the given header and a flag indicating if
+		// the header is present and is a valid non negative integer.
+		func rateLimitValue(header http.Header, name string) (value int64, ok bool) {
+			text := strings.TrimSpace(header.Get(name))
+			if text == "" {
+				return
+			}
+			value, err := strconv.ParseInt(text, 10, 64)
+			ok = err == nil && value >= 0
+			return
+		}
+
+		// Values of the 'X-RateLimit-Reset' header greater than this are considered absolute
+		// Unix times instead of a number of seconds:
+		const rateLimitEpochThreshold = 1000000000
+		`)
+}
+
+func (g *HelpersGenerator) generateValidationSource() {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("errors", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("strings", "")
+	g.buffer.EmitTemplate("validation", `
+		// FieldError describes a field whose value doesn't satisfy the constraints of the
+		// model.
+		type FieldError struct {
+			// Field is the path of the field, using the JSON names and dots to separate the
+			// names of nested fields, for example 'nodes.compute'.
+			Field string
+
+			// Reason is a human readable description of the problem.
+			Reason string
+		}
+
+		// ValidationErrors is the error returned when the values of an object don't satisfy
+		// the constraints of the model. It contains one item for each problem found.
+		type ValidationErrors []*FieldError
+
+		// Add adds a new problem to the list.
+		func (e *ValidationErrors) Add(field, reason string) {
+			*e = append(*e, &FieldError{
+				Field:  field,
+				Reason: reason,
+			})
+		}
+
+		// Merge adds to the list the problems described by the given error. If it is of
+		// type ValidationErrors then the prefix is added to the names of its fields.
+		// Otherwise it is added as a problem of the object that the prefix points to. Nil
+		// errors are ignored.
+		func (e *ValidationErrors) Merge(prefix string, err error) {
+			if err == nil {
+				return
+			}
+			var errs ValidationErrors
+			if errors.As(err, &errs) {
+				for _, item := range errs {
+					e.Add(prefix+item.Field, item.Reason)
+				}
+				return
+			}
+			e.Add(strings.TrimSuffix(prefix, "."), err.Error())
+		}
+
+		// Error is the implementation of the error interface.
+		func (e ValidationErrors) Error() string {
+			texts := make([]string, len(e))
+			for i, item := range e {
+				if item.Field == "" {
+					texts[i] = item.Reason
+				} else {
+					texts[i] = fmt.Sprintf("field '%s' %s", item.Field, item.Reason)
+				}
+			}
+			return "invalid values: " + strings.Join(texts, ", ")
+		}
+
+		// EqualValues checks if the given values of attributes of type 'Interface' are equal.
+		// These values are decoded from JSON documents, so they are compared by their JSON
+		// representation, where the keys of maps are sorted. Values that can't be converted
+		// to JSON are never equal.
+		func EqualValues(a, b interface{}) bool {
+			aData, err := json.Marshal(a)
+			if err != nil {
+				return false
+			}
+			bData, err := json.Marshal(b)
+			if err != nil {
+				return false
+			}
+			return bytes.Equal(aData, bData)
+		}
+		`)
+}
+
+func (g *HelpersGenerator) generateCassetteSource() {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("net/textproto", "")
+	g.buffer.Import("net/url", "")
+	g.buffer.Import("reflect", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("sync", "")
+	g.buffer.EmitTemplate("cassette", `
+		// Cassette contains a sequence of interactions between a client and a server. It is
+		// created with a cassette recorder, saved to a file, and then used in tests with a
+		// cassette replayer, so that those tests don't need a real server. Cassettes are saved
+		// as JSON documents where the names of the fields are the names of the Go fields.
+		type Cassette struct {
+			Interactions []*Interaction
+		}
+
+		// Interaction is a request sent by a client and the response returned by the server.
+		type Interaction struct {
+			Request  *InteractionRequest
+			Response *InteractionResponse
+		}
+
+		// InteractionRequest contains the details of a recorded request.
+		type InteractionRequest struct {
+			Method string
+			Path   string
+			Query  string
+			Header http.Header
+			Body   string
+		}
+
+		// InteractionResponse contains the details of a recorded response.
+		type InteractionResponse struct {
+			Status int
+			Header http.Header
+			Body   string
+		}
+
+		// LoadCassette reads a cassette from the given file.
+		func LoadCassette(path string) (cassette *Cassette, err error) {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				err = fmt.Errorf("can't read cassette file '%s': %v", path, err)
+				return
+			}
+			cassette = &Cassette{}
+			err = json.Unmarshal(data, cassette)
+			if err != nil {
+				err = fmt.Errorf("can't parse cassette file '%s': %v", path, err)
+				cassette = nil
+			}
+			return
+		}
+
+		// Save writes the cassette to the given file.
+		func (c *Cassette) Save(path string) error {
+			data, err := json.MarshalIndent(c, "", "  ")
+			if err != nil {
+				return fmt.Errorf("can't marshal cassette: %v", err)
+			}
+			err = ioutil.WriteFile(path, data, 0600)
+			if err != nil {
+				return fmt.Errorf("can't write cassette file '%s': %v", path, err)
+			}
+			return nil
+		}
+
+		// CassetteRecorderBuilder contains the configuration and logic needed to create a
+		// cassette recorder. Don't create instances of this type directly, use the
+		// NewCassetteRecorder function instead.
+		type CassetteRecorderBuilder struct {
+			wrapped  http.RoundTripper
+			redacted []string
+			fields   []string
+			filters  []func(*Interaction)
+		}
+
+		// CassetteRecorder is a transport that sends requests using the wrapped transport and
+		// records them, together with the responses, so that they can be saved to a cassette.
+		// Don't create instances of this type directly, use the NewCassetteRecorder function
+		// instead.
+		type CassetteRecorder struct {
+			wrapped  http.RoundTripper
+			redacted map[string]bool
+			fields   map[string]bool
+			filters  []func(*Interaction)
+			lock     *sync.Mutex
+			cassette *Cassette
+		}
+
+		// NewCassetteRecorder creates a builder that can then be used to configure and create a
+		// cassette recorder that wraps the given transport. By default the values of the
+		// 'Authorization', 'Cookie' and 'Set-Cookie' headers are redacted, and so are the
+		// values of the query parameters and of the fields of JSON and form bodies used by the
+		// OAuth protocol to send credentials, like 'access_token' or 'password', and of the
+		// attributes marked as sensitive in the model, so that credentials aren't saved in the
+		// cassette.
+		func NewCassetteRecorder(wrapped http.RoundTripper) *CassetteRecorderBuilder {
+			return &CassetteRecorderBuilder{
+				wrapped:  wrapped,
+				redacted: []string{"Authorization", "Cookie", "Set-Cookie"},
+				fields:   defaultRedactedFields(),
+			}
+		}
+
+		// Redact adds headers whose values will be replaced with 'REDACTED' in the recorded
+		// requests and responses.
+		func (b *CassetteRecorderBuilder) Redact(values ...string) *CassetteRecorderBuilder {
+			b.redacted = append(b.redacted, values...)
+			return b
+		}
+
+		// RedactFields adds names of query parameters and of fields of JSON and form bodies
+		// whose values will be replaced with 'REDACTED' in the recorded requests and responses.
+		// Fields of JSON documents are redacted at any level of nesting.
+		func (b *CassetteRecorderBuilder) RedactFields(values ...string) *CassetteRecorderBuilder {
+			b.fields = append(b.fields, values...)
+			return b
+		}
+
+		// Filter adds functions that will be called for each interaction before it is recorded.
+		// They can modify the interaction, for example to remove sensitive data from the bodies.
+		func (b *CassetteRecorderBuilder) Filter(values ...func(*Interaction)) *CassetteRecorderBuilder {
+			b.filters = append(b.filters, values...)
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new cassette recorder.
+		func (b *CassetteRecorderBuilder) Build() (recorder *CassetteRecorder, err error) {
+			// Check parameters:
+			if b.wrapped == nil {
+				err = fmt.Errorf("wrapped transport is mandatory")
+				return
+			}
+
+			// Create and populate the object:
+			redacted := map[string]bool{}
+			for _, name := range b.redacted {
+				redacted[textproto.CanonicalMIMEHeaderKey(name)] = true
+			}
+			fields := map[string]bool{}
+			for _, name := range b.fields {
+				fields[name] = true
+			}
+			filters := make([]func(*Interaction), len(b.filters))
+			copy(filters, b.filters)
+			recorder = &CassetteRecorder{
+				wrapped:  b.wrapped,
+				redacted: redacted,
+				fields:   fields,
+				filters:  filters,
+				lock:     &sync.Mutex{},
+				cassette: &Cassette{},
+			}
+
+			return
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (r *CassetteRecorder) RoundTrip(request *http.Request) (response *http.Response, err error) {
+			var requestBody []byte
+			if request.Body != nil {
+				requestBody, err = ioutil.ReadAll(request.Body)
+				if err != nil {
+					request.Body.Close()
+					return
+				}
+				err = request.Body.Close()
+				if err != nil {
+					return
+				}
+				// Round trippers must not modify the request, so the body that has been
+				// read is sent with a copy:
+				copy := *request
+				copy.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+				copy.GetBody = func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(bytes.NewReader(requestBody)), nil
+				}
+				request = &copy
+			}
+			requestType := request.Header.Get("Content-Type")
+			response, err = r.wrapped.RoundTrip(request)
+			if err != nil {
+				return
+			}
+			// The caller doesn't close the body of the response when there is an error, so it
+			// needs to be closed here:
+			responseBody, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				response.Body.Close()
+				response = nil
+				return
+			}
+			err = response.Body.Close()
+			if err != nil {
+				response = nil
+				return
+			}
+			response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+			responseType := response.Header.Get("Content-Type")
+			interaction := &Interaction{
+				Request: &InteractionRequest{
+					Method: request.Method,
+					Path:   request.URL.Path,
+					Query:  redactQuery(request.URL.RawQuery, r.fields),
+					Header: r.redact(request.Header),
+					Body:   redactBody(requestType, requestBody, r.fields, false),
+				},
+				Response: &InteractionResponse{
+					Status: response.StatusCode,
+					Header: r.redact(response.Header),
+					Body:   redactBody(responseType, responseBody, r.fields, false),
+				},
+			}
+			for _, filter := range r.filters {
+				filter(interaction)
+			}
+			r.lock.Lock()
+			r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+			r.lock.Unlock()
+			return
+		}
+
+		// Cassette returns a cassette containing the interactions recorded so far.
+		func (r *CassetteRecorder) Cassette() *Cassette {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			interactions := make([]*Interaction, len(r.cassette.Interactions))
+			copy(interactions, r.cassette.Interactions)
+			return &Cassette{
+				Interactions: interactions,
+			}
+		}
+
+		// Save writes the interactions recorded so far to the given file.
+		func (r *CassetteRecorder) Save(path string) error {
+			return r.Cassette().Save(path)
+		}
+
+		// redact returns a copy of the given header where the values of the redacted headers
+		// have been replaced.
+		func (r *CassetteRecorder) redact(header http.Header) http.Header {
+			if len(header) == 0 {
+				return nil
+			}
+			result := http.Header{}
+			for name, values := range header {
+				if r.redacted[textproto.CanonicalMIMEHeaderKey(name)] {
+					result[name] = []string{Redacted}
+				} else {
+					result[name] = CopyValues(values)
+				}
+			}
+			return result
+		}
+
+		// CassetteReplayerBuilder contains the configuration and logic needed to create a
+		// cassette replayer. Don't create instances of this type directly, use the
+		// NewCassetteReplayer function instead.
+		type CassetteReplayerBuilder struct {
+			cassette *Cassette
+			matcher  func(*http.Request, []byte, *InteractionRequest) bool
+		}
+
+		// CassetteReplayer is a transport that doesn't send requests to a server. Instead it
+		// returns the responses recorded in a cassette. Each recorded interaction is used only
+		// once, and in the order that they were recorded, so that a sequence of identical
+		// requests can receive different responses. Don't create instances of this type
+		// directly, use the NewCassetteReplayer function instead.
+		type CassetteReplayer struct {
+			matcher      func(*http.Request, []byte, *InteractionRequest) bool
+			lock         *sync.Mutex
+			interactions []*Interaction
+			used         []bool
+		}
+
+		// NewCassetteReplayer creates a builder that can then be used to configure and create a
+		// cassette replayer that returns the responses recorded in the given cassette. By
+		// default requests are matched using the MatchInteraction function.
+		func NewCassetteReplayer(cassette *Cassette) *CassetteReplayerBuilder {
+			return &CassetteReplayerBuilder{
+				cassette: cassette,
+				matcher:  MatchInteraction,
+			}
+		}
+
+		// Matcher sets the function that decides if a request matches a recorded request. It
+		// receives the request, its body, and the recorded request.
+		func (b *CassetteReplayerBuilder) Matcher(
+			value func(*http.Request, []byte, *InteractionRequest) bool) *CassetteReplayerBuilder {
+			b.matcher = value
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new cassette replayer.
+		func (b *CassetteReplayerBuilder) Build() (replayer *CassetteReplayer, err error) {
+			// Check parameters:
+			if b.cassette == nil {
+				err = fmt.Errorf("cassette is mandatory")
+				return
+			}
+			if b.matcher == nil {
+				err = fmt.Errorf("matcher function is mandatory")
+				return
+			}
+
+			// Create and populate the object:
+			interactions := make([]*Interaction, len(b.cassette.Interactions))
+			copy(interactions, b.cassette.Interactions)
+			replayer = &CassetteReplayer{
+				matcher:      b.matcher,
+				lock:         &sync.Mutex{},
+				interactions: interactions,
+				used:         make([]bool, len(interactions)),
+			}
+
+			return
+		}
+
+		// MatchInteraction is the default function used by the cassette replayer to match
+		// requests. It checks that the method, the path, the query and the body are the same.
+		// Bodies that contain JSON documents are compared ignoring formatting and the order
+		// of fields. Values of query parameters and of JSON and form bodies that were redacted
+		// by the recorder match any value.
+		func MatchInteraction(request *http.Request, body []byte, recorded *InteractionRequest) bool {
+			if request.Method != recorded.Method ||
+				request.URL.Path != recorded.Path ||
+				!matchQuery(request.URL.RawQuery, recorded.Query) {
+				return false
+			}
+			if string(body) == recorded.Body {
+				return true
+			}
+			contentType := request.Header.Get("Content-Type")
+			if strings.Contains(contentType, "application/x-www-form-urlencoded") {
+				return matchQuery(string(body), recorded.Body)
+			}
+			var actual, expected interface{}
+			if json.Unmarshal(body, &actual) != nil {
+				return false
+			}
+			if json.Unmarshal([]byte(recorded.Body), &expected) != nil {
+				return false
+			}
+			return matchValue(actual, expected)
+		}
+
+		// matchQuery checks if the given queries, or form bodies, are equal, considering that
+		// redacted values in the expected query match any actual value.
+		func matchQuery(actual, expected string) bool {
+			if actual == expected {
+				return true
+			}
+			actualValues, err := url.ParseQuery(actual)
+			if err != nil {
+				return false
+			}
+			expectedValues, err := url.ParseQuery(expected)
+			if err != nil {
+				return false
+			}
+			if len(actualValues) != len(expectedValues) {
+				return false
+			}
+			for name, values := range expectedValues {
+				if len(values) == 1 && values[0] == Redacted {
+					if _, ok := actualValues[name]; ok {
+						continue
+					}
+				}
+				if !reflect.DeepEqual(actualValues[name], values) {
+					return false
+				}
+			}
+			return true
+		}
+
+		// matchValue checks if the given JSON values are equal, considering that redacted
+		// values in the expected value match any actual value.
+		func matchValue(actual, expected interface{}) bool {
+			switch typed := expected.(type) {
+			case string:
+				if typed == Redacted {
+					return true
+				}
+			case []interface{}:
+				items, ok := actual.([]interface{})
+				if !ok || len(items) != len(typed) {
+					return false
+				}
+				for i, item := range typed {
+					if !matchValue(items[i], item) {
+						return false
+					}
+				}
+				return true
+			case map[string]interface{}:
+				fields, ok := actual.(map[string]interface{})
+				if !ok || len(fields) != len(typed) {
+					return false
+				}
+				for name, field := range typed {
+					value, ok := fields[name]
+					if !ok || !matchValue(value, field) {
+						return false
+					}
+				}
+				return true
+			}
+			return reflect.DeepEqual(actual, expected)
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (r *CassetteReplayer) RoundTrip(request *http.Request) (response *http.Response, err error) {
+			var body []byte
+			if request.Body != nil {
+				body, err = ioutil.ReadAll(request.Body)
+				if err != nil {
+					return
+				}
+				err = request.Body.Close()
+				if err != nil {
+					return
+				}
+			}
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			for i, interaction := range r.interactions {
+				if r.used[i] || !r.matcher(request, body, interaction.Request) {
+					continue
+				}
+				r.used[i] = true
+				recorded := interaction.Response
+				header := http.Header{}
+				for name, values := range recorded.Header {
+					header[name] = CopyValues(values)
+				}
+				response = &http.Response{
+					Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+					StatusCode:    recorded.Status,
+					Proto:         "HTTP/1.1",
+					ProtoMajor:    1,
+					ProtoMinor:    1,
+					Header:        header,
+					Body:          ioutil.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+					ContentLength: int64(len(recorded.Body)),
+					Request:       request,
+				}
+				return
+			}
+			err = fmt.Errorf(
+				"there is no recorded interaction for method '%s' and path '%s'",
+				request.Method, request.URL.Path,
+			)
+			return
+		}
+
+		// Remaining returns the number of recorded interactions that haven't been used yet.
+		// Tests can check that it is zero to verify that all the expected requests were sent.
+		func (r *CassetteReplayer) Remaining() int {
+			r.lock.Lock()
+			defer r.lock.Unlock()
+			count := 0
+			for _, used := range r.used {
+				if !used {
+					count++
+				}
+			}
+			return count
+		}
+
+		// defaultRedactedFields returns the names of the fields of JSON and form bodies, and of
+		// the query parameters, that are redacted by default: the fields used by the OAuth
+		// protocol to send credentials and the attributes marked as sensitive in the model.
+		func defaultRedactedFields() []string {
+			return []string{
+				{{ range .Fields }}
+					{{ printf "%q" . }},
+				{{ end }}
+			}
+		}
+
+		// redactQuery returns the given query with the values of the given fields replaced.
+		// The query is returned unchanged if it doesn't contain any of the fields.
+		func redactQuery(query string, fields map[string]bool) string {
+			values, err := url.ParseQuery(query)
+			if err != nil {
+				return query
+			}
+			count := 0
+			for name := range values {
+				if fields[name] {
+					values[name] = []string{Redacted}
+					count++
+				}
+			}
+			if count == 0 {
+				return query
+			}
+			return values.Encode()
+		}
+
+		// redactBody returns the text of the given body with the values of the given fields
+		// replaced. Form bodies and JSON documents are supported, other bodies are returned
+		// unchanged. When the pretty flag is true JSON documents are indented and form bodies
+		// are decoded, so that they are easier to read. Otherwise the body is returned
+		// unchanged if it doesn't contain any of the fields.
+		func redactBody(contentType string, body []byte, fields map[string]bool, pretty bool) string {
+			text := string(body)
+			switch {
+			case strings.Contains(contentType, "application/x-www-form-urlencoded"):
+				values, err := url.ParseQuery(text)
+				if err != nil {
+					return text
+				}
+				count := 0
+				for name := range values {
+					if fields[name] {
+						values[name] = []string{Redacted}
+						count++
+					}
+				}
+				if pretty {
+					decoded, err := url.QueryUnescape(values.Encode())
+					if err == nil {
+						return decoded
+					}
+				}
+				if pretty || count > 0 {
+					return values.Encode()
+				}
+			default:
+				var value interface{}
+				err := json.Unmarshal(body, &value)
+				if err != nil {
+					return text
+				}
+				count := redactFields(value, fields)
+				if pretty {
+					indented, err := json.MarshalIndent(value, "", "  ")
+					if err == nil {
+						return string(indented)
+					}
+				} else if count > 0 {
+					compact, err := json.Marshal(value)
+					if err == nil {
+						return string(compact)
+					}
+				}
+			}
+			return text
+		}
+
+		// redactFields replaces the values of the given fields in the given JSON value, at any
+		// level of nesting, and returns the number of values replaced.
+		func redactFields(value interface{}, fields map[string]bool) int {
+			count := 0
+			switch typed := value.(type) {
+			case []interface{}:
+				for _, item := range typed {
+					count += redactFields(item, fields)
+				}
+			case map[string]interface{}:
+				for name, item := range typed {
+					if fields[name] {
+						typed[name] = Redacted
+						count++
+					} else {
+						count += redactFields(item, fields)
+					}
+				}
+			}
+			return count
+		}
+		`,
+		"Fields", g.redactedFields(),
+	)
+}
+
+// redactedFields calculates the names of the fields that are redacted by default by the cassette
+// recorder and the dump transport: the fields used by the OAuth protocol to send credentials and
+// the attributes marked as sensitive in the model.
+func (g *HelpersGenerator) redactedFields() []string {
+	set := map[string]bool{
+		"access_token":  true,
+		"client_secret": true,
+		"id_token":      true,
+		"password":      true,
+		"refresh_token": true,
+	}
+	for _, service := range g.model.Services() {
+		for _, version := range service.Versions() {
+			for _, typ := range version.Types() {
+				for _, attribute := range typ.Attributes() {
+					if attribute.Sensitive() {
+						set[g.binding.AttributeName(attribute)] = true
+					}
+				}
+			}
+		}
+	}
+	fields := make([]string, 0, len(set))
+	for field := range set {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+func (g *HelpersGenerator) generateDumpSource() {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("net/textproto", "")
+	g.buffer.Import("os", "")
+	g.buffer.Import("sort", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("sync", "")
+	g.buffer.EmitTemplate("dump", `
+		// DumpTransportBuilder contains the configuration and logic needed to create a
+		// transport that dumps the requests and responses. Don't create instances of this
+		// type directly, use the NewDumpTransport function instead.
+		type DumpTransportBuilder struct {
+			wrapped  http.RoundTripper
+			writer   io.Writer
+			debug    bool
+			redacted []string
+			fields   []string
+		}
+
+		// DumpTransport is a transport that sends requests using the wrapped transport and
+		// writes the details of the requests and the responses, including the headers and
+		// the bodies. JSON bodies are pretty printed. The values of headers, query parameters
+		// and body fields that usually contain credentials are redacted. Don't create
+		// instances of this type directly, use the NewDumpTransport function instead.
+		type DumpTransport struct {
+			wrapped  http.RoundTripper
+			writer   io.Writer
+			debug    bool
+			redacted map[string]bool
+			fields   map[string]bool
+			lock     *sync.Mutex
+		}
+
+		// NewDumpTransport creates a builder that can then be used to configure and create a
+		// transport that dumps the traffic sent and received with the given transport. By
+		// default the traffic is written to the standard error output, the values of the
+		// 'Authorization', 'Proxy-Authorization', 'Cookie', 'Set-Cookie' and 'X-Api-Key'
+		// headers are redacted, and so are the values of the query parameters and of the
+		// fields of JSON and form bodies used by the OAuth protocol to send credentials, like
+		// 'access_token' or 'password', and of the attributes marked as sensitive in the
+		// model.
+		func NewDumpTransport(wrapped http.RoundTripper) *DumpTransportBuilder {
+			return &DumpTransportBuilder{
+				wrapped: wrapped,
+				writer:  os.Stderr,
+				debug:   true,
+				redacted: []string{
+					"Authorization",
+					"Proxy-Authorization",
+					"Cookie",
+					"Set-Cookie",
+					"X-Api-Key",
+				},
+				fields: defaultRedactedFields(),
+			}
+		}
+
+		// Debug sets the flag that indicates if the traffic is dumped. It can be changed for
+		// individual requests using the WithDebug function. The default is true.
+		func (b *DumpTransportBuilder) Debug(value bool) *DumpTransportBuilder {
+			b.debug = value
+			return b
+		}
+
+		// DumpTo sets the writer where the traffic will be dumped. The default is the standard
+		// error output.
+		func (b *DumpTransportBuilder) DumpTo(value io.Writer) *DumpTransportBuilder {
+			b.writer = value
+			return b
+		}
+
+		// Redact adds headers whose values will be replaced with 'REDACTED' in the dumped
+		// requests and responses.
+		func (b *DumpTransportBuilder) Redact(values ...string) *DumpTransportBuilder {
+			b.redacted = append(b.redacted, values...)
+			return b
+		}
+
+		// RedactFields adds names of query parameters and of fields of JSON and form bodies
+		// whose values will be replaced with 'REDACTED' in the dumped requests and responses.
+		// Fields of JSON documents are redacted at any level of nesting.
+		func (b *DumpTransportBuilder) RedactFields(values ...string) *DumpTransportBuilder {
+			b.fields = append(b.fields, values...)
+			return b
+		}
+
+		// Build uses the configuration stored in the builder to create a new dump transport.
+		func (b *DumpTransportBuilder) Build() (transport *DumpTransport, err error) {
+			// Check parameters:
+			if b.wrapped == nil {
+				err = fmt.Errorf("wrapped transport is mandatory")
+				return
+			}
+			if b.writer == nil {
+				err = fmt.Errorf("writer is mandatory")
+				return
+			}
+
+			// Create and populate the object:
+			redacted := map[string]bool{}
+			for _, name := range b.redacted {
+				redacted[textproto.CanonicalMIMEHeaderKey(name)] = true
+			}
+			fields := map[string]bool{}
+			for _, name := range b.fields {
+				fields[name] = true
+			}
+			transport = &DumpTransport{
+				wrapped:  b.wrapped,
+				writer:   b.writer,
+				debug:    b.debug,
+				redacted: redacted,
+				fields:   fields,
+				lock:     &sync.Mutex{},
+			}
+
+			return
+		}
+
+		// debugKey is the key used to store the debug flag in the context.
+		type debugKey struct{}
+
+		// WithDebug returns a new context that contains the given debug flag. When the context
+		// is used to send a request the flag overrides the configuration of the dump
+		// transport, so that the traffic can be dumped only for some requests.
+		func WithDebug(ctx context.Context, value bool) context.Context {
+			return context.WithValue(ctx, debugKey{}, value)
+		}
+
+		// RoundTrip is the implementation of the http.RoundTripper interface.
+		func (t *DumpTransport) RoundTrip(request *http.Request) (response *http.Response, err error) {
+			debug := t.debug
+			value, ok := request.Context().Value(debugKey{}).(bool)
+			if ok {
+				debug = value
+			}
+			if !debug {
+				return t.wrapped.RoundTrip(request)
+			}
+			var requestBody []byte
+			if request.Body != nil {
+				requestBody, err = ioutil.ReadAll(request.Body)
+				if err != nil {
+					request.Body.Close()
+					return
+				}
+				err = request.Body.Close()
+				if err != nil {
+					return
+				}
+				// Round trippers must not modify the request, so the body that has been
+				// read is sent with a copy:
+				copy := *request
+				copy.Body = ioutil.NopCloser(bytes.NewReader(requestBody))
+				copy.GetBody = func() (io.ReadCloser, error) {
+					return ioutil.NopCloser(bytes.NewReader(requestBody)), nil
+				}
+				request = &copy
+			}
+			address := *request.URL
+			address.RawQuery = redactQuery(address.RawQuery, t.fields)
+			buffer := &bytes.Buffer{}
+			fmt.Fprintf(buffer, "> %s %s\n", request.Method, &address)
+			t.dumpHeader(buffer, ">", request.Header)
+			t.dumpBody(buffer, ">", request.Header, requestBody)
+			response, err = t.wrapped.RoundTrip(request)
+			if err != nil {
+				fmt.Fprintf(buffer, "< %v\n", err)
+				t.write(buffer)
+				return
+			}
+			// The caller doesn't close the body of the response when there is an error, so it
+			// needs to be closed here:
+			responseBody, err := ioutil.ReadAll(response.Body)
+			if err != nil {
+				response.Body.Close()
+				response = nil
+				fmt.Fprintf(buffer, "< %v\n", err)
+				t.write(buffer)
+				return
+			}
+			err = response.Body.Close()
+			if err != nil {
+				response = nil
+				fmt.Fprintf(buffer, "< %v\n", err)
+				t.write(buffer)
+				return
+			}
+			response.Body = ioutil.NopCloser(bytes.NewReader(responseBody))
+			fmt.Fprintf(buffer, "< %s %s\n", response.Proto, response.Status)
+			t.dumpHeader(buffer, "<", response.Header)
+			t.dumpBody(buffer, "<", response.Header, responseBody)
+			t.write(buffer)
+			return
+		}
+
+		// write writes the content of the given buffer to the output, making sure that dumps
+		// of requests sent concurrently don't get mixed.
+		func (t *DumpTransport) write(buffer *bytes.Buffer) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+			_, _ = t.writer.Write(buffer.Bytes())
+		}
+
+		// dumpHeader writes the given header, sorted by name and with the values of the
+		// redacted headers replaced.
+		func (t *DumpTransport) dumpHeader(buffer *bytes.Buffer, prefix string, header http.Header) {
+			names := make([]string, 0, len(header))
+			for name := range header {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				for _, value := range header[name] {
+					if t.redacted[textproto.CanonicalMIMEHeaderKey(name)] {
+						value = Redacted
+					}
+					fmt.Fprintf(buffer, "%s %s: %s\n", prefix, name, value)
+				}
+			}
+		}
+
+		// dumpBody writes the given body. JSON documents are pretty printed and form bodies
+		// are decoded, and in both cases the values of the redacted fields are replaced.
+		func (t *DumpTransport) dumpBody(buffer *bytes.Buffer, prefix string, header http.Header,
+			body []byte) {
+			if len(body) == 0 {
+				return
+			}
+			text := redactBody(header.Get("Content-Type"), body, t.fields, true)
+			fmt.Fprintf(buffer, "%s\n", prefix)
+			for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+				fmt.Fprintf(buffer, "%s %s\n", prefix, line)
+			}
+		}
+		`)
+}
+
+func (g *HelpersGenerator) generateCurlSource() {
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("sort", "")
+	g.buffer.Import("strings", "")
+	g.buffer.EmitTemplate("curl", `
+		// Curl returns a curl command that sends the given request with the given body. The
+		// command uses the 'URL' environment variable as the address of the server, and
+		// the 'TOKEN' environment variable as the access token, so that the real token is
+		// never included. For example, for a request to retrieve a cluster it returns
+		// something like this:
+		//
+		//	curl --header "Authorization: Bearer ${TOKEN}" "${URL}"'/api/clusters_mgmt/v1/clusters/123'
+		//
+		// The values of the body in the given sensitive paths are replaced by Redacted. The
+		// paths use the format expected by the AuditValue function.
+		func Curl(request *http.Request, body []byte, sensitive ...string) string {
+			buffer := &strings.Builder{}
+			buffer.WriteString("curl")
+			if request.Method != http.MethodGet {
+				buffer.WriteString(" --request ")
+				buffer.WriteString(request.Method)
+			}
+			buffer.WriteString(" --header \"Authorization: Bearer ${TOKEN}\"")
+			names := make([]string, 0, len(request.Header))
+			for name := range request.Header {
+				switch http.CanonicalHeaderKey(name) {
+				case "Authorization", http.CanonicalHeaderKey(metricHeader):
+					continue
+				}
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				for _, value := range request.Header[name] {
+					buffer.WriteString(" --header ")
+					buffer.WriteString(shellQuote(name + ": " + value))
+				}
+			}
+			if len(body) > 0 {
+				if request.Header.Get("Content-Type") == "" {
+					buffer.WriteString(" --header ")
+					buffer.WriteString(shellQuote("Content-Type: application/json"))
+				}
+				buffer.WriteString(" --data ")
+				buffer.WriteString(shellQuote(redactPaths(body, sensitive)))
+			}
+			buffer.WriteString(" \"${URL}\"")
+			buffer.WriteString(shellQuote(request.URL.RequestURI()))
+			return buffer.String()
+		}
+
+		// redactPaths returns the text of the given JSON document with the values in the given
+		// sensitive paths replaced by Redacted. The document is returned unchanged if there
+		// are no sensitive paths, and it is replaced completely if it can't be decoded, so
+		// that the sensitive values are never included.
+		func redactPaths(body []byte, sensitive []string) string {
+			if len(sensitive) == 0 {
+				return string(body)
+			}
+			var value interface{}
+			err := json.Unmarshal(body, &value)
+			if err != nil {
+				return Redacted
+			}
+			for _, path := range sensitive {
+				redact(value, strings.Split(path, "."))
+			}
+			redacted, err := json.Marshal(value)
+			if err != nil {
+				return Redacted
+			}
+			return string(redacted)
+		}
+
+		// shellQuote encloses the given text in single quotes, so that the shell doesn't
+		// interpret it.
+		func shellQuote(text string) string {
+			return "'" + strings.ReplaceAll(text, "'", "'\\''") + "'"
+		}
+		`)
+}
+
+func (g *HelpersGenerator) generateBrokerSource() {
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("sync", "")
+	g.buffer.EmitTemplate("broker", `
+		// Message is a message sent to or received from a message broker.
+		type Message struct {
+			// Topic is the name of the topic, queue or exchange where the message is
+			// sent.
+			Topic string
+
+			// Key is the identifier of the object that the message is about. Brokers
+			// can use it to keep in order the messages about the same object.
+			Key string
+
+			// ContentType is the media type of the body, for example
+			// 'application/json'.
+			ContentType string
+
+			// Kind is the kind of the object contained in the body.
+			Kind string
+
+			// Body contains the serialized object.
+			Body []byte
+		}
+
+		// MessageHandler is a function that processes a message received from a broker.
+		type MessageHandler func(ctx context.Context, message *Message) error
+
+		// Broker is the interface used by the generated event publishers and subscribers to
+		// send and receive messages. Implement it to connect them to a message broker like
+		// Kafka or AMQP.
+		type Broker interface {
+			// Publish sends a message to the topic indicated in the message.
+			Publish(ctx context.Context, message *Message) error
+
+			// Subscribe starts passing the messages sent to the given topic to the
+			// handler. Messages stop being delivered when the context is cancelled.
+			Subscribe(ctx context.Context, topic string, handler MessageHandler) error
+		}
+
+		// MemoryBroker is a broker that delivers the messages to the subscribers of the same
+		// process, synchronously. It is intended for tests and for applications that don't
+		// need a real broker. Don't create instances directly, use the NewMemoryBroker
+		// function instead.
+		type MemoryBroker struct {
+			lock          sync.Mutex
+			subscriptions map[string][]*memorySubscription
+			closed        bool
+			done          chan struct{}
+			watchers      sync.WaitGroup
+		}
+
+		type memorySubscription struct {
+			handler MessageHandler
+		}
+
+		// NewMemoryBroker creates a new broker that delivers the messages to the subscribers
+		// of the same process.
+		func NewMemoryBroker() *MemoryBroker {
+			return &MemoryBroker{
+				subscriptions: map[string][]*memorySubscription{},
+				done:          make(chan struct{}),
+			}
+		}
+
+		// Publish is the implementation of the Broker interface. It calls the handlers of all
+		// the subscribers of the topic and returns the first error that they return.
+		func (b *MemoryBroker) Publish(ctx context.Context, message *Message) error {
+			b.lock.Lock()
+			subscriptions := make(
+				[]*memorySubscription,
+				len(b.subscriptions[message.Topic]),
+			)
+			copy(subscriptions, b.subscriptions[message.Topic])
+			b.lock.Unlock()
+			var result error
+			for _, subscription := range subscriptions {
+				err := subscription.handler(ctx, message)
+				if err != nil && result == nil {
+					result = err
+				}
+			}
+			return result
+		}
+
+		// Subscribe is the implementation of the Broker interface. Subscriptions are removed
+		// when the context is cancelled or when the broker is closed.
+		func (b *MemoryBroker) Subscribe(ctx context.Context, topic string,
+			handler MessageHandler) error {
+			subscription := &memorySubscription{
+				handler: handler,
+			}
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			if b.closed {
+				return fmt.Errorf("can't subscribe to topic '%s', broker is closed", topic)
+			}
+			b.subscriptions[topic] = append(b.subscriptions[topic], subscription)
+
+			// Contexts that can't be cancelled, like the background context, don't need a
+			// goroutine, the subscription will be removed when the broker is closed:
+			if ctx.Done() == nil {
+				return nil
+			}
+			b.watchers.Add(1)
+			go func() {
+				defer b.watchers.Done()
+				select {
+				case <-ctx.Done():
+					b.unsubscribe(topic, subscription)
+				case <-b.done:
+				}
+			}()
+			return nil
+		}
+
+		// Close removes all the subscriptions and waits till the goroutines that watch their
+		// contexts have finished.
+		func (b *MemoryBroker) Close() error {
+			b.lock.Lock()
+			if !b.closed {
+				b.closed = true
+				b.subscriptions = map[string][]*memorySubscription{}
+				close(b.done)
+			}
+			b.lock.Unlock()
+			b.watchers.Wait()
+			return nil
+		}
+
+		// unsubscribe removes the given subscription.
+		func (b *MemoryBroker) unsubscribe(topic string, subscription *memorySubscription) {
+			b.lock.Lock()
+			defer b.lock.Unlock()
+			subscriptions := b.subscriptions[topic]
+			for i, current := range subscriptions {
+				if current == subscription {
+					b.subscriptions[topic] = append(
+						subscriptions[:i:i],
+						subscriptions[i+1:]...,
+					)
+					break
+				}
+			}
+		}
+		`)
+}
+
+func (g *HelpersGenerator) generateGenericsFile() error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.HelpersPackage()
+	fileName := g.genericsFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("helpers").
+		Package(pkgName).
+		File(fileName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateGenericsSource()
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *HelpersGenerator) generateGenericsSource() {
+	g.buffer.EmitTemplate("generics", `
+		// Value returns the value that the given pointer points to and a flag indicating if
+		// the pointer isn't nil. It is used by the accessors of the generated types.
+		func Value[T any](pointer *T) (value T, ok bool) {
+			ok = pointer != nil
+			if ok {
+				value = *pointer
+			}
+			return
+		}
+
+		// ListGet returns the item of the given slice with the given index. If there is no
+		// item with that index it returns the zero value. It is used by the Get method of the
+		// generated list types.
+		func ListGet[T any](items []T, i int) (item T) {
+			if i >= 0 && i < len(items) {
+				item = items[i]
+			}
+			return
+		}
+
+		// ListSlice returns a copy of the given slice. The result is never nil. It is used by
+		// the Slice method of the generated list types.
+		func ListSlice[T any](items []T) []T {
+			slice := make([]T, len(items))
+			copy(slice, items)
+			return slice
+		}
+
+		// ListRange runs the given function for each index and item of the given slice, in
+		// order, till the function returns false. It is used by the Range and Each methods of
+		// the generated list types.
+		func ListRange[T any](items []T, f func(index int, item T) bool) {
+			for index, item := range items {
+				if !f(index, item) {
+					break
+				}
+			}
+		}
+		`)
+}
+
+func (g *HelpersGenerator) helpersFile() string {
+	return g.names.File(nomenclator.Helpers)
+}
+
+func (g *HelpersGenerator) genericsFile() string {
+	return g.names.File(nomenclator.Generics)
+}
+
+func (g *HelpersGenerator) generateAuditSource() {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("context", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("audit", `
+		// AuditOutcome summarizes the result of a request recorded in the audit trail.
+		type AuditOutcome string
+
+		// Outcomes of audited requests:
+		const (
+			// AuditSuccess means that the request was processed and the response has a
+			// status code lower than 400.
+			AuditSuccess AuditOutcome = "success"
+
+			// AuditDenied means that the request was rejected because the client isn't
+			// authenticated or isn't allowed to perform it.
+			AuditDenied AuditOutcome = "denied"
+
+			// AuditFailure means that the response has any other error status code.
+			AuditFailure AuditOutcome = "failure"
+		)
+
+		// AuditOutcomeFromStatus calculates the outcome corresponding to the given HTTP status
+		// code.
+		func AuditOutcomeFromStatus(status int) AuditOutcome {
+			switch {
+			case status < 400:
+				return AuditSuccess
+			case status == http.StatusUnauthorized || status == http.StatusForbidden:
+				return AuditDenied
+			default:
+				return AuditFailure
+			}
+		}
+
+		// Redacted is the text that replaces the values of sensitive attributes in audit
+		// records, and of sensitive headers and fields in cassettes and dumps.
+		const Redacted = "REDACTED"
+
+		// AuditRecord contains the details of a request processed by a generated adapter.
+		type AuditRecord struct {
+			// Time is the time when the adapter received the request.
+			Time time.Time
+
+			// Duration is the time that it took to process the request.
+			Duration time.Duration
+
+			// OperationID is the identifier of the operation, sent or received in the
+			// 'X-Operation-ID' header.
+			OperationID string
+
+			// Actor is the identity of the client, as stored in the context by the
+			// authentication middleware with the WithActor function. It will be empty if
+			// the middleware didn't store it.
+			Actor string
+
+			// ImpersonatedUser is the user that the client asked to impersonate, if any.
+			ImpersonatedUser string
+
+			// Method is the HTTP method of the request.
+			Method string
+
+			// Path is the path of the request.
+			Path string
+
+			// Route describes the service, version, resource and method that the request was
+			// addressed to. It will be nil if the request didn't match any method.
+			Route *Route
+
+			// Request contains the parameters of the request, indexed by the names used in
+			// the query and in the JSON documents. Structured values are represented as
+			// they would be decoded with the json.Unmarshal function, and the values of
+			// the attributes marked as sensitive in the model are replaced by Redacted. It
+			// will be nil if the request wasn't read, for example because it wasn't
+			// authorized.
+			Request map[string]interface{}
+
+			// Status is the HTTP status code of the response.
+			Status int
+
+			// Outcome summarizes the result of the request.
+			Outcome AuditOutcome
+		}
+
+		// AuditSink is the interface that should be implemented by objects that store the
+		// audit records generated by the adapters, for example writing them to a log or
+		// sending them to a message broker. The adapters call it once the response has been
+		// sent, so implementations that take time should do their work in the background.
+		type AuditSink interface {
+			Audit(ctx context.Context, record *AuditRecord)
+		}
+
+		// AuditSinkFunc is a function that implements the AuditSink interface.
+		type AuditSinkFunc func(ctx context.Context, record *AuditRecord)
+
+		// Audit is the implementation of the AuditSink interface.
+		func (f AuditSinkFunc) Audit(ctx context.Context, record *AuditRecord) {
+			f(ctx, record)
+		}
+
+		// actorKey is the key used to store the actor in the context.
+		type actorKey struct{}
+
+		// WithActor returns a new context that contains the given actor. It is intended for
+		// authentication middleware, so that the identity of the client is included in the
+		// audit records.
+		func WithActor(ctx context.Context, actor string) context.Context {
+			return context.WithValue(ctx, actorKey{}, actor)
+		}
+
+		// Actor returns the actor stored in the context, or an empty string if there is no
+		// actor.
+		func Actor(ctx context.Context) string {
+			actor, _ := ctx.Value(actorKey{}).(string)
+			return actor
+		}
+
+		// auditRecordKey is the key used to store the audit record in the context.
+		type auditRecordKey struct{}
+
+		// WithAuditRecord returns a new context that contains the given audit record, so that
+		// the generated adapters can add to it the details of the request.
+		func WithAuditRecord(ctx context.Context, record *AuditRecord) context.Context {
+			return context.WithValue(ctx, auditRecordKey{}, record)
+		}
+
+		// AuditRecordFromContext returns the audit record that is being populated for the
+		// request, or nil if the request isn't being audited.
+		func AuditRecordFromContext(ctx context.Context) *AuditRecord {
+			record, _ := ctx.Value(auditRecordKey{}).(*AuditRecord)
+			return record
+		}
+
+		// AuditValue returns the value written by the given marshal function, decoded as it
+		// would be decoded by the json.Unmarshal function, and with the values in the given
+		// sensitive paths replaced by Redacted. Each path is a list of JSON field names
+		// separated by dots. Lists are traversed transparently, and the asterisk matches
+		// any key of a map. If the value can't be marshalled the result is nil.
+		func AuditValue(marshal func(writer io.Writer) error, sensitive ...string) interface{} {
+			buffer := &bytes.Buffer{}
+			err := marshal(buffer)
+			if err != nil {
+				return nil
+			}
+			var value interface{}
+			err = json.Unmarshal(buffer.Bytes(), &value)
+			if err != nil {
+				return nil
+			}
+			for _, path := range sensitive {
+				redact(value, strings.Split(path, "."))
+			}
+			return value
+		}
+
+		// redact replaces with Redacted the values in the given path.
+		func redact(value interface{}, path []string) {
+			switch typed := value.(type) {
+			case []interface{}:
+				for _, item := range typed {
+					redact(item, path)
+				}
+			case map[string]interface{}:
+				for key, item := range typed {
+					if path[0] != "*" && path[0] != key {
+						continue
+					}
+					if len(path) == 1 {
+						typed[key] = Redacted
+					} else {
+						redact(item, path[1:])
+					}
+				}
+			}
+		}
+	`)
 }
@@ -0,0 +1,259 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the checker that detects model concepts that would be translated into the
+// same Go identifier, so that they can be reported as model errors before generating code that
+// doesn't compile.
+
+package golang
+
+import (
+	"fmt"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// IdentifiersCheckerBuilder is an object used to configure and build the identifiers checker.
+// Don't create instances directly, use the NewIdentifiersChecker function instead.
+type IdentifiersCheckerBuilder struct {
+	reporter *reporter.Reporter
+	names    *NamesCalculator
+}
+
+// IdentifiersChecker checks that the Go identifiers generated for the concepts of the model are
+// unique within each package. Don't create instances directly, use the builder instead.
+type IdentifiersChecker struct {
+	reporter *reporter.Reporter
+	names    *NamesCalculator
+	errors   int
+}
+
+// identifiersScope contains the identifiers generated in a scope, for example a package or the
+// methods of a type, and the description of the concept that generated each of them.
+type identifiersScope struct {
+	name    string
+	sources map[string]string
+}
+
+// NewIdentifiersChecker creates a new builder for identifiers checkers.
+func NewIdentifiersChecker() *IdentifiersCheckerBuilder {
+	return new(IdentifiersCheckerBuilder)
+}
+
+// Reporter sets the object that will be used to report the collisions.
+func (b *IdentifiersCheckerBuilder) Reporter(
+	value *reporter.Reporter) *IdentifiersCheckerBuilder {
+	b.reporter = value
+	return b
+}
+
+// Names sets the object that will be used to calculate the Go identifiers. It must be the same
+// used by the generators.
+func (b *IdentifiersCheckerBuilder) Names(value *NamesCalculator) *IdentifiersCheckerBuilder {
+	b.names = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// identifiers checker using it.
+func (b *IdentifiersCheckerBuilder) Build() (checker *IdentifiersChecker, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.names == nil {
+		err = fmt.Errorf("names calculator is mandatory")
+		return
+	}
+
+	// Create the checker:
+	checker = &IdentifiersChecker{
+		reporter: b.reporter,
+		names:    b.names,
+	}
+
+	return
+}
+
+// Check checks the given model, reports the collisions found and returns an error if there is
+// at least one.
+func (c *IdentifiersChecker) Check(model *concepts.Model) error {
+	c.errors = 0
+	for _, service := range model.Services() {
+		for _, version := range service.Versions() {
+			c.checkVersion(version)
+		}
+	}
+	if c.errors > 0 {
+		if c.errors > 1 {
+			return fmt.Errorf("there were %d identifier collisions", c.errors)
+		}
+		return fmt.Errorf("there was 1 identifier collision")
+	}
+	return nil
+}
+
+func (c *IdentifiersChecker) checkVersion(version *concepts.Version) {
+	// All the code of the version goes to the same package, so all the identifiers of types,
+	// resources and methods need to be unique in that package:
+	scope := c.newScope(fmt.Sprintf(
+		"package of version '%s' of service '%s'",
+		version.Name(), version.Owner().Name(),
+	))
+
+	// Identifiers generated for the version metadata, which are always present:
+	metadata := "the version metadata"
+	c.add(scope, nomenclator.Metadata, metadata)
+	c.add(scope, names.Cat(nomenclator.Metadata, nomenclator.Builder), metadata)
+	c.add(scope, names.Cat(nomenclator.New, nomenclator.Metadata), metadata)
+	c.add(scope, names.Cat(nomenclator.Marshal, nomenclator.Metadata), metadata)
+	c.add(scope, names.Cat(nomenclator.Unmarshal, nomenclator.Metadata), metadata)
+	c.add(scope, names.Cat(nomenclator.Metadata, nomenclator.Request), metadata)
+	c.add(scope, names.Cat(nomenclator.Metadata, nomenclator.Response), metadata)
+	c.add(scope, nomenclator.GeneratedFrom, metadata)
+
+	// Identifiers generated for the types:
+	for _, typ := range version.Types() {
+		switch {
+		case typ.IsEnum():
+			source := fmt.Sprintf("enumerated type '%s'", typ.Name())
+			c.add(scope, typ.Name(), source)
+			for _, value := range typ.Values() {
+				c.add(
+					scope,
+					names.Cat(typ.Name(), value.Name()),
+					fmt.Sprintf("value '%s' of %s", value.Name(), source),
+				)
+			}
+		case typ.IsStruct() || typ.IsList() && typ.Element().IsStruct():
+			source := fmt.Sprintf("type '%s'", typ.Name())
+			c.add(scope, typ.Name(), source)
+			c.add(scope, names.Cat(typ.Name(), nomenclator.Builder), source)
+			c.add(scope, names.Cat(nomenclator.New, typ.Name()), source)
+			c.add(scope, names.Cat(nomenclator.Marshal, typ.Name()), source)
+			c.add(scope, names.Cat(nomenclator.Unmarshal, typ.Name()), source)
+		}
+		if typ.IsStruct() {
+			c.checkAttributes(typ)
+		}
+	}
+
+	// Identifiers generated for the resources and their methods:
+	for _, resource := range version.Resources() {
+		var client, server *names.Name
+		if resource.IsRoot() {
+			client = nomenclator.Client
+			server = nomenclator.Server
+		} else {
+			client = names.Cat(resource.Name(), nomenclator.Client)
+			server = names.Cat(resource.Name(), nomenclator.Server)
+		}
+		source := fmt.Sprintf("resource '%s'", resource.Name())
+		c.add(scope, client, source)
+		c.add(scope, server, source)
+		for _, method := range resource.Methods() {
+			var prefix *names.Name
+			if resource.IsRoot() {
+				prefix = method.Name()
+			} else {
+				prefix = names.Cat(resource.Name(), method.Name())
+			}
+			source := fmt.Sprintf("method '%s' of resource '%s'", method.Name(), resource.Name())
+			c.add(scope, names.Cat(prefix, nomenclator.Request), source)
+			c.add(scope, names.Cat(prefix, nomenclator.Response), source)
+			c.add(scope, names.Cat(prefix, nomenclator.Server, nomenclator.Request), source)
+			c.add(scope, names.Cat(prefix, nomenclator.Server, nomenclator.Response), source)
+			c.checkParameters(method)
+		}
+		c.checkMembers(resource)
+	}
+}
+
+// checkAttributes checks that the attributes of the given type generate different accessors.
+func (c *IdentifiersChecker) checkAttributes(typ *concepts.Type) {
+	scope := c.newScope(fmt.Sprintf("type '%s'", typ.Name()))
+	for _, attribute := range typ.Attributes() {
+		c.add(scope, attribute.Name(), fmt.Sprintf("attribute '%s'", attribute.Name()))
+	}
+}
+
+// checkParameters checks that the parameters of the given method generate different accessors,
+// and that those accessors don't collide with the methods that the request and response types
+// always contain. The accessors are escaped to avoid those methods, but the escaped name can
+// still collide, for example when the escape suffix is 'Context' and the parameter is 'Send'.
+func (c *IdentifiersChecker) checkParameters(method *concepts.Method) {
+	scope := c.newScope(fmt.Sprintf(
+		"method '%s' of resource '%s'",
+		method.Name(), method.Owner().Name(),
+	))
+	for accessor := range requestAccessors {
+		c.addIdentifier(scope, accessor, "the request and response types")
+	}
+	for _, parameter := range method.Parameters() {
+		source := fmt.Sprintf("parameter '%s'", parameter.Name())
+		accessor := c.names.Accessor(parameter.Name())
+		c.addIdentifier(scope, accessor, source)
+		c.addIdentifier(scope, "Get"+accessor, source)
+	}
+}
+
+// checkMembers checks that the methods and locators of the given resource generate different
+// methods in the client and in the server.
+func (c *IdentifiersChecker) checkMembers(resource *concepts.Resource) {
+	scope := c.newScope(fmt.Sprintf("resource '%s'", resource.Name()))
+	for _, method := range resource.Methods() {
+		c.add(scope, method.Name(), fmt.Sprintf("method '%s'", method.Name()))
+	}
+	for _, locator := range resource.Locators() {
+		c.add(scope, locator.Name(), fmt.Sprintf("locator '%s'", locator.Name()))
+	}
+}
+
+func (c *IdentifiersChecker) newScope(name string) *identifiersScope {
+	return &identifiersScope{
+		name:    name,
+		sources: map[string]string{},
+	}
+}
+
+// add adds to the scope the identifier generated for the given name, and reports an error if
+// the same identifier was already generated for a different concept.
+func (c *IdentifiersChecker) add(scope *identifiersScope, name *names.Name, source string) {
+	c.addIdentifier(scope, c.names.Public(name), source)
+}
+
+// addIdentifier adds to the scope the given identifier, and reports an error if the same
+// identifier was already generated for a different concept.
+func (c *IdentifiersChecker) addIdentifier(scope *identifiersScope, identifier string,
+	source string) {
+	existing, ok := scope.sources[identifier]
+	if !ok {
+		scope.sources[identifier] = source
+		return
+	}
+	if existing == source {
+		return
+	}
+	c.reporter.Errorf(
+		"Both %s and %s generate the Go identifier '%s' in the %s",
+		existing, source, identifier, scope.name,
+	)
+	c.errors++
+}
@@ -0,0 +1,131 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the checker of collisions of generated identifiers.
+
+package golang
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Identifiers checker", func() {
+	var checker *IdentifiersChecker
+
+	BeforeEach(func() {
+		var err error
+
+		// Create the names calculator:
+		calculator, err := NewNamesCalculator().
+			Reporter(reporter.NewReporter()).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the checker:
+		checker, err = NewIdentifiersChecker().
+			Reporter(reporter.NewReporter()).
+			Names(calculator).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// makeModel creates a model containing one service and one version with struct types with
+	// the given names.
+	makeModel := func(typeNames ...string) *concepts.Model {
+		version := concepts.NewVersion()
+		version.SetName(names.ParseUsingCase("V1"))
+		for _, typeName := range typeNames {
+			typ := concepts.NewType()
+			typ.SetKind(concepts.StructType)
+			typ.SetName(names.ParseUsingCase(typeName))
+			version.AddType(typ)
+		}
+		service := concepts.NewService()
+		service.SetName(names.ParseUsingCase("Clusters"))
+		service.AddVersion(version)
+		model := concepts.NewModel()
+		model.AddService(service)
+		return model
+	}
+
+	It("Accepts types that generate different identifiers", func() {
+		err := checker.Check(makeModel("Cluster", "Node"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Rejects type whose name collides with the builder of another type", func() {
+		err := checker.Check(makeModel("Cluster", "ClusterBuilder"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rejects type whose name collides with the version metadata", func() {
+		err := checker.Check(makeModel("Metadata"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	// makeMethodModel creates a model containing one service, one version and one resource with
+	// a method that has parameters with the given names.
+	makeMethodModel := func(parameterNames ...string) *concepts.Model {
+		model := makeModel()
+		version := model.Services()[0].Versions()[0]
+		method := concepts.NewMethod()
+		method.SetName(names.ParseUsingCase("Get"))
+		for _, parameterName := range parameterNames {
+			parameter := concepts.NewParameter()
+			parameter.SetName(names.ParseUsingCase(parameterName))
+			method.AddParameter(parameter)
+		}
+		resource := concepts.NewResource()
+		resource.SetName(names.ParseUsingCase("Cluster"))
+		resource.AddMethod(method)
+		version.AddResource(resource)
+		return model
+	}
+
+	It("Accepts parameters named like the methods of the requests", func() {
+		err := checker.Check(makeMethodModel("Send", "Header", "Clone", "RateLimit"))
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Rejects parameter whose getter collides with another parameter", func() {
+		err := checker.Check(makeMethodModel("Name", "GetName"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rejects parameter whose escaped accessor collides with the methods of requests", func() {
+		// Create a checker that escapes names adding 'Context', so that the accessor of the
+		// 'Send' parameter will be 'SendContext':
+		calculator, err := NewNamesCalculator().
+			Reporter(reporter.NewReporter()).
+			Escape("Context").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		checker, err := NewIdentifiersChecker().
+			Reporter(reporter.NewReporter()).
+			Names(calculator).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Check the model:
+		err = checker.Check(makeMethodModel("Send"))
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,117 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the functions used by the buffer to calculate the imports that are required
+// by the generated code.
+
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"strings"
+)
+
+// fixImports analyzes the given code and calculates the imports that it needs. Only the imports
+// that have been explicitly added to the buffer are considered, and the ones that aren't used are
+// removed.
+func (b *Buffer) fixImports(code []byte) (imports map[string]string, err error) {
+	// Add the package and import statements, because the code of the buffer doesn't contain
+	// them:
+	pkgName := b.cleanPkg(path.Base(b.pkg))
+	names := map[string]string{}
+	source := new(bytes.Buffer)
+	fmt.Fprintf(source, "package %s\n", pkgName)
+	for imprt, selector := range b.imports {
+		name := b.importName(imprt, selector)
+		names[imprt] = name
+		fmt.Fprintf(source, "import %s %q\n", name, imprt)
+	}
+	source.Write(code)
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, b.file, source.Bytes(), 0)
+	if err != nil {
+		return
+	}
+
+	// Check the types of the code using empty packages for the imports. This produces errors
+	// for the references to the content of those packages and to the content of the other
+	// files of the package, but we ignore them because we only need to know which identifiers
+	// are resolved to the imported packages. Note that this takes into account the scopes, so
+	// local variables with the same name than a package don't count as uses of the package.
+	info := &types.Info{
+		Uses: map[*ast.Ident]types.Object{},
+	}
+	config := &types.Config{
+		Importer: emptyImporter(names),
+		Error:    func(error) {},
+	}
+	_, _ = config.Check(b.pkg, fset, []*ast.File{file}, info)
+
+	// Keep the explicit imports that are used:
+	imports = map[string]string{}
+	for _, object := range info.Uses {
+		name, ok := object.(*types.PkgName)
+		if ok {
+			imprt := name.Imported().Path()
+			imports[imprt] = b.imports[imprt]
+		}
+	}
+
+	return
+}
+
+// importName calculates the name that will be used to refer to the given imported package. If the
+// selector isn't empty that is the name, otherwise it is the name of the package, which is assumed
+// to be the last segment of the import path. Packages where that isn't true need to be imported
+// with an explicit selector.
+func (b *Buffer) importName(imprt, selector string) string {
+	if selector != "" {
+		return selector
+	}
+	return b.cleanPkg(path.Base(imprt))
+}
+
+// emptyImporter is an importer that returns empty packages with the names given in the map, which
+// is indexed by import path.
+type emptyImporter map[string]string
+
+// Import is the implementation of the types.Importer interface.
+func (i emptyImporter) Import(imprt string) (*types.Package, error) {
+	name, ok := i[imprt]
+	if !ok {
+		name = path.Base(imprt)
+	}
+	pkg := types.NewPackage(imprt, name)
+	pkg.MarkComplete()
+	return pkg, nil
+}
+
+// isStandardImport checks if the given import path corresponds to a package of the standard
+// library. Those don't have a dot in the first segment of the path.
+func isStandardImport(imprt string) bool {
+	first := imprt
+	index := strings.Index(first, "/")
+	if index > 0 {
+		first = first[0:index]
+	}
+	return !strings.Contains(first, ".")
+}
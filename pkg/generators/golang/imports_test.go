@@ -0,0 +1,175 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the calculation of the imports of the generated code.
+
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Imports", func() {
+	var tmp string
+	var buffer *Buffer
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a temporary directory for the generated code:
+		tmp, err = ioutil.TempDir("", "imports-*")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the buffer:
+		reporter := reporter.NewReporter()
+		packages, err := NewPackagesCalculator().
+			Reporter(reporter).
+			Base("example.com/generated").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer, err = NewBuffer().
+			Reporter(reporter).
+			Output(tmp).
+			Packages(packages).
+			Package("mypkg").
+			File("example").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// read writes the buffer and returns the content of the generated file.
+	read := func() string {
+		err := buffer.Write()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(filepath.Join(tmp, "mypkg", "example.go"))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("Removes unused imports", func() {
+		buffer.Import("time", "")
+		buffer.Import("strings", "")
+		buffer.Emit(`
+			func upper(s string) string {
+				return strings.ToUpper(s)
+			}
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring(`"strings"`))
+		Expect(code).ToNot(ContainSubstring(`"time"`))
+	})
+
+	It("Doesn't add imports that weren't requested", func() {
+		buffer.Import("fmt", "")
+		buffer.Emit(`
+			func now() string {
+				return fmt.Sprintf("%v", time.Now())
+			}
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring(`"fmt"`))
+		Expect(code).ToNot(ContainSubstring(`"time"`))
+	})
+
+	It("Ignores local variables with the name of a package", func() {
+		buffer.Import("net/url", "")
+		buffer.Emit(`
+			type location struct {
+				Path string
+			}
+
+			func path(url *location) string {
+				return url.Path
+			}
+		`)
+		code := read()
+		Expect(code).ToNot(ContainSubstring(`"net/url"`))
+	})
+
+	It("Uses packages that are shadowed only in some scopes", func() {
+		buffer.Import("net/url", "")
+		buffer.Emit(`
+			type location struct {
+				Path string
+			}
+
+			func path(url *location) string {
+				return url.Path
+			}
+
+			func parse(text string) (*url.URL, error) {
+				return url.Parse(text)
+			}
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring(`"net/url"`))
+	})
+
+	It("Uses types and functions declared in other files of the package", func() {
+		buffer.Import("strings", "")
+		buffer.Emit(`
+			func upper(value *Other) string {
+				return strings.ToUpper(other(value))
+			}
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring(`"strings"`))
+	})
+
+	It("Uses the selector of packages with unusual paths", func() {
+		buffer.Import("github.com/json-iterator/go", "jsoniter")
+		buffer.Emit(`
+			var api = jsoniter.ConfigCompatibleWithStandardLibrary
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring(`"github.com/json-iterator/go"`))
+	})
+
+	It("Keeps imports with explicit selectors", func() {
+		buffer.Import("example.com/other/v1", "otherv1")
+		buffer.Import("example.com/unused/v1", "unusedv1")
+		buffer.Emit(`
+			var value otherv1.Value
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring(`otherv1 "example.com/other/v1"`))
+		Expect(code).ToNot(ContainSubstring(`"example.com/unused/v1"`))
+	})
+
+	It("Puts standard packages in a separate group", func() {
+		buffer.Import("example.com/other/v1", "")
+		buffer.Import("fmt", "")
+		buffer.Emit(`
+			func show(value v1.Value) {
+				fmt.Println(value)
+			}
+		`)
+		code := read()
+		Expect(code).To(ContainSubstring("import (\n\t\"fmt\"\n\n\t\"example.com/other/v1\"\n)"))
+	})
+})
@@ -18,7 +18,9 @@ package golang
 
 import (
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
@@ -30,32 +32,55 @@ import (
 // JSONSupportGeneratorBuilder is an object used to configure and build the JSON support generator.
 // Don't create instances directly, use the NewJSONSupporgGenerator function instead.
 type JSONSupportGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	binding  *http.BindingCalculator
+	reporter        *reporter.Reporter
+	model           *concepts.Model
+	output          string
+	packages        *PackagesCalculator
+	names           *NamesCalculator
+	types           *TypesCalculator
+	binding         *http.BindingCalculator
+	rawAttributes   bool
+	tolerantNumbers bool
+	longsAsStrings  bool
+	canonicalJSON   bool
+	clients         bool
+	servers         bool
+	header          *Header
+	stream          bool
+	maxLines        int
+	templates       *Templates
 }
 
 // JSONSupportGenerator generates JSON support code. Don't create instances directly, use the
 // builder instead.
 type JSONSupportGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	buffer   *Buffer
-	binding  *http.BindingCalculator
+	reporter        *reporter.Reporter
+	errors          int
+	model           *concepts.Model
+	output          string
+	packages        *PackagesCalculator
+	names           *NamesCalculator
+	types           *TypesCalculator
+	rawAttributes   bool
+	tolerantNumbers bool
+	longsAsStrings  bool
+	canonicalJSON   bool
+	clients         bool
+	servers         bool
+	header          *Header
+	stream          bool
+	maxLines        int
+	templates       *Templates
+	buffer          *Buffer
+	binding         *http.BindingCalculator
 }
 
 // NewJSONSupportGenerator creates a new builder JSON support code generators.
 func NewJSONSupportGenerator() *JSONSupportGeneratorBuilder {
-	return &JSONSupportGeneratorBuilder{}
+	return &JSONSupportGeneratorBuilder{
+		clients: true,
+		servers: true,
+	}
 }
 
 // Reporter sets the object that will be used to report information about the generation process,
@@ -104,6 +129,84 @@ func (b *JSONSupportGeneratorBuilder) Binding(
 	return b
 }
 
+// RawAttributes sets the flag that indicates if the generated types should keep the JSON text of
+// the attributes read when unmarshalling objects, and provide access to it with the
+// RawAttribute method. The default is to not keep it.
+func (b *JSONSupportGeneratorBuilder) RawAttributes(value bool) *JSONSupportGeneratorBuilder {
+	b.rawAttributes = value
+	return b
+}
+
+// TolerantNumbers sets the flag that indicates if the generated readers should accept integer and
+// long values encoded as JSON strings, in addition to JSON numbers. Some services use strings to
+// avoid loss of precision of 64 bits values. The default is to accept only numbers.
+func (b *JSONSupportGeneratorBuilder) TolerantNumbers(value bool) *JSONSupportGeneratorBuilder {
+	b.tolerantNumbers = value
+	return b
+}
+
+// LongsAsStrings sets the flag that indicates if the generated writers should encode long values
+// as JSON strings instead of JSON numbers. When it is set the generated readers also accept long
+// values encoded as strings, so that they can read what the writers produce. The default is to
+// use numbers.
+func (b *JSONSupportGeneratorBuilder) LongsAsStrings(value bool) *JSONSupportGeneratorBuilder {
+	b.longsAsStrings = value
+	return b
+}
+
+// CanonicalJSON sets the flag that indicates if the generated writers should produce canonical
+// JSON: without white space, with the attributes of objects sorted by name and with numbers
+// formatted in a stable way. The same object will then always be written with exactly the same
+// bytes, which is useful to calculate hashes or signatures of payloads. The default is to write
+// indented JSON with the attributes in the order of the model.
+func (b *JSONSupportGeneratorBuilder) CanonicalJSON(value bool) *JSONSupportGeneratorBuilder {
+	b.canonicalJSON = value
+	return b
+}
+
+// Clients sets the flag that indicates if the code used by the clients to write requests and
+// read responses should be generated. The default is to generate it.
+func (b *JSONSupportGeneratorBuilder) Clients(value bool) *JSONSupportGeneratorBuilder {
+	b.clients = value
+	return b
+}
+
+// Servers sets the flag that indicates if the code used by the servers to read requests and
+// write responses should be generated. The default is to generate it.
+func (b *JSONSupportGeneratorBuilder) Servers(value bool) *JSONSupportGeneratorBuilder {
+	b.servers = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *JSONSupportGeneratorBuilder) Header(value *Header) *JSONSupportGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *JSONSupportGeneratorBuilder) Stream(value bool) *JSONSupportGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *JSONSupportGeneratorBuilder) MaxLines(value int) *JSONSupportGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *JSONSupportGeneratorBuilder) Templates(value *Templates) *JSONSupportGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new types
 // generator using it.
 func (b *JSONSupportGeneratorBuilder) Build() (generator *JSONSupportGenerator, err error) {
@@ -135,12 +238,22 @@ func (b *JSONSupportGeneratorBuilder) Build() (generator *JSONSupportGenerator,
 
 	// Create the generator:
 	generator = &JSONSupportGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
-		types:    b.types,
+		reporter:        b.reporter,
+		model:           b.model,
+		output:          b.output,
+		packages:        b.packages,
+		names:           b.names,
+		types:           b.types,
+		rawAttributes:   b.rawAttributes,
+		tolerantNumbers: b.tolerantNumbers,
+		longsAsStrings:  b.longsAsStrings,
+		canonicalJSON:   b.canonicalJSON,
+		clients:         b.clients,
+		servers:         b.servers,
+		header:          b.header,
+		stream:          b.stream,
+		maxLines:        b.maxLines,
+		templates:       b.templates,
 	}
 
 	return
@@ -159,12 +272,27 @@ func (g *JSONSupportGenerator) Run() error {
 	// Generate the code for each type:
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating JSON support for version '%s'", version)
 			// Generate the code for the version metadata type:
 			err := g.generateVersionMetadataSupport(version)
 			if err != nil {
 				return err
 			}
 
+			// Generate the registry of kinds of the version:
+			err = g.generateVersionKindsSupport(version)
+			if err != nil {
+				return err
+			}
+
+			// Generate the dispatcher of the events of the version:
+			if len(version.Events()) > 0 {
+				err = g.generateVersionEventsSupport(version)
+				if err != nil {
+					return err
+				}
+			}
+
 			// Generate the code for the model types:
 			for _, typ := range version.Types() {
 				switch {
@@ -181,11 +309,14 @@ func (g *JSONSupportGenerator) Run() error {
 				}
 			}
 
-			// Generate the code for the model methods:
-			for _, resource := range version.Resources() {
-				err = g.generateResourceSupport(resource)
-				if err != nil {
-					return err
+			// Generate the code for the model methods, unless neither clients nor
+			// servers are generated:
+			if g.clients || g.servers {
+				for _, resource := range version.Resources() {
+					err = g.generateResourceSupport(resource)
+					if err != nil {
+						return err
+					}
 				}
 			}
 		}
@@ -216,26 +347,57 @@ func (g *JSONSupportGenerator) generateHelpers() error {
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
 		Package(pkgName).
 		File(fileName).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.buffer.Import("bytes", "")
+	g.buffer.Import("crypto/sha256", "")
+	g.buffer.Import("encoding/hex", "")
+	g.buffer.Import("encoding/json", "")
 	g.buffer.Import("fmt", "")
 	g.buffer.Import("io", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("math", "")
 	g.buffer.Import("net/url", "")
+	g.buffer.Import("sort", "")
 	g.buffer.Import("strconv", "")
-	g.buffer.Import("github.com/json-iterator/go", "")
-	g.buffer.Emit(`
+	g.buffer.Import("strings", "")
+	g.buffer.Import("sync", "")
+	g.buffer.Import("time", "")
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
+	g.buffer.EmitTemplate("helpers", `
+		// iteratorAPI and streamAPI are the configurations used to create iterators and
+		// streams. They are created only once because freezing a configuration is expensive.
+		// Numbers inside attributes of the interface type are read as json.Number so that
+		// they are written back exactly as they were received, and the keys of maps inside
+		// those attributes are sorted so that the output is stable.
+		var (
+			iteratorAPI = jsoniter.Config{
+				UseNumber: true,
+			}.Froze()
+			streamAPI = jsoniter.Config{
+				{{ if not .Canonical }}
+					IndentionStep: 2,
+				{{ end }}
+				SortMapKeys: true,
+			}.Froze()
+		)
+
 		// NewIterator creates a new JSON iterator that will read to the given source, which
 		// can be a slice of bytes, a string or a reader.
 		func NewIterator(source interface{}) (iterator *jsoniter.Iterator, err error) {
-			config := jsoniter.Config{}
-			api := config.Froze()
+			api := iteratorAPI
 			switch typed := source.(type) {
 			case []byte:
 				iterator = jsoniter.ParseBytes(api, typed)
@@ -252,15 +414,176 @@ func (g *JSONSupportGenerator) generateHelpers() error {
 			return
 		}
 
-		// NewStream creates a new JSON stream that will write to the given writer.
+		// NewStream creates a new JSON stream that will write to the given writer. Code that
+		// writes many objects should use BorrowStream and ReturnStream instead, as they reuse
+		// the streams and their buffers.
 		func NewStream(writer io.Writer) *jsoniter.Stream {
-			config := jsoniter.Config{
-				IndentionStep: 2,
+			return jsoniter.NewStream(streamAPI, writer, 0)
+		}
+
+		// BorrowStream takes a JSON stream from the pool and prepares it to write to the given
+		// writer. It must be returned to the pool using ReturnStream when it is no longer
+		// needed.
+		func BorrowStream(writer io.Writer) *jsoniter.Stream {
+			stream := streamPool.Get().(*jsoniter.Stream)
+			stream.Reset(writer)
+			return stream
+		}
+
+		// ReturnStream flushes the given stream, returns it to the pool and returns the error
+		// of the stream, if any. The stream must not be used after calling this function.
+		func ReturnStream(stream *jsoniter.Stream) error {
+			stream.Flush()
+			err := stream.Error
+			stream.Reset(nil)
+			stream.Error = nil
+			stream.Attachment = nil
+			if cap(stream.Buffer()) <= maxPooledStreamSize {
+				streamPool.Put(stream)
+			}
+			return err
+		}
+
+		// Hash calculates the SHA-256 digest of the JSON text written by the given function and
+		// returns it encoded in hexadecimal. If writing fails the result is an empty string.
+		func Hash(write func(stream *jsoniter.Stream)) string {
+			digest := sha256.New()
+			stream := BorrowStream(digest)
+			write(stream)
+			err := ReturnStream(stream)
+			if err != nil {
+				return ""
+			}
+			return hex.EncodeToString(digest.Sum(nil))
+		}
+
+		// KindInfo contains the metadata of a kind of object and the function used to
+		// unmarshal objects of that kind.
+		type KindInfo struct {
+			// Kind is the value of the 'kind' attribute, for example 'Cluster' or
+			// 'ClusterLink'.
+			Kind string
+
+			// Type is the name of the type of the objects.
+			Type string
+
+			// Service and Version are the names of the service and version that
+			// define the type.
+			Service string
+			Version string
+
+			// Link is true if the objects of this kind are links.
+			Link bool
+
+			// Unmarshal reads an object of this kind from a slice of bytes, a string
+			// or a reader.
+			Unmarshal func(source interface{}) (object interface{}, err error)
+		}
+
+		// KindRegistry maps kinds of objects to their metadata, so that documents containing
+		// objects of different kinds can be unmarshalled without knowing the kind in advance.
+		type KindRegistry struct {
+			kinds map[string]*KindInfo
+		}
+
+		// NewKindRegistry creates a registry containing the given kinds.
+		func NewKindRegistry(infos ...*KindInfo) *KindRegistry {
+			registry := &KindRegistry{
+				kinds: make(map[string]*KindInfo, len(infos)),
+			}
+			for _, info := range infos {
+				registry.kinds[info.Kind] = info
+			}
+			return registry
+		}
+
+		// Lookup returns the metadata of the given kind, or nil if the registry doesn't
+		// contain that kind.
+		func (r *KindRegistry) Lookup(kind string) *KindInfo {
+			if r == nil {
+				return nil
+			}
+			return r.kinds[kind]
+		}
+
+		// Kinds returns the sorted list of kinds contained in the registry.
+		func (r *KindRegistry) Kinds() []string {
+			if r == nil {
+				return nil
+			}
+			kinds := make([]string, 0, len(r.kinds))
+			for kind := range r.kinds {
+				kinds = append(kinds, kind)
+			}
+			sort.Strings(kinds)
+			return kinds
+		}
+
+		// Unmarshal reads an object from the given source, which can be a slice of bytes, a
+		// string or a reader. The type of the object is selected using the value of its
+		// 'kind' attribute. The returned object is a pointer to the corresponding type, and
+		// the returned metadata describes its kind.
+		func (r *KindRegistry) Unmarshal(source interface{}) (object interface{}, info *KindInfo,
+			err error) {
+			var data []byte
+			switch typed := source.(type) {
+			case []byte:
+				data = typed
+			case string:
+				data = []byte(typed)
+			case io.Reader:
+				data, err = ioutil.ReadAll(typed)
+				if err != nil {
+					return
+				}
+			default:
+				err = fmt.Errorf(
+					"expected slice of bytes, string or reader but got '%T'",
+					source,
+				)
+				return
+			}
+			iterator := jsoniter.ParseBytes(iteratorAPI, data)
+			kind := ""
+			for field := iterator.ReadObject(); field != ""; field = iterator.ReadObject() {
+				if field == "kind" {
+					kind = iterator.ReadString()
+					break
+				}
+				iterator.Skip()
+			}
+			if iterator.Error != nil {
+				err = iterator.Error
+				return
+			}
+			if kind == "" {
+				err = fmt.Errorf("object doesn't have a 'kind' attribute")
+				return
+			}
+			info = r.Lookup(kind)
+			if info == nil {
+				err = fmt.Errorf("unknown kind '%s'", kind)
+				return
 			}
-			api := config.Froze()
-			return jsoniter.NewStream(api, writer, 0)
+			object, err = info.Unmarshal(data)
+			return
+		}
+
+		// streamPool contains the streams that aren't currently in use.
+		var streamPool = sync.Pool{
+			New: func() interface{} {
+				return jsoniter.NewStream(streamAPI, nil, initialStreamSize)
+			},
 		}
 
+		// Sizes of the buffers of the pooled streams. Buffers that grow beyond the maximum size
+		// aren't returned to the pool, so that a few large objects don't keep large amounts
+		// of memory in use.
+		const (
+			initialStreamSize   = 512
+			maxPooledStreamSize = 64 * 1024
+		)
+
 		// NewBoolean allocates a new bool in the heap and returns a pointer to it.
 		func NewBoolean(value bool) *bool {
 			return &value
@@ -408,7 +731,66 @@ func (g *JSONSupportGenerator) generateHelpers() error {
 			}
 			return &parsedTime, nil
 		}
-	`)
+
+		// WriteCanonicalFloat writes a floating point number in the format used by canonical
+		// JSON: the shortest representation that reads back to the same value, without
+		// exponent for values between 1e-6 and 1e21, and with an exponent without leading
+		// zeros otherwise.
+		func WriteCanonicalFloat(stream *jsoniter.Stream, value float64) {
+			if math.IsInf(value, 0) || math.IsNaN(value) {
+				if stream.Error == nil {
+					stream.Error = fmt.Errorf("unsupported value: %v", value)
+				}
+				return
+			}
+			if value == 0 {
+				stream.WriteRaw("0")
+				return
+			}
+			abs := math.Abs(value)
+			if abs >= 1e-6 && abs < 1e21 {
+				stream.WriteRaw(strconv.FormatFloat(value, 'f', -1, 64))
+				return
+			}
+			text := strconv.FormatFloat(value, 'e', -1, 64)
+			index := strings.IndexByte(text, 'e')
+			mantissa := text[0:index]
+			sign := text[index+1 : index+2]
+			exponent := strings.TrimLeft(text[index+2:], "0")
+			stream.WriteRaw(mantissa + "e" + sign + exponent)
+		}
+
+		// ReadInteger reads an integer from the given iterator. The value can be encoded as a
+		// JSON number or as a JSON string containing the number.
+		func ReadInteger(iterator *jsoniter.Iterator) int {
+			if iterator.WhatIsNext() != jsoniter.StringValue {
+				return iterator.ReadInt()
+			}
+			text := iterator.ReadString()
+			value, err := strconv.Atoi(text)
+			if err != nil {
+				iterator.ReportError("ReadInteger", err.Error())
+			}
+			return value
+		}
+
+		// ReadLong reads a 64 bits integer from the given iterator. The value can be encoded as
+		// a JSON number or as a JSON string containing the number, as some services do to avoid
+		// loss of precision.
+		func ReadLong(iterator *jsoniter.Iterator) int64 {
+			if iterator.WhatIsNext() != jsoniter.StringValue {
+				return iterator.ReadInt64()
+			}
+			text := iterator.ReadString()
+			value, err := strconv.ParseInt(text, 10, 64)
+			if err != nil {
+				iterator.ReportError("ReadLong", err.Error())
+			}
+			return value
+		}
+		`,
+		"Canonical", g.canonicalJSON,
+	)
 
 	// Write the generated code:
 	return g.buffer.Write()
@@ -426,6 +808,11 @@ func (g *JSONSupportGenerator) generateVersionMetadataSupport(version *concepts.
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
 		Package(pkgName).
 		File(fileName).
 		Function("enumName", g.types.EnumName).
@@ -441,6 +828,7 @@ func (g *JSONSupportGenerator) generateVersionMetadataSupport(version *concepts.
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateVersionMetadataSource(version)
@@ -449,25 +837,195 @@ func (g *JSONSupportGenerator) generateVersionMetadataSupport(version *concepts.
 	return g.buffer.Write()
 }
 
+func (g *JSONSupportGenerator) generateVersionKindsSupport(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.kindsFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
+		Package(pkgName).
+		File(fileName).
+		Function("structName", g.types.StructName).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateVersionKindsSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *JSONSupportGenerator) generateVersionKindsSource(version *concepts.Version) {
+	var classes []*concepts.Type
+	for _, typ := range version.Types() {
+		if typ.IsClass() {
+			classes = append(classes, typ)
+		}
+	}
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("version_kinds", `
+		// Kinds contains the kinds of the objects of this version, including the kinds of
+		// the links, and the functions used to unmarshal them.
+		var Kinds = helpers.NewKindRegistry(
+			{{ range .Classes }}
+				{{ $structName := structName . }}
+				{{ $unmarshalTypeFunc := unmarshalTypeFunc . }}
+				{{ range $link := $.Links }}
+					&helpers.KindInfo{
+						{{ if $link }}
+							Kind: {{ $structName }}LinkKind,
+						{{ else }}
+							Kind: {{ $structName }}Kind,
+						{{ end }}
+						Type:    "{{ $structName }}",
+						Service: "{{ $.Version.Owner.Name.Snake }}",
+						Version: "{{ $.Version.Name.Snake }}",
+						Link:    {{ $link }},
+						Unmarshal: func(source interface{}) (interface{}, error) {
+							object, err := {{ $unmarshalTypeFunc }}(source)
+							if err != nil {
+								return nil, err
+							}
+							return object, nil
+						},
+					},
+				{{ end }}
+			{{ end }}
+		)
+		`,
+		"Version", version,
+		"Classes", classes,
+		"Links", []bool{false, true},
+	)
+}
+
+func (g *JSONSupportGenerator) generateVersionEventsSupport(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.eventsFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
+		Package(pkgName).
+		File(fileName).
+		Function("structName", g.types.StructName).
+		Function("handleEventMethod", g.handleEventMethod).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateVersionEventsSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *JSONSupportGenerator) generateVersionEventsSource(version *concepts.Version) {
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.EmitTemplate("version_events", `
+		// EventHandler is the interface implemented by the objects that process the events
+		// of this version. Use the DispatchEvent function to call the method that corresponds
+		// to the kind of an event.
+		type EventHandler interface {
+			{{ range .Version.Events }}
+				{{ $structName := structName .Payload }}
+				{{ $handleEventMethod := handleEventMethod . }}
+
+				// {{ $handleEventMethod }} processes an event of kind '{{ $structName }}'.
+				{{ $handleEventMethod }}(ctx context.Context, event *{{ $structName }}) error
+			{{ end }}
+		}
+
+		// DispatchEvent reads an event from the given source, which can be a slice of bytes,
+		// a string or a reader, and calls the method of the handler that corresponds to the
+		// kind of the event.
+		func DispatchEvent(ctx context.Context, handler EventHandler, source interface{}) error {
+			object, info, err := Kinds.Unmarshal(source)
+			if err != nil {
+				return err
+			}
+			switch event := object.(type) {
+			{{ range .Version.Events }}
+				{{ $structName := structName .Payload }}
+				case *{{ $structName }}:
+					if !info.Link {
+						return handler.{{ handleEventMethod . }}(ctx, event)
+					}
+			{{ end }}
+			}
+			return fmt.Errorf("object of kind '%s' isn't an event", info.Kind)
+		}
+		`,
+		"Version", version,
+	)
+}
+
 func (g *JSONSupportGenerator) generateVersionMetadataSource(version *concepts.Version) {
 	g.buffer.Import("fmt", "")
 	g.buffer.Import("io", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Import("github.com/json-iterator/go", "")
-	g.buffer.Emit(`
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
+	g.buffer.EmitTemplate("version_metadata", `
 		// MarshalMetadata writes a value of the metadata type to the given target, which
 		// can be a writer or a JSON encoder.
 		func MarshalMetadata(object *Metadata, writer io.Writer) error {
-			stream := helpers.NewStream(writer)
+			stream := helpers.BorrowStream(writer)
 			writeMetadata(object, stream)
-			stream.Flush()
-			return stream.Error
+			return helpers.ReturnStream(stream)
 		}
 
 		func writeMetadata(object *Metadata, stream *jsoniter.Stream) {
 			count := 0
 			stream.WriteObjectStart()
 			{{ generateWriteAttribute "serverVersion" "server_version" .Version.StringType false }}
+			{{ generateWriteAttribute "buildTimestamp" "build_timestamp" .Version.DateType false }}
+			{{ generateWriteAttribute "gitCommit" "git_commit" .Version.StringType false }}
+			{{ generateWriteAttribute "generatedFrom" "generated_from" .Version.StringType false }}
+			if object.features != nil {
+				if count > 0 {
+					stream.WriteMore()
+				}
+				stream.WriteObjectField("features")
+				stream.WriteArrayStart()
+				for i, feature := range object.features {
+					if i > 0 {
+						stream.WriteMore()
+					}
+					stream.WriteString(feature)
+				}
+				stream.WriteArrayEnd()
+				count++
+			}
 			stream.WriteObjectEnd()
 		}
 
@@ -492,6 +1050,14 @@ func (g *JSONSupportGenerator) generateVersionMetadataSource(version *concepts.V
 				}
 				switch field {
 				{{ generateReadAttribute "serverVersion" "server_version" .Version.StringType false }}
+				{{ generateReadAttribute "buildTimestamp" "build_timestamp" .Version.DateType false }}
+				{{ generateReadAttribute "gitCommit" "git_commit" .Version.StringType false }}
+				{{ generateReadAttribute "generatedFrom" "generated_from" .Version.StringType false }}
+				case "features":
+					object.features = []string{}
+					for iterator.ReadArray() {
+						object.features = append(object.features, iterator.ReadString())
+					}
 				default:
 					iterator.ReadAny()
 				}
@@ -515,14 +1081,21 @@ func (g *JSONSupportGenerator) generateStructTypeSupport(typ *concepts.Type) err
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
 		Package(pkgName).
 		File(fileName).
 		Function("attributeFieldName", g.attributeFieldName).
 		Function("attributeFieldTag", g.binding.AttributeName).
+		Function("decodeFunc", g.decodeFunc).
 		Function("enumName", g.types.EnumName).
 		Function("generateReadAttribute", g.generateReadAttribute).
+		Function("generateReadDateAttribute", g.generateReadDateAttribute).
 		Function("generateReadValue", g.generateReadValue).
-		Function("generateWriteAttribute", g.generateWriteAttribute).
+		Function("generateWriteAttributes", g.generateWriteAttributes).
 		Function("generateWriteValue", g.generateWriteValue).
 		Function("marshalTypeFunc", g.marshalTypeFunc).
 		Function("readTypeFunc", g.readTypeFunc).
@@ -534,21 +1107,28 @@ func (g *JSONSupportGenerator) generateStructTypeSupport(typ *concepts.Type) err
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateStructTypeSource(typ)
+	if typ.HasLazyAttributes() {
+		g.generateStructDecodeSource(typ)
+	}
 
 	// Write the generated code:
 	return g.buffer.Write()
 }
 
 func (g *JSONSupportGenerator) generateStructTypeSource(typ *concepts.Type) {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("encoding/json", "")
 	g.buffer.Import("fmt", "")
 	g.buffer.Import("io", "")
+	g.buffer.Import("sync", "")
 	g.buffer.Import("time", "")
-	g.buffer.Import("github.com/json-iterator/go", "")
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("struct_type", `
 		{{ $structName := structName .Type }}
 		{{ $marshalTypeFunc := marshalTypeFunc .Type }}
 		{{ $writeTypeFunc := writeTypeFunc .Type }}
@@ -557,36 +1137,40 @@ func (g *JSONSupportGenerator) generateStructTypeSource(typ *concepts.Type) {
 
 		// {{ $marshalTypeFunc }} writes a value of the '{{ .Type.Name }}' type to the given writer.
 		func {{ $marshalTypeFunc }}(object *{{ $structName }}, writer io.Writer) error {
-			stream := helpers.NewStream(writer)
+			stream := helpers.BorrowStream(writer)
 			{{ $writeTypeFunc }}(object, stream)
-			stream.Flush()
-			return stream.Error
+			return helpers.ReturnStream(stream)
 		}
 
 		// {{ $writeTypeFunc }} writes a value of the '{{ .Type.Name }}' type to the given stream.
+		// A nil object is written as a JSON null.
 		func {{ $writeTypeFunc }}(object *{{ $structName }}, stream *jsoniter.Stream) {
+			if object == nil {
+				stream.WriteNil()
+				return
+			}
+			{{ if .Type.HasLazyAttributes }}
+				object.decode()
+			{{ end }}
 			count := 0
 			stream.WriteObjectStart()
-			{{ if .Type.IsClass }}
-				if count > 0 {
-					stream.WriteMore()
-				}
-				stream.WriteObjectField("kind")
-				if object.link {
-					stream.WriteString({{ $structName }}LinkKind)
-				} else {
-					stream.WriteString({{ $structName }}Kind)
-				}
-				count++
-				{{ generateWriteAttribute "id" "id" .Type.Owner.StringType false }}
-				{{ generateWriteAttribute "href" "href" .Type.Owner.StringType false }}
-			{{ end }}
-			{{ range .Type.Attributes }}
-				{{ generateWriteAttribute (attributeFieldName .) (attributeFieldTag .) .Type .Link }}
-			{{ end }}
+			{{ generateWriteAttributes .Type }}
 			stream.WriteObjectEnd()
 		}
 
+		// Hash calculates a digest of the attributes of the object that are set. Objects with
+		// the same attributes have the same hash, so it can be used to cheaply check if an
+		// object has changed.
+		func (o *{{ $structName }}) Hash() string {
+			return helpers.Hash(func(stream *jsoniter.Stream) {
+				if o == nil {
+					stream.WriteNil()
+					return
+				}
+				{{ $writeTypeFunc }}(o, stream)
+			})
+		}
+
 		// {{ $unmarshalTypeFunc }} reads a value of the '{{ .Type.Name }}' type from the given
 		// source, which can be an slice of bytes, a string or a reader.
 		func {{ $unmarshalTypeFunc }}(source interface{}) (object *{{ $structName }}, err error) {
@@ -607,6 +1191,21 @@ func (g *JSONSupportGenerator) generateStructTypeSource(typ *concepts.Type) {
 				if field == "" {
 					break
 				}
+				{{ if .RawAttributes }}
+					// The returned bytes start with the white space that precedes the value,
+					// so it needs to be removed to save exactly the JSON text of the value.
+					raw := bytes.TrimSpace(iterator.SkipAndReturnBytes())
+					if object.raw == nil {
+						object.raw = map[string]json.RawMessage{}
+					}
+					object.raw[field] = raw
+					outer := iterator
+					iterator, err := helpers.NewIterator(raw)
+					if err != nil {
+						outer.ReportError("", err.Error())
+						break
+					}
+				{{ end }}
 				switch field {
 				{{ if .Type.IsClass }}
 					case "kind":
@@ -620,31 +1219,105 @@ func (g *JSONSupportGenerator) generateStructTypeSource(typ *concepts.Type) {
 						object.href = &value
 				{{ end }}
 				{{ range .Type.Attributes }}
-					{{ generateReadAttribute (attributeFieldName .) (attributeFieldTag .) .Type .Link }}
+					{{ if .Lazy }}
+						{{ $fieldName := attributeFieldName . }}
+						case "{{ attributeFieldTag . }}":
+							object.{{ $fieldName }}Raw = iterator.SkipAndReturnBytes()
+							object.{{ $fieldName }}Once = new(sync.Once)
+					{{ else if .Format }}
+						{{ generateReadDateAttribute (attributeFieldName .) (attributeFieldTag .) .Format }}
+					{{ else }}
+						{{ generateReadAttribute (attributeFieldName .) (attributeFieldTag .) .Type .Link }}
+					{{ end }}
 				{{ end }}
 				default:
 					iterator.ReadAny()
 				}
+				{{ if .RawAttributes }}
+					// The iterator reports the end of the input while reading numbers
+					// as an error, but that is expected when the input is only the
+					// value of the attribute.
+					if iterator.Error != nil && iterator.Error != io.EOF {
+						outer.ReportError("", iterator.Error.Error())
+					}
+				{{ end }}
 			}
 			return object
 		}
 		`,
 		"Type", typ,
+		"RawAttributes", g.rawAttributes,
 	)
 }
 
-func (g *JSONSupportGenerator) generateListTypeSupport(typ *concepts.Type) error {
-	var err error
+func (g *JSONSupportGenerator) generateStructDecodeSource(typ *concepts.Type) {
+	g.buffer.Import("sync", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("struct_decode", `
+		{{ $structName := structName .Type }}
 
-	// Calculate the package and file name:
-	pkgName := g.packages.VersionPackage(typ.Owner())
-	fileName := g.typeFile(typ)
+		// decode decodes the values of all the attributes of the object that were kept as
+		// raw JSON when the object was read.
+		func (o *{{ $structName }}) decode() {
+			{{ range .Type.Attributes }}
+				{{ if .Lazy }}
+					o.{{ decodeFunc . }}()
+				{{ end }}
+			{{ end }}
+		}
+
+		{{ range .Type.Attributes }}
+			{{ if .Lazy }}
+				{{ $fieldName := attributeFieldName . }}
+
+				// {{ decodeFunc . }} decodes the value of the '{{ .Name }}' attribute if
+				// it was kept as raw JSON when the object was read. This is done only
+				// once, even if called concurrently. If the value can't be decoded the
+				// attribute will not have a value.
+				func (o *{{ $structName }}) {{ decodeFunc . }}() {
+					if o == nil || o.{{ $fieldName }}Once == nil {
+						return
+					}
+					o.{{ $fieldName }}Once.Do(func() {
+						iterator, err := helpers.NewIterator(o.{{ $fieldName }}Raw)
+						if err != nil {
+							return
+						}
+						{{ if or .Type.IsList .Type.IsMap }}
+							if iterator.ReadNil() {
+								return
+							}
+						{{ end }}
+						{{ generateReadValue "value" .Type false }}
+						if iterator.Error == nil {
+							o.{{ $fieldName }} = value
+						}
+					})
+				}
+			{{ end }}
+		{{ end }}
+		`,
+		"Type", typ,
+	)
+}
+
+func (g *JSONSupportGenerator) generateListTypeSupport(typ *concepts.Type) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(typ.Owner())
+	fileName := g.typeFile(typ)
 
 	// Create the buffer for the generated code:
 	g.buffer, err = NewBuffer().
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
 		Package(pkgName).
 		File(fileName).
 		Function("enumName", g.types.EnumName).
@@ -662,6 +1335,7 @@ func (g *JSONSupportGenerator) generateListTypeSupport(typ *concepts.Type) error
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	g.generateListTypeSource(typ)
@@ -672,9 +1346,10 @@ func (g *JSONSupportGenerator) generateListTypeSupport(typ *concepts.Type) error
 
 func (g *JSONSupportGenerator) generateListTypeSource(typ *concepts.Type) {
 	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Import("github.com/json-iterator/go", "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("list_type", `
 		{{ $structName := structName .Type }}
 		{{ $sliceType := valueReference .Type }}
 		{{ $marshalTypeFunc := marshalTypeFunc .Type }}
@@ -685,10 +1360,9 @@ func (g *JSONSupportGenerator) generateListTypeSource(typ *concepts.Type) {
 		// {{ $marshalTypeFunc }} writes a list of values of the '{{ .Type.Element.Name }}' type to
 		// the given writer.
 		func {{ $marshalTypeFunc }}(list {{ $sliceType }}, writer io.Writer) error {
-			stream := helpers.NewStream(writer)
+			stream := helpers.BorrowStream(writer)
 			{{ $writeTypeFunc }}(list, stream)
-			stream.Flush()
-			return stream.Error
+			return helpers.ReturnStream(stream)
 		}
 
 		// {{ $writeTypeFunc }} writes a list of value of the '{{ .Type.Element.Name }}' type to
@@ -743,10 +1417,16 @@ func (g *JSONSupportGenerator) generateResourceSupport(resource *concepts.Resour
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json").
 		Package(pkgName).
 		File(fileName).
 		Function("clientRequestName", g.clientRequestName).
 		Function("clientResponseName", g.clientResponseName).
+		Function("clients", g.generateClients).
 		Function("defaultValue", g.defaultValue).
 		Function("enumName", g.types.EnumName).
 		Function("generateReadBodyParameter", g.generateReadBodyParameter).
@@ -765,6 +1445,7 @@ func (g *JSONSupportGenerator) generateResourceSupport(resource *concepts.Resour
 		Function("responseBodyParameters", g.binding.ResponseParameters).
 		Function("serverRequestName", g.serverRequestName).
 		Function("serverResponseName", g.serverResponseName).
+		Function("servers", g.generateServers).
 		Function("structName", g.types.StructName).
 		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
 		Function("valueReference", g.types.ValueReference).
@@ -775,6 +1456,7 @@ func (g *JSONSupportGenerator) generateResourceSupport(resource *concepts.Resour
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the code:
 	for _, method := range resource.Methods() {
@@ -814,70 +1496,108 @@ func (g *JSONSupportGenerator) generateAddMethodSource(method *concepts.Method)
 	body := method.GetParameter(nomenclator.Body)
 
 	// Generate the code:
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("add_method", `
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
 
-		func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
-			var err error
-			{{ if $requestQueryParameters }}
-				query := r.URL.Query()
-				{{ range $requestQueryParameters }}
-					{{ generateReadQueryParameter . }}
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
+				var err error
+				{{ if $requestQueryParameters }}
+					query := r.URL.Query()
+					{{ range $requestQueryParameters }}
+						{{ generateReadQueryParameter . }}
+					{{ end }}
 				{{ end }}
-			{{ end }}
-			request.body, err = {{ unmarshalTypeFunc .Body.Type }}(r.Body)
-			return err
-		}
+				idempotencyKey := r.Header.Get(helpers.IdempotencyKeyHeader)
+				if idempotencyKey != "" {
+					request.idempotencyKey = &idempotencyKey
+				}
+				request.body, err = {{ unmarshalTypeFunc .Body.Type }}(r.Body)
+				if err != nil {
+					return err
+				}
+				{{ if .Validate }}
+					request.body = request.body.withoutReadOnly()
+					var errs helpers.ValidationErrors
+					request.body.validate("", &errs)
+					if len(errs) > 0 {
+						return errs
+					}
+				{{ end }}
+				return nil
+			}
+		{{ end }}
 
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			return {{ marshalTypeFunc .Body.Type }}(request.body, writer)
-		}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				{{ if .Validate }}
+					return {{ marshalTypeFunc .Body.Type }}(request.body.withoutReadOnly(), writer)
+				{{ else }}
+					return {{ marshalTypeFunc .Body.Type }}(request.body, writer)
+				{{ end }}
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			var err error
-			response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
-			return err
-		}
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				var err error
+				response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
+				return err
+			}
+		{{ end }}
 
-		func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
-			return {{ marshalTypeFunc .Body.Type }}(response.body, w)
-		}
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				return {{ marshalTypeFunc .Body.Type }}(response.body, w)
+			}
+		{{ end }}
 		`,
 		"Method", method,
 		"Body", body,
+		"Validate", body.Type().Owner() == method.Owner().Owner(),
 	)
 }
 
 func (g *JSONSupportGenerator) generateDeleteMethodSource(method *concepts.Method) {
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("delete_method", `
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
 
-		func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
-			{{ if $requestQueryParameters }}
-				var err error
-				query := r.URL.Query()
-				{{ range $requestQueryParameters }}
-					{{ generateReadQueryParameter . }}
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
+				{{ if $requestQueryParameters }}
+					var err error
+					query := r.URL.Query()
+					{{ range $requestQueryParameters }}
+						{{ generateReadQueryParameter . }}
+					{{ end }}
 				{{ end }}
-			{{ end }}
-			return nil
-		}
+				return nil
+			}
+		{{ end }}
 
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			return nil
-		}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				return nil
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			return nil
-		}
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				return nil
+			}
+		{{ end }}
 
-		func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
-			return nil
-		}
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				return nil
+			}
+		{{ end }}
 		`,
 		"Method", method,
 	)
@@ -888,35 +1608,44 @@ func (g *JSONSupportGenerator) generateGetMethodSource(method *concepts.Method)
 	body := method.GetParameter(nomenclator.Body)
 
 	// Generate the code:
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("get_method", `
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
 
-		func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
-			{{ if $requestQueryParameters }}
-				var err error
-				query := r.URL.Query()
-				{{ range $requestQueryParameters }}
-					{{ generateReadQueryParameter . }}
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
+				{{ if $requestQueryParameters }}
+					var err error
+					query := r.URL.Query()
+					{{ range $requestQueryParameters }}
+						{{ generateReadQueryParameter . }}
+					{{ end }}
 				{{ end }}
-			{{ end }}
-			return nil
-		}
+				return nil
+			}
+		{{ end }}
 
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			return nil
-		}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				return nil
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			var err error
-			response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
-			return err
-		}
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				var err error
+				response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
+				return err
+			}
+		{{ end }}
 
-		func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
-			return {{ marshalTypeFunc .Body.Type }}(response.body, w)
-		}
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				return {{ marshalTypeFunc .Body.Type }}(response.body, w)
+			}
+		{{ end }}
 		`,
 		"Method", method,
 		"Body", body,
@@ -947,96 +1676,104 @@ func (g *JSONSupportGenerator) generateListMethodSource(method *concepts.Method)
 	}
 
 	// Generate the code:
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("list_method", `
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
 
-		func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
-			{{ if $requestQueryParameters }}
-				var err error
-				query := r.URL.Query()
-				{{ range $requestQueryParameters }}
-					{{ generateReadQueryParameter . }}
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
+				{{ if $requestQueryParameters }}
+					var err error
+					query := r.URL.Query()
+					{{ range $requestQueryParameters }}
+						{{ generateReadQueryParameter . }}
+					{{ end }}
 				{{ end }}
-			{{ end }}
-			return nil
-		}
+				return nil
+			}
+		{{ end }}
 
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			return nil
-		}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				return nil
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			iterator, err := helpers.NewIterator(reader)
-			if err != nil {
-				return err
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				iterator, err := helpers.NewIterator(reader)
+				if err != nil {
+					return err
+				}
+				for {
+					field := iterator.ReadObject()
+					if field == "" {
+						break
+					}
+					switch field {
+					{{ if .Page }}
+						{{ generateReadBodyParameter "response" .Page }}
+					{{ end }}
+					{{ if .Size }}
+						{{ generateReadBodyParameter "response" .Size }}
+					{{ end }}
+					{{ if .Total }}
+						{{ generateReadBodyParameter "response" .Total }}
+					{{ end }}
+					{{ range .Other }}
+						{{ if .Out }}
+							{{ generateReadBodyParameter "response" . }}
+						{{ end }}
+					{{ end }}
+					case "items":
+						{{ generateReadValue "items" .Items.Type false }}
+						response.items = &{{ structName .Items.Type }}{
+							items: items,
+						}
+					default:
+						iterator.ReadAny()
+					}
+				}
+				return iterator.Error
 			}
-			for {
-				field := iterator.ReadObject()
-				if field == "" {
-					break
+		{{ end }}
+
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				stream := helpers.BorrowStream(w)
+				stream.WriteObjectStart()
+				stream.WriteObjectField("kind")
+				count := 1
+				stream.WriteString({{ structName .Items.Type }}Kind)
+				if response.items != nil && response.items.href != nil {
+					stream.WriteMore()
+					stream.WriteObjectField("href")
+					stream.WriteString(*response.items.href)
+					count++
 				}
-				switch field {
 				{{ if .Page }}
-					{{ generateReadBodyParameter "response" .Page }}
+					{{ generateWriteBodyParameter "response" .Page }}
 				{{ end }}
 				{{ if .Size }}
-					{{ generateReadBodyParameter "response" .Size }}
+					{{ generateWriteBodyParameter "response" .Size }}
 				{{ end }}
 				{{ if .Total }}
-					{{ generateReadBodyParameter "response" .Total }}
+					{{ generateWriteBodyParameter "response" .Total }}
 				{{ end }}
 				{{ range .Other }}
 					{{ if .Out }}
-						{{ generateReadBodyParameter "response" . }}
+						{{ generateWriteBodyParameter "response" . }}
 					{{ end }}
 				{{ end }}
-				case "items":
-					{{ generateReadValue "items" .Items.Type false }}
-					response.items = &{{ structName .Items.Type }}{
-						items: items,
-					}
-				default:
-					iterator.ReadAny()
+				if response.items != nil {
+					{{ generateWriteBodyParameter "response.items" .Items }}
 				}
+				stream.WriteObjectEnd()
+				return helpers.ReturnStream(stream)
 			}
-			return iterator.Error
-		}
-
-		func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
-			stream := helpers.NewStream(w)
-			stream.WriteObjectStart()
-			stream.WriteObjectField("kind")
-			count := 1
-			stream.WriteString({{ structName .Items.Type }}Kind)
-			if response.items != nil && response.items.href != nil {
-				stream.WriteMore()
-				stream.WriteObjectField("href")
-				stream.WriteString(*response.items.href)
-				count++
-			}
-			{{ if .Page }}
-				{{ generateWriteBodyParameter "response" .Page }}
-			{{ end }}
-			{{ if .Size }}
-				{{ generateWriteBodyParameter "response" .Size }}
-			{{ end }}
-			{{ if .Total }}
-				{{ generateWriteBodyParameter "response" .Total }}
-			{{ end }}
-			{{ range .Other }}
-				{{ if .Out }}
-					{{ generateWriteBodyParameter "response" . }}
-				{{ end }}
-			{{ end }}
-			if response.items != nil {
-				{{ generateWriteBodyParameter "response.items" .Items }}
-			}
-			stream.WriteObjectEnd()
-			stream.Flush()
-			return stream.Error
-		}
+		{{ end }}
 		`,
 		"Version", method.Owner().Owner(),
 		"Method", method,
@@ -1063,44 +1800,53 @@ func (g *JSONSupportGenerator) generatePostMethodSource(method *concepts.Method)
 	}
 
 	// Generate the code:
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
-		func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
-			{{ if .Request }}
-				var err error
-				request.{{ parameterFieldName .Request }}, err = {{ unmarshalTypeFunc .Request.Type }}(r) 
-				return err
-			{{ else }}
-				return nil
-			{{ end }}
-		}
+	g.buffer.EmitTemplate("post_method", `
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
+				{{ if .Request }}
+					var err error
+					request.{{ parameterFieldName .Request }}, err = {{ unmarshalTypeFunc .Request.Type }}(r) 
+					return err
+				{{ else }}
+					return nil
+				{{ end }}
+			}
+		{{ end }}
 
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			{{ if .Request }}
-				return {{ marshalTypeFunc .Request.Type }}(request.{{ parameterFieldName .Request }}, writer)
-			{{ else }}
-				return nil
-			{{ end }}
-		}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				{{ if .Request }}
+					return {{ marshalTypeFunc .Request.Type }}(request.{{ parameterFieldName .Request }}, writer)
+				{{ else }}
+					return nil
+				{{ end }}
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			{{ if .Response }}
-				var err error
-				response.{{ parameterFieldName .Response }}, err = {{ unmarshalTypeFunc .Response.Type }}(reader)
-				return err
-			{{ else }}
-				return nil
-			{{ end }}
-		}
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				{{ if .Response }}
+					var err error
+					response.{{ parameterFieldName .Response }}, err = {{ unmarshalTypeFunc .Response.Type }}(reader)
+					return err
+				{{ else }}
+					return nil
+				{{ end }}
+			}
+		{{ end }}
 
-		func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
-			{{ if .Response }}
-				return {{ marshalTypeFunc .Response.Type }}(response.{{ parameterFieldName .Response }}, w)
-			{{ else }}
-				return nil
-			{{ end }}
-		}
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				{{ if .Response }}
+					return {{ marshalTypeFunc .Response.Type }}(response.{{ parameterFieldName .Response }}, w)
+				{{ else }}
+					return nil
+				{{ end }}
+			}
+		{{ end }}
 		`,
 		"Method", method,
 		"Request", request,
@@ -1113,144 +1859,208 @@ func (g *JSONSupportGenerator) generateUpdateMethodSource(method *concepts.Metho
 	body := method.GetParameter(nomenclator.Body)
 
 	// Generate the code:
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("update_method", `
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
 
-		func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
-			var err error
-			{{ if $requestQueryParameters }}
-				query := r.URL.Query()
-				{{ range $requestQueryParameters }}
-					{{ generateReadQueryParameter . }}
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ serverRequestName .Method }}, r *http.Request) error {
+				var err error
+				{{ if $requestQueryParameters }}
+					query := r.URL.Query()
+					{{ range $requestQueryParameters }}
+						{{ generateReadQueryParameter . }}
+					{{ end }}
 				{{ end }}
-			{{ end }}
-			request.body, err = {{ unmarshalTypeFunc .Body.Type }}(r.Body)
-			return err
-		}
+				request.body, err = {{ unmarshalTypeFunc .Body.Type }}(r.Body)
+				if err != nil {
+					return err
+				}
+				{{ if .Validate }}
+					request.body = request.body.withoutReadOnly()
+					var errs helpers.ValidationErrors
+					request.body.validate("", &errs)
+					if len(errs) > 0 {
+						return errs
+					}
+				{{ end }}
+				return nil
+			}
+		{{ end }}
 
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			return {{ marshalTypeFunc .Body.Type }}(request.body, writer)
-		}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				{{ if .Validate }}
+					return {{ marshalTypeFunc .Body.Type }}(request.body.withoutReadOnly(), writer)
+				{{ else }}
+					return {{ marshalTypeFunc .Body.Type }}(request.body, writer)
+				{{ end }}
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			var err error
-			response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
-			return err
-		}
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				var err error
+				response.body, err = {{ unmarshalTypeFunc .Body.Type }}(reader)
+				return err
+			}
+		{{ end }}
 
-		func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
-			return {{ marshalTypeFunc .Body.Type }}(response.body, w)
-		}
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ serverResponseName .Method }}, w http.ResponseWriter) error {
+				return {{ marshalTypeFunc .Body.Type }}(response.body, w)
+			}
+		{{ end }}
 		`,
 		"Method", method,
 		"Body", body,
+		"Validate", body.Type().Owner() == method.Owner().Owner(),
 	)
 }
 
 func (g *JSONSupportGenerator) generateActionMethodSource(method *concepts.Method) {
+	// The objects received by bulk add methods are processed like the body of the add methods:
+	// the read only attributes are removed and the rest are validated.
+	var validated []*concepts.Parameter
+	if method.IsBulkAdd() {
+		for _, parameter := range method.Parameters() {
+			typ := parameter.Type()
+			if parameter.In() && typ.IsList() && typ.Element().IsStruct() &&
+				typ.Element().Owner() == method.Owner().Owner() {
+				validated = append(validated, parameter)
+			}
+		}
+	}
+	if len(validated) > 0 {
+		g.buffer.Import("fmt", "")
+	}
+
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("action_method", `
 		{{ $requestBodyParameters := requestBodyParameters .Method }}
 		{{ $requestQueryParameters := requestQueryParameters .Method }}
 		{{ $responseBodyParameters := responseBodyParameters .Method }}
 		{{ $serverRequestName := serverRequestName .Method }}
 		{{ $serverResponseName := serverResponseName .Method }}
 
-		func {{ readRequestFunc .Method }}(request *{{ $serverRequestName }}, r *http.Request) error {
-			{{ if $requestQueryParameters }}
-				var err error
-				query := r.URL.Query()
-				{{ range  $requestQueryParameters }}
-					{{ generateReadQueryParameter . }}
+		{{ if servers }}
+			func {{ readRequestFunc .Method }}(request *{{ $serverRequestName }}, r *http.Request) error {
+				{{ if $requestQueryParameters }}
+					var err error
+					query := r.URL.Query()
+					{{ range  $requestQueryParameters }}
+						{{ generateReadQueryParameter . }}
+					{{ end }}
 				{{ end }}
-			{{ end }}
-			{{ if $requestBodyParameters }}
-				iterator, err := helpers.NewIterator(r.Body)
-				if err != nil {
-					return err
-				}
-				for {
-					field := iterator.ReadObject()
-					if field == "" {
-						break
+				{{ if $requestBodyParameters }}
+					iterator, err := helpers.NewIterator(r.Body)
+					if err != nil {
+						return err
 					}
-					switch field {
-					{{ range $requestBodyParameters }}
-						{{ generateReadBodyParameter "request" . }}
+					for {
+						field := iterator.ReadObject()
+						if field == "" {
+							break
+						}
+						switch field {
+						{{ range $requestBodyParameters }}
+							{{ generateReadBodyParameter "request" . }}
+						{{ end }}
+						default:
+							iterator.ReadAny()
+						}
+					}
+					err = iterator.Error
+					if err != nil {
+						return err
+					}
+				{{ end }}
+				{{ if .Validated }}
+					var errs helpers.ValidationErrors
+					{{ range .Validated }}
+						{{ $field := parameterFieldName . }}
+						{{ $tag := parameterFieldTag . }}
+						for i, item := range request.{{ $field }} {
+							item = item.withoutReadOnly()
+							item.validate(fmt.Sprintf("{{ $tag }}[%d].", i), &errs)
+							request.{{ $field }}[i] = item
+						}
 					{{ end }}
-					default:
-						iterator.ReadAny()
+					if len(errs) > 0 {
+						return errs
 					}
-				}
-				err = iterator.Error
-				if err != nil {
-					return err
-				}
-			{{ end }}
-			return nil
-		}
-
-		func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
-			{{ if $requestBodyParameters }} 
-				count := 0
-				stream := helpers.NewStream(writer)
-				stream.WriteObjectStart()
-				{{ range $requestBodyParameters }}
-					{{ generateWriteBodyParameter "request" . }}
 				{{ end }}
-				stream.WriteObjectEnd()
-				stream.Flush()
-				return stream.Error
-			{{ else }}
 				return nil
-			{{ end }}
-		}
+			}
+		{{ end }}
 
-		func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
-			{{ if $responseBodyParameters }} 
-				iterator, err := helpers.NewIterator(reader)
-				if err != nil {
-					return err
-				}
-				for {
-					field := iterator.ReadObject()
-					if field == "" {
-						break
-					}
-					switch field {
-					{{ range $responseBodyParameters }}
-						{{ generateReadBodyParameter "response" . }}
+		{{ if clients }}
+			func {{ writeRequestFunc .Method }}(request *{{ clientRequestName .Method }}, writer io.Writer) error {
+				{{ if $requestBodyParameters }} 
+					count := 0
+					stream := helpers.BorrowStream(writer)
+					stream.WriteObjectStart()
+					{{ range $requestBodyParameters }}
+						{{ generateWriteBodyParameter "request" . }}
 					{{ end }}
-					default:
-						iterator.ReadAny()
+					stream.WriteObjectEnd()
+					return helpers.ReturnStream(stream)
+				{{ else }}
+					return nil
+				{{ end }}
+			}
+		{{ end }}
+
+		{{ if clients }}
+			func {{ readResponseFunc .Method }}(response *{{ clientResponseName .Method }}, reader io.Reader) error {
+				{{ if $responseBodyParameters }} 
+					iterator, err := helpers.NewIterator(reader)
+					if err != nil {
+						return err
 					}
-				}
-				return iterator.Error
-			{{ else }}
-				return nil
-			{{ end }}
-		}
+					for {
+						field := iterator.ReadObject()
+						if field == "" {
+							break
+						}
+						switch field {
+						{{ range $responseBodyParameters }}
+							{{ generateReadBodyParameter "response" . }}
+						{{ end }}
+						default:
+							iterator.ReadAny()
+						}
+					}
+					return iterator.Error
+				{{ else }}
+					return nil
+				{{ end }}
+			}
+		{{ end }}
 
-		func {{ writeResponseFunc .Method }}(response *{{ $serverResponseName }}, w http.ResponseWriter) error {
-			{{ if $responseBodyParameters }} 
-				count := 0
-				stream := helpers.NewStream(w)
-				stream.WriteObjectStart()
-				{{ range $responseBodyParameters }}
-					{{ generateWriteBodyParameter "response" . }}
+		{{ if servers }}
+			func {{ writeResponseFunc .Method }}(response *{{ $serverResponseName }}, w http.ResponseWriter) error {
+				{{ if $responseBodyParameters }} 
+					count := 0
+					stream := helpers.BorrowStream(w)
+					stream.WriteObjectStart()
+					{{ range $responseBodyParameters }}
+						{{ generateWriteBodyParameter "response" . }}
+					{{ end }}
+					stream.WriteObjectEnd()
+					return helpers.ReturnStream(stream)
+				{{ else }}
+					return nil
 				{{ end }}
-				stream.WriteObjectEnd()
-				stream.Flush()
-				return stream.Error
-			{{ else }}
-				return nil
-			{{ end }}
-		}
+			}
+		{{ end }}
 		`,
 		"Method", method,
+		"Validated", validated,
 	)
 }
 
@@ -1292,6 +2102,12 @@ func (g *JSONSupportGenerator) generateReadAttribute(field, tag string, typ *con
 	link bool) string {
 	return g.buffer.Eval(`
 		case "{{ .Tag }}":
+			{{ if or .Type.IsMap (and .Type.IsList (not .Link)) }}
+				if iterator.ReadNil() {
+					object.{{ .Field }} = nil
+					break
+				}
+			{{ end }}
 			{{ generateReadValue "value" .Type .Link }}
 			{{ if .Type.IsScalar }}
 				object.{{ .Field }} = &value
@@ -1306,6 +2122,37 @@ func (g *JSONSupportGenerator) generateReadAttribute(field, tag string, typ *con
 	)
 }
 
+// generateReadDateAttribute generates the code that reads a date attribute that uses a format
+// different to the default.
+func (g *JSONSupportGenerator) generateReadDateAttribute(field, tag, format string) string {
+	g.buffer.Import("time", "")
+	return g.buffer.Eval(`
+		case "{{ .Tag }}":
+			{{ if eq .Format "unix" }}
+				value := time.Unix(iterator.ReadInt64(), 0).UTC()
+			{{ else if eq .Format "unix_millis" }}
+				value := time.Unix(0, iterator.ReadInt64()*int64(time.Millisecond)).UTC()
+			{{ else }}
+				text := iterator.ReadString()
+				{{ if eq .Format "date" }}
+					value, err := time.Parse("2006-01-02", text)
+				{{ else if eq .Format "rfc3339nano" }}
+					value, err := time.Parse(time.RFC3339Nano, text)
+				{{ else }}
+					value, err := time.Parse(time.RFC3339, text)
+				{{ end }}
+				if err != nil {
+					iterator.ReportError("", err.Error())
+				}
+			{{ end }}
+			object.{{ .Field }} = &value
+		`,
+		"Field", field,
+		"Tag", tag,
+		"Format", format,
+	)
+}
+
 func (g *JSONSupportGenerator) generateReadBodyParameter(object string, parameter *concepts.
 	Parameter) string {
 	field := g.parameterFieldName(parameter)
@@ -1329,13 +2176,22 @@ func (g *JSONSupportGenerator) generateReadBodyParameter(object string, paramete
 
 func (g *JSONSupportGenerator) generateReadValue(variable string, typ *concepts.Type, link bool) string {
 	g.buffer.Import("time", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
 	return g.buffer.Eval(`
 		{{ if .Type.IsBoolean }}
 			{{ .Variable }} := iterator.ReadBool()
 		{{ else if .Type.IsInteger }}
-			{{ .Variable }} := iterator.ReadInt()
+			{{ if .TolerantNumbers }}
+				{{ .Variable }} := helpers.ReadInteger(iterator)
+			{{ else }}
+				{{ .Variable }} := iterator.ReadInt()
+			{{ end }}
 		{{ else if .Type.IsLong }}
-			{{ .Variable }} := iterator.ReadInt64()
+			{{ if or .TolerantNumbers .LongsAsStrings }}
+				{{ .Variable }} := helpers.ReadLong(iterator)
+			{{ else }}
+				{{ .Variable }} := iterator.ReadInt64()
+			{{ end }}
 		{{ else if .Type.IsFloat }}
 			{{ .Variable }} := iterator.ReadFloat64()
 		{{ else if .Type.IsString }}
@@ -1346,6 +2202,8 @@ func (g *JSONSupportGenerator) generateReadValue(variable string, typ *concepts.
 			if err != nil {
 				iterator.ReportError("", err.Error())
 			}
+		{{ else if .Type.IsInterface }}
+			{{ .Variable }} := iterator.Read()
 		{{ else if .Type.IsEnum }}
 			text := iterator.ReadString()
 			{{ .Variable }} := {{ enumName .Type }}(text)
@@ -1393,9 +2251,76 @@ func (g *JSONSupportGenerator) generateReadValue(variable string, typ *concepts.
 		"Variable", variable,
 		"Type", typ,
 		"Link", link,
+		"TolerantNumbers", g.tolerantNumbers,
+		"LongsAsStrings", g.longsAsStrings,
 	)
 }
 
+// generateWriteAttributes generates the code that writes all the attributes of the given struct
+// type, including the kind, identifier and link of classes. When the canonical mode is enabled
+// the attributes are written sorted by their JSON names, so that the output is deterministic.
+func (g *JSONSupportGenerator) generateWriteAttributes(typ *concepts.Type) string {
+	type snippet struct {
+		tag  string
+		code string
+	}
+	var snippets []snippet
+	if typ.IsClass() {
+		snippets = append(snippets, snippet{
+			tag: "kind",
+			code: g.buffer.Eval(`
+				if count > 0 {
+					stream.WriteMore()
+				}
+				stream.WriteObjectField("kind")
+				if object.link {
+					stream.WriteString({{ .Struct }}LinkKind)
+				} else {
+					stream.WriteString({{ .Struct }}Kind)
+				}
+				count++
+				`,
+				"Struct", g.types.StructName(typ),
+			),
+		})
+		stringType := typ.Owner().StringType()
+		snippets = append(snippets, snippet{
+			tag:  "id",
+			code: g.generateWriteAttribute("id", "id", stringType, false),
+		})
+		snippets = append(snippets, snippet{
+			tag:  "href",
+			code: g.generateWriteAttribute("href", "href", stringType, false),
+		})
+	}
+	for _, attribute := range typ.Attributes() {
+		field := g.attributeFieldName(attribute)
+		tag := g.binding.AttributeName(attribute)
+		var code string
+		if attribute.Format() != "" {
+			code = g.generateWriteDateAttribute(field, tag, attribute.Format())
+		} else if attribute.Always() {
+			code = g.generateWriteAlwaysAttribute(field, tag, attribute.Type())
+		} else {
+			code = g.generateWriteAttribute(field, tag, attribute.Type(), attribute.Link())
+		}
+		snippets = append(snippets, snippet{
+			tag:  tag,
+			code: code,
+		})
+	}
+	if g.canonicalJSON {
+		sort.SliceStable(snippets, func(i, j int) bool {
+			return snippets[i].tag < snippets[j].tag
+		})
+	}
+	var buffer strings.Builder
+	for _, snippet := range snippets {
+		buffer.WriteString(snippet.code)
+	}
+	return buffer.String()
+}
+
 func (g *JSONSupportGenerator) generateWriteAttribute(field, tag string, typ *concepts.Type,
 	link bool) string {
 	var value string
@@ -1428,6 +2353,60 @@ func (g *JSONSupportGenerator) generateWriteAttribute(field, tag string, typ *co
 	)
 }
 
+// generateWriteAlwaysAttribute generates the code that writes a scalar attribute that is written
+// even if it hasn't been set, using the zero value of the type in that case.
+func (g *JSONSupportGenerator) generateWriteAlwaysAttribute(field, tag string,
+	typ *concepts.Type) string {
+	return g.buffer.Eval(`
+		if count > 0 {
+			stream.WriteMore()
+		}
+		stream.WriteObjectField("{{ .Tag }}")
+		if object.{{ .Field }} != nil {
+			{{ generateWriteValue .Value .Type false }}
+		} else {
+			{{ generateWriteValue .Zero .Type false }}
+		}
+		count++
+		`,
+		"Field", field,
+		"Tag", tag,
+		"Value", "*object."+field,
+		"Zero", g.types.ZeroValue(typ),
+		"Type", typ,
+	)
+}
+
+// generateWriteDateAttribute generates the code that writes a date attribute that uses a format
+// different to the default.
+func (g *JSONSupportGenerator) generateWriteDateAttribute(field, tag, format string) string {
+	g.buffer.Import("time", "")
+	return g.buffer.Eval(`
+		if object.{{ .Field }} != nil {
+			if count > 0 {
+				stream.WriteMore()
+			}
+			stream.WriteObjectField("{{ .Tag }}")
+			{{ if eq .Format "unix" }}
+				stream.WriteInt64(object.{{ .Field }}.Unix())
+			{{ else if eq .Format "unix_millis" }}
+				stream.WriteInt64(object.{{ .Field }}.UnixNano() / int64(time.Millisecond))
+			{{ else if eq .Format "date" }}
+				stream.WriteString(object.{{ .Field }}.Format("2006-01-02"))
+			{{ else if eq .Format "rfc3339nano" }}
+				stream.WriteString(object.{{ .Field }}.Format(time.RFC3339Nano))
+			{{ else }}
+				stream.WriteString(object.{{ .Field }}.Format(time.RFC3339))
+			{{ end }}
+			count++
+		}
+		`,
+		"Field", field,
+		"Tag", tag,
+		"Format", format,
+	)
+}
+
 func (g *JSONSupportGenerator) generateWriteBodyParameter(object string,
 	parameter *concepts.Parameter) string {
 	typ := parameter.Type()
@@ -1467,6 +2446,7 @@ func (g *JSONSupportGenerator) generateWriteBodyParameter(object string,
 
 func (g *JSONSupportGenerator) generateWriteValue(value string, typ *concepts.Type, link bool) string {
 	g.buffer.Import("sort", "")
+	g.buffer.Import("strconv", "")
 	g.buffer.Import("time", "")
 	return g.buffer.Eval(`
 		{{ if .Type.IsBoolean }}
@@ -1474,13 +2454,23 @@ func (g *JSONSupportGenerator) generateWriteValue(value string, typ *concepts.Ty
 		{{ else if .Type.IsInteger }}
 			stream.WriteInt({{ .Value }})
 		{{ else if .Type.IsLong }}
-			stream.WriteInt64({{ .Value }})
+			{{ if .LongsAsStrings }}
+				stream.WriteString(strconv.FormatInt({{ .Value }}, 10))
+			{{ else }}
+				stream.WriteInt64({{ .Value }})
+			{{ end }}
 		{{ else if .Type.IsFloat }}
-			stream.WriteFloat64({{ .Value }})
+			{{ if .Canonical }}
+				helpers.WriteCanonicalFloat(stream, {{ .Value }})
+			{{ else }}
+				stream.WriteFloat64({{ .Value }})
+			{{ end }}
 		{{ else if .Type.IsString }}
 			stream.WriteString({{ .Value }})
 		{{ else if .Type.IsDate }}
 			stream.WriteString(({{ .Value }}).Format(time.RFC3339))
+		{{ else if .Type.IsInterface }}
+			stream.WriteVal({{ .Value }})
 		{{ else if .Type.IsEnum }}
 			stream.WriteString(string({{ .Value }}))
 		{{ else if .Type.IsStruct }}
@@ -1517,6 +2507,8 @@ func (g *JSONSupportGenerator) generateWriteValue(value string, typ *concepts.Ty
 		"Value", value,
 		"Type", typ,
 		"Link", link,
+		"LongsAsStrings", g.longsAsStrings,
+		"Canonical", g.canonicalJSON,
 	)
 }
 
@@ -1524,6 +2516,18 @@ func (g *JSONSupportGenerator) helpersFile() string {
 	return g.names.File(names.Cat(nomenclator.JSON, nomenclator.Helpers))
 }
 
+func (g *JSONSupportGenerator) eventsFile() string {
+	return g.names.File(names.Cat(nomenclator.Event, nomenclator.Dispatcher))
+}
+
+func (g *JSONSupportGenerator) handleEventMethod(event *concepts.Event) string {
+	return g.names.Public(names.Cat(nomenclator.Handle, event.Name()))
+}
+
+func (g *JSONSupportGenerator) kindsFile() string {
+	return g.names.File(nomenclator.Kinds)
+}
+
 func (g *JSONSupportGenerator) metadataFile() string {
 	return g.names.File(names.Cat(nomenclator.Metadata, nomenclator.Reader))
 }
@@ -1556,12 +2560,25 @@ func (g *JSONSupportGenerator) readTypeFunc(typ *concepts.Type) string {
 	return g.names.Private(name)
 }
 
+func (g *JSONSupportGenerator) decodeFunc(attribute *concepts.Attribute) string {
+	name := names.Cat(nomenclator.Decode, attribute.Name())
+	return g.names.Private(name)
+}
+
 func (g *JSONSupportGenerator) attributeFieldName(attribute *concepts.Attribute) string {
 	return g.names.Private(attribute.Name())
 }
 
 func (g *JSONSupportGenerator) parameterFieldName(parameter *concepts.Parameter) string {
-	return g.names.Private(parameter.Name())
+	return g.names.Field(parameter.Name())
+}
+
+func (g *JSONSupportGenerator) generateClients() bool {
+	return g.clients
+}
+
+func (g *JSONSupportGenerator) generateServers() bool {
+	return g.servers
 }
 
 func (g *JSONSupportGenerator) clientRequestName(method *concepts.Method) string {
@@ -0,0 +1,905 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package golang
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// JSONTestsGeneratorBuilder is an object used to configure and build the JSON tests generator.
+// Don't create instances directly, use the NewJSONTestsGenerator function instead.
+type JSONTestsGeneratorBuilder struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	binding   *http.BindingCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	longs     bool
+	raw       bool
+}
+
+// JSONTestsGenerator generates golden JSON files for the struct types, tests that check that
+// those files are read and written back without changes, fuzz targets for the JSON readers and
+// benchmarks for the JSON readers and writers. Don't create instances directly, use the builder
+// instead.
+type JSONTestsGenerator struct {
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	binding   *http.BindingCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	longs     bool
+	raw       bool
+	buffer    *Buffer
+}
+
+// NewJSONTestsGenerator creates a new builder for JSON tests generators.
+func NewJSONTestsGenerator() *JSONTestsGeneratorBuilder {
+	return &JSONTestsGeneratorBuilder{}
+}
+
+// Reporter sets the object that will be used to report information about the generation process,
+// including errors.
+func (b *JSONTestsGeneratorBuilder) Reporter(value *reporter.Reporter) *JSONTestsGeneratorBuilder {
+	b.reporter = value
+	return b
+}
+
+// Model sets the model that will be used by the JSON tests generator.
+func (b *JSONTestsGeneratorBuilder) Model(value *concepts.Model) *JSONTestsGeneratorBuilder {
+	b.model = value
+	return b
+}
+
+// Output sets the directory where the source will be generated.
+func (b *JSONTestsGeneratorBuilder) Output(value string) *JSONTestsGeneratorBuilder {
+	b.output = value
+	return b
+}
+
+// Packages sets the object that will be used to calculate package names.
+func (b *JSONTestsGeneratorBuilder) Packages(value *PackagesCalculator) *JSONTestsGeneratorBuilder {
+	b.packages = value
+	return b
+}
+
+// Names sets the object that will be used to calculate names.
+func (b *JSONTestsGeneratorBuilder) Names(value *NamesCalculator) *JSONTestsGeneratorBuilder {
+	b.names = value
+	return b
+}
+
+// Binding sets the object that will by used to do HTTP binding calculations.
+func (b *JSONTestsGeneratorBuilder) Binding(value *http.BindingCalculator) *JSONTestsGeneratorBuilder {
+	b.binding = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *JSONTestsGeneratorBuilder) Header(value *Header) *JSONTestsGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *JSONTestsGeneratorBuilder) Stream(value bool) *JSONTestsGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *JSONTestsGeneratorBuilder) MaxLines(value int) *JSONTestsGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *JSONTestsGeneratorBuilder) Templates(value *Templates) *JSONTestsGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
+// LongsAsStrings sets the flag that indicates if the JSON writers encode long values as strings.
+// It must be the same used for the JSON support generator, so that the golden files contain the
+// same text that the writers produce. The default is false.
+func (b *JSONTestsGeneratorBuilder) LongsAsStrings(value bool) *JSONTestsGeneratorBuilder {
+	b.longs = value
+	return b
+}
+
+// RawAttributes sets the flag that indicates if the generated types keep the JSON text of their
+// attributes. It must be the same used for the JSON support generator. The default is false.
+func (b *JSONTestsGeneratorBuilder) RawAttributes(value bool) *JSONTestsGeneratorBuilder {
+	b.raw = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// JSON tests generator using it.
+func (b *JSONTestsGeneratorBuilder) Build() (generator *JSONTestsGenerator, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.model == nil {
+		err = fmt.Errorf("model is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output is mandatory")
+		return
+	}
+	if b.packages == nil {
+		err = fmt.Errorf("packages calculator is mandatory")
+		return
+	}
+	if b.names == nil {
+		err = fmt.Errorf("names calculator is mandatory")
+		return
+	}
+	if b.binding == nil {
+		err = fmt.Errorf("binding calculator is mandatory")
+		return
+	}
+
+	// Create the generator:
+	generator = &JSONTestsGenerator{
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		binding:   b.binding,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
+		longs:     b.longs,
+		raw:       b.raw,
+	}
+
+	return
+}
+
+// Run executes the code generator.
+func (g *JSONTestsGenerator) Run() error {
+	var err error
+
+	// Generate the tests and the golden files for each version:
+	for _, service := range g.model.Services() {
+		for _, version := range service.Versions() {
+			err = g.generateVersionTests(version)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *JSONTestsGenerator) generateVersionTests(version *concepts.Version) error {
+	var err error
+
+	// Find the types that have JSON readers:
+	var structs, lists []*concepts.Type
+	for _, typ := range version.Types() {
+		switch {
+		case typ.IsStruct():
+			structs = append(structs, typ)
+		case typ.IsList():
+			element := typ.Element()
+			if element.IsScalar() || element.IsStruct() {
+				lists = append(lists, typ)
+			}
+		}
+	}
+
+	// Generate the round trip tests:
+	if len(structs) > 0 {
+		err = g.generateRoundTripTests(version, structs)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Generate the fuzz targets:
+	err = g.generateFuzzTests(version, append(structs, lists...))
+	if err != nil {
+		return err
+	}
+
+	// Generate the benchmarks, excluding the lists whose elements don't have a sample value:
+	var samples []*concepts.Type
+	for _, list := range lists {
+		_, ok := g.sample(list.Element())
+		if ok {
+			samples = append(samples, list)
+		}
+	}
+	if len(structs) == 0 && len(samples) == 0 {
+		return nil
+	}
+	return g.generateBenchmarks(version, structs, samples)
+}
+
+func (g *JSONTestsGenerator) generateRoundTripTests(version *concepts.Version,
+	types []*concepts.Type) error {
+	var err error
+
+	// Write the golden files:
+	for _, typ := range types {
+		err = g.writeGoldenFile(typ)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.roundTripFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json_tests").
+		Package(pkgName).
+		File(fileName).
+		Function("goldenFile", g.goldenFile).
+		Function("keepsJSON", g.keepsJSON).
+		Function("marshalTypeFunc", g.marshalTypeFunc).
+		Function("testFunc", g.testFunc).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateTestsSource(types)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *JSONTestsGenerator) generateTestsSource(types []*concepts.Type) {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("path/filepath", "")
+	g.buffer.Import("reflect", "")
+	g.buffer.Import("testing", "")
+	g.buffer.EmitTemplate("tests", `
+		{{ range .Types }}
+			{{ $goldenFile := goldenFile . }}
+			{{ $unmarshalTypeFunc := unmarshalTypeFunc . }}
+
+			// {{ testFunc . }} checks that the value of the '{{ .Name }}' type stored in
+			// the 'testdata/{{ $goldenFile }}' golden file is written back without changes.
+			func {{ testFunc . }}(t *testing.T) {
+				golden := readGoldenFile(t, "{{ $goldenFile }}")
+				object, err := {{ $unmarshalTypeFunc }}(golden)
+				if err != nil {
+					t.Fatalf("Can't unmarshal golden file: %v", err)
+				}
+				buffer := &bytes.Buffer{}
+				err = {{ marshalTypeFunc . }}(object, buffer)
+				if err != nil {
+					t.Fatalf("Can't marshal object: %v", err)
+				}
+				checkSameJSON(t, golden, buffer.Bytes())
+				again, err := {{ $unmarshalTypeFunc }}(buffer.Bytes())
+				if err != nil {
+					t.Fatalf("Can't unmarshal marshalled object: %v", err)
+				}
+				{{ if keepsJSON . }}
+					// The objects keep part of the JSON text that they were read from, and
+					// that text is formatted differently in the golden file, so they are
+					// compared writing them again:
+					second := &bytes.Buffer{}
+					err = {{ marshalTypeFunc . }}(again, second)
+					if err != nil {
+						t.Fatalf("Can't marshal unmarshalled object: %v", err)
+					}
+					checkSameJSON(t, buffer.Bytes(), second.Bytes())
+				{{ else }}
+					if !reflect.DeepEqual(object, again) {
+						t.Errorf("Unmarshalled object is different to the original one")
+					}
+				{{ end }}
+			}
+		{{ end }}
+
+		// readGoldenFile reads the golden file with the given name from the 'testdata'
+		// directory.
+		func readGoldenFile(t testing.TB, name string) []byte {
+			data, err := ioutil.ReadFile(filepath.Join("testdata", name))
+			if err != nil {
+				t.Fatalf("Can't read golden file '%s': %v", name, err)
+			}
+			return data
+		}
+
+		// checkSameJSON checks that the given JSON documents are equivalent, ignoring
+		// formatting and the order of fields.
+		func checkSameJSON(t *testing.T, expected, actual []byte) {
+			var expectedValue, actualValue interface{}
+			err := json.Unmarshal(expected, &expectedValue)
+			if err != nil {
+				t.Fatalf("Can't parse expected JSON: %v", err)
+			}
+			err = json.Unmarshal(actual, &actualValue)
+			if err != nil {
+				t.Fatalf("Can't parse actual JSON: %v", err)
+			}
+			if !reflect.DeepEqual(expectedValue, actualValue) {
+				t.Errorf("Expected JSON:\n%s\nActual JSON:\n%s", expected, actual)
+			}
+		}
+		`,
+		"Types", types,
+	)
+}
+
+func (g *JSONTestsGenerator) generateFuzzTests(version *concepts.Version,
+	types []*concepts.Type) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.fuzzFile()
+
+	// Create the buffer for the generated code. Fuzz targets are only supported since Go 1.18,
+	// so the file needs a build constraint to not break older versions.
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json_tests").
+		Package(pkgName).
+		File(fileName).
+		Constraint("go1.18").
+		Function("fuzzFunc", g.fuzzFunc).
+		Function("sampleJSON", g.sampleJSON).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateFuzzSource(types)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *JSONTestsGenerator) generateFuzzSource(types []*concepts.Type) {
+	g.buffer.Import("testing", "")
+	g.buffer.EmitTemplate("fuzz", `
+		// FuzzUnmarshalMetadata checks that UnmarshalMetadata doesn't panic when it
+		// receives malformed input.
+		func FuzzUnmarshalMetadata(f *testing.F) {
+			addFuzzSeeds(f, "{\"server_version\": \"123\"}")
+			f.Fuzz(func(t *testing.T, data []byte) {
+				UnmarshalMetadata(data)
+			})
+		}
+
+		{{ range .Types }}
+			{{ $unmarshalTypeFunc := unmarshalTypeFunc . }}
+
+			// {{ fuzzFunc . }} checks that {{ $unmarshalTypeFunc }} doesn't panic when it
+			// receives malformed input.
+			func {{ fuzzFunc . }}(f *testing.F) {
+				addFuzzSeeds(f, {{ sampleJSON . }})
+				f.Fuzz(func(t *testing.T, data []byte) {
+					{{ $unmarshalTypeFunc }}(data)
+				})
+			}
+		{{ end }}
+
+		// addFuzzSeeds adds to the corpus the given valid example, some truncated versions of
+		// it, and some documents that are malformed or have unexpected types.
+		func addFuzzSeeds(f *testing.F, example string) {
+			f.Add([]byte(example))
+			for i := 1; i < len(example); i *= 2 {
+				f.Add([]byte(example[:i]))
+			}
+			for _, seed := range fuzzSeeds {
+				f.Add([]byte(seed))
+			}
+		}
+
+		// fuzzSeeds contains documents that are malformed or have unexpected types, added to
+		// the corpus of all the fuzz targets.
+		var fuzzSeeds = []string{
+			"",
+			"null",
+			"{",
+			"[",
+			"{}",
+			"[]",
+			"[{}]",
+			"{\"kind\": 1}",
+			"{\"id\": {}}",
+			"{\"items\": \"\"}",
+			"\"\"",
+			"123",
+		}
+		`,
+		"Types", types,
+	)
+}
+
+func (g *JSONTestsGenerator) generateBenchmarks(version *concepts.Version,
+	structs, lists []*concepts.Type) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.benchmarkFile()
+
+	// Create the buffer for the generated code:
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("json_tests").
+		Package(pkgName).
+		File(fileName).
+		Function("listName", g.listName).
+		Function("marshalTypeFunc", g.marshalTypeFunc).
+		Function("sampleJSON", g.sampleJSON).
+		Function("structName", g.structName).
+		Function("unmarshalTypeFunc", g.unmarshalTypeFunc).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the code:
+	g.generateBenchmarksSource(structs, lists)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *JSONTestsGenerator) generateBenchmarksSource(structs, lists []*concepts.Type) {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("io/ioutil", "")
+	g.buffer.Import("testing", "")
+	g.buffer.EmitTemplate("benchmarks", `
+		{{ range .Structs }}
+			{{ $structName := structName . }}
+			{{ $listName := listName . }}
+			{{ $marshalTypeFunc := marshalTypeFunc . }}
+			{{ $unmarshalTypeFunc := unmarshalTypeFunc . }}
+
+			// Benchmark{{ $marshalTypeFunc }} measures the time and the allocations needed
+			// to write a value of the '{{ .Name }}' type.
+			func Benchmark{{ $marshalTypeFunc }}(b *testing.B) {
+				object, err := {{ $unmarshalTypeFunc }}({{ sampleJSON . }})
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					err = {{ $marshalTypeFunc }}(object, ioutil.Discard)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+
+			// Benchmark{{ $unmarshalTypeFunc }} measures the time and the allocations needed
+			// to read a value of the '{{ .Name }}' type.
+			func Benchmark{{ $unmarshalTypeFunc }}(b *testing.B) {
+				data := []byte({{ sampleJSON . }})
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, err := {{ $unmarshalTypeFunc }}(data)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+
+			// Benchmark{{ $listName }}Each measures the time needed to iterate a list of
+			// values of the '{{ .Name }}' type.
+			func Benchmark{{ $listName }}Each(b *testing.B) {
+				list := &{{ $listName }}{
+					items: make([]*{{ $structName }}, benchmarkListSize),
+				}
+				for i := range list.items {
+					list.items[i] = &{{ $structName }}{}
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					list.Each(func(item *{{ $structName }}) bool {
+						return true
+					})
+				}
+			}
+
+			// Benchmark{{ $listName }}Slice measures the time and the allocations needed to
+			// copy a list of values of the '{{ .Name }}' type to a slice.
+			func Benchmark{{ $listName }}Slice(b *testing.B) {
+				list := &{{ $listName }}{
+					items: make([]*{{ $structName }}, benchmarkListSize),
+				}
+				for i := range list.items {
+					list.items[i] = &{{ $structName }}{}
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					list.Slice()
+				}
+			}
+		{{ end }}
+
+		{{ range .Lists }}
+			{{ $marshalTypeFunc := marshalTypeFunc . }}
+			{{ $unmarshalTypeFunc := unmarshalTypeFunc . }}
+
+			// Benchmark{{ $marshalTypeFunc }} measures the time and the allocations needed
+			// to write a list of values of the '{{ .Element.Name }}' type.
+			func Benchmark{{ $marshalTypeFunc }}(b *testing.B) {
+				list, err := {{ $unmarshalTypeFunc }}(benchmarkArray({{ sampleJSON .Element }}))
+				if err != nil {
+					b.Fatal(err)
+				}
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					err = {{ $marshalTypeFunc }}(list, ioutil.Discard)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+
+			// Benchmark{{ $unmarshalTypeFunc }} measures the time and the allocations needed
+			// to read a list of values of the '{{ .Element.Name }}' type.
+			func Benchmark{{ $unmarshalTypeFunc }}(b *testing.B) {
+				data := benchmarkArray({{ sampleJSON .Element }})
+				b.ReportAllocs()
+				b.ResetTimer()
+				for i := 0; i < b.N; i++ {
+					_, err := {{ $unmarshalTypeFunc }}(data)
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		{{ end }}
+
+		// benchmarkArray returns a JSON array that contains the given item repeated the
+		// number of times given by benchmarkListSize.
+		func benchmarkArray(item string) []byte {
+			buffer := &bytes.Buffer{}
+			buffer.WriteString("[")
+			for i := 0; i < benchmarkListSize; i++ {
+				if i > 0 {
+					buffer.WriteString(",")
+				}
+				buffer.WriteString(item)
+			}
+			buffer.WriteString("]")
+			return buffer.Bytes()
+		}
+
+		// benchmarkListSize is the number of items of the lists used in benchmarks.
+		const benchmarkListSize = 100
+		`,
+		"Structs", structs,
+		"Lists", lists,
+	)
+}
+
+// writeGoldenFile writes the golden JSON file for the given type.
+func (g *JSONTestsGenerator) writeGoldenFile(typ *concepts.Type) error {
+	file := filepath.Join(
+		g.output,
+		g.packages.VersionPackage(typ.Owner()),
+		"testdata",
+		g.goldenFile(typ),
+	)
+	g.reporter.Infof("Writing file '%s'", file)
+	sample := g.sampleObject(typ, []*concepts.Type{typ})
+	data, err := json.MarshalIndent(sample, "", "  ")
+	if err != nil {
+		return fmt.Errorf("can't marshal golden file '%s': %v", file, err)
+	}
+	data = append(data, '\n')
+	dir := filepath.Dir(file)
+	err = os.MkdirAll(dir, 0777)
+	if err != nil {
+		return fmt.Errorf("can't create output directory '%s': %v", dir, err)
+	}
+	err = ioutil.WriteFile(file, data, 0666)
+	if err != nil {
+		return fmt.Errorf("can't write golden file '%s': %v", file, err)
+	}
+	return nil
+}
+
+// keepsJSON returns true if the objects of the given type, or of the types that they contain,
+// keep part of the JSON text that they were read from, for example the text of lazy attributes,
+// or the text of all the attributes when raw attributes are enabled. Those objects can't be
+// compared with reflect.DeepEqual after a round trip, because the text may be formatted
+// differently.
+func (g *JSONTestsGenerator) keepsJSON(typ *concepts.Type) bool {
+	return g.raw || g.reachesLazy(typ, map[*concepts.Type]bool{})
+}
+
+func (g *JSONTestsGenerator) reachesLazy(typ *concepts.Type, visited map[*concepts.Type]bool) bool {
+	if visited[typ] {
+		return false
+	}
+	visited[typ] = true
+	switch {
+	case typ.IsStruct():
+		for _, attribute := range typ.Attributes() {
+			if attribute.Lazy() || g.reachesLazy(attribute.Type(), visited) {
+				return true
+			}
+		}
+	case typ.IsList() || typ.IsMap():
+		return g.reachesLazy(typ.Element(), visited)
+	}
+	return false
+}
+
+// sampleObject calculates the sample value of the given struct type. The stack contains the
+// types that are already being calculated, and it is used to avoid infinite recursion when
+// types reference themselves directly or indirectly.
+func (g *JSONTestsGenerator) sampleObject(typ *concepts.Type,
+	stack []*concepts.Type) map[string]interface{} {
+	object := map[string]interface{}{}
+	if typ.IsClass() {
+		object["kind"] = g.names.Public(typ.Name())
+		object["id"] = sampleID
+		object["href"] = sampleHREF
+	}
+	for _, attribute := range typ.Attributes() {
+		tag := g.binding.AttributeName(attribute)
+		value, ok := g.sampleValue(tag, attribute.Type(), attribute.Link(),
+			attribute.Format(), stack)
+		if ok {
+			object[tag] = value
+		}
+	}
+	return object
+}
+
+// sampleValue calculates the sample value of the given type. The format is the one of the
+// attribute that contains the value, and it is used to write dates in the format that the
+// generated readers expect. The returned flag will be false if no value can be calculated, for
+// example for enumerated types without values, or for types that would result in infinite
+// recursion.
+func (g *JSONTestsGenerator) sampleValue(tag string, typ *concepts.Type, link bool, format string,
+	stack []*concepts.Type) (value interface{}, ok bool) {
+	switch {
+	case typ.IsBoolean():
+		value, ok = true, true
+	case typ.IsInteger():
+		value, ok = 1, true
+	case typ.IsLong():
+		if g.longs {
+			value, ok = "1", true
+		} else {
+			value, ok = int64(1), true
+		}
+	case typ.IsFloat():
+		value, ok = 1.5, true
+	case typ.IsString():
+		value, ok = tag, true
+	case typ.IsDate():
+		value, ok = g.sampleDate(format), true
+	case typ.IsInterface():
+		value, ok = map[string]interface{}{tag: tag}, true
+	case typ.IsEnum():
+		values := typ.Values()
+		if len(values) > 0 {
+			value, ok = g.binding.EnumValueName(values[0]), true
+		}
+	case typ.IsStruct():
+		if link && typ.IsClass() {
+			value = map[string]interface{}{
+				"kind": g.names.Public(typ.Name()) + "Link",
+				"id":   sampleID,
+				"href": sampleHREF,
+			}
+			ok = true
+			break
+		}
+		for _, current := range stack {
+			if current == typ {
+				return
+			}
+		}
+		value, ok = g.sampleObject(typ, append(stack, typ)), true
+	case typ.IsList():
+		var item interface{}
+		item, ok = g.sampleValue(tag, typ.Element(), link, "", stack)
+		if !ok {
+			break
+		}
+		items := []interface{}{item}
+		if link {
+			value = map[string]interface{}{
+				"items": items,
+			}
+		} else {
+			value = items
+		}
+	case typ.IsMap():
+		var item interface{}
+		item, ok = g.sampleValue(tag, typ.Element(), false, "", stack)
+		if !ok {
+			break
+		}
+		value = map[string]interface{}{
+			sampleKey: item,
+		}
+	}
+	return
+}
+
+func (g *JSONTestsGenerator) roundTripFile() string {
+	return g.names.File(names.Cat(nomenclator.JSON, nomenclator.RoundTrip, nomenclator.Test))
+}
+
+func (g *JSONTestsGenerator) benchmarkFile() string {
+	return g.names.File(names.Cat(nomenclator.JSON, nomenclator.Benchmark, nomenclator.Test))
+}
+
+func (g *JSONTestsGenerator) fuzzFile() string {
+	return g.names.File(names.Cat(nomenclator.JSON, nomenclator.Fuzz, nomenclator.Test))
+}
+
+func (g *JSONTestsGenerator) goldenFile(typ *concepts.Type) string {
+	return g.names.File(typ.Name()) + ".json"
+}
+
+func (g *JSONTestsGenerator) testFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Test, typ.Name(), nomenclator.JSON, nomenclator.RoundTrip)
+	return g.names.Public(name)
+}
+
+func (g *JSONTestsGenerator) fuzzFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Fuzz, nomenclator.Unmarshal, typ.Name())
+	return g.names.Public(name)
+}
+
+// sample calculates the sample value of the given type. The returned flag will be false if no
+// value can be calculated.
+func (g *JSONTestsGenerator) sample(typ *concepts.Type) (value interface{}, ok bool) {
+	if typ.IsStruct() {
+		value, ok = g.sampleObject(typ, []*concepts.Type{typ}), true
+		return
+	}
+	return g.sampleValue(g.names.File(typ.Name()), typ, false, "", nil)
+}
+
+// sampleDate returns the sample value of a date written in the given format.
+func (g *JSONTestsGenerator) sampleDate(format string) interface{} {
+	switch format {
+	case concepts.DateFormatDate:
+		return sampleDay
+	case concepts.DateFormatUnix:
+		return sampleUnix
+	case concepts.DateFormatUnixMillis:
+		return sampleUnix * 1000
+	default:
+		return sampleDate
+	}
+}
+
+// sampleJSON calculates the sample value of the given type and returns it as a quoted Go string
+// containing the JSON document. If no value can be calculated the document will be an empty
+// list.
+func (g *JSONTestsGenerator) sampleJSON(typ *concepts.Type) string {
+	value, ok := g.sample(typ)
+	if !ok {
+		value = []interface{}{}
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		g.reporter.Errorf("Can't marshal sample value for type '%s': %v", typ.Name(), err)
+		return `""`
+	}
+	return strconv.Quote(string(data))
+}
+
+func (g *JSONTestsGenerator) structName(typ *concepts.Type) string {
+	return g.names.Public(typ.Name())
+}
+
+func (g *JSONTestsGenerator) listName(typ *concepts.Type) string {
+	name := names.Cat(typ.Name(), nomenclator.List)
+	return g.names.Public(name)
+}
+
+func (g *JSONTestsGenerator) marshalTypeFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Marshal, typ.Name())
+	return g.names.Public(name)
+}
+
+func (g *JSONTestsGenerator) unmarshalTypeFunc(typ *concepts.Type) string {
+	name := names.Cat(nomenclator.Unmarshal, typ.Name())
+	return g.names.Public(name)
+}
+
+// Values used in the golden files:
+const (
+	sampleDate = "2020-01-02T03:04:05Z"
+	sampleDay  = "2020-01-02"
+	sampleUnix = int64(1577934245)
+	sampleHREF = "/123"
+	sampleID   = "123"
+	sampleKey  = "key"
+)
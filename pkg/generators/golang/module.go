@@ -0,0 +1,336 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the types and functions used to generate code inside a Go module.
+
+package golang
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime/debug"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/semver"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// ModuleBuilder is used to create objects that describe the Go module where the code will be
+// generated. Don't create instances directly, use the NewModule function instead.
+type ModuleBuilder struct {
+	reporter  *reporter.Reporter
+	output    string
+	path      string
+	goVersion string
+}
+
+// Module describes the Go module where the code is generated. It is used to calculate the import
+// path of the output directory and to create or update the go.mod file. Don't create instances
+// directly, use the builder instead.
+type Module struct {
+	reporter  *reporter.Reporter
+	root      string
+	path      string
+	base      string
+	goVersion string
+}
+
+// NewModule creates a builder for modules.
+func NewModule() *ModuleBuilder {
+	return &ModuleBuilder{
+		goVersion: "1.12",
+	}
+}
+
+// Reporter sets the object that will be used to report information and errors.
+func (b *ModuleBuilder) Reporter(value *reporter.Reporter) *ModuleBuilder {
+	b.reporter = value
+	return b
+}
+
+// Output sets the directory where the code will be generated.
+func (b *ModuleBuilder) Output(value string) *ModuleBuilder {
+	b.output = value
+	return b
+}
+
+// Path sets the path of the module, for example 'github.com/my-org/my-sdk'. This is optional if
+// the output directory is already inside a module, as the path will then be taken from the
+// go.mod file. If it isn't then this is mandatory, and the output directory will be the root of
+// the module.
+func (b *ModuleBuilder) Path(value string) *ModuleBuilder {
+	b.path = value
+	return b
+}
+
+// Go sets the version of Go that will be written to the 'go' directive of the go.mod file when it
+// is created. It has no effect if the file already exists. The default is 1.12.
+func (b *ModuleBuilder) Go(value string) *ModuleBuilder {
+	b.goVersion = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// module using it.
+func (b *ModuleBuilder) Build() (module *Module, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.output == "" {
+		err = fmt.Errorf("output is mandatory")
+		return
+	}
+	if b.goVersion == "" {
+		err = fmt.Errorf("version of Go is mandatory")
+		return
+	}
+
+	// Find the go.mod file in the output directory or in its parents. Note that the output
+	// directory may not exist yet, but that isn't a problem because we only look at the
+	// names.
+	output, err := filepath.Abs(b.output)
+	if err != nil {
+		return
+	}
+	root := output
+	var modPath, goVersion string
+	for {
+		modPath, goVersion, err = moduleFileInfo(filepath.Join(root, "go.mod"))
+		if err != nil {
+			return
+		}
+		if modPath != "" {
+			break
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			root = ""
+			break
+		}
+		root = parent
+	}
+
+	// Calculate the path of the module and the import path of the output directory:
+	var base string
+	switch {
+	case root != "":
+		if b.path != "" && b.path != modPath {
+			err = fmt.Errorf(
+				"module path '%s' doesn't match '%s' from file '%s'",
+				b.path, modPath, filepath.Join(root, "go.mod"),
+			)
+			return
+		}
+		var rel string
+		rel, err = filepath.Rel(root, output)
+		if err != nil {
+			return
+		}
+		base = path.Join(modPath, filepath.ToSlash(rel))
+	case b.path != "":
+		root = output
+		modPath = b.path
+		base = b.path
+		goVersion = b.goVersion
+	default:
+		err = fmt.Errorf(
+			"output directory '%s' isn't inside a module and the module path hasn't "+
+				"been specified",
+			b.output,
+		)
+		return
+	}
+
+	// Create the module:
+	module = &Module{
+		reporter:  b.reporter,
+		root:      root,
+		path:      modPath,
+		base:      base,
+		goVersion: goVersion,
+	}
+
+	return
+}
+
+// Root returns the directory that contains the go.mod file of the module.
+func (m *Module) Root() string {
+	return m.root
+}
+
+// Path returns the path of the module.
+func (m *Module) Path() string {
+	return m.path
+}
+
+// Base returns the import path of the output directory, suitable for use as the base package of
+// the packages calculator.
+func (m *Module) Base() string {
+	return m.base
+}
+
+// Go returns the version of Go from the 'go' directive of the go.mod file. If the file doesn't
+// exist yet it returns the version that will be used when it is created. If the file exists but
+// doesn't contain the directive it returns an empty string.
+func (m *Module) Go() string {
+	return m.goVersion
+}
+
+// GoAtLeast returns true if the version of Go of the module is the given one or a newer one.
+func (m *Module) GoAtLeast(version string) bool {
+	return m.goVersion != "" && semver.Compare("v"+m.goVersion, "v"+version) >= 0
+}
+
+// Update creates the go.mod file if it doesn't exist, and adds the modules required by the
+// generated code that are missing. The versions of the required modules are the ones used to
+// build the generator. Modules whose version isn't known are skipped, as those can be added later
+// with 'go mod tidy'.
+func (m *Module) Update() error {
+	// Read the existing file, or create a new one:
+	file := filepath.Join(m.root, "go.mod")
+	var content *modfile.File
+	data, err := ioutil.ReadFile(file)
+	switch {
+	case os.IsNotExist(err):
+		m.reporter.Infof("Creating module file '%s'", file)
+		err = os.MkdirAll(m.root, 0777)
+		if err != nil {
+			return err
+		}
+		content = new(modfile.File)
+		err = content.AddModuleStmt(m.path)
+		if err != nil {
+			return err
+		}
+		err = content.AddGoStmt(m.goVersion)
+		if err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		content, err = modfile.Parse(file, data, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Add the required modules that are missing:
+	present := map[string]bool{}
+	for _, require := range content.Require {
+		present[require.Mod.Path] = true
+	}
+	versions := moduleVersions()
+	for _, required := range moduleRequirements {
+		if required == m.path || present[required] {
+			continue
+		}
+		version, ok := versions[required]
+		if !ok {
+			m.reporter.Infof(
+				"Version of required module '%s' isn't known, it will not be added "+
+					"to file '%s'",
+				required, file,
+			)
+			continue
+		}
+		err = content.AddRequire(required, version)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Save the result:
+	content.Cleanup()
+	updated, err := content.Format()
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(updated, data) {
+		return nil
+	}
+	m.reporter.Infof("Updating module file '%s'", file)
+	return ioutil.WriteFile(file, updated, 0666)
+}
+
+// moduleFileInfo reads the given go.mod file and returns the path of the module and the version
+// of Go from the 'go' directive. If the file doesn't exist it returns empty strings.
+func moduleFileInfo(file string) (modPath, goVersion string, err error) {
+	data, err := ioutil.ReadFile(file)
+	if os.IsNotExist(err) {
+		err = nil
+		return
+	}
+	if err != nil {
+		return
+	}
+	modPath = modfile.ModulePath(data)
+	if modPath == "" {
+		err = fmt.Errorf("can't find module path in file '%s'", file)
+		return
+	}
+	goVersion = moduleGoVersion(data)
+	return
+}
+
+// moduleGoVersion returns the version of Go from the 'go' directive of the given go.mod file
+// content, or an empty string if there is no such directive. The file is scanned line by line
+// instead of parsed with the modfile package because the version of that package that we use
+// rejects the version formats introduced by newer versions of Go, like '1.21.0'.
+func moduleGoVersion(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		index := strings.Index(line, "//")
+		if index >= 0 {
+			line = line[0:index]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "go" {
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// moduleVersions returns the versions of the modules used to build the generator, indexed by
+// module path.
+func moduleVersions() map[string]string {
+	result := map[string]string{}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return result
+	}
+	for _, dep := range info.Deps {
+		if dep.Replace != nil {
+			continue
+		}
+		result[dep.Path] = dep.Version
+	}
+	return result
+}
+
+// moduleRequirements contains the paths of the modules that are required by the generated code.
+var moduleRequirements = []string{
+	"github.com/golang/glog",
+	"github.com/json-iterator/go",
+}
@@ -0,0 +1,209 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the Go module support.
+
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Module", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "module-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Calculates the base from the enclosing module", func() {
+		err := ioutil.WriteFile(
+			filepath.Join(tmp, "go.mod"),
+			[]byte("module example.com/sdk\n\ngo 1.12\n"),
+			0666,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(filepath.Join(tmp, "pkg", "api")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(module.Path()).To(Equal("example.com/sdk"))
+		Expect(module.Base()).To(Equal("example.com/sdk/pkg/api"))
+	})
+
+	It("Uses the module path when the output is the root of the module", func() {
+		err := ioutil.WriteFile(
+			filepath.Join(tmp, "go.mod"),
+			[]byte("module \"example.com/sdk\"\n"),
+			0666,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(module.Base()).To(Equal("example.com/sdk"))
+	})
+
+	It("Rejects a path that doesn't match the enclosing module", func() {
+		err := ioutil.WriteFile(
+			filepath.Join(tmp, "go.mod"),
+			[]byte("module example.com/sdk\n"),
+			0666,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Path("example.com/other").
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Fails if there is no module and no path", func() {
+		_, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(filepath.Join(tmp, "out")).
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Creates the module file if it doesn't exist", func() {
+		output := filepath.Join(tmp, "out")
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(output).
+			Path("example.com/sdk").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(module.Root()).To(Equal(output))
+		Expect(module.Base()).To(Equal("example.com/sdk"))
+		err = module.Update()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(filepath.Join(output, "go.mod"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(HavePrefix("module example.com/sdk\n"))
+	})
+
+	It("Returns the version of Go of the enclosing module", func() {
+		err := ioutil.WriteFile(
+			filepath.Join(tmp, "go.mod"),
+			[]byte("module example.com/sdk\n\ngo 1.21.0 // Generics.\n"),
+			0666,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(module.Go()).To(Equal("1.21.0"))
+		Expect(module.GoAtLeast("1.18")).To(BeTrue())
+		Expect(module.GoAtLeast("1.22")).To(BeFalse())
+	})
+
+	It("Doesn't support any version of Go if the module doesn't specify it", func() {
+		err := ioutil.WriteFile(
+			filepath.Join(tmp, "go.mod"),
+			[]byte("module example.com/sdk\n"),
+			0666,
+		)
+		Expect(err).ToNot(HaveOccurred())
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Go("1.18").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(module.Go()).To(BeEmpty())
+		Expect(module.GoAtLeast("1.12")).To(BeFalse())
+	})
+
+	It("Creates the module file with the given version of Go", func() {
+		output := filepath.Join(tmp, "out")
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(output).
+			Path("example.com/sdk").
+			Go("1.18").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(module.Go()).To(Equal("1.18"))
+		err = module.Update()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(filepath.Join(output, "go.mod"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(ContainSubstring("\ngo 1.18\n"))
+	})
+
+	It("Doesn't add modules that are already required", func() {
+		content := "module example.com/sdk\n\n" +
+			"go 1.12\n\n" +
+			"require (\n" +
+			"\tgithub.com/golang/glog v1.0.0\n" +
+			"\tgithub.com/json-iterator/go v1.1.9\n" +
+			")\n"
+		err := ioutil.WriteFile(filepath.Join(tmp, "go.mod"), []byte(content), 0666)
+		Expect(err).ToNot(HaveOccurred())
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = module.Update()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(filepath.Join(tmp, "go.mod"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(content))
+	})
+
+	It("Understands requirements written in a single line", func() {
+		content := "// My SDK.\n" +
+			"module example.com/sdk\n\n" +
+			"go 1.12\n\n" +
+			"require github.com/golang/glog v1.0.0\n\n" +
+			"require github.com/json-iterator/go v1.1.9 // indirect\n\n" +
+			"replace github.com/golang/glog => ../glog\n"
+		err := ioutil.WriteFile(filepath.Join(tmp, "go.mod"), []byte(content), 0666)
+		Expect(err).ToNot(HaveOccurred())
+		module, err := NewModule().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = module.Update()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(filepath.Join(tmp, "go.mod"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(data)).To(Equal(content))
+	})
+})
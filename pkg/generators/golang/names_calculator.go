@@ -19,6 +19,7 @@ package golang
 import (
 	"fmt"
 	"strings"
+	"unicode"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
@@ -28,17 +29,23 @@ import (
 // create instances directly, use the NewNamesCalculator function instead.
 type NamesCalculatorBuilder struct {
 	reporter *reporter.Reporter
+	escape   string
+	plurals  map[string]string
 }
 
 // NamesCalculator is an object used to calculate Go names. Don't create instances directly, use the
 // builder instead.
 type NamesCalculator struct {
-	reporter *reporter.Reporter
+	reporter  *reporter.Reporter
+	escape    string
+	inflector *names.Inflector
 }
 
 // NewNamesCalculator creates a Go names calculator builder.
 func NewNamesCalculator() *NamesCalculatorBuilder {
-	return &NamesCalculatorBuilder{}
+	return &NamesCalculatorBuilder{
+		escape: "_",
+	}
 }
 
 // Reporter sets the object that will be used to report information about the calculation processes,
@@ -48,6 +55,34 @@ func (b *NamesCalculatorBuilder) Reporter(value *reporter.Reporter) *NamesCalcul
 	return b
 }
 
+// Escape sets the suffix that will be added to names that would otherwise be Go keywords,
+// predeclared identifiers or identifiers used by the generated code. The default is an
+// underscore, so that for example an attribute named 'type' will be stored in a field named
+// 'type_'.
+func (b *NamesCalculatorBuilder) Escape(value string) *NamesCalculatorBuilder {
+	b.escape = value
+	return b
+}
+
+// Plural sets the plural form of a word, overriding the one calculated by the default rules. For
+// example, Plural("mesh", "meshes").
+func (b *NamesCalculatorBuilder) Plural(singular, plural string) *NamesCalculatorBuilder {
+	if b.plurals == nil {
+		b.plurals = map[string]string{}
+	}
+	b.plurals[singular] = plural
+	return b
+}
+
+// Plurals sets the plural forms of multiple words. This is equivalent to calling the Plural
+// method multiple times.
+func (b *NamesCalculatorBuilder) Plurals(values map[string]string) *NamesCalculatorBuilder {
+	for singular, plural := range values {
+		b.Plural(singular, plural)
+	}
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new
 // calculator using it.
 func (b *NamesCalculatorBuilder) Build() (calculator *NamesCalculator, err error) {
@@ -56,10 +91,35 @@ func (b *NamesCalculatorBuilder) Build() (calculator *NamesCalculator, err error
 		err = fmt.Errorf("reporter is mandatory")
 		return
 	}
+	if b.escape == "" {
+		err = fmt.Errorf("escape suffix is mandatory")
+		return
+	}
+	for _, r := range b.escape {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' {
+			err = fmt.Errorf(
+				"escape suffix '%s' contains character '%c' that isn't valid in Go "+
+					"identifiers",
+				b.escape, r,
+			)
+			return
+		}
+	}
+
+	// Create the inflector and add the overrides:
+	inflector := names.NewInflector()
+	for singular, plural := range b.plurals {
+		err = inflector.Override(singular, plural)
+		if err != nil {
+			return
+		}
+	}
 
 	// Create the calculator:
 	calculator = &NamesCalculator{
-		reporter: b.reporter,
+		reporter:  b.reporter,
+		escape:    b.escape,
+		inflector: inflector,
 	}
 
 	return
@@ -73,11 +133,12 @@ func (c *NamesCalculator) Public(name *names.Name) string {
 		chunks[i] = word.Capitalize()
 	}
 	public := strings.Join(chunks, "")
-	public = AvoidReservedWord(public)
+	public = c.avoid(public, reservedWords)
 	return public
 }
 
 // Private converts the given name into an string, following the rules for Go private names.
+// Names that are Go keywords or predeclared identifiers, like 'type' or 'len', are escaped.
 func (c *NamesCalculator) Private(name *names.Name) string {
 	words := name.Words()
 	chunks := make([]string, len(words))
@@ -89,10 +150,53 @@ func (c *NamesCalculator) Private(name *names.Name) string {
 		}
 	}
 	private := strings.Join(chunks, "")
-	private = AvoidReservedWord(private)
+	private = c.avoid(private, reservedWords, predeclaredIdentifiers)
 	return private
 }
 
+// Plural converts the given name into the plural form, with the words separated by spaces, so
+// that it can be used in documentation. For example, 'cluster_status' is converted into 'cluster
+// statuses'.
+func (c *NamesCalculator) Plural(name *names.Name) string {
+	words := c.inflector.Plural(name).Words()
+	chunks := make([]string, len(words))
+	for i, word := range words {
+		if word.Initialism() {
+			chunks[i] = word.String()
+		} else {
+			chunks[i] = strings.ToLower(word.String())
+		}
+	}
+	return strings.Join(chunks, " ")
+}
+
+// Field converts the given name into an string that can be used as the name of a field of the
+// request and response types generated for the parameters of methods. It is the private name
+// escaped so that it doesn't collide with the fields that those types always contain.
+func (c *NamesCalculator) Field(name *names.Name) string {
+	return c.avoid(c.Private(name), requestFields)
+}
+
+// Accessor converts the given name into an string that can be used as the name of a getter or
+// setter of the request and response types generated for the parameters of methods. It is the
+// public name escaped so that it doesn't collide with the methods that those types always
+// contain.
+func (c *NamesCalculator) Accessor(name *names.Name) string {
+	return c.avoid(c.Public(name), requestAccessors)
+}
+
+// avoid adds the escape suffix to the given word if it is in any of the given sets of reserved
+// words.
+func (c *NamesCalculator) avoid(word string, sets ...map[string]interface{}) string {
+	for _, set := range sets {
+		_, reserved := set[word]
+		if reserved {
+			return word + c.escape
+		}
+	}
+	return word
+}
+
 // File converts the given name into an string, following the rules for Go source files.
 func (c *NamesCalculator) File(name *names.Name) string {
 	return name.LowerJoined("_")
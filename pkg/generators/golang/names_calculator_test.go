@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the calculator of Go names.
+
+package golang
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Names calculator", func() {
+	var calculator *NamesCalculator
+
+	BeforeEach(func() {
+		var err error
+		calculator, err = NewNamesCalculator().
+			Reporter(reporter.NewReporter()).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	DescribeTable(
+		"Escapes private names",
+		func(name, expected string) {
+			Expect(calculator.Private(names.ParseUsingCase(name))).To(Equal(expected))
+		},
+		Entry("Keyword", "Type", "type_"),
+		Entry("Keyword", "Interface", "interface_"),
+		Entry("Keyword", "Range", "range_"),
+		Entry("Predeclared function", "Len", "len_"),
+		Entry("Predeclared type", "String", "string_"),
+		Entry("Regular name", "Name", "name"),
+		Entry("Multiple words", "TypeName", "typeName"),
+	)
+
+	It("Escapes fields and accessors of requests", func() {
+		name := names.ParseUsingCase("Status")
+		Expect(calculator.Field(name)).To(Equal("status_"))
+		Expect(calculator.Accessor(name)).To(Equal("Status_"))
+		Expect(calculator.Public(name)).To(Equal("Status"))
+	})
+
+	DescribeTable(
+		"Escapes fields of requests and responses",
+		func(name, expected string) {
+			Expect(calculator.Field(names.ParseUsingCase(name))).To(Equal(expected))
+		},
+		Entry("Path", "Path", "path_"),
+		Entry("Header", "Header", "header_"),
+		Entry("Query", "Query", "query_"),
+		Entry("Transport", "Transport", "transport_"),
+		Entry("Raw body", "RawBody", "rawBody_"),
+		Entry("Private method", "HTTPRequest", "httpRequest_"),
+		Entry("Regular name", "Name", "name"),
+	)
+
+	DescribeTable(
+		"Escapes accessors of requests and responses",
+		func(name, expected string) {
+			Expect(calculator.Accessor(names.ParseUsingCase(name))).To(Equal(expected))
+		},
+		Entry("Clone", "Clone", "Clone_"),
+		Entry("Send", "Send", "Send_"),
+		Entry("Header", "Header", "Header_"),
+		Entry("Rate limit", "RateLimit", "RateLimit_"),
+		Entry("Operation identifier", "OperationID", "OperationID_"),
+		Entry("List all", "ListAll", "ListAll_"),
+		Entry("Regular name", "Name", "Name"),
+	)
+
+	It("Uses the configured escape suffix", func() {
+		calculator, err := NewNamesCalculator().
+			Reporter(reporter.NewReporter()).
+			Escape("Value").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(calculator.Private(names.ParseUsingCase("Type"))).To(Equal("typeValue"))
+	})
+
+	It("Rejects escape suffix that isn't valid in identifiers", func() {
+		_, err := NewNamesCalculator().
+			Reporter(reporter.NewReporter()).
+			Escape("-").
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -34,24 +34,32 @@ import (
 // OpenAPIGeneratorBuilder is an object used to configure and build a OpenAPI specification
 // generators. Don't create instances directly, use the NewOpenAPIGenerator function instead.
 type OpenAPIGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	binding  *http.BindingCalculator
-	names    *openapi.NamesCalculator
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	binding   *http.BindingCalculator
+	names     *openapi.NamesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
 }
 
 // OpenAPIGenerator generates helper code. Don't create instances directly, use the builder instead.
 type OpenAPIGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	binding  *http.BindingCalculator
-	names    *openapi.NamesCalculator
-	buffer   *Buffer
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	binding   *http.BindingCalculator
+	names     *openapi.NamesCalculator
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
 }
 
 // NewOpenAPIGenerator creates a new builder for helpers generators.
@@ -97,6 +105,35 @@ func (b *OpenAPIGeneratorBuilder) Binding(value *http.BindingCalculator) *OpenAP
 	return b
 }
 
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *OpenAPIGeneratorBuilder) Header(value *Header) *OpenAPIGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *OpenAPIGeneratorBuilder) Stream(value bool) *OpenAPIGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *OpenAPIGeneratorBuilder) MaxLines(value int) *OpenAPIGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *OpenAPIGeneratorBuilder) Templates(value *Templates) *OpenAPIGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new client
 // generator using it.
 func (b *OpenAPIGeneratorBuilder) Build() (generator *OpenAPIGenerator, err error) {
@@ -128,12 +165,16 @@ func (b *OpenAPIGeneratorBuilder) Build() (generator *OpenAPIGenerator, err erro
 
 	// Create the generator:
 	generator = &OpenAPIGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		binding:  b.binding,
-		names:    b.names,
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		binding:   b.binding,
+		names:     b.names,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
 	}
 
 	return
@@ -175,6 +216,7 @@ func (g *OpenAPIGenerator) Run() error {
 	// Generate the Go source file containing the OpenAPI specification for each version:
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating OpenAPI specification for version '%s'", version)
 			err = g.generateSpec(version, jsonDir)
 			if err != nil {
 				return err
@@ -216,15 +258,21 @@ func (g *OpenAPIGenerator) generateSpec(version *concepts.Version, jsonDir strin
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("openapi").
 		Package(pkgName).
 		File("openapi").
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the Go source:
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("spec", `
 		// OpenAPI contains the OpenAPI specification of the service in JSON.
 		var OpenAPI = []byte{
 			{{ byteArray .Data }}
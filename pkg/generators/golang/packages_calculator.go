@@ -112,6 +112,12 @@ func (g *PackagesCalculator) VersionSelector(version *concepts.Version) string {
 	return path.Base(g.VersionPackage(version))
 }
 
+// AliasesPackage returns the name of the package that contains the prefixed aliases of the types
+// of the given version.
+func (g *PackagesCalculator) AliasesPackage(version *concepts.Version) string {
+	return path.Join(g.VersionPackage(version), nomenclator.Aliases.LowerJoined(""))
+}
+
 // HelpersPackage returns the name of the helpers package.
 func (g *PackagesCalculator) HelpersPackage() string {
 	return nomenclator.Helpers.LowerJoined("")
@@ -132,6 +138,16 @@ func (g *PackagesCalculator) ErrorsImport() string {
 	return path.Join(g.base, g.ErrorsPackage())
 }
 
+// DescriptorsPackage returns the name of the package that contains the descriptors of the model.
+func (g *PackagesCalculator) DescriptorsPackage() string {
+	return nomenclator.Descriptors.LowerJoined("")
+}
+
+// DescriptorsImport returns complete import path of the descriptors package.
+func (g *PackagesCalculator) DescriptorsImport() string {
+	return path.Join(g.base, g.DescriptorsPackage())
+}
+
 // BasePackage returns the import path of the base package.
 func (g *PackagesCalculator) BasePackage() string {
 	return g.base
@@ -56,3 +56,106 @@ var reservedWords = map[string]interface{}{
 	"type":        nil,
 	"var":         nil,
 }
+
+// predeclaredIdentifiers is the list of identifiers that are implicitly declared in the Go
+// universe block. Private names can't use them because the generated code would shadow them.
+var predeclaredIdentifiers = map[string]interface{}{
+	"append":     nil,
+	"bool":       nil,
+	"byte":       nil,
+	"cap":        nil,
+	"close":      nil,
+	"complex":    nil,
+	"complex64":  nil,
+	"complex128": nil,
+	"copy":       nil,
+	"delete":     nil,
+	"error":      nil,
+	"false":      nil,
+	"float32":    nil,
+	"float64":    nil,
+	"imag":       nil,
+	"int":        nil,
+	"int8":       nil,
+	"int16":      nil,
+	"int32":      nil,
+	"int64":      nil,
+	"iota":       nil,
+	"len":        nil,
+	"make":       nil,
+	"new":        nil,
+	"nil":        nil,
+	"panic":      nil,
+	"print":      nil,
+	"println":    nil,
+	"real":       nil,
+	"recover":    nil,
+	"rune":       nil,
+	"string":     nil,
+	"true":       nil,
+	"uint":       nil,
+	"uint8":      nil,
+	"uint16":     nil,
+	"uint32":     nil,
+	"uint64":     nil,
+	"uintptr":    nil,
+}
+
+// requestFields is the list of fields and private methods that the generated request and
+// response types always contain, and that can't be used for the fields generated for the
+// parameters. It includes the members of the types generated for clients and for servers.
+var requestFields = map[string]interface{}{
+	"err":                nil,
+	"header":             nil,
+	"httpRequest":        nil,
+	"idempotencyKey":     nil,
+	"impersonatedGroups": nil,
+	"impersonatedUser":   nil,
+	"keepRawBody":        nil,
+	"lastModified":       nil,
+	"marshal":            nil,
+	"maxItems":           nil,
+	"metric":             nil,
+	"path":               nil,
+	"query":              nil,
+	"raw":                nil,
+	"rawBody":            nil,
+	"status":             nil,
+	"stream":             nil,
+	"transport":          nil,
+}
+
+// requestAccessors is the list of methods that the generated request and response types always
+// contain, and that can't be used for the getters and setters generated for the parameters. It
+// includes the methods of the types generated for clients, for servers and for the polling
+// requests, as those have a setter for each parameter of the method that they poll.
+var requestAccessors = map[string]interface{}{
+	"AsCurl":             nil,
+	"CheckImmutable":     nil,
+	"Clone":              nil,
+	"Error":              nil,
+	"GetIdempotencyKey":  nil,
+	"GetLastModified":    nil,
+	"Header":             nil,
+	"IdempotencyKey":     nil,
+	"IfModifiedSince":    nil,
+	"ImpersonatedGroups": nil,
+	"ImpersonatedUser":   nil,
+	"Impersonate":        nil,
+	"Interval":           nil,
+	"KeepRawBody":        nil,
+	"LastModified":       nil,
+	"ListAll":            nil,
+	"MaxItems":           nil,
+	"NotModified":        nil,
+	"OperationID":        nil,
+	"Parameter":          nil,
+	"Predicate":          nil,
+	"RateLimit":          nil,
+	"RawBody":            nil,
+	"RawResponse":        nil,
+	"Send":               nil,
+	"SendContext":        nil,
+	"StartContext":       nil,
+	"Status":             nil,
+}
@@ -18,6 +18,7 @@ package golang
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/http"
@@ -29,27 +30,39 @@ import (
 // ServersGeneratorBuilder is an object used to configure and build the servers generator. Don't create
 // instances directly, use the ServersGeneratorBuilder function instead.
 type ServersGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	binding  *http.BindingCalculator
+	reporter  *reporter.Reporter
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	binding   *http.BindingCalculator
+	slash     string
+	cbor      bool
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
 }
 
 // ServersGenerator generate resources for the model resources.
 // Don't create instances directly, use the builder instead.
 type ServersGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	binding  *http.BindingCalculator
-	buffer   *Buffer
+	reporter  *reporter.Reporter
+	errors    int
+	model     *concepts.Model
+	output    string
+	packages  *PackagesCalculator
+	names     *NamesCalculator
+	types     *TypesCalculator
+	binding   *http.BindingCalculator
+	slash     string
+	cbor      bool
+	header    *Header
+	stream    bool
+	maxLines  int
+	templates *Templates
+	buffer    *Buffer
 }
 
 // NewServersGenerator creates a new builder for resource generators.
@@ -101,6 +114,53 @@ func (b *ServersGeneratorBuilder) Binding(value *http.BindingCalculator) *Server
 	return b
 }
 
+// TrailingSlash sets the default way that the generated adapters handle request paths that end
+// with a slash. The value can be 'ignore', to treat them as equivalent to the paths without the
+// slash, or 'redirect', to redirect the client to the path without the slash. The default is
+// 'ignore'. Users of the generated code can change it with the 'TrailingSlash' method of the
+// adapter.
+func (b *ServersGeneratorBuilder) TrailingSlash(value string) *ServersGeneratorBuilder {
+	b.slash = value
+	return b
+}
+
+// CBOR sets the flag that indicates if the generated servers should send response bodies encoded
+// with CBOR when the 'Accept' header of the request indicates that the client accepts them. This
+// requires the code generated by the CBOR support generator. The default is false.
+func (b *ServersGeneratorBuilder) CBOR(value bool) *ServersGeneratorBuilder {
+	b.cbor = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *ServersGeneratorBuilder) Header(value *Header) *ServersGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *ServersGeneratorBuilder) Stream(value bool) *ServersGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *ServersGeneratorBuilder) MaxLines(value int) *ServersGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *ServersGeneratorBuilder) Templates(value *Templates) *ServersGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new
 // types generator using it.
 func (b *ServersGeneratorBuilder) Build() (generator *ServersGenerator, err error) {
@@ -134,15 +194,35 @@ func (b *ServersGeneratorBuilder) Build() (generator *ServersGenerator, err erro
 		return
 	}
 
+	// Check the trailing slash handling:
+	slash := b.slash
+	switch slash {
+	case "":
+		slash = "ignore"
+	case "ignore", "redirect":
+	default:
+		err = fmt.Errorf(
+			"trailing slash handling should be 'ignore' or 'redirect' but it is '%s'",
+			slash,
+		)
+		return
+	}
+
 	// Create the generator:
 	generator = &ServersGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
-		types:    b.types,
-		binding:  b.binding,
+		reporter:  b.reporter,
+		model:     b.model,
+		output:    b.output,
+		packages:  b.packages,
+		names:     b.names,
+		types:     b.types,
+		binding:   b.binding,
+		slash:     slash,
+		cbor:      b.cbor,
+		header:    b.header,
+		stream:    b.stream,
+		maxLines:  b.maxLines,
+		templates: b.templates,
 	}
 
 	return
@@ -160,6 +240,11 @@ func (g *ServersGenerator) Run() error {
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("servers").
 		File(fileName).
 		Function("serviceName", g.serviceName).
 		Function("serviceSelector", g.packages.ServiceSelector).
@@ -168,10 +253,14 @@ func (g *ServersGenerator) Run() error {
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the source for the model server:
 	g.generateMainServerSource()
 	g.generateMainDispatcherSource()
+	g.generateMainCORSSource()
+	g.generateMainHealthSource()
+	g.generateMainLoggingSource()
 	err = g.buffer.Write()
 	if err != nil {
 		return err
@@ -179,6 +268,7 @@ func (g *ServersGenerator) Run() error {
 
 	// Generate the server for each service:
 	for _, service := range g.model.Services() {
+		g.reporter.Infof("Generating server for service '%s'", service.Name())
 		err = g.generateServiceServer(service)
 		if err != nil {
 			return err
@@ -202,7 +292,7 @@ func (g *ServersGenerator) generateMainServerSource() {
 	for _, service := range g.model.Services() {
 		g.buffer.Import(g.packages.ServiceImport(service), "")
 	}
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("main_server", `
 		// Server is the interface of the top level server.
 		type Server interface {
 			{{ range .Model.Services }}
@@ -214,63 +304,1212 @@ func (g *ServersGenerator) generateMainServerSource() {
 			{{ end }}
 		}
 		`,
-		"Model", g.model,
+		"Model", g.model,
+	)
+}
+
+func (g *ServersGenerator) generateMainDispatcherSource() {
+	g.buffer.Import("bytes", "")
+	g.buffer.Import("compress/gzip", "")
+	g.buffer.Import("context", "")
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("net/http/httputil", "")
+	g.buffer.Import("net/url", "")
+	g.buffer.Import("runtime/debug", "")
+	g.buffer.Import("strconv", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("time", "")
+	g.buffer.Import("github.com/golang/glog", "")
+	g.buffer.Import(g.packages.ErrorsImport(), "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("main_dispatcher", `
+		// Dispatch navigates the servers tree till it finds one that matches the given set
+		// of path segments, and then invokes it.
+		func Dispatch(w http.ResponseWriter, r *http.Request, server Server, segments []string) {
+			if len(segments) == 0 {
+				// TODO: This should send the metadata.
+				errors.SendMethodNotAllowed(w, r)
+				return
+			} else {
+				switch segments[0] {
+				{{ range .Model.Services }}
+					{{ $serviceName := serviceName . }}
+					{{ $serviceSelector := serviceSelector . }}
+
+					case "{{ serviceSegment . }}":
+						service := server.{{ $serviceName }}()
+						if service == nil {
+							helpers.Fallback(w, r, errors.SendNotFound)
+							return
+						}
+						{{ $serviceSelector }}.Dispatch(w, r, service, segments[1:])
+				{{ end }}
+				default:
+					errors.SendNotFound(w, r)
+					return
+				}
+			}
+		}
+
+		// NewProxy creates an HTTP handler that sends requests to the given upstream server,
+		// keeping the path and the query of the original request. It is intended to be used
+		// as the fallback handler of an adapter, for example:
+		//
+		//	proxy, err := NewProxy("https://api.openshift.com")
+		//	if err != nil {
+		//		...
+		//	}
+		//	adapter := NewAdapter(server).Fallback(proxy)
+		func NewProxy(upstream string) (http.Handler, error) {
+			target, err := url.Parse(upstream)
+			if err != nil {
+				return nil, fmt.Errorf("can't parse upstream URL '%s': %v", upstream, err)
+			}
+			if target.Scheme == "" || target.Host == "" {
+				return nil, fmt.Errorf(
+					"upstream URL '%s' should contain a scheme and a host",
+					upstream,
+				)
+			}
+			proxy := httputil.NewSingleHostReverseProxy(target)
+			director := proxy.Director
+			proxy.Director = func(r *http.Request) {
+				director(r)
+				r.Host = target.Host
+			}
+			return proxy, nil
+		}
+
+		// IdempotentResponse contains the details of a response that the adapter saves so
+		// that it can be sent again when the client retries the request.
+		type IdempotentResponse struct {
+			Status int
+			Header http.Header
+			Body   []byte
+		}
+
+		// IdempotencyStore is the interface that should be implemented by objects that
+		// save the responses of requests that contain an idempotency key, so that the
+		// retries of those requests are answered without processing them again.
+		type IdempotencyStore interface {
+			// Reserve checks the given key and, if there is no saved response and no
+			// request in progress for it, marks it as in progress. It returns the saved
+			// response if there is one, and a flag indicating if the key was reserved.
+			// Implementations must do this atomically, so that only one of several
+			// concurrent requests with the same key is processed.
+			Reserve(ctx context.Context, key string) (saved *IdempotentResponse, reserved bool,
+				err error)
+
+			// Put saves the response for a key that was previously reserved.
+			Put(ctx context.Context, key string, response *IdempotentResponse) error
+
+			// Release removes the reservation of a key when the request failed, so that
+			// the client can retry it.
+			Release(ctx context.Context, key string) error
+		}
+
+		// IdempotencyScope is a function that returns the identifier of the principal that
+		// sent a request, for example the user name extracted from the authentication token
+		// by the authentication middleware. It is used to prevent responses saved for a
+		// client from being sent to other clients that use the same idempotency key. If it
+		// returns an empty string the request is processed without checking the key.
+		type IdempotencyScope func(r *http.Request) (string, error)
+
+		// ActorIdempotencyScope is the scope used when the adapter is configured without one.
+		// It returns the actor stored in the context by the authentication middleware with
+		// the helpers.WithActor function, so requests without an actor aren't deduplicated.
+		func ActorIdempotencyScope(r *http.Request) (string, error) {
+			return helpers.Actor(r.Context()), nil
+		}
+
+		// TrailingSlashMode indicates how the adapter handles request paths that end with a
+		// slash.
+		type TrailingSlashMode int
+
+		const (
+			// TrailingSlashIgnore indicates that paths that end with a slash are equivalent
+			// to the paths without the slash.
+			TrailingSlashIgnore TrailingSlashMode = iota
+
+			// TrailingSlashRedirect indicates that requests for paths that end with a slash
+			// are redirected to the paths without the slash.
+			TrailingSlashRedirect
+		)
+
+		// Adapter is an HTTP handler that knows how to translate HTTP requests into calls
+		// to the methods of an object that implements the Server interface.
+		type Adapter struct {
+			dispatcher    helpers.Dispatcher
+			prefix        []string
+			handler       http.Handler
+			idempotency   IdempotencyStore
+			scope         IdempotencyScope
+			trailingSlash TrailingSlashMode
+			interceptors  []helpers.Interceptor
+			authorizer    helpers.Authorizer
+			audit         helpers.AuditSink
+			timeouts      *helpers.TimeoutConfig
+			probes        bool
+			liveness      map[string]HealthCheck
+			readiness     map[string]HealthCheck
+			panicHandler  func(r *http.Request, value interface{})
+			maxBodySize   int64
+			compression   int
+			cors          *CORSHandler
+			fallback      http.Handler
+		}
+
+		// NewAdapter creates a new adapter that will translate HTTP requests into calls to
+		// the given server. The optional middleware will be applied to all the requests, in
+		// the given order, so the first one will be the outermost.
+		//
+		// The adapter also answers the '/healthz' and '/readyz' probes, without applying the
+		// middleware, so that they don't need credentials. If the server
+		// implements the LivenessChecker or ReadinessChecker interfaces it is included in
+		// the checks of the corresponding probe with the name 'server'.
+		func NewAdapter(server Server, middleware ...func(http.Handler) http.Handler) *Adapter {
+			dispatcher := func(w http.ResponseWriter, r *http.Request, segments []string) {
+				Dispatch(w, r, server, segments)
+			}
+			adapter := newAdapter(nil, dispatcher, middleware)
+			adapter.probes = true
+			if checker, ok := server.(LivenessChecker); ok {
+				adapter.LivenessCheck("server", checker.CheckLiveness)
+			}
+			if checker, ok := server.(ReadinessChecker); ok {
+				adapter.ReadinessCheck("server", checker.CheckReadiness)
+			}
+			return adapter
+		}
+
+		// NewSubtreeAdapter creates a new adapter that will translate HTTP requests into calls
+		// to the servers of a subtree of resources, so that it can be mounted under an
+		// arbitrary prefix of an existing router. The dispatcher is created with the
+		// functions generated for each resource, for example:
+		//
+		//	adapter := NewSubtreeAdapter(
+		//		"/api/clusters_mgmt/v1/clusters/{cluster}/identity_providers",
+		//		v1.NewIdentityProvidersDispatcher(server),
+		//	)
+		//
+		// The path of each request must start with the prefix, otherwise the response will
+		// be a 404 error. Segments of the prefix enclosed in braces match any value, and the
+		// value is added to the path variables of the request, using the text inside the
+		// braces as the name. The optional middleware will be applied to all the requests,
+		// in the given order, so the first one will be the outermost.
+		func NewSubtreeAdapter(prefix string, dispatcher helpers.Dispatcher,
+			middleware ...func(http.Handler) http.Handler) *Adapter {
+			var segments []string
+			if strings.Trim(prefix, "/") != "" {
+				segments = helpers.Segments(prefix)
+			}
+			return newAdapter(segments, dispatcher, middleware)
+		}
+
+		func newAdapter(prefix []string, dispatcher helpers.Dispatcher,
+			middleware []func(http.Handler) http.Handler) *Adapter {
+			adapter := &Adapter{
+				dispatcher:    dispatcher,
+				prefix:        prefix,
+				trailingSlash: {{ if eq .Slash "redirect" }}TrailingSlashRedirect{{ else }}TrailingSlashIgnore{{ end }},
+			}
+			adapter.handler = http.HandlerFunc(adapter.serve)
+			for i := len(middleware) - 1; i >= 0; i-- {
+				adapter.handler = middleware[i](adapter.handler)
+			}
+			return adapter
+		}
+
+		// Intercept adds interceptors that will be called after reading each request and
+		// before calling the server, in the given order. Interceptors receive the typed
+		// request and response objects of the method, for example the request of the 'Get'
+		// method of the 'Cluster' resource is a '*v1.ClusterGetServerRequest', so they can
+		// use a type switch to act only on the resources and methods that they are
+		// interested in.
+		func (a *Adapter) Intercept(values ...helpers.Interceptor) *Adapter {
+			a.interceptors = append(a.interceptors, values...)
+			return a
+		}
+
+		// TrailingSlash sets the way that the adapter handles request paths that end with a
+		// slash. The default is {{ if eq .Slash "redirect" }}TrailingSlashRedirect{{ else }}TrailingSlashIgnore{{ end }}.
+		func (a *Adapter) TrailingSlash(value TrailingSlashMode) *Adapter {
+			a.trailingSlash = value
+			return a
+		}
+
+		// Idempotency enables the detection of retries of 'POST' requests that contain an
+		// idempotency key. When the store already has a response for the key that response
+		// will be sent without calling the server, and when another request with the same
+		// key is still in progress the response will be a 409 error. The key used for the
+		// store contains the principal returned by the scope function, the path of the
+		// request and the key sent by the client. If the scope is nil the
+		// ActorIdempotencyScope function is used. The default is to not detect retries.
+		func (a *Adapter) Idempotency(store IdempotencyStore, scope IdempotencyScope) *Adapter {
+			if scope == nil {
+				scope = ActorIdempotencyScope
+			}
+			a.idempotency = store
+			a.scope = scope
+			return a
+		}
+
+		// Authorizer sets the object that will be used to decide if requests are allowed
+		// before calling the server. The default is to allow all requests.
+		func (a *Adapter) Authorizer(value helpers.Authorizer) *Adapter {
+			a.authorizer = value
+			return a
+		}
+
+		// Audit sets the sink that will receive an audit record for each request, once the
+		// response has been sent. The record contains the actor stored in the context by
+		// the authentication middleware with the helpers.WithActor function, the route, a
+		// summary of the parameters of the request where the values of the attributes
+		// marked as sensitive in the model are redacted, and the outcome. The default is to
+		// not generate audit records.
+		func (a *Adapter) Audit(value helpers.AuditSink) *Adapter {
+			a.audit = value
+			return a
+		}
+
+		// Timeouts sets the maximum time that the servers can take to process requests, for
+		// each kind of method. When the timeout expires the context passed to the server is
+		// cancelled, and if the server returns the error of the context the client receives
+		// a 504 status code. The default is to not have timeouts.
+		func (a *Adapter) Timeouts(value *helpers.TimeoutConfig) *Adapter {
+			a.timeouts = value
+			return a
+		}
+
+		// LivenessCheck adds a check that will be executed when the '/healthz' probe is
+		// called. The probe will fail if any of the checks fails. Checks added with the same
+		// name replace each other. Note that only adapters created with NewAdapter answer
+		// the probes.
+		func (a *Adapter) LivenessCheck(name string, check HealthCheck) *Adapter {
+			if a.liveness == nil {
+				a.liveness = map[string]HealthCheck{}
+			}
+			a.liveness[name] = check
+			return a
+		}
+
+		// ReadinessCheck adds a check that will be executed when the '/readyz' probe is
+		// called. It is intended to check the dependencies that the servers need to process
+		// requests, like databases or message brokers.
+		func (a *Adapter) ReadinessCheck(name string, check HealthCheck) *Adapter {
+			if a.readiness == nil {
+				a.readiness = map[string]HealthCheck{}
+			}
+			a.readiness[name] = check
+			return a
+		}
+
+		// MaxBodySize sets the maximum size in bytes of request bodies. Requests with larger
+		// bodies will be rejected with a 413 status code. The default is zero, which means
+		// that there is no limit.
+		func (a *Adapter) MaxBodySize(value int64) *Adapter {
+			a.maxBodySize = value
+			return a
+		}
+
+		// Compression sets the minimum size in bytes of the response bodies that will be
+		// compressed using gzip when the client supports it. The default is zero, which means
+		// that responses aren't compressed.
+		func (a *Adapter) Compression(value int) *Adapter {
+			a.compression = value
+			return a
+		}
+
+		// CORS sets the object that handles cross origin resource sharing. When it is set the
+		// adapter will answer preflight requests and add the CORS headers to the responses
+		// for allowed origins. This is done before applying the middleware, so that
+		// preflight requests, which don't contain credentials, aren't rejected by the
+		// authentication middleware. Use the NewCORSHandler function to create the object,
+		// for example:
+		//
+		//	cors, err := NewCORSHandler(&CORSConfig{
+		//		AllowedOrigins: []string{"https://example.com"},
+		//	})
+		//	if err != nil {
+		//		...
+		//	}
+		//	adapter := NewAdapter(server).CORS(cors)
+		//
+		// The default is to not handle CORS.
+		func (a *Adapter) CORS(value *CORSHandler) *Adapter {
+			a.cors = value
+			return a
+		}
+
+		// Fallback sets the handler that will receive the requests for services, versions and
+		// resources that don't have a server, because the corresponding method of the parent
+		// server returned nil. It is intended to be used with NewProxy to send those requests
+		// to an upstream server, so that a service can be implemented gradually, or developed
+		// locally against a real environment. Requests for objects that don't exist, because
+		// the locator that receives the identifier returned nil, aren't sent to this handler,
+		// they still get a 404 response. The middleware and the authorizer are applied to
+		// these requests, but the interceptors aren't, as there is no typed request. The route
+		// passed to the authorizer only contains the path and the variables. The default is
+		// to send a 404 response.
+		func (a *Adapter) Fallback(value http.Handler) *Adapter {
+			a.fallback = value
+			return a
+		}
+
+		// PanicHandler sets a function that will be called when the server panics while
+		// processing a request, after the panic has been logged and before the error response
+		// is sent. It receives the request and the value passed to panic, and it is intended
+		// to increment metrics or send notifications. It must not write the response.
+		func (a *Adapter) PanicHandler(value func(r *http.Request, value interface{})) *Adapter {
+			a.panicHandler = value
+			return a
+		}
+
+		// ServeHTTP is the implementation of the http.Handler interface.
+		func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+			// The probes are answered before the middleware, so that they aren't rejected by
+			// the authentication middleware:
+			if a.probes {
+				switch r.URL.Path {
+				case HealthzPath:
+					sendProbe(w, r, a.liveness)
+					return
+				case ReadyzPath:
+					sendProbe(w, r, a.readiness)
+					return
+				}
+			}
+
+			// Preflight requests are also answered before the middleware, as browsers
+			// don't send credentials with them:
+			if a.cors != nil && a.cors.handle(w, r) {
+				return
+			}
+
+			// The holder of the route is added before the middleware, so that it can see
+			// the route once the request has been matched to a method:
+			r = r.WithContext(helpers.WithRouteHolder(r.Context()))
+			a.handler.ServeHTTP(w, r)
+		}
+
+		// serve processes the request after it has passed through the middleware.
+		func (a *Adapter) serve(w http.ResponseWriter, r *http.Request) {
+			operationID := r.Header.Get(helpers.OperationIDHeader)
+			if operationID == "" {
+				operationID = helpers.NewOperationID()
+			}
+			w.Header().Set(helpers.OperationIDHeader, operationID)
+			r = r.WithContext(helpers.WithOperationID(r.Context(), operationID))
+			if a.compression > 0 {
+				w.Header().Add("Vary", "Accept-Encoding")
+				if acceptsGzip(r) {
+					compressor := &compressWriter{
+						ResponseWriter: w,
+						threshold:      a.compression,
+					}
+					defer compressor.close()
+					w = compressor
+				}
+			}
+			if a.audit != nil {
+				// The record may have been already added to the context by the request
+				// logger, and in that case we populate and send that same record:
+				record := helpers.AuditRecordFromContext(r.Context())
+				if record == nil {
+					record = &helpers.AuditRecord{}
+					r = r.WithContext(helpers.WithAuditRecord(r.Context(), record))
+				}
+				record.Time = time.Now()
+				record.OperationID = operationID
+				record.Actor = helpers.Actor(r.Context())
+				record.Method = r.Method
+				record.Path = r.URL.Path
+				record.ImpersonatedUser, _ = helpers.Impersonation(r)
+				recorder := &auditWriter{
+					ResponseWriter: w,
+				}
+				defer a.finishAudit(r, recorder, record)
+				w = recorder
+			}
+			tracker := &panicWriter{
+				ResponseWriter: w,
+			}
+			defer a.handlePanic(tracker, r)
+			w = tracker
+			if a.maxBodySize > 0 && r.Body != nil {
+				r.Body = helpers.LimitBody(r.Body, a.maxBodySize)
+			}
+			if len(a.interceptors) > 0 {
+				r = r.WithContext(helpers.WithInterceptors(r.Context(), a.interceptors))
+			}
+			if a.authorizer != nil {
+				r = r.WithContext(helpers.WithAuthorizer(r.Context(), a.authorizer))
+			}
+			if a.timeouts != nil {
+				r = r.WithContext(helpers.WithTimeoutConfig(r.Context(), a.timeouts))
+			}
+			if a.fallback != nil {
+				fallback := http.HandlerFunc(a.serveFallback)
+				r = r.WithContext(helpers.WithFallback(r.Context(), fallback))
+			}
+			if a.trailingSlash == TrailingSlashRedirect {
+				path := r.URL.Path
+				if len(path) > 1 && strings.HasSuffix(path, "/") {
+					a.redirect(w, r)
+					return
+				}
+			}
+			if a.idempotency != nil && r.Method == http.MethodPost {
+				key := r.Header.Get(helpers.IdempotencyKeyHeader)
+				if key != "" {
+					principal, err := a.scope(r)
+					if err != nil {
+						glog.Errorf(
+							"Can't get idempotency scope for method '%s' and path '%s': %v",
+							r.Method, r.URL.Path, err,
+						)
+						errors.SendInternalServerError(w, r)
+						return
+					}
+					if principal != "" {
+						a.deduplicate(w, r, principal+" "+r.URL.Path+" "+key)
+						return
+					}
+				}
+			}
+			a.dispatch(w, r)
+		}
+
+		// dispatch removes the prefix from the path of the request, if there is a prefix, and
+		// then sends it to the dispatcher.
+		func (a *Adapter) dispatch(w http.ResponseWriter, r *http.Request) {
+			segments := helpers.Segments(r.URL.Path)
+			if a.prefix != nil {
+				if len(segments) < len(a.prefix) {
+					errors.SendNotFound(w, r)
+					return
+				}
+				for i, pattern := range a.prefix {
+					if strings.HasPrefix(pattern, "{") && strings.HasSuffix(pattern, "}") {
+						r = helpers.AddVariable(r, pattern[1:len(pattern)-1], segments[i])
+					} else if pattern != segments[i] {
+						errors.SendNotFound(w, r)
+						return
+					}
+				}
+				segments = segments[len(a.prefix):]
+			}
+			a.dispatcher(w, r, segments)
+		}
+
+		// redirect sends the client to the path of the request without the trailing slashes,
+		// preserving the query. The path is taken from the original request URI because the
+		// URL may have been modified by handlers like http.StripPrefix that mount the adapter
+		// in a different path. Leading slashes are collapsed and the scheme and host are
+		// removed, so that a path like '//example.com/' can't be used to redirect the client
+		// to a different host.
+		func (a *Adapter) redirect(w http.ResponseWriter, r *http.Request) {
+			target := *r.URL
+			if r.RequestURI != "" {
+				original, err := url.ParseRequestURI(r.RequestURI)
+				if err == nil {
+					target = *original
+				}
+			}
+			target.Scheme = ""
+			target.Opaque = ""
+			target.User = nil
+			target.Host = ""
+			target.Path = "/" + strings.Trim(target.Path, "/")
+			target.RawPath = ""
+			http.Redirect(w, r, target.String(), http.StatusPermanentRedirect)
+		}
+
+		// handlePanic recovers from panics that happen while processing the given request, so
+		// that they are logged and the client receives an internal server error instead of
+		// a closed connection. The http.ErrAbortHandler value is used to deliberately abort
+		// the response, so it isn't recovered. If the response has already been started it
+		// isn't possible to send the error, so the response is aborted instead.
+		func (a *Adapter) handlePanic(w *panicWriter, r *http.Request) {
+			value := recover()
+			if value == nil {
+				return
+			}
+			if value == http.ErrAbortHandler {
+				panic(value)
+			}
+			glog.Errorf(
+				"Panic while processing request for method '%s' and path '%s': %v\n%s",
+				r.Method, r.URL.Path, value, debug.Stack(),
+			)
+			if a.panicHandler != nil {
+				a.panicHandler(r, value)
+			}
+			if w.started {
+				panic(http.ErrAbortHandler)
+			}
+			errors.SendInternalServerError(w, r)
+		}
+
+		// serveFallback calls the authorizer and, if the request is allowed, sends it to the
+		// fallback handler.
+		func (a *Adapter) serveFallback(w http.ResponseWriter, r *http.Request) {
+			route := &helpers.Route{
+				Path:      r.URL.Path,
+				Variables: helpers.Variables(r.Context()),
+			}
+			r = r.WithContext(helpers.WithRoute(r.Context(), route))
+			record := helpers.AuditRecordFromContext(r.Context())
+			if record != nil {
+				record.Route = route
+			}
+			allowed, reason, err := helpers.Authorize(r.Context(), route)
+			if err != nil {
+				glog.Errorf(
+					"Can't authorize request for method '%s' and path '%s': %v",
+					r.Method, r.URL.Path, err,
+				)
+				errors.SendInternalServerError(w, r)
+				return
+			}
+			if !allowed {
+				errors.SendForbidden(w, r, reason)
+				return
+			}
+			a.fallback.ServeHTTP(w, r)
+		}
+
+		// finishAudit completes the given audit record with the result of the request and
+		// sends it to the audit sink.
+		func (a *Adapter) finishAudit(r *http.Request, w *auditWriter, record *helpers.AuditRecord) {
+			record.Duration = time.Since(record.Time)
+			record.Status = w.status
+			if record.Status == 0 {
+				if r.Context().Err() != nil {
+					record.Status = helpers.StatusClientClosedRequest
+				} else {
+					record.Status = http.StatusOK
+				}
+			}
+			record.Outcome = helpers.AuditOutcomeFromStatus(record.Status)
+			a.audit.Audit(r.Context(), record)
+		}
+
+		// deduplicate sends the response saved for the given key if it exists, or a conflict
+		// error if there is another request in progress with the same key. Otherwise it
+		// dispatches the request and saves the response if it is successful.
+		func (a *Adapter) deduplicate(w http.ResponseWriter, r *http.Request, key string) {
+			ctx := r.Context()
+			saved, reserved, err := a.idempotency.Reserve(ctx, key)
+			if err != nil {
+				glog.Errorf(
+					"Can't reserve idempotency key for method '%s' and path '%s': %v",
+					r.Method, r.URL.Path, err,
+				)
+				errors.SendInternalServerError(w, r)
+				return
+			}
+			if saved != nil {
+				header := w.Header()
+				for name, values := range saved.Header {
+					header[name] = helpers.CopyValues(values)
+				}
+				w.WriteHeader(saved.Status)
+				_, err = w.Write(saved.Body)
+				if err != nil {
+					glog.Errorf(
+						"Can't write saved response for method '%s' and path '%s': %v",
+						r.Method, r.URL.Path, err,
+					)
+				}
+				return
+			}
+			if !reserved {
+				errors.SendConflict(
+					w, r,
+					"Another request with the same idempotency key is in progress",
+				)
+				return
+			}
+			recorder := &idempotencyRecorder{
+				ResponseWriter: w,
+			}
+			completed := false
+			defer func() {
+				if completed {
+					return
+				}
+				err := a.idempotency.Release(ctx, key)
+				if err != nil {
+					glog.Errorf(
+						"Can't release idempotency key for method '%s' and path '%s': %v",
+						r.Method, r.URL.Path, err,
+					)
+				}
+			}()
+			a.dispatch(recorder, r)
+			recorder.save(http.StatusOK)
+			if recorder.status < 200 || recorder.status >= 300 {
+				return
+			}
+			err = a.idempotency.Put(ctx, key, &IdempotentResponse{
+				Status: recorder.status,
+				Header: recorder.header,
+				Body:   recorder.body.Bytes(),
+			})
+			if err != nil {
+				glog.Errorf(
+					"Can't save response for method '%s' and path '%s': %v",
+					r.Method, r.URL.Path, err,
+				)
+				return
+			}
+			completed = true
+		}
+
+		// acceptsGzip checks if the Accept-Encoding header of the given request contains the
+		// gzip coding with a quality value greater than zero.
+		func acceptsGzip(r *http.Request) bool {
+			for _, header := range r.Header["Accept-Encoding"] {
+				for _, item := range strings.Split(header, ",") {
+					params := strings.Split(item, ";")
+					coding := strings.TrimSpace(params[0])
+					if !strings.EqualFold(coding, "gzip") {
+						continue
+					}
+					quality := 1.0
+					for _, param := range params[1:] {
+						param = strings.TrimSpace(param)
+						if len(param) > 2 && strings.EqualFold(param[0:2], "q=") {
+							value, err := strconv.ParseFloat(param[2:], 64)
+							if err != nil {
+								value = 0
+							}
+							quality = value
+						}
+					}
+					return quality > 0
+				}
+			}
+			return false
+		}
+
+		// compressWriter is a response writer that compresses the body using gzip when it is
+		// larger than a threshold. Smaller bodies are sent without compression, unless the
+		// response is flushed before reaching the threshold.
+		type compressWriter struct {
+			http.ResponseWriter
+			threshold int
+			status    int
+			buffer    bytes.Buffer
+			writer    *gzip.Writer
+		}
+
+		func (w *compressWriter) WriteHeader(status int) {
+			if w.status == 0 {
+				w.status = status
+			}
+		}
+
+		func (w *compressWriter) Write(data []byte) (int, error) {
+			if w.status == 0 {
+				w.status = http.StatusOK
+			}
+			if w.writer != nil {
+				return w.writer.Write(data)
+			}
+			w.buffer.Write(data)
+			if w.buffer.Len() < w.threshold {
+				return len(data), nil
+			}
+			err := w.start()
+			if err != nil {
+				return 0, err
+			}
+			return len(data), nil
+		}
+
+		// Flush sends the data that has been written so far. As the final size of the body
+		// isn't known at this point, flushed responses are always compressed.
+		func (w *compressWriter) Flush() {
+			if w.status == 0 {
+				w.status = http.StatusOK
+			}
+			var err error
+			if w.writer == nil {
+				err = w.start()
+			}
+			if err == nil {
+				err = w.writer.Flush()
+			}
+			if err != nil {
+				glog.Errorf("Can't flush compressed response: %v", err)
+				return
+			}
+			flusher, ok := w.ResponseWriter.(http.Flusher)
+			if ok {
+				flusher.Flush()
+			}
+		}
+
+		// start sends the header of the response and the data that has been buffered so far,
+		// and switches to the gzip writer.
+		func (w *compressWriter) start() error {
+			header := w.Header()
+			header.Set("Content-Encoding", "gzip")
+			header.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(w.status)
+			w.writer = gzip.NewWriter(w.ResponseWriter)
+			_, err := w.writer.Write(w.buffer.Bytes())
+			w.buffer.Reset()
+			return err
+		}
+
+		// close sends the data that is still buffered. It must be called once the response
+		// is complete.
+		func (w *compressWriter) close() {
+			var err error
+			switch {
+			case w.writer != nil:
+				err = w.writer.Close()
+			case w.status != 0:
+				w.ResponseWriter.WriteHeader(w.status)
+				_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+			}
+			if err != nil {
+				glog.Errorf("Can't write compressed response: %v", err)
+			}
+		}
+
+		// auditWriter is a response writer that saves the status code of the response, so
+		// that it can be added to the audit record.
+		type auditWriter struct {
+			http.ResponseWriter
+			status int
+		}
+
+		func (w *auditWriter) WriteHeader(status int) {
+			if w.status == 0 {
+				w.status = status
+			}
+			w.ResponseWriter.WriteHeader(status)
+		}
+
+		func (w *auditWriter) Write(data []byte) (int, error) {
+			if w.status == 0 {
+				w.status = http.StatusOK
+			}
+			return w.ResponseWriter.Write(data)
+		}
+
+		func (w *auditWriter) Flush() {
+			flusher, ok := w.ResponseWriter.(http.Flusher)
+			if ok {
+				if w.status == 0 {
+					w.status = http.StatusOK
+				}
+				flusher.Flush()
+			}
+		}
+
+		// panicWriter is a response writer that remembers if the response has been started,
+		// so that the panic handler knows if it can still send an error response.
+		type panicWriter struct {
+			http.ResponseWriter
+			started bool
+		}
+
+		func (w *panicWriter) WriteHeader(status int) {
+			w.started = true
+			w.ResponseWriter.WriteHeader(status)
+		}
+
+		func (w *panicWriter) Write(data []byte) (int, error) {
+			w.started = true
+			return w.ResponseWriter.Write(data)
+		}
+
+		func (w *panicWriter) Flush() {
+			flusher, ok := w.ResponseWriter.(http.Flusher)
+			if ok {
+				w.started = true
+				flusher.Flush()
+			}
+		}
+
+		// idempotencyRecorder is a response writer that saves a copy of the status, headers
+		// and body of the response, so that it can be sent again if the client retries the
+		// request.
+		type idempotencyRecorder struct {
+			http.ResponseWriter
+			status int
+			header http.Header
+			body   bytes.Buffer
+		}
+
+		func (r *idempotencyRecorder) WriteHeader(status int) {
+			r.save(status)
+			r.ResponseWriter.WriteHeader(status)
+		}
+
+		func (r *idempotencyRecorder) Write(data []byte) (int, error) {
+			r.save(http.StatusOK)
+			r.body.Write(data)
+			return r.ResponseWriter.Write(data)
+		}
+
+		// save saves the status and the headers of the response the first time that it is
+		// called, before the response writers wrapped by this one add their own headers.
+		func (r *idempotencyRecorder) save(status int) {
+			if r.header != nil {
+				return
+			}
+			r.status = status
+			r.header = http.Header{}
+			for name, values := range r.ResponseWriter.Header() {
+				if idempotentHeader(name) {
+					r.header[name] = helpers.CopyValues(values)
+				}
+			}
+		}
+
+		// idempotentHeader checks if the given response header should be saved and sent again
+		// when a request is retried. Headers that depend on the particular request, like the
+		// operation identifier and the CORS headers, aren't saved. Neither are the headers
+		// that describe the encoding, because the saved body isn't compressed.
+		func idempotentHeader(name string) bool {
+			name = http.CanonicalHeaderKey(name)
+			switch name {
+			case "Content-Encoding", "Content-Length", "Vary":
+				return false
+			case http.CanonicalHeaderKey(helpers.OperationIDHeader):
+				return false
+			}
+			return !strings.HasPrefix(name, "Access-Control-")
+		}
+		`,
+		"Model", g.model,
+		"Slash", g.slash,
+	)
+}
+
+func (g *ServersGenerator) generateMainCORSSource() {
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("strconv", "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("main_cors", `
+		// CORSConfig contains the configuration for cross origin resource sharing.
+		type CORSConfig struct {
+			// AllowedOrigins is the list of origins that are allowed to send requests. The
+			// value '*' allows all origins, but it can't be used when credentials are
+			// allowed. Other values must match exactly the origin of the request.
+			AllowedOrigins []string
+
+			// AllowedMethods is the list of methods allowed in preflight requests. The
+			// default is all the methods used by the generated servers.
+			AllowedMethods []string
+
+			// AllowedHeaders is the list of headers allowed in preflight requests. The
+			// default is to allow the headers requested by the client.
+			AllowedHeaders []string
+
+			// ExposedHeaders is the list of response headers that the browser will make
+			// available to the client.
+			ExposedHeaders []string
+
+			// AllowCredentials indicates if the browser can send credentials.
+			AllowCredentials bool
+
+			// MaxAge is the time that the browser can cache the result of a preflight
+			// request. The default is to not send the header.
+			MaxAge time.Duration
+		}
+
+		// CORSHandler handles cross origin resource sharing for an adapter, using a
+		// configuration that has already been checked. Don't create instances of this type
+		// directly, use the NewCORSHandler function instead.
+		type CORSHandler struct {
+			config CORSConfig
+		}
+
+		// NewCORSHandler checks the given configuration and creates an object that handles
+		// cross origin resource sharing with it. Like in the Fetch specification, the '*'
+		// origin can't be used together with credentials, as that would allow any site to
+		// send credentialed requests, so in that case it returns an error.
+		func NewCORSHandler(config *CORSConfig) (*CORSHandler, error) {
+			if config == nil {
+				return nil, fmt.Errorf("CORS configuration is mandatory")
+			}
+			if config.AllowCredentials {
+				for _, value := range config.AllowedOrigins {
+					if value == "*" {
+						return nil, fmt.Errorf(
+							"CORS origin '*' can't be used when credentials are allowed",
+						)
+					}
+				}
+			}
+			return &CORSHandler{
+				config: *config,
+			}, nil
+		}
+
+		// handle adds the CORS headers to the response if the request comes from an allowed
+		// origin. It returns true if the request was a preflight request and it has been
+		// completely answered.
+		func (h *CORSHandler) handle(w http.ResponseWriter, r *http.Request) bool {
+			c := &h.config
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				return false
+			}
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			wildcard := false
+			exact := false
+			for _, value := range c.AllowedOrigins {
+				switch value {
+				case "*":
+					wildcard = !c.AllowCredentials
+				case origin:
+					exact = true
+				}
+			}
+			switch {
+			case exact:
+				header.Set("Access-Control-Allow-Origin", origin)
+			case wildcard:
+				header.Set("Access-Control-Allow-Origin", "*")
+			default:
+				return false
+			}
+			if c.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+			requested := r.Header.Get("Access-Control-Request-Method")
+			if r.Method != http.MethodOptions || requested == "" {
+				if len(c.ExposedHeaders) > 0 {
+					header.Set("Access-Control-Expose-Headers", strings.Join(c.ExposedHeaders, ", "))
+				}
+				return false
+			}
+			methods := c.AllowedMethods
+			if len(methods) == 0 {
+				methods = []string{
+					http.MethodGet,
+					http.MethodPost,
+					http.MethodPatch,
+					http.MethodDelete,
+				}
+			}
+			header.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if len(c.AllowedHeaders) > 0 {
+				header.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+			} else {
+				requested := r.Header.Get("Access-Control-Request-Headers")
+				if requested != "" {
+					header.Set("Access-Control-Allow-Headers", requested)
+				}
+			}
+			if c.MaxAge > 0 {
+				seconds := int(c.MaxAge / time.Second)
+				header.Set("Access-Control-Max-Age", strconv.Itoa(seconds))
+			}
+			w.WriteHeader(http.StatusNoContent)
+			return true
+		}
+		`)
+}
+
+func (g *ServersGenerator) generateMainHealthSource() {
+	g.buffer.Import("context", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("net/http", "")
+	g.buffer.Import("github.com/golang/glog", "")
+	g.buffer.Import(g.packages.ErrorsImport(), "")
+	g.buffer.EmitTemplate("main_health", `
+		// Paths of the probes answered by the adapters created with NewAdapter:
+		const (
+			HealthzPath = "/healthz"
+			ReadyzPath  = "/readyz"
+		)
+
+		// HealthCheck is a function that checks the health of the server or of one of its
+		// dependencies. It should return nil if it is healthy, or an error explaining the
+		// problem otherwise.
+		type HealthCheck func(ctx context.Context) error
+
+		// LivenessChecker is the interface that the server can optionally implement in order
+		// to report if it is alive. When the check fails the '/healthz' probe returns a 503
+		// status code, and the orchestrator will usually restart the process.
+		type LivenessChecker interface {
+			CheckLiveness(ctx context.Context) error
+		}
+
+		// ReadinessChecker is the interface that the server can optionally implement in
+		// order to report if it is ready to process requests. When the check fails the
+		// '/readyz' probe returns a 503 status code, and the orchestrator will usually stop
+		// sending requests to the process.
+		type ReadinessChecker interface {
+			CheckReadiness(ctx context.Context) error
+		}
+
+		// sendProbe executes the given checks and sends the result. The status code is 200
+		// if all the checks succeed and 503 otherwise. The body contains the result of each
+		// check, which is 'ok' or the error message.
+		func sendProbe(w http.ResponseWriter, r *http.Request, checks map[string]HealthCheck) {
+			if r.Method != http.MethodGet && r.Method != http.MethodHead {
+				w.Header().Set("Allow", "GET, HEAD")
+				errors.SendMethodNotAllowed(w, r)
+				return
+			}
+			status := http.StatusOK
+			results := make(map[string]string, len(checks))
+			for name, check := range checks {
+				err := check(r.Context())
+				if err != nil {
+					glog.Warningf("Check '%s' of probe '%s' failed: %v", name, r.URL.Path, err)
+					results[name] = err.Error()
+					status = http.StatusServiceUnavailable
+				} else {
+					results[name] = "ok"
+				}
+			}
+			result := map[string]interface{}{
+				"status": "ok",
+			}
+			if status != http.StatusOK {
+				result["status"] = "failed"
+			}
+			if len(results) > 0 {
+				result["checks"] = results
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("Cache-Control", "no-store")
+			w.WriteHeader(status)
+			if r.Method == http.MethodHead {
+				return
+			}
+			err := json.NewEncoder(w).Encode(result)
+			if err != nil {
+				glog.Errorf("Can't write result of probe '%s': %v", r.URL.Path, err)
+			}
+		}
+		`,
 	)
 }
 
-func (g *ServersGenerator) generateMainDispatcherSource() {
+func (g *ServersGenerator) generateMainLoggingSource() {
+	g.buffer.Import("context", "")
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("fmt", "")
 	g.buffer.Import("net/http", "")
-	g.buffer.Import(g.packages.ErrorsImport(), "")
+	g.buffer.Import("strings", "")
+	g.buffer.Import("time", "")
+	g.buffer.Import("github.com/golang/glog", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
-		// Dispatch navigates the servers tree till it finds one that matches the given set
-		// of path segments, and then invokes it.
-		func Dispatch(w http.ResponseWriter, r *http.Request, server Server, segments []string) {
-			if len(segments) == 0 {
-				// TODO: This should send the metadata.
-				errors.SendMethodNotAllowed(w, r)
-				return
-			} else {
-				switch segments[0] {
-				{{ range .Model.Services }}
-					{{ $serviceName := serviceName . }}
-					{{ $serviceSelector := serviceSelector . }}
+	g.buffer.EmitTemplate("main_logging", `
+		// RequestLogger is a middleware that writes to the log a line for each request
+		// processed by the adapter. The line contains the HTTP method and path, the route
+		// of the method of the model and the path variables, the status code and the
+		// duration. Optionally it can also contain the parameters of the request, including
+		// the bodies, with the values of the attributes marked as sensitive in the model
+		// replaced by asterisks. For example:
+		//
+		//	logger := NewRequestLogger().Bodies(true)
+		//	adapter := NewAdapter(server, logger.Middleware)
+		//
+		// Don't create instances of this type directly, use the NewRequestLogger function
+		// instead.
+		type RequestLogger struct {
+			bodies bool
+			logf   func(ctx context.Context, format string, args ...interface{})
+		}
 
-					case "{{ serviceSegment . }}":
-						service := server.{{ $serviceName }}()
-						if service == nil {
-							errors.SendNotFound(w, r)
-							return
-						}
-						{{ $serviceSelector }}.Dispatch(w, r, service, segments[1:])
-				{{ end }}
-				default:
-					errors.SendNotFound(w, r)
-					return
-				}
+		// NewRequestLogger creates a new request logger that writes to the log using the
+		// glog.Infof function and doesn't include the parameters of the requests.
+		func NewRequestLogger() *RequestLogger {
+			return &RequestLogger{
+				logf: func(ctx context.Context, format string, args ...interface{}) {
+					glog.InfoDepth(1, fmt.Sprintf(format, args...))
+				},
 			}
 		}
 
-		// Adapter is an HTTP handler that knows how to translate HTTP requests into calls
-		// to the methods of an object that implements the Server interface.
-		type Adapter struct {
-			server Server
+		// Bodies sets the flag that indicates if the parameters of the requests, including
+		// the bodies, should be included in the log. Values of attributes marked as
+		// sensitive in the model are always redacted. The default is false.
+		func (l *RequestLogger) Bodies(value bool) *RequestLogger {
+			l.bodies = value
+			return l
 		}
 
-		// NewAdapter creates a new adapter that will translate HTTP requests into calls to
-		// the given server.
-		func NewAdapter(server Server) *Adapter {
-			return &Adapter{
-				server: server,
-			}
+		// Logf sets the function that will be used to write the log lines. The default is to
+		// use the glog.Infof function.
+		func (l *RequestLogger) Logf(value func(ctx context.Context, format string,
+			args ...interface{})) *RequestLogger {
+			l.logf = value
+			return l
 		}
 
-		// ServeHTTP is the implementation of the http.Handler interface.
-		func (a *Adapter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-			Dispatch(w, r, a.server, helpers.Segments(r.URL.Path))
+		// Middleware returns a handler that logs the requests and passes them to the given
+		// handler. It is intended for use with NewAdapter and NewSubtreeAdapter.
+		func (l *RequestLogger) Middleware(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				start := time.Now()
+				record := helpers.AuditRecordFromContext(r.Context())
+				if record == nil {
+					record = &helpers.AuditRecord{}
+					r = r.WithContext(helpers.WithAuditRecord(r.Context(), record))
+				}
+				recorder := &auditWriter{
+					ResponseWriter: w,
+				}
+				next.ServeHTTP(recorder, r)
+				status := recorder.status
+				if status == 0 {
+					status = http.StatusOK
+				}
+				l.log(r, w, record, status, time.Since(start))
+			})
+		}
+
+		// log writes the log line for the given request.
+		func (l *RequestLogger) log(r *http.Request, w http.ResponseWriter,
+			record *helpers.AuditRecord, status int, duration time.Duration) {
+			format := &strings.Builder{}
+			var args []interface{}
+			format.WriteString("Method '%s', path '%s'")
+			args = append(args, r.Method, r.URL.Path)
+			operationID := w.Header().Get(helpers.OperationIDHeader)
+			if operationID != "" {
+				format.WriteString(", operation '%s'")
+				args = append(args, operationID)
+			}
+			route := helpers.RouteFromContext(r.Context())
+			if route != nil {
+				format.WriteString(", service '%s', version '%s', resource '%s', method '%s'")
+				args = append(args, route.Service, route.Version, route.Resource, route.Method)
+				if len(route.Variables) > 0 {
+					format.WriteString(", variables %v")
+					args = append(args, route.Variables)
+				}
+			}
+			if l.bodies && record.Request != nil {
+				parameters, err := json.Marshal(record.Request)
+				if err == nil {
+					format.WriteString(", parameters %s")
+					args = append(args, parameters)
+				}
+			}
+			format.WriteString(", status %d, duration %s")
+			args = append(args, status, duration)
+			l.logf(r.Context(), format.String(), args...)
 		}
 		`,
-		"Model", g.model,
 	)
 }
 
@@ -286,9 +1525,15 @@ func (g *ServersGenerator) generateServiceServer(service *concepts.Service) erro
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("servers").
 		Package(pkgName).
 		File(fileName).
 		Function("serverName", g.serverName).
+		Function("serviceAllowedMethods", g.serviceAllowedMethods).
 		Function("versionName", g.versionName).
 		Function("versionSelector", g.packages.VersionSelector).
 		Function("versionSegment", g.binding.VersionSegment).
@@ -296,6 +1541,7 @@ func (g *ServersGenerator) generateServiceServer(service *concepts.Service) erro
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the source for the service:
 	g.generateServiceServerSource(service)
@@ -320,7 +1566,7 @@ func (g *ServersGenerator) generateServiceServerSource(service *concepts.Service
 	for _, version := range service.Versions() {
 		g.buffer.Import(g.packages.VersionImport(version), "")
 	}
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("service_server", `
 		// Server is the interface for the '{{ .Service.Name }}' service.
 		type Server interface {
 			{{ range .Service.Versions }}
@@ -339,14 +1585,25 @@ func (g *ServersGenerator) generateServiceServerSource(service *concepts.Service
 
 func (g *ServersGenerator) generateServiceDispatcherSource(service *concepts.Service) {
 	g.buffer.Import("net/http", "")
+	g.buffer.Import("strings", "")
 	g.buffer.Import(g.packages.ErrorsImport(), "")
-	g.buffer.Emit(`
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("service_dispatcher", `
 		// Dispatch navigates the servers tree till it finds one that matches the given set
 		// of path segments, and then invokes it.
 		func Dispatch(w http.ResponseWriter, r *http.Request, server Server, segments []string) {
 			if len(segments) == 0 {
-				// TODO: This should send the service metadata.
-				errors.SendMethodNotAllowed(w, r)
+				switch r.Method {
+				case "GET":
+					sendVersions(w, r, server)
+				case "HEAD":
+					sendVersions(helpers.DiscardBody(w), r, server)
+				case "OPTIONS":
+					helpers.SendOptions(w, "{{ serviceAllowedMethods .Service }}")
+				default:
+					w.Header().Set("Allow", "{{ serviceAllowedMethods .Service }}")
+					errors.SendMethodNotAllowed(w, r)
+				}
 				return
 			} else {
 				switch segments[0] {
@@ -357,7 +1614,7 @@ func (g *ServersGenerator) generateServiceDispatcherSource(service *concepts.Ser
 					case "{{ versionSegment . }}":
 						version := server.{{ $versionName }}()
 						if version == nil {
-							errors.SendNotFound(w, r)
+							helpers.Fallback(w, r, errors.SendNotFound)
 							return
 						}
 						{{ $versionSelector }}.Dispatch(w, r, version, segments[1:])
@@ -368,6 +1625,43 @@ func (g *ServersGenerator) generateServiceDispatcherSource(service *concepts.Ser
 				}
 			}
 		}
+
+		// sendVersions sends the list of versions of the service that are implemented by
+		// the given server, so that clients can select the version that they want to use.
+		func sendVersions(w http.ResponseWriter, r *http.Request, server Server) {
+			base := strings.TrimSuffix(r.URL.Path, "/")
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			stream := helpers.NewStream(w)
+			stream.WriteObjectStart()
+			stream.WriteObjectField("kind")
+			stream.WriteString("APIVersionList")
+			stream.WriteMore()
+			stream.WriteObjectField("items")
+			stream.WriteArrayStart()
+			count := 0
+			{{ range .Service.Versions }}
+				if server.{{ versionName . }}() != nil {
+					if count > 0 {
+						stream.WriteMore()
+					}
+					stream.WriteObjectStart()
+					stream.WriteObjectField("kind")
+					stream.WriteString("APIVersionLink")
+					stream.WriteMore()
+					stream.WriteObjectField("id")
+					stream.WriteString("{{ versionSegment . }}")
+					stream.WriteMore()
+					stream.WriteObjectField("href")
+					stream.WriteString(base + "/{{ versionSegment . }}")
+					stream.WriteObjectEnd()
+					count++
+				}
+			{{ end }}
+			stream.WriteArrayEnd()
+			stream.WriteObjectEnd()
+			stream.Flush()
+		}
 		`,
 		"Service", service,
 	)
@@ -395,20 +1689,32 @@ func (g *ServersGenerator) generateResourceServer(resource *concepts.Resource) e
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("servers").
 		Package(pkgName).
 		File(fileName).
 		Function("adaptRequestName", g.adaptRequestName).
+		Function("allowedMethods", g.allowedMethods).
+		Function("currentServerName", g.currentServerName).
 		Function("defaultStatus", g.binding.DefaultStatus).
 		Function("dispatchName", g.dispatchName).
+		Function("dispatcherName", g.dispatcherName).
 		Function("fieldName", g.fieldName).
 		Function("fieldType", g.fieldType).
 		Function("getterName", g.getterName).
 		Function("getterType", g.getterType).
 		Function("httpMethod", g.binding.Method).
+		Function("immutableBody", g.immutableBody).
 		Function("jsonFieldName", g.jsonFieldName).
 		Function("jsonFieldType", g.jsonFieldType).
 		Function("locatorName", g.locatorName).
 		Function("locatorSegment", g.binding.LocatorSegment).
+		Function("marshalFunc", g.marshalFunc).
+		Function("metadataEndpoint", g.binding.MetadataEndpoint).
+		Function("methodKind", g.methodKind).
 		Function("methodName", g.methodName).
 		Function("methodSegment", g.binding.MethodSegment).
 		Function("parameterName", g.binding.ParameterName).
@@ -420,17 +1726,21 @@ func (g *ServersGenerator) generateResourceServer(resource *concepts.Resource) e
 		Function("responseBodyParameters", g.binding.ResponseBodyParameters).
 		Function("responseName", g.responseName).
 		Function("responseParameters", g.binding.ResponseParameters).
+		Function("sensitivePaths", g.binding.SensitivePaths).
 		Function("serverName", g.serverName).
 		Function("setterName", g.setterName).
 		Function("setterType", g.setterType).
 		Function("structName", g.types.StructName).
+		Function("summarizeRequestFunc", g.summarizeRequestFunc).
 		Function("writeFunc", g.writeFunc).
+		Function("writeCBORResponseFunc", g.writeCBORResponseFunc).
 		Function("writeResponseFunc", g.writeResponseFunc).
 		Function("zeroValue", g.types.ZeroValue).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the source:
 	g.generateResourceServerSource(resource)
@@ -441,7 +1751,8 @@ func (g *ServersGenerator) generateResourceServer(resource *concepts.Resource) e
 }
 
 func (g *ServersGenerator) generateResourceServerSource(resource *concepts.Resource) {
-	g.buffer.Emit(`
+	g.buffer.Import("context", "")
+	g.buffer.EmitTemplate("resource_server", `
 		{{ $serverName := serverName .Resource }}
 
 		// {{ $serverName }} represents the interface the manages the '{{ .Resource.Name }}' resource.
@@ -473,6 +1784,58 @@ func (g *ServersGenerator) generateResourceServerSource(resource *concepts.Resou
 				{{ end }}
 			{{ end }}
 		}
+
+		{{ range .Resource.Methods }}
+			{{ with immutableBody . }}
+				{{ $currentServerName := currentServerName $.Resource }}
+
+				// {{ $currentServerName }} is the interface that the {{ $serverName }} can
+				// optionally implement in order to return the current version of the object.
+				// When it is implemented the adapter uses it to reject with a 400 status code
+				// the update requests that try to change attributes that can't be changed once
+				// the object has been created, before calling the update method.
+				type {{ $currentServerName }} interface {
+					// Current returns the current version of the object, or nil if it doesn't
+					// exist.
+					Current(ctx context.Context) ({{ getterType . }}, error)
+				}
+			{{ end }}
+		{{ end }}
+
+		// Unimplemented{{ $serverName }} is an implementation of the {{ $serverName }}
+		// interface where all the methods send a 501 response and all the locators return
+		// nil. It is intended to be embedded in the implementations of the interface, so
+		// that they don't break when new methods are added to the model.
+		type Unimplemented{{ $serverName }} struct {
+		}
+
+		// Make sure that we implement the interface:
+		var _ {{ $serverName }} = &Unimplemented{{ $serverName }}{}
+
+		{{ range .Resource.Methods }}
+			{{ $methodName := methodName . }}
+			// {{ $methodName }} returns helpers.ErrNotImplemented.
+			func (s *Unimplemented{{ $serverName }}) {{ $methodName }}(ctx context.Context,
+				request *{{ requestName . }}, response *{{ responseName . }}) error {
+				return helpers.ErrNotImplemented
+			}
+		{{ end }}
+
+		{{ range .Resource.Locators }}
+			{{ $locatorName := locatorName . }}
+			{{ $targetName := serverName .Target }}
+			{{ if .Variable }}
+				// {{ $locatorName }} returns nil.
+				func (s *Unimplemented{{ $serverName }}) {{ $locatorName }}(id string) {{ $targetName }} {
+					return nil
+				}
+			{{ else }}
+				// {{ $locatorName }} returns nil.
+				func (s *Unimplemented{{ $serverName }}) {{ $locatorName }}() {{ $targetName }} {
+					return nil
+				}
+			{{ end }}
+		{{ end }}
 		`,
 		"Resource", resource,
 	)
@@ -485,13 +1848,28 @@ func (g *ServersGenerator) generateResourceServerSource(resource *concepts.Resou
 }
 
 func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.Resource) {
+	g.buffer.Import("context", "")
+	g.buffer.Import("errors", "goerrors")
 	g.buffer.Import("fmt", "")
+	g.buffer.Import("io", "")
 	g.buffer.Import("net/http", "")
+	g.buffer.Import("time", "")
+	g.buffer.Import("github.com/golang/glog", "")
 	g.buffer.Import(g.packages.ErrorsImport(), "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("resource_dispatcher", `
 		{{ $serverName := serverName .Resource }}
 		{{ $dispatchName := dispatchName .Resource }}
+		{{ $dispatcherName := dispatcherName .Resource }}
+
+		// {{ $dispatcherName }} returns a dispatcher that sends requests to the servers tree
+		// rooted at the given server. It can be used with NewSubtreeAdapter to serve only
+		// this part of the tree.
+		func {{ $dispatcherName }}(server {{ $serverName }}) helpers.Dispatcher {
+			return func(w http.ResponseWriter, r *http.Request, segments []string) {
+				{{ $dispatchName }}(w, r, server, segments)
+			}
+		}
 
 		// {{ $dispatchName }} navigates the servers tree rooted at the given server
 		// till it finds one that matches the given set of path segments, and then invokes
@@ -499,15 +1877,33 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 		func {{ $dispatchName }}(w http.ResponseWriter, r *http.Request, server {{ $serverName }}, segments []string) {
 			if len(segments) == 0 {
 				switch r.Method {
+				{{ if metadataEndpoint .Resource }}
+					case "GET":
+						sendMetadata(w, r, server)
+						return
+					case "HEAD":
+						sendMetadata(helpers.DiscardBody(w), r, server)
+						return
+				{{ end }}
 				{{ range .Resource.Methods }}
 					{{ $methodSegment := methodSegment . }}
 					{{ if not $methodSegment }}
-						case "{{ httpMethod . }}":
+						{{ $httpMethod := httpMethod . }}
+						case "{{ $httpMethod }}":
 							{{ adaptRequestName . }}(w, r, server)
 							return
+						{{ if eq $httpMethod "GET" }}
+							case "HEAD":
+								{{ adaptRequestName . }}(helpers.DiscardBody(w), r, server)
+								return
+						{{ end }}
 					{{ end }}
 				{{ end }}
+				case "OPTIONS":
+					helpers.SendOptions(w, "{{ allowedMethods .Resource }}")
+					return
 				default:
+					w.Header().Set("Allow", "{{ allowedMethods .Resource }}")
 					errors.SendMethodNotAllowed(w, r)
 					return
 				}
@@ -517,7 +1913,12 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 				{{ $methodSegment := methodSegment . }}
 				{{ if $methodSegment }}
 					case "{{ methodSegment . }}":
+						if r.Method == "OPTIONS" {
+							helpers.SendOptions(w, "POST, OPTIONS")
+							return
+						}
 						if r.Method != "POST" {
+							w.Header().Set("Allow", "POST, OPTIONS")
 							errors.SendMethodNotAllowed(w, r)
 							return
 						}
@@ -529,7 +1930,7 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 				case "{{ locatorSegment . }}":
 					target := server.{{ locatorName . }}()
 					if target == nil {
-						errors.SendNotFound(w, r)
+						helpers.Fallback(w, r, errors.SendNotFound)
 						return
 					}
 					{{ dispatchName .Target }}(w, r, target, segments[1:])
@@ -537,6 +1938,7 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 			default:
 				{{ if .Resource.VariableLocator }}
 					{{ with .Resource.VariableLocator }}
+						r = helpers.AddVariable(r, "{{ locatorSegment . }}", segments[0])
 						target := server.{{ locatorName . }}(segments[0])
 						if target == nil {
 							errors.SendNotFound(w, r)
@@ -559,13 +1961,68 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 			{{ $requestBodyParameters := requestBodyParameters . }}
 			{{ $requestBodyLen := len $requestBodyParameters }}
 			{{ $responseParameters := responseParameters . }}
+			{{ $responseBodyParameters := responseBodyParameters . }}
+			{{ $cbor := and $.CBOR (or .IsGet .IsAdd .IsUpdate) }}
 
 			// {{ $adaptRequestName }} translates the given HTTP request into a call to
 			// the corresponding method of the given server. Then it translates the
 			// results returned by that method into an HTTP response.
 			func {{ $adaptRequestName }}(w http.ResponseWriter, r *http.Request, server {{ $serverName }}) {
+				route := &helpers.Route{
+					Service:   "{{ .Owner.Owner.Owner.Name.Snake }}",
+					Version:   "{{ .Owner.Owner.Name.Snake }}",
+					Resource:  "{{ .Owner.Name.Snake }}",
+					Method:    "{{ .Name.Snake }}",
+					Path:      r.URL.Path,
+					Variables: helpers.Variables(r.Context()),
+				}
+				r = r.WithContext(helpers.WithRoute(r.Context(), route))
+				record := helpers.AuditRecordFromContext(r.Context())
+				if record != nil {
+					record.Route = route
+				}
+				allowed, reason, err := helpers.Authorize(r.Context(), route)
+				if err != nil {
+					glog.Errorf(
+						"Can't authorize request for method '%s' and path '%s': %v",
+						r.Method, r.URL.Path, err,
+					)
+					errors.SendInternalServerError(w, r)
+					return
+				}
+				if !allowed {
+					errors.SendForbidden(w, r, reason)
+					return
+				}
+				ctx, cancel := helpers.WithMethodTimeout(r.Context(), "{{ methodKind . }}")
+				defer cancel()
+				r = r.WithContext(ctx)
+				{{ if $responseBodyParameters }}
+					contentType, acceptable := helpers.NegotiateContentType(
+						r,
+						helpers.JSONContentType,
+						helpers.YAMLContentType,
+						{{ if $cbor }}
+							helpers.CBORContentType,
+						{{ end }}
+					)
+					if !acceptable {
+						errors.SendNotAcceptable(w, r)
+						return
+					}
+				{{ end }}
 				request := &{{ $requestName }}{}
-				err := {{ readRequestFunc . }}(request, r)
+				request.impersonatedUser, request.impersonatedGroups = helpers.Impersonation(r)
+				err = {{ readRequestFunc . }}(request, r)
+				if goerrors.Is(err, helpers.ErrBodyTooLarge) {
+					errors.SendRequestEntityTooLarge(w, r)
+					return
+				}
+				var errs helpers.ValidationErrors
+				if goerrors.As(err, &errs) {
+					errors.SendBadRequest(w, r, errs.Error())
+					return
+				}
 				if err != nil {
 					glog.Errorf(
 						"Can't read request for method '%s' and path '%s': %v",
@@ -574,19 +2031,121 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 					errors.SendInternalServerError(w, r)
 					return
 				}
+				if record != nil {
+					record.Request = {{ summarizeRequestFunc . }}(request)
+				}
+				{{ if immutableBody . }}
+					if source, ok := server.({{ currentServerName .Owner }}); ok {
+						current, err := source.Current(r.Context())
+						if err != nil {
+							glog.Errorf(
+								"Can't get current object for method '%s' and path '%s': %v",
+								r.Method, r.URL.Path, err,
+							)
+							errors.SendInternalServerError(w, r)
+							return
+						}
+						err = request.CheckImmutable(current)
+						if err != nil {
+							errors.SendBadRequest(w, r, err.Error())
+							return
+						}
+					}
+				{{ end }}
 				response := &{{ $responseName }}{}
 				response.status = {{ defaultStatus . }}
-				err = server.{{ $methodName }}(r.Context(), request, response)
+				{{ if .IsPing }}
+					// The time of ping responses is always populated, so that servers
+					// only need to populate the version:
+					response.Time(time.Now().UTC())
+				{{ end }}
+				err = helpers.Intercept(r.Context(), request, response, func(ctx context.Context) error {
+					return server.{{ $methodName }}(ctx, request, response)
+				})
+				if goerrors.Is(err, helpers.ErrNotImplemented) {
+					errors.SendNotImplemented(w, r)
+					return
+				}
+				if goerrors.As(err, &errs) {
+					errors.SendBadRequest(w, r, errs.Error())
+					return
+				}
 				if err != nil {
-					glog.Errorf(
-						"Can't process request for method '%s' and path '%s': %v",
-						r.Method, r.URL.Path, err,
+					switch helpers.ContextStatus(r.Context(), err) {
+					case helpers.StatusClientClosedRequest:
+						glog.Infof(
+							"Client closed request for method '%s' and path '%s': %v",
+							r.Method, r.URL.Path, err,
+						)
+						errors.SendClientClosedRequest(w, r)
+					case http.StatusGatewayTimeout:
+						glog.Errorf(
+							"Deadline exceeded for method '%s' and path '%s': %v",
+							r.Method, r.URL.Path, err,
+						)
+						errors.SendGatewayTimeout(w, r)
+					default:
+						glog.Errorf(
+							"Can't process request for method '%s' and path '%s': %v",
+							r.Method, r.URL.Path, err,
+						)
+						errors.SendInternalServerError(w, r)
+					}
+					return
+				}
+				if r.Context().Err() == context.Canceled {
+					glog.Infof(
+						"Client closed request for method '%s' and path '%s' before "+
+							"the response was sent",
+						r.Method, r.URL.Path,
 					)
-					errors.SendInternalServerError(w, r)
 					return
 				}
-				err = {{ writeResponseFunc . }}(response, w)
+				{{ if or .IsGet .IsList }}
+					if !response.lastModified.IsZero() {
+						w.Header().Set("Last-Modified", response.lastModified.UTC().Format(http.TimeFormat))
+						if helpers.NotModified(r, response.lastModified) {
+							w.WriteHeader(http.StatusNotModified)
+							return
+						}
+					}
+				{{ end }}
+				{{ if $responseBodyParameters }}
+					// Responses with the 204 status can't have a body, so in that case only
+					// the status is sent:
+					if response.status == http.StatusNoContent {
+						w.WriteHeader(response.status)
+						return
+					}
+					w.Header().Set("Content-Type", contentType)
+					w.WriteHeader(response.status)
+					switch contentType {
+					{{ if $cbor }}
+						case helpers.CBORContentType:
+							err = {{ writeCBORResponseFunc . }}(response, w)
+					{{ end }}
+					case helpers.YAMLContentType:
+						writer := helpers.NewYAMLWriter(w)
+						err = {{ writeResponseFunc . }}(response, writer)
+						if err == nil {
+							err = writer.Close()
+						}
+					default:
+						err = {{ writeResponseFunc . }}(response, w)
+					}
+				{{ else }}
+					w.WriteHeader(response.status)
+					err = {{ writeResponseFunc . }}(response, w)
+				{{ end }}
 				if err != nil {
+					if r.Context().Err() != nil {
+						glog.Infof(
+							"Client closed request for method '%s' and path '%s' "+
+								"while the response was sent: %v",
+							r.Method, r.URL.Path, err,
+						)
+						return
+					}
 					glog.Errorf(
 						"Can't write response for method '%s' and path '%s': %v",
 						r.Method, r.URL.Path, err,
@@ -594,9 +2153,103 @@ func (g *ServersGenerator) generateResourceDispatcherSource(resource *concepts.R
 					return
 				}
 			}
+
+			{{ $summarizeRequestFunc := summarizeRequestFunc . }}
+
+			// {{ $summarizeRequestFunc }} creates the summary of the parameters of the
+			// given request that is added to the audit records.
+			func {{ $summarizeRequestFunc }}(request *{{ $requestName }}) map[string]interface{} {
+				summary := map[string]interface{}{}
+				{{ range requestParameters . }}
+					{{ $fieldName := fieldName . }}
+					{{ $parameterName := parameterName . }}
+					if request.{{ $fieldName }} != nil {
+						{{ if .IsItems }}
+							summary["{{ $parameterName }}"] = helpers.AuditValue(
+								func(writer io.Writer) error {
+									return {{ marshalFunc .Type }}(request.{{ $fieldName }}.Slice(), writer)
+								},
+								{{ range sensitivePaths .Type }}
+									{{ printf "%q" . }},
+								{{ end }}
+							)
+						{{ else if or .Type.IsStruct (and .Type.IsList .Type.Element.IsStruct) }}
+							summary["{{ $parameterName }}"] = helpers.AuditValue(
+								func(writer io.Writer) error {
+									return {{ marshalFunc .Type }}(request.{{ $fieldName }}, writer)
+								},
+								{{ range sensitivePaths .Type }}
+									{{ printf "%q" . }},
+								{{ end }}
+							)
+						{{ else if and .Type.IsMap .Type.Element.IsStruct }}
+							values := map[string]interface{}{}
+							for key, value := range request.{{ $fieldName }} {
+								values[key] = helpers.AuditValue(
+									func(writer io.Writer) error {
+										return {{ marshalFunc .Type.Element }}(value, writer)
+									},
+									{{ range sensitivePaths .Type.Element }}
+										{{ printf "%q" . }},
+									{{ end }}
+								)
+							}
+							summary["{{ $parameterName }}"] = values
+						{{ else if .Type.IsScalar }}
+							summary["{{ $parameterName }}"] = *request.{{ $fieldName }}
+						{{ else }}
+							summary["{{ $parameterName }}"] = request.{{ $fieldName }}
+						{{ end }}
+					}
+				{{ end }}
+				return summary
+			}
+		{{ end }}
+
+		{{ if metadataEndpoint .Resource }}
+			{{ $serverName := serverName .Resource }}
+
+			// MetadataServer is the interface that the server of the version can optionally
+			// implement in order to return the metadata of the version. When it isn't
+			// implemented the metadata endpoint returns an empty object.
+			type MetadataServer interface {
+				// Metadata returns the metadata of the version.
+				Metadata(ctx context.Context) (*Metadata, error)
+			}
+
+			// sendMetadata sends the metadata of the version, obtained from the given server if
+			// it implements the MetadataServer interface.
+			func sendMetadata(w http.ResponseWriter, r *http.Request, server {{ $serverName }}) {
+				metadata := &Metadata{}
+				provider, ok := server.(MetadataServer)
+				if ok {
+					result, err := provider.Metadata(r.Context())
+					if err != nil {
+						glog.Errorf(
+							"Can't get metadata for path '%s': %v",
+							r.URL.Path, err,
+						)
+						errors.SendInternalServerError(w, r)
+						return
+					}
+					if result != nil {
+						metadata = result
+					}
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+				err := MarshalMetadata(metadata, w)
+				if err != nil {
+					glog.Errorf(
+						"Can't write metadata for path '%s': %v",
+						r.URL.Path, err,
+					)
+				}
+			}
 		{{ end }}
 		`,
 		"Resource", resource,
+		"CBOR", g.cbor,
 	)
 }
 
@@ -616,7 +2269,8 @@ func (g *ServersGenerator) generateRequestSource(method *concepts.Method) {
 	// Generate the code:
 	g.buffer.Import("encoding/json", "")
 	g.buffer.Import("io", "")
-	g.buffer.Emit(`
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("request", `
 		{{ $requestName := requestName .Method }}
 		{{ $requestParameters := requestParameters .Method }}
 		{{ $requestBodyParameters := requestBodyParameters .Method }}
@@ -624,11 +2278,74 @@ func (g *ServersGenerator) generateRequestSource(method *concepts.Method) {
 
 		// {{ $requestName }} is the request for the '{{ .Method.Name }}' method.
 		type {{ $requestName }} struct {
+			impersonatedUser   string
+			impersonatedGroups []string
+			{{ if .Method.IsAdd }}
+				idempotencyKey *string
+			{{ end }}
 			{{ range $requestParameters }}
 				{{ fieldName . }} {{ fieldType . }}
 			{{ end }}
 		}
 
+		// ImpersonatedUser returns the user that the client asked to impersonate, or an empty
+		// string if the client didn't request impersonation. The adapter doesn't check if the
+		// client is allowed to impersonate, the server needs to do it.
+		func (r *{{ $requestName }}) ImpersonatedUser() string {
+			if r == nil {
+				return ""
+			}
+			return r.impersonatedUser
+		}
+
+		// ImpersonatedGroups returns the groups that the client asked to impersonate.
+		func (r *{{ $requestName }}) ImpersonatedGroups() []string {
+			if r == nil {
+				return nil
+			}
+			return r.impersonatedGroups
+		}
+
+		{{ if .Method.IsAdd }}
+			// IdempotencyKey returns the key sent by the client to detect retries of this
+			// request, or an empty string if the client didn't send it.
+			func (r *{{ $requestName }}) IdempotencyKey() string {
+				if r != nil && r.idempotencyKey != nil {
+					return *r.idempotencyKey
+				}
+				return ""
+			}
+
+			// GetIdempotencyKey returns the key sent by the client to detect retries of this
+			// request and a flag indicating if the client sent it.
+			func (r *{{ $requestName }}) GetIdempotencyKey() (value string, ok bool) {
+				ok = r != nil && r.idempotencyKey != nil
+				if ok {
+					value = *r.idempotencyKey
+				}
+				return
+			}
+		{{ end }}
+
+		{{ if .Immutable }}
+			// CheckImmutable compares the body of the request with the given current version
+			// of the object, and returns an error if the request tries to change attributes
+			// that can't be changed once the object has been created. Servers should call it
+			// before applying the update, and return the error, so that the adapter sends it
+			// to the client with a 400 status code.
+			func (r *{{ $requestName }}) CheckImmutable(current {{ getterType .Main }}) error {
+				if r == nil {
+					return nil
+				}
+				var errs helpers.ValidationErrors
+				r.{{ fieldName .Main }}.checkImmutable(current, "", &errs)
+				if len(errs) > 0 {
+					return errs
+				}
+				return nil
+			}
+		{{ end }}
+
 		{{ range $requestParameters }}
 			{{ $parameterType := .Type.Name.String }}
 			{{ $fieldName := fieldName . }}
@@ -672,6 +2389,7 @@ func (g *ServersGenerator) generateRequestSource(method *concepts.Method) {
 		"Method", method,
 		"Main", main,
 		"Others", others,
+		"Immutable", g.immutableBody(method) != nil,
 	)
 }
 
@@ -689,9 +2407,10 @@ func (g *ServersGenerator) generateResponseSource(method *concepts.Method) {
 	}
 
 	// Generate the code:
+	g.buffer.Import("time", "")
 	g.buffer.Import(g.packages.HelpersImport(), "")
-	g.buffer.Import("github.com/json-iterator/go", "")
-	g.buffer.Emit(`
+	g.buffer.Import("github.com/json-iterator/go", "jsoniter")
+	g.buffer.EmitTemplate("response", `
 		{{ $responseName := responseName .Method }}
 		{{ $responseParameters := responseParameters .Method }}
 		{{ $responseLen := len $responseParameters }}
@@ -701,6 +2420,9 @@ func (g *ServersGenerator) generateResponseSource(method *concepts.Method) {
 		type  {{ $responseName }} struct {
 			status int
 			err    *errors.Error
+			{{ if or .Method.IsGet .Method.IsList }}
+				lastModified time.Time
+			{{ end }}
 			{{ range $responseParameters }}
 				{{ fieldName . }} {{ fieldType . }}
 			{{ end }}
@@ -743,6 +2465,17 @@ func (g *ServersGenerator) generateResponseSource(method *concepts.Method) {
 			}
 		{{ end }}
 
+		{{ if or .Method.IsGet .Method.IsList }}
+			// LastModified sets the time when the returned data was last modified. It will
+			// be sent in the 'Last-Modified' header, and if the client sent the
+			// 'If-Modified-Since' header and the data hasn't been modified since then the
+			// response will have a 304 status code and no body.
+			func (r *{{ $responseName }}) LastModified(value time.Time) *{{ $responseName }} {
+				r.lastModified = value
+				return r
+			}
+		{{ end }}
+
 		// Status sets the status code.
 		func (r *{{ $responseName }}) Status(value int) *{{ $responseName }} {
 			r.status = value
@@ -795,6 +2528,22 @@ func (g *ServersGenerator) dispatchName(resource *concepts.Resource) string {
 	return g.names.Private(names.Cat(nomenclator.Dispatch, resource.Name()))
 }
 
+func (g *ServersGenerator) dispatcherName(resource *concepts.Resource) string {
+	root := resource.Owner().Root()
+	if resource == root {
+		return g.names.Public(names.Cat(nomenclator.New, nomenclator.Dispatcher))
+	}
+	return g.names.Public(names.Cat(nomenclator.New, resource.Name(), nomenclator.Dispatcher))
+}
+
+func (g *ServersGenerator) allowedMethods(resource *concepts.Resource) string {
+	return strings.Join(g.binding.AllowedMethods(resource), ", ")
+}
+
+func (g *ServersGenerator) serviceAllowedMethods(service *concepts.Service) string {
+	return strings.Join(g.binding.ServiceAllowedMethods(service), ", ")
+}
+
 func (g *ServersGenerator) adaptRequestName(method *concepts.Method) string {
 	name := names.Cat(
 		nomenclator.Adapt,
@@ -841,10 +2590,29 @@ func (g *ServersGenerator) responseName(method *concepts.Method) string {
 	return g.names.Public(name)
 }
 
+// immutableBody returns the body parameter of the given method if it is an update that the
+// adapter can check for changes to immutable attributes, or nil otherwise. That is only possible
+// when the body is an object of the same version.
+func (g *ServersGenerator) immutableBody(method *concepts.Method) *concepts.Parameter {
+	if !method.IsUpdate() {
+		return nil
+	}
+	for _, parameter := range g.binding.RequestBodyParameters(method) {
+		if parameter.IsBody() && parameter.Type().IsStruct() &&
+			parameter.Type().Owner() == method.Owner().Owner() {
+			return parameter
+		}
+	}
+	return nil
+}
+
+func (g *ServersGenerator) currentServerName(resource *concepts.Resource) string {
+	name := names.Cat(resource.Name(), nomenclator.Current, nomenclator.Server)
+	return g.names.Public(name)
+}
+
 func (g *ServersGenerator) fieldName(parameter *concepts.Parameter) string {
-	name := g.names.Private(parameter.Name())
-	name = g.avoidBuiltin(name, builtinFields)
-	return name
+	return g.names.Field(parameter.Name())
 }
 
 func (g *ServersGenerator) fieldType(parameter *concepts.Parameter) *TypeReference {
@@ -855,9 +2623,7 @@ func (g *ServersGenerator) fieldType(parameter *concepts.Parameter) *TypeReferen
 }
 
 func (g *ServersGenerator) getterName(parameter *concepts.Parameter) string {
-	name := g.names.Public(parameter.Name())
-	name = g.avoidBuiltin(name, builtinGetters)
-	return name
+	return g.names.Accessor(parameter.Name())
 }
 
 func (g *ServersGenerator) getterType(parameter *concepts.Parameter) *TypeReference {
@@ -865,9 +2631,7 @@ func (g *ServersGenerator) getterType(parameter *concepts.Parameter) *TypeRefere
 }
 
 func (g *ServersGenerator) setterName(parameter *concepts.Parameter) string {
-	name := g.names.Public(parameter.Name())
-	name = g.avoidBuiltin(name, builtinSetters)
-	return name
+	return g.names.Accessor(parameter.Name())
 }
 
 func (g *ServersGenerator) setterType(parameter *concepts.Parameter) *TypeReference {
@@ -902,14 +2666,6 @@ func (g *ServersGenerator) accessorType(parameter *concepts.Parameter) *TypeRefe
 	return ref
 }
 
-func (g *ServersGenerator) avoidBuiltin(name string, builtins map[string]interface{}) string {
-	_, ok := builtins[name]
-	if ok {
-		name = name + "_"
-	}
-	return name
-}
-
 func (g *ServersGenerator) readerName(typ *concepts.Type) string {
 	version := typ.Owner()
 	switch typ {
@@ -954,6 +2710,41 @@ func (g *ServersGenerator) readRequestFunc(method *concepts.Method) string {
 	return g.names.Private(name)
 }
 
+// methodKind returns the kind of the given method, as expected by the helpers.WithMethodTimeout
+// function.
+func (g *ServersGenerator) methodKind(method *concepts.Method) string {
+	switch {
+	case method.IsAction():
+		return "action"
+	default:
+		return method.Name().Snake()
+	}
+}
+
+func (g *ServersGenerator) summarizeRequestFunc(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(
+			nomenclator.Summarize,
+			method.Name(),
+			nomenclator.Request,
+		)
+	} else {
+		name = names.Cat(
+			nomenclator.Summarize,
+			resource.Name(),
+			method.Name(),
+			nomenclator.Request,
+		)
+	}
+	return g.names.Private(name)
+}
+
+func (g *ServersGenerator) marshalFunc(typ *concepts.Type) string {
+	return g.names.Public(names.Cat(nomenclator.Marshal, typ.Name()))
+}
+
 func (g *ServersGenerator) writeResponseFunc(method *concepts.Method) string {
 	resource := method.Owner()
 	var name *names.Name
@@ -973,3 +2764,25 @@ func (g *ServersGenerator) writeResponseFunc(method *concepts.Method) string {
 	}
 	return g.names.Private(name)
 }
+
+func (g *ServersGenerator) writeCBORResponseFunc(method *concepts.Method) string {
+	resource := method.Owner()
+	var name *names.Name
+	if resource.IsRoot() {
+		name = names.Cat(
+			nomenclator.Write,
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	} else {
+		name = names.Cat(
+			nomenclator.Write,
+			resource.Name(),
+			method.Name(),
+			nomenclator.Response,
+			nomenclator.CBOR,
+		)
+	}
+	return g.names.Private(name)
+}
@@ -0,0 +1,278 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the splitting of long generated files.
+
+package golang
+
+import (
+	"fmt"
+	"go/format"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Split", func() {
+	var tmp string
+	var packages *PackagesCalculator
+
+	BeforeEach(func() {
+		var err error
+
+		// Create a temporary directory for the generated code:
+		tmp, err = ioutil.TempDir("", "split-*")
+		Expect(err).ToNot(HaveOccurred())
+
+		// Create the packages calculator:
+		packages, err = NewPackagesCalculator().
+			Reporter(reporter.NewReporter()).
+			Base("example.com/generated").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		err := os.RemoveAll(tmp)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	// generateWith creates a buffer for the given file, emits three functions that use different
+	// packages and writes it.
+	generateWith := func(file string, maxLines int, stream bool) {
+		buffer, err := NewBuffer().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Packages(packages).
+			Package("mypkg").
+			File(file).
+			MaxLines(maxLines).
+			Stream(stream).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer.Import("fmt", "")
+		buffer.Import("strings", "")
+		buffer.Import("time", "")
+		buffer.Emit(`
+			// first uses the fmt package.
+			func first() string {
+				return fmt.Sprintf("%d", 1)
+			}
+
+			// second uses the strings package.
+			func second() string {
+				return strings.ToUpper("second")
+			}
+
+			// third uses the time package.
+			func third() time.Time {
+				return time.Now()
+			}
+		`)
+		err = buffer.Write()
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	// generate is like generateWith, but keeping the code in memory.
+	generate := func(file string, maxLines int) {
+		generateWith(file, maxLines, false)
+	}
+
+	// read returns the content of the given generated file.
+	read := func(file string) string {
+		data, err := ioutil.ReadFile(filepath.Join(tmp, "mypkg", file))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("Doesn't split by default", func() {
+		generate("example", 0)
+		code := read("example.go")
+		Expect(code).To(ContainSubstring("func first()"))
+		Expect(code).To(ContainSubstring("func second()"))
+		Expect(code).To(ContainSubstring("func third()"))
+		_, err := os.Stat(filepath.Join(tmp, "mypkg", "example_2.go"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+
+	It("Splits between declarations keeping the comments", func() {
+		generate("example", 4)
+		first := read("example.go")
+		Expect(first).To(ContainSubstring("// first uses the fmt package."))
+		Expect(first).To(ContainSubstring("func first()"))
+		Expect(first).ToNot(ContainSubstring("func second()"))
+		second := read("example_2.go")
+		Expect(second).To(ContainSubstring("// second uses the strings package."))
+		Expect(second).To(ContainSubstring("func second()"))
+		third := read("example_3.go")
+		Expect(third).To(ContainSubstring("// third uses the time package."))
+		Expect(third).To(ContainSubstring("func third()"))
+	})
+
+	It("Calculates the imports of each file", func() {
+		generate("example", 4)
+		first := read("example.go")
+		Expect(first).To(ContainSubstring(`"fmt"`))
+		Expect(first).ToNot(ContainSubstring(`"strings"`))
+		second := read("example_2.go")
+		Expect(second).To(ContainSubstring(`"strings"`))
+		Expect(second).ToNot(ContainSubstring(`"fmt"`))
+		third := read("example_3.go")
+		Expect(third).To(ContainSubstring(`"time"`))
+	})
+
+	It("Keeps the test suffix", func() {
+		generate("example_test", 4)
+		Expect(read("example_test.go")).To(ContainSubstring("func first()"))
+		Expect(read("example_2_test.go")).To(ContainSubstring("func second()"))
+		Expect(read("example_3_test.go")).To(ContainSubstring("func third()"))
+	})
+
+	It("Splits in streaming mode", func() {
+		generateWith("example", 4, true)
+		first := read("example.go")
+		Expect(first).To(ContainSubstring("func first()"))
+		Expect(first).To(ContainSubstring(`"fmt"`))
+		Expect(first).ToNot(ContainSubstring(`"strings"`))
+		second := read("example_2.go")
+		Expect(second).To(ContainSubstring("func second()"))
+		Expect(second).To(ContainSubstring(`"strings"`))
+		Expect(second).ToNot(ContainSubstring(`"fmt"`))
+		third := read("example_3.go")
+		Expect(third).To(ContainSubstring("func third()"))
+		Expect(third).To(ContainSubstring(`"time"`))
+	})
+
+	It("Generates the same code in streaming mode than in memory", func() {
+		generateWith("memory", 0, false)
+		generateWith("stream", 0, true)
+		Expect(read("stream.go")).To(Equal(read("memory.go")))
+	})
+
+	It("Streams long files in chunks", func() {
+		buffer, err := NewBuffer().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Packages(packages).
+			Package("mypkg").
+			File("example").
+			Stream(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer.Import("fmt", "")
+		for i := 0; i < 1000; i++ {
+			buffer.Emit(`
+				// f{{ .index }} uses the fmt package.
+				func f{{ .index }}() string {
+					return fmt.Sprintf("%d", {{ .index }})
+				}
+				`,
+				"index", i,
+			)
+		}
+		err = buffer.Write()
+		Expect(err).ToNot(HaveOccurred())
+		code := read("example.go")
+		Expect(code).To(ContainSubstring(`"fmt"`))
+		Expect(code).To(ContainSubstring("func f0()"))
+		Expect(code).To(ContainSubstring("func f999()"))
+		formatted, err := format.Source([]byte(code))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(formatted)).To(Equal(code))
+	})
+
+	It("Delays parsing declarations generated by many calls", func() {
+		buffer, err := NewBuffer().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Packages(packages).
+			Package("mypkg").
+			File("example").
+			Stream(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Generate a function with one statement per call, so that the code in memory
+		// can't be parsed till the function is complete:
+		buffer.Emit(`
+			// big is generated by many calls.
+			func big() (result int) {
+			`,
+		)
+		for i := 0; i < 3*streamChunkLines; i++ {
+			buffer.Emit(`result += {{ .index }}`, "index", i)
+		}
+
+		// Check that the attempts to parse the incomplete code have been delayed:
+		Expect(buffer.retry).To(BeNumerically(">", streamChunkLines))
+		Expect(buffer.retry).To(BeNumerically(">", buffer.pending))
+
+		// Complete the function and check that the result is correct:
+		buffer.Emit(`
+				return
+			}
+			`,
+		)
+		err = buffer.Write()
+		Expect(err).ToNot(HaveOccurred())
+		code := read("example.go")
+		Expect(code).To(ContainSubstring("func big() (result int) {"))
+		Expect(code).To(ContainSubstring(fmt.Sprintf("result += %d\n", 3*streamChunkLines-1)))
+		formatted, err := format.Source([]byte(code))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(formatted)).To(Equal(code))
+	})
+
+	It("Removes the temporary files if the buffer isn't written", func() {
+		spool := filepath.Join(tmp, "spool")
+		err := os.Mkdir(spool, 0755)
+		Expect(err).ToNot(HaveOccurred())
+		old := os.Getenv("TMPDIR")
+		os.Setenv("TMPDIR", spool)
+		defer os.Setenv("TMPDIR", old)
+		buffer, err := NewBuffer().
+			Reporter(reporter.NewReporter()).
+			Output(tmp).
+			Packages(packages).
+			Package("mypkg").
+			File("example").
+			Stream(true).
+			MaxLines(4).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 10; i++ {
+			buffer.Emit(`
+				func f{{ .index }}() {
+				}
+				`,
+				"index", i,
+			)
+		}
+		files, err := ioutil.ReadDir(spool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).ToNot(BeEmpty())
+		err = buffer.Close()
+		Expect(err).ToNot(HaveOccurred())
+		files, err = ioutil.ReadDir(spool)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(files).To(BeEmpty())
+	})
+})
@@ -0,0 +1,161 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the object that loads the templates that replace the built-in templates of
+// the generators.
+
+package golang
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// TemplatesBuilder is used to create the set of template overrides. Don't create instances
+// directly, use the NewTemplates function instead.
+type TemplatesBuilder struct {
+	reporter *reporter.Reporter
+	dir      string
+}
+
+// Templates contains the texts of the templates that should be used instead of the built-in
+// templates of the generators. Don't create instances directly, use the builder instead.
+type Templates struct {
+	reporter *reporter.Reporter
+	texts    map[string]string
+	used     map[string]bool
+	lock     *sync.Mutex
+}
+
+// NewTemplates creates a builder for template overrides.
+func NewTemplates() *TemplatesBuilder {
+	return new(TemplatesBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the loaded templates.
+func (b *TemplatesBuilder) Reporter(value *reporter.Reporter) *TemplatesBuilder {
+	b.reporter = value
+	return b
+}
+
+// Dir sets the directory that contains the templates. It should contain one sub-directory for
+// each generator, and inside it one file for each template, named like the template with the
+// '.tmpl' extension. For example, to replace the template that generates the struct types the
+// directory should contain the 'types/struct_type.tmpl' file. The names of the templates of
+// each generator are the names of the functions that use them, without the 'generate' prefix
+// and the 'Source' suffix, and in snake case.
+func (b *TemplatesBuilder) Dir(value string) *TemplatesBuilder {
+	b.dir = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, loads the
+// templates.
+func (b *TemplatesBuilder) Build() (templates *Templates, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+	if b.dir == "" {
+		err = fmt.Errorf("directory is mandatory")
+		return
+	}
+
+	// Load the templates:
+	texts := map[string]string{}
+	err = filepath.Walk(b.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".tmpl" {
+			return nil
+		}
+		relative, err := filepath.Rel(b.dir, path)
+		if err != nil {
+			return err
+		}
+		key := strings.TrimSuffix(filepath.ToSlash(relative), ".tmpl")
+		if strings.Count(key, "/") != 1 {
+			return fmt.Errorf(
+				"template file '%s' should be inside the directory of a generator",
+				path,
+			)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		texts[key] = string(data)
+		b.reporter.Infof("Loaded template '%s' from file '%s'", key, path)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("can't load templates from directory '%s': %v", b.dir, err)
+		return
+	}
+
+	// Create the templates:
+	templates = &Templates{
+		reporter: b.reporter,
+		texts:    texts,
+		used:     map[string]bool{},
+		lock:     &sync.Mutex{},
+	}
+
+	return
+}
+
+// Lookup returns the text of the template that replaces the given template of the given
+// generator, and a flag indicating if there is such replacement.
+func (t *Templates) Lookup(generator, name string) (text string, ok bool) {
+	if t == nil {
+		return
+	}
+	key := generator + "/" + name
+	text, ok = t.texts[key]
+	if ok {
+		t.lock.Lock()
+		t.used[key] = true
+		t.lock.Unlock()
+	}
+	return
+}
+
+// Unused returns the sorted keys, in 'generator/name' format, of the templates that haven't
+// been used by any generator. This is intended to detect misspelled file names.
+func (t *Templates) Unused() []string {
+	if t == nil {
+		return nil
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	var keys []string
+	for key := range t.texts {
+		if !t.used[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
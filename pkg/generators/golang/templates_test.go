@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the templates that replace the built-in templates.
+
+package golang
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Templates", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "templates-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmp)
+	})
+
+	// write creates a template file inside the temporary directory.
+	write := func(name, text string) {
+		file := filepath.Join(tmp, "templates", filepath.FromSlash(name))
+		err := os.MkdirAll(filepath.Dir(file), 0755)
+		Expect(err).ToNot(HaveOccurred())
+		err = ioutil.WriteFile(file, []byte(text), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	It("Loads templates keyed by generator and name", func() {
+		write("types/struct_type.tmpl", "my text")
+		templates, err := NewTemplates().
+			Reporter(reporter.NewReporter()).
+			Dir(filepath.Join(tmp, "templates")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		text, ok := templates.Lookup("types", "struct_type")
+		Expect(ok).To(BeTrue())
+		Expect(text).To(Equal("my text"))
+		_, ok = templates.Lookup("json", "struct_type")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Rejects templates outside of the directory of a generator", func() {
+		write("struct_type.tmpl", "my text")
+		_, err := NewTemplates().
+			Reporter(reporter.NewReporter()).
+			Dir(filepath.Join(tmp, "templates")).
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Reports templates that haven't been used", func() {
+		write("types/struct_type.tmpl", "my text")
+		write("types/strcut_type.tmpl", "my text")
+		templates, err := NewTemplates().
+			Reporter(reporter.NewReporter()).
+			Dir(filepath.Join(tmp, "templates")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		templates.Lookup("types", "struct_type")
+		Expect(templates.Unused()).To(ConsistOf("types/strcut_type"))
+	})
+
+	It("Is used by the buffer instead of the built-in template", func() {
+		write("mygen/value.tmpl", "var value = {{ .value }} + 1")
+		reporter := reporter.NewReporter()
+		templates, err := NewTemplates().
+			Reporter(reporter).
+			Dir(filepath.Join(tmp, "templates")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		packages, err := NewPackagesCalculator().
+			Reporter(reporter).
+			Base("example.com/generated").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer, err := NewBuffer().
+			Reporter(reporter).
+			Output(filepath.Join(tmp, "output")).
+			Packages(packages).
+			Package("mypkg").
+			File("example").
+			Templates(templates).
+			Generator("mygen").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer.EmitTemplate("value", `
+			var value = {{ .value }}
+			`,
+			"value", 42,
+		)
+		buffer.EmitTemplate("other", `
+			var other = {{ .value }}
+			`,
+			"value", 43,
+		)
+		err = buffer.Write()
+		Expect(err).ToNot(HaveOccurred())
+		data, err := ioutil.ReadFile(filepath.Join(tmp, "output", "mypkg", "example.go"))
+		Expect(err).ToNot(HaveOccurred())
+		code := string(data)
+		Expect(code).To(ContainSubstring("var value = 42 + 1\n"))
+		Expect(code).To(ContainSubstring("var other = 43\n"))
+	})
+})
@@ -179,6 +179,10 @@ func (c *TypesCalculator) ValueReference(typ *concepts.Type) *TypeReference {
 		ref.selector = "time"
 		ref.name = "Time"
 		ref.text = "time.Time"
+	case typ == version.InterfaceType():
+		ref = &TypeReference{}
+		ref.name = "interface{}"
+		ref.text = "interface{}"
 	case typ.IsEnum():
 		ref = &TypeReference{}
 		ref.imprt, ref.selector = c.Package(typ)
@@ -362,6 +366,8 @@ func (c *TypesCalculator) ZeroValue(typ *concepts.Type) string {
 		return `0.0`
 	case typ == version.DateType():
 		return `time.Time{}`
+	case typ == version.InterfaceType():
+		return `nil`
 	case typ == version.StringType():
 		return `""`
 	default:
@@ -390,6 +396,8 @@ func (c *TypesCalculator) Package(typ *concepts.Type) (imprt, selector string) {
 		imprt = "time"
 		selector = "time"
 		return
+	case typ == version.InterfaceType():
+		return
 	case typ.IsEnum() || typ.IsStruct() || typ.IsList():
 		imprt = c.packages.VersionImport(version)
 		selector = path.Base(imprt)
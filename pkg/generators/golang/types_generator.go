@@ -20,6 +20,7 @@ import (
 	"fmt"
 
 	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/info"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
@@ -28,25 +29,41 @@ import (
 // TypesGeneratorBuilder is an object used to configure and build the types generator. Don't create
 // instances directly, use the NewTypesGenerator function instead.
 type TypesGeneratorBuilder struct {
-	reporter *reporter.Reporter
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
+	reporter      *reporter.Reporter
+	model         *concepts.Model
+	output        string
+	packages      *PackagesCalculator
+	names         *NamesCalculator
+	types         *TypesCalculator
+	rawAttributes bool
+	listChecks    bool
+	generics      bool
+	modelRevision string
+	header        *Header
+	stream        bool
+	maxLines      int
+	templates     *Templates
 }
 
 // TypesGenerator Go types for the model types. Don't create instances directly, use the builder
 // instead.
 type TypesGenerator struct {
-	reporter *reporter.Reporter
-	errors   int
-	model    *concepts.Model
-	output   string
-	packages *PackagesCalculator
-	names    *NamesCalculator
-	types    *TypesCalculator
-	buffer   *Buffer
+	reporter      *reporter.Reporter
+	errors        int
+	model         *concepts.Model
+	output        string
+	packages      *PackagesCalculator
+	names         *NamesCalculator
+	types         *TypesCalculator
+	rawAttributes bool
+	listChecks    bool
+	generics      bool
+	modelRevision string
+	header        *Header
+	stream        bool
+	maxLines      int
+	templates     *Templates
+	buffer        *Buffer
 }
 
 // NewTypesGenerator creates a new builder for types generators.
@@ -91,6 +108,69 @@ func (b *TypesGeneratorBuilder) Types(value *TypesCalculator) *TypesGeneratorBui
 	return b
 }
 
+// RawAttributes sets the flag that indicates if the generated types should keep the JSON text of
+// the attributes read when unmarshalling objects, and provide access to it with the
+// RawAttribute method. The default is to not keep it.
+func (b *TypesGeneratorBuilder) RawAttributes(value bool) *TypesGeneratorBuilder {
+	b.rawAttributes = value
+	return b
+}
+
+// ListChecks sets the flag that indicates if a test file that checks that all the generated list
+// types implement the generic list interface of the runtime package should be generated for each
+// version. That test file imports the runtime package, so it is only useful when that package is
+// available. The default is to not generate it.
+func (b *TypesGeneratorBuilder) ListChecks(value bool) *TypesGeneratorBuilder {
+	b.listChecks = value
+	return b
+}
+
+// Generics sets the flag that indicates if the generated types should use the generic functions of
+// the helpers package instead of repeating the same logic in the accessors and in the methods of
+// the list types of each type. This reduces the size of the generated code, but it needs Go 1.18
+// or newer. The default is to not use them.
+func (b *TypesGeneratorBuilder) Generics(value bool) *TypesGeneratorBuilder {
+	b.generics = value
+	return b
+}
+
+// ModelRevision sets the identifier of the revision of the model, for example a git tag or
+// commit hash. It is added, together with the version of the metamodel, to the GeneratedFrom
+// constant of the generated version packages. The default is to not add it.
+func (b *TypesGeneratorBuilder) ModelRevision(value string) *TypesGeneratorBuilder {
+	b.modelRevision = value
+	return b
+}
+
+// Header sets the header that will be added to the generated files. The default is to add the
+// Red Hat copyright and the Apache license.
+func (b *TypesGeneratorBuilder) Header(value *Header) *TypesGeneratorBuilder {
+	b.header = value
+	return b
+}
+
+// Stream sets the flag that indicates if the generated code should be streamed to disk instead
+// of being kept in memory while it is generated. This reduces the memory used when generating
+// large models. The default is to keep the code in memory.
+func (b *TypesGeneratorBuilder) Stream(value bool) *TypesGeneratorBuilder {
+	b.stream = value
+	return b
+}
+
+// MaxLines sets the approximate maximum number of lines of the generated files. Longer files will
+// be split into multiple files. The default is zero, which means that files are never split.
+func (b *TypesGeneratorBuilder) MaxLines(value int) *TypesGeneratorBuilder {
+	b.maxLines = value
+	return b
+}
+
+// Templates sets the object that contains the templates that will be used instead of the built-in
+// templates of the generator. The default is to use the built-in templates.
+func (b *TypesGeneratorBuilder) Templates(value *Templates) *TypesGeneratorBuilder {
+	b.templates = value
+	return b
+}
+
 // Build checks the configuration stored in the builder and, if it is correct, creates a new
 // types generator using it.
 func (b *TypesGeneratorBuilder) Build() (generator *TypesGenerator, err error) {
@@ -122,12 +202,20 @@ func (b *TypesGeneratorBuilder) Build() (generator *TypesGenerator, err error) {
 
 	// Create the generator:
 	generator = &TypesGenerator{
-		reporter: b.reporter,
-		model:    b.model,
-		output:   b.output,
-		packages: b.packages,
-		names:    b.names,
-		types:    b.types,
+		reporter:      b.reporter,
+		model:         b.model,
+		output:        b.output,
+		packages:      b.packages,
+		names:         b.names,
+		types:         b.types,
+		rawAttributes: b.rawAttributes,
+		listChecks:    b.listChecks,
+		generics:      b.generics,
+		modelRevision: b.modelRevision,
+		header:        b.header,
+		stream:        b.stream,
+		maxLines:      b.maxLines,
+		templates:     b.templates,
 	}
 
 	return
@@ -140,6 +228,7 @@ func (g *TypesGenerator) Run() error {
 	// Generate the go types:
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating types for version '%s'", version)
 			// Generate the version metadata type:
 			err := g.generateVersionMetadataTypeFile(version)
 			if err != nil {
@@ -156,6 +245,14 @@ func (g *TypesGenerator) Run() error {
 					return err
 				}
 			}
+
+			// Generate the checks for the generic list interface:
+			if g.listChecks {
+				err = g.generateVersionListChecksFile(version)
+				if err != nil {
+					return err
+				}
+			}
 		}
 	}
 
@@ -184,12 +281,18 @@ func (g *TypesGenerator) generateVersionMetadataTypeFile(version *concepts.Versi
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("types").
 		Package(pkgName).
 		File(fileName).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the source:
 	g.generateVersionMetadataTypeSource(version)
@@ -198,11 +301,74 @@ func (g *TypesGenerator) generateVersionMetadataTypeFile(version *concepts.Versi
 	return g.buffer.Write()
 }
 
+func (g *TypesGenerator) generateVersionListChecksFile(version *concepts.Version) error {
+	var err error
+
+	// Calculate the package and file name:
+	pkgName := g.packages.VersionPackage(version)
+	fileName := g.listChecksFile()
+
+	// Create the buffer for the generated code. Note that the generic list interface needs
+	// Go 1.18 or newer, so this file is excluded when building with older versions.
+	g.buffer, err = NewBuffer().
+		Reporter(g.reporter).
+		Output(g.output).
+		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("types").
+		Package(pkgName).
+		File(fileName).
+		Constraint("go1.18").
+		Function("listName", g.listName).
+		Function("objectName", g.objectName).
+		Build()
+	if err != nil {
+		return err
+	}
+	defer g.buffer.Close()
+
+	// Generate the source:
+	g.generateVersionListChecksSource(version)
+
+	// Write the generated code:
+	return g.buffer.Write()
+}
+
+func (g *TypesGenerator) generateVersionListChecksSource(version *concepts.Version) {
+	g.buffer.Import("github.com/openshift-online/ocm-api-metamodel/pkg/runtime", "")
+	g.buffer.EmitTemplate("version_list_checks", `
+		// Make sure that all the list types implement the generic list interface, so that
+		// they can be used with the generic functions of the runtime package, like
+		// runtime.Map, runtime.Filter and runtime.Reduce.
+		var (
+			{{ range .Version.Types }}
+				{{ if .IsStruct }}
+					_ runtime.List[*{{ objectName . }}] = (*{{ listName . }})(nil)
+				{{ end }}
+			{{ end }}
+		)
+		`,
+		"Version", version,
+	)
+}
+
 func (g *TypesGenerator) generateVersionMetadataTypeSource(version *concepts.Version) {
-	g.buffer.Emit(`
+	g.buffer.Import("time", "")
+	g.buffer.EmitTemplate("version_metadata_type", `
+		// GeneratedFrom contains the revision of the model and the version of the metamodel
+		// that were used to generate this package.
+		const GeneratedFrom = {{ printf "%q" .GeneratedFrom }}
+
 		// Metadata contains the version metadata.
 		type Metadata struct {
-			serverVersion *string
+			serverVersion  *string
+			buildTimestamp *time.Time
+			gitCommit      *string
+			generatedFrom  *string
+			features       []string
 		}
 
 		// ServerVersion returns the version of the server.
@@ -222,10 +388,104 @@ func (g *TypesGenerator) generateVersionMetadataTypeSource(version *concepts.Ver
 			}
 			return
 		}
+
+		// BuildTimestamp returns the time when the server was built.
+		func (m *Metadata) BuildTimestamp() time.Time {
+			if m != nil && m.buildTimestamp != nil {
+				return *m.buildTimestamp
+			}
+			return time.Time{}
+		}
+
+		// GetBuildTimestamp returns the value of the build timestamp and a flag indicating if
+		// the attribute has a value.
+		func (m *Metadata) GetBuildTimestamp() (value time.Time, ok bool) {
+			ok = m != nil && m.buildTimestamp != nil
+			if ok {
+				value = *m.buildTimestamp
+			}
+			return
+		}
+
+		// GitCommit returns the identifier of the git commit used to build the server.
+		func (m *Metadata) GitCommit() string {
+			if m != nil && m.gitCommit != nil {
+				return *m.gitCommit
+			}
+			return ""
+		}
+
+		// GetGitCommit returns the value of the git commit and a flag indicating if the
+		// attribute has a value.
+		func (m *Metadata) GetGitCommit() (value string, ok bool) {
+			ok = m != nil && m.gitCommit != nil
+			if ok {
+				value = *m.gitCommit
+			}
+			return
+		}
+
+		// GeneratedFrom returns the revision of the model and the version of the metamodel
+		// that were used to generate the code of the server.
+		func (m *Metadata) GeneratedFrom() string {
+			if m != nil && m.generatedFrom != nil {
+				return *m.generatedFrom
+			}
+			return ""
+		}
+
+		// GetGeneratedFrom returns the value of the generated from attribute and a flag
+		// indicating if the attribute has a value.
+		func (m *Metadata) GetGeneratedFrom() (value string, ok bool) {
+			ok = m != nil && m.generatedFrom != nil
+			if ok {
+				value = *m.generatedFrom
+			}
+			return
+		}
+
+		// Features returns the names of the features supported by the server.
+		func (m *Metadata) Features() []string {
+			if m != nil {
+				return m.features
+			}
+			return nil
+		}
+
+		// GetFeatures returns the names of the features supported by the server and a flag
+		// indicating if the attribute has a value.
+		func (m *Metadata) GetFeatures() (value []string, ok bool) {
+			ok = m != nil && m.features != nil
+			if ok {
+				value = m.features
+			}
+			return
+		}
+
+		// HasFeature returns true if the server supports the feature with the given name.
+		func (m *Metadata) HasFeature(name string) bool {
+			if m != nil {
+				for _, feature := range m.features {
+					if feature == name {
+						return true
+					}
+				}
+			}
+			return false
+		}
 		`,
+		"GeneratedFrom", g.generatedFrom(),
 	)
 }
 
+// generatedFrom calculates the value of the GeneratedFrom constant of the version packages.
+func (g *TypesGenerator) generatedFrom() string {
+	if g.modelRevision == "" {
+		return fmt.Sprintf("metamodel %s", info.Version)
+	}
+	return fmt.Sprintf("model %s, metamodel %s", g.modelRevision, info.Version)
+}
+
 func (g *TypesGenerator) generateTypeFile(typ *concepts.Type) error {
 	var err error
 
@@ -238,22 +498,34 @@ func (g *TypesGenerator) generateTypeFile(typ *concepts.Type) error {
 		Reporter(g.reporter).
 		Output(g.output).
 		Packages(g.packages).
+		Header(g.header).
+		Stream(g.stream).
+		MaxLines(g.maxLines).
+		Templates(g.templates).
+		Generator("types").
 		Package(pkgName).
 		File(fileName).
 		Function("enumName", g.types.EnumName).
 		Function("fieldName", g.fieldName).
 		Function("fieldType", g.fieldType).
+		Function("decodeFunc", g.decodeFunc).
+		Function("equalFunc", g.equalFunc).
 		Function("getterName", g.getterName).
 		Function("getterType", g.getterType).
 		Function("listName", g.listName).
 		Function("objectName", g.objectName).
+		Function("patternName", g.patternName).
+		Function("plural", g.names.Plural).
+		Function("sameVersion", g.sameVersion).
+		Function("validatorName", g.validatorName).
+		Function("validatorType", g.validatorType).
 		Function("valueName", g.valueName).
 		Function("valueTag", g.valueTag).
-		Function("zeroValue", g.types.ZeroValue).
 		Build()
 	if err != nil {
 		return err
 	}
+	defer g.buffer.Close()
 
 	// Generate the source:
 	g.generateTypeSource(typ)
@@ -268,11 +540,12 @@ func (g *TypesGenerator) generateTypeSource(typ *concepts.Type) {
 		g.generateEnumTypeSource(typ)
 	case typ.IsStruct():
 		g.generateStructTypeSource(typ)
+		g.generateStructValidationSource(typ)
 	}
 }
 
 func (g *TypesGenerator) generateEnumTypeSource(typ *concepts.Type) {
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("enum_type", `
 		{{ $enumName := enumName .Type }}
 
 		// {{ $enumName }} represents the values of the '{{ .Type.Name }}' enumerated type.
@@ -289,9 +562,19 @@ func (g *TypesGenerator) generateEnumTypeSource(typ *concepts.Type) {
 	)
 }
 
+// generateStructTypeSource generates the struct and list types. To reduce the size of the
+// generated code the simple accessors delegate to the ones that return the value and the flag,
+// and the iteration methods of lists delegate to each other. When generics are enabled the
+// remaining logic of the accessors and of the lists is delegated to the generic functions of the
+// helpers package, so that it is compiled only once.
 func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
+	if g.generics {
+		g.buffer.Import(g.packages.HelpersImport(), "")
+	}
+	g.buffer.Import("encoding/json", "")
+	g.buffer.Import("sync", "")
 	g.buffer.Import("time", "")
-	g.buffer.Emit(`
+	g.buffer.EmitTemplate("struct_type", `
 		{{ $objectName := objectName .Type }}
 		{{ $listName := listName .Type }}
 
@@ -320,6 +603,13 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 			{{ end }}
 			{{ range .Type.Attributes }}
 				{{ fieldName . }} {{ fieldType . }}
+				{{ if .Lazy }}
+					{{ fieldName . }}Raw []byte
+					{{ fieldName . }}Once *sync.Once
+				{{ end }}
+			{{ end }}
+			{{ if .RawAttributes }}
+				raw map[string]json.RawMessage
 			{{ end }}
 		}
 
@@ -337,19 +627,23 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 
 			// ID returns the identifier of the object.
 			func (o *{{ $objectName }}) ID() string {
-				if o != nil && o.id != nil {
-					return *o.id
-				}
-				return ""
+				value, _ := o.GetID()
+				return value
 			}
 
 			// GetID returns the identifier of the object and a flag indicating if the
 			// identifier has a value.
 			func (o *{{ $objectName }}) GetID() (value string, ok bool) {
-				ok = o != nil && o.id != nil
-				if ok {
-					value = *o.id
-				}
+				{{ if $.Generics }}
+					if o != nil {
+						value, ok = helpers.Value(o.id)
+					}
+				{{ else }}
+					ok = o != nil && o.id != nil
+					if ok {
+						value = *o.id
+					}
+				{{ end }}
 				return
 			}
 
@@ -360,25 +654,47 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 
 			// HREF returns the link to the object.
 			func (o *{{ $objectName }}) HREF() string {
-				if o != nil && o.href != nil {
-					return *o.href
-				}
-				return ""
+				value, _ := o.GetHREF()
+				return value
 			}
 
 			// GetHREF returns the link of the object and a flag indicating if the
 			// link has a value.
 			func (o *{{ $objectName }}) GetHREF() (value string, ok bool) {
-				ok = o != nil && o.href != nil
-				if ok {
-					value = *o.href
+				{{ if $.Generics }}
+					if o != nil {
+						value, ok = helpers.Value(o.href)
+					}
+				{{ else }}
+					ok = o != nil && o.href != nil
+					if ok {
+						value = *o.href
+					}
+				{{ end }}
+				return
+			}
+		{{ end }}
+
+		{{ if .RawAttributes }}
+			// RawAttribute returns the JSON text of the attribute with the given name, as
+			// it was received when the object was unmarshalled, and a flag indicating if
+			// the attribute was present. The returned value is shared with the object, so
+			// it must not be modified.
+			func (o *{{ $objectName }}) RawAttribute(name string) (value json.RawMessage, ok bool) {
+				if o != nil {
+					value, ok = o.raw[name]
 				}
 				return
 			}
 		{{ end }}
 
-		// Empty returns true if the object is empty, i.e. no attribute has a value.
+		// Empty returns true if the object is empty, i.e. no attribute has a value. Lists
+		// and maps without elements are considered empty even if they have been explicitly
+		// set, use the Unset method to check that.
 		func (o *{{ $objectName }}) Empty() bool {
+			{{ if .Type.HasLazyAttributes }}
+				o.decode()
+			{{ end }}
 			return o == nil || (
 				{{ if .Type.IsClass }}
 					o.id == nil &&
@@ -395,11 +711,29 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 						{{ end }}
 					{{ else if .Type.IsMap }}
 						len(o.{{ $fieldName }}) == 0 &&
+					{{ else if .Type.IsStruct }}
+						o.{{ $fieldName }} == nil &&
 					{{ end }}
 				{{ end }}
 				true);
 		}
 
+		// Unset returns true if no attribute of the object has been set, not even to an
+		// empty list or map.
+		func (o *{{ $objectName }}) Unset() bool {
+			{{ if .Type.HasLazyAttributes }}
+				o.decode()
+			{{ end }}
+			return o == nil || (
+				{{ if .Type.IsClass }}
+					o.id == nil &&
+				{{ end }}
+				{{ range .Type.Attributes }}
+					o.{{ fieldName . }} == nil &&
+				{{ end }}
+				true);
+		}
+
 		{{ range .Type.Attributes }}
 			{{ $attributeType := .Type.Name.String }}
 			{{ $fieldName := fieldName . }}
@@ -410,33 +744,41 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 			// the zero value of the type if the attribute doesn't have a value.
 			//
 			{{ lineComment .Doc }}
+			{{ with .Since }}
+				//
+				// Available since version {{ . }} of the API.
+			{{ end }}
 			func (o *{{ $objectName }}) {{ $getterName }}() {{ $getterType }} {
-				{{ if or .Type.IsStruct .Type.IsList .Type.IsMap }}
-					if o == nil {
-						return nil
-					}
-					return o.{{ $fieldName }}
-				{{ else }}
-					if o != nil && o.{{ $fieldName }} != nil {
-						return *o.{{ $fieldName }}
-					}
-					return {{ zeroValue .Type }}
-				{{ end }}
+				value, _ := o.Get{{ $getterName }}()
+				return value
 			}
 
 			// Get{{ $getterName }} returns the value of the '{{ .Name }}' attribute and
 			// a flag indicating if the attribute has a value.
 			//
 			{{ lineComment .Doc }}
+			{{ with .Since }}
+				//
+				// Available since version {{ . }} of the API.
+			{{ end }}
 			func (o *{{ $objectName }}) Get{{ $getterName }}() (value {{ $getterType }}, ok bool) {
-				ok = o != nil && o.{{ $fieldName }} != nil
-				if ok {
-					{{ if or .Type.IsStruct .Type.IsList .Type.IsMap }}
-						value = o.{{ $fieldName }}
-					{{ else }}
-						value = *o.{{ $fieldName }}
-					{{ end }}
-				}
+				{{ if .Lazy }}
+					o.{{ decodeFunc . }}()
+				{{ end }}
+				{{ if and $.Generics .Type.IsScalar }}
+					if o != nil {
+						value, ok = helpers.Value(o.{{ $fieldName }})
+					}
+				{{ else }}
+					ok = o != nil && o.{{ $fieldName }} != nil
+					if ok {
+						{{ if or .Type.IsStruct .Type.IsList .Type.IsMap }}
+							value = o.{{ $fieldName }}
+						{{ else }}
+							value = *o.{{ $fieldName }}
+						{{ end }}
+					}
+				{{ end }}
 				return
 			}
 		{{ end }}
@@ -453,7 +795,8 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 		// type '{{ .Type.Name }}'.
 		const {{ $listName }}NilKind = "{{ $listName }}Nil"
 
-		// {{ $listName }} is a list of values of the '{{ .Type.Name }}' type.
+		// {{ $listName }} is a list of {{ plural .Type.Name }}, values of the '{{ .Type.Name }}'
+		// type.
 		type {{ $listName }} struct {
 			href  *string
 			link  bool
@@ -479,19 +822,23 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 
 			// HREF returns the link to the list.
 			func (l *{{ $listName }}) HREF() string {
-				if l != nil && l.href != nil {
-					return *l.href
-				}
-				return ""
+				value, _ := l.GetHREF()
+				return value
 			}
 
 			// GetHREF returns the link of the list and a flag indicating if the
 			// link has a value.
 			func (l *{{ $listName }}) GetHREF() (value string, ok bool) {
-				ok = l != nil && l.href != nil
-				if ok {
-					value = *l.href
-				}
+				{{ if $.Generics }}
+					if l != nil {
+						value, ok = helpers.Value(l.href)
+					}
+				{{ else }}
+					ok = l != nil && l.href != nil
+					if ok {
+						value = *l.href
+					}
+				{{ end }}
 				return
 			}
 		{{ end }}
@@ -506,16 +853,23 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 
 		// Empty returns true if the list is empty.
 		func (l *{{ $listName }}) Empty() bool {
-			return l == nil || len(l.items) == 0
+			return l.Len() == 0
 		}
 
 		// Get returns the item of the list with the given index. If there is no item with
 		// that index it returns nil.
 		func (l *{{ $listName }}) Get(i int) *{{ $objectName }} {
-			if l == nil || i < 0 || i >= len(l.items) {
-				return nil
-			}
-			return l.items[i]
+			{{ if .Generics }}
+				if l == nil {
+					return nil
+				}
+				return helpers.ListGet(l.items, i)
+			{{ else }}
+				if l == nil || i < 0 || i >= len(l.items) {
+					return nil
+				}
+				return l.items[i]
+			{{ end }}
 		}
 
 		// Slice returns an slice containing the items of the list. The returned slice is a
@@ -525,28 +879,30 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 		// If you don't need to modify the returned slice consider using the Each or Range
 		// functions, as they don't need to allocate a new slice.
 		func (l *{{ $listName }}) Slice() []*{{ $objectName }} {
-			var slice []*{{ $objectName }}
-			if l == nil {
-				slice = make([]*{{ $objectName}}, 0)
-			} else {
-				slice = make([]*{{ $objectName}}, len(l.items))
-				copy(slice, l.items)
-			}
-			return slice
+			{{ if .Generics }}
+				if l == nil {
+					return helpers.ListSlice[*{{ $objectName }}](nil)
+				}
+				return helpers.ListSlice(l.items)
+			{{ else }}
+				var slice []*{{ $objectName }}
+				if l == nil {
+					slice = make([]*{{ $objectName}}, 0)
+				} else {
+					slice = make([]*{{ $objectName}}, len(l.items))
+					copy(slice, l.items)
+				}
+				return slice
+			{{ end }}
 		}
 
 		// Each runs the given function for each item of the list, in order. If the function
 		// returns false the iteration stops, otherwise it continues till all the elements
 		// of the list have been processed.
 		func (l *{{ $listName }}) Each(f func(item *{{ $objectName }}) bool) {
-			if l == nil {
-				return
-			}
-			for _, item := range l.items {
-				if !f(item) {
-					break
-				}
-			}
+			l.Range(func(_ int, item *{{ $objectName }}) bool {
+				return f(item)
+			})
 		}
 
 		// Range runs the given function for each index and item of the list, in order. If
@@ -556,11 +912,332 @@ func (g *TypesGenerator) generateStructTypeSource(typ *concepts.Type) {
 			if l == nil {
 				return
 			}
-			for index, item := range l.items {
-				if !f(index, item) {
-					break
+			{{ if .Generics }}
+				helpers.ListRange(l.items, f)
+			{{ else }}
+				for index, item := range l.items {
+					if !f(index, item) {
+						break
+					}
 				}
+			{{ end }}
+		}
+		`,
+		"Type", typ,
+		"RawAttributes", g.rawAttributes,
+		"Generics", g.generics,
+	)
+}
+
+func (g *TypesGenerator) generateStructValidationSource(typ *concepts.Type) {
+	// Add the imports needed by the constraints:
+	for _, attribute := range typ.Attributes() {
+		if attribute.Pattern() != "" {
+			g.buffer.Import("regexp", "")
+		}
+		if attribute.Type().IsString() && (attribute.Min() != nil || attribute.Max() != nil) {
+			g.buffer.Import("unicode/utf8", "")
+		}
+	}
+
+	// Generate the code:
+	g.buffer.Import("fmt", "")
+	g.buffer.Import("sync", "")
+	g.buffer.Import(g.packages.HelpersImport(), "")
+	g.buffer.EmitTemplate("struct_validation", `
+		{{ $objectName := objectName .Type }}
+		{{ $listName := listName .Type }}
+		{{ $validatorType := validatorType .Type }}
+		{{ $validatorName := validatorName .Type }}
+
+		// {{ $validatorType }} is the type of the functions that check the values of
+		// '{{ .Type.Name }}' objects that can't be checked with the constraints of the
+		// model, for example rules that involve multiple attributes. If the function
+		// returns an error of type helpers.ValidationErrors then the paths of the fields
+		// will be prefixed with the path of the object.
+		type {{ $validatorType }} func(object *{{ $objectName }}) error
+
+		// {{ $validatorName }} is the function registered with Set{{ $validatorType }}, and
+		// {{ $validatorName }}Lock protects it from concurrent access.
+		var (
+			{{ $validatorName }}     {{ $validatorType }}
+			{{ $validatorName }}Lock = &sync.RWMutex{}
+		)
+
+		// Set{{ $validatorType }} registers a function that will be used, in addition to
+		// the constraints of the model, to check the '{{ .Type.Name }}' objects created by
+		// builders and the ones received by servers in request bodies. It is safe to call
+		// it while other goroutines are validating objects, but it should be called during
+		// the initialization of the program, before any object is built or received, as
+		// the objects validated before the call aren't checked again.
+		func Set{{ $validatorType }}(validator {{ $validatorType }}) {
+			{{ $validatorName }}Lock.Lock()
+			defer {{ $validatorName }}Lock.Unlock()
+			{{ $validatorName }} = validator
+		}
+
+		// Validate checks that the values of the attributes of the object satisfy the
+		// constraints defined in the model and the rules of the function registered with
+		// Set{{ $validatorType }}. The returned error, if any, will be of type
+		// helpers.ValidationErrors.
+		func (o *{{ $objectName }}) Validate() error {
+			var errs helpers.ValidationErrors
+			o.validate("", &errs)
+			if len(errs) > 0 {
+				return errs
 			}
+			return nil
+		}
+
+		{{ range .Type.Attributes }}
+			{{ if .Pattern }}
+				// {{ patternName . }} is the regular expression that the values of the
+				// '{{ .Name }}' attribute must match.
+				var {{ patternName . }} = regexp.MustCompile({{ printf "%q" .Pattern }})
+			{{ end }}
+		{{ end }}
+
+		// validate checks that the values of the attributes of the object satisfy the
+		// constraints defined in the model, and adds the problems found to the given list. The
+		// prefix is added to the names of the fields, so that the problems of nested objects
+		// are reported with the complete path.
+		func (o *{{ $objectName }}) validate(prefix string, errs *helpers.ValidationErrors) {
+			if o == nil {
+				return
+			}
+			{{ if .Type.HasLazyAttributes }}
+				o.decode()
+			{{ end }}
+			{{ range .Type.Attributes }}
+				{{ $attribute := . }}
+				{{ $fieldName := fieldName . }}
+				{{ $field := .Name.Snake }}
+				{{ if and .Type.IsScalar .Constrained }}
+					if o.{{ $fieldName }} != nil {
+						{{ if .Type.IsString }}
+							{{ with .Min }}
+								if utf8.RuneCountInString(*o.{{ $fieldName }}) < {{ . }} {
+									errs.Add(prefix+"{{ $field }}", "must be at least {{ . }} characters long")
+								}
+							{{ end }}
+							{{ with .Max }}
+								if utf8.RuneCountInString(*o.{{ $fieldName }}) > {{ . }} {
+									errs.Add(prefix+"{{ $field }}", "must be at most {{ . }} characters long")
+								}
+							{{ end }}
+							{{ if .Pattern }}
+								if !{{ patternName . }}.MatchString(*o.{{ $fieldName }}) {
+									errs.Add(
+										prefix+"{{ $field }}",
+										{{ printf "%q" (printf "must match the pattern '%s'" .Pattern) }},
+									)
+								}
+							{{ end }}
+						{{ else }}
+							{{ with .Min }}
+								if *o.{{ $fieldName }} < {{ . }} {
+									errs.Add(prefix+"{{ $field }}", "must be greater than or equal to {{ . }}")
+								}
+							{{ end }}
+							{{ with .Max }}
+								if *o.{{ $fieldName }} > {{ . }} {
+									errs.Add(prefix+"{{ $field }}", "must be less than or equal to {{ . }}")
+								}
+							{{ end }}
+						{{ end }}
+					}
+				{{ else if .Type.IsList }}
+					{{ if .Constrained }}
+						if o.{{ $fieldName }} != nil {
+							{{ with .Min }}
+								if {{ if $attribute.Link }}o.{{ $fieldName }}.Len(){{ else }}len(o.{{ $fieldName }}){{ end }} < {{ . }} {
+									errs.Add(prefix+"{{ $field }}", "must have at least {{ . }} items")
+								}
+							{{ end }}
+							{{ with .Max }}
+								if {{ if $attribute.Link }}o.{{ $fieldName }}.Len(){{ else }}len(o.{{ $fieldName }}){{ end }} > {{ . }} {
+									errs.Add(prefix+"{{ $field }}", "must have at most {{ . }} items")
+								}
+							{{ end }}
+						}
+					{{ end }}
+					{{ if and (not .Link) .Type.Element.IsStruct (sameVersion .Type.Element $.Type) }}
+						for i, item := range o.{{ $fieldName }} {
+							item.validate(fmt.Sprintf("%s{{ $field }}[%d].", prefix, i), errs)
+						}
+					{{ end }}
+				{{ else if .Type.IsMap }}
+					{{ if and .Type.Element.IsStruct (sameVersion .Type.Element $.Type) }}
+						for key, item := range o.{{ $fieldName }} {
+							item.validate(fmt.Sprintf("%s{{ $field }}[%s].", prefix, key), errs)
+						}
+					{{ end }}
+				{{ else if and .Type.IsStruct (sameVersion .Type $.Type) }}
+					o.{{ $fieldName }}.validate(prefix+"{{ $field }}.", errs)
+				{{ end }}
+			{{ end }}
+			{{ $validatorName }}Lock.RLock()
+			validator := {{ $validatorName }}
+			{{ $validatorName }}Lock.RUnlock()
+			if validator != nil {
+				errs.Merge(prefix, validator(o))
+			}
+		}
+
+		// checkImmutable compares the object with the given current version and adds to the
+		// given list the attributes that can't be changed once the object has been created
+		// but that have a value different to the current one. This is intended for the
+		// bodies of update requests, where attributes without value aren't changed.
+		func (o *{{ $objectName }}) checkImmutable(current *{{ $objectName }}, prefix string,
+			errs *helpers.ValidationErrors) {
+			if o == nil || current == nil {
+				return
+			}
+			{{ if .Type.HasLazyAttributes }}
+				o.decode()
+				current.decode()
+			{{ end }}
+			{{ range .Type.Attributes }}
+				{{ $fieldName := fieldName . }}
+				{{ $field := .Name.Snake }}
+				{{ if .Immutable }}
+					if o.{{ $fieldName }} != nil && !{{ equalFunc . }}(o.{{ $fieldName }}, current.{{ $fieldName }}) {
+						errs.Add(prefix+"{{ $field }}", "can't be changed once the object has been created")
+					}
+				{{ else if and .Type.IsStruct (sameVersion .Type $.Type) }}
+					o.{{ $fieldName }}.checkImmutable(current.{{ $fieldName }}, prefix+"{{ $field }}.", errs)
+				{{ end }}
+			{{ end }}
+		}
+
+		// equal checks if the object has the same values than the given one. Lists and maps
+		// without items are equal to lists and maps without value.
+		func (o *{{ $objectName }}) equal(other *{{ $objectName }}) bool {
+			if o == nil || other == nil {
+				return o == other
+			}
+			{{ if .Type.HasLazyAttributes }}
+				o.decode()
+				other.decode()
+			{{ end }}
+			{{ if .Type.IsClass }}
+				if o.ID() != other.ID() || o.HREF() != other.HREF() {
+					return false
+				}
+			{{ end }}
+			{{ range .Type.Attributes }}
+				{{ $fieldName := fieldName . }}
+				if !{{ equalFunc . }}(o.{{ $fieldName }}, other.{{ $fieldName }}) {
+					return false
+				}
+			{{ end }}
+			return true
+		}
+
+		// equal checks if the list has the same items than the given one, in the same order.
+		func (l *{{ $listName }}) equal(other *{{ $listName }}) bool {
+			if l.Len() != other.Len() {
+				return false
+			}
+			for i := 0; i < l.Len(); i++ {
+				if !l.items[i].equal(other.items[i]) {
+					return false
+				}
+			}
+			return true
+		}
+
+		{{ range .Type.Attributes }}
+			{{ $equalFunc := equalFunc . }}
+			{{ $fieldType := fieldType . }}
+
+			// {{ $equalFunc }} checks if the given values of the '{{ .Name }}' attribute are
+			// equal.
+			func {{ $equalFunc }}(a, b {{ $fieldType }}) bool {
+				{{ if .Type.IsScalar }}
+					if a == nil || b == nil {
+						return a == b
+					}
+					{{ if .Type.IsDate }}
+						return a.Equal(*b)
+					{{ else if .Type.IsInterface }}
+						return helpers.EqualValues(*a, *b)
+					{{ else }}
+						return *a == *b
+					{{ end }}
+				{{ else if or .Type.IsStruct .Link }}
+					return a.equal(b)
+				{{ else if .Type.IsList }}
+					if len(a) != len(b) {
+						return false
+					}
+					for i, item := range a {
+						{{ with .Type.Element }}
+							{{ if .IsStruct }}
+								if !item.equal(b[i]) {
+							{{ else if .IsDate }}
+								if !item.Equal(b[i]) {
+							{{ else if .IsInterface }}
+								if !helpers.EqualValues(item, b[i]) {
+							{{ else }}
+								if item != b[i] {
+							{{ end }}
+						{{ end }}
+							return false
+						}
+					}
+					return true
+				{{ else if .Type.IsMap }}
+					if len(a) != len(b) {
+						return false
+					}
+					for key, item := range a {
+						value, ok := b[key]
+						if !ok {
+							return false
+						}
+						{{ with .Type.Element }}
+							{{ if .IsStruct }}
+								if !item.equal(value) {
+							{{ else if .IsDate }}
+								if !item.Equal(value) {
+							{{ else if .IsInterface }}
+								if !helpers.EqualValues(item, value) {
+							{{ else }}
+								if item != value {
+							{{ end }}
+						{{ end }}
+							return false
+						}
+					}
+					return true
+				{{ end }}
+			}
+		{{ end }}
+
+		// withoutReadOnly returns a copy of the object where the attributes that are
+		// populated by the server have been removed. The object itself isn't modified.
+		func (o *{{ $objectName }}) withoutReadOnly() *{{ $objectName }} {
+			if o == nil {
+				return nil
+			}
+			{{ if .Type.HasLazyAttributes }}
+				o.decode()
+			{{ end }}
+			result := *o
+			{{ range .Type.Attributes }}
+				{{ $fieldName := fieldName . }}
+				{{ if .ReadOnly }}
+					result.{{ $fieldName }} = nil
+					{{ if .Lazy }}
+						result.{{ $fieldName }}Raw = nil
+						result.{{ $fieldName }}Once = nil
+					{{ end }}
+				{{ else if and .Type.IsStruct (sameVersion .Type $.Type) }}
+					result.{{ $fieldName }} = o.{{ $fieldName }}.withoutReadOnly()
+				{{ end }}
+			{{ end }}
+			return &result
 		}
 		`,
 		"Type", typ,
@@ -571,6 +1248,10 @@ func (g *TypesGenerator) metadataFile() string {
 	return g.names.File(names.Cat(nomenclator.Metadata, nomenclator.Type))
 }
 
+func (g *TypesGenerator) listChecksFile() string {
+	return g.names.File(names.Cat(nomenclator.List, nomenclator.Checks, nomenclator.Test))
+}
+
 func (g *TypesGenerator) typeFile(typ *concepts.Type) string {
 	return g.names.File(names.Cat(typ.Name(), nomenclator.Type))
 }
@@ -618,6 +1299,10 @@ func (g *TypesGenerator) valueTag(value *concepts.EnumValue) string {
 	return value.Name().String()
 }
 
+func (g *TypesGenerator) decodeFunc(attribute *concepts.Attribute) string {
+	return g.names.Private(names.Cat(nomenclator.Decode, attribute.Name()))
+}
+
 func (g *TypesGenerator) getterName(attribute *concepts.Attribute) string {
 	return g.names.Public(attribute.Name())
 }
@@ -649,6 +1334,30 @@ func (g *TypesGenerator) fieldType(attribute *concepts.Attribute) *TypeReference
 	return ref
 }
 
+func (g *TypesGenerator) equalFunc(attribute *concepts.Attribute) string {
+	name := names.Cat(nomenclator.Equal, attribute.Owner().Name(), attribute.Name())
+	return g.names.Private(name)
+}
+
+func (g *TypesGenerator) patternName(attribute *concepts.Attribute) string {
+	name := names.Cat(attribute.Owner().Name(), attribute.Name(), nomenclator.Pattern)
+	return g.names.Private(name)
+}
+
+func (g *TypesGenerator) validatorType(typ *concepts.Type) string {
+	name := names.Cat(typ.Name(), nomenclator.Validator)
+	return g.names.Public(name)
+}
+
+func (g *TypesGenerator) validatorName(typ *concepts.Type) string {
+	name := names.Cat(typ.Name(), nomenclator.Validator)
+	return g.names.Private(name)
+}
+
+func (g *TypesGenerator) sameVersion(a, b *concepts.Type) bool {
+	return a.Owner() == b.Owner()
+}
+
 func (g *TypesGenerator) listName(typ *concepts.Type) string {
 	name := names.Cat(typ.Name(), nomenclator.List)
 	return g.names.Public(name)
@@ -199,6 +199,7 @@ func (b *Buffer) Write() error {
 	if err != nil {
 		return err
 	}
+	b.reporter.Written(b.output)
 
 	return nil
 }
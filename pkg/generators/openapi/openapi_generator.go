@@ -17,6 +17,7 @@ limitations under the License.
 package openapi
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"sort"
@@ -129,6 +130,7 @@ func (g *OpenAPIGenerator) Run() error {
 	// Generate the OpenAPI specification type for each version:
 	for _, service := range g.model.Services() {
 		for _, version := range service.Versions() {
+			g.reporter.Infof("Generating OpenAPI specification for version '%s'", version)
 			err = g.generateSpec(version)
 			if err != nil {
 				return err
@@ -314,8 +316,15 @@ func (g *OpenAPIGenerator) generateResourcePaths(prefix string,
 	}
 }
 func (g *OpenAPIGenerator) generateMethod(path []*concepts.Locator, method *concepts.Method) {
+	requestExamples, responseExamples, otherExamples := g.classifyExamples(method)
 	g.buffer.StartObject(strings.ToLower(g.binding.Method(method)))
-	g.generateDescription(method.Doc())
+	g.generateDescription(g.sinceDescription(
+		g.methodDescription(method, otherExamples),
+		method.Since(),
+	))
+	if method.Since() != "" {
+		g.buffer.Field("x-since", method.Since())
+	}
 	g.generateURLParameters(path, method)
 	parameters := g.binding.RequestBodyParameters(method)
 	if len(parameters) > 0 {
@@ -334,11 +343,98 @@ func (g *OpenAPIGenerator) generateMethod(path []*concepts.Locator, method *conc
 			g.generateSchemaReference(parameters[0].Type())
 		}
 		g.buffer.EndObject()
+		g.generateExamples(requestExamples)
 		g.buffer.EndObject()
 		g.buffer.EndObject()
 		g.buffer.EndObject()
 	}
-	g.generateResponses(method)
+	g.generateResponses(method, responseExamples)
+	g.buffer.EndObject()
+}
+
+// classifyExamples separates the examples of the given method into the examples of request bodies,
+// the examples of response bodies and the rest. Examples of request or response bodies are
+// considered part of the rest if the method doesn't have that kind of body.
+func (g *OpenAPIGenerator) classifyExamples(method *concepts.Method) (requests, responses,
+	others []*concepts.Example) {
+	hasRequest := len(g.binding.RequestBodyParameters(method)) > 0
+	hasResponse := len(g.binding.ResponseParameters(method)) > 0
+	for _, example := range method.Examples() {
+		switch {
+		case example.IsRequest() && hasRequest:
+			requests = append(requests, example)
+		case example.IsResponse() && hasResponse:
+			responses = append(responses, example)
+		default:
+			others = append(others, example)
+		}
+	}
+	return
+}
+
+// methodDescription calculates the description of the given method, adding to its documentation
+// the given examples as listing blocks.
+func (g *OpenAPIGenerator) methodDescription(method *concepts.Method,
+	examples []*concepts.Example) string {
+	doc := method.Doc()
+	if len(examples) == 0 {
+		return doc
+	}
+	buffer := &strings.Builder{}
+	buffer.WriteString(doc)
+	for _, example := range examples {
+		if buffer.Len() > 0 {
+			buffer.WriteString("\n\n")
+		}
+		switch {
+		case example.IsRequest():
+			buffer.WriteString("Example request")
+		case example.IsResponse():
+			buffer.WriteString("Example response")
+		default:
+			buffer.WriteString("Example")
+		}
+		if example.Summary() != "" {
+			fmt.Fprintf(buffer, ": %s", example.Summary())
+		}
+		fmt.Fprintf(buffer, "\n\n----\n%s\n----", example.Text())
+	}
+	return buffer.String()
+}
+
+// sinceDescription adds to the given description a note explaining the version of the API where
+// the element was introduced.
+func (g *OpenAPIGenerator) sinceDescription(doc string, since string) string {
+	if since == "" {
+		return doc
+	}
+	note := fmt.Sprintf("Available since version %s of the API.", since)
+	if doc == "" {
+		return note
+	}
+	return doc + "\n\n" + note
+}
+
+// generateExamples generates the 'examples' field of a media type. Examples that contain valid
+// JSON are added as values, the rest are added as strings.
+func (g *OpenAPIGenerator) generateExamples(examples []*concepts.Example) {
+	if len(examples) == 0 {
+		return
+	}
+	g.buffer.StartObject("examples")
+	for i, example := range examples {
+		g.buffer.StartObject(fmt.Sprintf("example%d", i+1))
+		if example.Summary() != "" {
+			g.buffer.Field("summary", example.Summary())
+		}
+		text := example.Text()
+		if json.Valid([]byte(text)) {
+			g.buffer.Field("value", json.RawMessage(text))
+		} else {
+			g.buffer.Field("value", text)
+		}
+		g.buffer.EndObject()
+	}
 	g.buffer.EndObject()
 }
 
@@ -387,7 +483,8 @@ func (g *OpenAPIGenerator) generateQueryParameter(parameter *concepts.Parameter)
 	g.buffer.EndObject()
 }
 
-func (g *OpenAPIGenerator) generateResponses(method *concepts.Method) {
+func (g *OpenAPIGenerator) generateResponses(method *concepts.Method,
+	examples []*concepts.Example) {
 	g.buffer.StartObject("responses")
 	g.buffer.StartObject(g.binding.DefaultStatus(method))
 	g.generateDescription("Success.")
@@ -407,6 +504,7 @@ func (g *OpenAPIGenerator) generateResponses(method *concepts.Method) {
 			g.generateSchemaReference(parameters[0].Type())
 		}
 		g.buffer.EndObject()
+		g.generateExamples(examples)
 		g.buffer.EndObject()
 		g.buffer.EndObject()
 	}
@@ -474,8 +572,39 @@ func (g *OpenAPIGenerator) generateMetadataSchema() {
 	g.buffer.StartObject("server_version")
 	g.generateDescription("Version of the server.")
 	g.buffer.Field("type", "string")
+	g.buffer.EndObject()
+
+	// Build timestamp:
+	g.buffer.StartObject("build_timestamp")
+	g.generateDescription("Date and time when the server was built.")
+	g.buffer.Field("type", "string")
+	g.buffer.Field("format", "date-time")
+	g.buffer.EndObject()
+
+	// Git commit:
+	g.buffer.StartObject("git_commit")
+	g.generateDescription("Identifier of the git commit used to build the server.")
+	g.buffer.Field("type", "string")
+	g.buffer.EndObject()
 
+	// Generated from:
+	g.buffer.StartObject("generated_from")
+	g.generateDescription(
+		"Revision of the model and version of the metamodel used to generate the code " +
+			"of the server.",
+	)
+	g.buffer.Field("type", "string")
 	g.buffer.EndObject()
+
+	// Features:
+	g.buffer.StartObject("features")
+	g.generateDescription("Names of the optional features enabled in the server.")
+	g.buffer.Field("type", "array")
+	g.buffer.StartObject("items")
+	g.buffer.Field("type", "string")
+	g.buffer.EndObject()
+	g.buffer.EndObject()
+
 	g.buffer.EndObject()
 	g.buffer.EndObject()
 }
@@ -531,8 +660,36 @@ func (g *OpenAPIGenerator) generateStructSchema(typ *concepts.Type) {
 func (g *OpenAPIGenerator) generateStructProperty(attribute *concepts.Attribute) {
 	name := g.names.AttributePropertyName(attribute)
 	g.buffer.StartObject(name)
-	g.generateDescription(attribute.Doc())
-	g.generateSchemaReference(attribute.Type())
+	g.generateDescription(g.sinceDescription(attribute.Doc(), attribute.Since()))
+	typ := attribute.Type()
+	switch {
+	case attribute.Format() != "":
+		g.generateDateFormatReference(attribute.Format())
+	case typ.IsEnum() || typ.IsStruct():
+		// Properties of a schema that contains a reference are ignored, so in order to
+		// keep the description and the flags we need to wrap the reference:
+		g.buffer.StartArray("allOf")
+		g.buffer.StartObject()
+		g.generateSchemaReference(typ)
+		g.buffer.EndObject()
+		g.buffer.EndArray()
+	default:
+		g.generateSchemaReference(typ)
+	}
+	if attribute.ReadOnly() {
+		g.buffer.Field("readOnly", true)
+	}
+	if attribute.Immutable() {
+		// There is no standard way to say that an attribute can be set when the object
+		// is created but not changed later, so we use an extension:
+		g.buffer.Field("x-immutable", true)
+	}
+	if attribute.Sensitive() {
+		g.buffer.Field("x-sensitive", true)
+	}
+	if attribute.Since() != "" {
+		g.buffer.Field("x-since", attribute.Since())
+	}
 	g.buffer.EndObject()
 }
 
@@ -564,6 +721,8 @@ func (g *OpenAPIGenerator) generateSchemaReference(typ *concepts.Type) {
 	case typ == version.DateType():
 		g.buffer.Field("type", "string")
 		g.buffer.Field("format", "date-time")
+	case typ == version.InterfaceType():
+		// A schema without type accepts any value.
 	case typ.IsEnum() || typ.IsStruct():
 		g.buffer.Field("$ref", "#/components/schemas/"+g.names.SchemaName(typ))
 	case typ.IsList():
@@ -584,6 +743,23 @@ func (g *OpenAPIGenerator) generateSchemaReference(typ *concepts.Type) {
 	}
 }
 
+func (g *OpenAPIGenerator) generateDateFormatReference(format string) {
+	switch format {
+	case concepts.DateFormatDate:
+		g.buffer.Field("type", "string")
+		g.buffer.Field("format", "date")
+	case concepts.DateFormatUnix, concepts.DateFormatUnixMillis:
+		// There is no standard format for dates represented as numbers, so we use an
+		// extension to indicate the unit:
+		g.buffer.Field("type", "integer")
+		g.buffer.Field("format", "int64")
+		g.buffer.Field("x-date-format", format)
+	default:
+		g.buffer.Field("type", "string")
+		g.buffer.Field("format", "date-time")
+	}
+}
+
 func (g *OpenAPIGenerator) generateErrorSchema() {
 	g.buffer.StartObject("Error")
 	g.buffer.Field("type", "object")
@@ -0,0 +1,151 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hooks contains the object that runs the commands that post-process the generated files,
+// for example custom formatters or tools that add license headers.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// RunnerBuilder is an object used to configure and build hook runners. Don't create instances
+// directly, use the NewRunner function instead.
+type RunnerBuilder struct {
+	reporter  *reporter.Reporter
+	runHooks  []string
+	fileHooks []string
+	dir       string
+}
+
+// Runner runs the post generation hooks. Don't create instances directly, use the builder
+// instead.
+type Runner struct {
+	reporter  *reporter.Reporter
+	runHooks  []string
+	fileHooks []string
+	dir       string
+}
+
+// NewRunner creates a new builder for hook runners.
+func NewRunner() *RunnerBuilder {
+	return new(RunnerBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the execution of the
+// hooks, including errors.
+func (b *RunnerBuilder) Reporter(value *reporter.Reporter) *RunnerBuilder {
+	b.reporter = value
+	return b
+}
+
+// RunHooks adds commands that will be executed once per run. The names of all the generated
+// files will be passed in the standard input of the command, one per line.
+func (b *RunnerBuilder) RunHooks(values ...string) *RunnerBuilder {
+	b.runHooks = append(b.runHooks, values...)
+	return b
+}
+
+// FileHooks adds commands that will be executed once for each generated file. The name of the
+// file will be passed in the standard input of the command, followed by a new line, and also in
+// the METAMODEL_FILE environment variable.
+func (b *RunnerBuilder) FileHooks(values ...string) *RunnerBuilder {
+	b.fileHooks = append(b.fileHooks, values...)
+	return b
+}
+
+// Dir sets the working directory of the commands. The default is the current working directory.
+func (b *RunnerBuilder) Dir(value string) *RunnerBuilder {
+	b.dir = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// hook runner using it.
+func (b *RunnerBuilder) Build() (runner *Runner, err error) {
+	// Check that the mandatory parameters have been provided:
+	if b.reporter == nil {
+		err = fmt.Errorf("reporter is mandatory")
+		return
+	}
+
+	// Check that the hooks aren't empty:
+	for _, hooks := range [][]string{b.runHooks, b.fileHooks} {
+		for _, hook := range hooks {
+			if strings.TrimSpace(hook) == "" {
+				err = fmt.Errorf("hook commands can't be empty")
+				return
+			}
+		}
+	}
+
+	// Create the runner:
+	runner = &Runner{
+		reporter:  b.reporter,
+		runHooks:  b.runHooks,
+		fileHooks: b.fileHooks,
+		dir:       b.dir,
+	}
+
+	return
+}
+
+// Run executes the hooks for the given files. First it executes the file hooks, for each file
+// and in the order that they were added, and then the run hooks. Commands are executed using
+// the shell, so they can contain arguments, pipes and redirections. Execution stops with the
+// first command that fails.
+func (r *Runner) Run(files []string) error {
+	for _, file := range files {
+		for _, hook := range r.fileHooks {
+			r.reporter.Infof("Running hook '%s' for file '%s'", hook, file)
+			err := r.exec(hook, []string{file}, "METAMODEL_FILE="+file)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	for _, hook := range r.runHooks {
+		r.reporter.Infof("Running hook '%s' for %d generated files", hook, len(files))
+		err := r.exec(hook, files)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Runner) exec(hook string, files []string, env ...string) error {
+	input := strings.Join(files, "\n")
+	if len(files) > 0 {
+		input += "\n"
+	}
+	cmd := exec.Command("sh", "-c", hook)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = strings.NewReader(input)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err := cmd.Run()
+	if err != nil {
+		return fmt.Errorf("hook '%s' failed: %v", hook, err)
+	}
+	return nil
+}
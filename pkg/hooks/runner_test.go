@@ -0,0 +1,98 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the hooks runner.
+
+package hooks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+var _ = Describe("Runner", func() {
+	var tmp string
+
+	BeforeEach(func() {
+		var err error
+		tmp, err = ioutil.TempDir("", "hooks-*")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(tmp)
+	})
+
+	// read returns the content of a file of the temporary directory.
+	read := func(name string) string {
+		data, err := ioutil.ReadFile(filepath.Join(tmp, name))
+		Expect(err).ToNot(HaveOccurred())
+		return string(data)
+	}
+
+	It("Can't be created with empty hooks", func() {
+		_, err := NewRunner().
+			Reporter(reporter.NewReporter()).
+			RunHooks(" ").
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Passes all the files to the run hooks", func() {
+		runner, err := NewRunner().
+			Reporter(reporter.NewReporter()).
+			RunHooks("cat > files.txt").
+			Dir(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = runner.Run([]string{"a.go", "b.go"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read("files.txt")).To(Equal("a.go\nb.go\n"))
+	})
+
+	It("Runs the file hooks once per file", func() {
+		runner, err := NewRunner().
+			Reporter(reporter.NewReporter()).
+			FileHooks(`cat >> stdin.txt; echo "$METAMODEL_FILE" >> env.txt`).
+			Dir(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = runner.Run([]string{"a.go", "b.go"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(read("stdin.txt")).To(Equal("a.go\nb.go\n"))
+		Expect(read("env.txt")).To(Equal("a.go\nb.go\n"))
+	})
+
+	It("Stops when a hook fails", func() {
+		runner, err := NewRunner().
+			Reporter(reporter.NewReporter()).
+			FileHooks("exit 1").
+			RunHooks("touch ran.txt").
+			Dir(tmp).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = runner.Run([]string{"a.go"})
+		Expect(err).To(HaveOccurred())
+		_, err = os.Stat(filepath.Join(tmp, "ran.txt"))
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
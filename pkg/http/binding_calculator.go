@@ -162,6 +162,68 @@ func (c *BindingCalculator) Method(method *concepts.Method) string {
 	}
 }
 
+// AllowedMethods returns the HTTP methods that are allowed for the path of the given resource. This
+// doesn't include the paths of the actions of the resource, as those only allow the POST method.
+// The HEAD method is included when the GET method is allowed, and the OPTIONS method is always
+// included. For the root resource of a version that serves the metadata the GET and HEAD methods
+// are also included.
+func (c *BindingCalculator) AllowedMethods(resource *concepts.Resource) []string {
+	var values []string
+	if c.MetadataEndpoint(resource) {
+		values = append(values, http.MethodGet)
+	}
+	for _, method := range resource.Methods() {
+		if c.MethodSegment(method) != "" {
+			continue
+		}
+		values = append(values, c.Method(method))
+	}
+	return c.allowedMethods(values)
+}
+
+// ServiceAllowedMethods returns the HTTP methods that are allowed for the path of the given
+// service, which returns the list of versions. Like for resources the HEAD method is included
+// because the GET method is allowed, and the OPTIONS method is always included.
+func (c *BindingCalculator) ServiceAllowedMethods(service *concepts.Service) []string {
+	return c.allowedMethods([]string{http.MethodGet})
+}
+
+// allowedMethods removes duplicates from the given list of HTTP methods, adds the HEAD method after
+// the GET method and adds the OPTIONS method at the end.
+func (c *BindingCalculator) allowedMethods(values []string) []string {
+	var result []string
+	added := map[string]bool{}
+	add := func(value string) {
+		if !added[value] {
+			result = append(result, value)
+			added[value] = true
+		}
+	}
+	for _, value := range values {
+		add(value)
+		if value == http.MethodGet {
+			add(http.MethodHead)
+		}
+	}
+	add(http.MethodOptions)
+	return result
+}
+
+// MetadataEndpoint returns true if the path of the given resource should return the metadata of
+// the version in response to GET requests. That is the case for the root resource of the version,
+// unless it has its own GET method.
+func (c *BindingCalculator) MetadataEndpoint(resource *concepts.Resource) bool {
+	if !resource.IsRoot() {
+		return false
+	}
+	for _, method := range resource.Methods() {
+		if c.MethodSegment(method) == "" && c.Method(method) == http.MethodGet {
+			return false
+		}
+	}
+	return true
+}
+
 // Default status returns the HTTP status code that should be returned by default by the given
 // method when there are no errors.
 func (c *BindingCalculator) DefaultStatus(method *concepts.Method) string {
@@ -191,6 +253,56 @@ func (c *BindingCalculator) ParameterName(parameter *concepts.Parameter) string
 	return parameter.Name().Snake()
 }
 
+// SensitivePaths calculates the paths of the attributes of the given type that are marked as
+// sensitive. Each path is a list of JSON field names separated by dots. Lists are traversed
+// transparently, and the asterisk matches any key of a map.
+func (c *BindingCalculator) SensitivePaths(typ *concepts.Type) []string {
+	var paths []string
+	c.collectSensitivePaths(typ, "", map[*concepts.Type]bool{}, &paths)
+	return paths
+}
+
+// RequestBodySensitivePaths calculates the paths of the sensitive attributes of the HTTP request
+// body of the given method, in the same format used by the SensitivePaths method.
+func (c *BindingCalculator) RequestBodySensitivePaths(method *concepts.Method) []string {
+	var paths []string
+	for _, parameter := range c.RequestBodyParameters(method) {
+		// The body of actions is an object that contains one field for each parameter, for
+		// other methods it is the value of the only body parameter:
+		prefix := ""
+		if method.IsAction() {
+			prefix = c.ParameterName(parameter) + "."
+		}
+		c.collectSensitivePaths(parameter.Type(), prefix, map[*concepts.Type]bool{}, &paths)
+	}
+	return paths
+}
+
+func (c *BindingCalculator) collectSensitivePaths(typ *concepts.Type, prefix string,
+	visited map[*concepts.Type]bool, paths *[]string) {
+	switch {
+	case typ.IsList():
+		c.collectSensitivePaths(typ.Element(), prefix, visited, paths)
+	case typ.IsMap():
+		c.collectSensitivePaths(typ.Element(), prefix+"*.", visited, paths)
+	case typ.IsStruct():
+		// Recursive types can't be traversed again, as that would never end:
+		if visited[typ] {
+			return
+		}
+		visited[typ] = true
+		defer delete(visited, typ)
+		for _, attribute := range typ.Attributes() {
+			path := prefix + c.AttributeName(attribute)
+			if attribute.Sensitive() {
+				*paths = append(*paths, path)
+				continue
+			}
+			c.collectSensitivePaths(attribute.Type(), path+".", visited, paths)
+		}
+	}
+}
+
 // ServiceSegment calculates the URL segment corresponding to the given service.
 func (c *BindingCalculator) ServiceSegment(service *concepts.Service) string {
 	return service.Name().Snake()
@@ -32,6 +32,7 @@ CLASS: 'class';
 CODE: 'code';
 ENUM: 'enum';
 ERROR: 'error';
+EVENT: 'event';
 FALSE: 'false';
 IN: 'in';
 LINK: 'link';
@@ -51,6 +52,9 @@ LEFT_CURLY_BRACKET: '{';
 RIGHT_CURLY_BRACKET: '}';
 LEFT_SQUARE_BRACKET: '[';
 RIGHT_SQUARE_BRACKET: ']';
+LEFT_PARENTHESIS: '(';
+RIGHT_PARENTHESIS: ')';
+AT_SIGN: '@';
 
 // Operators:
 EQUALS_SIGN: '=';
@@ -35,6 +35,7 @@ file:
 declaration:
   typeDecl
 | resourceDecl
+| eventDecl
 ;
 
 typeDecl returns[result: *concepts.Type]:
@@ -45,7 +46,8 @@ typeDecl returns[result: *concepts.Type]:
 ;
 
 enumDecl returns[result: *concepts.Type]:
-  'enum' name = identifier '{'
+  'enum' name = identifier
+  constraints += constraintDecl* '{'
     members += enumMemberDecl*
   '}'
 ;
@@ -55,19 +57,26 @@ enumMemberDecl returns[result: *concepts.EnumValue]:
 ;
 
 classDecl returns[result: *concepts.Type]:
-  'class' name = identifier '{'
+  'class' name = identifier
+  constraints += constraintDecl* '{'
     members += structMemberDecl*
   '}'
 ;
 
 structDecl returns[result: *concepts.Type]:
-  'struct' name = identifier '{'
+  'struct' name = identifier
+  constraints += constraintDecl* '{'
     members += structMemberDecl*
   '}'
 ;
 
 structMemberDecl returns[result: *concepts.Attribute]:
   kind = attributeKind? name = identifier reference = typeReference
+  constraints += constraintDecl*
+;
+
+constraintDecl:
+  '@' name = identifier ( '(' value = literal ')' )?
 ;
 
 attributeKind returns[result: int]:
@@ -79,6 +88,7 @@ typeReference returns[result: *concepts.Type]:
   plain = plainTypeReference
 | list = listTypeReference
 | mp = mapTypeReference
+| anonymous = anonymousStructTypeReference
 ;
 
 plainTypeReference returns[result: *concepts.Type]:
@@ -93,8 +103,15 @@ mapTypeReference returns[result: *concepts.Type]:
   '[' index = identifier ']' element = identifier
 ;
 
+anonymousStructTypeReference returns[result: *concepts.Type]:
+  'struct' '{'
+    members += structMemberDecl*
+  '}'
+;
+
 resourceDecl returns[result: *concepts.Resource]:
-  'resource' name = identifier '{'
+  'resource' name = identifier
+  constraints += constraintDecl* '{'
     members += resourceMemberDecl*
   '}'
 ;
@@ -105,7 +122,8 @@ resourceMemberDecl returns[result: interface{}]:
 ;
 
 methodDecl returns[result: *concepts.Method]:
-  'method'? name = identifier '{'
+  'method'? name = identifier
+  constraints += constraintDecl* '{'
     members += methodMemberDecl*
   '}'
 ;
@@ -150,6 +168,10 @@ resourceReference returns[result: *concepts.Resource]:
   name = identifier
 ;
 
+eventDecl returns[result: *concepts.Event]:
+  'event' reference = plainTypeReference
+;
+
 errorDecl returns[result: *concepts.Error]:
   'error' name = identifier '{'
     members += errorMemberDecl*
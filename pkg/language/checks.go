@@ -19,6 +19,8 @@ limitations under the License.
 package language
 
 import (
+	"regexp"
+
 	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
 	"github.com/openshift-online/ocm-api-metamodel/pkg/nomenclator"
 )
@@ -41,10 +43,143 @@ func (r *Reader) checkVersion(version *concepts.Version) {
 		r.reporter.Errorf("Version '%s' doesn't have a root resource", version)
 	}
 
+	// Check the types:
+	for _, typ := range version.Types() {
+		r.checkType(typ)
+	}
+
 	// Check the resources:
 	for _, resource := range version.Resources() {
 		r.checkResource(resource)
 	}
+
+	// Check the events:
+	for _, event := range version.Events() {
+		r.checkEvent(event)
+	}
+}
+
+func (r *Reader) checkEvent(event *concepts.Event) {
+	object := event.Object()
+	if !object.IsStruct() {
+		r.reporter.Errorf(
+			"Type '%s' of event '%s' should be a class or a struct, but it is a %s",
+			object.Name(), event.Name(), object.Kind(),
+		)
+	}
+}
+
+func (r *Reader) checkType(typ *concepts.Type) {
+	if typ.IsStruct() {
+		for _, attribute := range typ.Attributes() {
+			r.checkAttribute(attribute)
+		}
+	}
+}
+
+func (r *Reader) checkAttribute(attribute *concepts.Attribute) {
+	typ := attribute.Type()
+
+	// Minimum and maximum are only valid for numbers, strings and lists:
+	if attribute.Min() != nil || attribute.Max() != nil {
+		numeric := typ.IsInteger() || typ.IsLong() || typ.IsFloat()
+		if !numeric && !typ.IsString() && !typ.IsList() {
+			r.reporter.Errorf(
+				"Attribute '%s' of type '%s' can't have minimum or maximum constraints "+
+					"because its type isn't a number, a string or a list",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+		if (typ.IsString() || typ.IsList()) && attribute.Min() != nil && *attribute.Min() < 0 {
+			r.reporter.Errorf(
+				"Minimum length of attribute '%s' of type '%s' can't be negative",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+		if attribute.Min() != nil && attribute.Max() != nil && *attribute.Min() > *attribute.Max() {
+			r.reporter.Errorf(
+				"Minimum of attribute '%s' of type '%s' is greater than the maximum",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+	}
+
+	// Patterns are only valid for strings, and they must be valid regular expressions:
+	if attribute.Pattern() != "" {
+		if !typ.IsString() {
+			r.reporter.Errorf(
+				"Attribute '%s' of type '%s' can't have a pattern constraint because "+
+					"its type isn't a string",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+		_, err := regexp.Compile(attribute.Pattern())
+		if err != nil {
+			r.reporter.Errorf(
+				"Pattern of attribute '%s' of type '%s' isn't a valid regular "+
+					"expression: %v",
+				attribute.Name(), attribute.Owner().Name(), err,
+			)
+		}
+	}
+
+	// Formats are only valid for dates, and must be one of the supported ones:
+	if attribute.Format() != "" {
+		if !typ.IsDate() {
+			r.reporter.Errorf(
+				"Attribute '%s' of type '%s' can't have a format constraint because "+
+					"its type isn't a date",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+		switch attribute.Format() {
+		case concepts.DateFormatRFC3339,
+			concepts.DateFormatRFC3339Nano,
+			concepts.DateFormatDate,
+			concepts.DateFormatUnix,
+			concepts.DateFormatUnixMillis:
+		default:
+			r.reporter.Errorf(
+				"Format '%s' of attribute '%s' of type '%s' isn't valid, should be "+
+					"'%s', '%s', '%s', '%s' or '%s'",
+				attribute.Format(), attribute.Name(), attribute.Owner().Name(),
+				concepts.DateFormatRFC3339, concepts.DateFormatRFC3339Nano,
+				concepts.DateFormatDate, concepts.DateFormatUnix,
+				concepts.DateFormatUnixMillis,
+			)
+		}
+	}
+
+	// Lazy decoding is only valid for structured types, lists and maps, as decoding scalars is
+	// cheap, and links are already small:
+	if attribute.Lazy() {
+		if !typ.IsStruct() && !typ.IsList() && !typ.IsMap() {
+			r.reporter.Errorf(
+				"Attribute '%s' of type '%s' can't be lazy because its type isn't a "+
+					"struct, a list or a map",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+		if attribute.Link() {
+			r.reporter.Errorf(
+				"Attribute '%s' of type '%s' can't be lazy because it is a link",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+	}
+
+	// Writing the zero value of attributes that aren't set is only possible for types that have
+	// a zero value that is valid in JSON documents:
+	if attribute.Always() {
+		if !typ.IsBoolean() && !typ.IsInteger() && !typ.IsLong() && !typ.IsFloat() &&
+			!typ.IsString() {
+			r.reporter.Errorf(
+				"Attribute '%s' of type '%s' can't be always written because its type "+
+					"isn't a boolean, an integer, a long, a float or a string",
+				attribute.Name(), attribute.Owner().Name(),
+			)
+		}
+	}
 }
 
 func (r *Reader) checkResource(resource *concepts.Resource) {
@@ -68,6 +203,12 @@ func (r *Reader) checkMethod(method *concepts.Method) {
 		r.checkPost(method)
 	case method.IsUpdate():
 		r.checkUpdate(method)
+	case method.IsBulkAdd():
+		r.checkBulkAdd(method)
+	case method.IsBulkDelete():
+		r.checkBulkDelete(method)
+	case method.IsPing():
+		r.checkPing(method)
 	case method.IsAction():
 		r.checkAction(method)
 	default:
@@ -449,6 +590,119 @@ func (r *Reader) checkUpdate(method *concepts.Method) {
 	}
 }
 
+func (r *Reader) checkBulkAdd(method *concepts.Method) {
+	// Check the `items` parameter:
+	items := method.GetParameter(nomenclator.Items)
+	if items == nil {
+		r.reporter.Errorf(
+			"Method '%s' doesn't have a '%s' parameter",
+			method, nomenclator.Items,
+		)
+	} else {
+		if !items.Type().IsList() || !items.Type().Element().IsStruct() {
+			r.reporter.Errorf(
+				"Type of parameter '%s' should be a list of structs but it is '%s'",
+				items, items.Type(),
+			)
+		}
+		if !items.In() || items.Out() {
+			r.reporter.Errorf(
+				"Direction of parameter '%s' should be 'in'",
+				items,
+			)
+		}
+	}
+
+	// The rest of the checks are common for all bulk methods:
+	r.checkBulk(method)
+}
+
+func (r *Reader) checkBulkDelete(method *concepts.Method) {
+	// Only scalar and list of scalar input parameters:
+	for _, parameter := range method.Parameters() {
+		if !parameter.In() {
+			continue
+		}
+		typ := parameter.Type()
+		if !typ.IsScalar() && !(typ.IsList() && typ.Element().IsScalar()) {
+			r.reporter.Errorf(
+				"Type of parameter '%s' should be scalar or list of scalars but it is '%s'",
+				parameter, typ,
+			)
+		}
+	}
+
+	// The rest of the checks are common for all bulk methods:
+	r.checkBulk(method)
+}
+
+func (r *Reader) checkBulk(method *concepts.Method) {
+	// The only output parameter should be `results`:
+	for _, parameter := range method.Parameters() {
+		if parameter.Out() && !nomenclator.Results.Equals(parameter.Name()) {
+			r.reporter.Errorf(
+				"Parameter '%s' should not be an output parameter, the results of "+
+					"bulk methods are reported in the '%s' parameter",
+				parameter, nomenclator.Results,
+			)
+		}
+	}
+
+	// Check the `results` parameter:
+	results := method.GetParameter(nomenclator.Results)
+	if results != nil {
+		typ := results.Type()
+		if !typ.IsList() || !typ.Element().Name().Equals(nomenclator.BulkResult) {
+			r.reporter.Errorf(
+				"Type of parameter '%s' should be a list of '%s' but it is '%s'",
+				results, nomenclator.BulkResult, typ,
+			)
+		}
+		if results.In() || !results.Out() {
+			r.reporter.Errorf(
+				"Direction of parameter '%s' should be 'out'",
+				results,
+			)
+		}
+	}
+}
+
+func (r *Reader) checkPing(method *concepts.Method) {
+	// Ping methods don't have input parameters:
+	for _, parameter := range method.Parameters() {
+		if parameter.In() {
+			r.reporter.Errorf(
+				"Parameter '%s' should not be an input parameter, ping methods don't "+
+					"accept parameters",
+				parameter,
+			)
+		}
+	}
+
+	// Check the `time` and `version` parameters:
+	version := method.Owner().Owner()
+	r.checkPingParameter(method.GetParameter(nomenclator.Time), version.DateType())
+	r.checkPingParameter(method.GetParameter(nomenclator.Version), version.StringType())
+}
+
+func (r *Reader) checkPingParameter(parameter *concepts.Parameter, typ *concepts.Type) {
+	if parameter == nil {
+		return
+	}
+	if parameter.Type() != typ {
+		r.reporter.Errorf(
+			"Type of parameter '%s' should be '%s' but it is '%s'",
+			parameter, typ, parameter.Type(),
+		)
+	}
+	if !parameter.Out() {
+		r.reporter.Errorf(
+			"Direction of parameter '%s' should be 'out'",
+			parameter,
+		)
+	}
+}
+
 func (r *Reader) checkAction(method *concepts.Method) {
 	// Empty on purpose.
 }
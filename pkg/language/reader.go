@@ -20,10 +20,12 @@ limitations under the License.
 package language
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strconv"
 	"strings"
 	"unicode"
@@ -47,6 +49,12 @@ type Reader struct {
 	// Paths of the files and directories to load.
 	inputs []string
 
+	// Names of the experimental features that are enabled.
+	features map[string]bool
+
+	// Indicates if the types and resources marked as internal should be removed.
+	public bool
+
 	// The model, service and version that are currently being loaded:
 	model   *concepts.Model
 	service *concepts.Service
@@ -94,6 +102,35 @@ func (r *Reader) Inputs(values []string) *Reader {
 	return r
 }
 
+// Feature enables an experimental feature. Attributes and methods marked with the
+// '@experimental' annotation are only added to the model when the corresponding feature is
+// enabled. Can be called multiple times to enable multiple features.
+func (r *Reader) Feature(value string) *Reader {
+	if r.features == nil {
+		r.features = map[string]bool{}
+	}
+	r.features[value] = true
+	return r
+}
+
+// Features enables multiple experimental features. This is equivalent to calling the Feature
+// method multiple times.
+func (r *Reader) Features(values []string) *Reader {
+	for _, value := range values {
+		r.Feature(value)
+	}
+	return r
+}
+
+// Public sets the flag that indicates if the types and resources marked with the '@internal'
+// annotation should be removed from the model, together with the locators that point to them.
+// This is intended for generating public SDKs and documentation from a model that also contains
+// private operational endpoints. The default is to keep them.
+func (r *Reader) Public(value bool) *Reader {
+	r.public = value
+	return r
+}
+
 // Read reads the model.
 func (r *Reader) Read() (model *concepts.Model, err error) {
 	// Check the parameters:
@@ -147,18 +184,47 @@ func (r *Reader) Read() (model *concepts.Model, err error) {
 		}
 	}
 
+	// Add the dry run parameter to the methods that modify resources, and the results parameter
+	// to the bulk and ping methods:
+	err = concepts.Walk(r.model, &concepts.VisitorFuncs{
+		Method: func(method *concepts.Method) error {
+			if method.IsAdd() || method.IsUpdate() || method.IsDelete() {
+				r.addDryRun(method)
+			}
+			if method.IsBulk() {
+				r.addBulkResults(method)
+			}
+			if method.IsPing() {
+				r.addPingResults(method)
+			}
+			return concepts.SkipChildren
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	// Remove the internal types and resources:
+	if r.public {
+		for _, service := range r.model.Services() {
+			for _, version := range service.Versions() {
+				r.removeInternal(version)
+			}
+		}
+	}
+
 	// Run checks:
 	r.checkModel()
 
 	// Check methods:
-	for _, service := range r.model.Services() {
-		for _, version := range service.Versions() {
-			for _, resource := range version.Resources() {
-				for _, method := range resource.Methods() {
-					r.checkMethod(method)
-				}
-			}
-		}
+	err = concepts.Walk(r.model, &concepts.VisitorFuncs{
+		Method: func(method *concepts.Method) error {
+			r.checkMethod(method)
+			return concepts.SkipChildren
+		},
+	})
+	if err != nil {
+		return
 	}
 
 	// Check if there are errors:
@@ -374,6 +440,12 @@ func (r *Reader) ExitEnumDecl(ctx *EnumDeclContext) {
 		typ.SetDoc(doc)
 	}
 
+	// Set the constraints:
+	for _, constraintCtx := range ctx.GetConstraints() {
+		typ.AddAnnotation(r.newAnnotation(constraintCtx))
+		r.addTypeConstraint(typ, constraintCtx)
+	}
+
 	// Add the values:
 	memberCtxs := ctx.GetMembers()
 	if len(memberCtxs) > 0 {
@@ -421,6 +493,12 @@ func (r *Reader) ExitClassDecl(ctx *ClassDeclContext) {
 		typ.SetDoc(doc)
 	}
 
+	// Set the constraints:
+	for _, constraintCtx := range ctx.GetConstraints() {
+		typ.AddAnnotation(r.newAnnotation(constraintCtx))
+		r.addTypeConstraint(typ, constraintCtx)
+	}
+
 	// Add the attributes:
 	memberCtxs := ctx.GetMembers()
 	if len(memberCtxs) > 0 {
@@ -428,6 +506,9 @@ func (r *Reader) ExitClassDecl(ctx *ClassDeclContext) {
 			typ.AddAttribute(memberCtx.GetResult())
 		}
 	}
+
+	// Add the anonymous types used by the attributes:
+	r.addAnonymousTypes(typ)
 }
 
 func (r *Reader) ExitStructDecl(ctx *StructDeclContext) {
@@ -453,6 +534,12 @@ func (r *Reader) ExitStructDecl(ctx *StructDeclContext) {
 		typ.SetDoc(doc)
 	}
 
+	// Set the constraints:
+	for _, constraintCtx := range ctx.GetConstraints() {
+		typ.AddAnnotation(r.newAnnotation(constraintCtx))
+		r.addTypeConstraint(typ, constraintCtx)
+	}
+
 	// Add the attributes:
 	memberCtxs := ctx.GetMembers()
 	if len(memberCtxs) > 0 {
@@ -460,6 +547,39 @@ func (r *Reader) ExitStructDecl(ctx *StructDeclContext) {
 			typ.AddAttribute(memberCtx.GetResult())
 		}
 	}
+
+	// Add the anonymous types used by the attributes:
+	r.addAnonymousTypes(typ)
+}
+
+// newAnnotation creates the annotation that corresponds to a constraint declaration. All the
+// constraints are added to the concepts as annotations, including the ones that the reader
+// doesn't understand.
+func (r *Reader) newAnnotation(ctx IConstraintDeclContext) *concepts.Annotation {
+	annotation := concepts.NewAnnotation()
+	annotation.SetName(ctx.GetName().GetResult())
+	if ctx.GetValue() != nil {
+		annotation.SetValue(ctx.GetValue().GetResult())
+	}
+	return annotation
+}
+
+func (r *Reader) addTypeConstraint(typ *concepts.Type, ctx IConstraintDeclContext) {
+	name := ctx.GetName().GetResult()
+	switch name.Snake() {
+	case "internal":
+		if ctx.GetValue() != nil {
+			r.reporter.Errorf(
+				"Constraint '%s' of type '%s' doesn't accept a value",
+				name, typ.Name(),
+			)
+			return
+		}
+		typ.SetInternal(true)
+	default:
+		// Unknown constraints aren't errors, they are kept as annotations so that
+		// generators can use them.
+	}
 }
 
 func (r *Reader) ExitStructMemberDecl(ctx *StructMemberDeclContext) {
@@ -480,10 +600,109 @@ func (r *Reader) ExitStructMemberDecl(ctx *StructMemberDeclContext) {
 		attribute.SetLink(kind.GetResult() == ModelLexerLINK)
 	}
 
+	// Set the constraints:
+	for _, constraintCtx := range ctx.GetConstraints() {
+		attribute.AddAnnotation(r.newAnnotation(constraintCtx))
+		r.addConstraint(attribute, constraintCtx)
+	}
+
+	// Discard the attribute if it belongs to an experimental feature that isn't enabled:
+	if attribute.Feature() != "" && !r.features[attribute.Feature()] {
+		ctx.SetResult(nil)
+		return
+	}
+
 	// Return the attribute:
 	ctx.SetResult(attribute)
 }
 
+func (r *Reader) addConstraint(attribute *concepts.Attribute, ctx IConstraintDeclContext) {
+	name := ctx.GetName().GetResult()
+	var value interface{}
+	if ctx.GetValue() != nil {
+		value = ctx.GetValue().GetResult()
+	}
+	switch name.Snake() {
+	case "min", "max":
+		number, ok := value.(int)
+		if !ok {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of attribute '%s' should be an integer",
+				name, attribute.Name(),
+			)
+			return
+		}
+		if name.Snake() == "min" {
+			attribute.SetMin(&number)
+		} else {
+			attribute.SetMax(&number)
+		}
+	case "pattern":
+		text, ok := value.(string)
+		if !ok {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of attribute '%s' should be a string",
+				name, attribute.Name(),
+			)
+			return
+		}
+		attribute.SetPattern(text)
+	case "format":
+		text, ok := value.(string)
+		if !ok {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of attribute '%s' should be a string",
+				name, attribute.Name(),
+			)
+			return
+		}
+		attribute.SetFormat(text)
+	case "since":
+		text, ok := value.(string)
+		if !ok || text == "" {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of attribute '%s' should be a version string",
+				name, attribute.Name(),
+			)
+			return
+		}
+		attribute.SetSince(text)
+	case "experimental":
+		text, ok := value.(string)
+		if !ok || text == "" {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of attribute '%s' should be a feature name",
+				name, attribute.Name(),
+			)
+			return
+		}
+		attribute.SetFeature(text)
+	case "immutable", "readonly", "lazy", "always", "sensitive":
+		if value != nil {
+			r.reporter.Errorf(
+				"Constraint '%s' of attribute '%s' doesn't accept a value",
+				name, attribute.Name(),
+			)
+			return
+		}
+		switch name.Snake() {
+		case "immutable":
+			attribute.SetImmutable(true)
+		case "readonly":
+			attribute.SetReadOnly(true)
+		case "lazy":
+			attribute.SetLazy(true)
+		case "always":
+			attribute.SetAlways(true)
+		case "sensitive":
+			attribute.SetSensitive(true)
+		}
+	default:
+		// Unknown constraints aren't errors, they are kept as annotations so that
+		// generators can use them.
+	}
+}
+
 func (r *Reader) ExitAttributeKind(ctx *AttributeKindContext) {
 	ctx.SetResult(ctx.GetStart().GetTokenType())
 }
@@ -499,6 +718,9 @@ func (r *Reader) ExitTypeReference(ctx *TypeReferenceContext) {
 	if ctx.GetMp() != nil {
 		typ = ctx.GetMp().GetResult()
 	}
+	if ctx.GetAnonymous() != nil {
+		typ = ctx.GetAnonymous().GetResult()
+	}
 	ctx.SetResult(typ)
 }
 
@@ -580,6 +802,145 @@ func (r *Reader) ExitMapTypeReference(ctx *MapTypeReferenceContext) {
 	ctx.SetResult(mapType)
 }
 
+func (r *Reader) ExitAnonymousStructTypeReference(ctx *AnonymousStructTypeReferenceContext) {
+	// Create the type without a name, it will be named and added to the version when the
+	// declaration of the type that contains it is completed:
+	typ := concepts.NewType()
+	typ.SetKind(concepts.StructType)
+
+	// Add the attributes:
+	for _, memberCtx := range ctx.GetMembers() {
+		typ.AddAttribute(memberCtx.GetResult())
+	}
+
+	// Return the type:
+	ctx.SetResult(typ)
+}
+
+// addAnonymousTypes gives names to the anonymous struct types used by the attributes of the given
+// type and adds them to the version. The name of an anonymous type is the name of the type that
+// contains it followed by the name of the attribute, for example the type of the 'Console'
+// attribute of the 'Cluster' class will be 'ClusterConsole'.
+func (r *Reader) addAnonymousTypes(typ *concepts.Type) {
+	for _, attribute := range typ.Attributes() {
+		anonymous := attribute.Type()
+		if anonymous == nil || anonymous.Name() != nil {
+			continue
+		}
+		name := names.Cat(typ.Name(), attribute.Name())
+		if r.version.FindType(name) != nil {
+			r.reporter.Errorf(
+				"Type '%s' of anonymous attribute '%s' is already defined",
+				name, attribute.Name(),
+			)
+			continue
+		}
+		anonymous.SetName(name)
+		if anonymous.Doc() == "" {
+			anonymous.SetDoc(attribute.Doc())
+		}
+		r.version.AddType(anonymous)
+		r.addAnonymousTypes(anonymous)
+	}
+}
+
+func (r *Reader) ExitEventDecl(ctx *EventDeclContext) {
+	// Check that the event isn't already defined:
+	object := ctx.GetReference().GetResult()
+	name := names.Cat(object.Name(), nomenclator.Event)
+	if r.version.FindEvent(name) != nil {
+		r.reporter.Errorf("Event '%s' is already defined", name)
+		return
+	}
+	if r.version.FindType(name) != nil {
+		r.reporter.Errorf(
+			"Payload type '%s' of event for type '%s' is already defined",
+			name, object.Name(),
+		)
+		return
+	}
+
+	// Create the event:
+	event := concepts.NewEvent()
+	event.SetName(name)
+	event.SetObject(object)
+	doc := r.getDoc(ctx.GetStart())
+	if doc != "" {
+		event.SetDoc(doc)
+	}
+
+	// Create the class that is sent as the payload of the event:
+	payload := concepts.NewType()
+	payload.SetKind(concepts.ClassType)
+	payload.SetName(name)
+	if doc != "" {
+		payload.SetDoc(doc)
+	} else {
+		payload.SetDoc(fmt.Sprintf(
+			"Notification of a change of an object of type '%s'.",
+			object.Name(),
+		))
+	}
+	action := concepts.NewAttribute()
+	action.SetName(nomenclator.Action)
+	action.SetType(r.eventActionType(r.version))
+	action.SetDoc("Action that changed the object.")
+	payload.AddAttribute(action)
+	resource := concepts.NewAttribute()
+	resource.SetName(nomenclator.Resource)
+	resource.SetType(r.version.StringType())
+	resource.SetDoc("Absolute path of the resource that manages the object.")
+	payload.AddAttribute(resource)
+	timestamp := concepts.NewAttribute()
+	timestamp.SetName(nomenclator.Timestamp)
+	timestamp.SetType(r.version.DateType())
+	timestamp.SetDoc("Date and time when the change happened.")
+	payload.AddAttribute(timestamp)
+	before := concepts.NewAttribute()
+	before.SetName(nomenclator.Before)
+	before.SetType(object)
+	before.SetDoc("State of the object before the change. Not set when the object is created.")
+	payload.AddAttribute(before)
+	after := concepts.NewAttribute()
+	after.SetName(nomenclator.After)
+	after.SetType(object)
+	after.SetDoc("State of the object after the change. Not set when the object is deleted.")
+	payload.AddAttribute(after)
+	r.version.AddType(payload)
+	event.SetPayload(payload)
+
+	// Add the event to the version:
+	r.version.AddEvent(event)
+	ctx.SetResult(event)
+}
+
+// eventActionType returns the enumerated type used to indicate the action that triggered events,
+// creating it if it doesn't exist yet.
+func (r *Reader) eventActionType(version *concepts.Version) *concepts.Type {
+	actionType := version.FindType(nomenclator.EventAction)
+	if actionType != nil {
+		return actionType
+	}
+	actionType = concepts.NewType()
+	actionType.SetKind(concepts.EnumType)
+	actionType.SetName(nomenclator.EventAction)
+	actionType.SetDoc("Action that changed the object notified by an event.")
+	create := concepts.NewEnumValue()
+	create.SetName(nomenclator.Create)
+	create.SetDoc("The object was created.")
+	actionType.AddValue(create)
+	update := concepts.NewEnumValue()
+	update.SetName(nomenclator.Update)
+	update.SetDoc("The object was updated.")
+	actionType.AddValue(update)
+	remove := concepts.NewEnumValue()
+	remove.SetName(nomenclator.Delete)
+	remove.SetDoc("The object was deleted.")
+	actionType.AddValue(remove)
+	version.AddType(actionType)
+	return actionType
+}
+
 func (r *Reader) ExitResourceReference(ctx *ResourceReferenceContext) {
 	name := ctx.GetName().GetResult()
 	resource := r.version.FindResource(name)
@@ -624,6 +985,12 @@ func (r *Reader) ExitResourceDecl(ctx *ResourceDeclContext) {
 		resource.SetDoc(doc)
 	}
 
+	// Set the constraints:
+	for _, constraintCtx := range ctx.GetConstraints() {
+		resource.AddAnnotation(r.newAnnotation(constraintCtx))
+		r.addResourceConstraint(resource, constraintCtx)
+	}
+
 	// Add the attributes:
 	memberCtxs := ctx.GetMembers()
 	if len(memberCtxs) > 0 {
@@ -638,16 +1005,50 @@ func (r *Reader) ExitResourceDecl(ctx *ResourceDeclContext) {
 	}
 }
 
+func (r *Reader) addResourceConstraint(resource *concepts.Resource,
+	ctx IConstraintDeclContext) {
+	name := ctx.GetName().GetResult()
+	switch name.Snake() {
+	case "internal":
+		if ctx.GetValue() != nil {
+			r.reporter.Errorf(
+				"Constraint '%s' of resource '%s' doesn't accept a value",
+				name, resource.Name(),
+			)
+			return
+		}
+		resource.SetInternal(true)
+	default:
+		// Unknown constraints aren't errors, they are kept as annotations so that
+		// generators can use them.
+	}
+}
+
 func (r *Reader) ExitMethodDecl(ctx *MethodDeclContext) {
 	// Create the method and set the basic properties:
 	method := concepts.NewMethod()
 	method.SetName(ctx.GetName().GetResult())
 
-	// Add the documentation:
-	doc := r.getDoc(ctx.GetStart())
+	// Add the documentation and the examples that it contains:
+	doc, examples := r.splitExamples(r.getDoc(ctx.GetStart()))
 	if doc != "" {
 		method.SetDoc(doc)
 	}
+	for _, example := range examples {
+		if (example.IsRequest() || example.IsResponse()) && !json.Valid([]byte(example.Text())) {
+			r.reporter.Errorf(
+				"Example %s of method '%s' isn't valid JSON",
+				example.Kind(), method.Name(),
+			)
+		}
+		method.AddExample(example)
+	}
+
+	// Set the constraints:
+	for _, constraintCtx := range ctx.GetConstraints() {
+		method.AddAnnotation(r.newAnnotation(constraintCtx))
+		r.addMethodConstraint(method, constraintCtx)
+	}
 
 	// Add the membmers:
 	membersCtxs := ctx.GetMembers()
@@ -660,10 +1061,49 @@ func (r *Reader) ExitMethodDecl(ctx *MethodDeclContext) {
 		}
 	}
 
+	// Discard the method if it belongs to an experimental feature that isn't enabled:
+	if method.Feature() != "" && !r.features[method.Feature()] {
+		ctx.SetResult(nil)
+		return
+	}
+
 	// Return the method:
 	ctx.SetResult(method)
 }
 
+func (r *Reader) addMethodConstraint(method *concepts.Method, ctx IConstraintDeclContext) {
+	name := ctx.GetName().GetResult()
+	var value interface{}
+	if ctx.GetValue() != nil {
+		value = ctx.GetValue().GetResult()
+	}
+	switch name.Snake() {
+	case "since":
+		text, ok := value.(string)
+		if !ok || text == "" {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of method '%s' should be a version string",
+				name, method.Name(),
+			)
+			return
+		}
+		method.SetSince(text)
+	case "experimental":
+		text, ok := value.(string)
+		if !ok || text == "" {
+			r.reporter.Errorf(
+				"Value of constraint '%s' of method '%s' should be a feature name",
+				name, method.Name(),
+			)
+			return
+		}
+		method.SetFeature(text)
+	default:
+		// Unknown constraints aren't errors, they are kept as annotations so that
+		// generators can use them.
+	}
+}
+
 func (r *Reader) ExitMethodMemberDecl(ctx *MethodMemberDeclContext) {
 	if ctx.MethodParameterDecl() != nil {
 		ctx.SetResult(ctx.MethodParameterDecl().GetResult())
@@ -854,6 +1294,182 @@ func (r *Reader) ExitLiteral(ctx *LiteralContext) {
 	}
 }
 
+// addDryRun adds to the given method the parameter that clients use to request that the server
+// validates the request without actually applying the changes. If the method already has a
+// parameter with that name it will be left unchanged.
+func (r *Reader) addDryRun(method *concepts.Method) {
+	if method.GetParameter(nomenclator.DryRun) != nil {
+		return
+	}
+	parameter := concepts.NewParameter()
+	parameter.SetName(nomenclator.DryRun)
+	parameter.SetType(method.Owner().Owner().Boolean())
+	parameter.SetIn(true)
+	parameter.SetDoc(
+		"If true the server will validate the request and report any errors, but it will " +
+			"not apply the changes.\n" +
+			"\n" +
+			"Servers that don't support validation only requests must reject them instead " +
+			"of ignoring this parameter.",
+	)
+	method.AddParameter(parameter)
+}
+
+// addPingResults adds to the given ping method the output parameters that contain the time and
+// version of the server. Parameters that already exist will be left unchanged.
+func (r *Reader) addPingResults(method *concepts.Method) {
+	version := method.Owner().Owner()
+	if method.GetParameter(nomenclator.Time) == nil {
+		parameter := concepts.NewParameter()
+		parameter.SetName(nomenclator.Time)
+		parameter.SetType(version.DateType())
+		parameter.SetOut(true)
+		parameter.SetDoc("Current time of the server.")
+		method.AddParameter(parameter)
+	}
+	if method.GetParameter(nomenclator.Version) == nil {
+		parameter := concepts.NewParameter()
+		parameter.SetName(nomenclator.Version)
+		parameter.SetType(version.StringType())
+		parameter.SetOut(true)
+		parameter.SetDoc("Version of the server.")
+		method.AddParameter(parameter)
+	}
+}
+
+// removeInternal removes from the version the types and resources that are marked as internal,
+// and the locators that point to those resources. Public types and methods that still use the
+// internal types are reported as errors, as they would generate code that doesn't compile.
+func (r *Reader) removeInternal(version *concepts.Version) {
+	for _, resource := range version.Resources() {
+		if resource.Internal() {
+			version.RemoveResource(resource)
+		}
+	}
+	for _, resource := range version.Resources() {
+		var locators []*concepts.Locator
+		for _, locator := range resource.Locators() {
+			if locator.Target() != nil && locator.Target().Internal() {
+				locators = append(locators, locator)
+			}
+		}
+		for _, locator := range locators {
+			resource.RemoveLocator(locator)
+		}
+	}
+	for _, typ := range version.Types() {
+		if r.isInternal(typ) {
+			version.RemoveType(typ)
+		}
+	}
+	for _, typ := range version.Types() {
+		for _, attribute := range typ.Attributes() {
+			if r.isInternal(attribute.Type()) {
+				r.reporter.Errorf(
+					"Attribute '%s' of public type '%s' uses internal type '%s'",
+					attribute.Name(), typ.Name(), attribute.Type().Name(),
+				)
+			}
+		}
+	}
+	for _, resource := range version.Resources() {
+		for _, method := range resource.Methods() {
+			for _, parameter := range method.Parameters() {
+				if r.isInternal(parameter.Type()) {
+					r.reporter.Errorf(
+						"Parameter '%s' of method '%s' of public resource '%s' "+
+							"uses internal type '%s'",
+						parameter.Name(), method.Name(), resource.Name(),
+						parameter.Type().Name(),
+					)
+				}
+			}
+		}
+	}
+}
+
+// isInternal checks if the given type is internal, or if it is a list or map of internal types.
+func (r *Reader) isInternal(typ *concepts.Type) bool {
+	switch {
+	case typ == nil:
+		return false
+	case typ.Internal():
+		return true
+	case typ.IsList() || typ.IsMap():
+		return r.isInternal(typ.Element())
+	default:
+		return false
+	}
+}
+
+// addBulkResults adds to the given bulk method the output parameter that servers use to report
+// the result of processing each object. The type of the elements of that parameter is created if
+// it doesn't exist yet. If the method already has a parameter with that name it will be left
+// unchanged.
+func (r *Reader) addBulkResults(method *concepts.Method) {
+	if method.GetParameter(nomenclator.Results) != nil {
+		return
+	}
+	version := method.Owner().Owner()
+	parameter := concepts.NewParameter()
+	parameter.SetName(nomenclator.Results)
+	parameter.SetType(r.bulkResultListType(version))
+	parameter.SetOut(true)
+	parameter.SetDoc(
+		"Results of processing the objects, in the same order that they were given in " +
+			"the request.",
+	)
+	method.AddParameter(parameter)
+}
+
+// bulkResultListType returns the list type used to report the results of bulk methods, creating
+// it and the type of its elements if they don't exist yet.
+func (r *Reader) bulkResultListType(version *concepts.Version) *concepts.Type {
+	resultType := version.FindType(nomenclator.BulkResult)
+	if resultType == nil {
+		resultType = concepts.NewType()
+		resultType.SetKind(concepts.StructType)
+		resultType.SetName(nomenclator.BulkResult)
+		resultType.SetDoc(
+			"Result of processing one of the objects of a bulk method.",
+		)
+		index := concepts.NewAttribute()
+		index.SetName(nomenclator.Index)
+		index.SetType(version.IntegerType())
+		index.SetDoc("Position of the object in the request, starting with zero.")
+		resultType.AddAttribute(index)
+		id := concepts.NewAttribute()
+		id.SetName(nomenclator.ID)
+		id.SetType(version.StringType())
+		id.SetDoc("Identifier of the object that was processed, if it has one.")
+		resultType.AddAttribute(id)
+		status := concepts.NewAttribute()
+		status.SetName(nomenclator.Status)
+		status.SetType(version.IntegerType())
+		status.SetDoc(
+			"HTTP status code that the server would have returned if the object had " +
+				"been processed with an individual request.",
+		)
+		resultType.AddAttribute(status)
+		reason := concepts.NewAttribute()
+		reason.SetName(nomenclator.Reason)
+		reason.SetType(version.StringType())
+		reason.SetDoc("Human readable description of the error, if processing failed.")
+		resultType.AddAttribute(reason)
+		version.AddType(resultType)
+	}
+	listName := names.Cat(nomenclator.BulkResult, nomenclator.List)
+	listType := version.FindType(listName)
+	if listType == nil {
+		listType = concepts.NewType()
+		listType.SetKind(concepts.ListType)
+		listType.SetName(listName)
+		listType.SetElement(resultType)
+		version.AddType(listType)
+	}
+	return listType
+}
+
 func (r *Reader) isUndefinedType(typ *concepts.Type) bool {
 	key := typ.Name().String()
 	_, ok := r.undefinedTypes[key]
@@ -960,3 +1576,70 @@ func (r *Reader) getDoc(token antlr.Token) string {
 	// Return the lines joined:
 	return strings.Join(lines, "\n")
 }
+
+// splitExamples extracts the example sections from the given documentation. Each example starts
+// with a line like 'Example:', 'Example request:' or 'Example response:', optionally followed by a
+// summary, and contains all the lines till the next example or the end of the documentation. It
+// returns the documentation without the examples and the list of examples.
+func (r *Reader) splitExamples(doc string) (result string, examples []*concepts.Example) {
+	var text []string
+	var body []string
+	var example *concepts.Example
+	for _, line := range strings.Split(doc, "\n") {
+		match := exampleHeaderRE.FindStringSubmatch(line)
+		if match != nil {
+			if example != nil {
+				example.SetText(exampleText(body))
+				examples = append(examples, example)
+			}
+			example = concepts.NewExample()
+			example.SetKind(concepts.ExampleKind(strings.TrimSpace(match[1])))
+			example.SetSummary(strings.TrimSpace(match[2]))
+			body = nil
+			continue
+		}
+		if example != nil {
+			body = append(body, line)
+		} else {
+			text = append(text, line)
+		}
+	}
+	if example == nil {
+		result = doc
+		return
+	}
+	example.SetText(exampleText(body))
+	examples = append(examples, example)
+	result = strings.TrimRight(strings.Join(text, "\n"), "\n")
+	return
+}
+
+// exampleText removes the leading and trailing blank lines of the given example, and the
+// indentation that is common to all the lines.
+func exampleText(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	prefix := ""
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := line[0 : len(line)-len(strings.TrimLeftFunc(line, unicode.IsSpace))]
+		if i == 0 || len(indent) < len(prefix) {
+			prefix = indent
+		}
+	}
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = strings.TrimPrefix(line, prefix)
+	}
+	return strings.Join(result, "\n")
+}
+
+// exampleHeaderRE is the regular expression used to find the lines of documentation comments that
+// start examples.
+var exampleHeaderRE = regexp.MustCompile(`^Example( request| response)?:(.*)$`)
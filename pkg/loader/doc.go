@@ -0,0 +1,37 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package loader is the entry point for tools that need to read a model, like linters,
+// documentation sites or custom code generators, without invoking the command line tool. It
+// reads the model files and returns the resulting concepts.Model, already checked and with the
+// same implicit types and parameters that the generators of this project see. For example:
+//
+//	loader, err := loader.NewLoader().
+//		Path("model").
+//		Feature("my_feature").
+//		Build()
+//	if err != nil {
+//		...
+//	}
+//	model, err := loader.Load()
+//
+// When the default options are enough the Load function can be used instead:
+//
+//	model, err := loader.Load("model")
+//
+// The API of this package is stable: new options may be added, but the existing ones will not
+// be changed or removed in incompatible ways.
+package loader
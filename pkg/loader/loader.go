@@ -0,0 +1,190 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the implementation of the loader.
+
+package loader
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/concepts"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/exporter"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/language"
+	"github.com/openshift-online/ocm-api-metamodel/pkg/reporter"
+)
+
+// LoaderBuilder is an object used to configure and build model loaders. Don't create instances
+// directly, use the NewLoader function instead.
+type LoaderBuilder struct {
+	reporter   *reporter.Reporter
+	paths      []string
+	descriptor string
+	features   []string
+	public     bool
+}
+
+// Loader reads models from files. Don't create instances directly, use the builder instead.
+type Loader struct {
+	reporter   *reporter.Reporter
+	paths      []string
+	descriptor string
+	features   []string
+	public     bool
+}
+
+// NewLoader creates a new builder for model loaders.
+func NewLoader() *LoaderBuilder {
+	return new(LoaderBuilder)
+}
+
+// Reporter sets the object that will be used to report information about the loading process,
+// including errors. This is optional, the default is to create a reporter that writes the
+// messages to the standard output.
+func (b *LoaderBuilder) Reporter(value *reporter.Reporter) *LoaderBuilder {
+	b.reporter = value
+	return b
+}
+
+// Path adds the path of a file or directory containing the model. If it is a directory then all
+// the '.model' files inside it and its sub directories will be loaded. Can be called multiple
+// times to load the model from multiple files and directories, and they will be loaded in the
+// same order. At least one path or a descriptor is mandatory.
+func (b *LoaderBuilder) Path(value string) *LoaderBuilder {
+	b.paths = append(b.paths, value)
+	return b
+}
+
+// Paths adds multiple model paths. This is equivalent to calling the Path method multiple times.
+func (b *LoaderBuilder) Paths(values ...string) *LoaderBuilder {
+	b.paths = append(b.paths, values...)
+	return b
+}
+
+// Descriptor sets the path of a JSON or YAML document, previously created with the exporter,
+// that will be used instead of the model files. This is useful to run generators in environments
+// where the model files aren't available. The model in the descriptor is already resolved, so
+// the features and public options have no effect. Can't be used together with paths.
+func (b *LoaderBuilder) Descriptor(value string) *LoaderBuilder {
+	b.descriptor = value
+	return b
+}
+
+// Feature enables an experimental feature. Attributes and methods marked with the
+// '@experimental' annotation are only added to the model when the corresponding feature is
+// enabled. Can be called multiple times to enable multiple features.
+func (b *LoaderBuilder) Feature(value string) *LoaderBuilder {
+	b.features = append(b.features, value)
+	return b
+}
+
+// Features enables multiple experimental features. This is equivalent to calling the Feature
+// method multiple times.
+func (b *LoaderBuilder) Features(values ...string) *LoaderBuilder {
+	b.features = append(b.features, values...)
+	return b
+}
+
+// Public sets the flag that indicates if the types and resources marked with the '@internal'
+// annotation should be removed from the model. The default is to keep them.
+func (b *LoaderBuilder) Public(value bool) *LoaderBuilder {
+	b.public = value
+	return b
+}
+
+// Build checks the configuration stored in the builder and, if it is correct, creates a new
+// loader using it.
+func (b *LoaderBuilder) Build() (loader *Loader, err error) {
+	// Check that the mandatory parameters have been provided:
+	if len(b.paths) == 0 && b.descriptor == "" {
+		err = fmt.Errorf("at least one model path or a descriptor is mandatory")
+		return
+	}
+	if len(b.paths) > 0 && b.descriptor != "" {
+		err = fmt.Errorf("model paths and descriptor can't be used together")
+		return
+	}
+
+	// Use the default reporter if none has been provided:
+	report := b.reporter
+	if report == nil {
+		report = reporter.NewReporter()
+	}
+
+	// Copy the paths and features so that changes in the builder don't affect the loader:
+	paths := make([]string, len(b.paths))
+	copy(paths, b.paths)
+	features := make([]string, len(b.features))
+	copy(features, b.features)
+
+	// Create the loader:
+	loader = &Loader{
+		reporter:   report,
+		paths:      paths,
+		descriptor: b.descriptor,
+		features:   features,
+		public:     b.public,
+	}
+
+	return
+}
+
+// Load reads the model. The returned error will be nil only if the model was read and checked
+// without errors. The details of the errors are sent to the reporter.
+func (l *Loader) Load() (model *concepts.Model, err error) {
+	if l.descriptor != "" {
+		model, err = l.loadDescriptor()
+		return
+	}
+	model, err = language.NewReader().
+		Reporter(l.reporter).
+		Inputs(l.paths).
+		Features(l.features).
+		Public(l.public).
+		Read()
+	return
+}
+
+func (l *Loader) loadDescriptor() (model *concepts.Model, err error) {
+	file, err := os.Open(l.descriptor)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	importer, err := exporter.NewImporter().
+		Reporter(l.reporter).
+		Input(file).
+		Build()
+	if err != nil {
+		return
+	}
+	model, err = importer.Import()
+	return
+}
+
+// Load is a shortcut that reads the model from the given paths using the default options. It is
+// equivalent to creating a loader with those paths and calling its Load method.
+func Load(paths ...string) (model *concepts.Model, err error) {
+	loader, err := NewLoader().
+		Paths(paths...).
+		Build()
+	if err != nil {
+		return
+	}
+	model, err = loader.Load()
+	return
+}
@@ -0,0 +1,130 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the model loader.
+
+package loader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/names"
+)
+
+var _ = Describe("Loader", func() {
+	It("Can't be created without paths", func() {
+		_, err := NewLoader().Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Loads the model with the default options", func() {
+		model, err := Load("../../tests/model")
+		Expect(err).ToNot(HaveOccurred())
+		service := model.FindService(names.ParseUsingSeparator("clusters_mgmt", "_"))
+		Expect(service).ToNot(BeNil())
+		version := service.FindVersion(names.ParseUsingCase("V1"))
+		Expect(version).ToNot(BeNil())
+		Expect(version.FindType(names.ParseUsingCase("Cluster"))).ToNot(BeNil())
+		Expect(version.FindType(names.ParseUsingCase("ClusterList"))).ToNot(BeNil())
+	})
+
+	It("Removes internal types in public mode", func() {
+		loader, err := NewLoader().
+			Path("../../tests/model").
+			Public(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		model, err := loader.Load()
+		Expect(err).ToNot(HaveOccurred())
+		service := model.FindService(names.ParseUsingSeparator("clusters_mgmt", "_"))
+		version := service.FindVersion(names.ParseUsingCase("V1"))
+		Expect(version.FindType(names.ParseUsingCase("MaintenanceWindow"))).To(BeNil())
+	})
+
+	It("Keeps unknown annotations", func() {
+		model, err := Load("../../tests/model")
+		Expect(err).ToNot(HaveOccurred())
+		service := model.FindService(names.ParseUsingSeparator("clusters_mgmt", "_"))
+		version := service.FindVersion(names.ParseUsingCase("V1"))
+		typ := version.FindType(names.ParseUsingCase("Cluster"))
+		var found bool
+		for _, attribute := range typ.Attributes() {
+			if attribute.Name().Equals(names.ParseUsingCase("MultiAZ")) {
+				annotation := attribute.FindAnnotation(names.ParseUsingCase("Label"))
+				Expect(annotation).ToNot(BeNil())
+				text, ok := annotation.StringValue()
+				Expect(ok).To(BeTrue())
+				Expect(text).To(Equal("Multi AZ"))
+				found = true
+			}
+		}
+		Expect(found).To(BeTrue())
+	})
+
+	It("Adds the dry run parameter to the methods that modify resources", func() {
+		model, err := Load("../../tests/model")
+		Expect(err).ToNot(HaveOccurred())
+		service := model.FindService(names.ParseUsingSeparator("clusters_mgmt", "_"))
+		version := service.FindVersion(names.ParseUsingCase("V1"))
+		resource := version.FindResource(names.ParseUsingCase("Cluster"))
+		dryRun := names.ParseUsingCase("DryRun")
+		remove := resource.FindMethod(names.ParseUsingCase("Delete"))
+		Expect(remove.GetParameter(dryRun)).ToNot(BeNil())
+		update := resource.FindMethod(names.ParseUsingCase("Update"))
+		Expect(update.GetParameter(dryRun)).ToNot(BeNil())
+		get := resource.FindMethod(names.ParseUsingCase("Get"))
+		Expect(get.GetParameter(dryRun)).To(BeNil())
+		collection := version.FindResource(names.ParseUsingCase("Clusters"))
+		add := collection.FindMethod(names.ParseUsingCase("Add"))
+		Expect(add.GetParameter(dryRun)).ToNot(BeNil())
+	})
+
+	It("Rejects request examples that aren't valid JSON", func() {
+		tmp, err := ioutil.TempDir("", "loader-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		dir := filepath.Join(tmp, "my_service", "v1")
+		err = os.MkdirAll(dir, 0755)
+		Expect(err).ToNot(HaveOccurred())
+		err = ioutil.WriteFile(filepath.Join(dir, "root_resource.model"), []byte(`
+			resource Root {
+				// Updates the object.
+				//
+				// Example request:
+				//
+				//   {
+				//     "name": "myobject"
+				method Update {
+					in out Body MyObject
+				}
+			}
+		`), 0644)
+		Expect(err).ToNot(HaveOccurred())
+		err = ioutil.WriteFile(filepath.Join(dir, "my_object_type.model"), []byte(`
+			struct MyObject {
+				Name string
+			}
+		`), 0644)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = Load(tmp)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -14,12 +14,13 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-// This file contains functions used to determine the case of strings.
+// This file contains functions used to determine and change the case of strings.
 
 package names
 
 import (
 	"unicode"
+	"unicode/utf8"
 )
 
 // isLower checks if all the runes of the given string are lower case.
@@ -41,3 +42,15 @@ func isUpper(s string) bool {
 	}
 	return true
 }
+
+// Capitalize converts the first character of the given text to upper case and leaves the rest
+// unchanged. It replaces the deprecated strings.Title function. The conversion uses the Unicode
+// default case mapping, no special casing, so the result doesn't depend on the language: for
+// example 'i' is always converted to 'I' and never to the Turkish dotted 'İ'.
+func Capitalize(text string) string {
+	first, size := utf8.DecodeRuneInString(text)
+	if size == 0 {
+		return text
+	}
+	return string(unicode.ToUpper(first)) + text[size:]
+}
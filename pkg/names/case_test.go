@@ -0,0 +1,52 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the case conversion functions.
+
+package names
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Case", func() {
+	DescribeTable("Capitalize",
+		func(text, expected string) {
+			Expect(Capitalize(text)).To(Equal(expected))
+		},
+		Entry("Empty", "", ""),
+		Entry("Lower case", "index", "Index"),
+		Entry("Keeps the rest", "iDs", "IDs"),
+		Entry("Non ASCII", "árbol", "Árbol"),
+		Entry("Turkish dotless i", "ıd", "Id"),
+		Entry("Turkish dotted I", "İd", "İd"),
+	)
+
+	It("Doesn't use Turkish special casing for 'i'", func() {
+		name := ParseUsingSeparator("instance_ids", "_")
+		Expect(name.Snake()).To(Equal("instance_ids"))
+		Expect(name.Camel()).To(Equal("InstanceIds"))
+	})
+
+	It("Doesn't use Turkish special casing for 'I'", func() {
+		name := ParseUsingCase("InstanceIDs")
+		Expect(name.String()).To(Equal("instance_IDs"))
+		Expect(name.Snake()).To(Equal("instance_ids"))
+		Expect(name.Camel()).To(Equal("InstanceIDs"))
+	})
+})
@@ -0,0 +1,196 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the inflector that converts names between their singular and plural forms.
+
+package names
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Inflector converts names between their singular and plural forms. Only the last word of a name
+// is changed, so that for example the plural of 'cluster_status' is 'cluster_statuses'. Don't
+// create instances directly, use the NewInflector function instead.
+type Inflector struct {
+	plurals   map[string]string
+	singulars map[string]string
+}
+
+// inflectionRule describes how to change the suffix of a word to convert it from one form to the
+// other.
+type inflectionRule struct {
+	suffix      string
+	replacement string
+}
+
+// NewInflector creates a new inflector that uses the default rules of the English language and
+// the default table of irregular words.
+func NewInflector() *Inflector {
+	inflector := &Inflector{
+		plurals:   map[string]string{},
+		singulars: map[string]string{},
+	}
+	for singular, plural := range irregularWords {
+		inflector.plurals[singular] = plural
+		inflector.singulars[plural] = singular
+	}
+	for _, word := range uncountableWords {
+		inflector.plurals[word] = word
+		inflector.singulars[word] = word
+	}
+	return inflector
+}
+
+// Override adds to the table of irregular words of the inflector the given singular and plural
+// forms. They take precedence over the default rules and the default table of irregular words.
+// The words are compared ignoring case.
+func (i *Inflector) Override(singular, plural string) error {
+	if singular == "" || plural == "" {
+		return fmt.Errorf(
+			"singular '%s' and plural '%s' must not be empty",
+			singular, plural,
+		)
+	}
+	singular = strings.ToLower(singular)
+	plural = strings.ToLower(plural)
+	i.plurals[singular] = plural
+	i.singulars[plural] = singular
+	return nil
+}
+
+// Plural returns the plural form of the given name, calculated with the default rules and the
+// default table of irregular words.
+func Plural(name *Name) *Name {
+	return defaultInflector.Plural(name)
+}
+
+// Singular returns the singular form of the given name, calculated with the default rules and the
+// default table of irregular words.
+func Singular(name *Name) *Name {
+	return defaultInflector.Singular(name)
+}
+
+// Plural returns the plural form of the given name.
+func (i *Inflector) Plural(name *Name) *Name {
+	return i.inflect(name, true, i.plurals, pluralRules)
+}
+
+// Singular returns the singular form of the given name.
+func (i *Inflector) Singular(name *Name) *Name {
+	return i.inflect(name, false, i.singulars, singularRules)
+}
+
+func (i *Inflector) inflect(name *Name, plural bool, table map[string]string,
+	rules []inflectionRule) *Name {
+	if name == nil || len(name.words) == 0 {
+		return name
+	}
+	words := name.Words()
+	last := words[len(words)-1]
+	if last.initialism {
+		words[len(words)-1] = i.inflectInitialism(last, plural)
+	} else {
+		words[len(words)-1] = NewWord(i.inflectWord(last.text, table, rules))
+	}
+	return NewName(words...)
+}
+
+// inflectInitialism converts initialisms, where the plural is always formed adding a lower case
+// 's', for example 'CPU' and 'CPUs'.
+func (i *Inflector) inflectInitialism(word *Word, plural bool) *Word {
+	text := word.text
+	if plural {
+		if !strings.HasSuffix(text, "s") {
+			text += "s"
+		}
+	} else {
+		text = strings.TrimSuffix(text, "s")
+	}
+	return NewInitialism(text)
+}
+
+func (i *Inflector) inflectWord(text string, table map[string]string,
+	rules []inflectionRule) string {
+	lower := strings.ToLower(text)
+	result, ok := table[lower]
+	if ok {
+		return result
+	}
+	for _, rule := range rules {
+		if strings.HasSuffix(lower, rule.suffix) {
+			return lower[0:len(lower)-len(rule.suffix)] + rule.replacement
+		}
+	}
+	return lower
+}
+
+// defaultInflector is the inflector used by the Plural and Singular functions.
+var defaultInflector = NewInflector()
+
+// pluralRules are the rules used to calculate plurals. They are checked in order, and the first
+// one that matches is used.
+var pluralRules = []inflectionRule{
+	{"ss", "sses"},
+	{"us", "uses"},
+	{"is", "es"},
+	{"sh", "shes"},
+	{"ch", "ches"},
+	{"x", "xes"},
+	{"z", "zes"},
+	{"ay", "ays"},
+	{"ey", "eys"},
+	{"oy", "oys"},
+	{"uy", "uys"},
+	{"y", "ies"},
+	{"s", "ses"},
+	{"", "s"},
+}
+
+// singularRules are the rules used to calculate singulars. They are checked in order, and the
+// first one that matches is used.
+var singularRules = []inflectionRule{
+	{"sses", "ss"},
+	{"uses", "us"},
+	{"shes", "sh"},
+	{"ches", "ch"},
+	{"xes", "x"},
+	{"zes", "z"},
+	{"iases", "ias"},
+	{"ies", "y"},
+	{"ss", "ss"},
+	{"us", "us"},
+	{"is", "is"},
+	{"s", ""},
+}
+
+// irregularWords contains the words whose plural can't be calculated with the rules.
+var irregularWords = map[string]string{
+	"analysis": "analyses",
+	"child":    "children",
+	"person":   "people",
+}
+
+// uncountableWords contains the words whose singular and plural are the same.
+var uncountableWords = []string{
+	"data",
+	"metadata",
+	"information",
+	"equipment",
+	"hardware",
+	"software",
+}
@@ -0,0 +1,76 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the inflector.
+
+package names
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Inflector", func() {
+	DescribeTable("Plural and singular",
+		func(singular, plural string) {
+			inflector := NewInflector()
+			name := ParseUsingCase(singular)
+			result := inflector.Plural(name)
+			Expect(result.String()).To(Equal(plural))
+			Expect(inflector.Singular(result).String()).To(Equal(name.String()))
+		},
+		Entry("Regular", "Cluster", "clusters"),
+		Entry("Ending in 'us'", "Status", "statuses"),
+		Entry("Ending in 'sh'", "Mesh", "meshes"),
+		Entry("Ending in 'ss'", "Address", "addresses"),
+		Entry("Ending in consonant and 'y'", "Proxy", "proxies"),
+		Entry("Ending in vowel and 'y'", "Key", "keys"),
+		Entry("Ending in 'x'", "Box", "boxes"),
+		Entry("Ending in 'as'", "Alias", "aliases"),
+		Entry("Ending in 'se'", "Release", "releases"),
+		Entry("Irregular", "Person", "people"),
+		Entry("Uncountable", "Metadata", "metadata"),
+		Entry("Multiple words", "ClusterStatus", "cluster_statuses"),
+		Entry("Initialism", "CPU", "CPUs"),
+	)
+
+	DescribeTable("Default inflector",
+		func(singular, plural string) {
+			name := ParseUsingCase(singular)
+			result := Plural(name)
+			Expect(result.String()).To(Equal(plural))
+			Expect(Singular(result).String()).To(Equal(name.String()))
+		},
+		Entry("Regular", "Cluster", "clusters"),
+		Entry("Ending in 'as'", "Alias", "aliases"),
+		Entry("Ending in 'us'", "Status", "statuses"),
+	)
+
+	It("Uses the overrides", func() {
+		inflector := NewInflector()
+		err := inflector.Override("Cactus", "Cacti")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inflector.Plural(ParseUsingCase("Cactus")).String()).To(Equal("cacti"))
+		Expect(inflector.Singular(ParseUsingCase("Cacti")).String()).To(Equal("cactus"))
+	})
+
+	It("Rejects empty overrides", func() {
+		inflector := NewInflector()
+		err := inflector.Override("Cactus", "")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -16,10 +16,6 @@ limitations under the License.
 
 package names
 
-import (
-	"strings"
-)
-
 // Words represents a single word.
 type Word struct {
 	text       string
@@ -59,7 +55,7 @@ func (w *Word) Capitalize() string {
 	if w.initialism {
 		return w.text
 	}
-	return strings.Title(w.text)
+	return Capitalize(w.text)
 }
 
 // Equals check if this word is equal to the given word.
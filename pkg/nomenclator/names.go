@@ -22,51 +22,89 @@ import (
 
 var (
 	// A:
+	Action  = names.ParseUsingCase("Action")
 	Adapt   = names.ParseUsingCase("Adapt")
 	Adapter = names.ParseUsingCase("Adapter")
 	Add     = names.ParseUsingCase("Add")
+	After   = names.ParseUsingCase("After")
+	Alias   = names.ParseUsingCase("Alias")
+	Aliases = names.Plural(Alias)
 
 	// B:
-	Body    = names.ParseUsingCase("Body")
-	Boolean = names.ParseUsingCase("Boolean")
-	Builder = names.ParseUsingCase("Builder")
+	Before     = names.ParseUsingCase("Before")
+	Benchmark  = names.ParseUsingCase("Benchmark")
+	Body       = names.ParseUsingCase("Body")
+	Boolean    = names.ParseUsingCase("Boolean")
+	Broker     = names.ParseUsingCase("Broker")
+	Builder    = names.ParseUsingCase("Builder")
+	BulkAdd    = names.ParseUsingCase("BulkAdd")
+	BulkDelete = names.ParseUsingCase("BulkDelete")
+	BulkResult = names.ParseUsingCase("BulkResult")
 
 	// C:
-	Client  = names.ParseUsingCase("Client")
-	Clients = names.ParseUsingCase("Clients")
+	CBOR        = names.ParseUsingCase("CBOR")
+	Check       = names.ParseUsingCase("Check")
+	Checks      = names.Plural(Check)
+	Client      = names.ParseUsingCase("Client")
+	Clients     = names.Plural(Client)
+	Conversion  = names.ParseUsingCase("Conversion")
+	Conversions = names.Plural(Conversion)
+	Convert     = names.ParseUsingCase("Convert")
+	Create      = names.ParseUsingCase("Create")
+	Created     = names.ParseUsingCase("Created")
+	Current     = names.ParseUsingCase("Current")
 
 	// D:
-	Data     = names.ParseUsingCase("Data")
-	Date     = names.ParseUsingCase("Date")
-	Delete   = names.ParseUsingCase("Delete")
-	Dispatch = names.ParseUsingCase("Dispatch")
+	Data        = names.ParseUsingCase("Data")
+	Date        = names.ParseUsingCase("Date")
+	Decode      = names.ParseUsingCase("Decode")
+	Delete      = names.ParseUsingCase("Delete")
+	Deleted     = names.ParseUsingCase("Deleted")
+	Descriptor  = names.ParseUsingCase("Descriptor")
+	Descriptors = names.Plural(Descriptor)
+	Dispatch    = names.ParseUsingCase("Dispatch")
+	Dispatcher  = names.ParseUsingCase("Dispatcher")
+	DryRun      = names.ParseUsingCase("DryRun")
 
 	// E:
-	Error  = names.ParseUsingCase("Error")
-	Errors = names.ParseUsingCase("Errors")
+	Equal       = names.ParseUsingCase("Equal")
+	Error       = names.ParseUsingCase("Error")
+	Errors      = names.Plural(Error)
+	Event       = names.ParseUsingCase("Event")
+	EventAction = names.ParseUsingCase("EventAction")
+	Expand      = names.ParseUsingCase("Expand")
 
 	// F:
+	Fetch = names.ParseUsingCase("Fetch")
 	Float = names.ParseUsingCase("Float")
+	Fuzz  = names.ParseUsingCase("Fuzz")
 
 	// G:
-	Get = names.ParseUsingCase("Get")
+	GeneratedFrom = names.ParseUsingCase("GeneratedFrom")
+	Generics      = names.ParseUsingCase("Generics")
+	Get           = names.ParseUsingCase("Get")
 
 	// H:
 	HREF    = names.ParseUsingCase("HREF")
+	Handle  = names.ParseUsingCase("Handle")
 	Handler = names.ParseUsingCase("Handler")
-	Helpers = names.ParseUsingCase("Helpers")
+	Helper  = names.ParseUsingCase("Helper")
+	Helpers = names.Plural(Helper)
 
 	// I:
-	ID      = names.ParseUsingCase("ID")
-	Index   = names.ParseUsingCase("Index")
-	Integer = names.ParseUsingCase("Integer")
-	Items   = names.ParseUsingCase("Items")
+	ID        = names.ParseUsingCase("ID")
+	Index     = names.ParseUsingCase("Index")
+	Integer   = names.ParseUsingCase("Integer")
+	Interface = names.ParseUsingCase("Interface")
+	Item      = names.ParseUsingCase("Item")
+	Items     = names.Plural(Item)
 
 	// J:
 	JSON = names.ParseUsingCase("JSON")
 
 	// K:
-	Kind = names.ParseUsingCase("Kind")
+	Kind  = names.ParseUsingCase("Kind")
+	Kinds = names.Plural(Kind)
 
 	// L:
 	Link = names.ParseUsingCase("Link")
@@ -83,38 +121,58 @@ var (
 	New = names.ParseUsingCase("New")
 
 	// P:
-	Page  = names.ParseUsingCase("Page")
-	Parse = names.ParseUsingCase("Parse")
-	Post  = names.ParseUsingCase("Post")
-	Poll  = names.ParseUsingCase("Poll")
+	Page      = names.ParseUsingCase("Page")
+	Parse     = names.ParseUsingCase("Parse")
+	Pattern   = names.ParseUsingCase("Pattern")
+	Ping      = names.ParseUsingCase("Ping")
+	Poll      = names.ParseUsingCase("Poll")
+	Post      = names.ParseUsingCase("Post")
+	Publish   = names.ParseUsingCase("Publish")
+	Publisher = names.ParseUsingCase("Publisher")
 
 	// R:
-	Read     = names.ParseUsingCase("Read")
-	Reader   = names.ParseUsingCase("Reader")
-	Readers  = names.ParseUsingCase("Readers")
-	Request  = names.ParseUsingCase("Request")
-	Resource = names.ParseUsingCase("Resource")
-	Response = names.ParseUsingCase("Response")
-	Root     = names.ParseUsingCase("Root")
+	Read      = names.ParseUsingCase("Read")
+	Reader    = names.ParseUsingCase("Reader")
+	Readers   = names.Plural(Reader)
+	Reason    = names.ParseUsingCase("Reason")
+	Request   = names.ParseUsingCase("Request")
+	Resource  = names.ParseUsingCase("Resource")
+	Response  = names.ParseUsingCase("Response")
+	Result    = names.ParseUsingCase("Result")
+	Results   = names.Plural(Result)
+	Root      = names.ParseUsingCase("Root")
+	RoundTrip = names.ParseUsingCase("RoundTrip")
 
 	// S:
-	Server  = names.ParseUsingCase("Server")
-	Servers = names.ParseUsingCase("Servers")
-	Service = names.ParseUsingCase("Service")
-	Set     = names.ParseUsingCase("Set")
-	Size    = names.ParseUsingCase("Size")
-	Spec    = names.ParseUsingCase("Spec")
-	Stream  = names.ParseUsingCase("Stream")
-	String  = names.ParseUsingCase("String")
+	Server     = names.ParseUsingCase("Server")
+	Servers    = names.Plural(Server)
+	Service    = names.ParseUsingCase("Service")
+	Set        = names.ParseUsingCase("Set")
+	Size       = names.ParseUsingCase("Size")
+	Spec       = names.ParseUsingCase("Spec")
+	Status     = names.ParseUsingCase("Status")
+	Stream     = names.ParseUsingCase("Stream")
+	String     = names.ParseUsingCase("String")
+	Subscriber = names.ParseUsingCase("Subscriber")
+	Summarize  = names.ParseUsingCase("Summarize")
 
 	// T:
-	Total = names.ParseUsingCase("Total")
-	Type  = names.ParseUsingCase("Type")
+	Test      = names.ParseUsingCase("Test")
+	Time      = names.ParseUsingCase("Time")
+	Timestamp = names.ParseUsingCase("Timestamp")
+	To        = names.ParseUsingCase("To")
+	Total     = names.ParseUsingCase("Total")
+	Type      = names.ParseUsingCase("Type")
 
 	// U:
 	Unmarshal = names.ParseUsingCase("Unmarshal")
 	Unwrap    = names.ParseUsingCase("Unwrap")
 	Update    = names.ParseUsingCase("Update")
+	Updated   = names.ParseUsingCase("Updated")
+
+	// V:
+	Validator = names.ParseUsingCase("Validator")
+	Version   = names.ParseUsingCase("Version")
 
 	// W:
 	Wrap  = names.ParseUsingCase("Wrap")
@@ -30,6 +30,9 @@ import (
 // output stream. Don't create instances directly, use the NewReporter function instead.
 type Reporter struct {
 	errors int
+	files  []string
+	sizes  []int64
+	steps  []*step
 }
 
 // NewReporter createsa a new reporter.
@@ -128,6 +131,24 @@ func (r *Reporter) Errors() int {
 	return r.errors
 }
 
+// Written records that the given file has been generated, so that it can later be processed by
+// other tools, for example by the post generation hooks.
+func (r *Reporter) Written(file string) {
+	var size int64
+	info, err := os.Stat(file)
+	if err == nil {
+		size = info.Size()
+	}
+	r.files = append(r.files, file)
+	r.sizes = append(r.sizes, size)
+}
+
+// Files returns the names of the files that have been generated, in the order that they were
+// written.
+func (r *Reporter) Files() []string {
+	return r.files
+}
+
 // Message prefix using ANSI scape seequences to set colors:
 const (
 	infoPrefix  = "\033[0;32mI:\033[m "
@@ -0,0 +1,106 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the methods of the reporter that measure the time and the files generated by
+// each of the steps of a run, and print a summary at the end.
+
+package reporter
+
+import (
+	"fmt"
+	"time"
+)
+
+// step contains the name and measurements of one of the steps of a run.
+type step struct {
+	name     string
+	duration time.Duration
+	files    int
+	bytes    int64
+}
+
+// Begin reports that a step, for example running one of the generators, has started. The name
+// of the step is calculated from the given format and arguments, like in the Infof method. It
+// returns a function that should be called when the step finishes, to record the time that it
+// took and the files that it wrote. For example:
+//
+//	end := reporter.Begin("Running generator '%s'", name)
+//	err := generator.Run()
+//	end()
+func (r *Reporter) Begin(format string, args ...interface{}) func() {
+	name := r.printf(format, args)
+	r.Infof("%s", name)
+	start := time.Now()
+	first := len(r.files)
+	return func() {
+		current := &step{
+			name:     name,
+			duration: time.Since(start),
+			files:    len(r.files) - first,
+		}
+		for _, size := range r.sizes[first:] {
+			current.bytes += size
+		}
+		r.steps = append(r.steps, current)
+	}
+}
+
+// Summary prints the time taken by each of the steps that have finished, and the number and
+// size of the files that they wrote, followed by the totals.
+func (r *Reporter) Summary() {
+	if len(r.steps) == 0 {
+		return
+	}
+	total := &step{
+		name: "Total",
+	}
+	width := len(total.name)
+	for _, current := range r.steps {
+		if len(current.name) > width {
+			width = len(current.name)
+		}
+		total.duration += current.duration
+		total.files += current.files
+		total.bytes += current.bytes
+	}
+	rows := make([]*step, 0, len(r.steps)+1)
+	rows = append(rows, r.steps...)
+	rows = append(rows, total)
+	r.Infof("Summary:")
+	for _, current := range rows {
+		r.Infof(
+			"  %-*s %10s %6d files %10s",
+			width, current.name, current.duration.Round(time.Millisecond),
+			current.files, formatBytes(current.bytes),
+		)
+	}
+}
+
+// formatBytes converts the given number of bytes to a human readable string, for example 1.5 MiB.
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+	value := float64(bytes)
+	prefixes := "KMGTPE"
+	index := -1
+	for value >= unit && index < len(prefixes)-1 {
+		value /= unit
+		index++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, prefixes[index])
+}
@@ -0,0 +1,70 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the summary of the steps of a run.
+
+package reporter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = ginkgo.Describe("Summary", func() {
+	ginkgo.It("Records the files written by each step", func() {
+		tmp, err := ioutil.TempDir("", "reporter-*")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		reporter := NewReporter()
+		write := func(name string, size int) {
+			file := filepath.Join(tmp, name)
+			err := ioutil.WriteFile(file, make([]byte, size), 0644)
+			Expect(err).ToNot(HaveOccurred())
+			reporter.Written(file)
+		}
+
+		end := reporter.Begin("First")
+		write("a.go", 10)
+		write("b.go", 20)
+		end()
+		end = reporter.Begin("Second")
+		write("c.go", 30)
+		end()
+
+		Expect(reporter.Files()).To(HaveLen(3))
+		Expect(reporter.steps).To(HaveLen(2))
+		Expect(reporter.steps[0].name).To(Equal("First"))
+		Expect(reporter.steps[0].files).To(Equal(2))
+		Expect(reporter.steps[0].bytes).To(BeNumerically("==", 30))
+		Expect(reporter.steps[1].name).To(Equal("Second"))
+		Expect(reporter.steps[1].files).To(Equal(1))
+		Expect(reporter.steps[1].bytes).To(BeNumerically("==", 30))
+	})
+
+	DescribeTable("Formats sizes",
+		func(bytes int64, expected string) {
+			Expect(formatBytes(bytes)).To(Equal(expected))
+		},
+		Entry("Bytes", int64(512), "512 B"),
+		Entry("Kibibytes", int64(1536), "1.5 KiB"),
+		Entry("Mebibytes", int64(3*1024*1024), "3.0 MiB"),
+	)
+})
@@ -0,0 +1,82 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains the generic list interface and the functions that work with it.
+
+package runtime
+
+// List is the interface implemented by all the generated list types. For example, the
+// ClusterList type implements List[*Cluster].
+type List[T any] interface {
+	// Len returns the length of the list.
+	Len() int
+
+	// Empty returns true if the list is empty.
+	Empty() bool
+
+	// Get returns the item of the list with the given index, or the zero value of the type
+	// if there is no item with that index.
+	Get(i int) T
+
+	// Slice returns a copy of the items of the list.
+	Slice() []T
+
+	// Each runs the given function for each item of the list, in order, till the function
+	// returns false.
+	Each(f func(item T) bool)
+
+	// Range runs the given function for each index and item of the list, in order, till
+	// the function returns false.
+	Range(f func(index int, item T) bool)
+}
+
+// Map returns a slice containing the results of applying the given function to each item of
+// the list.
+func Map[T, R any](list List[T], f func(item T) R) []R {
+	result := make([]R, 0, list.Len())
+	list.Each(func(item T) bool {
+		result = append(result, f(item))
+		return true
+	})
+	return result
+}
+
+// Filter returns a slice containing the items of the list for which the given function
+// returns true.
+func Filter[T any](list List[T], f func(item T) bool) []T {
+	result := make([]T, 0, list.Len())
+	list.Each(func(item T) bool {
+		if f(item) {
+			result = append(result, item)
+		}
+		return true
+	})
+	return result
+}
+
+// Reduce combines the items of the list using the given function, starting with the given
+// initial value.
+func Reduce[T, R any](list List[T], initial R, f func(result R, item T) R) R {
+	result := initial
+	list.Each(func(item T) bool {
+		result = f(result, item)
+		return true
+	})
+	return result
+}
@@ -0,0 +1,97 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the generic list functions.
+
+package runtime
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// intList is a simple implementation of the list interface used by the tests.
+type intList []int
+
+func (l intList) Len() int {
+	return len(l)
+}
+
+func (l intList) Empty() bool {
+	return len(l) == 0
+}
+
+func (l intList) Get(i int) int {
+	if i < 0 || i >= len(l) {
+		return 0
+	}
+	return l[i]
+}
+
+func (l intList) Slice() []int {
+	result := make([]int, len(l))
+	copy(result, l)
+	return result
+}
+
+func (l intList) Each(f func(item int) bool) {
+	for _, item := range l {
+		if !f(item) {
+			break
+		}
+	}
+}
+
+func (l intList) Range(f func(index int, item int) bool) {
+	for index, item := range l {
+		if !f(index, item) {
+			break
+		}
+	}
+}
+
+var _ = Describe("List", func() {
+	It("Maps items", func() {
+		result := Map[int, string](intList{1, 2, 3}, func(item int) string {
+			return string(rune('a' + item - 1))
+		})
+		Expect(result).To(Equal([]string{"a", "b", "c"}))
+	})
+
+	It("Maps empty list", func() {
+		result := Map[int, int](intList{}, func(item int) int {
+			return item
+		})
+		Expect(result).To(BeEmpty())
+	})
+
+	It("Filters items", func() {
+		result := Filter[int](intList{1, 2, 3, 4}, func(item int) bool {
+			return item%2 == 0
+		})
+		Expect(result).To(Equal([]int{2, 4}))
+	})
+
+	It("Reduces items", func() {
+		result := Reduce[int, int](intList{1, 2, 3}, 10, func(result, item int) int {
+			return result + item
+		})
+		Expect(result).To(Equal(16))
+	})
+})
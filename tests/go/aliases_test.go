@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the packages containing the prefixed aliases of the types.
+
+package tests
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	// Both versions of the clusters management service define the 'Cluster' type, so dot
+	// importing the aliases packages is the only way to dot import both without collisions.
+	. "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/accountsmgmt/v1/aliases"
+	. "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1/aliases"
+	. "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v2/aliases"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+)
+
+var _ = Describe("Aliases", func() {
+	It("Can use types with the same name from different versions", func() {
+		v1, err := NewClustersMgmtV1Cluster().
+			Name("first").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		v2, err := NewClustersMgmtV2Cluster().
+			Name("second").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(v1.Name()).To(Equal("first"))
+		Expect(v2.Name()).To(Equal("second"))
+	})
+
+	It("Aliases are identical to the original types", func() {
+		var object *ClustersMgmtV1Cluster
+		object, err := cmv1.NewCluster().
+			Name("mycluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		var original *cmv1.Cluster = object
+		Expect(original.Name()).To(Equal("mycluster"))
+	})
+
+	It("Uses the explicit prefix", func() {
+		object, err := NewAMRegistryAuth().
+			Username("myuser").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(object.Username()).To(Equal("myuser"))
+	})
+
+	It("Aliases the values of enumerated types", func() {
+		var state ClustersMgmtV1ClusterState = ClustersMgmtV1ClusterStateInstalling
+		Expect(state).To(Equal(cmv1.ClusterStateInstalling))
+	})
+
+	It("Aliases the marshalling functions", func() {
+		object, err := NewClustersMgmtV1Cluster().
+			Name("mycluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		err = MarshalClustersMgmtV1Cluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := UnmarshalClustersMgmtV1Cluster(buffer.Bytes())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Name()).To(Equal("mycluster"))
+	})
+})
@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the circuit breaker.
+
+package tests
+
+import (
+	"net/http"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("Circuit breaker", func() {
+	var server *Server
+	var transport http.RoundTripper
+
+	BeforeEach(func() {
+		server = NewServer()
+		transport = NewTransport(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Can't be created without a transport", func() {
+		_, err := helpers.NewCircuitBreaker(nil).Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Can't be created with zero threshold", func() {
+		_, err := helpers.NewCircuitBreaker(transport).
+			Threshold(0).
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Opens after the threshold is reached", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusInternalServerError, failure),
+			RespondWith(http.StatusInternalServerError, failure),
+		)
+
+		// Create the breaker:
+		breaker, err := helpers.NewCircuitBreaker(transport).
+			Threshold(2).
+			Timeout(1 * time.Hour).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the requests:
+		client := cmv1.NewClusterClient(breaker, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().Send()
+		Expect(err).To(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).To(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).To(Equal(helpers.ErrCircuitOpen))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Doesn't open if failures aren't consecutive", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusInternalServerError, failure),
+			RespondWith(http.StatusOK, `{}`),
+			RespondWith(http.StatusInternalServerError, failure),
+		)
+
+		// Create the breaker:
+		breaker, err := helpers.NewCircuitBreaker(transport).
+			Threshold(2).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the requests:
+		client := cmv1.NewClusterClient(breaker, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().Send()
+		Expect(err).To(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).ToNot(Equal(helpers.ErrCircuitOpen))
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Closes after a successful probe", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusInternalServerError, failure),
+			RespondWith(http.StatusOK, `{}`),
+			RespondWith(http.StatusOK, `{}`),
+		)
+
+		// Create the breaker:
+		breaker, err := helpers.NewCircuitBreaker(transport).
+			Threshold(1).
+			Timeout(10 * time.Millisecond).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Open the circuit:
+		client := cmv1.NewClusterClient(breaker, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().Send()
+		Expect(err).To(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).To(Equal(helpers.ErrCircuitOpen))
+
+		// Wait till the probe is allowed, and then verify that the circuit is closed. Requests
+		// rejected while the circuit is open don't reach the server, so this doesn't change
+		// the number of requests received:
+		Eventually(func() error {
+			_, err := client.Get().Send()
+			return err
+		}, 1*time.Second, 5*time.Millisecond).ShouldNot(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+	})
+
+	It("Uses separate circuits for separate resources", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusInternalServerError, failure),
+			RespondWith(http.StatusOK, `{}`),
+		)
+
+		// Create the breaker:
+		breaker, err := helpers.NewCircuitBreaker(transport).
+			Threshold(1).
+			Timeout(1 * time.Hour).
+			Key(helpers.BreakerPathKey).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Open the circuit of the clusters:
+		client := cmv1.NewClient(breaker, "/api/clusters_mgmt/v1", "/api/clusters_mgmt/v1")
+		_, err = client.Clusters().Cluster("123").Get().Send()
+		Expect(err).To(HaveOccurred())
+		_, err = client.Clusters().Cluster("123").Get().Send()
+		Expect(err).To(Equal(helpers.ErrCircuitOpen))
+
+		// Check that the circuit of the groups is still closed:
+		_, err = client.Clusters().Cluster("123").Groups().List().Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+// failure is the body of the error responses used by the circuit breaker tests.
+const failure = `{
+	"kind": "Error",
+	"id": "500",
+	"href": "/api/clusters_mgmt/v1/errors/500",
+	"code": "CLUSTERS-MGMT-500",
+	"reason": "Internal server error"
+}`
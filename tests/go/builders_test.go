@@ -19,11 +19,15 @@ limitations under the License.
 package tests
 
 import (
+	"errors"
+	"strings"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	amv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/accountsmgmt/v1"
 	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
 )
 
 var _ = Describe("Builder", func() {
@@ -202,6 +206,14 @@ var _ = Describe("Builder", func() {
 			Expect(second.Username()).To(Equal("youruser"))
 			Expect(second.Email()).To(Equal("yourmail"))
 		})
+
+		It("Can set attribute of enabled experimental feature", func() {
+			object, err := cmv1.NewGroup().
+				Description("mydescription").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Description()).To(Equal("mydescription"))
+		})
 	})
 
 	Describe("Copy", func() {
@@ -248,6 +260,22 @@ var _ = Describe("Builder", func() {
 			Expect(properties).To(BeEmpty())
 		})
 
+		It("Preserves empty map of strings when copying", func() {
+			original, err := cmv1.NewCluster().
+				Properties(map[string]string{}).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			replica, err := cmv1.NewCluster().
+				Copy(original).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			properties, ok := replica.GetProperties()
+			Expect(ok).To(BeTrue())
+			Expect(properties).ToNot(BeNil())
+			Expect(properties).To(BeEmpty())
+			Expect(replica.Unset()).To(BeFalse())
+		})
+
 		It("Copies map of strings with one value", func() {
 			original, err := cmv1.NewCluster().
 				Properties(map[string]string{
@@ -344,4 +372,120 @@ var _ = Describe("Builder", func() {
 			Expect(second.Email()).To(Equal("yourmail"))
 		})
 	})
+
+	Describe("Validation", func() {
+		It("Accepts values that satisfy the constraints", func() {
+			object, err := cmv1.NewCluster().
+				Name("my-cluster").
+				Nodes(cmv1.NewClusterNodes().Compute(1000)).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object).ToNot(BeNil())
+		})
+
+		It("Rejects string that doesn't match the pattern", func() {
+			object, err := cmv1.NewCluster().
+				Name("My Cluster").
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(object).To(BeNil())
+			errs, ok := err.(helpers.ValidationErrors)
+			Expect(ok).To(BeTrue())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("name"))
+		})
+
+		It("Rejects string that is too long", func() {
+			_, err := cmv1.NewCluster().
+				Name(strings.Repeat("a", 65)).
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("'name'"))
+		})
+
+		It("Rejects number below the minimum", func() {
+			_, err := cmv1.NewClusterNodes().
+				Compute(-1).
+				Build()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("'compute'"))
+		})
+
+		It("Reports the path of nested attributes", func() {
+			_, err := cmv1.NewCluster().
+				Name("My Cluster").
+				Nodes(cmv1.NewClusterNodes().Compute(1001)).
+				Build()
+			Expect(err).To(HaveOccurred())
+			errs, ok := err.(helpers.ValidationErrors)
+			Expect(ok).To(BeTrue())
+			Expect(errs).To(HaveLen(2))
+			Expect(errs[0].Field).To(Equal("name"))
+			Expect(errs[1].Field).To(Equal("nodes.compute"))
+		})
+	})
+
+	Describe("Validator", func() {
+		AfterEach(func() {
+			cmv1.SetClusterValidator(nil)
+			cmv1.SetClusterNodesValidator(nil)
+		})
+
+		It("Calls the registered validator", func() {
+			cmv1.SetClusterValidator(func(object *cmv1.Cluster) error {
+				if object.MultiAZ() && object.Nodes().Compute() < 3 {
+					return errors.New("multi AZ clusters need at least 3 compute nodes")
+				}
+				return nil
+			})
+			_, err := cmv1.NewCluster().
+				MultiAZ(true).
+				Nodes(cmv1.NewClusterNodes().Compute(2)).
+				Build()
+			Expect(err).To(HaveOccurred())
+			errs, ok := err.(helpers.ValidationErrors)
+			Expect(ok).To(BeTrue())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(BeEmpty())
+			Expect(errs[0].Reason).To(Equal("multi AZ clusters need at least 3 compute nodes"))
+			object, err := cmv1.NewCluster().
+				MultiAZ(true).
+				Nodes(cmv1.NewClusterNodes().Compute(3)).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object).ToNot(BeNil())
+		})
+
+		It("Adds the path of nested objects", func() {
+			cmv1.SetClusterNodesValidator(func(object *cmv1.ClusterNodes) error {
+				errs := helpers.ValidationErrors{}
+				if object.Master() > object.Compute() {
+					errs.Add("master", "must be less than or equal to compute")
+				}
+				if len(errs) > 0 {
+					return errs
+				}
+				return nil
+			})
+			_, err := cmv1.NewCluster().
+				Nodes(cmv1.NewClusterNodes().Master(3).Compute(1)).
+				Build()
+			Expect(err).To(HaveOccurred())
+			errs, ok := err.(helpers.ValidationErrors)
+			Expect(ok).To(BeTrue())
+			Expect(errs).To(HaveLen(1))
+			Expect(errs[0].Field).To(Equal("nodes.master"))
+		})
+
+		It("Is used by the Validate method", func() {
+			cmv1.SetClusterValidator(func(object *cmv1.Cluster) error {
+				return errors.New("invalid cluster")
+			})
+			object, err := cmv1.UnmarshalCluster(`{}`)
+			Expect(err).ToNot(HaveOccurred())
+			err = object.Validate()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid cluster"))
+		})
+	})
 })
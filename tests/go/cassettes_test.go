@@ -0,0 +1,444 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the cassette recorder and replayer.
+
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("Cassette", func() {
+	Describe("Recorder", func() {
+		var server *Server
+		var transport http.RoundTripper
+		var tmp string
+
+		BeforeEach(func() {
+			var err error
+			server = NewServer()
+			transport = NewTransport(server)
+			tmp, err = ioutil.TempDir("", "cassette-*")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			server.Close()
+			err := os.RemoveAll(tmp)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Can't be created without a transport", func() {
+			_, err := helpers.NewCassetteRecorder(nil).Build()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Records requests and responses", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(
+					http.StatusOK,
+					`{"kind": "Cluster", "id": "123", "name": "mycluster"}`,
+					http.Header{
+						"Content-Type": []string{"application/json"},
+						"Set-Cookie":   []string{"session=secret"},
+					},
+				),
+			)
+
+			// Create the recorder:
+			recorder, err := helpers.NewCassetteRecorder(transport).
+				Redact("X-Secret").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			client := cmv1.NewClusterClient(recorder, "/api/clusters_mgmt/v1/clusters/123", "")
+			response, err := client.Get().
+				Header("Authorization", "Bearer mytoken").
+				Header("X-Secret", "mysecret").
+				Parameter("fields", "name").
+				Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body().Name()).To(Equal("mycluster"))
+
+			// Save and load the cassette:
+			file := filepath.Join(tmp, "cassette.json")
+			err = recorder.Save(file)
+			Expect(err).ToNot(HaveOccurred())
+			cassette, err := helpers.LoadCassette(file)
+			Expect(err).ToNot(HaveOccurred())
+
+			// Verify the cassette:
+			Expect(cassette.Interactions).To(HaveLen(1))
+			interaction := cassette.Interactions[0]
+			Expect(interaction.Request.Method).To(Equal(http.MethodGet))
+			Expect(interaction.Request.Path).To(Equal("/api/clusters_mgmt/v1/clusters/123"))
+			Expect(interaction.Request.Query).To(Equal("fields=name"))
+			Expect(interaction.Request.Header.Get("Authorization")).To(Equal(helpers.Redacted))
+			Expect(interaction.Request.Header.Get("X-Secret")).To(Equal(helpers.Redacted))
+			Expect(interaction.Response.Status).To(Equal(http.StatusOK))
+			Expect(interaction.Response.Header.Get("Set-Cookie")).To(Equal(helpers.Redacted))
+			Expect(interaction.Response.Body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"id": "123",
+				"name": "mycluster"
+			}`))
+		})
+
+		It("Doesn't replace the body of the request", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyBody([]byte(`{"name":"mycluster"}`)),
+					RespondWith(http.StatusOK, `{}`),
+				),
+			)
+
+			// Create the recorder:
+			recorder, err := helpers.NewCassetteRecorder(transport).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			body := ioutil.NopCloser(strings.NewReader(`{"name":"mycluster"}`))
+			request, err := http.NewRequest(http.MethodPost, "/clusters", body)
+			Expect(err).ToNot(HaveOccurred())
+			response, err := recorder.RoundTrip(request)
+			Expect(err).ToNot(HaveOccurred())
+			defer response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			Expect(request.Body).To(BeIdenticalTo(body))
+		})
+
+		It("Redacts credentials in bodies", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(
+					http.StatusOK,
+					`{"access_token": "myaccess", "refresh_token": "myrefresh", "expires_in": 300}`,
+					http.Header{
+						"Content-Type": []string{"application/json"},
+					},
+				),
+			)
+
+			// Create the recorder:
+			recorder, err := helpers.NewCassetteRecorder(transport).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			form := url.Values{}
+			form.Set("grant_type", "password")
+			form.Set("username", "myuser")
+			form.Set("password", "mypassword")
+			client := &http.Client{
+				Transport: recorder,
+			}
+			response, err := client.PostForm(server.URL()+"/token", form)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := ioutil.ReadAll(response.Body)
+			Expect(err).ToNot(HaveOccurred())
+			err = response.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Verify that the client got the real response:
+			Expect(string(body)).To(ContainSubstring("myaccess"))
+
+			// Save the cassette and verify that it doesn't contain the credentials:
+			file := filepath.Join(tmp, "cassette.json")
+			err = recorder.Save(file)
+			Expect(err).ToNot(HaveOccurred())
+			data, err := ioutil.ReadFile(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(data)).ToNot(ContainSubstring("mypassword"))
+			Expect(string(data)).ToNot(ContainSubstring("myaccess"))
+			Expect(string(data)).ToNot(ContainSubstring("myrefresh"))
+			cassette, err := helpers.LoadCassette(file)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cassette.Interactions).To(HaveLen(1))
+			interaction := cassette.Interactions[0]
+			values, err := url.ParseQuery(interaction.Request.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values.Get("username")).To(Equal("myuser"))
+			Expect(values.Get("password")).To(Equal(helpers.Redacted))
+			Expect(interaction.Response.Body).To(MatchJSON(`{
+				"access_token": "REDACTED",
+				"refresh_token": "REDACTED",
+				"expires_in": 300
+			}`))
+
+			// Verify that the redacted request can be replayed:
+			replayer, err := helpers.NewCassetteReplayer(cassette).Build()
+			Expect(err).ToNot(HaveOccurred())
+			client = &http.Client{
+				Transport: replayer,
+			}
+			response, err = client.PostForm(server.URL()+"/token", form)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			err = response.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Redacts credentials in the query", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(http.StatusOK, `{}`),
+			)
+
+			// Create the recorder:
+			recorder, err := helpers.NewCassetteRecorder(transport).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			client := &http.Client{
+				Transport: recorder,
+			}
+			response, err := client.Get(server.URL() + "/clusters?access_token=mytoken&page=1")
+			Expect(err).ToNot(HaveOccurred())
+			err = response.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Verify that the cassette doesn't contain the token:
+			cassette := recorder.Cassette()
+			Expect(cassette.Interactions).To(HaveLen(1))
+			values, err := url.ParseQuery(cassette.Interactions[0].Request.Query)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values.Get("access_token")).To(Equal(helpers.Redacted))
+			Expect(values.Get("page")).To(Equal("1"))
+
+			// Verify that the redacted request can be replayed:
+			replayer, err := helpers.NewCassetteReplayer(cassette).Build()
+			Expect(err).ToNot(HaveOccurred())
+			client = &http.Client{
+				Transport: replayer,
+			}
+			response, err = client.Get(server.URL() + "/clusters?access_token=yourtoken&page=1")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+			err = response.Body.Close()
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("Redacts the attributes marked as sensitive in the model", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(http.StatusCreated, `{}`),
+			)
+
+			// Create the recorder:
+			recorder, err := helpers.NewCassetteRecorder(transport).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			provider, err := cmv1.NewIdentityProvider().
+				LDAP(cmv1.NewLDAPIdentityProvider().
+					BindDN("cn=admin").
+					BindPassword("mypassword"),
+				).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			client := cmv1.NewIdentityProvidersClient(
+				recorder,
+				"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				"",
+			)
+			_, err = client.Add().Body(provider).Send()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Verify that the cassette doesn't contain the password:
+			cassette := recorder.Cassette()
+			Expect(cassette.Interactions).To(HaveLen(1))
+			body := cassette.Interactions[0].Request.Body
+			Expect(body).To(ContainSubstring(`"bind_dn":"cn=admin"`))
+			Expect(body).To(ContainSubstring(`"bind_password":"REDACTED"`))
+			Expect(body).ToNot(ContainSubstring("mypassword"))
+		})
+
+		It("Closes the response body if it can't be read", func() {
+			// Create a recorder that wraps a transport returning a body that can't be read:
+			body := &BrokenBody{}
+			recorder, err := helpers.NewCassetteRecorder(
+				TransportFunc(func(request *http.Request) (*http.Response, error) {
+					response := &http.Response{
+						StatusCode: http.StatusOK,
+						Header:     http.Header{},
+						Body:       body,
+					}
+					return response, nil
+				}),
+			).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			request, err := http.NewRequest(http.MethodGet, "/clusters", nil)
+			Expect(err).ToNot(HaveOccurred())
+			response, err := recorder.RoundTrip(request)
+			Expect(err).To(HaveOccurred())
+			Expect(response).To(BeNil())
+			Expect(body.Closed).To(BeTrue())
+			Expect(recorder.Cassette().Interactions).To(BeEmpty())
+		})
+
+		It("Applies the filters", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(http.StatusOK, `{"kind": "Cluster", "id": "123"}`),
+			)
+
+			// Create the recorder:
+			recorder, err := helpers.NewCassetteRecorder(transport).
+				Filter(func(interaction *helpers.Interaction) {
+					interaction.Response.Body = strings.ReplaceAll(
+						interaction.Response.Body, "123", "456",
+					)
+				}).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			client := cmv1.NewClusterClient(recorder, "/api/clusters_mgmt/v1/clusters/123", "")
+			response, err := client.Get().Send()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Verify that the client got the real response and the cassette the filtered one:
+			Expect(response.Body().ID()).To(Equal("123"))
+			cassette := recorder.Cassette()
+			Expect(cassette.Interactions).To(HaveLen(1))
+			Expect(cassette.Interactions[0].Response.Body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"id": "456"
+			}`))
+		})
+	})
+
+	Describe("Replayer", func() {
+		It("Can't be created without a cassette", func() {
+			_, err := helpers.NewCassetteReplayer(nil).Build()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Returns the recorded responses in order", func() {
+			// Prepare the cassette:
+			cassette := &helpers.Cassette{
+				Interactions: []*helpers.Interaction{
+					{
+						Request: &helpers.InteractionRequest{
+							Method: http.MethodGet,
+							Path:   "/api/clusters_mgmt/v1/clusters/123",
+						},
+						Response: &helpers.InteractionResponse{
+							Status: http.StatusOK,
+							Body:   `{"kind": "Cluster", "id": "123", "state": "installing"}`,
+						},
+					},
+					{
+						Request: &helpers.InteractionRequest{
+							Method: http.MethodGet,
+							Path:   "/api/clusters_mgmt/v1/clusters/123",
+						},
+						Response: &helpers.InteractionResponse{
+							Status: http.StatusOK,
+							Body:   `{"kind": "Cluster", "id": "123", "state": "ready"}`,
+						},
+					},
+				},
+			}
+
+			// Create the replayer:
+			replayer, err := helpers.NewCassetteReplayer(cassette).Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replayer.Remaining()).To(Equal(2))
+
+			// Send the requests:
+			client := cmv1.NewClusterClient(replayer, "/api/clusters_mgmt/v1/clusters/123", "")
+			response, err := client.Get().Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body().State()).To(Equal(cmv1.ClusterStateInstalling))
+			response, err = client.Get().Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body().State()).To(Equal(cmv1.ClusterStateReady))
+			Expect(replayer.Remaining()).To(BeZero())
+
+			// There are no more recorded responses:
+			_, err = client.Get().Send()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("Matches bodies ignoring the format", func() {
+			// Prepare the cassette:
+			cassette := &helpers.Cassette{
+				Interactions: []*helpers.Interaction{
+					{
+						Request: &helpers.InteractionRequest{
+							Method: http.MethodPost,
+							Path:   "/api/clusters_mgmt/v1/clusters",
+							Body:   `{"name": "mycluster", "kind": "Cluster"}`,
+						},
+						Response: &helpers.InteractionResponse{
+							Status: http.StatusCreated,
+							Body:   `{"kind": "Cluster", "id": "123", "name": "mycluster"}`,
+						},
+					},
+				},
+			}
+
+			// Create the replayer:
+			replayer, err := helpers.NewCassetteReplayer(cassette).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send a request with a different body, which shouldn't match:
+			client := cmv1.NewClustersClient(replayer, "/api/clusters_mgmt/v1/clusters", "")
+			other, err := cmv1.NewCluster().Name("yourcluster").Build()
+			Expect(err).ToNot(HaveOccurred())
+			_, err = client.Add().Body(other).Send()
+			Expect(err).To(HaveOccurred())
+
+			// Send the request with the recorded body:
+			cluster, err := cmv1.NewCluster().Name("mycluster").Build()
+			Expect(err).ToNot(HaveOccurred())
+			response, err := client.Add().Body(cluster).Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Status()).To(Equal(http.StatusCreated))
+			Expect(response.Body().ID()).To(Equal("123"))
+		})
+
+		It("Doesn't send requests that don't match", func() {
+			// Create the replayer:
+			replayer, err := helpers.NewCassetteReplayer(&helpers.Cassette{}).Build()
+			Expect(err).ToNot(HaveOccurred())
+
+			// Send the request:
+			client := cmv1.NewClusterClient(replayer, "/api/clusters_mgmt/v1/clusters/123", "")
+			_, err = client.Get().Send()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("/api/clusters_mgmt/v1/clusters/123"))
+		})
+	})
+})
@@ -0,0 +1,295 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the CBOR encoding of types, requests and responses.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated"
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("CBOR", func() {
+	It("Can write and read object", func() {
+		date := time.Date(2019, time.July, 14, 15, 16, 17, 0, time.UTC)
+		original, err := cmv1.NewCluster().
+			ID("123").
+			Name("mycluster").
+			Managed(true).
+			State(cmv1.ClusterStateReady).
+			Properties(map[string]string{
+				"owner": "me",
+			}).
+			Nodes(cmv1.NewClusterNodes().
+				Compute(10),
+			).
+			ProbeTimestamp(date).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		err = cmv1.MarshalClusterCBOR(original, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := cmv1.UnmarshalClusterCBOR(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.ID()).To(Equal("123"))
+		Expect(result.Name()).To(Equal("mycluster"))
+		Expect(result.Managed()).To(BeTrue())
+		Expect(result.State()).To(Equal(cmv1.ClusterStateReady))
+		Expect(result.Properties()).To(Equal(map[string]string{
+			"owner": "me",
+		}))
+		Expect(result.Nodes().Compute()).To(Equal(10))
+		Expect(result.ProbeTimestamp()).To(Equal(date))
+	})
+
+	It("Can write and read interface attribute", func() {
+		original, err := cmv1.NewCluster().
+			Settings(map[string]interface{}{
+				"region": "us-east-1",
+				"zones":  []interface{}{"a", "b"},
+				"size":   3,
+				"public": true,
+				"extra":  nil,
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		err = cmv1.MarshalClusterCBOR(original, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		result, err := cmv1.UnmarshalClusterCBOR(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result.Settings()).To(Equal(map[string]interface{}{
+			"region": "us-east-1",
+			"zones":  []interface{}{"a", "b"},
+			"size":   int64(3),
+			"public": true,
+			"extra":  nil,
+		}))
+	})
+
+	It("Is smaller than JSON", func() {
+		object, err := cmv1.NewCluster().
+			Name("mycluster").
+			Nodes(cmv1.NewClusterNodes().
+				Infra(2).
+				Compute(10),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		jsonBuffer := &bytes.Buffer{}
+		err = cmv1.MarshalCluster(object, jsonBuffer)
+		Expect(err).ToNot(HaveOccurred())
+		cborBuffer := &bytes.Buffer{}
+		err = cmv1.MarshalClusterCBOR(object, cborBuffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cborBuffer.Len()).To(BeNumerically("<", jsonBuffer.Len()))
+	})
+
+	It("Fails if the data is truncated", func() {
+		object, err := cmv1.NewCluster().
+			Name("mycluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := &bytes.Buffer{}
+		err = cmv1.MarshalClusterCBOR(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		data := buffer.Bytes()
+		_, err = cmv1.UnmarshalClusterCBOR(data[0 : len(data)-2])
+		Expect(err).To(HaveOccurred())
+	})
+
+	DescribeTable(
+		"Rejects deeply nested data",
+		func(field string) {
+			// Prepare a map with one field containing a million nested arrays:
+			data := []byte{0xa1, 0x60 + byte(len(field))}
+			data = append(data, field...)
+			data = append(data, bytes.Repeat([]byte{0x81}, 1000000)...)
+			data = append(data, 0x00)
+
+			// Check that it fails without exhausting the stack:
+			_, err := cmv1.UnmarshalClusterCBOR(data)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("nested"))
+		},
+		Entry("Unknown field", "unknown"),
+		Entry("Interface attribute", "settings"),
+	)
+
+	It("Accepts nested data within the limit", func() {
+		data := []byte{0xa1, 0x67}
+		data = append(data, "unknown"...)
+		data = append(data, bytes.Repeat([]byte{0x81}, helpers.CBORMaxDepth-1)...)
+		data = append(data, 0x00)
+		_, err := cmv1.UnmarshalClusterCBOR(data)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Server", func() {
+		var (
+			server   *MyServer
+			adapter  *generated.Adapter
+			recorder *httptest.ResponseRecorder
+		)
+
+		BeforeEach(func() {
+			server = &MyServer{
+				clustersMgmt: &MyCMServer{
+					v1: &MyCMV1Server{
+						clusters: &MyClustersServer{
+							cluster: &MyClusterServer{},
+						},
+					},
+				},
+			}
+			server.clustersMgmt.v1.clusters.cluster.get = func(
+				ctx context.Context,
+				request *cmv1.ClusterGetServerRequest,
+				response *cmv1.ClusterGetServerResponse,
+			) error {
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				return nil
+			}
+			adapter = generated.NewAdapter(server)
+			recorder = httptest.NewRecorder()
+		})
+
+		It("Sends CBOR if the client accepts it", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept", "application/cbor")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal(helpers.CBORContentType))
+			body, err := cmv1.UnmarshalClusterCBOR(recorder.Body)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(body.Name()).To(Equal("mycluster"))
+		})
+
+		It("Sends JSON if the client doesn't accept CBOR", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept", "application/json")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"name": "mycluster"
+			}`))
+		})
+	})
+
+	Describe("Client", func() {
+		var server *Server
+		var transport http.RoundTripper
+
+		BeforeEach(func() {
+			server = NewServer()
+			transport = NewTransport(server)
+		})
+
+		AfterEach(func() {
+			server.Close()
+		})
+
+		It("Reads CBOR response", func() {
+			// Prepare the server:
+			object, err := cmv1.NewCluster().
+				ID("123").
+				Name("mycluster").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			buffer := &bytes.Buffer{}
+			err = cmv1.MarshalClusterCBOR(object, buffer)
+			Expect(err).ToNot(HaveOccurred())
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyHeaderKV("Accept", helpers.CBORContentType),
+					RespondWith(
+						http.StatusOK,
+						buffer.Bytes(),
+						http.Header{
+							"Content-Type": []string{
+								helpers.CBORContentType,
+							},
+						},
+					),
+				),
+			)
+
+			// Send the request:
+			client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+			response, err := client.Clusters().Cluster("123").Get().
+				Header("Accept", helpers.CBORContentType).
+				Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body().ID()).To(Equal("123"))
+			Expect(response.Body().Name()).To(Equal("mycluster"))
+		})
+
+		It("Reads JSON response", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(
+					http.StatusOK,
+					`{
+						"kind": "Cluster",
+						"id": "123",
+						"name": "mycluster"
+					}`,
+					http.Header{
+						"Content-Type": []string{
+							"application/json",
+						},
+					},
+				),
+			)
+
+			// Send the request:
+			client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+			response, err := client.Clusters().Cluster("123").Get().
+				Header("Accept", helpers.CBORContentType).
+				Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Body().Name()).To(Equal("mycluster"))
+		})
+	})
+})
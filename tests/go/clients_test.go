@@ -19,7 +19,15 @@ limitations under the License.
 package tests
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io/ioutil"
 	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -27,7 +35,9 @@ import (
 	. "github.com/onsi/gomega/ghttp"
 
 	amv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/accountsmgmt/v1"
+	cm "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt"
 	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
 )
 
 var _ = Describe("Client", func() {
@@ -107,7 +117,14 @@ var _ = Describe("Client", func() {
 
 	It("Can retrieve version metadata", func() {
 		server.AppendHandlers(RespondWith(http.StatusOK, `{
-			"server_version": "123"
+			"server_version": "123",
+			"build_timestamp": "2019-07-25T12:34:56Z",
+			"git_commit": "abcdef",
+			"generated_from": "model abcdef, metamodel 1.2.3",
+			"features": [
+				"a",
+				"b"
+			]
 		}`))
 		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
 		response, err := client.Get().Send()
@@ -116,6 +133,329 @@ var _ = Describe("Client", func() {
 		body := response.Body()
 		Expect(body).ToNot(BeNil())
 		Expect(body.ServerVersion()).To(Equal("123"))
+		Expect(body.BuildTimestamp()).To(Equal(time.Date(2019, 7, 25, 12, 34, 56, 0, time.UTC)))
+		Expect(body.GitCommit()).To(Equal("abcdef"))
+		Expect(body.GeneratedFrom()).To(Equal("model abcdef, metamodel 1.2.3"))
+		Expect(body.Features()).To(Equal([]string{"a", "b"}))
+		Expect(body.HasFeature("a")).To(BeTrue())
+		Expect(body.HasFeature("c")).To(BeFalse())
+	})
+
+	Describe("Link expansion", func() {
+		It("Expands link to object", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+					RespondWith(http.StatusOK, `{
+						"kind": "Cluster",
+						"id": "123",
+						"href": "/api/clusters_mgmt/v1/clusters/123",
+						"name": "mycluster"
+					}`),
+				),
+			)
+
+			// Expand the link:
+			link, err := cmv1.UnmarshalCluster(`{
+				"kind": "ClusterLink",
+				"id": "123",
+				"href": "/api/clusters_mgmt/v1/clusters/123"
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(link.Link()).To(BeTrue())
+			cluster, err := link.Expand(context.Background(), transport)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cluster.Link()).To(BeFalse())
+			Expect(cluster.ID()).To(Equal("123"))
+			Expect(cluster.Name()).To(Equal("mycluster"))
+		})
+
+		It("Returns object that isn't a link unchanged", func() {
+			cluster, err := cmv1.NewCluster().ID("123").Build()
+			Expect(err).ToNot(HaveOccurred())
+			result, err := cluster.Expand(context.Background(), transport)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(BeIdenticalTo(cluster))
+		})
+
+		It("Expands link to list retrieving all the pages", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/groups",
+						"page=1",
+					),
+					RespondWith(http.StatusOK, `{
+						"kind": "GroupList",
+						"page": 1,
+						"size": 1,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Group",
+								"id": "456"
+							}
+						]
+					}`),
+				),
+				CombineHandlers(
+					VerifyRequest(
+						http.MethodGet,
+						"/api/clusters_mgmt/v1/clusters/123/groups",
+						"page=2",
+					),
+					RespondWith(http.StatusOK, `{
+						"kind": "GroupList",
+						"page": 2,
+						"size": 1,
+						"total": 2,
+						"items": [
+							{
+								"kind": "Group",
+								"id": "789"
+							}
+						]
+					}`),
+				),
+			)
+
+			// Expand the link:
+			cluster, err := cmv1.UnmarshalCluster(`{
+				"kind": "Cluster",
+				"id": "123",
+				"groups": {
+					"kind": "GroupListLink",
+					"href": "/api/clusters_mgmt/v1/clusters/123/groups"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cluster.Groups().Link()).To(BeTrue())
+			groups, err := cluster.Groups().Expand(context.Background(), transport)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(groups.Link()).To(BeFalse())
+			Expect(groups.Len()).To(Equal(2))
+			Expect(groups.Get(0).ID()).To(Equal("456"))
+			Expect(groups.Get(1).ID()).To(Equal("789"))
+		})
+
+		It("Returns error if the server fails", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(http.StatusNotFound, `{
+					"kind": "Error",
+					"id": "404",
+					"href": "/api/clusters_mgmt/v1/errors/404",
+					"code": "CLUSTERS-MGMT-404",
+					"reason": "Not found"
+				}`),
+			)
+
+			// Expand the link:
+			link, err := cmv1.UnmarshalCluster(`{
+				"kind": "ClusterLink",
+				"id": "123",
+				"href": "/api/clusters_mgmt/v1/clusters/123"
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = link.Expand(context.Background(), transport)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Not found"))
+		})
+	})
+
+	Describe("Versions", func() {
+		It("Retrieves the versions supported by the server", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyRequest(http.MethodGet, "/api/clusters_mgmt"),
+					RespondWith(http.StatusOK, `{
+						"kind": "APIVersionList",
+						"items": [
+							{
+								"kind": "APIVersionLink",
+								"id": "v1",
+								"href": "/api/clusters_mgmt/v1"
+							}
+						]
+					}`),
+				),
+			)
+
+			// Send the request:
+			client := cm.NewClient(transport, "/api/clusters_mgmt", "")
+			response, err := client.Versions().Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.Items()).To(Equal([]string{"v1"}))
+			Expect(response.Supports("v1")).To(BeTrue())
+			Expect(response.Supports("v2")).To(BeFalse())
+			Expect(response.Link("v1")).To(Equal("/api/clusters_mgmt/v1"))
+			Expect(response.Link("v2")).To(BeEmpty())
+		})
+
+		It("Selects the newest known version supported by the server", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(http.StatusOK, `{
+					"items": [
+						{
+							"id": "v1"
+						},
+						{
+							"id": "v2"
+						},
+						{
+							"id": "v3"
+						}
+					]
+				}`),
+			)
+
+			// Send the request:
+			client := cm.NewClient(transport, "/api/clusters_mgmt", "")
+			response, err := client.Versions().Send()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cm.KnownVersions()).To(Equal([]string{"v1", "v2"}))
+			version, ok := response.Select()
+			Expect(ok).To(BeTrue())
+			Expect(version).To(Equal("v2"))
+		})
+
+		It("Selects the first preferred version supported by the server", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(http.StatusOK, `{
+					"items": [
+						{
+							"id": "v1"
+						}
+					]
+				}`),
+			)
+
+			// Send the request:
+			client := cm.NewClient(transport, "/api/clusters_mgmt", "")
+			response, err := client.Versions().Send()
+			Expect(err).ToNot(HaveOccurred())
+			version, ok := response.Select("v2", "v1")
+			Expect(ok).To(BeTrue())
+			Expect(version).To(Equal("v1"))
+			_, ok = response.Select("v2")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Sends the operation identifier and the impersonation headers", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				CombineHandlers(
+					VerifyHeaderKV("X-Operation-ID", "myid"),
+					VerifyHeaderKV("Impersonate-User", "myuser"),
+					RespondWith(
+						http.StatusOK,
+						`{
+							"items": []
+						}`,
+						http.Header{
+							"X-Operation-ID":    []string{"myid"},
+							"X-RateLimit-Limit": []string{"100"},
+						},
+					),
+				),
+			)
+
+			// Send the request:
+			client := cm.NewClient(transport, "/api/clusters_mgmt", "")
+			ctx := helpers.WithOperationID(context.Background(), "myid")
+			response, err := client.Versions().
+				Impersonate("myuser").
+				SendContext(ctx)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(response.OperationID()).To(Equal("myid"))
+			Expect(response.RawResponse().StatusCode).To(Equal(http.StatusOK))
+			Expect(response.RateLimit().Limit).To(Equal(100))
+		})
+
+		It("Gives access to the raw body of errors", func() {
+			// Prepare the server:
+			server.AppendHandlers(
+				RespondWith(
+					http.StatusNotFound,
+					`{
+						"kind": "Error",
+						"id": "404",
+						"reason": "Not found"
+					}`,
+				),
+			)
+
+			// Send the request:
+			client := cm.NewClient(transport, "/api/clusters_mgmt", "")
+			response, err := client.Versions().Send()
+			Expect(err).To(HaveOccurred())
+			Expect(response.RawBody()).To(ContainSubstring("Not found"))
+		})
+
+		It("Can clone and render the request", func() {
+			client := cm.NewClient(transport, "/api/clusters_mgmt", "")
+			base := client.Versions().Header("X-Base", "base")
+			command, err := base.Clone().Header("X-Clone", "clone").AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).To(ContainSubstring(`--header 'X-Base: base'`))
+			Expect(command).To(ContainSubstring(`--header 'X-Clone: clone'`))
+			Expect(command).To(HaveSuffix(`"${URL}"'/api/clusters_mgmt'`))
+			command, err = base.AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).ToNot(ContainSubstring("X-Clone"))
+		})
+	})
+
+	It("Doesn't send read only attributes", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters",
+				),
+				VerifyJSON(`{
+					"kind": "Cluster",
+					"name": "mycluster"
+				}`),
+				RespondWith(
+					http.StatusCreated,
+					`{
+						"kind": "Cluster",
+						"id": "123",
+						"name": "mycluster",
+						"state": "installing"
+					}`,
+				),
+			),
+		)
+
+		// Prepare the description of the cluster:
+		cluster, err := cmv1.NewCluster().
+			Name("mycluster").
+			State(cmv1.ClusterStateReady).
+			CreationTimestamp(time.Now()).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		response, err := client.Clusters().Add().
+			Body(cluster).
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).ToNot(BeNil())
+
+		// Verify that the original object hasn't been modified, and that the read only
+		// attributes are read from the response:
+		Expect(cluster.State()).To(Equal(cmv1.ClusterStateReady))
+		Expect(response.Body().State()).To(Equal(cmv1.ClusterStateInstalling))
 	})
 
 	It("Can execute action with one input parameter", func() {
@@ -212,73 +552,100 @@ var _ = Describe("Client", func() {
 		Expect(cluster.ExternalID()).To(Equal("456"))
 	})
 
-	It("Can retrieve nil list", func() {
-		// Prepare the server:
-		server.AppendHandlers(
-			CombineHandlers(
-				VerifyRequest(
-					http.MethodGet,
-					"/api/clusters_mgmt/v1/clusters",
-				),
-				RespondWith(http.StatusOK, `{}`),
-			),
-		)
-
-		// Send the request:
-		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
-		response, err := client.List().Send()
-		Expect(err).ToNot(HaveOccurred())
-		Expect(response).ToNot(BeNil())
-
-		// Verify the result:
-		items := response.Items()
-		Expect(items).To(BeNil())
-	})
-
-	It("Can retrieve empty list", func() {
+	It("Can execute bulk add", func() {
 		// Prepare the server:
 		server.AppendHandlers(
 			CombineHandlers(
 				VerifyRequest(
-					http.MethodGet,
-					"/api/clusters_mgmt/v1/clusters",
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/bulk_add",
 				),
+				VerifyJSON(`{
+					"items": [
+						{
+							"kind": "Cluster",
+							"name": "mycluster"
+						},
+						{
+							"kind": "Cluster",
+							"name": "yourcluster"
+						}
+					]
+				}`),
 				RespondWith(
 					http.StatusOK,
 					`{
-						"items": []
+						"results": [
+							{
+								"index": 0,
+								"id": "123",
+								"status": 201
+							},
+							{
+								"index": 1,
+								"status": 409,
+								"reason": "Cluster 'yourcluster' already exists"
+							}
+						]
 					}`,
 				),
 			),
 		)
 
+		// Prepare the descriptions of the clusters:
+		mine, err := cmv1.NewCluster().
+			Name("mycluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		yours, err := cmv1.NewCluster().
+			Name("yourcluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
 		// Send the request:
 		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
-		response, err := client.List().Send()
+		response, err := client.BulkAdd().
+			Items([]*cmv1.Cluster{mine, yours}).
+			Send()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(response).ToNot(BeNil())
 
 		// Verify the response:
-		items := response.Items()
-		Expect(items).ToNot(BeNil())
-		Expect(items.Len()).To(BeZero())
+		results := response.Results()
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].Index()).To(Equal(0))
+		Expect(results[0].ID()).To(Equal("123"))
+		Expect(results[0].Status()).To(Equal(http.StatusCreated))
+		Expect(results[1].Index()).To(Equal(1))
+		Expect(results[1].Status()).To(Equal(http.StatusConflict))
+		Expect(results[1].Reason()).To(Equal("Cluster 'yourcluster' already exists"))
 	})
 
-	It("Can retrieve list with one element", func() {
+	It("Can execute bulk delete", func() {
 		// Prepare the server:
 		server.AppendHandlers(
 			CombineHandlers(
 				VerifyRequest(
-					http.MethodGet,
-					"/api/clusters_mgmt/v1/clusters",
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/clusters/bulk_delete",
 				),
+				VerifyJSON(`{
+					"search": "name like 'my%'"
+				}`),
 				RespondWith(
 					http.StatusOK,
 					`{
-						"items": [
+						"results": [
 							{
+								"index": 0,
 								"id": "123",
-								"name": "mycluster"
+								"status": 204
+							},
+							{
+								"index": 1,
+								"id": "456",
+								"status": 403,
+								"reason": "Cluster '456' can't be deleted"
 							}
 						]
 					}`,
@@ -288,87 +655,439 @@ var _ = Describe("Client", func() {
 
 		// Send the request:
 		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
-		response, err := client.List().Send()
+		response, err := client.BulkDelete().
+			Search("name like 'my%'").
+			Send()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(response).ToNot(BeNil())
 
 		// Verify the response:
-		items := response.Items()
-		Expect(items).ToNot(BeNil())
-		Expect(items.Len()).To(Equal(1))
-		slice := items.Slice()
-		Expect(slice).ToNot(BeNil())
-		Expect(slice).To(HaveLen(1))
-		item := slice[0]
-		Expect(item).ToNot(BeNil())
-		Expect(item.ID()).To(Equal("123"))
-		Expect(item.Name()).To(Equal("mycluster"))
+		results := response.Results()
+		Expect(results).To(HaveLen(2))
+		Expect(results[0].ID()).To(Equal("123"))
+		Expect(results[0].Status()).To(Equal(http.StatusNoContent))
+		Expect(results[1].ID()).To(Equal("456"))
+		Expect(results[1].Status()).To(Equal(http.StatusForbidden))
+		Expect(results[1].Reason()).To(Equal("Cluster '456' can't be deleted"))
 	})
 
-	It("Can retrieve list with two elements", func() {
+	It("Can ping the server", func() {
 		// Prepare the server:
 		server.AppendHandlers(
 			CombineHandlers(
 				VerifyRequest(
-					http.MethodGet,
-					"/api/clusters_mgmt/v1/clusters",
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/ping",
 				),
 				RespondWith(
 					http.StatusOK,
 					`{
-						"items": [
-							{
-								"id": "123",
-								"name": "mycluster"
-							},
-							{
-								"id": "456",
-								"name": "yourcluster"
-							}
-						]
+						"time": "2019-07-14T15:16:17Z",
+						"version": "1.2.3"
 					}`,
 				),
 			),
 		)
 
 		// Send the request:
-		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
-		response, err := client.List().Send()
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		response, err := client.Ping().Send()
 		Expect(err).ToNot(HaveOccurred())
 		Expect(response).ToNot(BeNil())
 
 		// Verify the response:
-		items := response.Items()
-		Expect(items).ToNot(BeNil())
-		Expect(items.Len()).To(Equal(2))
-		slice := items.Slice()
-		Expect(slice).ToNot(BeNil())
-		Expect(slice).To(HaveLen(2))
-		first := slice[0]
-		Expect(first).ToNot(BeNil())
-		Expect(first.ID()).To(Equal("123"))
-		Expect(first.Name()).To(Equal("mycluster"))
-		second := slice[1]
-		Expect(second).ToNot(BeNil())
-		Expect(second.ID()).To(Equal("456"))
-		Expect(second.Name()).To(Equal("yourcluster"))
+		Expect(response.Time()).To(Equal(time.Date(2019, 7, 14, 15, 16, 17, 0, time.UTC)))
+		Expect(response.Version()).To(Equal("1.2.3"))
 	})
 
-	It("Sends paging parameters", func() {
+	Describe("Curl command", func() {
+		It("Renders a get request", func() {
+			client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+			command, err := client.Get().AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).To(Equal(
+				`curl --header "Authorization: Bearer ${TOKEN}" ` +
+					`"${URL}"'/api/clusters_mgmt/v1/clusters/123'`,
+			))
+		})
+
+		It("Renders query parameters and headers", func() {
+			client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+			command, err := client.List().
+				Parameter("search", "name = 'my'").
+				Header("X-Foo", "bar").
+				Header("Authorization", "Bearer mytoken").
+				AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).To(ContainSubstring(`--header 'X-Foo: bar'`))
+			Expect(command).To(ContainSubstring(`search=name+%3D+%27my%27`))
+			Expect(command).ToNot(ContainSubstring("mytoken"))
+		})
+
+		It("Renders a metadata request", func() {
+			client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+			command, err := client.Get().Parameter("experimental", true).AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).To(Equal(
+				`curl --header "Authorization: Bearer ${TOKEN}" ` +
+					`"${URL}"'/api/clusters_mgmt/v1?experimental=true'`,
+			))
+		})
+
+		It("Renders the body of the request", func() {
+			cluster, err := cmv1.NewCluster().
+				DisplayName("it's mine").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+			command, err := client.Add().Body(cluster).AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).To(HavePrefix("curl --request POST "))
+			Expect(command).To(ContainSubstring(`--header 'Content-Type: application/json'`))
+			Expect(command).To(ContainSubstring(`--data '{`))
+			Expect(command).To(ContainSubstring(`"display_name"`))
+			Expect(command).To(ContainSubstring(`"it'\''s mine"`))
+			Expect(command).To(HaveSuffix(`"${URL}"'/api/clusters_mgmt/v1/clusters'`))
+		})
+
+		It("Redacts sensitive attributes of the body", func() {
+			provider, err := cmv1.NewIdentityProvider().
+				Name("my").
+				LDAP(cmv1.NewLDAPIdentityProvider().
+					BindDN("cn=admin").
+					BindPassword("mypassword"),
+				).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			client := cmv1.NewIdentityProvidersClient(
+				transport,
+				"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				"",
+			)
+			command, err := client.Add().Body(provider).AsCurl()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(command).To(ContainSubstring(`"bind_dn":"cn=admin"`))
+			Expect(command).To(ContainSubstring(`"bind_password":"REDACTED"`))
+			Expect(command).ToNot(ContainSubstring("mypassword"))
+		})
+	})
+
+	It("Can clone requests", func() {
 		// Prepare the server:
 		server.AppendHandlers(
 			CombineHandlers(
-				VerifyRequest(
-					http.MethodGet,
-					"/api/clusters_mgmt/v1/clusters",
-				),
-				VerifyFormKV("page", "123"),
-				VerifyFormKV("size", "456"),
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=1&search=a"),
+				VerifyHeaderKV("X-Base", "base"),
+				VerifyHeaderKV("X-Clone", "first"),
 				RespondWith(http.StatusOK, `{}`),
 			),
-		)
-
-		// Send the request:
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=2&search=a"),
+				VerifyHeaderKV("X-Base", "base"),
+				VerifyHeaderKV("X-Clone", "second"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "search=a"),
+				VerifyHeaderKV("X-Base", "base"),
+				func(w http.ResponseWriter, r *http.Request) {
+					Expect(r.Header.Get("X-Clone")).To(BeEmpty())
+				},
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Prepare the base request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		base := client.List().
+			Parameter("search", "a").
+			Header("X-Base", "base")
+
+		// Send modified clones and then the base request:
+		_, err := base.Clone().Page(1).Header("X-Clone", "first").Send()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = base.Clone().Page(2).Header("X-Clone", "second").Send()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = base.Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can clone metadata requests", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1", "a=1&b=2"),
+				VerifyHeaderKV("X-Base", "base"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1", "a=1"),
+				VerifyHeaderKV("X-Base", "base"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send a modified clone and then the base request:
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		base := client.Get().
+			Parameter("a", 1).
+			Header("X-Base", "base")
+		_, err := base.Clone().Parameter("b", 2).Send()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = base.Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can retrieve multiple objects by identifier", func() {
+		// Prepare the server:
+		server.SetAllowUnhandledRequests(true)
+		server.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/123",
+			RespondWith(http.StatusOK, `{"id": "123", "name": "first"}`),
+		)
+		server.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/456",
+			RespondWith(http.StatusOK, `{"id": "456", "name": "second"}`),
+		)
+		server.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/789",
+			RespondWith(http.StatusNotFound, `{
+				"kind": "Error",
+				"id": "404",
+				"reason": "Cluster '789' not found"
+			}`),
+		)
+
+		// Send the requests:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		items, errs := client.GetMany(context.Background(), "123", "456", "789", "123")
+		Expect(server.ReceivedRequests()).To(HaveLen(3))
+
+		// Check the results:
+		Expect(items).To(HaveLen(2))
+		Expect(items["123"].Name()).To(Equal("first"))
+		Expect(items["456"].Name()).To(Equal("second"))
+		Expect(errs).To(HaveLen(1))
+		Expect(errs["789"]).To(HaveOccurred())
+		Expect(errs["789"].Error()).To(ContainSubstring("not found"))
+	})
+
+	It("Limits the number of concurrent requests of GetMany", func() {
+		// Prepare the server so that it records the maximum number of requests that are
+		// processed at the same time:
+		lock := &sync.Mutex{}
+		active := 0
+		peak := 0
+		server.SetAllowUnhandledRequests(true)
+		server.RouteToHandler(
+			http.MethodGet,
+			regexp.MustCompile(`^/api/clusters_mgmt/v1/clusters/[0-9]+$`),
+			func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				active++
+				if active > peak {
+					peak = active
+				}
+				lock.Unlock()
+				time.Sleep(10 * time.Millisecond)
+				lock.Lock()
+				active--
+				lock.Unlock()
+				w.Header().Set("Content-Type", "application/json")
+				_, err := w.Write([]byte(`{"id": "123"}`))
+				Expect(err).ToNot(HaveOccurred())
+			},
+		)
+
+		// Send the requests:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		items, errs := client.GetManyConcurrently(
+			context.Background(), 2,
+			"1", "2", "3", "4", "5", "6",
+		)
+		Expect(errs).To(BeEmpty())
+		Expect(items).To(HaveLen(6))
+		Expect(server.ReceivedRequests()).To(HaveLen(6))
+		Expect(peak).To(BeNumerically("<=", 2))
+	})
+
+	It("Doesn't send GetMany requests after the context is cancelled", func() {
+		// Prepare a context that is already cancelled:
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// Send the requests:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		items, errs := client.GetMany(ctx, "123", "456")
+		Expect(server.ReceivedRequests()).To(BeEmpty())
+
+		// Check the results:
+		Expect(items).To(BeEmpty())
+		Expect(errs).To(HaveLen(2))
+		Expect(errs["123"]).To(MatchError(context.Canceled))
+		Expect(errs["456"]).To(MatchError(context.Canceled))
+	})
+
+	It("Can retrieve nil list", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters",
+				),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).ToNot(BeNil())
+
+		// Verify the result:
+		items := response.Items()
+		Expect(items).To(BeNil())
+	})
+
+	It("Can retrieve empty list", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters",
+				),
+				RespondWith(
+					http.StatusOK,
+					`{
+						"items": []
+					}`,
+				),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).ToNot(BeNil())
+
+		// Verify the response:
+		items := response.Items()
+		Expect(items).ToNot(BeNil())
+		Expect(items.Len()).To(BeZero())
+	})
+
+	It("Can retrieve list with one element", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters",
+				),
+				RespondWith(
+					http.StatusOK,
+					`{
+						"items": [
+							{
+								"id": "123",
+								"name": "mycluster"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).ToNot(BeNil())
+
+		// Verify the response:
+		items := response.Items()
+		Expect(items).ToNot(BeNil())
+		Expect(items.Len()).To(Equal(1))
+		slice := items.Slice()
+		Expect(slice).ToNot(BeNil())
+		Expect(slice).To(HaveLen(1))
+		item := slice[0]
+		Expect(item).ToNot(BeNil())
+		Expect(item.ID()).To(Equal("123"))
+		Expect(item.Name()).To(Equal("mycluster"))
+	})
+
+	It("Can retrieve list with two elements", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters",
+				),
+				RespondWith(
+					http.StatusOK,
+					`{
+						"items": [
+							{
+								"id": "123",
+								"name": "mycluster"
+							},
+							{
+								"id": "456",
+								"name": "yourcluster"
+							}
+						]
+					}`,
+				),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response).ToNot(BeNil())
+
+		// Verify the response:
+		items := response.Items()
+		Expect(items).ToNot(BeNil())
+		Expect(items.Len()).To(Equal(2))
+		slice := items.Slice()
+		Expect(slice).ToNot(BeNil())
+		Expect(slice).To(HaveLen(2))
+		first := slice[0]
+		Expect(first).ToNot(BeNil())
+		Expect(first.ID()).To(Equal("123"))
+		Expect(first.Name()).To(Equal("mycluster"))
+		second := slice[1]
+		Expect(second).ToNot(BeNil())
+		Expect(second.ID()).To(Equal("456"))
+		Expect(second.Name()).To(Equal("yourcluster"))
+	})
+
+	It("Sends paging parameters", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1/clusters",
+				),
+				VerifyFormKV("page", "123"),
+				VerifyFormKV("size", "456"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the request:
 		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
 		response, err := client.List().
 			Page(123).
@@ -410,6 +1129,597 @@ var _ = Describe("Client", func() {
 		Expect(response.Total()).To(Equal(789))
 	})
 
+	It("Sends custom header and query parameter for actions", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodPost,
+					"/api/clusters_mgmt/v1/register_disconnected",
+					"experimental=true",
+				),
+				VerifyHeaderKV("X-Experimental", "yes"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		_, err := client.RegisterDisconnected().
+			Parameter("experimental", true).
+			Header("X-Experimental", "yes").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Sends custom header and query parameter for metadata", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(
+					http.MethodGet,
+					"/api/clusters_mgmt/v1",
+					"experimental=true",
+				),
+				VerifyHeaderKV("X-Experimental", "yes"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		_, err := client.Get().
+			Parameter("experimental", true).
+			Header("X-Experimental", "yes").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Gives access to the raw response", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"experimental": true
+				}`,
+				http.Header{
+					"Deprecation": []string{"true"},
+				},
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		response, err := client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().ID()).To(Equal("123"))
+
+		// Check the raw response:
+		raw := response.RawResponse()
+		Expect(raw).ToNot(BeNil())
+		Expect(raw.StatusCode).To(Equal(http.StatusOK))
+		Expect(raw.Header.Get("Deprecation")).To(Equal("true"))
+
+		// The body of successful responses is decoded while it is received, so it isn't
+		// kept:
+		Expect(response.RawBody()).To(BeNil())
+		data, err := ioutil.ReadAll(raw.Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(BeEmpty())
+	})
+
+	It("Gives access to the raw body of successful responses if requested", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{
+					"kind": "Cluster",
+					"id": "123",
+					"experimental": true
+				}`,
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		response, err := client.Get().KeepRawBody(true).Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().ID()).To(Equal("123"))
+
+		// Check that the body is kept, including the fields that the client doesn't
+		// understand:
+		Expect(response.RawBody()).To(MatchJSON(`{
+			"kind": "Cluster",
+			"id": "123",
+			"experimental": true
+		}`))
+		data, err := ioutil.ReadAll(response.RawResponse().Body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal(response.RawBody()))
+	})
+
+	It("Gives access to the raw body of metadata if requested", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{
+				"server_version": "123"
+			}`),
+		)
+
+		// Send the request:
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		response, err := client.Get().KeepRawBody(true).Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().ServerVersion()).To(Equal("123"))
+		Expect(response.RawBody()).To(MatchJSON(`{
+			"server_version": "123"
+		}`))
+	})
+
+	It("Gives access to the raw body of errors", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusNotFound,
+				`{
+					"kind": "Error",
+					"id": "404",
+					"reason": "Not found"
+				}`,
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		response, err := client.Get().Send()
+		Expect(err).To(HaveOccurred())
+		Expect(response.RawResponse().StatusCode).To(Equal(http.StatusNotFound))
+		Expect(response.RawBody()).To(ContainSubstring("Not found"))
+	})
+
+	It("Rejects error bodies that are too large", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusInternalServerError,
+				strings.Repeat("x", helpers.MaxErrorBodySize+1),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err := client.Get().Send()
+		Expect(err).To(MatchError(helpers.ErrBodyTooLarge))
+	})
+
+	It("Returns the rate limit information", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{}`,
+				http.Header{
+					"Date":                  []string{"Thu, 02 Jan 2020 03:04:05 GMT"},
+					"X-RateLimit-Limit":     []string{"100"},
+					"X-RateLimit-Remaining": []string{"42"},
+					"X-RateLimit-Reset":     []string{"60"},
+				},
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		limit := response.RateLimit()
+		Expect(limit).ToNot(BeNil())
+		Expect(limit.Limit).To(Equal(100))
+		Expect(limit.Remaining).To(Equal(42))
+		Expect(limit.Reset).To(BeTemporally("==", time.Date(2020, 1, 2, 3, 5, 5, 0, time.UTC)))
+		Expect(limit.RetryAfter).To(BeZero())
+	})
+
+	It("Returns the retry after information of errors", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusTooManyRequests,
+				`{
+					"kind": "Error",
+					"id": "429",
+					"reason": "Too many requests"
+				}`,
+				http.Header{
+					"Retry-After":       []string{"30"},
+					"X-RateLimit-Reset": []string{"1577934245"},
+				},
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).To(HaveOccurred())
+		limit := response.RateLimit()
+		Expect(limit).ToNot(BeNil())
+		Expect(limit.RetryAfter).To(Equal(30 * time.Second))
+		Expect(limit.Reset).To(BeTemporally("==", time.Unix(1577934245, 0)))
+	})
+
+	It("Returns nil rate limit if the server doesn't send it", func() {
+		// Prepare the server:
+		server.AppendHandlers(RespondWith(http.StatusOK, `{}`))
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.RateLimit()).To(BeNil())
+	})
+
+	It("Retrieves all the pages", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=1&size=2"),
+				RespondWith(http.StatusOK, `{
+					"page": 1,
+					"size": 2,
+					"total": 3,
+					"items": [
+						{ "id": "123" },
+						{ "id": "456" }
+					]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=2&size=2"),
+				RespondWith(http.StatusOK, `{
+					"page": 2,
+					"size": 1,
+					"total": 3,
+					"items": [
+						{ "id": "789" }
+					]
+				}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		list, err := client.List().
+			Size(2).
+			ListAll(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Len()).To(Equal(3))
+		Expect(list.Get(0).ID()).To(Equal("123"))
+		Expect(list.Get(1).ID()).To(Equal("456"))
+		Expect(list.Get(2).ID()).To(Equal("789"))
+	})
+
+	It("Stops retrieving pages after a page shorter than the requested size", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=1&size=2"),
+				RespondWith(http.StatusOK, `{
+					"page": 1,
+					"size": 2,
+					"items": [
+						{ "id": "123" },
+						{ "id": "456" }
+					]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=2&size=2"),
+				RespondWith(http.StatusOK, `{
+					"page": 2,
+					"size": 1,
+					"items": [
+						{ "id": "789" }
+					]
+				}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		list, err := client.List().
+			Size(2).
+			ListAll(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Len()).To(Equal(3))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Stops retrieving pages after a page shorter than the default size", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=1"),
+				RespondWith(http.StatusOK, `{
+					"page": 1,
+					"size": 2,
+					"items": [
+						{ "id": "123" },
+						{ "id": "456" }
+					]
+				}`),
+			),
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters", "page=2"),
+				RespondWith(http.StatusOK, `{
+					"page": 2,
+					"size": 1,
+					"items": [
+						{ "id": "789" }
+					]
+				}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		list, err := client.List().ListAll(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(list.Len()).To(Equal(3))
+		Expect(server.ReceivedRequests()).To(HaveLen(2))
+	})
+
+	It("Fails if there are more items than the maximum", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{
+				"page": 1,
+				"size": 2,
+				"total": 4,
+				"items": [
+					{ "id": "123" },
+					{ "id": "456" }
+				]
+			}`),
+			RespondWith(http.StatusOK, `{
+				"page": 2,
+				"size": 2,
+				"total": 4,
+				"items": [
+					{ "id": "789" },
+					{ "id": "012" }
+				]
+			}`),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		_, err := client.List().
+			Size(2).
+			MaxItems(3).
+			ListAll(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("3"))
+	})
+
+	It("Stops retrieving pages when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		_, err := client.List().ListAll(ctx)
+		Expect(err).To(Equal(context.Canceled))
+		Expect(server.ReceivedRequests()).To(BeEmpty())
+	})
+
+	It("Sends the operation identifier from the context", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("X-Operation-ID", "myid"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		ctx := helpers.WithOperationID(context.Background(), "myid")
+		_, err := client.List().SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Sends the impersonation headers", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyHeader(http.Header{
+					"Impersonate-User":  []string{"myuser"},
+					"Impersonate-Group": []string{"mygroup1", "mygroup2"},
+				}),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		_, err := client.List().
+			Impersonate("myuser", "mygroup1", "mygroup2").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Sends the impersonation headers of the transport", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Impersonate-User", "myuser"),
+				VerifyHeaderKV("Impersonate-Group", "mygroup"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+			CombineHandlers(
+				VerifyHeaderKV("Impersonate-User", "youruser"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+
+		// Send the requests:
+		impersonator := helpers.NewImpersonationTransport(transport, "myuser", "mygroup")
+		client := cmv1.NewClustersClient(impersonator, "/api/clusters_mgmt/v1/clusters", "")
+		_, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		_, err = client.List().Impersonate("youruser").Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Returns the operation identifier", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{}`,
+				http.Header{
+					"X-Operation-ID": []string{"myid"},
+				},
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.OperationID()).To(Equal("myid"))
+	})
+
+	It("Returns the operation identifier of metadata", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{}`,
+				http.Header{
+					"X-Operation-ID": []string{"myid"},
+				},
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClient(transport, "/api/clusters_mgmt/v1", "")
+		response, err := client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.OperationID()).To(Equal("myid"))
+	})
+
+	It("Returns the operation identifier of errors", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusNotFound,
+				`{
+					"kind": "Error",
+					"id": "404",
+					"reason": "Not found",
+					"operation_id": "myid"
+				}`,
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).To(HaveOccurred())
+		Expect(response.Error().OperationID()).To(Equal("myid"))
+	})
+
+	It("Rejects responses larger than the limit", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{
+				"items": [
+					{
+						"name": "mycluster"
+					},
+					{
+						"name": "yourcluster"
+					}
+				]
+			}`),
+		)
+
+		// Send the request:
+		limiter := helpers.NewResponseLimiter(transport, 10)
+		client := cmv1.NewClustersClient(limiter, "/api/clusters_mgmt/v1/clusters", "")
+		_, err := client.List().Send()
+		Expect(err).To(Equal(helpers.ErrBodyTooLarge))
+	})
+
+	It("Handles not modified responses", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("If-Modified-Since", "Thu, 02 Jan 2020 03:04:05 GMT"),
+				RespondWith(
+					http.StatusNotModified,
+					nil,
+					http.Header{
+						"Last-Modified": []string{"Thu, 02 Jan 2020 03:04:05 GMT"},
+					},
+				),
+			),
+		)
+
+		// Send the request:
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		response, err := client.Get().
+			IfModifiedSince(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)).
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.NotModified()).To(BeTrue())
+		Expect(response.LastModified()).To(BeTemporally(
+			"==",
+			time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		))
+	})
+
+	It("Decompresses gzip responses", func() {
+		// Prepare the server:
+		buffer := &bytes.Buffer{}
+		writer := gzip.NewWriter(buffer)
+		_, err := writer.Write([]byte(`{
+			"page": 1,
+			"size": 1,
+			"total": 1,
+			"items": [
+				{
+					"name": "mycluster"
+				}
+			]
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		err = writer.Close()
+		Expect(err).ToNot(HaveOccurred())
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Accept-Encoding", "gzip"),
+				RespondWith(
+					http.StatusOK,
+					buffer.Bytes(),
+					http.Header{
+						"Content-Encoding": []string{"gzip"},
+					},
+				),
+			),
+		)
+
+		// Send the request:
+		compressor := helpers.NewGzipTransport(transport)
+		client := cmv1.NewClustersClient(compressor, "/api/clusters_mgmt/v1/clusters", "")
+		response, err := client.List().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Items().Len()).To(Equal(1))
+		Expect(response.Items().Get(0).Name()).To(Equal("mycluster"))
+	})
+
 	DescribeTable(
 		"Custom query parameters",
 		func(value interface{}, expected string) {
@@ -0,0 +1,155 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests that send requests concurrently. They are intended to be executed
+// with the race detector enabled, as done by the 'go_tests' target of the Makefile.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("Concurrency", func() {
+	// Number of goroutines used by each test:
+	const count = 20
+
+	var server *Server
+	var transport http.RoundTripper
+
+	BeforeEach(func() {
+		server = NewServer()
+		server.SetAllowUnhandledRequests(true)
+		server.RouteToHandler(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters",
+			func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(
+					w,
+					`{"page": 1, "size": 1, "total": 1, "items": [{"id": "%s", "name": "%s"}]}`,
+					r.URL.Query().Get("search"),
+					r.Header.Get("X-Name"),
+				)
+			},
+		)
+		transport = NewTransport(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Sends the same request from multiple goroutines", func() {
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		request := client.List().
+			Search("shared").
+			Header("X-Name", "shared")
+		errs := make(chan error, count)
+		wg := &sync.WaitGroup{}
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer GinkgoRecover()
+				ctx := helpers.WithOperationID(context.Background(), "shared")
+				response, err := request.SendContext(ctx)
+				if err != nil {
+					errs <- err
+					return
+				}
+				item := response.Items().Get(0)
+				Expect(item.ID()).To(Equal("shared"))
+				Expect(item.Name()).To(Equal("shared"))
+			}()
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("Sends clones of a base request from multiple goroutines", func() {
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		base := client.List().
+			Size(1).
+			Header("X-Name", "base")
+		errs := make(chan error, count)
+		wg := &sync.WaitGroup{}
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				id := fmt.Sprintf("%d", i)
+				response, err := base.Clone().
+					Search(id).
+					Header("X-Name", id).
+					Send()
+				if err != nil {
+					errs <- err
+					return
+				}
+				item := response.Items().Get(0)
+				Expect(item.ID()).To(Equal(id))
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+
+	It("Shares a client between goroutines", func() {
+		client := cmv1.NewClustersClient(transport, "/api/clusters_mgmt/v1/clusters", "")
+		errs := make(chan error, count)
+		wg := &sync.WaitGroup{}
+		for i := 0; i < count; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				defer GinkgoRecover()
+				id := fmt.Sprintf("%d", i)
+				response, err := client.List().
+					Search(id).
+					Send()
+				if err != nil {
+					errs <- err
+					return
+				}
+				item := response.Items().Get(0)
+				Expect(item.ID()).To(Equal(id))
+			}(i)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			Expect(err).ToNot(HaveOccurred())
+		}
+	})
+})
@@ -0,0 +1,116 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the functions that convert objects between versions.
+
+package tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cm "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt"
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	cmv2 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v2"
+)
+
+var _ = Describe("Conversion", func() {
+	It("Converts nil", func() {
+		result, unconverted, err := cm.ConvertClusterV1ToV2(nil)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(BeNil())
+		Expect(unconverted).To(BeEmpty())
+	})
+
+	It("Converts attributes with the same name and type", func() {
+		original, err := cmv1.NewCluster().
+			ID("123").
+			Name("mycluster").
+			DisplayName("My cluster").
+			Properties(map[string]string{
+				"owner": "me",
+			}).
+			State(cmv1.ClusterStateReady).
+			Nodes(cmv1.NewClusterNodes().
+				Master(3).
+				Compute(10),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, unconverted, err := cm.ConvertClusterV1ToV2(original)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(unconverted).To(BeEmpty())
+		Expect(result).ToNot(BeNil())
+		Expect(result.ID()).To(Equal("123"))
+		Expect(result.Name()).To(Equal("mycluster"))
+		Expect(result.DisplayName()).To(Equal("My cluster"))
+		Expect(result.Properties()).To(Equal(map[string]string{
+			"owner": "me",
+		}))
+		Expect(result.State()).To(Equal(cmv2.ClusterStateReady))
+		Expect(result.Nodes().Master()).To(Equal(3))
+		Expect(result.Nodes().Compute()).To(Equal(10))
+	})
+
+	It("Reports attributes that can't be converted", func() {
+		original, err := cmv1.NewCluster().
+			Name("mycluster").
+			ExternalID("456").
+			Managed(true).
+			State(cmv1.ClusterStatePending).
+			Nodes(cmv1.NewClusterNodes().
+				Infra(2).
+				Compute(10),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, unconverted, err := cm.ConvertClusterV1ToV2(original)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeNil())
+		Expect(result.Name()).To(Equal("mycluster"))
+		Expect(result.Nodes().Compute()).To(Equal(10))
+		Expect(unconverted).To(ConsistOf(
+			"nodes.infra",
+			"state",
+			"managed",
+			"external_id",
+		))
+	})
+
+	It("Converts back to the previous version", func() {
+		original, err := cmv2.NewCluster().
+			Name("mycluster").
+			Region("us-east-1").
+			State(cmv2.ClusterStateInstalling).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		result, unconverted, err := cm.ConvertClusterV2ToV1(original)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).ToNot(BeNil())
+		Expect(result.Name()).To(Equal("mycluster"))
+		Expect(result.State()).To(Equal(cmv1.ClusterStateInstalling))
+		Expect(unconverted).To(ConsistOf("region"))
+	})
+
+	It("Fails if the result doesn't satisfy the constraints", func() {
+		original, err := cmv2.NewCluster().
+			Name("My Cluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = cm.ConvertClusterV2ToV1(original)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,85 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the descriptors of the model.
+
+package tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/descriptors"
+)
+
+var _ = Describe("Descriptors", func() {
+	It("Describe the attributes of types", func() {
+		service := descriptors.Model.FindService("clusters_mgmt")
+		Expect(service).ToNot(BeNil())
+		version := service.FindVersion("v1")
+		Expect(version).ToNot(BeNil())
+		Expect(version.Path).To(Equal("/api/clusters_mgmt/v1"))
+		cluster := version.FindType("Cluster")
+		Expect(cluster).ToNot(BeNil())
+		Expect(cluster.Kind).To(Equal(descriptors.ClassKind))
+		name := cluster.FindAttribute("name")
+		Expect(name).ToNot(BeNil())
+		Expect(name.Name).To(Equal("Name"))
+		Expect(name.Type).To(Equal("String"))
+		Expect(name.Immutable).To(BeTrue())
+		factor := cluster.FindAttribute("Factor")
+		Expect(factor).ToNot(BeNil())
+		Expect(factor.Field).To(Equal("factor"))
+		Expect(factor.Since).To(Equal("1.1"))
+	})
+
+	It("Describe the values of enum types", func() {
+		state := descriptors.Model.
+			FindService("clusters_mgmt").
+			FindVersion("v1").
+			FindType("ClusterState")
+		Expect(state).ToNot(BeNil())
+		Expect(state.Kind).To(Equal(descriptors.EnumKind))
+		Expect(state.Values).To(ContainElement("ready"))
+	})
+
+	It("Describe the methods of resources", func() {
+		resource := descriptors.Model.
+			FindService("clusters_mgmt").
+			FindVersion("v1").
+			FindResource("Cluster")
+		Expect(resource).ToNot(BeNil())
+		get := resource.FindMethod("Get")
+		Expect(get).ToNot(BeNil())
+		Expect(get.HTTPMethod).To(Equal("GET"))
+		remove := resource.FindMethod("Delete")
+		Expect(remove).ToNot(BeNil())
+		Expect(remove.HTTPMethod).To(Equal("DELETE"))
+		Expect(remove.Since).To(Equal("1.1"))
+	})
+
+	It("Describe the paths of versions", func() {
+		version := descriptors.Model.
+			FindService("clusters_mgmt").
+			FindVersion("v1")
+		var paths []string
+		for _, path := range version.Paths {
+			paths = append(paths, path.Path)
+		}
+		Expect(paths).To(ContainElement("/api/clusters_mgmt/v1/clusters"))
+		Expect(paths).To(ContainElement("/api/clusters_mgmt/v1/clusters/{cluster_id}"))
+	})
+})
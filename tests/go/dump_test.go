@@ -0,0 +1,281 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the transport that dumps requests and responses.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("Dump transport", func() {
+	var server *Server
+	var transport http.RoundTripper
+	var output *bytes.Buffer
+
+	BeforeEach(func() {
+		server = NewServer()
+		transport = NewTransport(server)
+		output = &bytes.Buffer{}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Can't be created without a transport", func() {
+		_, err := helpers.NewDumpTransport(nil).Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Dumps requests and responses", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{"kind": "Cluster", "id": "123", "name": "mycluster"}`,
+				http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+			),
+		)
+
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		client := cmv1.NewClusterClient(dumper, "/api/clusters_mgmt/v1/clusters/123", "")
+		response, err := client.Get().
+			Header("Authorization", "Bearer mytoken").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(response.Body().Name()).To(Equal("mycluster"))
+
+		// Check the output:
+		text := output.String()
+		Expect(text).To(ContainSubstring("> GET /api/clusters_mgmt/v1/clusters/123"))
+		Expect(text).To(ContainSubstring("> Authorization: REDACTED"))
+		Expect(text).ToNot(ContainSubstring("mytoken"))
+		Expect(text).To(ContainSubstring("< HTTP/1.1 200 OK"))
+		Expect(text).To(ContainSubstring("< Content-Type: application/json"))
+		Expect(text).To(ContainSubstring(`<   "name": "mycluster"`))
+	})
+
+	It("Redacts sensitive fields of bodies", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(
+				http.StatusOK,
+				`{"access_token": "mytoken", "token_type": "Bearer"}`,
+				http.Header{
+					"Content-Type": []string{"application/json"},
+				},
+			),
+		)
+
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		client := cmv1.NewClusterClient(dumper, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Check the output:
+		text := output.String()
+		Expect(text).To(ContainSubstring(`"access_token": "REDACTED"`))
+		Expect(text).To(ContainSubstring(`"token_type": "Bearer"`))
+		Expect(text).ToNot(ContainSubstring("mytoken"))
+	})
+
+	It("Redacts the attributes marked as sensitive in the model", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusCreated, `{}`),
+		)
+
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		provider, err := cmv1.NewIdentityProvider().
+			LDAP(cmv1.NewLDAPIdentityProvider().
+				BindDN("cn=admin").
+				BindPassword("mypassword"),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewIdentityProvidersClient(
+			dumper,
+			"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+			"",
+		)
+		_, err = client.Add().Body(provider).Send()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Check the output:
+		text := output.String()
+		Expect(text).To(ContainSubstring(`"bind_dn": "cn=admin"`))
+		Expect(text).To(ContainSubstring(`"bind_password": "REDACTED"`))
+		Expect(text).ToNot(ContainSubstring("mypassword"))
+	})
+
+	It("Redacts credentials in the query", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{}`),
+		)
+
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		client := cmv1.NewClustersClient(dumper, "/api/clusters_mgmt/v1/clusters", "")
+		_, err = client.List().
+			Parameter("access_token", "mytoken").
+			Page(1).
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Check the output:
+		text := output.String()
+		Expect(text).To(ContainSubstring("access_token=REDACTED"))
+		Expect(text).To(ContainSubstring("page=1"))
+		Expect(text).ToNot(ContainSubstring("mytoken"))
+	})
+
+	It("Redacts other credential headers", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{}`),
+		)
+
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		client := cmv1.NewClusterClient(dumper, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().
+			Header("Proxy-Authorization", "Basic myproxy").
+			Header("X-Api-Key", "mykey").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Check the output:
+		text := output.String()
+		Expect(text).To(ContainSubstring("> Proxy-Authorization: REDACTED"))
+		Expect(text).To(ContainSubstring("> X-Api-Key: REDACTED"))
+		Expect(text).ToNot(ContainSubstring("myproxy"))
+		Expect(text).ToNot(ContainSubstring("mykey"))
+	})
+
+	It("Closes the request body if it can't be read", func() {
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		body := &BrokenBody{}
+		request, err := http.NewRequest(http.MethodPost, "/clusters", body)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = dumper.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(body.Closed).To(BeTrue())
+	})
+
+	It("Closes the response body if it can't be read", func() {
+		// Create a transport that wraps a transport returning a body that can't be read:
+		body := &BrokenBody{}
+		dumper, err := helpers.NewDumpTransport(
+			TransportFunc(func(request *http.Request) (*http.Response, error) {
+				response := &http.Response{
+					Proto:      "HTTP/1.1",
+					Status:     "200 OK",
+					StatusCode: http.StatusOK,
+					Header:     http.Header{},
+					Body:       body,
+				}
+				return response, nil
+			}),
+		).
+			DumpTo(output).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Send the request:
+		request, err := http.NewRequest(http.MethodGet, "/clusters", nil)
+		Expect(err).ToNot(HaveOccurred())
+		response, err := dumper.RoundTrip(request)
+		Expect(err).To(HaveOccurred())
+		Expect(response).To(BeNil())
+		Expect(body.Closed).To(BeTrue())
+		Expect(output.String()).To(ContainSubstring("< broken body"))
+	})
+
+	It("Can be disabled and enabled for individual requests", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{}`),
+			RespondWith(http.StatusOK, `{}`),
+		)
+
+		// Create the transport:
+		dumper, err := helpers.NewDumpTransport(transport).
+			DumpTo(output).
+			Debug(false).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(dumper, "/api/clusters_mgmt/v1/clusters/123", "")
+
+		// Send a request without enabling debug:
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Len()).To(BeZero())
+
+		// Send a request enabling debug:
+		ctx := helpers.WithDebug(context.Background(), true)
+		_, err = client.Get().SendContext(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.String()).To(ContainSubstring("> GET /api/clusters_mgmt/v1/clusters/123"))
+	})
+})
@@ -0,0 +1,243 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the events.
+
+package tests
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+// clusterEventHandler is an event handler that remembers the events that it receives.
+type clusterEventHandler struct {
+	events []*cmv1.ClusterEvent
+}
+
+func (h *clusterEventHandler) HandleClusterEvent(ctx context.Context,
+	event *cmv1.ClusterEvent) error {
+	h.events = append(h.events, event)
+	return nil
+}
+
+var _ = Describe("Events", func() {
+	It("Can be built and marshalled", func() {
+		timestamp := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+		event, err := cmv1.NewClusterEvent().
+			ID("123").
+			Action(cmv1.EventActionUpdate).
+			Resource("/api/clusters_mgmt/v1/clusters/456").
+			Timestamp(timestamp).
+			Before(cmv1.NewCluster().Name("old")).
+			After(cmv1.NewCluster().Name("new")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		object, info, err := cmv1.Kinds.Unmarshal(marshalClusterEvent(event))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Kind).To(Equal(cmv1.ClusterEventKind))
+		result, ok := object.(*cmv1.ClusterEvent)
+		Expect(ok).To(BeTrue())
+		Expect(result.Action()).To(Equal(cmv1.EventActionUpdate))
+		Expect(result.Timestamp()).To(BeTemporally("==", timestamp))
+		Expect(result.Before().Name()).To(Equal("old"))
+		Expect(result.After().Name()).To(Equal("new"))
+	})
+
+	It("Dispatches event to the handler", func() {
+		handler := &clusterEventHandler{}
+		err := cmv1.DispatchEvent(context.Background(), handler, `{
+			"kind": "ClusterEvent",
+			"id": "123",
+			"action": "create",
+			"resource": "/api/clusters_mgmt/v1/clusters/456",
+			"after": {
+				"kind": "Cluster",
+				"id": "456",
+				"name": "mycluster"
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(handler.events).To(HaveLen(1))
+		event := handler.events[0]
+		Expect(event.Action()).To(Equal(cmv1.EventActionCreate))
+		Expect(event.Resource()).To(Equal("/api/clusters_mgmt/v1/clusters/456"))
+		_, ok := event.GetBefore()
+		Expect(ok).To(BeFalse())
+		Expect(event.After().Name()).To(Equal("mycluster"))
+	})
+
+	It("Publishes and receives events", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		broker := helpers.NewMemoryBroker()
+		handler := &clusterEventHandler{}
+		subscriber, err := cmv1.NewEventSubscriber().
+			Broker(broker).
+			Topic("clusters").
+			Handler(handler).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = subscriber.Start(ctx)
+		Expect(err).ToNot(HaveOccurred())
+		for _, contentType := range []string{"application/json", "application/cbor"} {
+			publisher, err := cmv1.NewEventPublisher().
+				Broker(broker).
+				Topic("clusters").
+				ContentType(contentType).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			before, err := cmv1.NewCluster().ID("123").Name("old").Build()
+			Expect(err).ToNot(HaveOccurred())
+			after, err := cmv1.NewCluster().ID("123").Name("new").Build()
+			Expect(err).ToNot(HaveOccurred())
+			err = publisher.ClusterUpdated(ctx, before, after)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		Expect(handler.events).To(HaveLen(2))
+		for _, event := range handler.events {
+			Expect(event.Action()).To(Equal(cmv1.EventActionUpdate))
+			Expect(event.Before().Name()).To(Equal("old"))
+			Expect(event.After().Name()).To(Equal("new"))
+			Expect(event.Timestamp()).ToNot(BeZero())
+		}
+	})
+
+	It("Passes the object identifier as the message key", func() {
+		var messages []*helpers.Message
+		broker := helpers.NewMemoryBroker()
+		err := broker.Subscribe(
+			context.Background(),
+			"clusters",
+			func(ctx context.Context, message *helpers.Message) error {
+				messages = append(messages, message)
+				return nil
+			},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		publisher, err := cmv1.NewEventPublisher().
+			Broker(broker).
+			Topic("clusters").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		object, err := cmv1.NewCluster().ID("123").Build()
+		Expect(err).ToNot(HaveOccurred())
+		err = publisher.ClusterDeleted(context.Background(), object)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(messages).To(HaveLen(1))
+		Expect(messages[0].Key).To(Equal("123"))
+		Expect(messages[0].Kind).To(Equal(cmv1.ClusterEventKind))
+		Expect(messages[0].ContentType).To(Equal("application/json"))
+	})
+
+	It("Stops delivering messages when the context is cancelled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		broker := helpers.NewMemoryBroker()
+		defer broker.Close()
+		received := make(chan *helpers.Message, 10)
+		err := broker.Subscribe(
+			ctx,
+			"clusters",
+			func(ctx context.Context, message *helpers.Message) error {
+				received <- message
+				return nil
+			},
+		)
+		Expect(err).ToNot(HaveOccurred())
+		cancel()
+		Eventually(func() bool {
+			err := broker.Publish(context.Background(), &helpers.Message{
+				Topic: "clusters",
+			})
+			Expect(err).ToNot(HaveOccurred())
+			select {
+			case <-received:
+				return true
+			default:
+				return false
+			}
+		}).Should(BeFalse())
+	})
+
+	It("Stops the subscription goroutines when closed", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		broker := helpers.NewMemoryBroker()
+		for i := 0; i < 10; i++ {
+			err := broker.Subscribe(
+				ctx,
+				"clusters",
+				func(ctx context.Context, message *helpers.Message) error {
+					return nil
+				},
+			)
+			Expect(err).ToNot(HaveOccurred())
+		}
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			broker.Close()
+		}()
+		Eventually(closed).Should(BeClosed())
+	})
+
+	It("Rejects subscriptions after it is closed", func() {
+		broker := helpers.NewMemoryBroker()
+		err := broker.Close()
+		Expect(err).ToNot(HaveOccurred())
+		err = broker.Subscribe(
+			context.Background(),
+			"clusters",
+			func(ctx context.Context, message *helpers.Message) error {
+				return nil
+			},
+		)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Rejects unsupported content type", func() {
+		_, err := cmv1.NewEventPublisher().
+			Broker(helpers.NewMemoryBroker()).
+			Topic("clusters").
+			ContentType("text/plain").
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Fails to dispatch objects that aren't events", func() {
+		handler := &clusterEventHandler{}
+		err := cmv1.DispatchEvent(context.Background(), handler, `{
+			"kind": "Cluster",
+			"id": "456"
+		}`)
+		Expect(err).To(HaveOccurred())
+		Expect(handler.events).To(BeEmpty())
+	})
+})
+
+func marshalClusterEvent(event *cmv1.ClusterEvent) string {
+	buffer := &strings.Builder{}
+	err := cmv1.MarshalClusterEvent(event, buffer)
+	Expect(err).ToNot(HaveOccurred())
+	return buffer.String()
+}
@@ -0,0 +1,120 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the Fetch function.
+
+package tests
+
+import (
+	"context"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+)
+
+var _ = Describe("Fetch", func() {
+	var server *Server
+	var transport http.RoundTripper
+
+	BeforeEach(func() {
+		server = NewServer()
+		transport = NewTransport(server)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Fetches object using its link", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyRequest(http.MethodGet, "/api/clusters_mgmt/v1/clusters/123"),
+				RespondWith(http.StatusOK, `{
+					"kind": "Cluster",
+					"id": "123",
+					"href": "/api/clusters_mgmt/v1/clusters/123",
+					"name": "mycluster"
+				}`),
+			),
+		)
+
+		// Fetch the object:
+		cluster, err := cmv1.Fetch[*cmv1.Cluster](
+			context.Background(),
+			transport,
+			"/api/clusters_mgmt/v1/clusters/123",
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cluster).ToNot(BeNil())
+		Expect(cluster.ID()).To(Equal("123"))
+		Expect(cluster.Name()).To(Equal("mycluster"))
+	})
+
+	It("Fetches object of the requested type", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusOK, `{
+				"kind": "Group",
+				"id": "456"
+			}`),
+		)
+
+		// Fetch the object:
+		group, err := cmv1.Fetch[*cmv1.Group](
+			context.Background(),
+			transport,
+			"/api/clusters_mgmt/v1/clusters/123/groups/456",
+		)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(group.ID()).To(Equal("456"))
+	})
+
+	It("Returns error if the server fails", func() {
+		// Prepare the server:
+		server.AppendHandlers(
+			RespondWith(http.StatusNotFound, `{
+				"kind": "Error",
+				"id": "404",
+				"href": "/api/clusters_mgmt/v1/errors/404",
+				"code": "CLUSTERS-MGMT-404",
+				"reason": "Not found"
+			}`),
+		)
+
+		// Fetch the object:
+		cluster, err := cmv1.Fetch[*cmv1.Cluster](
+			context.Background(),
+			transport,
+			"/api/clusters_mgmt/v1/clusters/123",
+		)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Not found"))
+		Expect(cluster).To(BeNil())
+	})
+
+	It("Fails if the link is empty", func() {
+		_, err := cmv1.Fetch[*cmv1.Cluster](context.Background(), transport, "")
+		Expect(err).To(HaveOccurred())
+	})
+})
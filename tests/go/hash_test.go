@@ -0,0 +1,83 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the methods that calculate the hashes of objects.
+
+package tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+)
+
+var _ = Describe("Hash", func() {
+	It("Is the same for objects with the same attributes", func() {
+		first, err := cmv1.NewCluster().
+			Name("mycluster").
+			Properties(map[string]string{
+				"a": "1",
+				"b": "2",
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		second, err := cmv1.NewCluster().
+			Properties(map[string]string{
+				"b": "2",
+				"a": "1",
+			}).
+			Name("mycluster").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.Hash()).ToNot(BeEmpty())
+		Expect(first.Hash()).To(Equal(second.Hash()))
+	})
+
+	It("Changes when an attribute changes", func() {
+		first, err := cmv1.NewCluster().
+			Name("mycluster").
+			Nodes(cmv1.NewClusterNodes().
+				Compute(10),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		second, err := cmv1.NewCluster().
+			Name("mycluster").
+			Nodes(cmv1.NewClusterNodes().
+				Compute(11),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.Hash()).ToNot(Equal(second.Hash()))
+	})
+
+	It("Distinguishes empty from unset attributes", func() {
+		first, err := cmv1.NewCluster().
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		second, err := cmv1.NewCluster().
+			Name("").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first.Hash()).ToNot(Equal(second.Hash()))
+	})
+
+	It("Can be calculated for nil object", func() {
+		var object *cmv1.Cluster
+		Expect(object.Hash()).ToNot(BeEmpty())
+	})
+})
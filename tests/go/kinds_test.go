@@ -0,0 +1,84 @@
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the registries of kinds.
+
+package tests
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+)
+
+var _ = Describe("Kinds", func() {
+	It("Contains the kinds of objects and links", func() {
+		Expect(cmv1.Kinds.Kinds()).To(ContainElement(cmv1.ClusterKind))
+		Expect(cmv1.Kinds.Kinds()).To(ContainElement(cmv1.ClusterLinkKind))
+		info := cmv1.Kinds.Lookup("Cluster")
+		Expect(info).ToNot(BeNil())
+		Expect(info.Type).To(Equal("Cluster"))
+		Expect(info.Service).To(Equal("clusters_mgmt"))
+		Expect(info.Version).To(Equal("v1"))
+		Expect(info.Link).To(BeFalse())
+	})
+
+	It("Returns nil for unknown kind", func() {
+		Expect(cmv1.Kinds.Lookup("Junk")).To(BeNil())
+	})
+
+	It("Unmarshals object selecting the type from the kind", func() {
+		object, info, err := cmv1.Kinds.Unmarshal(`{
+			"id": "123",
+			"kind": "Cluster",
+			"name": "mycluster"
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Kind).To(Equal(cmv1.ClusterKind))
+		cluster, ok := object.(*cmv1.Cluster)
+		Expect(ok).To(BeTrue())
+		Expect(cluster.ID()).To(Equal("123"))
+		Expect(cluster.Name()).To(Equal("mycluster"))
+	})
+
+	It("Unmarshals link", func() {
+		object, info, err := cmv1.Kinds.Unmarshal(strings.NewReader(`{
+			"kind": "ClusterLink",
+			"id": "123",
+			"href": "/api/clusters_mgmt/v1/clusters/123"
+		}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(info.Link).To(BeTrue())
+		cluster, ok := object.(*cmv1.Cluster)
+		Expect(ok).To(BeTrue())
+		Expect(cluster.Link()).To(BeTrue())
+		Expect(cluster.ID()).To(Equal("123"))
+	})
+
+	It("Fails for unknown kind", func() {
+		_, _, err := cmv1.Kinds.Unmarshal(`{"kind": "Junk"}`)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Junk"))
+	})
+
+	It("Fails if there is no kind", func() {
+		_, _, err := cmv1.Kinds.Unmarshal(`{"id": "123"}`)
+		Expect(err).To(HaveOccurred())
+	})
+})
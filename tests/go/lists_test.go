@@ -0,0 +1,74 @@
+//go:build go1.18
+// +build go1.18
+
+/*
+Copyright (c) 2019 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the generic list functions applied to generated lists.
+
+package tests
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	"github.com/openshift-online/ocm-api-metamodel/pkg/runtime"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+)
+
+// The generated list types don't depend on the runtime package, but they must implement the
+// generic list interface so that they can be used with its functions:
+var _ runtime.List[*cmv1.Cluster] = (*cmv1.ClusterList)(nil)
+
+var _ = Describe("Generic lists", func() {
+	var list *cmv1.ClusterList
+
+	BeforeEach(func() {
+		var err error
+		list, err = cmv1.NewClusterList().
+			Items(
+				cmv1.NewCluster().ID("123").Name("a"),
+				cmv1.NewCluster().ID("456").Name("b"),
+				cmv1.NewCluster().ID("789").Name("c"),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Maps generated list", func() {
+		ids := runtime.Map[*cmv1.Cluster](list, (*cmv1.Cluster).ID)
+		Expect(ids).To(Equal([]string{"123", "456", "789"}))
+	})
+
+	It("Filters generated list", func() {
+		items := runtime.Filter[*cmv1.Cluster](list, func(item *cmv1.Cluster) bool {
+			return item.Name() != "b"
+		})
+		Expect(items).To(HaveLen(2))
+		Expect(items[0].ID()).To(Equal("123"))
+		Expect(items[1].ID()).To(Equal("789"))
+	})
+
+	It("Reduces generated list", func() {
+		names := runtime.Reduce[*cmv1.Cluster](list, "",
+			func(result string, item *cmv1.Cluster) string {
+				return result + item.Name()
+			},
+		)
+		Expect(names).To(Equal("abc"))
+	})
+})
@@ -19,6 +19,7 @@ limitations under the License.
 package tests
 
 import (
+	"errors"
 	"net/http"
 	"testing"
 
@@ -56,3 +57,30 @@ func (t *Transport) RoundTrip(request *http.Request) (response *http.Response, e
 	response, err = t.wrapped.RoundTrip(request)
 	return
 }
+
+// TransportFunc is a function that implements the http.RoundTripper interface, used by the tests
+// that need to control exactly what the wrapped transport returns.
+type TransportFunc func(request *http.Request) (*http.Response, error)
+
+// RoundTrip implements the RoundTripper interface.
+func (f TransportFunc) RoundTrip(request *http.Request) (*http.Response, error) {
+	return f(request)
+}
+
+// BrokenBody is a request or response body that fails when it is read, and that remembers if it
+// has been closed.
+type BrokenBody struct {
+	Closed bool
+}
+
+// Read implements the io.Reader interface. It always fails.
+func (b *BrokenBody) Read(p []byte) (n int, err error) {
+	err = errors.New("broken body")
+	return
+}
+
+// Close implements the io.Closer interface.
+func (b *BrokenBody) Close() error {
+	b.Closed = true
+	return nil
+}
@@ -20,6 +20,10 @@ package tests
 
 import (
 	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -27,6 +31,7 @@ import (
 
 	amv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/accountsmgmt/v1"
 	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	cmv2 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v2"
 	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/errors"
 )
 
@@ -376,4 +381,210 @@ var _ = Describe("Marshal", func() {
 			"2019-08-15T16:17:18Z"
 		]`))
 	})
+
+	It("Writes correctly when called concurrently", func() {
+		const count = 100
+		results := make([]*bytes.Buffer, count)
+		group := &sync.WaitGroup{}
+		group.Add(count)
+		for i := 0; i < count; i++ {
+			go func(i int) {
+				defer GinkgoRecover()
+				defer group.Done()
+				object, err := cmv1.NewCluster().
+					ID(strconv.Itoa(i)).
+					Name(fmt.Sprintf("cluster-%d", i)).
+					Build()
+				Expect(err).ToNot(HaveOccurred())
+				buffer := new(bytes.Buffer)
+				err = cmv1.MarshalCluster(object, buffer)
+				Expect(err).ToNot(HaveOccurred())
+				results[i] = buffer
+			}(i)
+		}
+		group.Wait()
+		for i, result := range results {
+			Expect(result).To(MatchJSON(fmt.Sprintf(`{
+				"kind": "Cluster",
+				"id": "%d",
+				"name": "cluster-%d"
+			}`, i, i)))
+		}
+	})
+
+	It("Writes dates using the format of the attribute", func() {
+		date := time.Date(2019, time.July, 14, 15, 16, 17, 123000000, time.UTC)
+		object, err := cmv1.NewCluster().
+			InstallDate(date).
+			ProbeTimestamp(date).
+			UpgradeTimestamp(date).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"kind": "Cluster",
+			"install_date": "2019-07-14",
+			"probe_timestamp": 1563117377123,
+			"upgrade_timestamp": 1563117377
+		}`))
+	})
+
+	It("Writes long as string", func() {
+		object, err := cmv2.NewCluster().
+			ExternalID(9007199254740993).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv2.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"kind": "Cluster",
+			"external_id": "9007199254740993"
+		}`))
+	})
+
+	It("Writes interface attribute", func() {
+		object, err := cmv1.NewCluster().
+			Settings(map[string]interface{}{
+				"region": "us-east-1",
+				"zones":  []interface{}{"a", "b"},
+				"size":   3,
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"kind": "Cluster",
+			"settings": {
+				"region": "us-east-1",
+				"zones": ["a", "b"],
+				"size": 3
+			}
+		}`))
+	})
+
+	It("Preserves numbers of interface attribute", func() {
+		object, err := cmv1.UnmarshalCluster(`{
+			"settings": {
+				"big": 9007199254740993
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(ContainSubstring(`9007199254740993`))
+	})
+
+	It("Writes anonymous struct attribute", func() {
+		object, err := cmv1.NewCluster().
+			Console(cmv1.NewClusterConsole().
+				URL("https://console.example.com").
+				Enabled(true),
+			).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"kind": "Cluster",
+			"console": {
+				"url": "https://console.example.com",
+				"enabled": true
+			}
+		}`))
+	})
+
+	It("Writes empty map", func() {
+		object, err := cmv1.NewCluster().
+			Properties(map[string]string{}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"kind": "Cluster",
+			"properties": {}
+		}`))
+	})
+
+	It("Writes empty list", func() {
+		object, err := cmv1.NewGithubIdentityProvider().
+			Teams().
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalGithubIdentityProvider(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"teams": []
+		}`))
+	})
+
+	It("Writes zero value of attribute that is always written", func() {
+		object, err := cmv1.NewLDAPIdentityProvider().
+			URL("ldap://example.com").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalLDAPIdentityProvider(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"url": "ldap://example.com",
+			"insecure": false
+		}`))
+	})
+
+	It("Writes value of attribute that is always written", func() {
+		object, err := cmv1.NewLDAPIdentityProvider().
+			Insecure(true).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalLDAPIdentityProvider(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer).To(MatchJSON(`{
+			"insecure": true
+		}`))
+	})
+
+	It("Writes canonical JSON", func() {
+		// The Makefile runs the tests also with code generated without the canonical mode:
+		if os.Getenv("JSON_MODE") == "default" {
+			Skip("Code has been generated without the canonical JSON mode")
+		}
+		object, err := cmv1.NewCluster().
+			Name("mycluster").
+			ID("123").
+			Nodes(cmv1.NewClusterNodes().
+				Infra(2).
+				Compute(10),
+			).
+			Properties(map[string]string{
+				"b": "2",
+				"a": "1",
+			}).
+			Managed(true).
+			Factor(0.0000001).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		buffer := new(bytes.Buffer)
+		err = cmv1.MarshalCluster(object, buffer)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(buffer.String()).To(Equal(`{` +
+			`"factor":1e-7,` +
+			`"id":"123",` +
+			`"kind":"Cluster",` +
+			`"managed":true,` +
+			`"name":"mycluster",` +
+			`"nodes":{"compute":10,"infra":2},` +
+			`"properties":{"a":"1","b":"2"}` +
+			`}`))
+	})
 })
@@ -19,10 +19,18 @@ limitations under the License.
 package tests
 
 import (
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
+	"sync"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -32,6 +40,8 @@ import (
 	az "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/authorizations"
 	cm "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt"
 	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	cmv2 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v2"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
 )
 
 var _ = Describe("Server", func() {
@@ -182,30 +192,2325 @@ var _ = Describe("Server", func() {
 		})
 	})
 
+	Describe("Dry run", func() {
+		It("Is false by default", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				Expect(request.DryRun()).To(BeFalse())
+				_, ok := request.GetDryRun()
+				Expect(ok).To(BeFalse())
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("Is read from the query", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				Expect(request.DryRun()).To(BeTrue())
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123?dry_run=true",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+	})
+
+	Describe("Idempotency key", func() {
+		It("Is empty by default", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				Expect(request.IdempotencyKey()).To(BeEmpty())
+				_, ok := request.GetIdempotencyKey()
+				Expect(ok).To(BeFalse())
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("Is read from the header", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				Expect(request.IdempotencyKey()).To(Equal("mykey"))
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{}`),
+			)
+			request.Header.Set("Idempotency-Key", "mykey")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("Sends the saved response for retries", func() {
+			// Prepare the server:
+			calls := 0
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				calls++
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				return nil
+			}
+			adapter.Idempotency(&MyIdempotencyStore{}, myIdempotencyScope)
+
+			// Send the request twice:
+			for i := 0; i < 2; i++ {
+				recorder = httptest.NewRecorder()
+				request := httptest.NewRequest(
+					http.MethodPost,
+					"/clusters_mgmt/v1/clusters",
+					strings.NewReader(`{}`),
+				)
+				request.Header.Set("Idempotency-Key", "mykey")
+				request.Header.Set("X-User", "myuser")
+				adapter.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+				Expect(recorder.Body).To(MatchJSON(`{
+					"kind": "Cluster",
+					"name": "mycluster"
+				}`))
+			}
+			Expect(calls).To(Equal(1))
+		})
+
+		It("Doesn't save the operation identifier of the first request", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				return nil
+			}
+			adapter.Idempotency(&MyIdempotencyStore{}, myIdempotencyScope)
+
+			// Send the request twice with different operation identifiers:
+			for _, operationID := range []string{"first", "second"} {
+				recorder = httptest.NewRecorder()
+				request := httptest.NewRequest(
+					http.MethodPost,
+					"/clusters_mgmt/v1/clusters",
+					strings.NewReader(`{}`),
+				)
+				request.Header.Set("Idempotency-Key", "mykey")
+				request.Header.Set("X-User", "myuser")
+				request.Header.Set("X-Operation-ID", operationID)
+				adapter.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+				Expect(recorder.Header().Values("X-Operation-ID")).To(ConsistOf(operationID))
+			}
+		})
+
+		It("Doesn't send the saved response to other principals", func() {
+			// Prepare the server:
+			calls := 0
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				calls++
+				return nil
+			}
+			adapter.Idempotency(&MyIdempotencyStore{}, myIdempotencyScope)
+
+			// Send the same request for two different users:
+			for _, user := range []string{"alice", "bob"} {
+				recorder = httptest.NewRecorder()
+				request := httptest.NewRequest(
+					http.MethodPost,
+					"/clusters_mgmt/v1/clusters",
+					strings.NewReader(`{}`),
+				)
+				request.Header.Set("Idempotency-Key", "mykey")
+				request.Header.Set("X-User", user)
+				adapter.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+			}
+			Expect(calls).To(Equal(2))
+		})
+
+		It("Doesn't deduplicate requests without principal", func() {
+			// Prepare the server:
+			calls := 0
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				calls++
+				return nil
+			}
+			adapter.Idempotency(&MyIdempotencyStore{}, myIdempotencyScope)
+
+			// Send the request twice:
+			for i := 0; i < 2; i++ {
+				recorder = httptest.NewRecorder()
+				request := httptest.NewRequest(
+					http.MethodPost,
+					"/clusters_mgmt/v1/clusters",
+					strings.NewReader(`{}`),
+				)
+				request.Header.Set("Idempotency-Key", "mykey")
+				adapter.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+			}
+			Expect(calls).To(Equal(2))
+		})
+
+		It("Rejects retries while the first request is in progress", func() {
+			// Prepare a server that blocks till the second request has been answered:
+			started := make(chan struct{})
+			release := make(chan struct{})
+			calls := 0
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				calls++
+				close(started)
+				<-release
+				return nil
+			}
+			adapter.Idempotency(&MyIdempotencyStore{}, myIdempotencyScope)
+
+			// Send the first request in the background:
+			first := httptest.NewRecorder()
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				request := httptest.NewRequest(
+					http.MethodPost,
+					"/clusters_mgmt/v1/clusters",
+					strings.NewReader(`{}`),
+				)
+				request.Header.Set("Idempotency-Key", "mykey")
+				request.Header.Set("X-User", "myuser")
+				adapter.ServeHTTP(first, request)
+			}()
+			<-started
+
+			// Send the retry while the first is still in progress:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{}`),
+			)
+			request.Header.Set("Idempotency-Key", "mykey")
+			request.Header.Set("X-User", "myuser")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusConflict))
+
+			// Let the first request finish:
+			close(release)
+			<-done
+			Expect(first.Code).To(Equal(http.StatusCreated))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("Uses the actor as the default scope", func() {
+			// Prepare the server:
+			calls := 0
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				calls++
+				return nil
+			}
+
+			// Create the adapter with middleware that stores the actor in the context:
+			authenticate := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					actor := r.Header.Get("X-User")
+					if actor != "" {
+						r = r.WithContext(helpers.WithActor(r.Context(), actor))
+					}
+					next.ServeHTTP(w, r)
+				})
+			}
+			adapter = generated.NewAdapter(server, authenticate).
+				Idempotency(&MyIdempotencyStore{}, nil)
+
+			// Send the request twice with an actor, and twice without it:
+			for _, user := range []string{"myuser", "myuser", "", ""} {
+				recorder = httptest.NewRecorder()
+				request := httptest.NewRequest(
+					http.MethodPost,
+					"/clusters_mgmt/v1/clusters",
+					strings.NewReader(`{}`),
+				)
+				request.Header.Set("Idempotency-Key", "mykey")
+				request.Header.Set("X-User", user)
+				adapter.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusCreated))
+			}
+			Expect(calls).To(Equal(3))
+		})
+	})
+
+	Describe("Validation", func() {
+		It("Accepts body that satisfies the constraints", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				Expect(request.Body().Name()).To(Equal("my-cluster"))
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{
+					"name": "my-cluster",
+					"nodes": {
+						"compute": 10
+					}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("Rejects body that doesn't satisfy the constraints", func() {
+			// Prepare the server:
+			called := false
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				called = true
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{
+					"name": "My Cluster",
+					"nodes": {
+						"compute": -1
+					}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("'name'"))
+			Expect(recorder.Body.String()).To(ContainSubstring("'nodes.compute'"))
+			Expect(called).To(BeFalse())
+		})
+
+		It("Rejects body that doesn't satisfy the registered validator", func() {
+			// Prepare the server:
+			cmv1.SetClusterValidator(func(object *cmv1.Cluster) error {
+				if object.MultiAZ() && object.Nodes().Compute() < 3 {
+					return errors.New("multi AZ clusters need at least 3 compute nodes")
+				}
+				return nil
+			})
+			defer cmv1.SetClusterValidator(nil)
+			called := false
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				called = true
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{
+					"multi_az": true,
+					"nodes": {
+						"compute": 2
+					}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("at least 3 compute nodes"))
+			Expect(called).To(BeFalse())
+		})
+
+		It("Ignores read only attributes", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.add = func(
+				ctx context.Context,
+				request *cmv1.ClustersAddServerRequest,
+				response *cmv1.ClustersAddServerResponse,
+			) error {
+				body := request.Body()
+				Expect(body.Name()).To(Equal("my-cluster"))
+				_, ok := body.GetState()
+				Expect(ok).To(BeFalse())
+				_, ok = body.GetCreationTimestamp()
+				Expect(ok).To(BeFalse())
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters",
+				strings.NewReader(`{
+					"name": "my-cluster",
+					"state": "ready",
+					"creation_timestamp": "2019-07-12T17:12:57Z"
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		})
+
+		It("Rejects bulk add items that don't satisfy the constraints", func() {
+			// Prepare the server:
+			called := false
+			server.clustersMgmt.v1.clusters.bulkAdd = func(
+				ctx context.Context,
+				request *cmv1.ClustersBulkAddServerRequest,
+				response *cmv1.ClustersBulkAddServerResponse,
+			) error {
+				called = true
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters/bulk_add",
+				strings.NewReader(`{
+					"items": [
+						{
+							"name": "my-cluster"
+						},
+						{
+							"name": "Your Cluster"
+						}
+					]
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("'items[1].name'"))
+			Expect(called).To(BeFalse())
+		})
+
+		It("Ignores read only attributes of bulk add items", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.bulkAdd = func(
+				ctx context.Context,
+				request *cmv1.ClustersBulkAddServerRequest,
+				response *cmv1.ClustersBulkAddServerResponse,
+			) error {
+				items := request.Items()
+				Expect(items).To(HaveLen(1))
+				Expect(items[0].Name()).To(Equal("my-cluster"))
+				_, ok := items[0].GetState()
+				Expect(ok).To(BeFalse())
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters/bulk_add",
+				strings.NewReader(`{
+					"items": [
+						{
+							"name": "my-cluster",
+							"state": "ready"
+						}
+					]
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Accepts update that doesn't change immutable attributes", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.update = func(
+				ctx context.Context,
+				request *cmv1.ClusterUpdateServerRequest,
+				response *cmv1.ClusterUpdateServerResponse,
+			) error {
+				Expect(request.Body().DisplayName()).To(Equal("My cluster"))
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"display_name": "My cluster"
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("Accepts update that sends the current value of immutable attributes", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.update = func(
+				ctx context.Context,
+				request *cmv1.ClusterUpdateServerRequest,
+				response *cmv1.ClusterUpdateServerResponse,
+			) error {
+				current, err := cmv1.NewCluster().
+					Name("my-cluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				return request.CheckImmutable(current)
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"name": "my-cluster",
+					"display_name": "My cluster"
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		})
+
+		It("Rejects update that changes immutable attributes", func() {
+			// Prepare the server:
+			updated := false
+			server.clustersMgmt.v1.clusters.cluster.update = func(
+				ctx context.Context,
+				request *cmv1.ClusterUpdateServerRequest,
+				response *cmv1.ClusterUpdateServerResponse,
+			) error {
+				current, err := cmv1.NewCluster().
+					Name("my-cluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				err = request.CheckImmutable(current)
+				if err != nil {
+					return err
+				}
+				updated = true
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"name": "your-cluster"
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("'name'"))
+			Expect(recorder.Body.String()).To(ContainSubstring("can't be changed"))
+			Expect(updated).To(BeFalse())
+		})
+
+		It("Rejects changes to immutable attributes before calling the server", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.current = func(
+				ctx context.Context,
+			) (*cmv1.Cluster, error) {
+				return cmv1.NewCluster().
+					Name("my-cluster").
+					Build()
+			}
+			server.clustersMgmt.v1.clusters.cluster.update = func(
+				ctx context.Context,
+				request *cmv1.ClusterUpdateServerRequest,
+				response *cmv1.ClusterUpdateServerResponse,
+			) error {
+				Fail("Server shouldn't be called")
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"name": "your-cluster"
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("'name'"))
+			Expect(recorder.Body.String()).To(ContainSubstring("can't be changed"))
+		})
+
+		It("Compares immutable attributes by their values", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.current = func(
+				ctx context.Context,
+			) (*cmv1.Cluster, error) {
+				return cmv1.NewCluster().
+					Name("my-cluster").
+					Nodes(cmv1.NewClusterNodes().Compute(3)).
+					Build()
+			}
+			updated := false
+			server.clustersMgmt.v1.clusters.cluster.update = func(
+				ctx context.Context,
+				request *cmv1.ClusterUpdateServerRequest,
+				response *cmv1.ClusterUpdateServerResponse,
+			) error {
+				updated = true
+				return nil
+			}
+
+			// Send the request with an equal copy of the nodes and with an empty map of
+			// properties, which is equal to the current properties that have no value:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"name": "my-cluster",
+					"nodes": {
+						"compute": 3
+					},
+					"properties": {}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(updated).To(BeTrue())
+		})
+
+		It("Rejects changes to immutable maps", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.current = func(
+				ctx context.Context,
+			) (*cmv1.Cluster, error) {
+				return cmv1.NewCluster().
+					Properties(map[string]string{
+						"owner": "me",
+					}).
+					Build()
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"properties": {
+						"owner": "you"
+					}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("'properties'"))
+		})
+
+		It("Sends 500 if the current object can't be retrieved", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.current = func(
+				ctx context.Context,
+			) (*cmv1.Cluster, error) {
+				return nil, fmt.Errorf("database is down")
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPatch,
+				"/clusters_mgmt/v1/clusters/123",
+				strings.NewReader(`{
+					"name": "my-cluster"
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	It("Reports the results of a bulk method", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.bulkAdd = func(
+			ctx context.Context,
+			request *cmv1.ClustersBulkAddServerRequest,
+			response *cmv1.ClustersBulkAddServerResponse,
+		) error {
+			items := request.Items()
+			Expect(items).To(HaveLen(2))
+			Expect(items[0].Name()).To(Equal("mycluster"))
+			Expect(items[1].Name()).To(Equal("yourcluster"))
+			created, err := cmv1.NewBulkResult().
+				Index(0).
+				ID("123").
+				Status(http.StatusCreated).
+				Build()
+			if err != nil {
+				return err
+			}
+			conflict, err := cmv1.NewBulkResult().
+				Index(1).
+				Status(http.StatusConflict).
+				Reason("Cluster 'yourcluster' already exists").
+				Build()
+			if err != nil {
+				return err
+			}
+			response.Results([]*cmv1.BulkResult{created, conflict})
+			return nil
+		}
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/clusters_mgmt/v1/clusters/bulk_add",
+			strings.NewReader(`{
+				"items": [
+					{
+						"name": "mycluster"
+					},
+					{
+						"name": "yourcluster"
+					}
+				]
+			}`),
+		)
+		adapter.ServeHTTP(recorder, request)
+
+		// Verify the response:
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"results": [
+				{
+					"index": 0,
+					"id": "123",
+					"status": 201
+				},
+				{
+					"index": 1,
+					"status": 409,
+					"reason": "Cluster 'yourcluster' already exists"
+				}
+			]
+		}`))
+	})
+
+	It("Reports the partial failure of a bulk delete", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.bulkDelete = func(
+			ctx context.Context,
+			request *cmv1.ClustersBulkDeleteServerRequest,
+			response *cmv1.ClustersBulkDeleteServerResponse,
+		) error {
+			Expect(request.Search()).To(Equal("name like 'my%'"))
+			deleted, err := cmv1.NewBulkResult().
+				Index(0).
+				ID("123").
+				Status(http.StatusNoContent).
+				Build()
+			if err != nil {
+				return err
+			}
+			forbidden, err := cmv1.NewBulkResult().
+				Index(1).
+				ID("456").
+				Status(http.StatusForbidden).
+				Reason("Cluster '456' can't be deleted").
+				Build()
+			if err != nil {
+				return err
+			}
+			response.Results([]*cmv1.BulkResult{deleted, forbidden})
+			return nil
+		}
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/clusters_mgmt/v1/clusters/bulk_delete",
+			strings.NewReader(`{
+				"search": "name like 'my%'"
+			}`),
+		)
+		adapter.ServeHTTP(recorder, request)
+
+		// Verify the response:
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"results": [
+				{
+					"index": 0,
+					"id": "123",
+					"status": 204
+				},
+				{
+					"index": 1,
+					"id": "456",
+					"status": 403,
+					"reason": "Cluster '456' can't be deleted"
+				}
+			]
+		}`))
+	})
+
+	Describe("Content negotiation", func() {
+		BeforeEach(func() {
+			server.clustersMgmt.v1.clusters.cluster.get = func(
+				ctx context.Context,
+				request *cmv1.ClusterGetServerRequest,
+				response *cmv1.ClusterGetServerResponse,
+			) error {
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Nodes(cmv1.NewClusterNodes().
+						Compute(10),
+					).
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				return nil
+			}
+		})
+
+		It("Sends JSON if the request doesn't have an Accept header", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"name": "mycluster",
+				"nodes": {
+					"compute": 10
+				}
+			}`))
+		})
+
+		It("Sends YAML if the client prefers it", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept", "application/json;q=0.5, application/yaml")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/yaml"))
+			Expect(recorder.Body.String()).To(Equal(
+				"\"kind\": \"Cluster\"\n" +
+					"\"name\": \"mycluster\"\n" +
+					"\"nodes\":\n" +
+					"  \"compute\": 10\n",
+			))
+		})
+
+		It("Sends JSON if the client accepts any media type", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept", "*/*")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		})
+
+		It("Sends 406 if the client doesn't accept any supported media type", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept", "text/html")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotAcceptable))
+			Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+			Expect(recorder.Body.String()).To(ContainSubstring(`"406"`))
+		})
+	})
+
+	Describe("HEAD and OPTIONS", func() {
+		It("Answers HEAD like GET but without body", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.get = func(
+				ctx context.Context,
+				request *cmv1.ClusterGetServerRequest,
+				response *cmv1.ClusterGetServerResponse,
+			) error {
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodHead,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+
+		It("Advertises the allowed methods of a resource", func() {
+			request := httptest.NewRequest(
+				http.MethodOptions,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Header().Get("Allow")).To(Equal("DELETE, GET, HEAD, PATCH, OPTIONS"))
+		})
+
+		It("Advertises the allowed methods of an action", func() {
+			request := httptest.NewRequest(
+				http.MethodOptions,
+				"/clusters_mgmt/v1/clusters/bulk_add",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Header().Get("Allow")).To(Equal("POST, OPTIONS"))
+		})
+	})
+
+	It("Returns a 405 with the allowed methods for a wrong method", func() {
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/clusters_mgmt/v1/clusters/123",
+			strings.NewReader(`{}`),
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(recorder.Header().Get("Allow")).To(Equal("DELETE, GET, HEAD, PATCH, OPTIONS"))
+	})
+
+	It("Returns a 405 with the allowed methods for a wrong action method", func() {
+		request := httptest.NewRequest(
+			http.MethodGet,
+			"/clusters_mgmt/v1/clusters/bulk_add",
+			nil,
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(recorder.Header().Get("Allow")).To(Equal("POST, OPTIONS"))
+	})
+
 	It("Returns the list of clusters with a trailing slash", func() {
 		// Prepare the server:
-		server.clustersMgmt.v1.clusters.list = func(
+		server.clustersMgmt.v1.clusters.list = func(
+			ctx context.Context,
+			request *cmv1.ClustersListServerRequest,
+			response *cmv1.ClustersListServerResponse,
+		) error {
+			items, err := cmv1.NewClusterList().Build()
+			if err != nil {
+				return err
+			}
+			response.Items(items)
+			response.Page(1)
+			response.Size(0)
+			response.Total(0)
+			return nil
+		}
+
+		// Send the request:
+		request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1/clusters/", nil)
+		adapter.ServeHTTP(recorder, request)
+
+		// Verify the response:
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("Redirects paths with a trailing slash if configured", func() {
+		adapter.TrailingSlash(generated.TrailingSlashRedirect)
+		request := httptest.NewRequest(
+			http.MethodGet,
+			"/clusters_mgmt/v1/clusters/?page=2",
+			nil,
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusPermanentRedirect))
+		Expect(recorder.Header().Get("Location")).To(Equal("/clusters_mgmt/v1/clusters?page=2"))
+	})
+
+	It("Redirects to the original path when mounted with a prefix", func() {
+		adapter.TrailingSlash(generated.TrailingSlashRedirect)
+		handler := http.StripPrefix("/api", adapter)
+		request := httptest.NewRequest(
+			http.MethodGet,
+			"/api/clusters_mgmt/v1/clusters/?page=2",
+			nil,
+		)
+		handler.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusPermanentRedirect))
+		Expect(recorder.Header().Get("Location")).To(Equal(
+			"/api/clusters_mgmt/v1/clusters?page=2",
+		))
+	})
+
+	It("Doesn't redirect to a different host", func() {
+		adapter.TrailingSlash(generated.TrailingSlashRedirect)
+		request := httptest.NewRequest(http.MethodGet, "//evil.example/", nil)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusPermanentRedirect))
+		Expect(recorder.Header().Get("Location")).To(Equal("/evil.example"))
+	})
+
+	Describe("Middleware and interceptors", func() {
+		It("Applies middleware in order", func() {
+			// Create the adapter with middleware that records the order:
+			var order []string
+			record := func(name string) func(http.Handler) http.Handler {
+				return func(next http.Handler) http.Handler {
+					return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+						order = append(order, name)
+						next.ServeHTTP(w, r)
+					})
+				}
+			}
+			adapter = generated.NewAdapter(server, record("first"), record("second"))
+
+			// Send the request:
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(order).To(Equal([]string{"first", "second"}))
+		})
+
+		It("Passes the typed request to interceptors", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				return nil
+			}
+
+			// Add an interceptor that checks the request:
+			called := false
+			adapter.Intercept(func(ctx context.Context, request, response interface{},
+				next func(ctx context.Context) error) error {
+				switch typed := request.(type) {
+				case *cmv1.ClusterDeleteServerRequest:
+					called = true
+					Expect(typed.Reason()).To(Equal("yourreason"))
+				}
+				return next(ctx)
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123?reason=yourreason",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(called).To(BeTrue())
+		})
+
+		It("Doesn't call the server if an interceptor fails", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				Fail("Server shouldn't be called")
+				return nil
+			}
+
+			// Add an interceptor that rejects the request:
+			adapter.Intercept(func(ctx context.Context, request, response interface{},
+				next func(ctx context.Context) error) error {
+				return fmt.Errorf("rejected")
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		})
+
+		It("Sends 501 if an interceptor wraps the not implemented error", func() {
+			// Add an interceptor that wraps the error:
+			adapter.Intercept(func(ctx context.Context, request, response interface{},
+				next func(ctx context.Context) error) error {
+				return fmt.Errorf("can't delete cluster: %w", helpers.ErrNotImplemented)
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+		})
+
+		It("Sends 400 if an interceptor wraps validation errors", func() {
+			// Add an interceptor that wraps the error:
+			adapter.Intercept(func(ctx context.Context, request, response interface{},
+				next func(ctx context.Context) error) error {
+				var errs helpers.ValidationErrors
+				errs.Add("name", "is mandatory")
+				return fmt.Errorf("can't delete cluster: %w", errs)
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+			Expect(recorder.Body.String()).To(ContainSubstring("field 'name' is mandatory"))
+		})
+	})
+
+	It("Stores the route in the context", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.cluster.del = func(
+			ctx context.Context,
+			request *cmv1.ClusterDeleteServerRequest,
+			response *cmv1.ClusterDeleteServerResponse,
+		) error {
+			route := helpers.RouteFromContext(ctx)
+			Expect(route).ToNot(BeNil())
+			Expect(route.Service).To(Equal("clusters_mgmt"))
+			Expect(route.Version).To(Equal("v1"))
+			Expect(route.Resource).To(Equal("cluster"))
+			Expect(route.Method).To(Equal("delete"))
+			Expect(route.Variables).To(HaveKeyWithValue("cluster", "123"))
+			return nil
+		}
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodDelete,
+			"/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+	})
+
+	It("Recovers from panics", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.cluster.del = func(
+			ctx context.Context,
+			request *cmv1.ClusterDeleteServerRequest,
+			response *cmv1.ClusterDeleteServerResponse,
+		) error {
+			panic("mypanic")
+		}
+
+		// Count the panics:
+		var panics []interface{}
+		adapter.PanicHandler(func(r *http.Request, value interface{}) {
+			panics = append(panics, value)
+		})
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodDelete,
+			"/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(panics).To(Equal([]interface{}{"mypanic"}))
+	})
+
+	Describe("Operation identifier", func() {
+		It("Is generated if the client doesn't send it", func() {
+			// Prepare the server:
+			var operationID string
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				operationID = helpers.OperationIDFromContext(ctx)
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(operationID).ToNot(BeEmpty())
+			Expect(recorder.Header().Get("X-Operation-ID")).To(Equal(operationID))
+		})
+
+		It("Is propagated from the request", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				Expect(helpers.OperationIDFromContext(ctx)).To(Equal("myid"))
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("X-Operation-ID", "myid")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(recorder.Header().Get("X-Operation-ID")).To(Equal("myid"))
+		})
+
+		It("Is included in error bodies", func() {
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			request.Header.Set("X-Operation-ID", "myid")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Error",
+				"id": "404",
+				"reason": "Can't find resource for path '/foo''",
+				"operation_id": "myid"
+			}`))
+		})
+	})
+
+	It("Doesn't recover from abort panics", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.cluster.del = func(
+			ctx context.Context,
+			request *cmv1.ClusterDeleteServerRequest,
+			response *cmv1.ClusterDeleteServerResponse,
+		) error {
+			panic(http.ErrAbortHandler)
+		}
+
+		// Count the panics:
+		var panics []interface{}
+		adapter.PanicHandler(func(r *http.Request, value interface{}) {
+			panics = append(panics, value)
+		})
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodDelete,
+			"/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		Expect(func() {
+			adapter.ServeHTTP(recorder, request)
+		}).To(Panic())
+		Expect(panics).To(BeEmpty())
+	})
+
+	It("Aborts the response if it was started before the panic", func() {
+		// Prepare a fallback that starts the response and then panics:
+		adapter.Fallback(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			panic("mypanic")
+		}))
+
+		// Send the request:
+		request := httptest.NewRequest(http.MethodGet, "/accounts_mgmt/v1/accounts", nil)
+		var value interface{}
+		func() {
+			defer func() {
+				value = recover()
+			}()
+			adapter.ServeHTTP(recorder, request)
+		}()
+		Expect(value).To(Equal(http.ErrAbortHandler))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("Rejects request bodies larger than the limit", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.add = func(
+			ctx context.Context,
+			request *cmv1.ClustersAddServerRequest,
+			response *cmv1.ClustersAddServerResponse,
+		) error {
+			Fail("Server shouldn't be called")
+			return nil
+		}
+		adapter.MaxBodySize(10)
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/clusters_mgmt/v1/clusters",
+			strings.NewReader(`{
+				"name": "mycluster"
+			}`),
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusRequestEntityTooLarge))
+	})
+
+	Describe("Impersonation", func() {
+		It("Gives access to the impersonated user and groups", func() {
+			// Prepare the server:
+			var user string
+			var groups []string
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				user = request.ImpersonatedUser()
+				groups = request.ImpersonatedGroups()
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Impersonate-User", "myuser")
+			request.Header.Add("Impersonate-Group", "mygroup1")
+			request.Header.Add("Impersonate-Group", "mygroup2")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(user).To(Equal("myuser"))
+			Expect(groups).To(ConsistOf("mygroup1", "mygroup2"))
+		})
+
+		It("Ignores groups if there is no user", func() {
+			// Prepare the server:
+			var groups []string
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				groups = request.ImpersonatedGroups()
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Impersonate-Group", "mygroup")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(groups).To(BeEmpty())
+		})
+	})
+
+	Describe("Last modified", func() {
+		var modified time.Time
+
+		BeforeEach(func() {
+			modified = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+			server.clustersMgmt.v1.clusters.cluster.get = func(
+				ctx context.Context,
+				request *cmv1.ClusterGetServerRequest,
+				response *cmv1.ClusterGetServerResponse,
+			) error {
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				response.LastModified(modified)
+				return nil
+			}
+		})
+
+		It("Sends the header", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Last-Modified")).To(Equal(
+				"Thu, 02 Jan 2020 03:04:05 GMT",
+			))
+		})
+
+		It("Returns 304 if not modified", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("If-Modified-Since", "Thu, 02 Jan 2020 03:04:05 GMT")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotModified))
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+
+		It("Returns the data if modified", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("If-Modified-Since", "Wed, 01 Jan 2020 00:00:00 GMT")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"name": "mycluster"
+			}`))
+		})
+	})
+
+	Describe("CORS", func() {
+		// newCORS creates the CORS handler and checks that there are no errors:
+		newCORS := func(config *generated.CORSConfig) *generated.CORSHandler {
+			handler, err := generated.NewCORSHandler(config)
+			Expect(err).ToNot(HaveOccurred())
+			return handler
+		}
+
+		BeforeEach(func() {
+			adapter.CORS(newCORS(&generated.CORSConfig{
+				AllowedOrigins: []string{"https://example.com"},
+				ExposedHeaders: []string{"X-Operation-ID"},
+				MaxAge:         time.Hour,
+			}))
+		})
+
+		It("Answers preflight requests", func() {
+			request := httptest.NewRequest(
+				http.MethodOptions,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Origin", "https://example.com")
+			request.Header.Set("Access-Control-Request-Method", "DELETE")
+			request.Header.Set("Access-Control-Request-Headers", "Authorization")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			header := recorder.Header()
+			Expect(header.Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+			Expect(header.Get("Access-Control-Allow-Methods")).To(ContainSubstring("DELETE"))
+			Expect(header.Get("Access-Control-Allow-Headers")).To(Equal("Authorization"))
+			Expect(header.Get("Access-Control-Max-Age")).To(Equal("3600"))
+		})
+
+		It("Answers preflight requests before the middleware", func() {
+			// Create the adapter with middleware that rejects requests without
+			// credentials:
+			authenticate := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					if r.Header.Get("Authorization") == "" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					next.ServeHTTP(w, r)
+				})
+			}
+			adapter = generated.NewAdapter(server, authenticate).CORS(newCORS(
+				&generated.CORSConfig{
+					AllowedOrigins: []string{"https://example.com"},
+				},
+			))
+
+			// Send the preflight request, without credentials:
+			request := httptest.NewRequest(
+				http.MethodOptions,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Origin", "https://example.com")
+			request.Header.Set("Access-Control-Request-Method", "DELETE")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			header := recorder.Header()
+			Expect(header.Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+			Expect(header.Get("Access-Control-Allow-Methods")).To(ContainSubstring("DELETE"))
+		})
+
+		It("Adds headers to regular requests", func() {
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			request.Header.Set("Origin", "https://example.com")
+			adapter.ServeHTTP(recorder, request)
+			header := recorder.Header()
+			Expect(header.Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+			Expect(header.Get("Access-Control-Expose-Headers")).To(Equal("X-Operation-ID"))
+		})
+
+		It("Ignores origins that aren't allowed", func() {
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			request.Header.Set("Origin", "https://example.org")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+
+		It("Ignores origins that only match partially", func() {
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			request.Header.Set("Origin", "https://example.com.evil.org")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Access-Control-Allow-Origin")).To(BeEmpty())
+		})
+
+		It("Sends wildcard origin without credentials", func() {
+			adapter.CORS(newCORS(&generated.CORSConfig{
+				AllowedOrigins: []string{"*"},
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			request.Header.Set("Origin", "https://example.org")
+			adapter.ServeHTTP(recorder, request)
+			header := recorder.Header()
+			Expect(header.Get("Access-Control-Allow-Origin")).To(Equal("*"))
+			Expect(header.Get("Access-Control-Allow-Credentials")).To(BeEmpty())
+		})
+
+		It("Sends credentials only for exact origins", func() {
+			adapter.CORS(newCORS(&generated.CORSConfig{
+				AllowedOrigins:   []string{"https://example.com"},
+				AllowCredentials: true,
+			}))
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			request.Header.Set("Origin", "https://example.com")
+			adapter.ServeHTTP(recorder, request)
+			header := recorder.Header()
+			Expect(header.Get("Access-Control-Allow-Origin")).To(Equal("https://example.com"))
+			Expect(header.Get("Access-Control-Allow-Credentials")).To(Equal("true"))
+		})
+
+		It("Rejects wildcard origin with credentials", func() {
+			handler, err := generated.NewCORSHandler(&generated.CORSConfig{
+				AllowedOrigins:   []string{"*"},
+				AllowCredentials: true,
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(handler).To(BeNil())
+		})
+	})
+
+	Describe("Compression", func() {
+		BeforeEach(func() {
+			server.clustersMgmt.v1.clusters.cluster.get = func(
+				ctx context.Context,
+				request *cmv1.ClusterGetServerRequest,
+				response *cmv1.ClusterGetServerResponse,
+			) error {
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				return nil
+			}
+		})
+
+		It("Compresses responses larger than the threshold", func() {
+			// Send the request:
+			adapter.Compression(10)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept-Encoding", "gzip")
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			reader, err := gzip.NewReader(recorder.Body)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"name": "mycluster"
+			}`))
+		})
+
+		It("Doesn't compress responses smaller than the threshold", func() {
+			// Send the request:
+			adapter.Compression(1000)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept-Encoding", "gzip")
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Cluster",
+				"name": "mycluster"
+			}`))
+		})
+
+		It("Doesn't compress if the client doesn't support it", func() {
+			// Send the request:
+			adapter.Compression(10)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+		})
+
+		It("Doesn't compress if the client rejects gzip explicitly", func() {
+			// Send the request:
+			adapter.Compression(10)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept-Encoding", "gzip;q=0, identity")
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+		})
+
+		It("Doesn't compress if the client only supports other codings", func() {
+			// Send the request:
+			adapter.Compression(10)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept-Encoding", "x-gzip, deflate")
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Encoding")).To(BeEmpty())
+		})
+
+		It("Compresses if gzip has a quality value greater than zero", func() {
+			// Send the request:
+			adapter.Compression(10)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("Accept-Encoding", "deflate, GZIP ; q=0.5")
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+		})
+
+		It("Supports flushing compressed responses", func() {
+			// Prepare a fallback that flushes the response before reaching the
+			// threshold:
+			adapter.Compression(1000)
+			adapter.Fallback(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, err := w.Write([]byte(`{"name": "mycluster"}`))
+				Expect(err).ToNot(HaveOccurred())
+				flusher, ok := w.(http.Flusher)
+				Expect(ok).To(BeTrue())
+				flusher.Flush()
+			}))
+
+			// Send the request:
+			request := httptest.NewRequest(http.MethodGet, "/accounts_mgmt/v1/accounts", nil)
+			request.Header.Set("Accept-Encoding", "gzip")
+			adapter.ServeHTTP(recorder, request)
+
+			// Verify the response:
+			Expect(recorder.Flushed).To(BeTrue())
+			Expect(recorder.Header().Get("Content-Encoding")).To(Equal("gzip"))
+			reader, err := gzip.NewReader(recorder.Body)
+			Expect(err).ToNot(HaveOccurred())
+			body, err := ioutil.ReadAll(reader)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(body).To(MatchJSON(`{"name": "mycluster"}`))
+		})
+	})
+
+	Describe("Cancellation", func() {
+		It("Returns 499 when the server returns context canceled", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				return context.Canceled
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(499))
+		})
+
+		It("Returns 504 when the server returns deadline exceeded", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				return context.DeadlineExceeded
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		})
+
+		It("Returns 504 when the server returns a wrapped deadline exceeded", func() {
+			// Prepare the server so that it returns the error that the HTTP client returns
+			// when the deadline of a request to another service expires:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				return &url.Error{
+					Op:  http.MethodGet,
+					URL: "https://api.example.com/api/accounts_mgmt/v1/current_account",
+					Err: context.DeadlineExceeded,
+				}
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		})
+
+		It("Doesn't write the response if the client aborted", func() {
+			// Prepare the server so that it simulates the client closing the connection
+			// while the request is being processed:
+			ctx, cancel := context.WithCancel(context.Background())
+			server.clustersMgmt.v1.clusters.cluster.get = func(
+				ctx context.Context,
+				request *cmv1.ClusterGetServerRequest,
+				response *cmv1.ClusterGetServerResponse,
+			) error {
+				cancel()
+				body, err := cmv1.NewCluster().
+					Name("mycluster").
+					Build()
+				if err != nil {
+					return err
+				}
+				response.Body(body)
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request = request.WithContext(ctx)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+	})
+
+	It("Makes the route available to middleware after calling the next handler", func() {
+		// Prepare the server:
+		server.clustersMgmt.v1.clusters.cluster.del = func(
 			ctx context.Context,
-			request *cmv1.ClustersListServerRequest,
-			response *cmv1.ClustersListServerResponse,
+			request *cmv1.ClusterDeleteServerRequest,
+			response *cmv1.ClusterDeleteServerResponse,
 		) error {
-			items, err := cmv1.NewClusterList().Build()
-			if err != nil {
-				return err
+			return nil
+		}
+
+		// Create the adapter with middleware that saves the route:
+		var before, after *helpers.Route
+		middleware := func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				before = helpers.RouteFromContext(r.Context())
+				next.ServeHTTP(w, r)
+				after = helpers.RouteFromContext(r.Context())
+			})
+		}
+		adapter = generated.NewAdapter(server, middleware)
+
+		// Send the request:
+		request := httptest.NewRequest(
+			http.MethodDelete,
+			"/clusters_mgmt/v1/clusters/123",
+			nil,
+		)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+
+		// Check the route:
+		Expect(before).To(BeNil())
+		Expect(after).ToNot(BeNil())
+		Expect(after.Service).To(Equal("clusters_mgmt"))
+		Expect(after.Version).To(Equal("v1"))
+		Expect(after.Resource).To(Equal("cluster"))
+		Expect(after.Method).To(Equal("delete"))
+		Expect(after.Variables).To(HaveKeyWithValue("cluster", "123"))
+	})
+
+	Describe("Request logger", func() {
+		var lines []string
+
+		BeforeEach(func() {
+			lines = nil
+		})
+
+		logf := func(ctx context.Context, format string, args ...interface{}) {
+			lines = append(lines, fmt.Sprintf(format, args...))
+		}
+
+		send := func() {
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters/123/identity_providers",
+				strings.NewReader(`{
+					"name": "my_gitlab",
+					"gitlab": {
+						"client_secret": "my_secret"
+					}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+		}
+
+		It("Logs the route and the status", func() {
+			logger := generated.NewRequestLogger().Logf(logf)
+			adapter = generated.NewAdapter(server, logger.Middleware)
+			send()
+			Expect(lines).To(HaveLen(1))
+			line := lines[0]
+			Expect(line).To(ContainSubstring(
+				"path '/clusters_mgmt/v1/clusters/123/identity_providers'",
+			))
+			Expect(line).To(ContainSubstring("operation '"))
+			Expect(line).To(ContainSubstring("resource 'identity_providers'"))
+			Expect(line).To(ContainSubstring("method 'add'"))
+			Expect(line).To(ContainSubstring("variables map[cluster:123]"))
+			Expect(line).To(ContainSubstring("status 201"))
+			Expect(line).ToNot(ContainSubstring("parameters"))
+		})
+
+		It("Logs the bodies with sensitive attributes masked", func() {
+			logger := generated.NewRequestLogger().Logf(logf).Bodies(true)
+			adapter = generated.NewAdapter(server, logger.Middleware)
+			send()
+			Expect(lines).To(HaveLen(1))
+			line := lines[0]
+			Expect(line).To(ContainSubstring(`"name":"my_gitlab"`))
+			Expect(line).To(ContainSubstring(`"client_secret":"REDACTED"`))
+			Expect(line).ToNot(ContainSubstring("my_secret"))
+		})
+
+		It("Shares the record with the audit sink", func() {
+			var records []*helpers.AuditRecord
+			logger := generated.NewRequestLogger().Logf(logf).Bodies(true)
+			adapter = generated.NewAdapter(server, logger.Middleware)
+			adapter.Audit(helpers.AuditSinkFunc(func(ctx context.Context,
+				record *helpers.AuditRecord) {
+				records = append(records, record)
+			}))
+			send()
+			Expect(lines).To(HaveLen(1))
+			Expect(lines[0]).To(ContainSubstring(`"client_secret":"REDACTED"`))
+			Expect(records).To(HaveLen(1))
+			Expect(records[0].Route).ToNot(BeNil())
+			Expect(records[0].Status).To(Equal(http.StatusCreated))
+		})
+	})
+
+	Describe("Timeouts", func() {
+		It("Returns 504 when the timeout of the method expires", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+
+			// Set the timeouts:
+			adapter.Timeouts(&helpers.TimeoutConfig{
+				Default: time.Minute,
+				Delete:  10 * time.Millisecond,
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusGatewayTimeout))
+		})
+
+		It("Uses the default timeout", func() {
+			// Prepare the server:
+			var deadline time.Time
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				deadline, _ = ctx.Deadline()
+				return nil
+			}
+
+			// Set the timeouts:
+			adapter.Timeouts(&helpers.TimeoutConfig{
+				Default: time.Minute,
+				Get:     time.Second,
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(deadline).To(BeTemporally("~", time.Now().Add(time.Minute), time.Second))
+		})
+
+		It("Doesn't set a deadline by default", func() {
+			// Prepare the server:
+			hasDeadline := true
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				_, hasDeadline = ctx.Deadline()
+				return nil
+			}
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(hasDeadline).To(BeFalse())
+		})
+	})
+
+	Describe("Probes", func() {
+		It("Answers the liveness probe", func() {
+			request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"status": "ok"
+			}`))
+		})
+
+		It("Answers the readiness probe when all the checks succeed", func() {
+			adapter.ReadinessCheck("database", func(ctx context.Context) error {
+				return nil
+			})
+			request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"status": "ok",
+				"checks": {
+					"database": "ok"
+				}
+			}`))
+		})
+
+		It("Fails the readiness probe when a check fails", func() {
+			adapter.ReadinessCheck("database", func(ctx context.Context) error {
+				return nil
+			})
+			adapter.ReadinessCheck("broker", func(ctx context.Context) error {
+				return errors.New("connection refused")
+			})
+			request := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusServiceUnavailable))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"status": "failed",
+				"checks": {
+					"broker": "connection refused",
+					"database": "ok"
+				}
+			}`))
+		})
+
+		It("Doesn't run the readiness checks for the liveness probe", func() {
+			adapter.ReadinessCheck("broker", func(ctx context.Context) error {
+				return errors.New("connection refused")
+			})
+			request := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Rejects methods other than GET and HEAD", func() {
+			request := httptest.NewRequest(http.MethodPost, "/healthz", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD"))
+		})
+
+		It("Answers the probes without applying the middleware", func() {
+			// Create the adapter with a middleware that rejects all requests:
+			reject := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusUnauthorized)
+				})
+			}
+			adapter = generated.NewAdapter(server, reject)
+
+			// Check that the probes are answered:
+			for _, path := range []string{"/healthz", "/readyz"} {
+				recorder = httptest.NewRecorder()
+				request := httptest.NewRequest(http.MethodGet, path, nil)
+				adapter.ServeHTTP(recorder, request)
+				Expect(recorder.Code).To(Equal(http.StatusOK))
 			}
-			response.Items(items)
-			response.Page(1)
-			response.Size(0)
-			response.Total(0)
-			return nil
-		}
 
-		// Send the request:
-		request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1/clusters/", nil)
-		adapter.ServeHTTP(recorder, request)
+			// Check that other requests are rejected:
+			recorder = httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1/clusters", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusUnauthorized))
+		})
+	})
 
-		// Verify the response:
+	It("Answers ping requests with the time and version of the server", func() {
+		before := time.Now()
+		request := httptest.NewRequest(
+			http.MethodPost,
+			"/clusters_mgmt/v1/ping",
+			strings.NewReader(`{}`),
+		)
+		adapter.ServeHTTP(recorder, request)
+		after := time.Now()
 		Expect(recorder.Code).To(Equal(http.StatusOK))
+		var body struct {
+			Time    time.Time
+			Version string
+		}
+		err := json.Unmarshal(recorder.Body.Bytes(), &body)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(body.Version).To(Equal("1.2.3"))
+		Expect(body.Time).To(BeTemporally(">=", before.Truncate(time.Second)))
+		Expect(body.Time).To(BeTemporally("<=", after))
+	})
+
+	Describe("Authorizer", func() {
+		It("Receives the route", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				return nil
+			}
+
+			// Set the authorizer:
+			authorizer := &MyAuthorizer{
+				allowed: true,
+			}
+			adapter.Authorizer(authorizer)
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNoContent))
+			Expect(authorizer.route).To(Equal(&helpers.Route{
+				Service:  "clusters_mgmt",
+				Version:  "v1",
+				Resource: "cluster",
+				Method:   "delete",
+				Path:     "/clusters_mgmt/v1/clusters/123",
+				Variables: map[string]string{
+					"cluster": "123",
+				},
+			}))
+		})
+
+		It("Rejects requests that aren't allowed", func() {
+			// Prepare the server:
+			server.clustersMgmt.v1.clusters.cluster.del = func(
+				ctx context.Context,
+				request *cmv1.ClusterDeleteServerRequest,
+				response *cmv1.ClusterDeleteServerResponse,
+			) error {
+				Fail("Server shouldn't be called")
+				return nil
+			}
+
+			// Set the authorizer:
+			adapter.Authorizer(&MyAuthorizer{
+				allowed: false,
+				reason:  "You can't delete clusters",
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			request.Header.Set("X-Operation-ID", "myid")
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Error",
+				"id": "403",
+				"reason": "You can't delete clusters",
+				"operation_id": "myid"
+			}`))
+		})
+	})
+
+	Describe("Audit", func() {
+		var records []*helpers.AuditRecord
+
+		BeforeEach(func() {
+			records = nil
+			adapter.Audit(helpers.AuditSinkFunc(func(ctx context.Context,
+				record *helpers.AuditRecord) {
+				records = append(records, record)
+			}))
+		})
+
+		It("Redacts sensitive attributes", func() {
+			// Create an adapter with a middleware that sets the actor:
+			authenticator := func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					ctx := helpers.WithActor(r.Context(), "alice")
+					next.ServeHTTP(w, r.WithContext(ctx))
+				})
+			}
+			adapter = generated.NewAdapter(server, authenticator)
+			adapter.Audit(helpers.AuditSinkFunc(func(ctx context.Context,
+				record *helpers.AuditRecord) {
+				records = append(records, record)
+			}))
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/clusters_mgmt/v1/clusters/123/identity_providers",
+				strings.NewReader(`{
+					"name": "my_gitlab",
+					"gitlab": {
+						"client_id": "my_client",
+						"client_secret": "my_secret"
+					}
+				}`),
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusCreated))
+
+			// Check the record:
+			Expect(records).To(HaveLen(1))
+			record := records[0]
+			Expect(record.Actor).To(Equal("alice"))
+			Expect(record.OperationID).ToNot(BeEmpty())
+			Expect(record.Method).To(Equal(http.MethodPost))
+			Expect(record.Path).To(Equal("/clusters_mgmt/v1/clusters/123/identity_providers"))
+			Expect(record.Route).ToNot(BeNil())
+			Expect(record.Route.Resource).To(Equal("identity_providers"))
+			Expect(record.Route.Method).To(Equal("add"))
+			Expect(record.Status).To(Equal(http.StatusCreated))
+			Expect(record.Outcome).To(Equal(helpers.AuditSuccess))
+			Expect(record.Request).To(HaveKey("body"))
+			body, ok := record.Request["body"].(map[string]interface{})
+			Expect(ok).To(BeTrue())
+			Expect(body).To(HaveKeyWithValue("name", "my_gitlab"))
+			gitlab, ok := body["gitlab"].(map[string]interface{})
+			Expect(ok).To(BeTrue())
+			Expect(gitlab).To(HaveKeyWithValue("client_id", "my_client"))
+			Expect(gitlab).To(HaveKeyWithValue("client_secret", helpers.Redacted))
+		})
+
+		It("Records requests that aren't allowed", func() {
+			// Set the authorizer:
+			adapter.Authorizer(&MyAuthorizer{
+				allowed: false,
+				reason:  "You can't delete clusters",
+			})
+
+			// Send the request:
+			request := httptest.NewRequest(
+				http.MethodDelete,
+				"/clusters_mgmt/v1/clusters/123",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+
+			// Check the record:
+			Expect(records).To(HaveLen(1))
+			record := records[0]
+			Expect(record.Actor).To(BeEmpty())
+			Expect(record.Route).ToNot(BeNil())
+			Expect(record.Route.Method).To(Equal("delete"))
+			Expect(record.Request).To(BeNil())
+			Expect(record.Status).To(Equal(http.StatusForbidden))
+			Expect(record.Outcome).To(Equal(helpers.AuditDenied))
+		})
+
+		It("Records requests that don't match a method", func() {
+			request := httptest.NewRequest(http.MethodGet, "/foo", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+			Expect(records).To(HaveLen(1))
+			record := records[0]
+			Expect(record.Route).To(BeNil())
+			Expect(record.Status).To(Equal(http.StatusNotFound))
+			Expect(record.Outcome).To(Equal(helpers.AuditFailure))
+		})
 	})
 
 	It("Returns a 404 for an unknown resource", func() {
@@ -514,10 +2819,219 @@ var _ = Describe("Server", func() {
 		Expect(recorder.Code).To(Equal(http.StatusNotFound))
 	})
 
+	Describe("Unimplemented server", func() {
+		It("Sends 501 for methods that aren't implemented", func() {
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/register_cluster",
+				strings.NewReader(`{}`),
+			)
+			cmv1.Dispatch(recorder, request, &MyPartialServer{}, []string{"register_cluster"})
+			Expect(recorder.Code).To(Equal(http.StatusNotImplemented))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "Error",
+				"id": "501",
+				"reason": "Method 'POST' for path '/register_cluster' isn't implemented"
+			}`))
+		})
+
+		It("Calls methods that are implemented", func() {
+			request := httptest.NewRequest(
+				http.MethodPost,
+				"/register_disconnected",
+				strings.NewReader(`{}`),
+			)
+			cmv1.Dispatch(recorder, request, &MyPartialServer{}, []string{"register_disconnected"})
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+		})
+
+		It("Sends 404 for locators that aren't implemented", func() {
+			request := httptest.NewRequest(http.MethodGet, "/clusters", nil)
+			cmv1.Dispatch(recorder, request, &MyPartialServer{}, []string{"clusters"})
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	Describe("Fallback", func() {
+		var upstream *httptest.Server
+
+		BeforeEach(func() {
+			upstream = httptest.NewServer(http.HandlerFunc(
+				func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/json")
+					fmt.Fprintf(w, `{"path": "%s", "query": "%s"}`, r.URL.Path, r.URL.RawQuery)
+				},
+			))
+			proxy, err := generated.NewProxy(upstream.URL)
+			Expect(err).ToNot(HaveOccurred())
+			adapter.Fallback(proxy)
+		})
+
+		AfterEach(func() {
+			upstream.Close()
+		})
+
+		It("Sends to the upstream requests for resources without server", func() {
+			request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1/nil?page=2", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"path": "/clusters_mgmt/v1/nil",
+				"query": "page=2"
+			}`))
+		})
+
+		It("Sends to the upstream requests for services without server", func() {
+			request := httptest.NewRequest(http.MethodGet, "/accounts_mgmt/v1/accounts", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"path": "/accounts_mgmt/v1/accounts",
+				"query": ""
+			}`))
+		})
+
+		It("Doesn't send to the upstream requests for implemented resources", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123/identity_providers",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "IdentityProviderList",
+				"page": 1,
+				"size": 1,
+				"total": 1,
+				"items": [
+					{
+						"kind": "IdentityProvider",
+						"name": "test-list-identity-providers"
+					}
+				]
+			}`))
+		})
+
+		It("Doesn't send to the upstream requests for objects that don't exist", func() {
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/clusters_mgmt/v1/clusters/123/identity_providers/456",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("Calls the authorizer before sending to the upstream", func() {
+			authorizer := &MyAuthorizer{
+				allowed: false,
+				reason:  "You can't use the upstream",
+			}
+			adapter.Authorizer(authorizer)
+			request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1/nil", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusForbidden))
+			Expect(authorizer.route).ToNot(BeNil())
+			Expect(authorizer.route.Path).To(Equal("/clusters_mgmt/v1/nil"))
+		})
+
+		It("Still returns 404 for unknown resources", func() {
+			request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1/flusters", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("Rejects an upstream URL without host", func() {
+			_, err := generated.NewProxy("/api")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Subtree adapter", func() {
+		const prefix = "/api/clusters_mgmt/v1/clusters/{cluster}/identity_providers"
+
+		It("Serves requests for the mounted subtree", func() {
+			server := &MyMountedIdentityProvidersServer{}
+			adapter := generated.NewSubtreeAdapter(
+				prefix,
+				cmv1.NewIdentityProvidersDispatcher(server),
+			)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/clusters/123/identity_providers",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"kind": "IdentityProviderList",
+				"page": 1,
+				"size": 1,
+				"total": 1,
+				"items": [
+					{
+						"kind": "IdentityProvider",
+						"name": "test-list-identity-providers"
+					}
+				]
+			}`))
+			Expect(server.variables).To(HaveKeyWithValue("cluster", "123"))
+		})
+
+		It("Returns 404 if the path doesn't match the prefix", func() {
+			adapter := generated.NewSubtreeAdapter(
+				prefix,
+				cmv1.NewIdentityProvidersDispatcher(&MyMountedIdentityProvidersServer{}),
+			)
+			request := httptest.NewRequest(
+				http.MethodGet,
+				"/api/clusters_mgmt/v1/clusters/123/groups",
+				nil,
+			)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+
+		It("Returns 404 if the path is shorter than the prefix", func() {
+			adapter := generated.NewSubtreeAdapter(
+				prefix,
+				cmv1.NewIdentityProvidersDispatcher(&MyMountedIdentityProvidersServer{}),
+			)
+			request := httptest.NewRequest(http.MethodGet, "/api/clusters_mgmt/v1", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		})
+	})
+
+	It("Returns the versions of the service", func() {
+		request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt", nil)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"kind": "APIVersionList",
+			"items": [
+				{
+					"kind": "APIVersionLink",
+					"id": "v1",
+					"href": "/clusters_mgmt/v1"
+				}
+			]
+		}`))
+	})
+
 	It("Returns 405 for unsupported service method", func() {
 		request := httptest.NewRequest(http.MethodPost, "/clusters_mgmt", nil)
 		adapter.ServeHTTP(recorder, request)
 		Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD, OPTIONS"))
+	})
+
+	It("Advertises the allowed methods of the service", func() {
+		request := httptest.NewRequest(http.MethodOptions, "/clusters_mgmt", nil)
+		adapter.ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusNoContent))
+		Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD, OPTIONS"))
 	})
 
 	It("Returns 405 for unsupported version method", func() {
@@ -561,6 +3075,63 @@ var _ = Describe("Server", func() {
 			}
 		}`))
 	})
+
+	Describe("Version metadata", func() {
+		It("Returns empty metadata by default", func() {
+			request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{}`))
+		})
+
+		It("Returns metadata provided by the server", func() {
+			// Prepare the server:
+			var err error
+			server.clustersMgmt.v1.metadata, err = cmv1.NewMetadata().
+				ServerVersion("123").
+				BuildTimestamp(time.Date(2019, 7, 25, 12, 34, 56, 0, time.UTC)).
+				GitCommit("abcdef").
+				Features("a", "b").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cmv1.GeneratedFrom).To(HavePrefix("model test, metamodel "))
+
+			// Send the request:
+			request := httptest.NewRequest(http.MethodGet, "/clusters_mgmt/v1", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body).To(MatchJSON(`{
+				"server_version": "123",
+				"build_timestamp": "2019-07-25T12:34:56Z",
+				"git_commit": "abcdef",
+				"generated_from": "` + cmv1.GeneratedFrom + `",
+				"features": [
+					"a",
+					"b"
+				]
+			}`))
+		})
+
+		It("Returns headers only for HEAD", func() {
+			request := httptest.NewRequest(http.MethodHead, "/clusters_mgmt/v1", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusOK))
+			Expect(recorder.Body.Len()).To(BeZero())
+		})
+
+		It("Includes GET and HEAD in the response to OPTIONS", func() {
+			request := httptest.NewRequest(http.MethodOptions, "/clusters_mgmt/v1", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD, OPTIONS"))
+		})
+
+		It("Includes GET and HEAD in the allow header of method not allowed", func() {
+			request := httptest.NewRequest(http.MethodDelete, "/clusters_mgmt/v1", nil)
+			adapter.ServeHTTP(recorder, request)
+			Expect(recorder.Code).To(Equal(http.StatusMethodNotAllowed))
+			Expect(recorder.Header().Get("Allow")).To(Equal("GET, HEAD, OPTIONS"))
+		})
+	})
 })
 
 // MyServer is the implementation of the top level server.
@@ -595,13 +3166,23 @@ func (s *MyCMServer) V1() cmv1.Server {
 	return s.v1
 }
 
+func (s *MyCMServer) V2() cmv2.Server {
+	return nil
+}
+
 // MyCMV1Server is the implementation of version 1 of the clusters management server.
 type MyCMV1Server struct {
 	clusters *MyClustersServer
+	metadata *cmv1.Metadata
 }
 
-// Make sure that we implement the interface:
+// Make sure that we implement the interfaces:
 var _ cmv1.Server = &MyCMV1Server{}
+var _ cmv1.MetadataServer = &MyCMV1Server{}
+
+func (s *MyCMV1Server) Metadata(ctx context.Context) (*cmv1.Metadata, error) {
+	return s.metadata, nil
+}
 
 func (s *MyCMV1Server) RegisterCluster(ctx context.Context,
 	request *cmv1.RegisterClusterServerRequest,
@@ -626,10 +3207,22 @@ func (s *MyCMV1Server) RegisterDisconnected(ctx context.Context,
 	return nil
 }
 
+func (s *MyCMV1Server) Ping(ctx context.Context,
+	request *cmv1.PingServerRequest,
+	response *cmv1.PingServerResponse) error {
+	response.Version("1.2.3")
+	return nil
+}
+
 func (s *MyCMV1Server) Clusters() cmv1.ClustersServer {
 	return s.clusters
 }
 
+func (s *MyCMV1Server) Maintenance() cmv1.MaintenanceServer {
+	// The internal maintenance resource isn't used by these tests.
+	return nil
+}
+
 func (s *MyCMV1Server) Nil() cmv1.NilServer {
 	// This should always return nil, as it is used in the tests to check what happens when
 	// a locator returns nil.
@@ -644,6 +3237,21 @@ type MyClustersServer struct {
 		request *cmv1.ClustersListServerRequest,
 		response *cmv1.ClustersListServerResponse,
 	) error
+	add func(
+		ctx context.Context,
+		request *cmv1.ClustersAddServerRequest,
+		response *cmv1.ClustersAddServerResponse,
+	) error
+	bulkAdd func(
+		ctx context.Context,
+		request *cmv1.ClustersBulkAddServerRequest,
+		response *cmv1.ClustersBulkAddServerResponse,
+	) error
+	bulkDelete func(
+		ctx context.Context,
+		request *cmv1.ClustersBulkDeleteServerRequest,
+		response *cmv1.ClustersBulkDeleteServerResponse,
+	) error
 
 	// Locators:
 	cluster *MyClusterServer
@@ -656,7 +3264,24 @@ func (s *MyClustersServer) List(ctx context.Context, request *cmv1.ClustersListS
 
 func (s *MyClustersServer) Add(ctx context.Context, request *cmv1.ClustersAddServerRequest,
 	response *cmv1.ClustersAddServerResponse) error {
-	return nil
+	if s.add == nil {
+		return nil
+	}
+	return s.add(ctx, request, response)
+}
+
+func (s *MyClustersServer) BulkAdd(ctx context.Context, request *cmv1.ClustersBulkAddServerRequest,
+	response *cmv1.ClustersBulkAddServerResponse) error {
+	return s.bulkAdd(ctx, request, response)
+}
+
+func (s *MyClustersServer) BulkDelete(ctx context.Context,
+	request *cmv1.ClustersBulkDeleteServerRequest,
+	response *cmv1.ClustersBulkDeleteServerResponse) error {
+	if s.bulkDelete == nil {
+		return nil
+	}
+	return s.bulkDelete(ctx, request, response)
 }
 
 func (s *MyClustersServer) Cluster(id string) cmv1.ClusterServer {
@@ -676,16 +3301,27 @@ type MyClusterServer struct {
 		response *cmv1.ClusterGetServerResponse,
 	) error
 
+	// Update method:
+	update func(
+		ctx context.Context,
+		request *cmv1.ClusterUpdateServerRequest,
+		response *cmv1.ClusterUpdateServerResponse,
+	) error
+
 	// Delete method:
 	del func(
 		ctx context.Context,
 		request *cmv1.ClusterDeleteServerRequest,
 		response *cmv1.ClusterDeleteServerResponse,
 	) error
+
+	// Current version of the object:
+	current func(ctx context.Context) (*cmv1.Cluster, error)
 }
 
-// Make sure we implement the interface:
+// Make sure we implement the interfaces:
 var _ cmv1.ClusterServer = &MyClusterServer{}
+var _ cmv1.ClusterCurrentServer = &MyClusterServer{}
 
 func (s *MyClusterServer) Get(ctx context.Context, request *cmv1.ClusterGetServerRequest,
 	response *cmv1.ClusterGetServerResponse) error {
@@ -694,7 +3330,10 @@ func (s *MyClusterServer) Get(ctx context.Context, request *cmv1.ClusterGetServe
 
 func (s *MyClusterServer) Update(ctx context.Context, request *cmv1.ClusterUpdateServerRequest,
 	response *cmv1.ClusterUpdateServerResponse) error {
-	return nil
+	if s.update == nil {
+		return nil
+	}
+	return s.update(ctx, request, response)
 }
 
 func (s *MyClusterServer) Delete(ctx context.Context, request *cmv1.ClusterDeleteServerRequest,
@@ -702,6 +3341,13 @@ func (s *MyClusterServer) Delete(ctx context.Context, request *cmv1.ClusterDelet
 	return s.del(ctx, request, response)
 }
 
+func (s *MyClusterServer) Current(ctx context.Context) (*cmv1.Cluster, error) {
+	if s.current == nil {
+		return nil, nil
+	}
+	return s.current(ctx)
+}
+
 func (s *MyClusterServer) Groups() cmv1.GroupsServer {
 	return nil
 }
@@ -744,3 +3390,96 @@ func (s *MyIdentityProvidersServer) Add(ctx context.Context,
 func (s *MyIdentityProvidersServer) IdentityProvider(id string) cmv1.IdentityProviderServer {
 	return nil
 }
+
+// MyMountedIdentityProvidersServer is an implementation of the identity providers server that
+// saves the path variables of the requests that it receives.
+type MyMountedIdentityProvidersServer struct {
+	MyIdentityProvidersServer
+	variables map[string]string
+}
+
+func (s *MyMountedIdentityProvidersServer) List(ctx context.Context,
+	request *cmv1.IdentityProvidersListServerRequest,
+	response *cmv1.IdentityProvidersListServerResponse) error {
+	s.variables = helpers.Variables(ctx)
+	return s.MyIdentityProvidersServer.List(ctx, request, response)
+}
+
+// MyIdempotencyStore is an idempotency store that keeps the responses in memory.
+type MyIdempotencyStore struct {
+	lock      sync.Mutex
+	pending   map[string]bool
+	responses map[string]*generated.IdempotentResponse
+}
+
+func (s *MyIdempotencyStore) Reserve(ctx context.Context,
+	key string) (saved *generated.IdempotentResponse, reserved bool, err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	saved = s.responses[key]
+	if saved != nil || s.pending[key] {
+		return
+	}
+	if s.pending == nil {
+		s.pending = map[string]bool{}
+	}
+	s.pending[key] = true
+	reserved = true
+	return
+}
+
+func (s *MyIdempotencyStore) Put(ctx context.Context, key string,
+	response *generated.IdempotentResponse) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.pending, key)
+	if s.responses == nil {
+		s.responses = map[string]*generated.IdempotentResponse{}
+	}
+	s.responses[key] = response
+	return nil
+}
+
+func (s *MyIdempotencyStore) Release(ctx context.Context, key string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.pending, key)
+	return nil
+}
+
+// myIdempotencyScope is an idempotency scope that takes the principal from the 'X-User'
+// header.
+func myIdempotencyScope(r *http.Request) (string, error) {
+	return r.Header.Get("X-User"), nil
+}
+
+// MyAuthorizer is an authorizer that returns a fixed decision and saves the route that it
+// received.
+type MyAuthorizer struct {
+	allowed bool
+	reason  string
+	route   *helpers.Route
+}
+
+func (a *MyAuthorizer) Authorize(ctx context.Context,
+	route *helpers.Route) (allowed bool, reason string, err error) {
+	a.route = route
+	allowed = a.allowed
+	reason = a.reason
+	return
+}
+
+// MyPartialServer is an implementation of version 1 of the clusters management server that
+// implements only one of the methods, and uses the unimplemented server for the rest.
+type MyPartialServer struct {
+	cmv1.UnimplementedServer
+}
+
+// Make sure that we implement the interface:
+var _ cmv1.Server = &MyPartialServer{}
+
+func (s *MyPartialServer) RegisterDisconnected(ctx context.Context,
+	request *cmv1.RegisterDisconnectedServerRequest,
+	response *cmv1.RegisterDisconnectedServerResponse) error {
+	return nil
+}
@@ -0,0 +1,271 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the token management support.
+
+package tests
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("Token transport", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server = NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// countingSource returns a token source that generates a new token each time it is
+	// called, with the given lifetime.
+	countingSource := func(calls *int, lifetime time.Duration) helpers.TokenSource {
+		return helpers.TokenSourceFunc(func(ctx context.Context) (string, time.Time, error) {
+			*calls++
+			return fmt.Sprintf("token%d", *calls), time.Now().Add(lifetime), nil
+		})
+	}
+
+	It("Can't be created without a source", func() {
+		_, err := helpers.NewTokenTransport(NewTransport(server)).Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Adds the authorization header", func() {
+		server.AppendHandlers(CombineHandlers(
+			VerifyHeaderKV("Authorization", "Bearer mytoken"),
+			RespondWith(http.StatusOK, `{}`),
+		))
+		transport, err := helpers.NewTokenTransport(NewTransport(server)).
+			Source(helpers.StaticTokenSource("mytoken")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Doesn't replace an explicit authorization header", func() {
+		server.AppendHandlers(CombineHandlers(
+			VerifyHeaderKV("Authorization", "Bearer explicit"),
+			RespondWith(http.StatusOK, `{}`),
+		))
+		transport, err := helpers.NewTokenTransport(NewTransport(server)).
+			Source(helpers.StaticTokenSource("mytoken")).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().
+			Header("Authorization", "Bearer explicit").
+			Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Reuses the token till it is about to expire", func() {
+		calls := 0
+		transport, err := helpers.NewTokenTransport(NewTransport(server)).
+			Source(countingSource(&calls, 1*time.Hour)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		for i := 0; i < 3; i++ {
+			access, err := transport.Token(context.Background())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(access).To(Equal("token1"))
+		}
+		Expect(calls).To(Equal(1))
+	})
+
+	It("Refreshes the token before it expires", func() {
+		calls := 0
+		transport, err := helpers.NewTokenTransport(NewTransport(server)).
+			Source(countingSource(&calls, 30*time.Second)).
+			Margin(1 * time.Minute).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		first, err := transport.Token(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		second, err := transport.Token(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(first).To(Equal("token1"))
+		Expect(second).To(Equal("token2"))
+	})
+
+	It("Discards the token when the server rejects it", func() {
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyHeaderKV("Authorization", "Bearer token1"),
+				RespondWith(http.StatusUnauthorized, `{}`),
+			),
+			CombineHandlers(
+				VerifyHeaderKV("Authorization", "Bearer token2"),
+				RespondWith(http.StatusOK, `{}`),
+			),
+		)
+		calls := 0
+		transport, err := helpers.NewTokenTransport(NewTransport(server)).
+			Source(countingSource(&calls, 1*time.Hour)).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(transport, "/api/clusters_mgmt/v1/clusters/123", "")
+		_, err = client.Get().Send()
+		Expect(err).To(HaveOccurred())
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+})
+
+var _ = Describe("OAuth token source", func() {
+	var server *Server
+
+	BeforeEach(func() {
+		server = NewServer()
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("Can't be created without a secret or refresh token", func() {
+		_, err := helpers.NewOAuthTokenSource(&http.Transport{}).
+			URL(server.URL()+"/token").
+			Client("myclient", "").
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Uses the client credentials grant", func() {
+		server.AppendHandlers(CombineHandlers(
+			VerifyRequest(http.MethodPost, "/token"),
+			VerifyFormKV("grant_type", "client_credentials"),
+			VerifyFormKV("client_id", "myclient"),
+			VerifyFormKV("client_secret", "mysecret"),
+			VerifyFormKV("scope", "openid profile"),
+			RespondWith(http.StatusOK, `{
+				"access_token": "myaccess",
+				"expires_in": 300
+			}`),
+		))
+		source, err := helpers.NewOAuthTokenSource(&http.Transport{}).
+			URL(server.URL()+"/token").
+			Client("myclient", "mysecret").
+			Scopes("openid", "profile").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		access, expiry, err := source.Token(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(access).To(Equal("myaccess"))
+		Expect(expiry).To(BeTemporally("~", time.Now().Add(300*time.Second), 5*time.Second))
+	})
+
+	It("Uses the rotated refresh token", func() {
+		server.AppendHandlers(
+			CombineHandlers(
+				VerifyFormKV("grant_type", "refresh_token"),
+				VerifyFormKV("refresh_token", "myrefresh1"),
+				RespondWith(http.StatusOK, `{
+					"access_token": "myaccess1",
+					"refresh_token": "myrefresh2"
+				}`),
+			),
+			CombineHandlers(
+				VerifyFormKV("grant_type", "refresh_token"),
+				VerifyFormKV("refresh_token", "myrefresh2"),
+				RespondWith(http.StatusOK, `{
+					"access_token": "myaccess2"
+				}`),
+			),
+		)
+		source, err := helpers.NewOAuthTokenSource(&http.Transport{}).
+			URL(server.URL()+"/token").
+			Client("myclient", "").
+			RefreshToken("myrefresh1").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		access, _, err := source.Token(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(access).To(Equal("myaccess1"))
+		access, _, err = source.Token(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(access).To(Equal("myaccess2"))
+	})
+
+	It("Returns the error sent by the server", func() {
+		server.AppendHandlers(RespondWith(http.StatusBadRequest, `{
+			"error": "invalid_grant",
+			"error_description": "Token is not active"
+		}`))
+		source, err := helpers.NewOAuthTokenSource(&http.Transport{}).
+			URL(server.URL()+"/token").
+			Client("myclient", "").
+			RefreshToken("myrefresh").
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		_, _, err = source.Token(context.Background())
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("invalid_grant"))
+		Expect(err.Error()).To(ContainSubstring("Token is not active"))
+	})
+})
+
+var _ = Describe("File token source", func() {
+	It("Reads the token and the expiry time from the file", func() {
+		// Create a token with an expiry claim. The header and the signature aren't checked,
+		// so any value will work:
+		exp := time.Now().Add(1 * time.Hour).Truncate(time.Second)
+		claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(
+			`{"exp": %d}`, exp.Unix(),
+		)))
+		token := "header." + claims + ".signature"
+
+		// Write the token to a temporary file:
+		file, err := ioutil.TempFile("", "token")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.Remove(file.Name())
+		_, err = file.WriteString(token + "\n")
+		Expect(err).ToNot(HaveOccurred())
+		err = file.Close()
+		Expect(err).ToNot(HaveOccurred())
+
+		// Read the token:
+		source := helpers.FileTokenSource(file.Name())
+		access, expiry, err := source.Token(context.Background())
+		Expect(err).ToNot(HaveOccurred())
+		Expect(access).To(Equal(token))
+		Expect(expiry).To(BeTemporally("==", exp))
+	})
+
+	It("Fails if the file doesn't exist", func() {
+		source := helpers.FileTokenSource("/does/not/exist")
+		_, _, err := source.Token(context.Background())
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,177 @@
+/*
+Copyright (c) 2020 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+  http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// This file contains tests for the transport builder.
+
+package tests
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/ghttp"
+
+	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/helpers"
+)
+
+var _ = Describe("Transport", func() {
+	It("Can be created with the default configuration", func() {
+		transport, err := helpers.NewTransport().Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport).ToNot(BeNil())
+		Expect(transport.MaxIdleConns).To(Equal(100))
+	})
+
+	It("Can't be created with negative limits", func() {
+		_, err := helpers.NewTransport().
+			MaxIdleConnsPerHost(-1).
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Can't be created with negative timeouts", func() {
+		_, err := helpers.NewTransport().
+			DialTimeout(-1 * time.Second).
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Applies the connection limits", func() {
+		transport, err := helpers.NewTransport().
+			MaxIdleConns(10).
+			MaxIdleConnsPerHost(5).
+			MaxConnsPerHost(20).
+			IdleConnTimeout(1 * time.Minute).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport.MaxIdleConns).To(Equal(10))
+		Expect(transport.MaxIdleConnsPerHost).To(Equal(5))
+		Expect(transport.MaxConnsPerHost).To(Equal(20))
+		Expect(transport.IdleConnTimeout).To(Equal(1 * time.Minute))
+	})
+
+	It("Copies the TLS configuration", func() {
+		config := &tls.Config{
+			ServerName: "api.example.com",
+		}
+		transport, err := helpers.NewTransport().
+			TLSConfig(config).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport.TLSClientConfig).ToNot(BeIdenticalTo(config))
+		Expect(transport.TLSClientConfig.ServerName).To(Equal("api.example.com"))
+	})
+
+	It("Enables HTTP/2 by default", func() {
+		transport, err := helpers.NewTransport().Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport.ForceAttemptHTTP2).To(BeTrue())
+	})
+
+	It("Can disable HTTP/2", func() {
+		transport, err := helpers.NewTransport().
+			HTTP2(false).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(transport.TLSNextProto).ToNot(BeNil())
+		Expect(transport.TLSNextProto).To(BeEmpty())
+	})
+
+	It("Can be used by the clients", func() {
+		server := NewServer()
+		defer server.Close()
+		server.AppendHandlers(RespondWith(http.StatusOK, `{}`))
+		transport, err := helpers.NewTransport().Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(
+			&Transport{server: server, wrapped: transport},
+			"/api/clusters_mgmt/v1/clusters/123",
+			"",
+		)
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("Can't be created with both a custom dialer and a Unix socket", func() {
+		_, err := helpers.NewTransport().
+			Dialer((&net.Dialer{}).DialContext).
+			UnixSocket("/tmp/my.socket").
+			Build()
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Uses the custom dialer", func() {
+		server := NewServer()
+		defer server.Close()
+		server.AppendHandlers(RespondWith(http.StatusOK, `{}`))
+		var addresses []string
+		transport, err := helpers.NewTransport().
+			Dialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+				addresses = append(addresses, address)
+				return (&net.Dialer{}).DialContext(ctx, network, address)
+			}).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(
+			&Transport{server: server, wrapped: transport},
+			"/api/clusters_mgmt/v1/clusters/123",
+			"",
+		)
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(addresses).To(ConsistOf(server.Addr()))
+	})
+
+	It("Sends requests to the Unix socket", func() {
+		// Start a server listening in a Unix socket:
+		tmp, err := ioutil.TempDir("", "transport")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(tmp)
+		socket := filepath.Join(tmp, "server.socket")
+		listener, err := net.Listen("unix", socket)
+		Expect(err).ToNot(HaveOccurred())
+		server := NewUnstartedServer()
+		server.HTTPTestServer.Listener = listener
+		server.Start()
+		defer server.Close()
+		server.AppendHandlers(RespondWith(http.StatusOK, `{}`))
+
+		// Send the request using the address of other server, as it will be ignored:
+		other := NewServer()
+		defer other.Close()
+		transport, err := helpers.NewTransport().
+			UnixSocket(socket).
+			Build()
+		Expect(err).ToNot(HaveOccurred())
+		client := cmv1.NewClusterClient(
+			&Transport{server: other, wrapped: transport},
+			"/api/clusters_mgmt/v1/clusters/123",
+			"",
+		)
+		_, err = client.Get().Send()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+})
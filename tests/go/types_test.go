@@ -177,6 +177,55 @@ var _ = Describe("Type", func() {
 		})
 	})
 
+	Describe("Slice", func() {
+		It("Returns empty slice for nil list", func() {
+			var list *cmv1.ClusterList
+			slice := list.Slice()
+			Expect(slice).ToNot(BeNil())
+			Expect(slice).To(BeEmpty())
+		})
+
+		It("Returns a copy of the items", func() {
+			list, err := cmv1.NewClusterList().
+				Items(
+					cmv1.NewCluster().ID("123"),
+					cmv1.NewCluster().ID("456"),
+				).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			slice := list.Slice()
+			Expect(slice).To(HaveLen(2))
+			slice[0] = nil
+			Expect(list.Get(0)).ToNot(BeNil())
+		})
+	})
+
+	Describe("Range", func() {
+		It("Doesn't call the function for nil list", func() {
+			var list *cmv1.ClusterList
+			list.Range(func(index int, item *cmv1.Cluster) bool {
+				Fail("Shouldn't be called")
+				return true
+			})
+		})
+
+		It("Stops when the function returns false", func() {
+			list, err := cmv1.NewClusterList().
+				Items(
+					cmv1.NewCluster().ID("123"),
+					cmv1.NewCluster().ID("456"),
+				).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			var ids []string
+			list.Range(func(index int, item *cmv1.Cluster) bool {
+				ids = append(ids, item.ID())
+				return false
+			})
+			Expect(ids).To(Equal([]string{"123"}))
+		})
+	})
+
 	Describe("Empty", func() {
 		It("Returns `true` for nil object ", func() {
 			var object *cmv1.Cluster
@@ -296,6 +345,43 @@ var _ = Describe("Type", func() {
 		})
 	})
 
+	Describe("Unset", func() {
+		It("Returns `true` for nil object", func() {
+			var object *cmv1.Cluster
+			Expect(object.Unset()).To(BeTrue())
+		})
+
+		It("Returns `true` for an object without attributes", func() {
+			object, err := cmv1.NewCluster().Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Unset()).To(BeTrue())
+		})
+
+		It("Returns `false` for an object with an string attribute", func() {
+			object, err := cmv1.NewCluster().
+				Name("mycluster").
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Unset()).To(BeFalse())
+		})
+
+		It("Returns `false` for empty map of strings", func() {
+			object, err := cmv1.NewCluster().
+				Properties(map[string]string{}).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Unset()).To(BeFalse())
+		})
+
+		It("Returns `false` for empty map of objects", func() {
+			object, err := amv1.NewRegistryAuths().
+				Map(map[string]*amv1.RegistryAuthBuilder{}).
+				Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Unset()).To(BeFalse())
+		})
+	})
+
 	Describe("Attribute names", func() {
 		It("Generates correct names for plurals of initialisms", func() {
 			obj, err := azv1.NewResourceReview().
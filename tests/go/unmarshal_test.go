@@ -19,6 +19,8 @@ limitations under the License.
 package tests
 
 import (
+	"bytes"
+	"encoding/json"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -26,6 +28,7 @@ import (
 
 	amv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/accountsmgmt/v1"
 	cmv1 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v1"
+	cmv2 "github.com/openshift-online/ocm-api-metamodel/tests/go/generated/clustersmgmt/v2"
 	"github.com/openshift-online/ocm-api-metamodel/tests/go/generated/errors"
 )
 
@@ -186,6 +189,77 @@ var _ = Describe("Unmarshal", func() {
 		Expect(object).ToNot(BeNil())
 	})
 
+	It("Can read interface attribute", func() {
+		object, err := cmv1.UnmarshalCluster(`{
+			"settings": {
+				"region": "us-east-1",
+				"zones": ["a", "b"],
+				"size": 3
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		settings, ok := object.Settings().(map[string]interface{})
+		Expect(ok).To(BeTrue())
+		Expect(settings).To(HaveKeyWithValue("region", "us-east-1"))
+		Expect(settings).To(HaveKeyWithValue("zones", []interface{}{"a", "b"}))
+		Expect(settings).To(HaveKeyWithValue("size", json.Number("3")))
+	})
+
+	It("Can read anonymous struct attribute", func() {
+		object, err := cmv1.UnmarshalCluster(`{
+			"console": {
+				"url": "https://console.example.com",
+				"enabled": true
+			}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		console, ok := object.GetConsole()
+		Expect(ok).To(BeTrue())
+		Expect(console).ToNot(BeNil())
+		Expect(console.URL()).To(Equal("https://console.example.com"))
+		Expect(console.Enabled()).To(BeTrue())
+	})
+
+	It("Can read empty map attribute", func() {
+		object, err := cmv1.UnmarshalCluster(`{
+			"properties": {}
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		properties, ok := object.GetProperties()
+		Expect(ok).To(BeTrue())
+		Expect(properties).ToNot(BeNil())
+		Expect(properties).To(BeEmpty())
+	})
+
+	It("Reads null map attribute as absent", func() {
+		object, err := cmv1.UnmarshalCluster(`{
+			"properties": null
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		_, ok := object.GetProperties()
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Can read empty list attribute", func() {
+		object, err := cmv1.UnmarshalGithubIdentityProvider(`{
+			"teams": []
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		teams, ok := object.GetTeams()
+		Expect(ok).To(BeTrue())
+		Expect(teams).ToNot(BeNil())
+		Expect(teams).To(BeEmpty())
+	})
+
+	It("Reads null list attribute as absent", func() {
+		object, err := cmv1.UnmarshalGithubIdentityProvider(`{
+			"teams": null
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		_, ok := object.GetTeams()
+		Expect(ok).To(BeFalse())
+	})
+
 	It("Can read an empty list of objects", func() {
 		object, err := cmv1.UnmarshalClusterList(`[]`)
 		Expect(err).ToNot(HaveOccurred())
@@ -636,4 +710,220 @@ var _ = Describe("Unmarshal", func() {
 			time.Date(2019, time.August, 15, 16, 17, 18, 0, time.UTC),
 		}))
 	})
+
+	Describe("Date formats", func() {
+		It("Reads dates using the format of the attribute", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"install_date": "2019-07-14",
+				"probe_timestamp": 1563117377123,
+				"upgrade_timestamp": 1563117377
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.InstallDate()).To(Equal(
+				time.Date(2019, time.July, 14, 0, 0, 0, 0, time.UTC),
+			))
+			Expect(object.ProbeTimestamp()).To(Equal(
+				time.Date(2019, time.July, 14, 15, 16, 17, 123000000, time.UTC),
+			))
+			Expect(object.UpgradeTimestamp()).To(Equal(
+				time.Date(2019, time.July, 14, 15, 16, 17, 0, time.UTC),
+			))
+		})
+
+		It("Fails if date doesn't match the format of the attribute", func() {
+			_, err := cmv1.UnmarshalCluster(`{
+				"install_date": "2019-07-14T15:16:17Z"
+			}`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Tolerant numbers", func() {
+		It("Reads integer encoded as string", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"nodes": {
+					"infra": 2,
+					"compute": "10"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Nodes().Infra()).To(Equal(2))
+			Expect(object.Nodes().Compute()).To(Equal(10))
+		})
+
+		It("Reads long encoded as number", func() {
+			object, err := cmv2.UnmarshalCluster(`{
+				"external_id": 123
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.ExternalID()).To(Equal(int64(123)))
+		})
+
+		It("Reads long encoded as string without loss of precision", func() {
+			object, err := cmv2.UnmarshalCluster(`{
+				"external_id": "9007199254740993"
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.ExternalID()).To(Equal(int64(9007199254740993)))
+		})
+
+		It("Fails if string doesn't contain a number", func() {
+			_, err := cmv2.UnmarshalCluster(`{
+				"external_id": "junk"
+			}`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Raw attributes", func() {
+		It("Returns the JSON text of the attributes", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"name": "mycluster",
+				"network": {"pod_cidr": "10.128.0.0/14"},
+				"unknown": [1, 2, 3]
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			name, ok := object.RawAttribute("name")
+			Expect(ok).To(BeTrue())
+			Expect(string(name)).To(Equal(`"mycluster"`))
+			network, ok := object.RawAttribute("network")
+			Expect(ok).To(BeTrue())
+			Expect(network).To(MatchJSON(`{"pod_cidr": "10.128.0.0/14"}`))
+			unknown, ok := object.RawAttribute("unknown")
+			Expect(ok).To(BeTrue())
+			Expect(unknown).To(MatchJSON(`[1, 2, 3]`))
+			Expect(object.Name()).To(Equal("mycluster"))
+			Expect(object.Network().PodCIDR()).To(Equal("10.128.0.0/14"))
+		})
+
+		It("Returns false for attributes that weren't present", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"name": "mycluster"
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := object.RawAttribute("display_name")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Returns false for objects that weren't unmarshalled", func() {
+			object, err := cmv1.NewCluster().Name("mycluster").Build()
+			Expect(err).ToNot(HaveOccurred())
+			_, ok := object.RawAttribute("name")
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Reports errors in attributes", func() {
+			_, err := cmv1.UnmarshalCluster(`{
+				"name": 123
+			}`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Lazy attributes", func() {
+		It("Decodes lazy attribute when accessed", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"name": "mycluster",
+				"network": {
+					"pod_cidr": "10.128.0.0/14",
+					"service_cidr": "172.30.0.0/16"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Name()).To(Equal("mycluster"))
+			network := object.Network()
+			Expect(network).ToNot(BeNil())
+			Expect(network.PodCIDR()).To(Equal("10.128.0.0/14"))
+			Expect(network.ServiceCIDR()).To(Equal("172.30.0.0/16"))
+			Expect(object.Network()).To(BeIdenticalTo(network))
+		})
+
+		It("Doesn't have value if lazy attribute isn't present", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"name": "mycluster"
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Network()).To(BeNil())
+			_, ok := object.GetNetwork()
+			Expect(ok).To(BeFalse())
+		})
+
+		It("Considers object with lazy attribute not empty", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"network": {
+					"pod_cidr": "10.128.0.0/14"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Empty()).To(BeFalse())
+		})
+
+		It("Writes lazy attribute that hasn't been accessed", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"network": {
+					"pod_cidr": "10.128.0.0/14"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			buffer := &bytes.Buffer{}
+			err = cmv1.MarshalCluster(object, buffer)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(buffer).To(MatchJSON(`{
+				"kind": "Cluster",
+				"network": {
+					"pod_cidr": "10.128.0.0/14"
+				}
+			}`))
+		})
+
+		It("Copies lazy attribute that hasn't been accessed", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"network": {
+					"pod_cidr": "10.128.0.0/14"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			copy, err := cmv1.NewCluster().Copy(object).Build()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(copy.Network().PodCIDR()).To(Equal("10.128.0.0/14"))
+		})
+
+		It("Decodes lazy attribute only once when accessed concurrently", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"network": {
+					"pod_cidr": "10.128.0.0/14"
+				}
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			results := make(chan *cmv1.Network, 10)
+			for i := 0; i < cap(results); i++ {
+				go func() {
+					results <- object.Network()
+				}()
+			}
+			first := <-results
+			Expect(first).ToNot(BeNil())
+			for i := 1; i < cap(results); i++ {
+				Expect(<-results).To(BeIdenticalTo(first))
+			}
+		})
+
+		It("Doesn't have value if lazy attribute can't be decoded", func() {
+			object, err := cmv1.UnmarshalCluster(`{
+				"name": "mycluster",
+				"network": "junk"
+			}`)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(object.Name()).To(Equal("mycluster"))
+			Expect(object.Network()).To(BeNil())
+		})
+	})
+
+	It("Can read attribute whose name is a Go keyword", func() {
+		object, err := cmv1.UnmarshalNetwork(`{
+			"type": "OVNKubernetes"
+		}`)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(object.Type()).To(Equal("OVNKubernetes"))
+	})
 })